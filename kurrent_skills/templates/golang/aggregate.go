@@ -0,0 +1,369 @@
+// KurrentDB Go Client Example - Order aggregate with pure command handlers
+// Demonstrates: decide (command -> events) kept separate from evolve (event -> state), saved with optimistic concurrency
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === EVENTS ===
+
+// ItemAdded is emitted when an item is added to an open order
+type ItemAdded struct {
+	Item  string  `json:"item"`
+	Price float64 `json:"price"`
+}
+
+// OrderShipped is emitted once an order with items leaves the warehouse
+type OrderShipped struct {
+	ShippedAt string `json:"shippedAt"`
+}
+
+// Domain errors returned by the command methods
+var (
+	ErrOrderExists     = errors.New("order already exists")
+	ErrOrderNotFound   = errors.New("order not found")
+	ErrOrderShipped    = errors.New("order already shipped")
+	ErrOrderEmpty      = errors.New("order has no items")
+	ErrInvalidPrice    = errors.New("price must be positive")
+	ErrVersionConflict = errors.New("order was modified concurrently")
+)
+
+// NoVersion is the version of an order whose stream doesn't exist yet
+const NoVersion int64 = -1
+
+// === AGGREGATE ===
+
+// Order is the state rebuilt from an order stream.
+//
+// Command methods (Create, AddItem, Ship) are pure decisions: they validate against the current
+// state and return the events to append, without changing the order. Evolve is the only place state
+// changes, for both loaded and newly emitted events.
+type Order struct {
+	ID         string
+	CustomerID string
+	Status     string // "", "created" or "shipped"
+	Items      []string
+	Amount     float64
+
+	// Version is the revision of the last loaded event, or NoVersion
+	Version int64
+}
+
+func NewOrder(id string) *Order {
+	return &Order{ID: id, Version: NoVersion}
+}
+
+// === DECIDE ===
+
+func (o *Order) Create(customerID string, amount float64) ([]kurrentdb.EventData, error) {
+	if o.Status != "" {
+		return nil, ErrOrderExists
+	}
+	return emit("OrderCreated", OrderCreated{OrderID: o.ID, CustomerID: customerID, Amount: amount})
+}
+
+func (o *Order) AddItem(item string, price float64) ([]kurrentdb.EventData, error) {
+	switch {
+	case o.Status == "":
+		return nil, ErrOrderNotFound
+	case o.Status == "shipped":
+		return nil, ErrOrderShipped
+	case price <= 0:
+		return nil, ErrInvalidPrice
+	}
+	return emit("ItemAdded", ItemAdded{Item: item, Price: price})
+}
+
+func (o *Order) Ship(at time.Time) ([]kurrentdb.EventData, error) {
+	switch {
+	case o.Status == "":
+		return nil, ErrOrderNotFound
+	case o.Status == "shipped":
+		return nil, ErrOrderShipped
+	case len(o.Items) == 0:
+		return nil, ErrOrderEmpty
+	}
+	return emit("OrderShipped", OrderShipped{ShippedAt: at.UTC().Format(time.RFC3339)})
+}
+
+// emit encodes one domain event as EventData for the repository to append
+func emit(eventType string, payload interface{}) ([]kurrentdb.EventData, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return []kurrentdb.EventData{{
+		EventID:     uuid.New(),
+		EventType:   eventType,
+		ContentType: kurrentdb.ContentTypeJson,
+		Data:        data,
+	}}, nil
+}
+
+// === EVOLVE ===
+
+// Evolve applies one event to the state. Unknown event types are ignored so old code can load
+// streams written by newer versions.
+func (o *Order) Evolve(eventType string, data []byte) error {
+	switch eventType {
+	case "OrderCreated":
+		var e OrderCreated
+		if err := json.Unmarshal(data, &e); err != nil {
+			return err
+		}
+		o.CustomerID = e.CustomerID
+		o.Amount = e.Amount
+		o.Status = "created"
+	case "ItemAdded":
+		var e ItemAdded
+		if err := json.Unmarshal(data, &e); err != nil {
+			return err
+		}
+		o.Items = append(o.Items, e.Item)
+		o.Amount += e.Price
+	case "OrderShipped":
+		o.Status = "shipped"
+	}
+	return nil
+}
+
+// EvolveAll applies freshly decided events, e.g. to run a second command on the same instance
+func (o *Order) EvolveAll(events []kurrentdb.EventData) error {
+	for _, event := range events {
+		if err := o.Evolve(event.EventType, event.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// === REPOSITORY ===
+
+// OrderRepository loads orders from and saves events to order-{id} streams. Give it
+// NewClientStore(client), or a MemoryEventStore in tests.
+type OrderRepository struct {
+	store EventStore
+}
+
+func NewOrderRepository(store EventStore) *OrderRepository {
+	return &OrderRepository{store: store}
+}
+
+// Load rebuilds an order from its stream. A missing stream yields an empty order at NoVersion.
+func (r *OrderRepository) Load(ctx context.Context, orderID string) (*Order, error) {
+	order := NewOrder(orderID)
+
+	stream, err := r.store.ReadStream(ctx, Streams.Name("order", orderID), kurrentdb.ReadStreamOptions{
+		Direction: kurrentdb.Forwards,
+		From:      kurrentdb.Start{},
+	}, ^uint64(0))
+	if err != nil {
+		if isStreamNotFound(err) {
+			return order, nil
+		}
+		return nil, err
+	}
+	defer stream.Close()
+
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return order, nil
+		}
+		if err != nil {
+			if isStreamNotFound(err) {
+				return order, nil
+			}
+			return nil, err
+		}
+
+		recorded := Resolve(event, false)
+		if err := order.Evolve(recorded.EventType, recorded.Data); err != nil {
+			return nil, fmt.Errorf("evolve %s@%d: %w", recorded.StreamID, recorded.EventNumber, err)
+		}
+		order.Version = int64(recorded.EventNumber)
+	}
+}
+
+// Save appends events emitted by a command, expecting the stream to still be at version (the
+// version the order was loaded at). It returns the new version, or ErrVersionConflict if another
+// writer appended in between.
+func (r *OrderRepository) Save(ctx context.Context, orderID string, version int64, events []kurrentdb.EventData) (int64, error) {
+	if len(events) == 0 {
+		return version, nil
+	}
+
+	var expected kurrentdb.StreamState = kurrentdb.NoStream{}
+	if version != NoVersion {
+		expected = kurrentdb.Revision(uint64(version))
+	}
+
+	result, err := r.store.AppendToStream(ctx, Streams.Name("order", orderID), kurrentdb.AppendToStreamOptions{
+		StreamState: expected,
+	}, events...)
+	if err != nil {
+		if isWrongExpectedVersion(err) {
+			return version, fmt.Errorf("%w: %v", ErrVersionConflict, err)
+		}
+		return version, err
+	}
+	return int64(result.NextExpectedVersion), nil
+}
+
+// Execute loads an order, runs one command and saves what it emitted
+func (r *OrderRepository) Execute(ctx context.Context, orderID string, command func(*Order) ([]kurrentdb.EventData, error)) ([]kurrentdb.EventData, error) {
+	order, err := r.Load(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := command(order)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Save(ctx, orderID, order.Version, events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// RunAggregateChecks asserts on emitted events without a server
+func RunAggregateChecks() {
+	checks := newChecker("aggregate")
+
+	// given: an order with one event applied
+	order := NewOrder("1")
+	created, err := order.Create("cust-1", 0)
+	checks.Check(err == nil, "Create failed: %v", err)
+	checks.Check(order.Status == "", "Create must not mutate the order, status = %q", order.Status)
+	checks.Check(order.EvolveAll(created) == nil, "evolve OrderCreated failed")
+
+	// when
+	events, err := order.AddItem("Widget", 25)
+
+	// then
+	checks.Check(err == nil, "AddItem failed: %v", err)
+	checks.Check(len(events) == 1 && events[0].EventType == "ItemAdded", "expected one ItemAdded, got %v", events)
+	if len(events) == 1 {
+		var added ItemAdded
+		checks.Check(json.Unmarshal(events[0].Data, &added) == nil && added == ItemAdded{Item: "Widget", Price: 25},
+			"unexpected ItemAdded payload %s", events[0].Data)
+		checks.Check(events[0].ContentType == kurrentdb.ContentTypeJson, "ItemAdded should be JSON")
+	}
+	checks.Check(len(order.Items) == 0 && order.Amount == 0, "AddItem must not mutate the order, items = %v", order.Items)
+
+	// rejections emit nothing
+	_, err = order.AddItem("Broken", -1)
+	checks.Check(errors.Is(err, ErrInvalidPrice), "negative price should be rejected, got %v", err)
+	_, err = order.Ship(time.Now())
+	checks.Check(errors.Is(err, ErrOrderEmpty), "shipping an empty order should be rejected, got %v", err)
+	_, err = NewOrder("2").AddItem("Widget", 25)
+	checks.Check(errors.Is(err, ErrOrderNotFound), "adding to a missing order should be rejected, got %v", err)
+
+	checks.Check(order.EvolveAll(events) == nil, "evolve ItemAdded failed")
+	checks.Check(order.Amount == 25 && len(order.Items) == 1, "evolve should add the item, got %.2f with %v", order.Amount, order.Items)
+
+	checks.Done()
+}
+
+// RunAggregate runs commands against a real order stream, including a concurrency conflict
+func RunAggregate() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	repository := NewOrderRepository(NewClientStore(client))
+	orderID := uuid.New().String()
+
+	// === COMMANDS ===
+	_, err := repository.Execute(ctx, orderID, func(o *Order) ([]kurrentdb.EventData, error) {
+		return o.Create("cust-1", 0)
+	})
+	if err != nil {
+		panic(err)
+	}
+	for _, item := range []ItemAdded{{"Widget", 25}, {"Gadget", 30}} {
+		_, err := repository.Execute(ctx, orderID, func(o *Order) ([]kurrentdb.EventData, error) {
+			return o.AddItem(item.Item, item.Price)
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	fmt.Printf("Created order %s with 2 items\n", orderID)
+
+	// === CONCURRENT WRITERS ===
+	// Both load the same version; the second save must be rejected
+	first, err := repository.Load(ctx, orderID)
+	if err != nil {
+		panic(err)
+	}
+	second, err := repository.Load(ctx, orderID)
+	if err != nil {
+		panic(err)
+	}
+
+	shipped, err := first.Ship(time.Now())
+	if err != nil {
+		panic(err)
+	}
+	if _, err := repository.Save(ctx, orderID, first.Version, shipped); err != nil {
+		panic(err)
+	}
+
+	late, err := second.AddItem("Late item", 5)
+	if err != nil {
+		panic(err)
+	}
+	_, conflict := repository.Save(ctx, orderID, second.Version, late)
+	fmt.Printf("Second writer: %v\n", conflict)
+
+	_, afterShip := repository.Execute(ctx, orderID, func(o *Order) ([]kurrentdb.EventData, error) {
+		return o.AddItem("Late item", 5)
+	})
+	fmt.Printf("Retry after reload: %v\n", afterShip)
+
+	final, err := repository.Load(ctx, orderID)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Final: status=%s amount=%.2f items=%v version=%d\n", final.Status, final.Amount, final.Items, final.Version)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if !errors.Is(conflict, ErrVersionConflict) {
+		fmt.Printf("FAIL: Stale save should fail with ErrVersionConflict, got %v\n", conflict)
+		passed = false
+	}
+	if !errors.Is(afterShip, ErrOrderShipped) {
+		fmt.Printf("FAIL: Reloaded command should be rejected as shipped, got %v\n", afterShip)
+		passed = false
+	}
+	if final.Status != "shipped" || final.Amount != 55 || len(final.Items) != 2 || final.Version != 3 {
+		fmt.Printf("FAIL: Expected shipped, 55.00, 2 items at version 3, got %s, %.2f, %v at %d\n", final.Status, final.Amount, final.Items, final.Version)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll aggregate tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,357 @@
+// KurrentDB Go Client Example - Global running aggregates projection
+// Demonstrates: Fleet-wide count, sum, min, max and average of order amounts, updated incrementally
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === AGGREGATES ===
+
+// OrderAggregates summarises the current amount of every order seen so far
+type OrderAggregates struct {
+	Count   int     `json:"count"`
+	Sum     float64 `json:"sum"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+	Average float64 `json:"average"`
+}
+
+// OrderAggregatesProjection is a global projection: one state across all order streams rather
+// than one per stream. It keeps each order's amount, because an ItemAdded changes an existing
+// order's amount instead of adding a new sample.
+type OrderAggregatesProjection struct {
+	Name       string
+	Checkpoint *kurrentdb.Position
+
+	amounts    map[string]float64
+	aggregates OrderAggregates
+	minStream  string
+}
+
+func NewOrderAggregatesProjection() *OrderAggregatesProjection {
+	return &OrderAggregatesProjection{
+		Name:    "OrderAggregates",
+		amounts: make(map[string]float64),
+	}
+}
+
+// Get returns the aggregates as of the checkpoint
+func (p *OrderAggregatesProjection) Get() OrderAggregates {
+	return p.aggregates
+}
+
+// Apply folds an OrderCreated or ItemAdded into the aggregates. Like Projection.Apply it returns
+// false for other event types and an error, with state and checkpoint unchanged, for events it
+// can't use: bad JSON, a missing or non-numeric amount, or an ItemAdded for an unknown order.
+func (p *OrderAggregatesProjection) Apply(event *kurrentdb.RecordedEvent, position kurrentdb.Position) (bool, error) {
+	var field string
+	switch event.EventType {
+	case "OrderCreated":
+		field = "amount"
+	case "ItemAdded":
+		field = "price"
+	default:
+		return false, nil
+	}
+
+	data, err := decodeNumbers(event.Data)
+	if err != nil {
+		return false, fmt.Errorf("decode %s on %s: %w", event.EventType, event.StreamID, err)
+	}
+	value, err := numberField(data, field)
+	if err != nil {
+		return false, fmt.Errorf("%s on %s: %w", event.EventType, event.StreamID, err)
+	}
+
+	previous, known := p.amounts[event.StreamID]
+	switch {
+	case event.EventType == "OrderCreated" && known:
+		return false, fmt.Errorf("duplicate OrderCreated on %s", event.StreamID)
+	case event.EventType == "ItemAdded" && !known:
+		return false, fmt.Errorf("ItemAdded on %s before OrderCreated", event.StreamID)
+	}
+
+	amount := value
+	if known {
+		amount = previous + value
+	}
+	p.update(event.StreamID, previous, amount, known)
+	p.Checkpoint = &position
+	return true, nil
+}
+
+// update adjusts the aggregates for one order's amount changing from previous to amount.
+// Count, sum and max are O(1). Min is too, except when the current minimum order changes,
+// which needs a rescan since the next-smallest order isn't tracked.
+func (p *OrderAggregatesProjection) update(stream string, previous, amount float64, known bool) {
+	p.amounts[stream] = amount
+	agg := &p.aggregates
+
+	if !known {
+		agg.Count++
+		agg.Sum += amount
+	} else {
+		agg.Sum += amount - previous
+	}
+
+	switch {
+	case agg.Count == 1:
+		agg.Min, agg.Max, p.minStream = amount, amount, stream
+	case stream == p.minStream && amount > previous:
+		p.rescanMin()
+	case amount < agg.Min:
+		agg.Min, p.minStream = amount, stream
+	}
+	if amount > agg.Max {
+		agg.Max = amount
+	} else if known && previous == agg.Max && amount < previous {
+		// Amounts only grow from ItemAdded with a positive price; a refund would take this path
+		p.rescanMax()
+	}
+
+	agg.Average = agg.Sum / float64(agg.Count)
+}
+
+func (p *OrderAggregatesProjection) rescanMin() {
+	p.aggregates.Min = math.Inf(1)
+	for stream, amount := range p.amounts {
+		if amount < p.aggregates.Min {
+			p.aggregates.Min, p.minStream = amount, stream
+		}
+	}
+}
+
+func (p *OrderAggregatesProjection) rescanMax() {
+	p.aggregates.Max = math.Inf(-1)
+	for _, amount := range p.amounts {
+		p.aggregates.Max = max(p.aggregates.Max, amount)
+	}
+}
+
+// === SAFE NUMBERS ===
+
+// decodeNumbers decodes a JSON object keeping numbers as json.Number, so numberField converts each
+// one explicitly instead of relying on encoding/json's default float64
+func decodeNumbers(raw []byte) (map[string]interface{}, error) {
+	data := make(map[string]interface{})
+	if len(raw) == 0 {
+		return data, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// numberField returns data[key] as a finite float64, or an error naming the field and its actual
+// type instead of panicking like data[key].(float64) does
+func numberField(data map[string]interface{}, key string) (float64, error) {
+	raw, ok := data[key]
+	if !ok || raw == nil {
+		return 0, fmt.Errorf("missing %q", key)
+	}
+
+	var value float64
+	switch v := raw.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number: %v", key, v)
+		}
+		value = f
+	case float64:
+		value = v
+	case float32:
+		value = float64(v)
+	case int:
+		value = float64(v)
+	case int64:
+		value = float64(v)
+	default:
+		return 0, fmt.Errorf("%q should be a number, got %T %v", key, raw, raw)
+	}
+
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("%q is not finite", key)
+	}
+	return value, nil
+}
+
+// RunAggregatesProjectionChecks feeds known events through the projection, no server required
+func RunAggregatesProjectionChecks() {
+	checks := newChecker("aggregates projection")
+
+	// === KNOWN INPUTS ===
+	fmt.Println("\n--- Known inputs ---")
+	{
+		projection := NewOrderAggregatesProjection()
+		checks.Check(projection.Get() == OrderAggregates{}, "empty projection should have zero aggregates, got %+v", projection.Get())
+
+		steps := []struct {
+			event    *kurrentdb.RecordedEvent
+			expected OrderAggregates
+		}{
+			{syntheticEvent("order-a", "OrderCreated", 0, 100, `{"amount":100}`), OrderAggregates{1, 100, 100, 100, 100}},
+			{syntheticEvent("order-b", "OrderCreated", 0, 200, `{"amount":40}`), OrderAggregates{2, 140, 40, 100, 70}},
+			{syntheticEvent("order-c", "OrderCreated", 0, 300, `{"amount":60}`), OrderAggregates{3, 200, 40, 100, 200.0 / 3}},
+			// The minimum order grows past another: min moves to order-c
+			{syntheticEvent("order-b", "ItemAdded", 1, 400, `{"item":"Widget","price":30}`), OrderAggregates{3, 230, 60, 100, 230.0 / 3}},
+			// A non-max order becomes the max
+			{syntheticEvent("order-c", "ItemAdded", 1, 500, `{"item":"Gadget","price":90}`), OrderAggregates{3, 320, 70, 150, 320.0 / 3}},
+			// Unrelated event types are ignored
+			{syntheticEvent("order-a", "OrderShipped", 1, 600, `{"shippedAt":"2024-01-15T10:00:00Z"}`), OrderAggregates{3, 320, 70, 150, 320.0 / 3}},
+		}
+
+		for i, step := range steps {
+			_, err := projection.Apply(step.event, step.event.Position)
+			got := projection.Get()
+			fmt.Printf("  %-12s %-7s -> %+v\n", step.event.EventType, step.event.StreamID, got)
+			checks.Check(err == nil, "step %d: %v", i, err)
+			checks.Check(got == step.expected, "step %d: expected %+v, got %+v", i, step.expected, got)
+		}
+		// As with Projection.Apply, ignored event types don't move the checkpoint
+		checks.Check(projection.Checkpoint != nil && projection.Checkpoint.Commit == 500, "checkpoint should follow the last applied event, got %v", projection.Checkpoint)
+	}
+
+	// === UNSAFE NUMBERS ===
+	fmt.Println("\n--- Malformed amounts ---")
+	{
+		projection := NewOrderAggregatesProjection()
+		created := syntheticEvent("order-a", "OrderCreated", 0, 100, `{"amount":100}`)
+		projection.Apply(created, created.Position)
+		before := projection.Get()
+
+		bad := []*kurrentdb.RecordedEvent{
+			syntheticEvent("order-a", "ItemAdded", 1, 200, `{"item":"Widget","price":"25"}`),
+			syntheticEvent("order-a", "ItemAdded", 2, 300, `{"item":"Widget"}`),
+			syntheticEvent("order-a", "ItemAdded", 3, 400, `{"item":"Widget","price":null}`),
+			syntheticEvent("order-x", "ItemAdded", 0, 500, `{"item":"Widget","price":5}`),
+			syntheticEvent("order-a", "OrderCreated", 4, 600, `{"amount":1}`),
+			syntheticEvent("order-b", "OrderCreated", 0, 700, `not json`),
+		}
+		for _, event := range bad {
+			applied, err := projection.Apply(event, event.Position)
+			fmt.Printf("  rejected: %v\n", err)
+			checks.Check(!applied && err != nil, "%s %s should be rejected without panicking", event.EventType, event.Data)
+		}
+		checks.Check(projection.Get() == before, "rejected events should leave the aggregates unchanged, got %+v", projection.Get())
+		checks.Check(projection.Checkpoint.Commit == 100, "rejected events should not advance the checkpoint, got %v", projection.Checkpoint)
+
+		big := syntheticEvent("order-big", "OrderCreated", 0, 800, `{"amount":12345678901234567890}`)
+		_, err := projection.Apply(big, big.Position)
+		checks.Check(err == nil && projection.Get().Max == 12345678901234567890, "large integer amounts should decode, got %v %+v", err, projection.Get())
+	}
+
+	checks.Done()
+}
+
+// RunAggregatesProjection appends a few orders and projects their aggregates from $all
+func RunAggregatesProjection() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	// === APPEND EVENTS ===
+	// A run-specific id prefix keeps other runs' orders out of this demo's aggregates
+	run := uuid.New().String()[:8]
+	orders := map[string][]float64{
+		Streams.Name("order", run+"-1"): {100, 25},
+		Streams.Name("order", run+"-2"): {50},
+		Streams.Name("order", run+"-3"): {10, 5, 5},
+	}
+
+	total := 0
+	for stream, amounts := range orders {
+		for i, amount := range amounts {
+			eventType, data := "OrderCreated", fmt.Sprintf(`{"amount":%v}`, amount)
+			if i > 0 {
+				eventType, data = "ItemAdded", fmt.Sprintf(`{"item":"Widget","price":%v}`, amount)
+			}
+			_, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+				EventID:     uuid.New(),
+				EventType:   eventType,
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        []byte(data),
+			})
+			if err != nil {
+				panic(err)
+			}
+			total++
+		}
+	}
+	fmt.Printf("Appended %d events to %d orders\n", total, len(orders))
+
+	// === RUN PROJECTION ===
+	projection := NewOrderAggregatesProjection()
+
+	subscription, err := client.SubscribeToAll(ctx, kurrentdb.SubscribeToAllOptions{
+		From: kurrentdb.Start{},
+		Filter: &kurrentdb.SubscriptionFilter{
+			Type:     kurrentdb.StreamFilterType,
+			Prefixes: []string{Streams.Name("order", run)},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	for applied := 0; applied < total; {
+		message := subscription.Recv()
+		if message.SubscriptionDropped != nil {
+			panic(message.SubscriptionDropped.Error)
+		}
+		if message.EventAppeared == nil {
+			continue
+		}
+
+		event := Resolve(message.EventAppeared, false)
+		ok, err := projection.Apply(event, Resolve(message.EventAppeared, true).Position)
+		if err != nil {
+			fmt.Printf("  Skipped: %v\n", err)
+		}
+		if ok {
+			applied++
+		}
+	}
+	subscription.Close()
+
+	aggregates := projection.Get()
+	result, _ := json.MarshalIndent(aggregates, "", "  ")
+	fmt.Printf("\n=== Aggregates ===\n%s\n", result)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	// Order amounts: 125, 50, 20
+	expected := OrderAggregates{Count: 3, Sum: 195, Min: 20, Max: 125, Average: 65}
+	if aggregates != expected {
+		fmt.Printf("FAIL: Expected %+v, got %+v\n", expected, aggregates)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll aggregates projection tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
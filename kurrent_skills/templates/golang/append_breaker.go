@@ -0,0 +1,689 @@
+// KurrentDB Go Client Example - Append retries with a retry budget and a circuit breaker
+// Demonstrates: Retrying failed appends within a budget, and failing fast while the server is down instead of piling retries onto it
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === CIRCUIT BREAKER ===
+
+var (
+	// ErrBreakerOpen is returned without calling the server while the breaker is open
+	ErrBreakerOpen = errors.New("circuit breaker open")
+	// ErrRetryBudgetExhausted wraps an append's last error when the budget had no retry left for it
+	ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+)
+
+// BreakerState is where a CircuitBreaker is in its cycle
+type BreakerState int
+
+const (
+	// BreakerClosed lets every call through and counts consecutive failures
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails every call fast until OpenFor has passed
+	BreakerOpen
+	// BreakerHalfOpen lets one probe through: its success closes the breaker, its failure reopens it
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+// BreakerStats is the breaker's state and what it has counted so far
+type BreakerStats struct {
+	State               BreakerState
+	ConsecutiveFailures int
+	// Opened counts the times the breaker opened, including reopening after a failed probe
+	Opened int64
+	// Rejected counts the calls failed fast while open or while a probe was in flight
+	Rejected  int64
+	Successes int64
+	Failures  int64
+	// OpenedAt is when the breaker last opened, zero if it never has
+	OpenedAt time.Time
+}
+
+// CircuitBreaker stops calls to a server that keeps failing. After FailureThreshold failures in a
+// row it opens and rejects calls with ErrBreakerOpen for OpenFor, then half-opens and lets a single
+// probe through to find out whether the server is back. Safe for concurrent use.
+//
+// Only failures that say the server can't be reached count, as decided by IsFailure: a wrong
+// expected version or an access denied is the server answering. Nor does a call whose own context
+// ended, which says nothing about the server.
+type CircuitBreaker struct {
+	// FailureThreshold is the consecutive failures that open the breaker
+	FailureThreshold int
+	// OpenFor is how long the breaker fails fast before probing
+	OpenFor time.Duration
+	// IsFailure decides which errors count against the server; nil uses isServerUnavailable
+	IsFailure func(error) bool
+
+	mu          sync.Mutex
+	state       BreakerState
+	consecutive int
+	openedAt    time.Time
+	probing     bool
+	// changed is closed and replaced whenever the breaker might admit a call it would have rejected
+	changed chan struct{}
+	now     func() time.Time
+
+	opened, rejected, successes, failures int64
+}
+
+// NewCircuitBreaker opens after threshold consecutive failures and stays open for openFor
+func NewCircuitBreaker(threshold int, openFor time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		FailureThreshold: max(threshold, 1),
+		OpenFor:          openFor,
+		changed:          make(chan struct{}),
+		now:              time.Now,
+	}
+}
+
+// Allow asks to make a call. It returns ctx's error if ctx has already ended, or ErrBreakerOpen if
+// the breaker is open; otherwise the caller makes the call and must pass its error, nil on success,
+// to done exactly once.
+func (b *CircuitBreaker) Allow(ctx context.Context) (done func(error), err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	probe := false
+	switch b.state {
+	case BreakerOpen:
+		if remaining := b.openedAt.Add(b.OpenFor).Sub(b.now()); remaining > 0 {
+			b.rejected++
+			return nil, fmt.Errorf("%w: probing in %s", ErrBreakerOpen, remaining.Round(time.Millisecond))
+		}
+		b.state = BreakerHalfOpen
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probing {
+			b.rejected++
+			return nil, fmt.Errorf("%w: a probe is in flight", ErrBreakerOpen)
+		}
+		b.probing, probe = true, true
+	}
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { b.record(ctx, probe, err) })
+	}, nil
+}
+
+// record applies a call's outcome. Only a probe moves a half-open breaker, and calls let through
+// before the breaker opened don't move it either way once it has.
+func (b *CircuitBreaker) record(ctx context.Context, probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		b.probing = false
+	}
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// The caller gave up; a probe that did leaves the next call to probe instead
+		if probe {
+			b.notify()
+		}
+	case err != nil && b.isFailure(err):
+		b.failures++
+		b.consecutive++
+		if probe || (b.state == BreakerClosed && b.consecutive >= b.FailureThreshold) {
+			b.state = BreakerOpen
+			b.openedAt = b.now()
+			b.opened++
+		}
+	default:
+		b.successes++
+		if b.state != BreakerOpen || probe {
+			b.consecutive = 0
+		}
+		if probe {
+			b.state = BreakerClosed
+			b.notify()
+		}
+	}
+}
+
+func (b *CircuitBreaker) isFailure(err error) bool {
+	if b.IsFailure != nil {
+		return b.IsFailure(err)
+	}
+	return isServerUnavailable(err)
+}
+
+// notify wakes everything in Wait; b.mu must be held
+func (b *CircuitBreaker) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// Wait blocks until the breaker would let a call through, or returns ctx's error if ctx ends
+// first. Callers that would rather wait out an outage than fail, like a background writer, call it
+// before Allow; it can still lose the probe to another caller, so Allow may still reject.
+func (b *CircuitBreaker) Wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		var remaining time.Duration
+		switch b.state {
+		case BreakerClosed:
+			b.mu.Unlock()
+			return nil
+		case BreakerOpen:
+			if remaining = b.openedAt.Add(b.OpenFor).Sub(b.now()); remaining <= 0 {
+				b.mu.Unlock()
+				return nil
+			}
+		case BreakerHalfOpen:
+			if !b.probing {
+				b.mu.Unlock()
+				return nil
+			}
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		var timer *time.Timer
+		var elapsed <-chan time.Time
+		if remaining > 0 {
+			timer = time.NewTimer(remaining)
+			elapsed = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			err := ctx.Err()
+			if timer != nil {
+				timer.Stop()
+			}
+			return err
+		case <-changed:
+		case <-elapsed:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// State returns the breaker's state; an open breaker whose OpenFor has passed reports half-open
+func (b *CircuitBreaker) State() BreakerState {
+	return b.Stats().State
+}
+
+// Stats returns the breaker's state and totals
+func (b *CircuitBreaker) Stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state := b.state
+	if state == BreakerOpen && !b.now().Before(b.openedAt.Add(b.OpenFor)) {
+		state = BreakerHalfOpen
+	}
+	return BreakerStats{
+		State:               state,
+		ConsecutiveFailures: b.consecutive,
+		Opened:              b.opened,
+		Rejected:            b.rejected,
+		Successes:           b.successes,
+		Failures:            b.failures,
+		OpenedAt:            b.openedAt,
+	}
+}
+
+// isServerUnavailable reports whether err suggests the server couldn't handle the request at all,
+// rather than answering it: anything but the errors the server returns about the request itself
+func isServerUnavailable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || isWrongExpectedVersion(err) || isStreamNotFound(err) {
+		return false
+	}
+	switch errorCode(err) {
+	case kurrentdb.ErrorCodeStreamDeleted, kurrentdb.ErrorCodeStreamTombstoned, kurrentdb.ErrorCodeAccessDenied,
+		kurrentdb.ErrorCodeUnauthenticated, kurrentdb.ErrorCodeAppendRecordSizeExceeded,
+		kurrentdb.ErrorCodeAppendTransactionSizeExceeded, kurrentdb.ErrorCodeUnsupportedFeature,
+		kurrentdb.ErrorCodeStreamRevisionConflict:
+		return false
+	}
+	return true
+}
+
+// === RETRY BUDGET ===
+
+// RetryBudget caps retries at a share of the calls made, so a struggling server sees at most
+// 1+Ratio times its normal traffic rather than MaxAttempts times. Every call deposits Ratio tokens
+// and every retry spends one; up to Max tokens are saved, so a few retries can follow a quiet spell.
+// Safe for concurrent use.
+type RetryBudget struct {
+	Ratio float64
+	Max   float64
+
+	mu     sync.Mutex
+	tokens float64
+	spent  int64
+	denied int64
+}
+
+// NewRetryBudget allows ratio retries per call with up to max saved, and starts full
+func NewRetryBudget(ratio, max float64) *RetryBudget {
+	return &RetryBudget{Ratio: ratio, Max: max, tokens: max}
+}
+
+func (b *RetryBudget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+b.Ratio, b.Max)
+}
+
+// withdraw spends a token for a retry, reporting false if there isn't a whole one
+func (b *RetryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		b.denied++
+		return false
+	}
+	b.tokens--
+	b.spent++
+	return true
+}
+
+// Tokens returns the retries the budget has saved
+func (b *RetryBudget) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens
+}
+
+// === RETRYING APPENDS ===
+
+// RetryingStore is an EventStore whose appends are retried on failures that IsFailure would count
+// against the server, with backoff, within MaxAttempts and the Budget, and only while the Breaker
+// lets them through. While the breaker is open appends fail with ErrBreakerOpen at once, without a
+// request, so callers shed load or queue work instead of stacking timeouts on a server that is
+// down. Reads and subscriptions pass straight through.
+//
+// A timed-out append may have landed. Retrying it is safe when the events keep their ids and the
+// expected state is a revision; see IdempotentBatchWriter for appends that need more than that.
+type RetryingStore struct {
+	EventStore
+	Breaker *CircuitBreaker
+	// Budget bounds retries across all appends; nil leaves only MaxAttempts
+	Budget      *RetryBudget
+	Backoff     Backoff
+	MaxAttempts int
+
+	appends, attempts, retries, budgetExhausted, shortCircuited, failed atomic.Int64
+}
+
+// NewRetryingStore tries each append up to 3 times, retrying at most one append in five
+func NewRetryingStore(store EventStore, breaker *CircuitBreaker) *RetryingStore {
+	return &RetryingStore{
+		EventStore:  store,
+		Breaker:     breaker,
+		Budget:      NewRetryBudget(0.2, 10),
+		Backoff:     NewBackoff(100*time.Millisecond, 2*time.Second),
+		MaxAttempts: 3,
+	}
+}
+
+// AppendToStream appends through the breaker, retrying as described on RetryingStore. When ctx
+// ends during a backoff it returns ctx's error.
+func (s *RetryingStore) AppendToStream(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+	s.appends.Add(1)
+	if s.Budget != nil {
+		s.Budget.deposit()
+	}
+
+	backoff := s.Backoff
+	backoff.Reset()
+	var lastErr error
+	for attempt := 1; attempt <= max(s.MaxAttempts, 1); attempt++ {
+		if attempt > 1 {
+			if s.Budget != nil && !s.Budget.withdraw() {
+				s.budgetExhausted.Add(1)
+				return nil, s.fail(fmt.Errorf("append to %s: %w: %w", stream, ErrRetryBudgetExhausted, lastErr))
+			}
+			s.retries.Add(1)
+			if err := backoff.Wait(ctx); err != nil {
+				return nil, s.fail(fmt.Errorf("append to %s: %w", stream, err))
+			}
+		}
+
+		done, err := s.Breaker.Allow(ctx)
+		if err != nil {
+			if errors.Is(err, ErrBreakerOpen) {
+				s.shortCircuited.Add(1)
+			}
+			return nil, s.fail(fmt.Errorf("append to %s: %w", stream, err))
+		}
+		s.attempts.Add(1)
+		result, err := s.EventStore.AppendToStream(ctx, stream, options, events...)
+		done(err)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil || !s.Breaker.isFailure(err) {
+			break
+		}
+	}
+	return nil, s.fail(lastErr)
+}
+
+func (s *RetryingStore) fail(err error) error {
+	s.failed.Add(1)
+	return err
+}
+
+// AppendRetrySnapshot is the JSON shape of RetryingStore's metrics
+type AppendRetrySnapshot struct {
+	BreakerState         string  `json:"breakerState"`
+	BreakerOpen          bool    `json:"breakerOpen"`
+	ConsecutiveFailures  int     `json:"consecutiveFailures"`
+	BreakerOpenedTotal   int64   `json:"breakerOpenedTotal"`
+	AppendsTotal         int64   `json:"appendsTotal"`
+	AttemptsTotal        int64   `json:"attemptsTotal"`
+	RetriesTotal         int64   `json:"retriesTotal"`
+	FailedTotal          int64   `json:"failedTotal"`
+	ShortCircuitedTotal  int64   `json:"shortCircuitedTotal"`
+	BudgetExhaustedTotal int64   `json:"budgetExhaustedTotal"`
+	RetryBudgetTokens    float64 `json:"retryBudgetTokens"`
+	SecondsSinceLastOpen float64 `json:"secondsSinceLastOpen,omitempty"`
+}
+
+// Snapshot returns the store's counters with the breaker's state
+func (s *RetryingStore) Snapshot() AppendRetrySnapshot {
+	breaker := s.Breaker.Stats()
+	snapshot := AppendRetrySnapshot{
+		BreakerState:         breaker.State.String(),
+		BreakerOpen:          breaker.State == BreakerOpen,
+		ConsecutiveFailures:  breaker.ConsecutiveFailures,
+		BreakerOpenedTotal:   breaker.Opened,
+		AppendsTotal:         s.appends.Load(),
+		AttemptsTotal:        s.attempts.Load(),
+		RetriesTotal:         s.retries.Load(),
+		FailedTotal:          s.failed.Load(),
+		ShortCircuitedTotal:  s.shortCircuited.Load(),
+		BudgetExhaustedTotal: s.budgetExhausted.Load(),
+	}
+	if s.Budget != nil {
+		snapshot.RetryBudgetTokens = s.Budget.Tokens()
+	}
+	if !breaker.OpenedAt.IsZero() {
+		snapshot.SecondsSinceLastOpen = time.Since(breaker.OpenedAt).Seconds()
+	}
+	return snapshot
+}
+
+// ServeHTTP exposes the snapshot as JSON, e.g. on /metrics/appends
+func (s *RetryingStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.Snapshot())
+}
+
+// outageStore fails every append with errOutage while down, standing in for an unreachable server
+type outageStore struct {
+	EventStore
+	down  atomic.Bool
+	calls atomic.Int64
+}
+
+// errOutage stands in for the client's error when the server can't be reached
+var errOutage = errors.New("connection refused")
+
+func (s *outageStore) AppendToStream(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+	s.calls.Add(1)
+	if s.down.Load() {
+		return nil, errOutage
+	}
+	return s.EventStore.AppendToStream(ctx, stream, options, events...)
+}
+
+func breakerEvent(sequence int) kurrentdb.EventData {
+	return kurrentdb.EventData{
+		EventID:     uuid.New(),
+		EventType:   "ReadingTaken",
+		ContentType: kurrentdb.ContentTypeJson,
+		Data:        []byte(fmt.Sprintf(`{"sequence":%d}`, sequence)),
+	}
+}
+
+// === CHECKS ===
+
+// RunAppendBreakerChecks drives the breaker through an outage on a fake clock, and the retrying
+// store's budget and cancellation, without a server
+func RunAppendBreakerChecks() {
+	checks := newChecker("append breaker")
+
+	ctx := context.Background()
+	clock := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	newBreaker := func(threshold int) *CircuitBreaker {
+		breaker := NewCircuitBreaker(threshold, 10*time.Second)
+		breaker.now = func() time.Time { return clock }
+		return breaker
+	}
+	newStore := func(breaker *CircuitBreaker) (*RetryingStore, *outageStore) {
+		inner := &outageStore{EventStore: NewMemoryEventStore()}
+		store := NewRetryingStore(inner, breaker)
+		store.Backoff = Backoff{Base: time.Millisecond, Max: 2 * time.Millisecond, Multiplier: 2}
+		store.Budget = nil
+		return store, inner
+	}
+
+	fmt.Println("\n--- Opening ---")
+	breaker := newBreaker(3)
+	store, inner := newStore(breaker)
+	_, err := store.AppendToStream(ctx, "reading-1", kurrentdb.AppendToStreamOptions{}, breakerEvent(1))
+	checks.Check(err == nil && breaker.State() == BreakerClosed, "a healthy append should leave the breaker closed, got %v %s", err, breaker.State())
+	_, err = store.AppendToStream(ctx, "reading-1", kurrentdb.AppendToStreamOptions{StreamState: kurrentdb.NoStream{}}, breakerEvent(2))
+	checks.Check(isWrongExpectedVersion(err) && inner.calls.Load() == 2, "a wrong expected version shouldn't be retried, got %v after %d calls", err, inner.calls.Load())
+	checks.Check(breaker.Stats().ConsecutiveFailures == 0, "a wrong expected version is the server answering, not a failure")
+
+	inner.down.Store(true)
+	_, err = store.AppendToStream(ctx, "reading-1", kurrentdb.AppendToStreamOptions{}, breakerEvent(3))
+	checks.Check(errors.Is(err, errOutage) && inner.calls.Load() == 5, "an append should be tried 3 times, got %v after %d calls", err, inner.calls.Load())
+	checks.Check(breaker.State() == BreakerOpen && breaker.Stats().Opened == 1, "3 failures in a row should open the breaker, got %+v", breaker.Stats())
+
+	fmt.Println("\n--- Failing fast ---")
+	for i := 0; i < 5; i++ {
+		_, err = store.AppendToStream(ctx, "reading-1", kurrentdb.AppendToStreamOptions{}, breakerEvent(4+i))
+		checks.Check(errors.Is(err, ErrBreakerOpen), "an open breaker should fail fast, got %v", err)
+	}
+	checks.Check(inner.calls.Load() == 5, "an open breaker shouldn't reach the server, got %d calls", inner.calls.Load())
+	snapshot := store.Snapshot()
+	checks.Check(snapshot.BreakerState == "open" && snapshot.ShortCircuitedTotal == 5 && snapshot.FailedTotal == 7,
+		"the snapshot should report the open breaker and what it rejected, got %+v", snapshot)
+	raw, _ := json.Marshal(snapshot)
+	checks.Check(strings.Contains(string(raw), `"breakerState":"open"`), "the JSON snapshot should carry the state, got %s", raw)
+
+	fmt.Println("\n--- Half-open probes ---")
+	clock = clock.Add(10 * time.Second)
+	checks.Check(breaker.State() == BreakerHalfOpen, "after OpenFor the breaker should report half-open, got %s", breaker.State())
+	done, err := breaker.Allow(ctx)
+	checks.Check(err == nil, "the first call after OpenFor should be let through as a probe: %v", err)
+	_, second := breaker.Allow(ctx)
+	checks.Check(errors.Is(second, ErrBreakerOpen), "a second call while the probe is in flight should fail fast, got %v", second)
+	done(errOutage)
+	checks.Check(breaker.State() == BreakerOpen && breaker.Stats().Opened == 2, "a failed probe should reopen the breaker, got %+v", breaker.Stats())
+
+	clock = clock.Add(10 * time.Second)
+	cancelled, cancel := context.WithCancel(ctx)
+	done, err = breaker.Allow(cancelled)
+	checks.Check(err == nil, "the probe should be let through: %v", err)
+	cancel()
+	done(context.Canceled)
+	checks.Check(breaker.State() == BreakerHalfOpen, "a probe its caller gave up on should leave the breaker half-open, got %s", breaker.State())
+	_, err = breaker.Allow(cancelled)
+	checks.Check(errors.Is(err, context.Canceled), "Allow should return an ended context's error, got %v", err)
+
+	inner.down.Store(false)
+	_, err = store.AppendToStream(ctx, "reading-1", kurrentdb.AppendToStreamOptions{}, breakerEvent(9))
+	checks.Check(err == nil && breaker.State() == BreakerClosed, "a successful probe should close the breaker, got %v %s", err, breaker.State())
+	checks.Check(breaker.Stats().ConsecutiveFailures == 0, "closing should reset the failure count")
+
+	fmt.Println("\n--- Waiting while open ---")
+	waiting := NewCircuitBreaker(1, time.Hour)
+	done, _ = waiting.Allow(ctx)
+	done(errOutage)
+	deadline, cancelWait := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancelWait()
+	started := time.Now()
+	err = waiting.Wait(deadline)
+	checks.Check(errors.Is(err, context.DeadlineExceeded) && time.Since(started) < time.Second,
+		"Wait should return when its context ends, got %v after %s", err, time.Since(started))
+
+	short := NewCircuitBreaker(1, 20*time.Millisecond)
+	done, _ = short.Allow(ctx)
+	done(errOutage)
+	checks.Check(short.Wait(ctx) == nil && short.State() == BreakerHalfOpen, "Wait should return once the breaker can probe")
+	probe, _ := short.Allow(ctx)
+	woken := make(chan error, 1)
+	go func() { woken <- short.Wait(ctx) }()
+	time.Sleep(10 * time.Millisecond)
+	probe(nil)
+	select {
+	case err = <-woken:
+		checks.Check(err == nil && short.State() == BreakerClosed, "Wait should return when the probe closes the breaker, got %v", err)
+	case <-time.After(time.Second):
+		checks.Check(false, "Wait should wake when the probe closes the breaker")
+	}
+
+	fmt.Println("\n--- Retry budget ---")
+	budgeted, budgetedInner := newStore(newBreaker(100))
+	budgeted.Budget = NewRetryBudget(0.5, 1)
+	budgetedInner.down.Store(true)
+	_, err = budgeted.AppendToStream(ctx, "reading-2", kurrentdb.AppendToStreamOptions{}, breakerEvent(1))
+	checks.Check(errors.Is(err, ErrRetryBudgetExhausted) && errors.Is(err, errOutage) && budgetedInner.calls.Load() == 2,
+		"a full budget of 1 should allow one retry, got %v after %d calls", err, budgetedInner.calls.Load())
+	_, err = budgeted.AppendToStream(ctx, "reading-2", kurrentdb.AppendToStreamOptions{}, breakerEvent(2))
+	checks.Check(errors.Is(err, ErrRetryBudgetExhausted) && budgetedInner.calls.Load() == 3,
+		"half a token shouldn't buy a retry, got %v after %d calls", err, budgetedInner.calls.Load())
+	_, err = budgeted.AppendToStream(ctx, "reading-2", kurrentdb.AppendToStreamOptions{}, breakerEvent(3))
+	checks.Check(errors.Is(err, ErrRetryBudgetExhausted) && budgetedInner.calls.Load() == 5,
+		"two deposits of half should buy one more retry, got %v after %d calls", err, budgetedInner.calls.Load())
+	checks.Check(budgeted.Snapshot().BudgetExhaustedTotal == 3 && budgeted.Snapshot().RetriesTotal == 2,
+		"the snapshot should count retries and exhaustion, got %+v", budgeted.Snapshot())
+
+	fmt.Println("\n--- Cancellation ---")
+	slow, slowInner := newStore(newBreaker(100))
+	slow.Backoff = NewBackoff(time.Hour, time.Hour)
+	slowInner.down.Store(true)
+	deadline, cancelSlow := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancelSlow()
+	started = time.Now()
+	_, err = slow.AppendToStream(deadline, "reading-3", kurrentdb.AppendToStreamOptions{}, breakerEvent(1))
+	checks.Check(errors.Is(err, context.DeadlineExceeded) && time.Since(started) < time.Second && slowInner.calls.Load() == 1,
+		"an append should stop backing off when its context ends, got %v after %s and %d calls", err, time.Since(started), slowInner.calls.Load())
+
+	checks.Done()
+}
+
+// === DEMO ===
+
+// RunAppendBreaker appends readings through a breaker while a simulated outage comes and goes
+func RunAppendBreaker() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	inner := &outageStore{EventStore: NewClientStore(client)}
+	breaker := NewCircuitBreaker(5, 500*time.Millisecond)
+	store := NewRetryingStore(inner, breaker)
+	store.Backoff = NewBackoff(10*time.Millisecond, 50*time.Millisecond)
+	stream := Streams.Name("reading", uuid.New().String())
+
+	appendReadings := func(phase string, count int) (written int, fastFailures int, elapsed time.Duration) {
+		started := time.Now()
+		callsBefore := inner.calls.Load()
+		for i := 0; i < count; i++ {
+			_, err := store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, breakerEvent(i))
+			switch {
+			case err == nil:
+				written++
+			case errors.Is(err, ErrBreakerOpen):
+				fastFailures++
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		elapsed = time.Since(started)
+		fmt.Printf("%-9s %2d written, %2d failed fast, %2d server calls in %s, breaker %s\n",
+			phase+":", written, fastFailures, inner.calls.Load()-callsBefore, elapsed.Round(10*time.Millisecond), breaker.State())
+		return written, fastFailures, elapsed
+	}
+
+	healthyWritten, _, _ := appendReadings("healthy", 10)
+
+	inner.down.Store(true)
+	callsBefore := inner.calls.Load()
+	_, outageFast, _ := appendReadings("outage", 40)
+	outageCalls := inner.calls.Load() - callsBefore
+	outageSnapshot := store.Snapshot()
+	metrics, _ := json.Marshal(outageSnapshot)
+	fmt.Printf("metrics during the outage: %s\n", metrics)
+
+	inner.down.Store(false)
+	waitErr := breaker.Wait(ctx)
+	recoveredWritten, _, _ := appendReadings("recovered", 10)
+
+	stored, _, readErr := readStreamFrom(ctx, inner, stream, NoVersion)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+	if healthyWritten != 10 {
+		fmt.Printf("FAIL: Every append before the outage should succeed, got %d\n", healthyWritten)
+		passed = false
+	}
+	if outageSnapshot.BreakerOpenedTotal == 0 || outageFast == 0 {
+		fmt.Printf("FAIL: The outage should open the breaker and fail appends fast, got %+v\n", outageSnapshot)
+		passed = false
+	}
+	if outageCalls >= 40 {
+		fmt.Printf("FAIL: The breaker should keep most outage appends off the server, got %d calls for 40 appends\n", outageCalls)
+		passed = false
+	}
+	if waitErr != nil || recoveredWritten != 10 || breaker.State() != BreakerClosed {
+		fmt.Printf("FAIL: After the outage the breaker should close and appends succeed, got %d written, %s (%v)\n", recoveredWritten, breaker.State(), waitErr)
+		passed = false
+	}
+	if readErr != nil || len(stored) != healthyWritten+recoveredWritten {
+		fmt.Printf("FAIL: The stream should hold only the written readings, got %d (%v)\n", len(stored), readErr)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll append breaker tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,372 @@
+// KurrentDB Go Client Example - Append size guardrails
+// Demonstrates: Rejecting oversized events and batches before the network call, and splitting large batches
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === APPEND LIMITS ===
+
+// The errors LimitedStore returns instead of sending an append the server would reject
+var (
+	ErrEventTooLarge = errors.New("event too large")
+	ErrBatchTooLarge = errors.New("batch too large")
+)
+
+// serverMaxAppendSize is the server's default MaxAppendSize, the most one append may carry
+const serverMaxAppendSize = 1024 * 1024
+
+// AppendLimits bounds what one append may carry. Sizes count an event's data and metadata, which is
+// nearly all of what the server measures; its IDs and type add a little more, so keep the limits a
+// little below the server's. A zero limit is not enforced.
+type AppendLimits struct {
+	// MaxEventBytes caps a single event
+	MaxEventBytes int
+	// MaxBatchEvents and MaxBatchBytes cap a whole append
+	MaxBatchEvents int
+	MaxBatchBytes  int
+	// Split appends a batch over MaxBatchEvents or MaxBatchBytes as consecutive smaller appends
+	// rather than rejecting it. The batch is then no longer atomic: see LimitedStore.AppendToStream.
+	Split bool
+}
+
+// DefaultAppendLimits keeps every event and append within the server's default max append size,
+// leaving 64KB of headroom for what the sizes don't count
+func DefaultAppendLimits() AppendLimits {
+	return AppendLimits{
+		MaxEventBytes: serverMaxAppendSize - 64*1024,
+		MaxBatchBytes: serverMaxAppendSize - 64*1024,
+	}
+}
+
+// EventSize is the size an event counts for against AppendLimits
+func EventSize(event kurrentdb.EventData) int {
+	return len(event.Data) + len(event.Metadata)
+}
+
+// Batches checks events against the limits and returns them as the appends to make: one batch, or
+// with Split as many as needed, each filled in order up to the limits. It returns ErrEventTooLarge
+// for an event over MaxEventBytes, or one that alone exceeds MaxBatchBytes, since no split can send
+// it; and ErrBatchTooLarge for a batch over the batch limits when Split is off.
+func (l AppendLimits) Batches(stream string, events []kurrentdb.EventData) ([][]kurrentdb.EventData, error) {
+	total := 0
+	for i, event := range events {
+		size := EventSize(event)
+		if l.MaxEventBytes > 0 && size > l.MaxEventBytes {
+			return nil, fmt.Errorf("%w: %s event %d (%s) is %d bytes, the limit is %d",
+				ErrEventTooLarge, stream, i, event.EventType, size, l.MaxEventBytes)
+		}
+		if l.Split && l.MaxBatchBytes > 0 && size > l.MaxBatchBytes {
+			return nil, fmt.Errorf("%w: %s event %d (%s) is %d bytes, more than a whole batch may carry (%d), so it can't be split off",
+				ErrEventTooLarge, stream, i, event.EventType, size, l.MaxBatchBytes)
+		}
+		total += size
+	}
+
+	overCount := l.MaxBatchEvents > 0 && len(events) > l.MaxBatchEvents
+	overBytes := l.MaxBatchBytes > 0 && total > l.MaxBatchBytes
+	if !overCount && !overBytes {
+		return [][]kurrentdb.EventData{events}, nil
+	}
+	if !l.Split {
+		return nil, fmt.Errorf("%w: %s batch is %d events and %d bytes, the limits are %s",
+			ErrBatchTooLarge, stream, len(events), total, l.batchLimits())
+	}
+
+	var batches [][]kurrentdb.EventData
+	start, batchBytes := 0, 0
+	for i, event := range events {
+		size := EventSize(event)
+		full := (l.MaxBatchEvents > 0 && i-start == l.MaxBatchEvents) || (l.MaxBatchBytes > 0 && batchBytes+size > l.MaxBatchBytes)
+		if full {
+			batches = append(batches, events[start:i])
+			start, batchBytes = i, 0
+		}
+		batchBytes += size
+	}
+	return append(batches, events[start:]), nil
+}
+
+func (l AppendLimits) batchLimits() string {
+	var limits []string
+	if l.MaxBatchEvents > 0 {
+		limits = append(limits, fmt.Sprintf("%d events", l.MaxBatchEvents))
+	}
+	if l.MaxBatchBytes > 0 {
+		limits = append(limits, fmt.Sprintf("%d bytes", l.MaxBatchBytes))
+	}
+	return strings.Join(limits, " and ")
+}
+
+// LimitedStore is an EventStore whose appends are checked against AppendLimits first, so an
+// oversized append fails with a clear error naming the event and limit instead of a round trip to
+// a server that rejects it. Reads and subscriptions pass straight through.
+type LimitedStore struct {
+	EventStore
+	Limits AppendLimits
+}
+
+func NewLimitedStore(store EventStore, limits AppendLimits) *LimitedStore {
+	return &LimitedStore{EventStore: store, Limits: limits}
+}
+
+// AppendToStream appends events in the batches Limits allows. The first batch is appended with
+// options' StreamState and every later one expects the revision the one before left, so a split
+// append still fails if another writer gets in between, and its events stay contiguous and in order.
+//
+// A split append is not atomic. If a later batch fails, the earlier ones stay appended, and the
+// error reports how many events were; append with a StreamState so a retry of the whole batch fails
+// rather than duplicating them, or retry the rest. The result is the last batch's.
+func (s *LimitedStore) AppendToStream(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+	batches, err := s.Limits.Batches(stream, events)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *kurrentdb.WriteResult
+	appended := 0
+	for i, batch := range batches {
+		if i > 0 {
+			options.StreamState = kurrentdb.StreamRevision{Value: result.NextExpectedVersion}
+		}
+		result, err = s.EventStore.AppendToStream(ctx, stream, options, batch...)
+		if err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			return nil, fmt.Errorf("append batch %d of %d to %s after %d of %d events were appended: %w",
+				i+1, len(batches), stream, appended, len(events), err)
+		}
+		appended += len(batch)
+	}
+	return result, nil
+}
+
+// countingStore records the appends that reach the store it wraps
+type countingStore struct {
+	EventStore
+
+	mu      sync.Mutex
+	appends []int
+	// fail, when set, fails the append with that index
+	fail func(index int) error
+}
+
+func (s *countingStore) AppendToStream(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+	s.mu.Lock()
+	index := len(s.appends)
+	s.appends = append(s.appends, len(events))
+	s.mu.Unlock()
+
+	if s.fail != nil {
+		if err := s.fail(index); err != nil {
+			return nil, err
+		}
+	}
+	return s.EventStore.AppendToStream(ctx, stream, options, events...)
+}
+
+func (s *countingStore) calls() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.appends...)
+}
+
+// sizedEvent is an event with a data payload of exactly size bytes
+func sizedEvent(eventType string, size int) kurrentdb.EventData {
+	return kurrentdb.EventData{
+		EventID:     uuid.New(),
+		EventType:   eventType,
+		ContentType: kurrentdb.ContentTypeBinary,
+		Data:        bytes.Repeat([]byte{'x'}, size),
+	}
+}
+
+// sizedEvents is count events of size bytes each
+func sizedEvents(count, size int) []kurrentdb.EventData {
+	events := make([]kurrentdb.EventData, count)
+	for i := range events {
+		events[i] = sizedEvent("Sized", size)
+	}
+	return events
+}
+
+// RunAppendLimitsChecks verifies the limits at and just past every boundary, and splitting, without
+// a server
+func RunAppendLimitsChecks() {
+	checks := newChecker("append limits")
+
+	ctx := context.Background()
+	limits := AppendLimits{MaxEventBytes: 100, MaxBatchEvents: 5, MaxBatchBytes: 300}
+	// attempt appends through fresh stores and reports the error and the appends that were sent
+	attempt := func(limits AppendLimits, events []kurrentdb.EventData) ([]int, error) {
+		counter := &countingStore{EventStore: NewMemoryEventStore()}
+		_, err := NewLimitedStore(counter, limits).AppendToStream(ctx, "limits-1", kurrentdb.AppendToStreamOptions{}, events...)
+		return counter.calls(), err
+	}
+
+	fmt.Println("\n--- Event size ---")
+	calls, err := attempt(limits, []kurrentdb.EventData{sizedEvent("AtLimit", 100)})
+	checks.Check(err == nil && len(calls) == 1, "An event of exactly MaxEventBytes should be appended, got %v", err)
+	withMetadata := sizedEvent("WithMetadata", 90)
+	withMetadata.Metadata = bytes.Repeat([]byte{'m'}, 10)
+	_, err = attempt(limits, []kurrentdb.EventData{withMetadata})
+	checks.Check(err == nil, "Data and metadata adding up to MaxEventBytes should be appended, got %v", err)
+	withMetadata.Metadata = append(withMetadata.Metadata, 'm')
+	calls, err = attempt(limits, []kurrentdb.EventData{withMetadata})
+	checks.Check(errors.Is(err, ErrEventTooLarge), "Metadata should count towards MaxEventBytes, got %v", err)
+	calls, err = attempt(limits, []kurrentdb.EventData{sizedEvent("Small", 10), sizedEvent("OverLimit", 101)})
+	fmt.Printf("  %v\n", err)
+	checks.Check(errors.Is(err, ErrEventTooLarge), "An event one byte over MaxEventBytes should fail with ErrEventTooLarge, got %v", err)
+	checks.Check(err != nil && strings.Contains(err.Error(), "event 1 (OverLimit)") && strings.Contains(err.Error(), "101 bytes"),
+		"The error should name the event and its size, got %v", err)
+	checks.Check(len(calls) == 0, "A rejected batch should not reach the store, got %v", calls)
+
+	fmt.Println("\n--- Batch count ---")
+	calls, err = attempt(limits, sizedEvents(5, 10))
+	checks.Check(err == nil && len(calls) == 1, "A batch of exactly MaxBatchEvents should be one append, got %v after %v", err, calls)
+	calls, err = attempt(limits, sizedEvents(6, 10))
+	fmt.Printf("  %v\n", err)
+	checks.Check(errors.Is(err, ErrBatchTooLarge) && len(calls) == 0, "A batch one over MaxBatchEvents should fail before the store, got %v after %v", err, calls)
+
+	fmt.Println("\n--- Batch bytes ---")
+	calls, err = attempt(limits, sizedEvents(3, 100))
+	checks.Check(err == nil && len(calls) == 1, "A batch of exactly MaxBatchBytes should be one append, got %v after %v", err, calls)
+	calls, err = attempt(limits, append(sizedEvents(2, 100), sizedEvent("Sized", 100), sizedEvent("Sized", 1)))
+	fmt.Printf("  %v\n", err)
+	checks.Check(errors.Is(err, ErrBatchTooLarge) && len(calls) == 0, "A batch one byte over MaxBatchBytes should fail before the store, got %v after %v", err, calls)
+
+	calls, err = attempt(AppendLimits{}, sizedEvents(1000, 2000))
+	checks.Check(err == nil && len(calls) == 1, "Zero limits should not be enforced, got %v after %v", err, calls)
+
+	fmt.Println("\n--- Splitting ---")
+	splitting := limits
+	splitting.Split = true
+
+	calls, err = attempt(splitting, sizedEvents(5, 10))
+	checks.Check(err == nil && fmt.Sprint(calls) == "[5]", "A batch within the limits should not be split, got %v", calls)
+	calls, err = attempt(splitting, sizedEvents(12, 10))
+	fmt.Printf("  12 small events: appends of %v\n", calls)
+	checks.Check(err == nil && fmt.Sprint(calls) == "[5 5 2]", "A batch over MaxBatchEvents should split by count, got %v after %v", err, calls)
+	calls, err = attempt(splitting, sizedEvents(7, 100))
+	fmt.Printf("  7 events of 100 bytes: appends of %v\n", calls)
+	checks.Check(err == nil && fmt.Sprint(calls) == "[3 3 1]", "A batch over MaxBatchBytes should split by size, got %v after %v", err, calls)
+	calls, err = attempt(splitting, []kurrentdb.EventData{sizedEvent("A", 40), sizedEvent("B", 60), sizedEvent("C", 100), sizedEvent("D", 100), sizedEvent("E", 100), sizedEvent("F", 50)})
+	checks.Check(err == nil && fmt.Sprint(calls) == "[4 2]", "Batches should fill up to MaxBatchBytes exactly, got %v after %v", err, calls)
+
+	// Order and revisions survive the split
+	memory := NewMemoryEventStore()
+	events := sizedEvents(12, 10)
+	result, err := NewLimitedStore(memory, splitting).AppendToStream(ctx, "limits-2", kurrentdb.AppendToStreamOptions{StreamState: kurrentdb.NoStream{}}, events...)
+	checks.Check(err == nil && result != nil && result.NextExpectedVersion == 11, "A split append should return the last batch's result, got %+v (%v)", result, err)
+	stored, _, err := readStreamFrom(ctx, memory, "limits-2", NoVersion)
+	checks.Check(err == nil && len(stored) == len(events), "Every event of a split batch should be appended, got %d (%v)", len(stored), err)
+	for i := range stored {
+		checks.Check(i < len(events) && stored[i].EventID == events[i].EventID, "Event %d should keep its place after splitting", i)
+	}
+
+	fmt.Println("\n--- Single event over the limit ---")
+	calls, err = attempt(splitting, append(sizedEvents(3, 10), sizedEvent("Huge", 101)))
+	fmt.Printf("  %v\n", err)
+	checks.Check(errors.Is(err, ErrEventTooLarge) && len(calls) == 0, "Splitting can't help an event over MaxEventBytes, got %v after %v", err, calls)
+	roomy := AppendLimits{MaxEventBytes: 500, MaxBatchBytes: 300, Split: true}
+	calls, err = attempt(roomy, []kurrentdb.EventData{sizedEvent("Small", 10), sizedEvent("Huge", 301)})
+	fmt.Printf("  %v\n", err)
+	checks.Check(errors.Is(err, ErrEventTooLarge) && len(calls) == 0, "An event bigger than a whole batch can't be split off, got %v after %v", err, calls)
+
+	fmt.Println("\n--- Split failure ---")
+	errOutage := errors.New("unavailable")
+	counter := &countingStore{EventStore: NewMemoryEventStore(), fail: func(index int) error {
+		if index == 1 {
+			return errOutage
+		}
+		return nil
+	}}
+	_, err = NewLimitedStore(counter, splitting).AppendToStream(ctx, "limits-3", kurrentdb.AppendToStreamOptions{StreamState: kurrentdb.NoStream{}}, sizedEvents(12, 10)...)
+	fmt.Printf("  %v\n", err)
+	checks.Check(errors.Is(err, errOutage), "A failed later batch should wrap the store's error, got %v", err)
+	checks.Check(err != nil && strings.Contains(err.Error(), "after 5 of 12 events"), "The error should say how much was appended, got %v", err)
+	checks.Check(fmt.Sprint(counter.calls()) == "[5 5]", "No batch should be tried after a failure, got %v", counter.calls())
+
+	checks.Done()
+}
+
+// RunAppendLimits appends through the default limits: a batch over the server's max append size is
+// split, and an event over it fails without a round trip
+func RunAppendLimits() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	counter := &countingStore{EventStore: NewClientStore(client)}
+	limits := DefaultAppendLimits()
+	limits.Split = true
+	store := NewLimitedStore(counter, limits)
+
+	// 3MB of 16KB events: three times what one append may carry
+	stream := Streams.Name("upload", uuid.New().String())
+	events := sizedEvents(192, 16*1024)
+	result, splitErr := store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{StreamState: kurrentdb.NoStream{}}, events...)
+	fmt.Printf("Appended %d events of 16KB to %s in %d appends of %v events\n", len(events), stream, len(counter.calls()), counter.calls())
+
+	stored, _, readErr := readStreamFrom(ctx, store, stream, NoVersion)
+
+	before := len(counter.calls())
+	_, hugeErr := store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, sizedEvent("Huge", 2*serverMaxAppendSize))
+	fmt.Printf("A 2MB event: %v\n", hugeErr)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+	if splitErr != nil || result == nil || result.NextExpectedVersion != uint64(len(events)-1) {
+		fmt.Printf("FAIL: The split append should end at revision %d, got %+v (%v)\n", len(events)-1, result, splitErr)
+		passed = false
+	}
+	if len(counter.calls()) < 4 {
+		fmt.Printf("FAIL: 3MB should take at least 4 appends under the default limits, took %v\n", counter.calls())
+		passed = false
+	}
+	if readErr != nil || len(stored) != len(events) {
+		fmt.Printf("FAIL: Every event should be stored, got %d (%v)\n", len(stored), readErr)
+		passed = false
+	}
+	for i := range stored {
+		if stored[i].EventID != events[i].EventID {
+			fmt.Printf("FAIL: Event %d is out of order\n", i)
+			passed = false
+			break
+		}
+	}
+	if !errors.Is(hugeErr, ErrEventTooLarge) {
+		fmt.Printf("FAIL: A 2MB event should fail with ErrEventTooLarge, got %v\n", hugeErr)
+		passed = false
+	}
+	if len(counter.calls()) != before {
+		fmt.Println("FAIL: The 2MB event should not have been sent")
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll append limits tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,171 @@
+// KurrentDB Go Client Example - Append returning the $all position
+// Demonstrates: Getting the commit position of a write and using it to start a tailing subscription
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === APPEND AND POSITION ===
+
+// AppendPosition is where an append landed
+type AppendPosition struct {
+	// NextExpectedVersion is the stream revision of the last appended event
+	NextExpectedVersion uint64
+	// Position is the $all position of the last appended event
+	Position kurrentdb.Position
+	// FromWriteResult is false when the server didn't report a position and it was read back
+	FromWriteResult bool
+}
+
+// AppendAndPosition appends events and returns both the stream revision and the $all position of
+// the last one, e.g. to checkpoint downstream or to wait for a subscriber to catch up to this write.
+//
+// The server reports the position in the write result for every append that writes events. It
+// reports none (0/0) when the append wrote nothing: an idempotent retry whose event ids were already
+// written. In that case the position is read back from the stream's last event, which is only the
+// same event if nobody appended since.
+func AppendAndPosition(ctx context.Context, client *kurrentdb.Client, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (AppendPosition, error) {
+	result, err := client.AppendToStream(ctx, stream, options, events...)
+	if err != nil {
+		return AppendPosition{}, err
+	}
+
+	appended := AppendPosition{NextExpectedVersion: result.NextExpectedVersion}
+	if result.CommitPosition != 0 || result.PreparePosition != 0 {
+		appended.Position = kurrentdb.Position{Commit: result.CommitPosition, Prepare: result.PreparePosition}
+		appended.FromWriteResult = true
+		return appended, nil
+	}
+
+	position, err := lastEventPosition(ctx, client, stream)
+	if err != nil {
+		return appended, fmt.Errorf("append succeeded but its position is unknown: %w", err)
+	}
+	appended.Position = position
+	return appended, nil
+}
+
+// lastEventPosition returns the $all position of the last event in stream
+func lastEventPosition(ctx context.Context, client *kurrentdb.Client, stream string) (kurrentdb.Position, error) {
+	event, err := LastEvent(ctx, client, stream)
+	if err != nil {
+		return kurrentdb.Position{}, err
+	}
+	if event == nil {
+		return kurrentdb.Position{}, fmt.Errorf("stream %s is empty", stream)
+	}
+	return event.Position, nil
+}
+
+// RunAppendPosition starts a tailing subscription right after a write and checks positions line up
+func RunAppendPosition() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	streamName := Streams.Name("order", uuid.New().String())
+	event := func(eventType string) kurrentdb.EventData {
+		return kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(`{}`),
+		}
+	}
+
+	// === APPEND AND GET THE POSITION ===
+	created, err := AppendAndPosition(ctx, client, streamName, kurrentdb.AppendToStreamOptions{StreamState: kurrentdb.NoStream{}}, event("OrderCreated"))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("OrderCreated at revision %d, position %d/%d\n", created.NextExpectedVersion, created.Position.Commit, created.Position.Prepare)
+
+	// === TAIL FROM THAT POSITION ===
+	// Subscribing from a position is exclusive, so the tail starts with the next write
+	subscription, err := client.SubscribeToAll(ctx, kurrentdb.SubscribeToAllOptions{
+		From: created.Position,
+		Filter: &kurrentdb.SubscriptionFilter{
+			Type:     kurrentdb.StreamFilterType,
+			Prefixes: []string{streamName},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer subscription.Close()
+
+	itemAdded := event("ItemAdded")
+	added, err := AppendAndPosition(ctx, client, streamName, kurrentdb.AppendToStreamOptions{StreamState: kurrentdb.Revision(created.NextExpectedVersion)}, itemAdded)
+	if err != nil {
+		panic(err)
+	}
+	shipped, err := AppendAndPosition(ctx, client, streamName, kurrentdb.AppendToStreamOptions{StreamState: kurrentdb.Revision(added.NextExpectedVersion)}, event("OrderShipped"))
+	if err != nil {
+		panic(err)
+	}
+
+	var tailed []*kurrentdb.RecordedEvent
+	for len(tailed) < 2 {
+		message := subscription.Recv()
+		if message.SubscriptionDropped != nil {
+			panic(message.SubscriptionDropped.Error)
+		}
+		if message.EventAppeared != nil {
+			recorded := Resolve(message.EventAppeared, true)
+			tailed = append(tailed, recorded)
+			fmt.Printf("  Tailed %s at %d/%d\n", recorded.EventType, recorded.Position.Commit, recorded.Position.Prepare)
+		}
+	}
+
+	// === IDEMPOTENT RETRY ===
+	// Re-sending an already written event id writes nothing, so the position is read back
+	retried, err := AppendAndPosition(ctx, client, streamName, kurrentdb.AppendToStreamOptions{StreamState: kurrentdb.Revision(created.NextExpectedVersion)}, itemAdded)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Idempotent retry: position %d/%d (from write result: %v)\n", retried.Position.Commit, retried.Position.Prepare, retried.FromWriteResult)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if !created.FromWriteResult || !added.FromWriteResult || !shipped.FromWriteResult {
+		fmt.Println("FAIL: Appends that write events should report their position")
+		passed = false
+	}
+	if tailed[0].EventType != "ItemAdded" || tailed[1].EventType != "OrderShipped" {
+		fmt.Printf("FAIL: Tail should start after OrderCreated, got %s, %s\n", tailed[0].EventType, tailed[1].EventType)
+		passed = false
+	}
+	if tailed[0].Position != added.Position || tailed[1].Position != shipped.Position {
+		fmt.Println("FAIL: Delivered positions should match the positions returned by the appends")
+		passed = false
+	}
+	if shipped.NextExpectedVersion != 2 {
+		fmt.Printf("FAIL: OrderShipped should be revision 2, got %d\n", shipped.NextExpectedVersion)
+		passed = false
+	}
+	// Either the original write's position, or the stream's head when it had to be read back
+	if retried.Position != added.Position && retried.Position != shipped.Position {
+		fmt.Printf("FAIL: Idempotent retry should resolve a known position, got %d/%d\n", retried.Position.Commit, retried.Position.Prepare)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll append position tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,189 @@
+// KurrentDB Go Client Example - Exponential backoff
+// Demonstrates: One backoff policy with jitter and a context-aware Retry, shared by every retry and reconnect loop
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// === BACKOFF ===
+
+// Backoff computes the delays between retries: Base, then Base x Multiplier, x Multiplier again and so
+// on up to Max. Jitter randomises each delay downwards by up to that fraction of it, so clients that
+// failed together don't all retry together: 0 waits exactly, 1 waits anywhere between 0 and the delay.
+//
+// A Backoff is not safe for concurrent use. Copy it to give each loop its own attempt count; Retry
+// does that itself, so one Backoff can be shared as a policy.
+type Backoff struct {
+	Base time.Duration
+	// Max caps each delay; 0 leaves them uncapped
+	Max        time.Duration
+	Multiplier float64
+	Jitter     float64
+
+	attempt int
+}
+
+// NewBackoff doubles from base up to max, with 20% jitter
+func NewBackoff(base, max time.Duration) Backoff {
+	return Backoff{Base: base, Max: max, Multiplier: 2, Jitter: 0.2}
+}
+
+// Next returns the delay before the next retry and advances to the one after
+func (b *Backoff) Next() time.Duration {
+	delay := float64(b.Base)
+	multiplier := max(b.Multiplier, 1)
+	for i := 0; i < b.attempt && (b.Max <= 0 || delay < float64(b.Max)); i++ {
+		delay *= multiplier
+	}
+	if b.Max > 0 {
+		delay = min(delay, float64(b.Max))
+	}
+	b.attempt++
+
+	if jitter := min(max(b.Jitter, 0), 1); jitter > 0 {
+		delay -= delay * jitter * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// Reset starts the delays over from Base, typically once a retried operation has succeeded
+func (b *Backoff) Reset() {
+	b.attempt = 0
+}
+
+// Wait sleeps for Next, returning ctx's error early if it ends first
+func (b *Backoff) Wait(ctx context.Context) error {
+	timer := time.NewTimer(b.Next())
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Retry calls fn up to maxAttempts times, numbering attempts from 1, waiting out the backoff between
+// them. It stops at the first success, at an error isRetryable rejects (nil retries every error), or
+// when ctx ends during a wait, and returns fn's last error or ctx's. b itself is not advanced.
+func (b *Backoff) Retry(ctx context.Context, maxAttempts int, fn func(attempt int) error, isRetryable func(error) bool) error {
+	backoff := *b
+	backoff.Reset()
+
+	var err error
+	for attempt := 1; attempt <= max(maxAttempts, 1); attempt++ {
+		if attempt > 1 {
+			if waitErr := backoff.Wait(ctx); waitErr != nil {
+				return waitErr
+			}
+		}
+		if err = fn(attempt); err == nil {
+			return nil
+		}
+		if isRetryable != nil && !isRetryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// === CHECKS ===
+
+// RunBackoffChecks verifies growth, jitter bounds and Retry's stopping rules without a server
+func RunBackoffChecks() {
+	checks := newChecker("backoff")
+
+	fmt.Println("\n--- Growth ---")
+	exact := Backoff{Base: 100 * time.Millisecond, Max: time.Second, Multiplier: 2}
+	var delays []time.Duration
+	for i := 0; i < 6; i++ {
+		delays = append(delays, exact.Next())
+	}
+	fmt.Printf("  %v\n", delays)
+	checks.Check(fmt.Sprint(delays) == "[100ms 200ms 400ms 800ms 1s 1s]", "Delays should double up to Max, got %v", delays)
+	exact.Reset()
+	checks.Check(exact.Next() == 100*time.Millisecond, "Reset should start over from Base")
+
+	uncapped := Backoff{Base: time.Millisecond, Multiplier: 10}
+	for i := 0; i < 3; i++ {
+		uncapped.Next()
+	}
+	checks.Check(uncapped.Next() == time.Second, "Without Max delays should keep growing")
+
+	fmt.Println("\n--- Jitter bounds ---")
+	for _, jitter := range []float64{0.2, 0.5, 1} {
+		jittered := Backoff{Base: 100 * time.Millisecond, Max: 400 * time.Millisecond, Multiplier: 2, Jitter: jitter}
+		for attempt := 0; attempt < 5; attempt++ {
+			ceiling := min(100*time.Millisecond<<attempt, 400*time.Millisecond)
+			floor := time.Duration(float64(ceiling) * (1 - jitter))
+			lowest, highest := ceiling, time.Duration(0)
+			for sample := 0; sample < 500; sample++ {
+				probe := jittered
+				delay := probe.Next()
+				lowest, highest = min(lowest, delay), max(highest, delay)
+			}
+			jittered.Next()
+			checks.Check(lowest >= floor && highest <= ceiling, "Jitter %.1f attempt %d should stay in [%s, %s], got [%s, %s]",
+				jitter, attempt, floor, ceiling, lowest, highest)
+			checks.Check(highest-lowest > (ceiling-floor)/2, "Jitter %.1f attempt %d should spread delays, got [%s, %s]",
+				jitter, attempt, lowest, highest)
+		}
+		fmt.Printf("  jitter %.1f: every delay within [delay x %.1f, delay]\n", jitter, 1-jitter)
+	}
+
+	fmt.Println("\n--- Retry ---")
+	errTransient := errors.New("transient")
+	errFatal := errors.New("fatal")
+	fast := Backoff{Base: time.Millisecond, Max: 5 * time.Millisecond, Multiplier: 2}
+
+	var attempts []int
+	err := fast.Retry(context.Background(), 5, func(attempt int) error {
+		attempts = append(attempts, attempt)
+		if attempt < 3 {
+			return errTransient
+		}
+		return nil
+	}, nil)
+	checks.Check(err == nil && fmt.Sprint(attempts) == "[1 2 3]", "Retry should stop at the first success, got %v after %v", err, attempts)
+	checks.Check(fast.Next() == time.Millisecond, "Retry should not advance the shared Backoff")
+
+	calls := 0
+	err = fast.Retry(context.Background(), 3, func(int) error { calls++; return errTransient }, nil)
+	checks.Check(errors.Is(err, errTransient) && calls == 3, "Retry should give up after maxAttempts with the last error, got %v after %d calls", err, calls)
+
+	calls = 0
+	err = fast.Retry(context.Background(), 5, func(int) error {
+		calls++
+		if calls == 2 {
+			return errFatal
+		}
+		return errTransient
+	}, func(err error) bool { return !errors.Is(err, errFatal) })
+	checks.Check(errors.Is(err, errFatal) && calls == 2, "Retry should stop at a non-retryable error, got %v after %d calls", err, calls)
+	fmt.Printf("  success on 3rd attempt, exhausted after 3, stopped on fatal after %d\n", calls)
+
+	fmt.Println("\n--- Context abort ---")
+	slow := NewBackoff(time.Hour, time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	calls = 0
+	started := time.Now()
+	err = slow.Retry(ctx, 5, func(int) error { calls++; return errTransient }, nil)
+	elapsed := time.Since(started)
+	fmt.Printf("  aborted after %s: %v\n", elapsed.Round(10*time.Millisecond), err)
+	checks.Check(errors.Is(err, context.DeadlineExceeded), "Retry should return ctx's error when it ends mid-wait, got %v", err)
+	checks.Check(calls == 1, "Retry should not call fn again once ctx ends, got %d calls", calls)
+	checks.Check(elapsed < time.Second, "Retry should abort the wait as soon as ctx ends, took %s", elapsed)
+
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	checks.Check(errors.Is(slow.Wait(cancelled), context.Canceled), "Wait should return at once on a cancelled ctx")
+
+	checks.Done()
+}
@@ -0,0 +1,441 @@
+// KurrentDB Go Client Example - Batching appends from many goroutines
+// Demonstrates: Grouping concurrently enqueued events per stream into fewer appends, in order, with per-event results
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === BATCH APPENDER ===
+
+// ErrAppenderClosed is the result of events enqueued after Close
+var ErrAppenderClosed = errors.New("batch appender closed")
+
+// BatchConflictPolicy chooses what BatchAppender does when another writer appended to a stream
+// since its last batch there
+type BatchConflictPolicy int
+
+const (
+	// FailBatch fails every event of the conflicting batch with the WrongExpectedVersion error.
+	// The stream's revision is forgotten, so the next batch appends at Any and learns it again.
+	FailBatch BatchConflictPolicy = iota
+	// FallBackToAny appends the conflicting batch again at Any, for events that don't depend on
+	// what else is in the stream
+	FallBackToAny
+)
+
+func (p BatchConflictPolicy) String() string {
+	switch p {
+	case FailBatch:
+		return "FailBatch"
+	case FallBackToAny:
+		return "FallBackToAny"
+	}
+	return fmt.Sprintf("BatchConflictPolicy(%d)", int(p))
+}
+
+// BatchAppenderStats counts what went to the server
+type BatchAppenderStats struct {
+	Events    int64
+	Appends   int64
+	Conflicts int64
+}
+
+// BatchAppender collects events enqueued by many goroutines and appends them per stream in
+// batches, trading a little latency for far fewer round trips under high write rates.
+//
+// A stream's first event waits up to MaxDelay for company, and a batch is appended at once when it
+// reaches MaxBatchSize. At most one append per stream is in flight, so a stream's events are
+// appended in the order they were enqueued; events enqueued during an append go in the next one as
+// soon as it returns. Different streams append concurrently.
+//
+// Each batch expects the revision left by the appender's previous batch to the stream, from a
+// RevisionCache; a stream not in the cache is appended at Any.
+type BatchAppender struct {
+	client    *kurrentdb.Client
+	revisions *RevisionCache
+
+	// MaxBatchSize is the most events per append
+	MaxBatchSize int
+	// MaxDelay is how long an event waits for others to the same stream before being appended
+	MaxDelay time.Duration
+	// OnConflict handles WrongExpectedVersion when another writer got in between batches
+	OnConflict BatchConflictPolicy
+
+	// append is swappable to simulate the server
+	append func(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error)
+
+	ctx context.Context
+	wg  sync.WaitGroup
+
+	mu      sync.Mutex
+	streams map[string]*streamQueue
+	closed  bool
+
+	events, appends, conflicts atomic.Int64
+}
+
+type queuedEvent struct {
+	event  kurrentdb.EventData
+	result chan error
+}
+
+// streamQueue holds a stream's events waiting to be appended
+type streamQueue struct {
+	pending []queuedEvent
+	// timer flushes after MaxDelay; nil while no events wait or a flush is running
+	timer *time.Timer
+	// flushing is set while a goroutine appends the queue's batches, one after another
+	flushing bool
+}
+
+// NewBatchAppender creates an appender whose appends run under ctx; cancelling it fails the
+// events not yet appended
+func NewBatchAppender(ctx context.Context, client *kurrentdb.Client) *BatchAppender {
+	return &BatchAppender{
+		client:       client,
+		revisions:    NewRevisionCache(0),
+		MaxBatchSize: 100,
+		MaxDelay:     2 * time.Millisecond,
+		append:       client.AppendToStream,
+		ctx:          ctx,
+		streams:      make(map[string]*streamQueue),
+	}
+}
+
+// Enqueue queues event for stream. The returned channel receives the event's result once its batch
+// is appended: nil, or the error shared by the whole batch, since an append is all or nothing.
+func (a *BatchAppender) Enqueue(stream string, event kurrentdb.EventData) <-chan error {
+	result := make(chan error, 1)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		result <- ErrAppenderClosed
+		return result
+	}
+
+	queue := a.streams[stream]
+	if queue == nil {
+		queue = &streamQueue{}
+		a.streams[stream] = queue
+	}
+	queue.pending = append(queue.pending, queuedEvent{event: event, result: result})
+
+	switch {
+	case queue.flushing:
+		// The running flush takes it after the current append
+	case len(queue.pending) >= a.MaxBatchSize:
+		a.startFlush(stream, queue)
+	case queue.timer == nil:
+		queue.timer = time.AfterFunc(a.MaxDelay, func() {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			// A full batch may have started the flush, or even finished it, since the timer fired
+			if a.streams[stream] == queue && !queue.flushing && len(queue.pending) > 0 {
+				a.startFlush(stream, queue)
+			}
+		})
+	}
+	return result
+}
+
+// startFlush starts appending queue's batches; a.mu must be held
+func (a *BatchAppender) startFlush(stream string, queue *streamQueue) {
+	if queue.timer != nil {
+		queue.timer.Stop()
+		queue.timer = nil
+	}
+	queue.flushing = true
+	a.wg.Add(1)
+	go a.flush(stream, queue)
+}
+
+// flush appends queue's events a batch at a time until none are left
+func (a *BatchAppender) flush(stream string, queue *streamQueue) {
+	defer a.wg.Done()
+	for {
+		a.mu.Lock()
+		if len(queue.pending) == 0 {
+			queue.flushing = false
+			delete(a.streams, stream)
+			a.mu.Unlock()
+			return
+		}
+		size := min(len(queue.pending), a.MaxBatchSize)
+		batch := queue.pending[:size:size]
+		queue.pending = queue.pending[size:]
+		a.mu.Unlock()
+
+		err := a.appendBatch(stream, batch)
+		for _, queued := range batch {
+			queued.result <- err
+		}
+	}
+}
+
+func (a *BatchAppender) appendBatch(stream string, batch []queuedEvent) error {
+	events := make([]kurrentdb.EventData, len(batch))
+	for i, queued := range batch {
+		events[i] = queued.event
+	}
+
+	var state kurrentdb.StreamState = kurrentdb.Any{}
+	if revision, ok := a.revisions.Get(stream); ok {
+		state = expectedState(revision)
+	}
+	a.appends.Add(1)
+	result, err := a.append(a.ctx, stream, kurrentdb.AppendToStreamOptions{StreamState: state}, events...)
+
+	if isWrongExpectedVersion(err) {
+		a.conflicts.Add(1)
+		a.revisions.Invalidate(stream)
+		if a.OnConflict == FailBatch {
+			return fmt.Errorf("append %d events to %s: %w", len(events), stream, err)
+		}
+		a.appends.Add(1)
+		result, err = a.append(a.ctx, stream, kurrentdb.AppendToStreamOptions{StreamState: kurrentdb.Any{}}, events...)
+	}
+	if err != nil {
+		// The append may have landed anyway, leaving the cached revision behind
+		a.revisions.Invalidate(stream)
+		return fmt.Errorf("append %d events to %s: %w", len(events), stream, err)
+	}
+
+	a.revisions.Set(stream, int64(result.NextExpectedVersion))
+	a.events.Add(int64(len(events)))
+	return nil
+}
+
+// Close appends everything still queued without waiting for MaxDelay, and returns once every
+// result has been delivered. Later Enqueue calls fail with ErrAppenderClosed.
+func (a *BatchAppender) Close() {
+	a.mu.Lock()
+	a.closed = true
+	for stream, queue := range a.streams {
+		if !queue.flushing && len(queue.pending) > 0 {
+			a.startFlush(stream, queue)
+		}
+	}
+	a.mu.Unlock()
+
+	a.wg.Wait()
+}
+
+// Stats returns the events appended and the appends and conflicts it took
+func (a *BatchAppender) Stats() BatchAppenderStats {
+	return BatchAppenderStats{
+		Events:    a.events.Load(),
+		Appends:   a.appends.Load(),
+		Conflicts: a.conflicts.Load(),
+	}
+}
+
+// RunBatchAppender compares per-call appends with a BatchAppender under many producers, checks
+// per-stream order, and runs both conflict policies against an outside writer
+func RunBatchAppender() {
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	const producers, perProducer, streamCount = 50, 20, 10
+	type reading struct {
+		Producer int `json:"producer"`
+		Seq      int `json:"seq"`
+	}
+	newEvent := func(data interface{}) kurrentdb.EventData {
+		payload, _ := json.Marshal(data)
+		return kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   "SensorReading",
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        payload,
+		}
+	}
+	newStreams := func() []string {
+		streams := make([]string, streamCount)
+		for i := range streams {
+			streams[i] = Streams.Name("sensor", uuid.New().String())
+		}
+		return streams
+	}
+
+	// produce runs the producers, each writing its readings one at a time to its stream and waiting
+	// for each to be appended, as a request handler would. It returns the time taken and the errors.
+	produce := func(streams []string, write func(stream string, event kurrentdb.EventData) error) (time.Duration, int) {
+		var wg sync.WaitGroup
+		var failures atomic.Int64
+		started := time.Now()
+		for p := 0; p < producers; p++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for seq := 0; seq < perProducer; seq++ {
+					if err := write(streams[p%streamCount], newEvent(reading{Producer: p, Seq: seq})); err != nil {
+						failures.Add(1)
+						fmt.Printf("  producer %d: %v\n", p, err)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		return time.Since(started), int(failures.Load())
+	}
+	rate := func(elapsed time.Duration) float64 {
+		return float64(producers*perProducer) / elapsed.Seconds()
+	}
+
+	// === PER-CALL APPENDS ===
+	fmt.Printf("\n=== %d producers x %d events over %d streams ===\n", producers, perProducer, streamCount)
+	perCallElapsed, perCallFailures := produce(newStreams(), func(stream string, event kurrentdb.EventData) error {
+		_, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, event)
+		return err
+	})
+	fmt.Printf("  per-call: %d appends in %s (%.0f events/s)\n", producers*perProducer, perCallElapsed.Round(time.Millisecond), rate(perCallElapsed))
+
+	// === BATCHED APPENDS ===
+	batchedStreams := newStreams()
+	appender := NewBatchAppender(ctx, client)
+	batchedElapsed, batchedFailures := produce(batchedStreams, func(stream string, event kurrentdb.EventData) error {
+		return <-appender.Enqueue(stream, event)
+	})
+	appender.Close()
+	stats := appender.Stats()
+	fmt.Printf("  batched:  %d appends in %s (%.0f events/s, %.1f events per append, %.1fx)\n",
+		stats.Appends, batchedElapsed.Round(time.Millisecond), rate(batchedElapsed),
+		float64(stats.Events)/float64(max(stats.Appends, 1)), perCallElapsed.Seconds()/batchedElapsed.Seconds())
+
+	// === ORDERING ===
+	fmt.Println("\n=== Per-stream order ===")
+	outOfOrder, stored := 0, 0
+	for _, stream := range batchedStreams {
+		events, err := client.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{From: kurrentdb.Start{}}, ^uint64(0))
+		if err != nil {
+			panic(err)
+		}
+		next := map[int]int{}
+		for {
+			event, err := events.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				panic(err)
+			}
+			var r reading
+			json.Unmarshal(Resolve(event, false).Data, &r)
+			if r.Seq != next[r.Producer] {
+				outOfOrder++
+			}
+			next[r.Producer] = r.Seq + 1
+			stored++
+		}
+		events.Close()
+	}
+	fmt.Printf("  %d events stored, %d out of producer order\n", stored, outOfOrder)
+
+	// === CONFLICTS ===
+	// conflict appends one event to learn the stream's revision, lets an outside writer append, then
+	// enqueues two events together: their batch expects the stale revision
+	conflict := func(policy BatchConflictPolicy) (errs [3]error, conflicts int64, stored []string) {
+		stream := Streams.Name("sensor", uuid.New().String())
+		appender := NewBatchAppender(ctx, client)
+		appender.OnConflict = policy
+		appender.MaxDelay = 50 * time.Millisecond
+
+		labelled := func(label string) kurrentdb.EventData { return newEvent(map[string]string{"label": label}) }
+		if err := <-appender.Enqueue(stream, labelled("first")); err != nil {
+			panic(err)
+		}
+		if _, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, labelled("outside")); err != nil {
+			panic(err)
+		}
+		second, third := appender.Enqueue(stream, labelled("second")), appender.Enqueue(stream, labelled("third"))
+		errs[0], errs[1] = <-second, <-third
+		errs[2] = <-appender.Enqueue(stream, labelled("after"))
+		appender.Close()
+
+		events, err := client.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{From: kurrentdb.Start{}}, ^uint64(0))
+		if err != nil {
+			panic(err)
+		}
+		defer events.Close()
+		for {
+			event, err := events.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				panic(err)
+			}
+			var data map[string]string
+			json.Unmarshal(Resolve(event, false).Data, &data)
+			stored = append(stored, data["label"])
+		}
+		fmt.Printf("  %-13s second: %v\n  %-13s third:  %v\n  %-13s stream: %v\n", policy, errs[0], "", errs[1], "", stored)
+		return errs, appender.Stats().Conflicts, stored
+	}
+
+	fmt.Println("\n=== Another writer appends between batches ===")
+	failErrs, failConflicts, failStored := conflict(FailBatch)
+	anyErrs, anyConflicts, anyStored := conflict(FallBackToAny)
+
+	closedErr := <-appender.Enqueue(batchedStreams[0], newEvent(reading{}))
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if perCallFailures != 0 || batchedFailures != 0 {
+		fmt.Printf("FAIL: Expected no append failures, got %d per-call and %d batched\n", perCallFailures, batchedFailures)
+		passed = false
+	}
+	if stats.Events != producers*perProducer || stats.Appends > stats.Events/2 {
+		fmt.Printf("FAIL: Expected %d events in at most half as many appends, got %d in %d\n", producers*perProducer, stats.Events, stats.Appends)
+		passed = false
+	}
+	if stored != producers*perProducer || outOfOrder != 0 {
+		fmt.Printf("FAIL: Expected all %d events stored in producer order, got %d with %d out of order\n", producers*perProducer, stored, outOfOrder)
+		passed = false
+	}
+	if !isWrongExpectedVersion(failErrs[0]) || !isWrongExpectedVersion(failErrs[1]) || failErrs[2] != nil || failConflicts != 1 {
+		fmt.Printf("FAIL: FailBatch should fail both events of the stale batch and then recover, got %v (%d conflicts)\n", failErrs, failConflicts)
+		passed = false
+	}
+	if fmt.Sprint(failStored) != "[first outside after]" {
+		fmt.Printf("FAIL: FailBatch should leave [first outside after], got %v\n", failStored)
+		passed = false
+	}
+	if anyErrs != [3]error{} || anyConflicts != 1 || fmt.Sprint(anyStored) != "[first outside second third after]" {
+		fmt.Printf("FAIL: FallBackToAny should append the stale batch in order after the outside event, got %v %v (%d conflicts)\n", anyErrs, anyStored, anyConflicts)
+		passed = false
+	}
+	if !errors.Is(closedErr, ErrAppenderClosed) {
+		fmt.Printf("FAIL: Enqueue after Close should fail with ErrAppenderClosed, got %v\n", closedErr)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll batch appender tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
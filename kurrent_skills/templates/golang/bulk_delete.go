@@ -0,0 +1,381 @@
+// KurrentDB Go Client Example - Bulk delete of a category
+// Demonstrates: Enumerating streams via $ce-{category} and deleting them with bounded concurrency, resumably
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === BULK DELETE ===
+
+// DeleteMode selects soft delete or tombstone
+type DeleteMode int
+
+const (
+	// SoftDelete hides the stream; appending to it again brings it back with the old revisions gone
+	SoftDelete DeleteMode = iota
+	// Tombstone deletes permanently; the stream name can never be used again
+	Tombstone
+)
+
+// BulkDeleteOptions configures a category cleanup
+type BulkDeleteOptions struct {
+	Category    string
+	Mode        DeleteMode
+	Concurrency int
+	// DryRun lists the streams that would be deleted without deleting anything
+	DryRun bool
+	// StatePath records finished streams so an interrupted run resumes where it stopped; optional
+	StatePath string
+	// Progress is called after every stream; optional
+	Progress func(done, total int)
+}
+
+// BulkDeleteReport is the outcome of one run
+type BulkDeleteReport struct {
+	Streams []string
+	Deleted []string
+	// Skipped were finished by an earlier run (per the state file) or were already deleted
+	Skipped []string
+	Failed  map[string]error
+}
+
+// bulkDeleteState is the resume file: streams already handled by earlier runs
+type bulkDeleteState struct {
+	Done map[string]bool `json:"done"`
+}
+
+// BulkDeleter deletes every stream in a category
+type BulkDeleter struct {
+	client  *kurrentdb.Client
+	options BulkDeleteOptions
+
+	// deleteStream is swappable to inject failures
+	deleteStream func(ctx context.Context, stream string) error
+}
+
+func NewBulkDeleter(client *kurrentdb.Client, options BulkDeleteOptions) *BulkDeleter {
+	if options.Concurrency <= 0 {
+		options.Concurrency = 4
+	}
+	d := &BulkDeleter{client: client, options: options}
+	d.deleteStream = d.delete
+	return d
+}
+
+// Streams enumerates the distinct streams linked from $ce-{category}, in first-seen order.
+// Links are read unresolved: their data is "<eventNumber>@<streamId>", which is all we need.
+// Requires the $by_category system projection.
+func (d *BulkDeleter) Streams(ctx context.Context) ([]string, error) {
+	events, err := d.client.ReadStream(ctx, Streams.Category(d.options.Category), kurrentdb.ReadStreamOptions{
+		Direction: kurrentdb.Forwards,
+		From:      kurrentdb.Start{},
+	}, ^uint64(0))
+	if err != nil {
+		if isStreamNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer events.Close()
+
+	seen := make(map[string]bool)
+	var streams []string
+	for {
+		event, err := events.Recv()
+		if errors.Is(err, io.EOF) {
+			return streams, nil
+		}
+		if err != nil {
+			if isStreamNotFound(err) {
+				return streams, nil
+			}
+			return nil, err
+		}
+
+		_, stream, ok := strings.Cut(string(Resolve(event, true).Data), "@")
+		if !ok || seen[stream] {
+			continue
+		}
+		seen[stream] = true
+		streams = append(streams, stream)
+	}
+}
+
+// Run deletes (or in dry-run mode, lists) every stream in the category. Failures don't stop the run;
+// they are reported and retried by the next run.
+func (d *BulkDeleter) Run(ctx context.Context) (*BulkDeleteReport, error) {
+	streams, err := d.Streams(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("enumerate %s: %w", Streams.Category(d.options.Category), err)
+	}
+
+	report := &BulkDeleteReport{Streams: streams, Failed: make(map[string]error)}
+	if d.options.DryRun {
+		return report, nil
+	}
+
+	state, err := d.loadState()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, stream := range streams {
+		if state.Done[stream] {
+			report.Skipped = append(report.Skipped, stream)
+		} else {
+			pending = append(pending, stream)
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	done := len(report.Skipped)
+	work := make(chan string)
+
+	for i := 0; i < d.options.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for stream := range work {
+				err := d.deleteStream(ctx, stream)
+				alreadyGone := isAlreadyDeleted(err)
+
+				mu.Lock()
+				switch {
+				case err == nil:
+					report.Deleted = append(report.Deleted, stream)
+					state.Done[stream] = true
+				case alreadyGone:
+					report.Skipped = append(report.Skipped, stream)
+					state.Done[stream] = true
+				default:
+					report.Failed[stream] = err
+				}
+				done++
+				if d.options.Progress != nil {
+					d.options.Progress(done, len(streams))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, stream := range pending {
+		select {
+		case work <- stream:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	sort.Strings(report.Deleted)
+	sort.Strings(report.Skipped)
+
+	if err := d.saveState(state); err != nil {
+		return report, err
+	}
+	return report, ctx.Err()
+}
+
+func (d *BulkDeleter) delete(ctx context.Context, stream string) error {
+	if d.options.Mode == Tombstone {
+		_, err := d.client.TombstoneStream(ctx, stream, kurrentdb.TombstoneStreamOptions{StreamState: kurrentdb.Any{}})
+		return err
+	}
+	_, err := d.client.DeleteStream(ctx, stream, kurrentdb.DeleteStreamOptions{StreamState: kurrentdb.Any{}})
+	return err
+}
+
+// isAlreadyDeleted treats an earlier tombstone or a missing stream as done, so reruns are idempotent
+func isAlreadyDeleted(err error) bool {
+	if err == nil {
+		return false
+	}
+	esErr, ok := kurrentdb.FromError(err)
+	return !ok && (esErr.Code() == kurrentdb.ErrorCodeStreamDeleted || esErr.Code() == kurrentdb.ErrorCodeResourceNotFound)
+}
+
+func (d *BulkDeleter) loadState() (*bulkDeleteState, error) {
+	state := &bulkDeleteState{Done: make(map[string]bool)}
+	if d.options.StatePath == "" {
+		return state, nil
+	}
+	data, err := os.ReadFile(d.options.StatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("corrupt bulk delete state %s: %w", d.options.StatePath, err)
+	}
+	if state.Done == nil {
+		state.Done = make(map[string]bool)
+	}
+	return state, nil
+}
+
+func (d *BulkDeleter) saveState(state *bulkDeleteState) error {
+	if d.options.StatePath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(d.options.StatePath, data, 0o644)
+}
+
+// RunBulkDelete creates a throwaway category, dry-runs, deletes with one failure, then resumes
+func RunBulkDelete() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	// === CREATE A CATEGORY ===
+	category := "cleanup" + strings.ReplaceAll(uuid.New().String(), "-", "")[:8]
+	const streamCount = 6
+
+	var created []string
+	for i := 0; i < streamCount; i++ {
+		stream := Streams.Name(category, uuid.New().String())
+		created = append(created, stream)
+		for _, eventType := range []string{"Created", "Updated"} {
+			_, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+				EventID:     uuid.New(),
+				EventType:   eventType,
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        []byte(`{}`),
+			})
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+	fmt.Printf("Created %d streams in category %s\n", streamCount, category)
+
+	dir, err := os.MkdirTemp("", "bulk-delete")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	options := BulkDeleteOptions{
+		Category:    category,
+		Mode:        SoftDelete,
+		Concurrency: 3,
+		StatePath:   filepath.Join(dir, "state.json"),
+		Progress: func(done, total int) {
+			fmt.Printf("  progress %d/%d\n", done, total)
+		},
+	}
+
+	// $by_category links events asynchronously; wait until every stream is visible
+	var listed []string
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(200 * time.Millisecond) {
+		if listed, err = NewBulkDeleter(client, options).Streams(ctx); err != nil {
+			panic(err)
+		}
+		if len(listed) == streamCount {
+			break
+		}
+	}
+
+	// === DRY RUN ===
+	dryOptions := options
+	dryOptions.DryRun = true
+	dry, err := NewBulkDeleter(client, dryOptions).Run(ctx)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("\nDry run: would delete %d streams\n", len(dry.Streams))
+	for _, stream := range dry.Streams {
+		fmt.Printf("  %s\n", stream)
+	}
+
+	// === FIRST RUN: ONE STREAM FAILS ===
+	fmt.Println("\nFirst run (one delete fails):")
+	first := NewBulkDeleter(client, options)
+	first.deleteStream = func(ctx context.Context, stream string) error {
+		if stream == created[2] {
+			return errors.New("simulated network error")
+		}
+		return first.delete(ctx, stream)
+	}
+	firstReport, err := first.Run(ctx)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("  deleted=%d skipped=%d failed=%d\n", len(firstReport.Deleted), len(firstReport.Skipped), len(firstReport.Failed))
+	for stream, err := range firstReport.Failed {
+		fmt.Printf("  FAILED %s: %v\n", stream, err)
+	}
+
+	// === RESUME ===
+	fmt.Println("\nResumed run:")
+	resumed, err := NewBulkDeleter(client, options).Run(ctx)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("  deleted=%d skipped=%d failed=%d\n", len(resumed.Deleted), len(resumed.Skipped), len(resumed.Failed))
+
+	remaining := 0
+	for _, stream := range created {
+		if first, err := FirstEvent(ctx, client, stream); first != nil || err != nil {
+			remaining++
+		}
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if len(dry.Streams) != streamCount || len(dry.Deleted) != 0 {
+		fmt.Printf("FAIL: Dry run should list %d streams and delete none, got %d listed, %d deleted\n", streamCount, len(dry.Streams), len(dry.Deleted))
+		passed = false
+	}
+	if len(firstReport.Deleted) != streamCount-1 || len(firstReport.Failed) != 1 {
+		fmt.Printf("FAIL: First run should delete %d and fail 1, got %d and %d\n", streamCount-1, len(firstReport.Deleted), len(firstReport.Failed))
+		passed = false
+	}
+	if len(resumed.Deleted) != 1 || len(resumed.Skipped) != streamCount-1 || len(resumed.Failed) != 0 {
+		fmt.Printf("FAIL: Resumed run should delete only the failed stream, got deleted=%d skipped=%d failed=%d\n", len(resumed.Deleted), len(resumed.Skipped), len(resumed.Failed))
+		passed = false
+	}
+	if remaining != 0 {
+		fmt.Printf("FAIL: All streams should be deleted, %d remain readable\n", remaining)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll bulk delete tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
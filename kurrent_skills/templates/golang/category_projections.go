@@ -0,0 +1,283 @@
+// KurrentDB Go Client Example - Per-category projection runner
+// Demonstrates: One isolated projection per $ce-{category} stream, each with its own checkpoint and a shared shutdown
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === CATEGORY PROJECTION ===
+
+// CategoryProjection runs one Projection over the $ce-{category} stream.
+// Requires the $by_category system projection to be running.
+type CategoryProjection struct {
+	Category string
+
+	mu         sync.Mutex
+	projection *Projection
+	// checkpoint is the last processed revision of the $ce- stream (the link's event number),
+	// which is what a stream subscription resumes from
+	checkpoint *uint64
+}
+
+// Read gives fn exclusive access to the projection while the runner is live
+func (c *CategoryProjection) Read(fn func(projection *Projection, checkpoint *uint64)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fn(c.projection, c.checkpoint)
+}
+
+// CategoryProjectionRunner runs a set of category projections side by side. A failure or drop in
+// one category never touches another's state or checkpoint.
+type CategoryProjectionRunner struct {
+	client      *kurrentdb.Client
+	projections []*CategoryProjection
+	reconnect   Backoff
+}
+
+func NewCategoryProjectionRunner(client *kurrentdb.Client) *CategoryProjectionRunner {
+	return &CategoryProjectionRunner{client: client, reconnect: NewBackoff(time.Second, 30*time.Second)}
+}
+
+// Add registers a projection for category and returns its handle
+func (r *CategoryProjectionRunner) Add(category string, projection *Projection) *CategoryProjection {
+	c := &CategoryProjection{Category: category, projection: projection}
+	r.projections = append(r.projections, c)
+	return c
+}
+
+// Run starts every category and blocks until ctx is cancelled, then waits for all of them to stop.
+// Drops are retried per category from that category's checkpoint.
+func (r *CategoryProjectionRunner) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, c := range r.projections {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.runCategory(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	for _, c := range r.projections {
+		c.Read(func(p *Projection, checkpoint *uint64) {
+			if checkpoint != nil {
+				fmt.Printf("  [%s] stopped at %s revision %d\n", c.Category, Streams.Category(c.Category), *checkpoint)
+			} else {
+				fmt.Printf("  [%s] stopped before any event\n", c.Category)
+			}
+		})
+	}
+}
+
+func (r *CategoryProjectionRunner) runCategory(ctx context.Context, c *CategoryProjection) {
+	reconnect := r.reconnect
+	for {
+		subscribed := time.Now()
+		err := r.subscribe(ctx, c)
+		if ctx.Err() != nil {
+			return
+		}
+		fmt.Printf("  [%s] subscription dropped, reconnecting: %v\n", c.Category, err)
+
+		// Only back off further while drops come in quick succession
+		if time.Since(subscribed) > reconnect.Max {
+			reconnect.Reset()
+		}
+		if reconnect.Wait(ctx) != nil {
+			return
+		}
+	}
+}
+
+func (r *CategoryProjectionRunner) subscribe(ctx context.Context, c *CategoryProjection) error {
+	options := kurrentdb.SubscribeToStreamOptions{From: kurrentdb.Start{}, ResolveLinkTos: true}
+	c.Read(func(_ *Projection, checkpoint *uint64) {
+		if checkpoint != nil {
+			options.From = kurrentdb.Revision(*checkpoint)
+		}
+	})
+
+	subscription, err := r.client.SubscribeToStream(ctx, Streams.Category(c.Category), options)
+	if err != nil {
+		return err
+	}
+	defer subscription.Close()
+
+	for {
+		event := subscription.Recv()
+
+		if event.SubscriptionDropped != nil {
+			if event.SubscriptionDropped.Error == nil {
+				return errors.New("subscription dropped")
+			}
+			return event.SubscriptionDropped.Error
+		}
+		if event.EventAppeared == nil {
+			continue
+		}
+
+		link := Resolve(event.EventAppeared, true)
+		revision := link.EventNumber
+
+		c.mu.Lock()
+		// An unresolved link means the link target was deleted; there is nothing to project
+		if resolved := Resolve(event.EventAppeared, false); resolved.EventType != linkEventType {
+			if _, err := c.projection.Apply(resolved, link.Position); err != nil {
+				fmt.Printf("  [%s] skipped: %v\n", c.Category, err)
+			}
+		}
+		c.checkpoint = &revision
+		c.mu.Unlock()
+	}
+}
+
+// === PAYMENT PROJECTION ===
+
+// NewPaymentSummaryProjection tracks authorized, captured and refunded amounts per payment
+func NewPaymentSummaryProjection() *Projection {
+	return NewProjection("PaymentSummary").
+		On("PaymentAuthorized", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			return map[string]interface{}{
+				"orderId":    data["orderId"],
+				"authorized": data["amount"],
+				"captured":   float64(0),
+				"status":     "authorized",
+			}
+		}).
+		On("PaymentCaptured", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			state["captured"] = data["amount"]
+			state["status"] = "captured"
+			return state
+		}).
+		On("PaymentRefunded", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			state["status"] = "refunded"
+			return state
+		})
+}
+
+// RunCategoryProjections runs order and payment projections side by side until both have caught up
+// with the events appended here (or Ctrl+C), then shuts them down together
+func RunCategoryProjections() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	// === APPEND ORDER AND PAYMENT EVENTS ===
+	orderID := uuid.New().String()
+	orderStream := Streams.Name("order", orderID)
+	paymentStream := Streams.Name("payment", uuid.New().String())
+
+	appendJSON := func(stream, eventType string, data interface{}) {
+		payload, _ := json.Marshal(data)
+		_, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        payload,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	appendJSON(orderStream, "OrderCreated", OrderCreated{OrderID: orderID, CustomerID: "cust-1", Amount: 100})
+	appendJSON(paymentStream, "PaymentAuthorized", map[string]interface{}{"orderId": orderID, "amount": 125})
+	appendJSON(orderStream, "ItemAdded", ItemAdded{Item: "Widget", Price: 25})
+	appendJSON(paymentStream, "PaymentCaptured", map[string]interface{}{"orderId": orderID, "amount": 125})
+	appendJSON(orderStream, "OrderShipped", OrderShipped{ShippedAt: "2024-01-15T10:00:00Z"})
+
+	fmt.Printf("Appended to %s and %s\n", orderStream, paymentStream)
+
+	// === RUN SIDE BY SIDE ===
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	runner := NewCategoryProjectionRunner(client)
+	orders := runner.Add("order", NewOrderSummaryProjection())
+	payments := runner.Add("payment", NewPaymentSummaryProjection())
+
+	stopped := make(chan struct{})
+	go func() {
+		runner.Run(runCtx)
+		close(stopped)
+	}()
+
+	var orderState, paymentState map[string]interface{}
+	deadline := time.Now().Add(15 * time.Second)
+	for time.Now().Before(deadline) && runCtx.Err() == nil {
+		orders.Read(func(p *Projection, _ *uint64) { orderState = copyState(p.Get(orderStream)) })
+		payments.Read(func(p *Projection, _ *uint64) { paymentState = copyState(p.Get(paymentStream)) })
+		if orderState["status"] == "shipped" && paymentState["status"] == "captured" {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// === GRACEFUL SHUTDOWN ===
+	fmt.Println("\nShutting down all category projections...")
+	cancel()
+	<-stopped
+
+	fmt.Printf("  order:   %v\n", orderState)
+	fmt.Printf("  payment: %v\n", paymentState)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if orderState["status"] != "shipped" || orderState["amount"] != float64(125) {
+		fmt.Printf("FAIL: Order projection should be shipped with 125, got %v\n", orderState)
+		passed = false
+	}
+	if paymentState["status"] != "captured" || paymentState["captured"] != float64(125) {
+		fmt.Printf("FAIL: Payment projection should be captured with 125, got %v\n", paymentState)
+		passed = false
+	}
+	orders.Read(func(p *Projection, _ *uint64) {
+		if len(p.Get(paymentStream)) != 0 {
+			fmt.Println("FAIL: Order projection should not see payment streams")
+			passed = false
+		}
+	})
+	var orderCheckpoint, paymentCheckpoint *uint64
+	orders.Read(func(_ *Projection, checkpoint *uint64) { orderCheckpoint = checkpoint })
+	payments.Read(func(_ *Projection, checkpoint *uint64) { paymentCheckpoint = checkpoint })
+	if orderCheckpoint == nil || paymentCheckpoint == nil {
+		fmt.Println("FAIL: Each category should hold its own checkpoint")
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll category projection tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
+
+// copyState takes a shallow copy so it can be read after the projection's lock is released
+func copyState(state map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(state))
+	for k, v := range state {
+		copied[k] = v
+	}
+	return copied
+}
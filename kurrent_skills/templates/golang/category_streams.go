@@ -0,0 +1,213 @@
+// KurrentDB Go Client Example - Listing the streams in a category
+// Demonstrates: Enumerating aggregate instances by paging through $ce-{category} with resolved links
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === CATEGORY LISTING ===
+
+// ErrCategoryNotFound is returned when $ce-{category} doesn't exist. The server can't tell the
+// causes apart: no stream in the category has been written yet, the $by_category projection hasn't
+// caught up, or system projections are disabled (start the server with --run-projections=System).
+var ErrCategoryNotFound = errors.New("category stream not found")
+
+// categoryPageSize is how many links ListStreams reads per request
+const categoryPageSize = 500
+
+// ListStreams returns the distinct streams in category, in the order they were first written to.
+// It pages through $ce-{category}, so memory is bounded by the number of streams rather than events.
+// The category stream is built asynchronously: streams written moments ago may be missing.
+func ListStreams(ctx context.Context, client *kurrentdb.Client, category string) ([]string, error) {
+	return listStreams(ctx, client, category, categoryPageSize)
+}
+
+func listStreams(ctx context.Context, client *kurrentdb.Client, category string, pageSize uint64) ([]string, error) {
+	categoryStream := Streams.Category(category)
+
+	seen := make(map[string]bool)
+	var streams []string
+	var from kurrentdb.StreamPosition = kurrentdb.Start{}
+	for {
+		read, next, err := readCategoryPage(ctx, client, categoryStream, from, pageSize, func(stream string) {
+			if !seen[stream] {
+				seen[stream] = true
+				streams = append(streams, stream)
+			}
+		})
+		if isStreamNotFound(err) {
+			if len(streams) > 0 {
+				return nil, fmt.Errorf("%s deleted while listing: %w", categoryStream, err)
+			}
+			return nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, categoryStream)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", categoryStream, err)
+		}
+		if read < pageSize {
+			return streams, nil
+		}
+		from = kurrentdb.StreamRevision{Value: next}
+	}
+}
+
+// readCategoryPage reads up to pageSize links from categoryStream and passes each source stream id
+// to found. It returns the number of links read and the revision after the last one.
+func readCategoryPage(ctx context.Context, client *kurrentdb.Client, categoryStream string, from kurrentdb.StreamPosition, pageSize uint64, found func(stream string)) (uint64, uint64, error) {
+	events, err := client.ReadStream(ctx, categoryStream, kurrentdb.ReadStreamOptions{
+		Direction:      kurrentdb.Forwards,
+		From:           from,
+		ResolveLinkTos: true,
+	}, pageSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer events.Close()
+
+	var read, next uint64
+	for {
+		event, err := events.Recv()
+		if errors.Is(err, io.EOF) {
+			return read, next, nil
+		}
+		if err != nil {
+			return read, next, err
+		}
+		read++
+		link := Resolve(event, true)
+		next = link.EventNumber + 1
+
+		if recorded := Resolve(event, false); recorded.EventType != linkEventType {
+			found(recorded.StreamID)
+			continue
+		}
+		// The linked event is gone (stream deleted or truncated); the link data still names it
+		if stream, _, ok := ParseLink(link); ok {
+			found(stream)
+		}
+	}
+}
+
+// RunListStreams lists every order stream, then checks paging and the not-caught-up case on a
+// category of its own
+func RunListStreams() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	appendEvents := func(stream string, count int) {
+		for i := 0; i < count; i++ {
+			_, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+				EventID:     uuid.New(),
+				EventType:   "OrderUpdated",
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        []byte(fmt.Sprintf(`{"sequence":%d}`, i)),
+			})
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	// === ALL ORDER STREAMS ===
+	fmt.Println("\n=== order-* streams ===")
+	orderStream := Streams.Name("order", uuid.New().String())
+	appendEvents(orderStream, 1)
+
+	var orders []string
+	var err error
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(200 * time.Millisecond) {
+		orders, err = ListStreams(ctx, client, "order")
+		if err != nil && !errors.Is(err, ErrCategoryNotFound) {
+			panic(err)
+		}
+		if slices.Contains(orders, orderStream) {
+			break
+		}
+	}
+	fmt.Printf("Found %d order streams\n", len(orders))
+	for _, stream := range orders[max(0, len(orders)-5):] {
+		fmt.Printf("  ... %s\n", stream)
+	}
+
+	// === NOT CAUGHT UP YET ===
+	fmt.Println("\n=== Fresh category ===")
+	category := "listing" + strings.ReplaceAll(uuid.New().String(), "-", "")[:8]
+	var created []string
+	for i := 0; i < 5; i++ {
+		stream := Streams.Name(category, uuid.New().String())
+		created = append(created, stream)
+		appendEvents(stream, 3)
+	}
+
+	// Immediately after the writes $by_category may not have linked them all
+	immediate, err := ListStreams(ctx, client, category)
+	fmt.Printf("Right after writing 5 streams: %d listed (err: %v)\n", len(immediate), err)
+
+	// Callers that need every stream they just wrote wait until all of them show up
+	var listed []string
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(200 * time.Millisecond) {
+		// A page of 2 links splits one stream's 3 events across pages
+		listed, err = listStreams(ctx, client, category, 2)
+		if err != nil && !errors.Is(err, ErrCategoryNotFound) {
+			panic(err)
+		}
+		if len(listed) == len(created) {
+			break
+		}
+	}
+	fmt.Printf("After catching up, paging 2 links at a time: %d listed\n", len(listed))
+
+	// === MISSING CATEGORY ===
+	_, missingErr := ListStreams(ctx, client, "nothing"+strings.ReplaceAll(uuid.New().String(), "-", ""))
+	fmt.Printf("\nUnknown category: %v\n", missingErr)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if !slices.Contains(orders, orderStream) {
+		fmt.Printf("FAIL: Expected %s among the order streams\n", orderStream)
+		passed = false
+	}
+	if len(orders) != len(slices.Compact(slices.Sorted(slices.Values(orders)))) {
+		fmt.Println("FAIL: Listed order streams should be distinct")
+		passed = false
+	}
+	if !slices.Equal(listed, created) {
+		fmt.Printf("FAIL: Expected each stream once in write order across pages\n  got  %v\n  want %v\n", listed, created)
+		passed = false
+	}
+	if len(immediate) > len(created) {
+		fmt.Printf("FAIL: An early listing can miss streams but never add any, got %d\n", len(immediate))
+		passed = false
+	}
+	if !errors.Is(missingErr, ErrCategoryNotFound) {
+		fmt.Printf("FAIL: Expected ErrCategoryNotFound for an unknown category, got %v\n", missingErr)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll list streams tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
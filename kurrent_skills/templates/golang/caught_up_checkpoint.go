@@ -0,0 +1,211 @@
+// KurrentDB Go Client Example - Checkpointing a filtered subscription when it catches up
+// Demonstrates: Saving the head position on caught-up so a restart skips already-scanned, filtered-out events
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// countAllAfter counts the $all events a subscription starting at from would scan to reach the head
+func countAllAfter(ctx context.Context, client *kurrentdb.Client, from kurrentdb.Position, limit uint64) (int, error) {
+	events, err := client.ReadAll(ctx, kurrentdb.ReadAllOptions{Direction: kurrentdb.Forwards, From: from}, limit)
+	if err != nil {
+		return 0, err
+	}
+	defer events.Close()
+
+	count := 0
+	for {
+		event, err := events.Recv()
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		// Reading from a position includes the event at it
+		if Resolve(event, true).Position != from {
+			count++
+		}
+	}
+}
+
+// RunCaughtUpCheckpoint runs a filtered subscription over mostly unrelated events, stops it once
+// caught up, and compares how much a restart would re-scan with and without the head checkpoint
+func RunCaughtUpCheckpoint() {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	start, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+
+	orderStream := Streams.Name("order", uuid.New().String())
+	appendTo := func(stream, eventType string) {
+		_, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(`{}`),
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// === ONE MATCH, THEN NOISE ===
+	appendTo(orderStream, "OrderCreated")
+	const noise = 300
+	for i := 0; i < noise; i++ {
+		appendTo(Streams.Name("telemetry", uuid.New().String()), "Heartbeat")
+	}
+	fmt.Printf("Appended 1 event to %s and %d unrelated events after it\n", orderStream, noise)
+
+	dir, err := os.MkdirTemp("", "caught-up-checkpoint")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Filter checkpoints every 32 x 100 scanned events stand in for a busy $all, where the last one
+	// the server sent can be far behind the head
+	options := kurrentdb.SubscribeToAllOptions{
+		From:               start,
+		Filter:             &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{orderStream}},
+		CheckpointInterval: 100,
+	}
+
+	// run subscribes until the checkpoint file reaches at least want, then stops; it returns the
+	// events delivered and the saved checkpoint
+	run := func(name string, readHead func(context.Context) (kurrentdb.Position, error), want kurrentdb.Position) ([]string, *kurrentdb.Position) {
+		store := FileCheckpoint{Path: filepath.Join(dir, name+".json")}
+		metered := NewMeteredSubscription(client, options)
+		metered.Checkpoints = store
+		if readHead != nil {
+			metered.readHead = readHead
+		}
+
+		var mu sync.Mutex
+		var delivered []string
+		runCtx, stop := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() {
+			done <- metered.Run(runCtx, func(event *kurrentdb.ResolvedEvent) error {
+				mu.Lock()
+				defer mu.Unlock()
+				delivered = append(delivered, Resolve(event, false).EventType)
+				return nil
+			})
+		}()
+
+		for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+			if saved, _ := store.Load(); saved != nil && !positionAfter(want, *saved) {
+				break
+			}
+		}
+		stop()
+		if err := <-done; err != nil {
+			panic(err)
+		}
+
+		saved, err := store.Load()
+		if err != nil {
+			panic(err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return delivered, saved
+	}
+
+	head, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+
+	// === WITH THE HEAD ===
+	fmt.Println("\n=== Caught-up saves the head ===")
+	withHead, withHeadSaved := run("with-head", nil, head)
+	withHeadRescan := -1
+	if withHeadSaved != nil {
+		withHeadRescan, err = countAllAfter(ctx, client, *withHeadSaved, 10*noise)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("Delivered %v, saved %d/%d: a restart re-scans %d events\n", withHead, withHeadSaved.Commit, withHeadSaved.Prepare, withHeadRescan)
+	}
+
+	// === HEAD UNAVAILABLE ===
+	fmt.Println("\n=== Head unavailable: falls back to the last event ===")
+	noHead := func(context.Context) (kurrentdb.Position, error) {
+		return kurrentdb.Position{}, errors.New("simulated: no read access to $all")
+	}
+	fallback, fallbackSaved := run("fallback", noHead, start)
+	fallbackRescan := -1
+	if fallbackSaved != nil {
+		fallbackRescan, err = countAllAfter(ctx, client, *fallbackSaved, 10*noise)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("Delivered %v, saved %d/%d: a restart re-scans %d events\n", fallback, fallbackSaved.Commit, fallbackSaved.Prepare, fallbackRescan)
+	}
+
+	// === RESTART ===
+	fmt.Println("\n=== Restart from the head checkpoint ===")
+	appendTo(orderStream, "OrderShipped")
+	latest, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+	restarted, _ := run("with-head", nil, latest)
+	fmt.Printf("Delivered after restart: %v\n", restarted)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if fmt.Sprint(withHead) != "[OrderCreated]" || fmt.Sprint(fallback) != "[OrderCreated]" {
+		fmt.Printf("FAIL: Each run should deliver the one matching event, got %v and %v\n", withHead, fallback)
+		passed = false
+	}
+	if withHeadSaved == nil || positionAfter(head, *withHeadSaved) {
+		fmt.Printf("FAIL: Caught-up should save at least the head %d/%d, got %v\n", head.Commit, head.Prepare, withHeadSaved)
+		passed = false
+	}
+	if withHeadRescan < 0 || withHeadRescan >= noise {
+		fmt.Printf("FAIL: A restart after caught-up should skip the %d unrelated events, re-scans %d\n", noise, withHeadRescan)
+		passed = false
+	}
+	if fallbackRescan < noise {
+		fmt.Printf("FAIL: Without the head, the last event's position should be saved (re-scan >= %d), got %d\n", noise, fallbackRescan)
+		passed = false
+	}
+	if fmt.Sprint(restarted) != "[OrderShipped]" {
+		fmt.Printf("FAIL: The restart should deliver only the new event, got %v\n", restarted)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll caught-up checkpoint tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,367 @@
+// KurrentDB Go Client Example - Tracing an event's causation chain
+// Demonstrates: Rebuilding the tree of events linked by $causationId metadata, from the root command to every resulting event
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === CAUSATION TRACE ===
+
+// ErrTraceEventNotFound is returned when the starting event isn't among the events read
+var ErrTraceEventNotFound = errors.New("event not found")
+
+// defaultTraceMaxEvents bounds how many events TraceCausation reads and holds in memory
+const defaultTraceMaxEvents = 100_000
+
+// CausationTraceOptions chooses where TraceCausation looks for related events
+type CausationTraceOptions struct {
+	// Category reads $ce-{category} instead of $all: cheaper, but events the chain caused in other
+	// categories are not found. Requires the $by_category system projection.
+	Category string
+	// From is the $all position to read from; zero reads from the start. Ignored with Category.
+	From kurrentdb.Position
+	// MaxEvents is the most events read, 100,000 when zero
+	MaxEvents uint64
+}
+
+// CausationNode is one event in the tree, with the events that name it as their $causationId
+type CausationNode struct {
+	Event       Envelope
+	CausationID uuid.UUID
+	Children    []*CausationNode
+}
+
+// CausationLink is an event whose $causationId points to a given ID
+type CausationLink struct {
+	EventID     uuid.UUID
+	CausationID uuid.UUID
+}
+
+// CausationTrace is the tree containing the starting event, rooted at the earliest cause found
+type CausationTrace struct {
+	Start uuid.UUID
+	Root  *CausationNode
+	// MissingCause is the root's $causationId when that event wasn't found: deleted, scavenged,
+	// outside the range read, or never written. uuid.Nil when the root has no cause.
+	MissingCause uuid.UUID
+	// Cycles are the causation links left out of the tree because they lead back into it
+	Cycles []CausationLink
+	// Truncated is set when MaxEvents was reached, so parts of the chain may be missing
+	Truncated bool
+}
+
+// causationID returns the $causationId in metadata, or uuid.Nil when there is none
+func causationID(metadata []byte) uuid.UUID {
+	var meta Meta
+	if meta.UnmarshalJSON(metadata) != nil {
+		return uuid.Nil
+	}
+	id, err := uuid.Parse(meta.Causation())
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+// BuildCausationTrace finds start among events, follows $causationId up to the earliest cause,
+// then collects everything caused from there down. Events are expected in log order; children
+// keep that order.
+func BuildCausationTrace(events []Envelope, start uuid.UUID) (*CausationTrace, error) {
+	byID := make(map[uuid.UUID]Envelope, len(events))
+	children := make(map[uuid.UUID][]uuid.UUID)
+	for _, event := range events {
+		if _, seen := byID[event.EventID]; seen {
+			continue
+		}
+		byID[event.EventID] = event
+		if cause := causationID(event.Metadata); cause != uuid.Nil {
+			children[cause] = append(children[cause], event.EventID)
+		}
+	}
+
+	if _, ok := byID[start]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTraceEventNotFound, start)
+	}
+	trace := &CausationTrace{Start: start}
+
+	// Walk up; metadata is written by clients, so a chain can loop back on itself
+	rootID := start
+	upward := map[uuid.UUID]bool{start: true}
+	for {
+		cause := causationID(byID[rootID].Metadata)
+		if cause == uuid.Nil {
+			break
+		}
+		if _, ok := byID[cause]; !ok {
+			trace.MissingCause = cause
+			break
+		}
+		if upward[cause] {
+			break
+		}
+		upward[cause] = true
+		rootID = cause
+	}
+
+	// Walk down; an event is placed once, so links back into the tree are reported, not followed
+	placed := make(map[uuid.UUID]bool)
+	var build func(id uuid.UUID) *CausationNode
+	build = func(id uuid.UUID) *CausationNode {
+		placed[id] = true
+		node := &CausationNode{Event: byID[id], CausationID: causationID(byID[id].Metadata)}
+		for _, child := range children[id] {
+			if placed[child] {
+				trace.Cycles = append(trace.Cycles, CausationLink{EventID: child, CausationID: id})
+				continue
+			}
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+	trace.Root = build(rootID)
+	return trace, nil
+}
+
+// TraceCausation reads $all (or one category) and returns the causation tree containing eventID
+func TraceCausation(ctx context.Context, client *kurrentdb.Client, eventID uuid.UUID, opts CausationTraceOptions) (*CausationTrace, error) {
+	limit := opts.MaxEvents
+	if limit == 0 {
+		limit = defaultTraceMaxEvents
+	}
+
+	var events *kurrentdb.ReadStream
+	var err error
+	source := "$all"
+	if opts.Category != "" {
+		source = Streams.Category(opts.Category)
+		events, err = client.ReadStream(ctx, source, kurrentdb.ReadStreamOptions{
+			Direction:      kurrentdb.Forwards,
+			From:           kurrentdb.Start{},
+			ResolveLinkTos: true,
+		}, limit)
+	} else {
+		var from kurrentdb.AllPosition = kurrentdb.Start{}
+		if opts.From != (kurrentdb.Position{}) {
+			from = opts.From
+		}
+		events, err = client.ReadAll(ctx, kurrentdb.ReadAllOptions{Direction: kurrentdb.Forwards, From: from}, limit)
+	}
+	if isStreamNotFound(err) {
+		return nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", source, err)
+	}
+	defer events.Close()
+
+	var envelopes []Envelope
+	var read uint64
+	for {
+		event, err := events.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if isStreamNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", ErrCategoryNotFound, source)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", source, err)
+		}
+		read++
+		// Links whose event is gone, and system events, can't be part of a chain
+		if strings.HasPrefix(Resolve(event, false).EventType, "$") {
+			continue
+		}
+		envelopes = append(envelopes, NewEnvelope(event))
+	}
+
+	trace, err := BuildCausationTrace(envelopes, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	trace.Truncated = read == limit
+	return trace, nil
+}
+
+// Print writes the tree with one indented line per event, marking the starting event
+func (t *CausationTrace) Print(w io.Writer) {
+	if t.MissingCause != uuid.Nil {
+		fmt.Fprintf(w, "? %s (cause not found)\n", t.MissingCause)
+	}
+	var printNode func(node *CausationNode, depth int)
+	printNode = func(node *CausationNode, depth int) {
+		marker := " "
+		if node.Event.EventID == t.Start {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "%s %s%s  %s@%d  %s\n", marker, strings.Repeat("  ", depth), node.Event.EventType, node.Event.StreamID, node.Event.EventNumber, node.Event.EventID)
+		for _, child := range node.Children {
+			printNode(child, depth+1)
+		}
+	}
+	printNode(t.Root, 0)
+	for _, cycle := range t.Cycles {
+		fmt.Fprintf(w, "! cycle: %s claims to be caused by %s, which it already caused\n", cycle.EventID, cycle.CausationID)
+	}
+	if t.Truncated {
+		fmt.Fprintln(w, "! read limit reached: the chain may be incomplete")
+	}
+}
+
+// RunCausationTrace writes an order workflow with causation metadata and traces it from the
+// shipped event back to the command, plus a broken link and a cycle
+func RunCausationTrace() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	from, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+
+	orderID := uuid.New().String()
+	correlationID := uuid.New()
+
+	// write appends one event with the given ID, caused by cause (none when uuid.Nil)
+	write := func(stream, eventType string, id, cause uuid.UUID) uuid.UUID {
+		metadata := NewMeta().SetCorrelation(correlationID.String())
+		if cause != uuid.Nil {
+			metadata.SetCausation(cause.String())
+		}
+		rawMetadata, err := metadata.Marshal()
+		if err != nil {
+			panic(err)
+		}
+		_, err = client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     id,
+			EventType:   eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(fmt.Sprintf(`{"orderId":%q}`, orderID)),
+			Metadata:    rawMetadata,
+		})
+		if err != nil {
+			panic(err)
+		}
+		return id
+	}
+
+	// === ORDER WORKFLOW ===
+	// PlaceOrder -> OrderPlaced -> (PaymentRequested -> PaymentCaptured -> OrderShipped, StockReserved)
+	placeOrder := write(Streams.Name("orderCommand", orderID), "PlaceOrder", uuid.New(), uuid.Nil)
+	placed := write(Streams.Name("order", orderID), "OrderPlaced", uuid.New(), placeOrder)
+	requested := write(Streams.Name("payment", orderID), "PaymentRequested", uuid.New(), placed)
+	reserved := write(Streams.Name("inventory", orderID), "StockReserved", uuid.New(), placed)
+	captured := write(Streams.Name("payment", orderID), "PaymentCaptured", uuid.New(), requested)
+	shipped := write(Streams.Name("order", orderID), "OrderShipped", uuid.New(), captured)
+	// An unrelated workflow interleaved with this one
+	otherCommand := write(Streams.Name("orderCommand", uuid.New().String()), "PlaceOrder", uuid.New(), uuid.Nil)
+	write(Streams.Name("order", uuid.New().String()), "OrderPlaced", uuid.New(), otherCommand)
+
+	fmt.Println("\n=== Trace from OrderShipped ===")
+	workflow, err := TraceCausation(ctx, client, shipped, CausationTraceOptions{From: from})
+	if err != nil {
+		panic(err)
+	}
+	workflow.Print(os.Stdout)
+
+	var order []uuid.UUID
+	var walk func(node *CausationNode)
+	walk = func(node *CausationNode) {
+		order = append(order, node.Event.EventID)
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(workflow.Root)
+
+	// === BROKEN LINK AND CYCLE ===
+	// Written to a category of their own and traced through $ce-{category}
+	category := "trace" + strings.ReplaceAll(uuid.New().String(), "-", "")[:8]
+	stream := Streams.Name(category, orderID)
+	lost := uuid.New()
+	orphan := write(stream, "OrderCancelled", uuid.New(), lost)
+	first, second := uuid.New(), uuid.New()
+	write(stream, "RetryScheduled", first, second)
+	write(stream, "RetryAttempted", second, first)
+
+	// $ce-{category} is built asynchronously
+	traceCategory := func(id uuid.UUID) *CausationTrace {
+		var trace *CausationTrace
+		var err error
+		for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(200 * time.Millisecond) {
+			if trace, err = TraceCausation(ctx, client, id, CausationTraceOptions{Category: category}); err == nil {
+				return trace
+			}
+			if !errors.Is(err, ErrCategoryNotFound) && !errors.Is(err, ErrTraceEventNotFound) {
+				panic(err)
+			}
+		}
+		panic(err)
+	}
+
+	fmt.Println("\n=== Trace with a missing cause ===")
+	broken := traceCategory(orphan)
+	broken.Print(os.Stdout)
+
+	fmt.Println("\n=== Trace with a cycle ===")
+	cyclic := traceCategory(first)
+	cyclic.Print(os.Stdout)
+
+	_, unknownErr := TraceCausation(ctx, client, uuid.New(), CausationTraceOptions{From: from})
+	fmt.Printf("\nUnknown event: %v\n", unknownErr)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	want := []uuid.UUID{placeOrder, placed, requested, captured, shipped, reserved}
+	if workflow.Root.Event.EventID != placeOrder || !slices.Equal(order, want) {
+		fmt.Printf("FAIL: Expected the tree PlaceOrder > OrderPlaced > (PaymentRequested > PaymentCaptured > OrderShipped, StockReserved)\n  got  %v\n  want %v\n", order, want)
+		passed = false
+	}
+	if workflow.MissingCause != uuid.Nil || len(workflow.Cycles) != 0 {
+		fmt.Printf("FAIL: The workflow should be complete, missing %s, cycles %v\n", workflow.MissingCause, workflow.Cycles)
+		passed = false
+	}
+	if slices.Contains(order, otherCommand) {
+		fmt.Println("FAIL: The unrelated workflow should not be in the tree")
+		passed = false
+	}
+	if broken.MissingCause != lost || broken.Root.Event.EventID != orphan {
+		fmt.Printf("FAIL: Expected OrderCancelled as root with missing cause %s, got %s (missing %s)\n", lost, broken.Root.Event.EventID, broken.MissingCause)
+		passed = false
+	}
+	if len(cyclic.Cycles) != 1 || len(cyclic.Root.Children) != 1 || len(cyclic.Root.Children[0].Children) != 0 {
+		fmt.Printf("FAIL: Expected the cycle to be cut after two events, cycles %v\n", cyclic.Cycles)
+		passed = false
+	}
+	if !errors.Is(unknownErr, ErrTraceEventNotFound) {
+		fmt.Printf("FAIL: Expected ErrTraceEventNotFound for an unknown event, got %v\n", unknownErr)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll causation trace tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,370 @@
+// KurrentDB Go Client Example - Fencing tokens for a single projection writer
+// Demonstrates: Rejecting a stale instance's read model writes once another instance has taken over the projection
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === FENCING ===
+//
+// Two instances of a projection writing to the same read model apply every event twice, e.g. when
+// a deployment overlaps the old and new versions, or a paused instance (a long GC pause, a frozen
+// VM, a network partition) is replaced and then wakes up. The checkpoint alone doesn't stop it: both
+// instances read it before either writes.
+//
+// Acquire issues a fencing token, one higher than any issued before for the projection, and
+// WithToken makes the read model write with it. Apply checks the token in the same transaction that
+// updates the rows and the checkpoint, and fails with ErrFenced unless it is the latest.
+//
+// The guarantee: once Acquire has returned token N, no transaction with a token below N commits. Of
+// any two instances, only the one that acquired last changes the read model, however long the other
+// was paused. Fencing doesn't pick the instance, or keep a second one from starting; whoever calls
+// Acquire wins, so call it only from the instance that should run, e.g. after winning a leader
+// election or from the new version in a deployment.
+//
+// Taking over:
+//  1. Acquire on the new instance. From then on the old instance's writes fail.
+//  2. Resume from the read model's Checkpoint, read after Acquire: it holds every event the old
+//     instance committed, and nothing it didn't.
+//  3. The old instance stops on its first ErrFenced. It must not retry or re-acquire by itself, or
+//     the two would keep fencing each other.
+
+// ErrFenced is returned for a write with a token older than the latest issued: another instance has
+// taken over the projection
+var ErrFenced = errors.New("fenced: another instance holds the projection")
+
+// FencingToken orders the instances that have acquired a projection; later ones have larger tokens
+type FencingToken int64
+
+// Acquire issues the next fencing token for the projection to owner, fencing off every earlier
+// holder. Tokens live in the read model's database, so they keep increasing across restarts.
+func (r *SQLiteOrderReadModel) Acquire(ctx context.Context, owner string) (FencingToken, error) {
+	var token FencingToken
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO fencing_tokens (projection, token, owner) VALUES (?, 1, ?)
+		ON CONFLICT(projection) DO UPDATE SET token = token + 1, owner = excluded.owner
+		RETURNING token`,
+		r.name, owner).Scan(&token)
+	if err != nil {
+		return 0, fmt.Errorf("acquire %s: %w", r.name, err)
+	}
+	return token, nil
+}
+
+// Holder returns the latest token and its owner; a zero token means none has been issued
+func (r *SQLiteOrderReadModel) Holder(ctx context.Context) (FencingToken, string, error) {
+	var token FencingToken
+	var owner string
+	err := r.db.QueryRowContext(ctx, `SELECT token, owner FROM fencing_tokens WHERE projection = ?`, r.name).Scan(&token, &owner)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, "", nil
+	}
+	return token, owner, err
+}
+
+// WithToken returns the read model writing with token. It shares the database connection with r,
+// so close only one of them.
+func (r *SQLiteOrderReadModel) WithToken(token FencingToken) *SQLiteOrderReadModel {
+	fenced := *r
+	fenced.token = token
+	return &fenced
+}
+
+// checkFence fails with ErrFenced unless r writes with the latest token. Without any tokens issued
+// writes are unfenced, as before fencing was introduced; once one is, tokenless writes fail too.
+func (r *SQLiteOrderReadModel) checkFence(ctx context.Context, tx *sql.Tx) error {
+	var latest FencingToken
+	var owner string
+	err := tx.QueryRowContext(ctx, `SELECT token, owner FROM fencing_tokens WHERE projection = ?`, r.name).Scan(&latest, &owner)
+	if errors.Is(err, sql.ErrNoRows) && r.token == 0 {
+		return nil
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if r.token != latest {
+		return fmt.Errorf("%w: writing %s with token %d, %s holds %d", ErrFenced, r.name, r.token, owner, latest)
+	}
+	return nil
+}
+
+// fencingDSN opens path so that two instances in separate processes can share it: transactions
+// take the write lock up front and wait for each other rather than fail with "database is locked"
+func fencingDSN(path string) string {
+	return "file:" + path + "?_pragma=busy_timeout(5000)&_txlock=immediate"
+}
+
+// === CHECKS ===
+
+// RunCheckpointFencingChecks simulates a split brain: a paused instance waking up after another
+// took over, then both writing at once. No server required.
+func RunCheckpointFencingChecks() {
+	ctx := context.Background()
+
+	checks := newChecker("checkpoint fencing")
+
+	dir, err := os.MkdirTemp("", "checkpoint-fencing")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	dbPath := filepath.Join(dir, "readmodel.db")
+
+	stream := Streams.Name("order", uuid.New().String())
+	var commit uint64
+	envelope := func(eventType, data string) Envelope {
+		commit += 100
+		return Envelope{
+			EventID:   uuid.New(),
+			EventType: eventType,
+			StreamID:  stream,
+			Data:      []byte(data),
+			Position:  kurrentdb.Position{Commit: commit, Prepare: commit},
+		}
+	}
+	history := []Envelope{
+		envelope("OrderCreated", `{"orderId":"1","customerId":"cust-1","amount":100}`),
+		envelope("ItemAdded", `{"item":"Widget","price":25}`),
+		envelope("ItemAdded", `{"item":"Gadget","price":30}`),
+		envelope("ItemAdded", `{"item":"Gizmo","price":5}`),
+	}
+	open := func() *SQLiteOrderReadModel {
+		readModel, err := OpenSQLiteOrderReadModel(fencingDSN(dbPath), "OrderSummary")
+		if err != nil {
+			panic(err)
+		}
+		return readModel
+	}
+	amount := func(readModel *SQLiteOrderReadModel) float64 {
+		row, err := readModel.Get(ctx, stream)
+		if err != nil {
+			return -1
+		}
+		return row.Amount
+	}
+
+	// --- Takeover ---
+	fmt.Println("\n--- Takeover ---")
+	first, second := open(), open()
+	defer first.Close()
+	defer second.Close()
+	tokenA, err := first.Acquire(ctx, "instance-a")
+	checks.Check(err == nil && tokenA == 1, "the first token should be 1, got %d (%v)", tokenA, err)
+	a := first.WithToken(tokenA)
+	for _, e := range history[:2] {
+		checks.Check(a.Apply(ctx, e, e.Position) == nil, "the holder's writes should commit")
+	}
+
+	// A pauses; B takes over and resumes from the checkpoint A left
+	tokenB, err := second.Acquire(ctx, "instance-b")
+	b := second.WithToken(tokenB)
+	checkpoint, _ := b.Checkpoint(ctx)
+	checks.Check(err == nil && tokenB > tokenA, "a takeover should get a larger token, got %d after %d (%v)", tokenB, tokenA, err)
+	checks.Check(checkpoint != nil && checkpoint.Commit == history[1].Position.Commit, "the new holder should resume after the old one's last write, got %v", checkpoint)
+	checks.Check(b.Apply(ctx, history[2], history[2].Position) == nil, "the new holder's write should commit")
+
+	// --- Split brain ---
+	fmt.Println("\n--- Split brain ---")
+	// A wakes up still holding the event it was about to apply
+	staleErr := a.Apply(ctx, history[2], history[2].Position)
+	fmt.Printf("  stale write: %v\n", staleErr)
+	checks.Check(errors.Is(staleErr, ErrFenced), "the old holder's write should be fenced, got %v", staleErr)
+	checks.Check(errors.Is(a.Apply(ctx, history[3], history[3].Position), ErrFenced), "the old holder's next event should be fenced too")
+	checks.Check(amount(b) == 155, "fenced writes should change nothing, amount %v", amount(b))
+
+	// Both instances write the rest at once
+	more := []Envelope{
+		envelope("ItemAdded", `{"item":"Doohickey","price":10}`),
+		envelope("OrderShipped", `{"shippedAt":"2024-01-15T10:00:00Z"}`),
+	}
+	rest := append([]Envelope{history[3]}, more...)
+	var wg sync.WaitGroup
+	results := make([][]error, 2)
+	for i, instance := range []*SQLiteOrderReadModel{a, b} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, e := range rest {
+				results[i] = append(results[i], instance.Apply(ctx, e, e.Position))
+			}
+		}()
+	}
+	wg.Wait()
+	for _, err := range results[0] {
+		checks.Check(errors.Is(err, ErrFenced), "every concurrent write by the old holder should be fenced, got %v", err)
+	}
+	for _, err := range results[1] {
+		checks.Check(err == nil, "every concurrent write by the holder should commit, got %v", err)
+	}
+	row, err := b.Get(ctx, stream)
+	fmt.Printf("  row: status=%s amount=%.2f items=%v\n", row.Status, row.Amount, row.Items)
+	checks.Check(err == nil && row.Amount == 170 && len(row.Items) == 4 && row.Status == "shipped", "each event should be applied once, got %+v (%v)", row, err)
+
+	// --- Tokenless and restarted writers ---
+	fmt.Println("\n--- Tokenless and restarted writers ---")
+	late := envelope("OrderCompleted", `{}`)
+	checks.Check(errors.Is(first.Apply(ctx, late, late.Position), ErrFenced), "a write without a token should be fenced once tokens are issued")
+	second.Close()
+	reopened := open()
+	defer reopened.Close()
+	tokenC, err := reopened.Acquire(ctx, "instance-c")
+	holder, owner, _ := reopened.Holder(ctx)
+	checks.Check(err == nil && tokenC == tokenB+1 && holder == tokenC && owner == "instance-c", "tokens should keep increasing across restarts, got %d after %d held by %s (%v)", tokenC, tokenB, owner, err)
+	checks.Check(reopened.WithToken(tokenB).Apply(ctx, late, late.Position) != nil, "the previous holder should be fenced after a restart")
+	checks.Check(reopened.WithToken(tokenC).Apply(ctx, late, late.Position) == nil, "the new holder should write")
+
+	checks.Done()
+}
+
+// === DEMO ===
+
+// RunCheckpointFencing projects an order with one instance, pauses it, lets a second take over,
+// then wakes the first
+func RunCheckpointFencing() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	dir, err := os.MkdirTemp("", "checkpoint-fencing")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	dbPath := filepath.Join(dir, "readmodel.db")
+
+	orderID := uuid.New().String()
+	stream := Streams.Name("order", orderID)
+	makeEvent := func(eventType string, data interface{}) kurrentdb.EventData {
+		payload, _ := json.Marshal(data)
+		return kurrentdb.EventData{EventID: uuid.New(), ContentType: kurrentdb.ContentTypeJson, EventType: eventType, Data: payload}
+	}
+	appendEvents := func(events ...kurrentdb.EventData) {
+		if _, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, events...); err != nil {
+			panic(err)
+		}
+	}
+
+	// Both instances follow only this order, from here on
+	head, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+	options := kurrentdb.SubscribeToAllOptions{
+		From:   head,
+		Filter: &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{stream}},
+	}
+	// Each instance is its own process with its own connection to the database
+	open := func() *SQLiteOrderReadModel {
+		readModel, err := OpenSQLiteOrderReadModel(fencingDSN(dbPath), "OrderSummary")
+		if err != nil {
+			panic(err)
+		}
+		return readModel
+	}
+	stopAt := func(eventType string) func(Envelope) bool {
+		return func(e Envelope) bool { return e.EventType == eventType }
+	}
+
+	// === INSTANCE A ===
+	fmt.Println("\n=== Instance A ===")
+	first := open()
+	defer first.Close()
+	tokenA, err := first.Acquire(ctx, "instance-a")
+	if err != nil {
+		panic(err)
+	}
+	a := first.WithToken(tokenA)
+	fmt.Printf("  acquired token %d\n", tokenA)
+	appendEvents(
+		makeEvent("OrderCreated", ProjectionOrderCreated{OrderID: orderID, CustomerID: "cust-1", Amount: 100}),
+		makeEvent("ItemAdded", ProjectionItemAdded{Item: "Widget", Price: 25}))
+	if err := NewTransactionalRunner(client, a, options).Run(ctx, stopAt("ItemAdded")); err != nil {
+		panic(err)
+	}
+	fmt.Println("  applied 2 events, then paused")
+
+	// === INSTANCE B TAKES OVER ===
+	fmt.Println("\n=== Instance B takes over ===")
+	second := open()
+	defer second.Close()
+	tokenB, err := second.Acquire(ctx, "instance-b")
+	if err != nil {
+		panic(err)
+	}
+	b := second.WithToken(tokenB)
+	fmt.Printf("  acquired token %d\n", tokenB)
+	appendEvents(
+		makeEvent("ItemAdded", ProjectionItemAdded{Item: "Gadget", Price: 30}),
+		makeEvent("OrderShipped", ProjectionOrderShipped{ShippedAt: "2024-01-15T10:00:00Z"}))
+	if err := NewTransactionalRunner(client, b, options).Run(ctx, stopAt("OrderShipped")); err != nil {
+		panic(err)
+	}
+	shipped, _ := b.Get(ctx, stream)
+	fmt.Printf("  applied through OrderShipped: amount %.2f\n", shipped.Amount)
+
+	// === INSTANCE A WAKES UP ===
+	fmt.Println("\n=== Instance A wakes up ===")
+	appendEvents(makeEvent("OrderCompleted", struct{}{}))
+	staleErr := NewTransactionalRunner(client, a, options).Run(ctx, stopAt("OrderCompleted"))
+	fmt.Printf("  stopped: %v\n", staleErr)
+	afterStale, _ := b.Get(ctx, stream)
+
+	// B, still the holder, picks the event up
+	if err := NewTransactionalRunner(client, b, options).Run(ctx, stopAt("OrderCompleted")); err != nil {
+		panic(err)
+	}
+	row, err := b.Get(ctx, stream)
+	if err != nil {
+		panic(err)
+	}
+	holder, owner, _ := b.Holder(ctx)
+	fmt.Printf("\nRow: status=%s amount=%.2f items=%v, held by %s with token %d\n", row.Status, row.Amount, row.Items, owner, holder)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if tokenB <= tokenA {
+		fmt.Printf("FAIL: Expected the takeover to get a larger token, got %d after %d\n", tokenB, tokenA)
+		passed = false
+	}
+	if !errors.Is(staleErr, ErrFenced) {
+		fmt.Printf("FAIL: Expected instance A to be fenced after the takeover, got %v\n", staleErr)
+		passed = false
+	}
+	if afterStale == nil || afterStale.Status != "shipped" {
+		fmt.Printf("FAIL: Expected instance A's write to leave the row as B left it, got %+v\n", afterStale)
+		passed = false
+	}
+	if row.Status != "completed" || row.Amount != 155 || len(row.Items) != 2 {
+		fmt.Printf("FAIL: Expected completed, amount 155 with 2 items, got %s, %.2f with %v\n", row.Status, row.Amount, row.Items)
+		passed = false
+	}
+	if holder != tokenB || owner != "instance-b" {
+		fmt.Printf("FAIL: Expected instance-b to hold token %d, got %s with %d\n", tokenB, owner, holder)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll checkpoint fencing tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,475 @@
+// KurrentDB Go Client Example - Compacted view of the latest event per stream
+// Demonstrates: Following $all into a view that keeps only each stream's latest event or derived value, dropping streams as they are deleted
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === COMPACTED VIEW ===
+
+// CompactedEntry is what a CompactedView keeps for one stream: the latest event it kept, without
+// the ones before
+type CompactedEntry struct {
+	Stream      string             `json:"stream"`
+	EventType   string             `json:"eventType"`
+	EventNumber uint64             `json:"eventNumber"`
+	Position    kurrentdb.Position `json:"position"`
+	Created     time.Time          `json:"created"`
+	Data        json.RawMessage    `json:"data,omitempty"`
+	// Value is what Derive returned, nil without Derive
+	Value interface{} `json:"value,omitempty"`
+}
+
+// CompactedView is the "current state of every entity" view: like a compacted topic, it keeps
+// one entry per stream, replaced by each newer event and removed when the stream is deleted, so
+// its memory follows the number of live streams rather than the number of events. Streams whose
+// names start with "$" are skipped, apart from the metadata streams that announce deletions.
+// Safe for queries on other goroutines while Follow applies events.
+type CompactedView struct {
+	Name string
+	// Derive computes the value kept for a stream from its newest event and the value kept before
+	// it, nil for a stream's first event. It returns false to ignore an event that doesn't change
+	// what the view shows, leaving the entry as it was. Without Derive every event is kept as it is.
+	Derive func(previous interface{}, event *kurrentdb.RecordedEvent) (interface{}, bool)
+
+	mu         sync.RWMutex
+	entries    map[string]*CompactedEntry
+	checkpoint *kurrentdb.Position
+	removed    int
+}
+
+func NewCompactedView(name string) *CompactedView {
+	return &CompactedView{Name: name, entries: make(map[string]*CompactedEntry)}
+}
+
+// CompactedFilter is the server-side filter for a view over the streams starting with prefixes:
+// their metadata streams are included, so soft deletes reach the view
+func CompactedFilter(prefixes ...string) *kurrentdb.SubscriptionFilter {
+	filter := &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType}
+	for _, prefix := range prefixes {
+		filter.Prefixes = append(filter.Prefixes, prefix, "$$"+prefix)
+	}
+	return filter
+}
+
+// Apply replaces the entry of the event's stream, or removes it when the event deletes the stream,
+// and moves the checkpoint to position. It reports whether the view changed.
+func (v *CompactedView) Apply(event *kurrentdb.RecordedEvent, position kurrentdb.Position) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.checkpoint == nil || positionAfter(position, *v.checkpoint) {
+		v.checkpoint = &position
+	}
+
+	switch {
+	case event.EventType == "$streamDeleted":
+		// A tombstone is the last event a hard-deleted stream ever gets
+		return v.remove(event.StreamID)
+	case strings.HasPrefix(event.StreamID, "$$") && event.EventType == "$metadata":
+		return v.truncate(strings.TrimPrefix(event.StreamID, "$$"), event.Data)
+	case strings.HasPrefix(event.StreamID, "$") || strings.HasPrefix(event.EventType, "$"):
+		return false
+	}
+
+	entry := v.entries[event.StreamID]
+	if entry != nil && event.EventNumber <= entry.EventNumber {
+		// Already kept, e.g. replayed after a resubscription from an older checkpoint
+		return false
+	}
+	var value interface{}
+	if v.Derive != nil {
+		var previous interface{}
+		if entry != nil {
+			previous = entry.Value
+		}
+		var keep bool
+		if value, keep = v.Derive(previous, event); !keep {
+			return false
+		}
+	}
+	v.entries[event.StreamID] = &CompactedEntry{
+		Stream:      event.StreamID,
+		EventType:   event.EventType,
+		EventNumber: event.EventNumber,
+		Position:    event.Position,
+		Created:     event.CreatedDate,
+		Data:        bytes.Clone(event.Data),
+		Value:       value,
+	}
+	return true
+}
+
+// truncate applies a metadata change to stream: a $tb past its kept event, which is what a soft
+// delete sets, means the event can no longer be read and the stream is gone from the view. Other
+// metadata, $maxAge and $maxCount included, leaves the entry alone.
+func (v *CompactedView) truncate(stream string, metadata []byte) bool {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(metadata, &fields) != nil {
+		return false
+	}
+	raw, ok := fields["$tb"]
+	if !ok {
+		return false
+	}
+	truncateBefore, err := strconv.ParseUint(string(raw), 10, 64)
+	if err != nil {
+		return false
+	}
+	if entry := v.entries[stream]; entry != nil && truncateBefore > entry.EventNumber {
+		return v.remove(stream)
+	}
+	return false
+}
+
+func (v *CompactedView) remove(stream string) bool {
+	if _, ok := v.entries[stream]; !ok {
+		return false
+	}
+	delete(v.entries, stream)
+	v.removed++
+	return true
+}
+
+// Advance moves the checkpoint for a filtered-out position, like Projection.Advance
+func (v *CompactedView) Advance(position kurrentdb.Position) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.checkpoint == nil || positionAfter(position, *v.checkpoint) {
+		v.checkpoint = &position
+	}
+}
+
+// Get returns the entry kept for stream
+func (v *CompactedView) Get(stream string) (CompactedEntry, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	entry, ok := v.entries[stream]
+	if !ok {
+		return CompactedEntry{}, false
+	}
+	return *entry, true
+}
+
+// Entries returns every entry, sorted by stream
+func (v *CompactedView) Entries() []CompactedEntry {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	entries := make([]CompactedEntry, 0, len(v.entries))
+	for _, stream := range slices.Sorted(maps.Keys(v.entries)) {
+		entries = append(entries, *v.entries[stream])
+	}
+	return entries
+}
+
+// Len returns how many streams the view holds, which bounds its memory
+func (v *CompactedView) Len() int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return len(v.entries)
+}
+
+// Removed returns how many streams deletes have taken out of the view
+func (v *CompactedView) Removed() int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return v.removed
+}
+
+// Checkpoint returns the position of the last event applied, nil before the first
+func (v *CompactedView) Checkpoint() *kurrentdb.Position {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return v.checkpoint
+}
+
+// Follow subscribes to $all on store with options and applies every event until done returns true
+// for an event that changed the view, then returns nil. Links aren't resolved: the view keeps the
+// streams events were written to. It returns the drop error if the subscription drops.
+func (v *CompactedView) Follow(ctx context.Context, store EventStore, options kurrentdb.SubscribeToAllOptions, done func(event *kurrentdb.RecordedEvent) bool) error {
+	subscription, err := store.SubscribeToAll(ctx, options)
+	if err != nil {
+		return err
+	}
+	defer subscription.Close()
+
+	for {
+		event := subscription.Recv()
+		if event.SubscriptionDropped != nil {
+			if event.SubscriptionDropped.Error != nil {
+				return event.SubscriptionDropped.Error
+			}
+			return errors.New("subscription dropped")
+		}
+		if event.CheckPointReached != nil {
+			v.Advance(*event.CheckPointReached)
+		}
+		if event.EventAppeared == nil {
+			continue
+		}
+
+		recorded := event.EventAppeared.OriginalEvent()
+		if v.Apply(recorded, recorded.Position) && done(recorded) {
+			return nil
+		}
+	}
+}
+
+// WaitUntil polls the view until condition holds or ctx ends, for demos and tests that need a
+// live view to catch up with their writes
+func (v *CompactedView) WaitUntil(ctx context.Context, condition func(v *CompactedView) bool) error {
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+	for !condition(v) {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", v.Name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// === ORDER STATUS ===
+
+// orderStatuses maps the order events that change an order's status to the status
+var orderStatuses = map[string]string{
+	"OrderCreated":   "created",
+	"OrderShipped":   "shipped",
+	"OrderCompleted": "completed",
+	"OrderCancelled": "cancelled",
+}
+
+// NewOrderStatusView keeps the current status of every order; events like ItemAdded that don't
+// change it are ignored
+func NewOrderStatusView() *CompactedView {
+	view := NewCompactedView("OrderStatus")
+	view.Derive = func(previous interface{}, event *kurrentdb.RecordedEvent) (interface{}, bool) {
+		status, ok := orderStatuses[event.EventType]
+		return status, ok
+	}
+	return view
+}
+
+// softDeleteMetadata is the stream metadata a soft delete writes: $tb at the largest revision
+var softDeleteMetadata = fmt.Sprintf(`{"$tb":%d}`, int64(math.MaxInt64))
+
+// printCompactedView prints one line per stream
+func printCompactedView(view *CompactedView) {
+	for _, entry := range view.Entries() {
+		fmt.Printf("  %-48s %-10v %s@%d\n", entry.Stream, entry.Value, entry.EventType, entry.EventNumber)
+	}
+}
+
+// === CHECKS ===
+
+// RunCompactedViewChecks applies replaces, deletes and live events to views over an in-memory log
+func RunCompactedViewChecks() {
+	checks := newChecker("compacted view")
+
+	status := func(view *CompactedView, stream string) interface{} {
+		entry, _ := view.Get(stream)
+		return entry.Value
+	}
+
+	fmt.Println("\n--- Latest event wins ---")
+	raw := NewCompactedView("Latest")
+	raw.Apply(syntheticEvent("order-1", "OrderCreated", 0, 100, `{"orderId":"1"}`), kurrentdb.Position{Commit: 100})
+	raw.Apply(syntheticEvent("order-1", "ItemAdded", 1, 200, `{"item":"widget"}`), kurrentdb.Position{Commit: 200})
+	entry, ok := raw.Get("order-1")
+	checks.Check(ok && entry.EventType == "ItemAdded" && entry.EventNumber == 1 && string(entry.Data) == `{"item":"widget"}`,
+		"the view should keep the newest event, got %+v", entry)
+	checks.Check(!raw.Apply(syntheticEvent("order-1", "OrderCreated", 0, 100, `{}`), kurrentdb.Position{Commit: 100}),
+		"replaying an older event shouldn't replace a newer one")
+	checks.Check(!raw.Apply(syntheticEvent("$ce-order", "$>", 0, 300, `0@order-1`), kurrentdb.Position{Commit: 300}) && raw.Len() == 1,
+		"system streams should be skipped, got %d entries", raw.Len())
+	checks.Check(raw.Checkpoint() != nil && raw.Checkpoint().Commit == 300, "skipped events should still move the checkpoint, got %v", raw.Checkpoint())
+
+	fmt.Println("\n--- Derived status ---")
+	view := NewOrderStatusView()
+	commit := uint64(0)
+	apply := func(stream, eventType string, number uint64, data string) bool {
+		commit += 100
+		return view.Apply(syntheticEvent(stream, eventType, number, commit, data), kurrentdb.Position{Commit: commit})
+	}
+	apply("order-1", "OrderCreated", 0, `{}`)
+	apply("order-1", "OrderShipped", 1, `{}`)
+	checks.Check(!apply("order-1", "ItemAdded", 2, `{}`), "an event that doesn't change the status should be ignored")
+	entry, _ = view.Get("order-1")
+	checks.Check(entry.Value == "shipped" && entry.EventType == "OrderShipped", "order-1 should be shipped by its OrderShipped, got %+v", entry)
+	apply("order-2", "OrderCreated", 0, `{}`)
+	apply("order-3", "OrderCreated", 0, `{}`)
+	apply("order-3", "OrderCompleted", 1, `{}`)
+	checks.Check(view.Len() == 3 && status(view, "order-2") == "created" && status(view, "order-3") == "completed",
+		"every order should show its current status, got %d entries", view.Len())
+
+	fmt.Println("\n--- Deletes ---")
+	checks.Check(!apply("$$order-1", "$metadata", 0, `{"$tb":1,"$maxCount":10}`) && status(view, "order-1") == "shipped",
+		"truncating before an event the view kept should leave it")
+	checks.Check(apply("$$order-2", "$metadata", 0, softDeleteMetadata), "a soft delete should remove the stream")
+	_, ok = view.Get("order-2")
+	checks.Check(!ok, "order-2 should be gone after its soft delete")
+	checks.Check(apply("order-3", "$streamDeleted", math.MaxInt64, `{}`), "a tombstone should remove the stream")
+	checks.Check(view.Len() == 1 && view.Removed() == 2, "one order should remain with two removed, got %d and %d", view.Len(), view.Removed())
+	checks.Check(apply("order-2", "OrderCreated", 1, `{}`) && status(view, "order-2") == "created",
+		"a soft-deleted stream written to again should come back")
+	checks.Check(!apply("$$order-9", "$metadata", 0, softDeleteMetadata), "deleting a stream the view never held shouldn't change it")
+
+	fmt.Println("\n--- Memory follows streams, not events ---")
+	bounded := NewOrderStatusView()
+	commit = 0
+	for i := 0; i < 5000; i++ {
+		commit += 100
+		eventType := []string{"OrderCreated", "OrderShipped", "OrderCompleted"}[i%3]
+		bounded.Apply(syntheticEvent(fmt.Sprintf("order-%d", i%50), eventType, uint64(i/50), commit, `{}`), kurrentdb.Position{Commit: commit})
+	}
+	checks.Check(bounded.Len() == 50, "5000 events over 50 orders should keep 50 entries, got %d", bounded.Len())
+
+	fmt.Println("\n--- Following a subscription ---")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	store := NewMemoryEventStore()
+	write := func(stream, eventType, data string) {
+		store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID: uuid.New(), EventType: eventType, ContentType: kurrentdb.ContentTypeJson, Data: []byte(data),
+		})
+	}
+	write("order-a", "OrderCreated", `{}`)
+	write("invoice-a", "InvoiceIssued", `{}`)
+	live := NewOrderStatusView()
+	followed := make(chan error, 1)
+	go func() {
+		followed <- live.Follow(ctx, store, kurrentdb.SubscribeToAllOptions{From: kurrentdb.Start{}, Filter: CompactedFilter("order-")},
+			func(event *kurrentdb.RecordedEvent) bool { return event.StreamID == "order-c" })
+	}()
+	write("order-b", "OrderCreated", `{}`)
+	write("order-a", "OrderShipped", `{}`)
+	write("$$order-b", "$metadata", softDeleteMetadata)
+	write("order-c", "OrderCreated", `{}`)
+	select {
+	case err := <-followed:
+		checks.Check(err == nil, "Follow should return once done, got %v", err)
+	case <-ctx.Done():
+		checks.Check(false, "the view should reach order-c")
+	}
+	entries := live.Entries()
+	checks.Check(len(entries) == 2 && entries[0].Stream == "order-a" && entries[0].Value == "shipped" && entries[1].Stream == "order-c",
+		"the live view should hold order-a shipped and order-c, got %+v", entries)
+	printCompactedView(live)
+
+	checks.Done()
+}
+
+// === DEMO ===
+
+// RunCompactedView follows a live "all orders, current status" view while orders are created,
+// shipped, soft-deleted and tombstoned
+func RunCompactedView() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	head, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+	category := "orderstatus" + strings.ReplaceAll(uuid.New().String()[:8], "-", "")
+	prefix := category + "-"
+	view := NewOrderStatusView()
+	followCtx, stopFollowing := context.WithCancel(ctx)
+	defer stopFollowing()
+	go view.Follow(followCtx, NewClientStore(client), kurrentdb.SubscribeToAllOptions{From: head, Filter: CompactedFilter(prefix)},
+		func(*kurrentdb.RecordedEvent) bool { return false })
+
+	appendEvent := func(stream, eventType string) error {
+		_, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID: uuid.New(), EventType: eventType, ContentType: kurrentdb.ContentTypeJson, Data: []byte(`{}`),
+		})
+		return err
+	}
+	orders := make([]string, 6)
+	var writeErr error
+	for i := range orders {
+		orders[i] = Streams.Name(category, fmt.Sprintf("%d", i))
+		writeErr = errors.Join(writeErr, appendEvent(orders[i], "OrderCreated"), appendEvent(orders[i], "ItemAdded"))
+	}
+	writeErr = errors.Join(writeErr,
+		appendEvent(orders[0], "OrderShipped"),
+		appendEvent(orders[1], "OrderShipped"),
+		appendEvent(orders[1], "OrderCompleted"),
+		appendEvent(orders[2], "OrderCancelled"),
+	)
+	waitErr := view.WaitUntil(ctx, func(v *CompactedView) bool { return v.Len() == 6 })
+	fmt.Println("\nAll orders, current status:")
+	printCompactedView(view)
+
+	_, deleteErr := client.DeleteStream(ctx, orders[3], kurrentdb.DeleteStreamOptions{StreamState: kurrentdb.Any{}})
+	_, tombstoneErr := client.TombstoneStream(ctx, orders[4], kurrentdb.TombstoneStreamOptions{StreamState: kurrentdb.Any{}})
+	deletedErr := view.WaitUntil(ctx, func(v *CompactedView) bool { return v.Len() == 4 })
+	fmt.Printf("\nAfter deleting %s and tombstoning %s:\n", orders[3], orders[4])
+	printCompactedView(view)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+	if writeErr != nil || deleteErr != nil || tombstoneErr != nil {
+		fmt.Printf("FAIL: Writing and deleting should succeed, got %v / %v / %v\n", writeErr, deleteErr, tombstoneErr)
+		passed = false
+	}
+	if waitErr != nil || deletedErr != nil {
+		fmt.Printf("FAIL: The view should catch up with the writes and deletes, got %v / %v\n", waitErr, deletedErr)
+		passed = false
+	}
+	want := map[string]interface{}{orders[0]: "shipped", orders[1]: "completed", orders[2]: "cancelled", orders[5]: "created"}
+	for stream, status := range want {
+		if entry, ok := view.Get(stream); !ok || entry.Value != status {
+			fmt.Printf("FAIL: %s should be %v, got %+v\n", stream, status, entry)
+			passed = false
+		}
+	}
+	for _, deleted := range orders[3:5] {
+		if _, ok := view.Get(deleted); ok {
+			fmt.Printf("FAIL: %s was deleted and should have left the view\n", deleted)
+			passed = false
+		}
+	}
+	if view.Removed() != 2 {
+		fmt.Printf("FAIL: Two deletes should have been applied, got %d\n", view.Removed())
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll compacted view tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,475 @@
+// KurrentDB Go Client Example - Append concurrency limiter
+// Demonstrates: Capping the appends in flight with a resizable semaphore, with queue depth and wait time metrics
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === LIMITER ===
+
+// ConcurrencyLimiter lets at most Limit callers hold a slot at once and queues the rest in arrival
+// order. Unlike a buffered channel, the limit can be changed while callers hold and wait for slots.
+// Safe for concurrent use.
+type ConcurrencyLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	inFlight int
+	waiting  []*limiterWaiter
+
+	acquired  int64
+	cancelled int64
+	maxQueued int
+	totalWait time.Duration
+	maxWait   time.Duration
+}
+
+// limiterWaiter is a queued Acquire; ready is closed once it has been given a slot
+type limiterWaiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// ConcurrencyLimiterStats is what the limiter has done so far
+type ConcurrencyLimiterStats struct {
+	Limit    int
+	InFlight int
+	// Queued is how many callers are waiting now, MaxQueued the most there have been at once
+	Queued    int
+	MaxQueued int
+	// Acquired counts slots handed out, Cancelled the waits that ended with their context instead
+	Acquired  int64
+	Cancelled int64
+	// TotalWait and MaxWait cover every acquired slot, including those that didn't have to wait
+	TotalWait time.Duration
+	MaxWait   time.Duration
+}
+
+// MeanWait returns the average time to get a slot
+func (s ConcurrencyLimiterStats) MeanWait() time.Duration {
+	if s.Acquired == 0 {
+		return 0
+	}
+	return s.TotalWait / time.Duration(s.Acquired)
+}
+
+// NewConcurrencyLimiter allows limit slots at once; limit is raised to 1 if lower
+func NewConcurrencyLimiter(limit int) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{limit: max(limit, 1)}
+}
+
+// Acquire waits for a slot and returns the function that gives it back, which must be called
+// exactly once (later calls do nothing). If ctx ends first it returns ctx's error and holds no slot.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context) (func(), error) {
+	start := time.Now()
+
+	l.mu.Lock()
+	if l.inFlight < l.limit && len(l.waiting) == 0 {
+		l.inFlight++
+		l.recordAcquired(0)
+		l.mu.Unlock()
+		return l.releaser(), nil
+	}
+	waiter := &limiterWaiter{ready: make(chan struct{})}
+	l.waiting = append(l.waiting, waiter)
+	l.maxQueued = max(l.maxQueued, len(l.waiting))
+	l.mu.Unlock()
+
+	select {
+	case <-waiter.ready:
+		l.mu.Lock()
+		l.recordAcquired(time.Since(start))
+		l.mu.Unlock()
+		return l.releaser(), nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cancelled++
+	if waiter.granted {
+		// The slot arrived as ctx ended: pass it on rather than leak it
+		l.inFlight--
+		l.grant()
+	} else {
+		for i, queued := range l.waiting {
+			if queued == waiter {
+				l.waiting = append(l.waiting[:i], l.waiting[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil, fmt.Errorf("wait for a slot: %w", ctx.Err())
+}
+
+// recordAcquired counts a slot handed out after waiting for wait; l.mu must be held
+func (l *ConcurrencyLimiter) recordAcquired(wait time.Duration) {
+	l.acquired++
+	l.totalWait += wait
+	l.maxWait = max(l.maxWait, wait)
+}
+
+func (l *ConcurrencyLimiter) releaser() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.inFlight--
+			l.grant()
+		})
+	}
+}
+
+// grant hands free slots to waiters in arrival order; l.mu must be held
+func (l *ConcurrencyLimiter) grant() {
+	for l.inFlight < l.limit && len(l.waiting) > 0 {
+		waiter := l.waiting[0]
+		l.waiting = l.waiting[1:]
+		l.inFlight++
+		waiter.granted = true
+		close(waiter.ready)
+	}
+}
+
+// SetLimit changes the limit, raised to 1 if lower. Raising it hands the new slots to waiters at
+// once; lowering it takes effect as slots are released, without interrupting the callers holding
+// them.
+func (l *ConcurrencyLimiter) SetLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = max(limit, 1)
+	l.grant()
+}
+
+// Limit returns the current limit
+func (l *ConcurrencyLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// Stats returns the limiter's current state and totals
+func (l *ConcurrencyLimiter) Stats() ConcurrencyLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return ConcurrencyLimiterStats{
+		Limit:     l.limit,
+		InFlight:  l.inFlight,
+		Queued:    len(l.waiting),
+		MaxQueued: l.maxQueued,
+		Acquired:  l.acquired,
+		Cancelled: l.cancelled,
+		TotalWait: l.totalWait,
+		MaxWait:   l.maxWait,
+	}
+}
+
+// === LIMITED APPENDS ===
+
+// ConcurrencyLimitedStore is an EventStore whose appends hold a slot of Limiter, so however many
+// goroutines append at once, at most the limit's worth reach the server together and the rest queue
+// in the process instead of piling up on it. Reads and subscriptions pass straight through. Share one
+// Limiter between stores that should share the budget.
+type ConcurrencyLimitedStore struct {
+	EventStore
+	Limiter *ConcurrencyLimiter
+}
+
+func NewConcurrencyLimitedStore(store EventStore, limiter *ConcurrencyLimiter) *ConcurrencyLimitedStore {
+	return &ConcurrencyLimitedStore{EventStore: store, Limiter: limiter}
+}
+
+// AppendToStream waits for a slot, then appends. Time spent waiting counts against ctx's deadline,
+// and an append whose ctx ends while it waits is never sent.
+func (s *ConcurrencyLimitedStore) AppendToStream(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+	release, err := s.Limiter.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("append to %s: %w", stream, err)
+	}
+	defer release()
+	return s.EventStore.AppendToStream(ctx, stream, options, events...)
+}
+
+// inFlightStore records how many appends are running in the store it wraps, and the most at once
+type inFlightStore struct {
+	EventStore
+	delay time.Duration
+
+	current atomic.Int64
+	peak    atomic.Int64
+}
+
+func (s *inFlightStore) AppendToStream(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+	current := s.current.Add(1)
+	defer s.current.Add(-1)
+	for peak := s.peak.Load(); current > peak && !s.peak.CompareAndSwap(peak, current); peak = s.peak.Load() {
+	}
+	time.Sleep(s.delay)
+	return s.EventStore.AppendToStream(ctx, stream, options, events...)
+}
+
+// burstAppend starts count appends at once, spread over streams, and waits for all of them. It
+// returns how many failed.
+func burstAppend(ctx context.Context, store EventStore, prefix string, streams, count int) int64 {
+	var failed atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			event := kurrentdb.EventData{
+				EventID:     uuid.New(),
+				EventType:   "BurstEvent",
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        []byte(fmt.Sprintf(`{"sequence":%d}`, i)),
+			}
+			if _, err := store.AppendToStream(ctx, fmt.Sprintf("%s-%d", prefix, i%streams), kurrentdb.AppendToStreamOptions{}, event); err != nil {
+				failed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	return failed.Load()
+}
+
+// RunConcurrencyLimiterChecks verifies the limit holds under a burst, resizing, and cancellation
+// while queued, without a server
+func RunConcurrencyLimiterChecks() {
+	checks := newChecker("concurrency limiter")
+
+	ctx := context.Background()
+
+	fmt.Println("\n--- Burst ---")
+	{
+		backend := &inFlightStore{EventStore: NewMemoryEventStore(), delay: time.Millisecond}
+		limiter := NewConcurrencyLimiter(20)
+		started := time.Now()
+		failed := burstAppend(ctx, NewConcurrencyLimitedStore(backend, limiter), "burst", 50, 10_000)
+		stats := limiter.Stats()
+		fmt.Printf("  10000 appends in %v: peak %d in flight, up to %d queued, mean wait %v, max wait %v\n",
+			time.Since(started).Round(time.Millisecond), backend.peak.Load(), stats.MaxQueued, stats.MeanWait().Round(time.Microsecond), stats.MaxWait.Round(time.Millisecond))
+		checks.Check(failed == 0 && stats.Acquired == 10_000, "every append should get a slot, %d failed, %d acquired", failed, stats.Acquired)
+		checks.Check(backend.peak.Load() <= 20, "at most 20 appends should run at once, saw %d", backend.peak.Load())
+		checks.Check(backend.peak.Load() == 20, "a burst should fill every slot, saw a peak of %d", backend.peak.Load())
+		checks.Check(stats.MaxQueued > 1000 && stats.MaxWait > 0, "a burst should queue, got %d queued and a %v max wait", stats.MaxQueued, stats.MaxWait)
+		checks.Check(stats.InFlight == 0 && stats.Queued == 0, "every slot should be returned, got %d in flight and %d queued", stats.InFlight, stats.Queued)
+		total := 0
+		for i := 0; i < 50; i++ {
+			events, _, _ := readStreamFrom(ctx, backend, fmt.Sprintf("burst-%d", i), NoVersion)
+			total += len(events)
+		}
+		checks.Check(total == 10_000, "every event should be stored, got %d", total)
+	}
+
+	fmt.Println("\n--- Resizing ---")
+	{
+		limiter := NewConcurrencyLimiter(2)
+		var releases []func()
+		for i := 0; i < 2; i++ {
+			release, _ := limiter.Acquire(ctx)
+			releases = append(releases, release)
+		}
+		acquired := make(chan func(), 5)
+		for i := 0; i < 5; i++ {
+			go func() {
+				release, err := limiter.Acquire(ctx)
+				if err == nil {
+					acquired <- release
+				}
+			}()
+		}
+		for limiter.Stats().Queued < 5 {
+			time.Sleep(time.Millisecond)
+		}
+
+		limiter.SetLimit(5)
+		for i := 0; i < 3; i++ {
+			releases = append(releases, <-acquired)
+		}
+		stats := limiter.Stats()
+		fmt.Printf("  raised to 5: %d in flight, %d queued\n", stats.InFlight, stats.Queued)
+		checks.Check(stats.InFlight == 5 && stats.Queued == 2, "raising the limit should let 3 waiters in, got %d in flight, %d queued", stats.InFlight, stats.Queued)
+
+		limiter.SetLimit(1)
+		for _, release := range releases[:4] {
+			release()
+		}
+		releases[0]() // a second call is ignored
+		stats = limiter.Stats()
+		fmt.Printf("  lowered to 1 and released 4: %d in flight, %d queued\n", stats.InFlight, stats.Queued)
+		checks.Check(stats.InFlight == 1 && stats.Queued == 2, "lowering the limit should let nothing in until in flight drops below it, got %d in flight, %d queued", stats.InFlight, stats.Queued)
+		releases[4]()
+		next := <-acquired
+		stats = limiter.Stats()
+		checks.Check(stats.InFlight == 1 && stats.Queued == 1 && limiter.Limit() == 1, "one waiter at a time should get the single slot, got %+v", stats)
+		next()
+		(<-acquired)()
+		checks.Check(limiter.Stats().InFlight == 0, "every slot should be returned, got %+v", limiter.Stats())
+	}
+
+	fmt.Println("\n--- Cancellation while queued ---")
+	{
+		limiter := NewConcurrencyLimiter(1)
+		hold, _ := limiter.Acquire(ctx)
+		store := NewConcurrencyLimitedStore(&countingStore{EventStore: NewMemoryEventStore()}, limiter)
+
+		waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		started := time.Now()
+		_, err := store.AppendToStream(waitCtx, "cancelled-1", kurrentdb.AppendToStreamOptions{}, sizedEvent("Waited", 10))
+		cancel()
+		fmt.Printf("  after %v: %v\n", time.Since(started).Round(time.Millisecond), err)
+		checks.Check(errors.Is(err, context.DeadlineExceeded), "an append whose context ends in the queue should fail with it, got %v", err)
+		checks.Check(store.EventStore.(*countingStore).calls() == nil, "a cancelled append should never be sent")
+		stats := limiter.Stats()
+		checks.Check(stats.Cancelled == 1 && stats.Queued == 0 && stats.InFlight == 1, "the waiter should leave the queue, got %+v", stats)
+
+		hold()
+		release, err := limiter.Acquire(ctx)
+		checks.Check(err == nil && limiter.Stats().InFlight == 1, "the slot should be free again, got %v", err)
+		release()
+
+		// Contexts ending as slots are handed over must neither leak nor duplicate slots
+		var wg sync.WaitGroup
+		var peak atomic.Int64
+		var current atomic.Int64
+		limiter.SetLimit(3)
+		for i := 0; i < 2000; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				waitCtx, cancel := context.WithTimeout(ctx, time.Duration(i%50)*time.Microsecond)
+				defer cancel()
+				release, err := limiter.Acquire(waitCtx)
+				if err != nil {
+					return
+				}
+				n := current.Add(1)
+				for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+				}
+				time.Sleep(10 * time.Microsecond)
+				current.Add(-1)
+				release()
+			}()
+		}
+		wg.Wait()
+		stats = limiter.Stats()
+		fmt.Printf("  2000 racing waits: %d acquired, %d cancelled, peak %d\n", stats.Acquired-2, stats.Cancelled-1, peak.Load())
+		checks.Check(stats.InFlight == 0 && stats.Queued == 0, "racing cancellations should leave no slot taken, got %+v", stats)
+		checks.Check(peak.Load() <= 3, "racing cancellations should never exceed the limit, saw %d", peak.Load())
+	}
+
+	checks.Done()
+}
+
+// RunConcurrencyLimiter sends a burst of 10k concurrent appends through a limiter and reports the
+// throughput it sustains, halving the limit half way through
+func RunConcurrencyLimiter() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	backend := &inFlightStore{EventStore: NewClientStore(client)}
+	limiter := NewConcurrencyLimiter(64)
+	store := NewConcurrencyLimitedStore(backend, limiter)
+
+	// === BURST ===
+	fmt.Println("\n=== 10000 concurrent appends, at most 64 in flight ===")
+	prefix := "burst" + uuid.New().String()[:8]
+	done := make(chan int64)
+	started := time.Now()
+	go func() { done <- burstAppend(ctx, store, prefix, 100, 10_000) }()
+
+	var failed int64
+	lowered, draining := false, false
+	peakAt64 := int64(0)
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+	last := int64(0)
+sampling:
+	for {
+		select {
+		case failed = <-done:
+			break sampling
+		case <-ticker.C:
+			stats := limiter.Stats()
+			rate := float64(stats.Acquired-int64(stats.InFlight)-last) * 4
+			last = stats.Acquired - int64(stats.InFlight)
+			fmt.Printf("  %6.0f appends/s  %2d in flight  %5d queued  mean wait %v\n", rate, stats.InFlight, stats.Queued, stats.MeanWait().Round(time.Millisecond))
+			if draining && stats.InFlight <= 16 {
+				// The appends running when the limit dropped have finished; measure from here
+				backend.peak.Store(backend.current.Load())
+				draining = false
+			}
+			if !lowered && last >= 5000 {
+				peakAt64 = backend.peak.Load()
+				limiter.SetLimit(16)
+				lowered, draining = true, true
+				fmt.Println("  -- limit lowered to 16 --")
+			}
+		}
+	}
+	elapsed := time.Since(started)
+	stats := limiter.Stats()
+	fmt.Printf("\n%d appends in %v (%.0f/s): up to %d queued, mean wait %v, max wait %v\n",
+		stats.Acquired, elapsed.Round(time.Millisecond), float64(stats.Acquired)/elapsed.Seconds(), stats.MaxQueued, stats.MeanWait().Round(time.Millisecond), stats.MaxWait.Round(time.Millisecond))
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if failed != 0 || stats.Acquired != 10_000 {
+		fmt.Printf("FAIL: Every append should succeed, %d failed of %d\n", failed, stats.Acquired)
+		passed = false
+	}
+	if peakAt64 > 64 {
+		fmt.Printf("FAIL: At most 64 appends should run at once, saw %d\n", peakAt64)
+		passed = false
+	}
+	if lowered && !draining && backend.peak.Load() > 16 {
+		fmt.Printf("FAIL: After lowering the limit at most 16 appends should run at once, saw %d\n", backend.peak.Load())
+		passed = false
+	}
+	if stats.MaxQueued < 1000 {
+		fmt.Printf("FAIL: The burst should have queued in the process, at most %d queued\n", stats.MaxQueued)
+		passed = false
+	}
+	if stats.InFlight != 0 || stats.Queued != 0 {
+		fmt.Printf("FAIL: Every slot should be returned, %d in flight and %d queued\n", stats.InFlight, stats.Queued)
+		passed = false
+	}
+	total := 0
+	for i := 0; i < 100; i++ {
+		events, _, err := readStreamFrom(ctx, store, fmt.Sprintf("%s-%d", prefix, i), NoVersion)
+		if err != nil {
+			panic(err)
+		}
+		total += len(events)
+	}
+	if total != 10_000 {
+		fmt.Printf("FAIL: Expected 10000 events stored, got %d\n", total)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll concurrency limiter tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,229 @@
+// KurrentDB Go Client Example - Stream-level expected content type enforcement
+// Demonstrates: Recording a stream's content type in metadata and rejecting mismatched appends
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === CONTENT TYPE GUARD ===
+
+// expectedContentTypeKey is the custom stream metadata key holding "json" or "binary"
+const expectedContentTypeKey = "expectedContentType"
+
+// ErrContentTypeMismatch is returned when an append doesn't match the stream's content type
+var ErrContentTypeMismatch = errors.New("content type mismatch")
+
+// ContentTypeGuard appends events only when they match the content type the stream was established with
+type ContentTypeGuard struct {
+	client *kurrentdb.Client
+}
+
+func NewContentTypeGuard(client *kurrentdb.Client) *ContentTypeGuard {
+	return &ContentTypeGuard{client: client}
+}
+
+// ExpectedContentType returns the content type a stream is restricted to.
+// It prefers the value stored in stream metadata and falls back to the content type
+// of the stream's last event. ok is false when the stream has no events yet.
+func (g *ContentTypeGuard) ExpectedContentType(ctx context.Context, stream string) (kurrentdb.ContentType, bool, error) {
+	metadata, err := g.client.GetStreamMetadata(ctx, stream, kurrentdb.ReadStreamOptions{})
+	if err != nil && !isStreamNotFound(err) {
+		return 0, false, err
+	}
+
+	if metadata != nil {
+		if value, ok := metadata.CustomProperty(expectedContentTypeKey).(string); ok {
+			contentType, err := parseContentTypeName(value)
+			if err != nil {
+				return 0, false, fmt.Errorf("stream %s has invalid %s metadata: %w", stream, expectedContentTypeKey, err)
+			}
+			return contentType, true, nil
+		}
+	}
+
+	// No metadata yet - derive it from the existing events, if any
+	last, err := LastEvent(ctx, g.client, stream)
+	if err != nil || last == nil {
+		return 0, false, err
+	}
+
+	return recordedContentType(last), true, nil
+}
+
+// Append validates every event against the stream's content type before appending.
+// The first write to an empty stream establishes the content type and stores it in metadata.
+func (g *ContentTypeGuard) Append(ctx context.Context, stream string, opts kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+	if len(events) == 0 {
+		return nil, fmt.Errorf("no events to append to %s", stream)
+	}
+
+	expected, established, err := g.ExpectedContentType(ctx, stream)
+	if err != nil {
+		return nil, err
+	}
+	if !established {
+		expected = events[0].ContentType
+	}
+
+	for _, event := range events {
+		if event.ContentType != expected {
+			return nil, fmt.Errorf("%w: stream %s only accepts %s events, got %s for %s",
+				ErrContentTypeMismatch, stream, contentTypeName(expected), contentTypeName(event.ContentType), event.EventType)
+		}
+	}
+
+	if !established {
+		if err := g.establish(ctx, stream, expected); err != nil {
+			return nil, err
+		}
+	}
+
+	return g.client.AppendToStream(ctx, stream, opts, events...)
+}
+
+// establish records the content type in the stream metadata, keeping any existing metadata.
+// Two writers racing on an empty stream can both establish a type; the last metadata write wins,
+// so callers needing a hard guarantee should also pass StreamState NoStream on the first append.
+func (g *ContentTypeGuard) establish(ctx context.Context, stream string, contentType kurrentdb.ContentType) error {
+	metadata, err := g.client.GetStreamMetadata(ctx, stream, kurrentdb.ReadStreamOptions{})
+	if err != nil && !isStreamNotFound(err) {
+		return err
+	}
+	if metadata == nil {
+		metadata = &kurrentdb.StreamMetadata{}
+	}
+
+	metadata.AddCustomProperty(expectedContentTypeKey, contentTypeName(contentType))
+
+	_, err = g.client.SetStreamMetadata(ctx, stream, kurrentdb.AppendToStreamOptions{}, *metadata)
+	return err
+}
+
+func contentTypeName(contentType kurrentdb.ContentType) string {
+	if contentType == kurrentdb.ContentTypeJson {
+		return "json"
+	}
+	return "binary"
+}
+
+func parseContentTypeName(name string) (kurrentdb.ContentType, error) {
+	switch name {
+	case "json":
+		return kurrentdb.ContentTypeJson, nil
+	case "binary":
+		return kurrentdb.ContentTypeBinary, nil
+	}
+	return 0, fmt.Errorf("unknown content type %q", name)
+}
+
+// recordedContentType maps the MIME type of a recorded event back to an append content type
+func recordedContentType(event *kurrentdb.RecordedEvent) kurrentdb.ContentType {
+	if event.ContentType == "application/json" {
+		return kurrentdb.ContentTypeJson
+	}
+	return kurrentdb.ContentTypeBinary
+}
+
+// errorCode returns the code of the client error in err's chain, or ErrorCodeUnknown if there is
+// none. kurrentdb.FromError only recognises an unwrapped error, and the client itself wraps some,
+// e.g. a missing stream in GetStreamMetadata.
+func errorCode(err error) kurrentdb.ErrorCode {
+	var esErr *kurrentdb.Error
+	if errors.As(err, &esErr) {
+		return esErr.Code()
+	}
+	return kurrentdb.ErrorCodeUnknown
+}
+
+// isStreamNotFound reports whether err is the client's "resource not found" error, or the
+// in-memory store's
+func isStreamNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, errMemoryStreamNotFound) {
+		return true
+	}
+	return errorCode(err) == kurrentdb.ErrorCodeResourceNotFound
+}
+
+// RunContentTypeGuard demonstrates rejecting a binary append to a JSON-only stream
+func RunContentTypeGuard() {
+	ctx := context.Background()
+
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	guard := NewContentTypeGuard(client)
+	streamName := fmt.Sprintf("document-%s", uuid.New().String())
+
+	// === FIRST WRITE ESTABLISHES JSON ===
+	fmt.Println("\n=== Establishing content type ===")
+
+	_, err := guard.Append(ctx, streamName, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+		EventID:     uuid.New(),
+		ContentType: kurrentdb.ContentTypeJson,
+		EventType:   "DocumentCreated",
+		Data:        []byte(`{"title":"Quarterly report"}`),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	expected, established, err := guard.ExpectedContentType(ctx, streamName)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Stream %s established as %s\n", streamName, contentTypeName(expected))
+
+	// === MATCHING APPEND ===
+	_, err = guard.Append(ctx, streamName, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+		EventID:     uuid.New(),
+		ContentType: kurrentdb.ContentTypeJson,
+		EventType:   "DocumentRenamed",
+		Data:        []byte(`{"title":"Q3 report"}`),
+	})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("Appended matching JSON event")
+
+	// === MISMATCHED APPEND ===
+	_, mismatchErr := guard.Append(ctx, streamName, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+		EventID:     uuid.New(),
+		ContentType: kurrentdb.ContentTypeBinary,
+		EventType:   "DocumentAttachment",
+		Data:        []byte{0x89, 0x50, 0x4e, 0x47},
+	})
+	fmt.Printf("Binary append rejected: %v\n", mismatchErr)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if !established || expected != kurrentdb.ContentTypeJson {
+		fmt.Println("FAIL: Stream should be established as json")
+		passed = false
+	}
+	if !errors.Is(mismatchErr, ErrContentTypeMismatch) {
+		fmt.Printf("FAIL: Binary append should fail with ErrContentTypeMismatch, got %v\n", mismatchErr)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll content type guard tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
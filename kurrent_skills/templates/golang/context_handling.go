@@ -0,0 +1,197 @@
+// KurrentDB Go Client Example - Deadlines and cancellation
+// Demonstrates: Per-operation timeouts for append/read, cancellable subscriptions, and the errors cancellation surfaces
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === OPERATION TIMEOUTS ===
+
+// Operation identifies the kind of call a context is created for
+type Operation int
+
+const (
+	// OperationAppend is a single round trip and should fail fast
+	OperationAppend Operation = iota
+	// OperationRead streams a bounded result set and gets a medium deadline
+	OperationRead
+	// OperationSubscribe is long-lived: no deadline, only explicit cancellation
+	OperationSubscribe
+)
+
+// OperationTimeouts are the defaults applied by WithOperationTimeout
+var OperationTimeouts = map[Operation]time.Duration{
+	OperationAppend: 5 * time.Second,
+	OperationRead:   30 * time.Second,
+}
+
+// WithOperationTimeout derives a context for op from parent. Appends and reads get a deadline;
+// subscriptions only get a cancel func, since a deadline would eventually drop a healthy subscription.
+// A deadline already on parent that is sooner always wins.
+func WithOperationTimeout(parent context.Context, op Operation) (context.Context, context.CancelFunc) {
+	timeout, ok := OperationTimeouts[op]
+	if !ok || op == OperationSubscribe {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// describeCancellation explains an error surfaced by a cancelled or timed-out call
+func describeCancellation(err error) string {
+	switch {
+	case err == nil:
+		return "no error"
+	case errors.Is(err, context.Canceled):
+		return fmt.Sprintf("context.Canceled (%v)", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Sprintf("context.DeadlineExceeded (%v)", err)
+	}
+
+	if esErr, ok := kurrentdb.FromError(err); !ok {
+		if esErr.Code() == kurrentdb.ErrorCodeDeadlineExceeded {
+			return fmt.Sprintf("kurrentdb.ErrorCodeDeadlineExceeded (%v)", err)
+		}
+		return fmt.Sprintf("kurrentdb error code %d (%v)", esErr.Code(), err)
+	}
+	return err.Error()
+}
+
+// RunContextHandling demonstrates deadlines per operation and prompt cancellation
+func RunContextHandling() {
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	streamName := fmt.Sprintf("context-%s", uuid.New().String())
+	passed := true
+
+	// === APPEND: SHORT DEADLINE ===
+	fmt.Println("\n=== Append with a short deadline ===")
+
+	events := make([]kurrentdb.EventData, 500)
+	for i := range events {
+		events[i] = kurrentdb.EventData{
+			EventID:     uuid.New(),
+			ContentType: kurrentdb.ContentTypeJson,
+			EventType:   "ContextSample",
+			Data:        []byte(fmt.Sprintf(`{"sequence":%d}`, i)),
+		}
+	}
+
+	appendCtx, cancelAppend := WithOperationTimeout(context.Background(), OperationAppend)
+	_, err := client.AppendToStream(appendCtx, streamName, kurrentdb.AppendToStreamOptions{}, events...)
+	cancelAppend()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Appended %d events within %s\n", len(events), OperationTimeouts[OperationAppend])
+
+	// An already-expired deadline fails before any work is done
+	expiredCtx, cancelExpired := context.WithTimeout(context.Background(), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	_, err = client.AppendToStream(expiredCtx, streamName, kurrentdb.AppendToStreamOptions{}, events[0])
+	cancelExpired()
+	fmt.Printf("Append with expired deadline: %s\n", describeCancellation(err))
+	if err == nil {
+		fmt.Println("FAIL: Append with an expired deadline should fail")
+		passed = false
+	}
+
+	// === READ: MEDIUM DEADLINE, CANCELLED MID-WAY ===
+	fmt.Println("\n=== Cancelling a read ===")
+
+	readCtx, cancelRead := WithOperationTimeout(context.Background(), OperationRead)
+	stream, err := client.ReadStream(readCtx, streamName, kurrentdb.ReadStreamOptions{
+		Direction: kurrentdb.Forwards,
+		From:      kurrentdb.Start{},
+	}, uint64(len(events)))
+	if err != nil {
+		panic(err)
+	}
+
+	read := 0
+	var readErr error
+	var cancelledAt time.Time
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+		read++
+		if read == 10 {
+			cancelledAt = time.Now()
+			cancelRead()
+		}
+	}
+	stream.Close()
+	stopDelay := time.Since(cancelledAt)
+
+	fmt.Printf("Read %d of %d events before Recv stopped after %s\n", read, len(events), stopDelay)
+	fmt.Printf("Read error: %s\n", describeCancellation(readErr))
+
+	// A few already-buffered events may still arrive, but not the whole stream
+	if readErr == nil || read >= len(events) {
+		fmt.Println("FAIL: Cancelling the read context should stop Recv with an error")
+		passed = false
+	}
+	if stopDelay > time.Second {
+		fmt.Printf("FAIL: Recv should stop promptly after cancel, took %s\n", stopDelay)
+		passed = false
+	}
+
+	// === SUBSCRIBE: NO DEADLINE, EXPLICIT CANCEL ===
+	fmt.Println("\n=== Cancelling a subscription ===")
+
+	subCtx, cancelSub := WithOperationTimeout(context.Background(), OperationSubscribe)
+	if _, hasDeadline := subCtx.Deadline(); hasDeadline {
+		fmt.Println("FAIL: Subscription context should not have a deadline")
+		passed = false
+	}
+
+	subscription, err := client.SubscribeToStream(subCtx, streamName, kurrentdb.SubscribeToStreamOptions{
+		From: kurrentdb.End{},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	time.AfterFunc(500*time.Millisecond, cancelSub)
+	subscribedAt := time.Now()
+
+	var dropErr error
+	for {
+		event := subscription.Recv()
+		if event.SubscriptionDropped != nil {
+			dropErr = event.SubscriptionDropped.Error
+			break
+		}
+	}
+	subscription.Close()
+
+	fmt.Printf("Subscription stopped after %s: %s\n", time.Since(subscribedAt).Round(time.Millisecond), describeCancellation(dropErr))
+	if time.Since(subscribedAt) > 2*time.Second {
+		fmt.Println("FAIL: Subscription should stop promptly after cancel")
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll context handling tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
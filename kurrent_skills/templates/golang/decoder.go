@@ -0,0 +1,224 @@
+// KurrentDB Go Client Example - Event decoder with a fallback for unknown types
+// Demonstrates: Decoding known event types into structs while passing unknown types through as UnknownEvent
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === REGISTRY ===
+
+// DecodeFunc turns raw event data into a typed value
+type DecodeFunc func(data []byte) (interface{}, error)
+
+// EventRegistry resolves an event type to its decoder. Implement it to plug in another source of
+// types, e.g. generated code or a schema registry.
+type EventRegistry interface {
+	Lookup(eventType string) (DecodeFunc, bool)
+}
+
+// TypeRegistry is a map-backed EventRegistry. Safe for concurrent use.
+type TypeRegistry struct {
+	mu       sync.RWMutex
+	decoders map[string]DecodeFunc
+}
+
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{decoders: make(map[string]DecodeFunc)}
+}
+
+func (r *TypeRegistry) Register(eventType string, decode DecodeFunc) *TypeRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.decoders[eventType] = decode
+	return r
+}
+
+func (r *TypeRegistry) Lookup(eventType string) (DecodeFunc, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	decode, ok := r.decoders[eventType]
+	return decode, ok
+}
+
+// RegisterJSON registers eventType to be JSON-decoded into a T value
+func RegisterJSON[T any](r *TypeRegistry, eventType string) *TypeRegistry {
+	return r.Register(eventType, func(data []byte) (interface{}, error) {
+		var value T
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	})
+}
+
+// NewOrderRegistry knows the order events used by the templates
+func NewOrderRegistry() *TypeRegistry {
+	registry := NewTypeRegistry()
+	RegisterJSON[OrderCreated](registry, "OrderCreated")
+	RegisterJSON[ItemAdded](registry, "ItemAdded")
+	RegisterJSON[OrderShipped](registry, "OrderShipped")
+	return registry
+}
+
+// === DECODER ===
+
+// UnknownEvent is returned for event types the registry doesn't know
+type UnknownEvent struct {
+	Type        string
+	RawData     []byte
+	RawMetadata []byte
+}
+
+// Decoder decodes envelopes using a registry
+type Decoder struct {
+	registry EventRegistry
+}
+
+func NewDecoder(registry EventRegistry) *Decoder {
+	return &Decoder{registry: registry}
+}
+
+// Decode returns the typed value for a known event type, or an UnknownEvent for anything else.
+// Only a known type whose data fails to decode is an error.
+func (d *Decoder) Decode(envelope Envelope) (interface{}, error) {
+	decode, ok := d.registry.Lookup(envelope.EventType)
+	if !ok {
+		return UnknownEvent{Type: envelope.EventType, RawData: envelope.Data, RawMetadata: envelope.Metadata}, nil
+	}
+
+	value, err := decode(envelope.Data)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s %s@%d: %w", envelope.EventType, envelope.StreamID, envelope.EventNumber, err)
+	}
+	return value, nil
+}
+
+// RunDecoder processes known order events from $all and logs the unknown ones
+func RunDecoder() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	head, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+
+	// === APPEND KNOWN AND UNKNOWN TYPES ===
+	orderID := uuid.New().String()
+	streamName := Streams.Name("order", orderID)
+
+	history := []struct{ eventType, data string }{
+		{"OrderCreated", fmt.Sprintf(`{"orderId":"%s","customerId":"cust-1","amount":0}`, orderID)},
+		{"ItemAdded", `{"item":"Widget","price":25}`},
+		{"CouponApplied", `{"code":"SPRING10","discount":10}`},
+		{"ItemAdded", `{"item":"Gadget","price":30}`},
+		{"GiftWrapRequested", `{}`},
+		{"OrderShipped", `{"shippedAt":"2024-01-15T10:00:00Z"}`},
+	}
+	for _, h := range history {
+		_, err := client.AppendToStream(ctx, streamName, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   h.eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(h.data),
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	fmt.Printf("Appended %d events to %s\n", len(history), streamName)
+
+	// === SUBSCRIBE AND DECODE ===
+	subscription, err := client.SubscribeToAll(ctx, kurrentdb.SubscribeToAllOptions{
+		From: head,
+		Filter: &kurrentdb.SubscriptionFilter{
+			Type:     kurrentdb.StreamFilterType,
+			Prefixes: []string{streamName},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer subscription.Close()
+
+	decoder := NewDecoder(NewOrderRegistry())
+	order := NewOrder(orderID)
+	var unknown []string
+	seen := 0
+
+	for seen < len(history) {
+		event := subscription.Recv()
+
+		if event.SubscriptionDropped != nil {
+			panic(event.SubscriptionDropped.Error)
+		}
+		if event.EventAppeared == nil {
+			continue
+		}
+		seen++
+
+		envelope := NewEnvelope(event.EventAppeared)
+		decoded, err := decoder.Decode(envelope)
+		if err != nil {
+			fmt.Printf("  Skipping malformed event: %v\n", err)
+			continue
+		}
+
+		switch e := decoded.(type) {
+		case OrderCreated:
+			order.Status = "created"
+			order.CustomerID = e.CustomerID
+			fmt.Printf("  OrderCreated for %s\n", e.CustomerID)
+		case ItemAdded:
+			order.Items = append(order.Items, e.Item)
+			order.Amount += e.Price
+			fmt.Printf("  ItemAdded %s at %.2f\n", e.Item, e.Price)
+		case OrderShipped:
+			order.Status = "shipped"
+			fmt.Printf("  OrderShipped at %s\n", e.ShippedAt)
+		case UnknownEvent:
+			unknown = append(unknown, e.Type)
+			fmt.Printf("  Unknown event type %s (%d bytes), passing through\n", e.Type, len(e.RawData))
+		}
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if fmt.Sprint(unknown) != "[CouponApplied GiftWrapRequested]" {
+		fmt.Printf("FAIL: Expected two unknown types, got %v\n", unknown)
+		passed = false
+	}
+	if order.Status != "shipped" || order.Amount != 55 || len(order.Items) != 2 {
+		fmt.Printf("FAIL: Known events should all be processed, got %s %.2f %v\n", order.Status, order.Amount, order.Items)
+		passed = false
+	}
+	if _, err := decoder.Decode(Envelope{EventType: "ItemAdded", Data: []byte(`{"price":"free"}`)}); err == nil {
+		fmt.Println("FAIL: A malformed known event should be a decode error")
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll decoder tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,100 @@
+// KurrentDB Go Client Example - Event deduplication window for at-least-once consumers
+package main
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// === DEDUPER ===
+
+// DeduperOptions configures the deduplication window
+type DeduperOptions struct {
+	// Capacity bounds how many event ids are remembered; the least recently seen is evicted first
+	Capacity int
+	// TTL additionally forgets ids older than this; zero keeps them until evicted by Capacity
+	TTL time.Duration
+}
+
+// Deduper remembers recently processed EventIDs so redelivered events can skip their side effects.
+// It is safe for concurrent use. Only ids within the window are detected: a redelivery after an id
+// was evicted runs again, so size Capacity/TTL above the subscription's retry horizon.
+type Deduper struct {
+	mu      sync.Mutex
+	options DeduperOptions
+	order   *list.List // front = most recently marked
+	entries map[uuid.UUID]*list.Element
+	now     func() time.Time
+}
+
+type dedupEntry struct {
+	id       uuid.UUID
+	markedAt time.Time
+}
+
+func NewDeduper(options DeduperOptions) *Deduper {
+	if options.Capacity <= 0 {
+		options.Capacity = 10000
+	}
+	return &Deduper{
+		options: options,
+		order:   list.New(),
+		entries: make(map[uuid.UUID]*list.Element),
+		now:     time.Now,
+	}
+}
+
+// Seen reports whether id was marked within the window
+func (d *Deduper) Seen(id uuid.UUID) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	element, ok := d.entries[id]
+	if !ok {
+		return false
+	}
+	if d.expired(element.Value.(*dedupEntry)) {
+		d.remove(element)
+		return false
+	}
+	d.order.MoveToFront(element)
+	return true
+}
+
+// Mark records id as processed. Call it after the side effect succeeded, so a failed attempt is retried.
+func (d *Deduper) Mark(id uuid.UUID) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if element, ok := d.entries[id]; ok {
+		element.Value.(*dedupEntry).markedAt = d.now()
+		d.order.MoveToFront(element)
+		return
+	}
+
+	d.entries[id] = d.order.PushFront(&dedupEntry{id: id, markedAt: d.now()})
+
+	for d.order.Len() > d.options.Capacity {
+		d.remove(d.order.Back())
+	}
+}
+
+// Len returns the number of ids currently in the window
+func (d *Deduper) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.order.Len()
+}
+
+func (d *Deduper) expired(entry *dedupEntry) bool {
+	return d.options.TTL > 0 && d.now().Sub(entry.markedAt) > d.options.TTL
+}
+
+func (d *Deduper) remove(element *list.Element) {
+	d.order.Remove(element)
+	delete(d.entries, element.Value.(*dedupEntry).id)
+}
@@ -0,0 +1,469 @@
+// KurrentDB Go Client Example - Subscription delivery simulator
+// Demonstrates: Load-testing handlers without a server by delivering synthetic events at a chosen rate, and finding the rate a handler saturates at
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === LOAD PROFILES ===
+
+// LoadProfile returns the rate, in events per second, to deliver at elapsed into a run
+type LoadProfile func(elapsed time.Duration) float64
+
+// SteadyLoad delivers at rate throughout
+func SteadyLoad(rate float64) LoadProfile {
+	return func(time.Duration) float64 { return rate }
+}
+
+// BurstyLoad delivers at base, except for the first burstFor of every period, when it delivers at
+// burst: the shape of a batch job or a reconnecting upstream catching up
+func BurstyLoad(base, burst float64, period, burstFor time.Duration) LoadProfile {
+	return func(elapsed time.Duration) float64 {
+		if elapsed%period < burstFor {
+			return burst
+		}
+		return base
+	}
+}
+
+// === STREAM DISTRIBUTIONS ===
+
+// StreamDistribution picks the index of the stream the next event goes to, out of streams
+type StreamDistribution func(random *rand.Rand, streams int) int
+
+// UniformStreams spreads events evenly across the streams
+func UniformStreams(random *rand.Rand, streams int) int {
+	return random.IntN(streams)
+}
+
+// ZipfStreams sends most events to a few hot streams, as real traffic tends to; s > 1 sets how
+// skewed, larger being more so
+func ZipfStreams(s float64) StreamDistribution {
+	var (
+		mu    sync.Mutex
+		zipfs = make(map[*rand.Rand]*rand.Zipf)
+	)
+	return func(random *rand.Rand, streams int) int {
+		mu.Lock()
+		defer mu.Unlock()
+
+		zipf := zipfs[random]
+		if zipf == nil {
+			zipf = rand.NewZipf(random, s, 1, uint64(streams-1))
+			zipfs[random] = zipf
+		}
+		return int(zipf.Uint64())
+	}
+}
+
+// === EVENT GENERATION ===
+
+// EventGenerator returns the type and data of the event numbered eventNumber in stream
+type EventGenerator func(random *rand.Rand, stream string, eventNumber uint64) (eventType string, data []byte)
+
+// OrderEvents opens each stream with OrderCreated and adds items to it after, in the shapes
+// NewOrderSummaryProjection handles
+func OrderEvents(random *rand.Rand, stream string, eventNumber uint64) (string, []byte) {
+	if eventNumber == 0 {
+		data, _ := json.Marshal(ProjectionOrderCreated{OrderID: stream, CustomerID: fmt.Sprintf("cust-%d", random.IntN(100)), Amount: 0})
+		return "OrderCreated", data
+	}
+	data, _ := json.Marshal(ProjectionItemAdded{Item: fmt.Sprintf("sku-%d", random.IntN(50)), Price: float64(1 + random.IntN(100))})
+	return "ItemAdded", data
+}
+
+// === SIMULATOR ===
+
+// SimulatorConfig describes the load a Simulator delivers
+type SimulatorConfig struct {
+	// Profile is the delivery rate over the run; Duration how long events are generated for
+	Profile  LoadProfile
+	Duration time.Duration
+	// Streams is how many streams events are spread across, by Distribution (UniformStreams if nil)
+	Streams      int
+	Distribution StreamDistribution
+	// Events generates each event; OrderEvents if nil
+	Events EventGenerator
+	// Buffer is how many generated events may wait for the handler, like a subscription's read-ahead;
+	// once it is full, generation waits for the handler, as the server's flow control would, and
+	// stops at Duration however far behind
+	Buffer int
+	// Seed makes the streams and data of a run repeatable; timing never is
+	Seed uint64
+}
+
+// SimulationReport is what a run delivered and how the handler kept up
+type SimulationReport struct {
+	// Target is the number of events Profile asks for over Duration. Generated fewer means the
+	// buffer filled and held generation back until Duration was up; the rest are never generated.
+	Target, Generated, Delivered, Errors int64
+	// Elapsed runs from the first event to the last one handled, including draining the buffer
+	Elapsed time.Duration
+	// TargetRate and Throughput are Target over Duration and Delivered over Elapsed
+	TargetRate, Throughput float64
+	// Latency is the time in the handler; Delay the time from an event being due to its handler
+	// starting, which grows without bound once the handler saturates
+	Latency, Delay HandlerStat
+	// ByType is the handler latency per event type
+	ByType map[string]HandlerStat
+	// MaxBacklog is the most events waiting in the buffer at once, besides the one being handled
+	MaxBacklog int
+}
+
+// Saturated reports whether the handler fell behind the target: throughput below 95% of it
+func (r SimulationReport) Saturated() bool {
+	return r.Throughput < 0.95*r.TargetRate
+}
+
+func (r SimulationReport) String() string {
+	return fmt.Sprintf("target %.0f/s, achieved %.0f/s, %d/%d delivered, p50 %v, p99 %v, delay p99 %v, backlog %d",
+		r.TargetRate, r.Throughput, r.Delivered, r.Target, r.Latency.Quantile(0.5), r.Latency.Quantile(0.99), r.Delay.Quantile(0.99), r.MaxBacklog)
+}
+
+// Simulator delivers synthetic events to a handler one at a time, in order, as a catch-up or
+// persistent subscription would, so handlers can be load-tested without a server. It measures the
+// handler and the queueing in front of it, not the client or the network.
+type Simulator struct {
+	config SimulatorConfig
+}
+
+func NewSimulator(config SimulatorConfig) *Simulator {
+	if config.Streams <= 0 {
+		config.Streams = 100
+	}
+	if config.Distribution == nil {
+		config.Distribution = UniformStreams
+	}
+	if config.Events == nil {
+		config.Events = OrderEvents
+	}
+	if config.Buffer <= 0 {
+		config.Buffer = 1000
+	}
+	return &Simulator{config: config}
+}
+
+// scheduledEnvelope is a generated event and when it was due
+type scheduledEnvelope struct {
+	envelope Envelope
+	due      time.Time
+}
+
+// Run generates events for the configured duration and delivers each to handler, then waits for the
+// buffer to drain. A handler error is counted and delivery goes on. Run stops early, with ctx's
+// error, if ctx ends.
+func (s *Simulator) Run(ctx context.Context, handler SinkHandler) (SimulationReport, error) {
+	config := s.config
+	random := rand.New(rand.NewPCG(config.Seed, config.Seed))
+	eventNumbers := make([]uint64, config.Streams)
+	run := uuid.New().String()[:8]
+
+	var report SimulationReport
+	buffer := make(chan scheduledEnvelope, config.Buffer)
+	started := time.Now()
+
+	// The generator schedules each event 1/rate after the previous one and sends it once due,
+	// catching up without sleeping when it is behind, so the rate holds even when sleeps overshoot
+	go func() {
+		defer close(buffer)
+		var commit uint64
+		heldBack := false
+		for offset := time.Duration(0); offset < config.Duration; {
+			rate := config.Profile(offset)
+			if rate <= 0 {
+				offset += time.Millisecond
+				continue
+			}
+			report.Target++
+			step := time.Duration(float64(time.Second) / rate)
+			if heldBack && time.Since(started) >= config.Duration {
+				// Held back past the end of the run: counted, so Generated shows the shortfall
+				offset += step
+				continue
+			}
+			due := started.Add(offset)
+			if wait := time.Until(due); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			index := config.Distribution(random, config.Streams)
+			stream := fmt.Sprintf("simulated-%s-%d", run, index)
+			eventType, data := config.Events(random, stream, eventNumbers[index])
+			commit++
+			envelope := Envelope{
+				EventID:     uuid.New(),
+				EventType:   eventType,
+				StreamID:    stream,
+				EventNumber: eventNumbers[index],
+				ContentType: "application/json",
+				Created:     due,
+				Data:        data,
+				Position:    kurrentdb.Position{Commit: commit, Prepare: commit},
+			}
+			eventNumbers[index]++
+
+			scheduled := scheduledEnvelope{envelope: envelope, due: due}
+			select {
+			case buffer <- scheduled:
+			default:
+				heldBack = true
+				select {
+				case buffer <- scheduled:
+				case <-ctx.Done():
+					return
+				}
+			}
+			report.Generated++
+			offset += step
+		}
+	}()
+
+	latency, delay := NewHandlerMetrics(), NewHandlerMetrics()
+	for scheduled := range buffer {
+		report.MaxBacklog = max(report.MaxBacklog, len(buffer))
+		handled := time.Now()
+		delay.Record("delay", handled.Sub(scheduled.due), nil)
+		err := handler(ctx, scheduled.envelope)
+		latency.Record(scheduled.envelope.EventType, time.Since(handled), err)
+		report.Delivered++
+		if err != nil {
+			report.Errors++
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	report.Elapsed = time.Since(started)
+	report.TargetRate = float64(report.Target) / config.Duration.Seconds()
+	report.Throughput = float64(report.Delivered) / report.Elapsed.Seconds()
+	report.ByType = latency.HandlerStats()
+	report.Latency = mergeHandlerStats(report.ByType)
+	report.Delay = delay.HandlerStats()["delay"]
+	return report, nil
+}
+
+// mergeHandlerStats adds up stats for several event types into one
+func mergeHandlerStats(stats map[string]HandlerStat) HandlerStat {
+	merged := HandlerStat{Buckets: make([]int64, len(HandlerLatencyBuckets)+1)}
+	for _, stat := range stats {
+		merged.Count += stat.Count
+		merged.Errors += stat.Errors
+		merged.Total += stat.Total
+		merged.Max = max(merged.Max, stat.Max)
+		for i, count := range stat.Buckets {
+			merged.Buckets[i] += count
+		}
+	}
+	return merged
+}
+
+// ProjectionHandler applies each envelope to projection, as Follow would for a subscription
+func ProjectionHandler(projection *Projection) SinkHandler {
+	return func(ctx context.Context, envelope Envelope) error {
+		_, err := projection.Apply(&kurrentdb.RecordedEvent{
+			EventID:      envelope.EventID,
+			EventType:    envelope.EventType,
+			ContentType:  envelope.ContentType,
+			StreamID:     envelope.StreamID,
+			EventNumber:  envelope.EventNumber,
+			Position:     envelope.Position,
+			Data:         envelope.Data,
+			UserMetadata: envelope.Metadata,
+			CreatedDate:  envelope.Created,
+		}, envelope.Position)
+		return err
+	}
+}
+
+// SaturationPoint runs config at each of rates in turn, with a fresh handler from newHandler, until
+// one saturates. It returns the reports so far and the last rate the handler kept up with, or 0 if
+// it didn't keep up with any.
+func SaturationPoint(ctx context.Context, config SimulatorConfig, rates []float64, newHandler func() SinkHandler) ([]SimulationReport, float64, error) {
+	var reports []SimulationReport
+	sustained := 0.0
+	for _, rate := range slices.Sorted(slices.Values(rates)) {
+		config.Profile = SteadyLoad(rate)
+		report, err := NewSimulator(config).Run(ctx, newHandler())
+		if err != nil {
+			return reports, sustained, err
+		}
+		reports = append(reports, report)
+		if report.Saturated() {
+			break
+		}
+		sustained = rate
+	}
+	return reports, sustained, nil
+}
+
+// slowWrite stands in for the read-model write a handler makes after projecting, e.g. an upsert
+// taking about cost
+func slowWrite(next SinkHandler, cost time.Duration) SinkHandler {
+	return func(ctx context.Context, envelope Envelope) error {
+		if err := next(ctx, envelope); err != nil {
+			return err
+		}
+		deadline := time.Now().Add(cost)
+		for time.Now().Before(deadline) {
+			// Spin rather than sleep: sleeps this short overshoot by more than the cost itself
+		}
+		return nil
+	}
+}
+
+// === CHECKS ===
+
+// RunDeliverySimulatorChecks drives an order projection through the simulator at rates it can and
+// can't keep up with. No server required.
+func RunDeliverySimulatorChecks() {
+	checks := newChecker("delivery simulator")
+
+	ctx := context.Background()
+
+	fmt.Println("\n--- Profiles ---")
+	bursty := BurstyLoad(100, 1000, time.Second, 200*time.Millisecond)
+	checks.Check(bursty(50*time.Millisecond) == 1000 && bursty(500*time.Millisecond) == 100 && bursty(1100*time.Millisecond) == 1000,
+		"bursts should repeat every period")
+	random := rand.New(rand.NewPCG(1, 1))
+	hits := make([]int, 10)
+	zipf := ZipfStreams(1.5)
+	for range 10000 {
+		hits[zipf(random, len(hits))]++
+	}
+	fmt.Printf("  zipf across 10 streams: %v\n", hits)
+	checks.Check(hits[0] > hits[1] && hits[1] > hits[9] && hits[0] > 3000, "zipf should favour the first streams, got %v", hits)
+
+	fmt.Println("\n--- Steady, under capacity ---")
+	projection := NewOrderSummaryProjection()
+	config := SimulatorConfig{Profile: SteadyLoad(200), Duration: 500 * time.Millisecond, Streams: 20, Seed: 7}
+	report, err := NewSimulator(config).Run(ctx, ProjectionHandler(projection))
+	fmt.Printf("  %s\n", report)
+	checks.Check(err == nil && report.Target == 100 && report.Delivered == 100 && report.Errors == 0, "every target event should be delivered, got %+v (%v)", report, err)
+	checks.Check(!report.Saturated() && report.Throughput > 150, "a fast handler should keep up, got %.0f/s", report.Throughput)
+	checks.Check(len(projection.State) == 20 && report.ByType["OrderCreated"].Count == 20, "each stream should open with OrderCreated, got %d streams and %+v", len(projection.State), report.ByType)
+	var total float64
+	for _, state := range projection.State {
+		total += state["amount"].(float64)
+	}
+	checks.Check(total > 0 && report.ByType["ItemAdded"].Count == 80, "items should be projected, got total %v", total)
+
+	// The same seed delivers the same streams and data
+	again := NewOrderSummaryProjection()
+	NewSimulator(config).Run(ctx, ProjectionHandler(again))
+	amounts := func(p *Projection) []float64 {
+		var amounts []float64
+		for _, state := range p.State {
+			amounts = append(amounts, state["amount"].(float64))
+		}
+		slices.Sort(amounts)
+		return amounts
+	}
+	checks.Check(slices.Equal(amounts(projection), amounts(again)), "a seeded run should repeat, got %v and %v", amounts(projection), amounts(again))
+
+	fmt.Println("\n--- Steady, over capacity ---")
+	slow := slowWrite(ProjectionHandler(NewOrderSummaryProjection()), 2*time.Millisecond)
+	report, err = NewSimulator(SimulatorConfig{Profile: SteadyLoad(2000), Duration: 300 * time.Millisecond, Buffer: 50}).Run(ctx, slow)
+	fmt.Printf("  %s\n", report)
+	checks.Check(err == nil && report.Saturated() && report.Throughput < 600, "a 2ms handler should not keep up with 2000/s, got %.0f/s", report.Throughput)
+	checks.Check(report.Generated < report.Target && report.MaxBacklog == 50, "a full buffer should hold generation back, got %d of %d, backlog %d", report.Generated, report.Target, report.MaxBacklog)
+	checks.Check(report.Latency.Quantile(0.5) >= time.Millisecond && report.Delay.Max > 10*time.Millisecond, "latency and delay should show the slow handler, got %v and %v", report.Latency.Quantile(0.5), report.Delay.Max)
+
+	fmt.Println("\n--- Bursty ---")
+	report, err = NewSimulator(SimulatorConfig{
+		Profile:  BurstyLoad(100, 2000, 200*time.Millisecond, 50*time.Millisecond),
+		Duration: 400 * time.Millisecond,
+	}).Run(ctx, slowWrite(ProjectionHandler(NewOrderSummaryProjection()), time.Millisecond))
+	fmt.Printf("  %s\n", report)
+	checks.Check(err == nil && report.Delivered == report.Target && report.Target >= 220 && report.Target <= 240, "every burst event should be delivered, got %d of %d (%v)", report.Delivered, report.Target, err)
+	checks.Check(report.MaxBacklog > 10 && report.Delay.Max > 5*time.Millisecond, "bursts should queue up behind the handler, got backlog %d", report.MaxBacklog)
+
+	fmt.Println("\n--- Errors and cancellation ---")
+	report, _ = NewSimulator(SimulatorConfig{Profile: SteadyLoad(1000), Duration: 50 * time.Millisecond}).Run(ctx, func(ctx context.Context, envelope Envelope) error {
+		if envelope.EventType == "OrderCreated" {
+			return fmt.Errorf("rejected")
+		}
+		return nil
+	})
+	checks.Check(report.Delivered == report.Target && report.Errors == report.ByType["OrderCreated"].Count && report.Errors > 0,
+		"handler errors should be counted without stopping delivery, got %+v", report)
+	cancelled, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	report, err = NewSimulator(SimulatorConfig{Profile: SteadyLoad(100), Duration: time.Hour}).Run(cancelled, ProjectionHandler(NewOrderSummaryProjection()))
+	cancel()
+	checks.Check(err == context.DeadlineExceeded && report.Delivered < 20, "a cancelled run should stop, got %v after %d events", err, report.Delivered)
+
+	checks.Done()
+}
+
+// === DEMO ===
+
+// RunDeliverySimulator ramps the load on an order projection with a 1ms read-model write until it
+// saturates, then shows how it copes with bursts above that rate. No server required.
+func RunDeliverySimulator() {
+	ctx := context.Background()
+
+	fmt.Println("=== Finding the saturation point ===")
+	config := SimulatorConfig{Duration: time.Second, Streams: 500, Distribution: ZipfStreams(1.2), Buffer: 500, Seed: 42}
+	newHandler := func() SinkHandler {
+		return slowWrite(ProjectionHandler(NewOrderSummaryProjection()), time.Millisecond)
+	}
+	reports, sustained, err := SaturationPoint(ctx, config, []float64{200, 400, 800, 1600, 3200}, newHandler)
+	if err != nil {
+		panic(err)
+	}
+	for _, report := range reports {
+		fmt.Printf("  %s, saturated %v\n", report, report.Saturated())
+	}
+	fmt.Printf("Sustained %.0f events/s\n", sustained)
+
+	fmt.Printf("\n=== Bursts of twice that rate ===\n")
+	config.Profile = BurstyLoad(sustained/2, 2*max(sustained, 100), 500*time.Millisecond, 100*time.Millisecond)
+	config.Duration = 2 * time.Second
+	burst, err := NewSimulator(config).Run(ctx, newHandler())
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("  %s\n", burst)
+	printHandlerStats(burst.ByType)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	last := reports[len(reports)-1]
+	if !last.Saturated() {
+		fmt.Printf("FAIL: Expected a 1ms handler to saturate below 3200/s, got %s\n", last)
+		passed = false
+	}
+	if sustained < 200 || sustained >= 1600 {
+		fmt.Printf("FAIL: Expected a 1ms handler to sustain between 200 and 1600/s, got %.0f\n", sustained)
+		passed = false
+	}
+	if burst.Delivered != burst.Target || burst.Saturated() {
+		fmt.Printf("FAIL: Expected the handler to absorb bursts averaging under its capacity, got %s\n", burst)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll delivery simulator tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
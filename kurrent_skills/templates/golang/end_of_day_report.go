@@ -0,0 +1,144 @@
+// KurrentDB Go Client Example - Point-in-time reports with a bounded catch-up
+// Demonstrates: Subscribing up to a recorded $all position and stopping cleanly, even with later events present
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// SalesReport totals sales up to a position
+type SalesReport struct {
+	Sales  int
+	Amount float64
+}
+
+// RunEndOfDayReport records the position at which a day closed, keeps selling into the next day,
+// then builds the closed day's report by catching up to that position only
+func RunEndOfDayReport() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	// Sales go to one stream per till; a unique prefix keeps this run's sales apart
+	prefix := "sale" + uuid.New().String()[:8]
+	sell := func(till string, amounts ...float64) {
+		for _, amount := range amounts {
+			data, _ := json.Marshal(map[string]float64{"amount": amount})
+			_, err := client.AppendToStream(ctx, Streams.Name(prefix, till), kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+				EventID:     uuid.New(),
+				EventType:   "SaleRecorded",
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        data,
+			})
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	// === DAY ONE ===
+	fmt.Println("\n=== Day one ===")
+	sell("till1", 10, 20)
+	sell("till2", 5)
+	sell("till1", 15)
+	// Closing the day records the close in its own stream; its position bounds the day
+	closed, err := AppendAndPosition(ctx, client, Streams.Name("businessDay", prefix), kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+		EventID:     uuid.New(),
+		EventType:   "DayClosed",
+		ContentType: kurrentdb.ContentTypeJson,
+		Data:        []byte(`{"day":"2024-01-15"}`),
+	})
+	if err != nil {
+		panic(err)
+	}
+	dayEnd := closed.Position
+	fmt.Printf("  4 sales for 50.00, day closed at %d/%d\n", dayEnd.Commit, dayEnd.Prepare)
+
+	// === DAY TWO ===
+	fmt.Println("\n=== Day two, before the report runs ===")
+	sell("till2", 100)
+	sell("till1", 200)
+	fmt.Println("  2 sales for 300.00")
+
+	// report catches up over this run's sales until stopAt, or until timeout
+	report := func(stopAt kurrentdb.Position, timeout time.Duration) (SalesReport, time.Duration, error) {
+		subscription := NewMeteredSubscription(client, kurrentdb.SubscribeToAllOptions{
+			Filter: &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{prefix + "-"}},
+		})
+		subscription.StopAt = &stopAt
+
+		runCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		var totals SalesReport
+		started := time.Now()
+		err := subscription.Run(runCtx, func(event *kurrentdb.ResolvedEvent) error {
+			var sale struct {
+				Amount float64 `json:"amount"`
+			}
+			if err := json.Unmarshal(Resolve(event, false).Data, &sale); err != nil {
+				return err
+			}
+			totals.Sales++
+			totals.Amount += sale.Amount
+			return nil
+		})
+		return totals, time.Since(started), err
+	}
+
+	// === END-OF-DAY REPORT ===
+	fmt.Println("\n=== End-of-day report for day one ===")
+	dayOne, dayOneElapsed, dayOneErr := report(dayEnd, 30*time.Second)
+	fmt.Printf("  %d sales for %.2f, finished in %s (%v)\n", dayOne.Sales, dayOne.Amount, dayOneElapsed.Round(time.Millisecond), dayOneErr)
+
+	// === UNREACHED POSITION ===
+	fmt.Println("\n=== Report up to a position not written yet ===")
+	head, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+	future := kurrentdb.Position{Commit: head.Commit + 1_000_000, Prepare: head.Prepare + 1_000_000}
+	partial, _, futureErr := report(future, time.Second)
+	fmt.Printf("  %d sales for %.2f before giving up: %v\n", partial.Sales, partial.Amount, futureErr)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if dayOneErr != nil || dayOne.Sales != 4 || dayOne.Amount != 50 {
+		fmt.Printf("FAIL: The day one report should cover 4 sales for 50, got %+v (%v)\n", dayOne, dayOneErr)
+		passed = false
+	}
+	if dayOneElapsed > 10*time.Second {
+		fmt.Printf("FAIL: The report should stop at the position, not wait for the timeout; took %s\n", dayOneElapsed)
+		passed = false
+	}
+	if !errors.Is(futureErr, ErrStopPositionNotReached) || !errors.Is(futureErr, context.DeadlineExceeded) {
+		fmt.Printf("FAIL: An unreached position should fail with ErrStopPositionNotReached and the deadline, got %v\n", futureErr)
+		passed = false
+	}
+	if partial.Sales != 6 || partial.Amount != 350 {
+		fmt.Printf("FAIL: Before timing out every sale written should be handled, got %+v\n", partial)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll end-of-day report tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,194 @@
+// KurrentDB Go Client Example - Automatic metadata enrichment on append
+// Demonstrates: Stamping schemaVersion, producer and a monotonic emittedAt onto every event
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === ENRICHED WRITER ===
+
+// EnrichedWriter merges a fixed metadata map plus an emittedAt timestamp into every appended event.
+// Metadata already set by the caller wins on key conflicts.
+type EnrichedWriter struct {
+	client   *kurrentdb.Client
+	metadata map[string]interface{}
+
+	mu       sync.Mutex
+	lastEmit time.Time
+	now      func() time.Time
+}
+
+func NewEnrichedWriter(client *kurrentdb.Client, metadata map[string]interface{}) *EnrichedWriter {
+	return &EnrichedWriter{
+		client:   client,
+		metadata: metadata,
+		now:      time.Now,
+	}
+}
+
+// emittedAt returns a UTC timestamp strictly greater than the previous one,
+// so events appended in the same clock tick (or after a clock step back) still sort in emit order
+func (w *EnrichedWriter) emittedAt() time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	t := w.now().UTC()
+	if !t.After(w.lastEmit) {
+		t = w.lastEmit.Add(time.Nanosecond)
+	}
+	w.lastEmit = t
+	return t
+}
+
+// Enrich returns a copy of event with the writer's metadata merged into Metadata
+func (w *EnrichedWriter) Enrich(event kurrentdb.EventData) (kurrentdb.EventData, error) {
+	merged := make(map[string]interface{}, len(w.metadata)+1)
+	for key, value := range w.metadata {
+		merged[key] = value
+	}
+	merged["emittedAt"] = w.emittedAt().Format(time.RFC3339Nano)
+
+	if len(event.Metadata) > 0 {
+		var existing map[string]interface{}
+		if err := json.Unmarshal(event.Metadata, &existing); err != nil {
+			return event, fmt.Errorf("event %s has non-JSON metadata: %w", event.EventType, err)
+		}
+		for key, value := range existing {
+			merged[key] = value
+		}
+	}
+
+	metadata, err := json.Marshal(merged)
+	if err != nil {
+		return event, err
+	}
+	event.Metadata = metadata
+	return event, nil
+}
+
+// AppendToStream enriches every event and appends them with the given options
+func (w *EnrichedWriter) AppendToStream(ctx context.Context, stream string, opts kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+	enriched := make([]kurrentdb.EventData, len(events))
+	for i, event := range events {
+		e, err := w.Enrich(event)
+		if err != nil {
+			return nil, err
+		}
+		enriched[i] = e
+	}
+	return w.client.AppendToStream(ctx, stream, opts, enriched...)
+}
+
+// RunEnrichedWriter demonstrates reading enriched metadata back from a stream
+func RunEnrichedWriter() {
+	ctx := context.Background()
+
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	writer := NewEnrichedWriter(client, map[string]interface{}{
+		"schemaVersion": 2,
+		"producer":      "order-service",
+	})
+
+	orderID := uuid.New().String()
+	streamName := Streams.Name("order", orderID)
+
+	// === APPEND WITH AND WITHOUT CALLER METADATA ===
+	created, _ := json.Marshal(OrderCreated{OrderID: orderID, CustomerID: "customer-123", Amount: 99.99})
+	shipped, _ := json.Marshal(map[string]string{"shippedAt": "2024-01-15T10:00:00Z"})
+
+	_, err := writer.AppendToStream(ctx, streamName, kurrentdb.AppendToStreamOptions{},
+		kurrentdb.EventData{
+			EventID:     uuid.New(),
+			ContentType: kurrentdb.ContentTypeJson,
+			EventType:   "OrderCreated",
+			Data:        created,
+		},
+		kurrentdb.EventData{
+			EventID:     uuid.New(),
+			ContentType: kurrentdb.ContentTypeJson,
+			EventType:   "OrderShipped",
+			Data:        shipped,
+			// The caller's producer wins over the writer default
+			Metadata: []byte(`{"producer":"shipping-service"}`),
+		})
+	if err != nil {
+		panic(err)
+	}
+
+	// === READ BACK ===
+	fmt.Println("\nReading enriched events:")
+
+	stream, err := client.ReadStream(ctx, streamName, kurrentdb.ReadStreamOptions{
+		Direction: kurrentdb.Forwards,
+		From:      kurrentdb.Start{},
+	}, 100)
+	if err != nil {
+		panic(err)
+	}
+	defer stream.Close()
+
+	var metadata []map[string]interface{}
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			panic(err)
+		}
+
+		recorded := Resolve(event, false)
+		var m map[string]interface{}
+		json.Unmarshal(recorded.UserMetadata, &m)
+		metadata = append(metadata, m)
+
+		fmt.Printf("  %s metadata: %s\n", recorded.EventType, string(recorded.UserMetadata))
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if len(metadata) != 2 {
+		fmt.Printf("FAIL: Expected 2 events, got %d\n", len(metadata))
+		os.Exit(1)
+	}
+	if metadata[0]["schemaVersion"] != float64(2) || metadata[0]["producer"] != "order-service" {
+		fmt.Printf("FAIL: OrderCreated should carry writer metadata, got %v\n", metadata[0])
+		passed = false
+	}
+	if metadata[1]["producer"] != "shipping-service" {
+		fmt.Printf("FAIL: Caller metadata should win, got producer %v\n", metadata[1]["producer"])
+		passed = false
+	}
+
+	first, err1 := time.Parse(time.RFC3339Nano, fmt.Sprint(metadata[0]["emittedAt"]))
+	second, err2 := time.Parse(time.RFC3339Nano, fmt.Sprint(metadata[1]["emittedAt"]))
+	if err1 != nil || err2 != nil || !second.After(first) {
+		fmt.Printf("FAIL: emittedAt should be strictly increasing, got %v then %v\n",
+			metadata[0]["emittedAt"], metadata[1]["emittedAt"])
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll enriched writer tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
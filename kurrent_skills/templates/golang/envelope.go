@@ -0,0 +1,131 @@
+// KurrentDB Go Client Example - Event envelope shared by sinks, runners and handlers
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// Envelope is the client-independent view of a delivered event
+type Envelope struct {
+	EventID     uuid.UUID
+	EventType   string
+	StreamID    string
+	EventNumber uint64
+	ContentType string
+	Created     time.Time
+	Data        []byte
+	Metadata    []byte
+
+	// Position is where the delivered record sits in $all; for resolved links this is the link's position
+	Position kurrentdb.Position
+
+	// Enrichment holds data an Enricher joined in from other streams, by join name. It is never
+	// stored with the event.
+	Enrichment map[string]map[string]interface{}
+}
+
+// linkEventType is the type of the link events in $ce-, $et- and other projected streams. Their
+// data is "{eventNumber}@{stream}", pointing at the linked event.
+const linkEventType = "$>"
+
+// Resolve picks the event to use from a read or subscription result.
+//
+// A ResolvedEvent holds up to two records. Reading a stream of links ($ce-order, $et-OrderPlaced)
+// with ResolveLinkTos delivers both: Link is the link record in the stream read, Event is the
+// event it points to. Without links, or without ResolveLinkTos, only Event is set and both
+// choices below return it.
+//
+// With preferOriginal, Resolve returns OriginalEvent(): the record actually read, so the link
+// when there is one. Use it for positions, revisions and checkpoints in the stream being read.
+// Otherwise it returns the linked event, for its type, data, metadata and own stream. When the
+// linked event was deleted or truncated Event is nil, and the link is returned instead: check for
+// linkEventType to skip it.
+func Resolve(resolved *kurrentdb.ResolvedEvent, preferOriginal bool) *kurrentdb.RecordedEvent {
+	if preferOriginal || resolved.Event == nil {
+		return resolved.OriginalEvent()
+	}
+	return resolved.Event
+}
+
+// NewEnvelope builds an envelope from a subscription or read result.
+// Data comes from the resolved event when a link was resolved, the position from the delivered record.
+func NewEnvelope(resolved *kurrentdb.ResolvedEvent) Envelope {
+	recorded := Resolve(resolved, false)
+
+	return Envelope{
+		EventID:     recorded.EventID,
+		EventType:   recorded.EventType,
+		StreamID:    recorded.StreamID,
+		EventNumber: recorded.EventNumber,
+		ContentType: recorded.ContentType,
+		Created:     recorded.CreatedDate,
+		Data:        recorded.Data,
+		Metadata:    recorded.UserMetadata,
+		Position:    Resolve(resolved, true).Position,
+	}
+}
+
+// RunResolveChecks checks Resolve, NewEnvelope and LinkStatusOf on a plain event, a resolved link, a link
+// read as is and a link whose target was deleted, no server required
+func RunResolveChecks() {
+	checks := newChecker("resolve")
+
+	// OrderPlaced is order-1@0 at 100 in $all; $by_category linked it as $ce-order@7 at 900
+	placed := syntheticEvent("order-1", "OrderPlaced", 0, 100, `{"amount":100}`)
+	link := syntheticEvent(Streams.Category("order"), linkEventType, 7, 900, "0@order-1")
+
+	// --- Plain event ---
+	fmt.Println("\n--- Plain event ---")
+	plain := &kurrentdb.ResolvedEvent{Event: placed}
+	checks.Check(Resolve(plain, false) == placed && Resolve(plain, true) == placed, "both choices should return the only event")
+	checks.Check(LinkStatusOf(plain) == NotALink, "a plain event should not be a link, got %s", LinkStatusOf(plain))
+
+	// --- Resolved link ---
+	fmt.Println("\n--- Resolved link ---")
+	resolved := &kurrentdb.ResolvedEvent{Event: placed, Link: link}
+	event, original := Resolve(resolved, false), Resolve(resolved, true)
+	fmt.Printf("  Resolve(false): %s %s@%d\n  Resolve(true):  %s %s@%d\n", event.EventType, event.StreamID, event.EventNumber, original.EventType, original.StreamID, original.EventNumber)
+	checks.Check(event == placed, "preferOriginal=false should return the linked event, got %s", event.EventType)
+	checks.Check(original == link && original == resolved.OriginalEvent(), "preferOriginal=true should return the link, got %s", original.EventType)
+	checks.Check(LinkStatusOf(resolved) == LinkResolved, "a link with its event should be LinkResolved, got %s", LinkStatusOf(resolved))
+	stream, number, ok := ParseLink(link)
+	checks.Check(ok && stream == "order-1" && number == 0, "ParseLink should return order-1@0, got %s@%d (%v)", stream, number, ok)
+	_, _, ok = ParseLink(placed)
+	checks.Check(!ok, "ParseLink should reject an event that isn't a link")
+	_, _, ok = ParseLink(syntheticEvent(Streams.Category("order"), linkEventType, 8, 901, "first@order-1"))
+	checks.Check(!ok, "ParseLink should reject a link without an event number")
+
+	// --- Unresolved link ---
+	fmt.Println("\n--- Link read without ResolveLinkTos ---")
+	unresolved := &kurrentdb.ResolvedEvent{Event: link}
+	checks.Check(LinkStatusOf(unresolved) == LinkUnresolved, "a link read as is should be LinkUnresolved, got %s", LinkStatusOf(unresolved))
+	checks.Check(Resolve(unresolved, false) == link && unresolved.OriginalEvent() == link, "Event and OriginalEvent() should both be the link")
+
+	envelope := NewEnvelope(resolved)
+	checks.Check(envelope.EventType == "OrderPlaced" && envelope.StreamID == "order-1" && string(envelope.Data) == `{"amount":100}`,
+		"envelope should carry the linked event, got %s %s", envelope.EventType, envelope.StreamID)
+	checks.Check(envelope.Position == link.Position, "envelope position should be the link's, got %v", envelope.Position)
+
+	projection := NewProjection("Orders").On("OrderPlaced", func(state, data map[string]interface{}) map[string]interface{} {
+		state["amount"] = data["amount"]
+		return state
+	})
+	applied, err := projection.Apply(Resolve(resolved, false), Resolve(resolved, true).Position)
+	checks.Check(applied && err == nil && projection.Get("order-1")["amount"] == float64(100), "the projection should apply the linked event to order-1, got %v (%v)", projection.State, err)
+	checks.Check(projection.Checkpoint != nil && projection.Checkpoint.Commit == 900, "the checkpoint should be the link's position, got %v", projection.Checkpoint)
+
+	// --- Deleted target ---
+	fmt.Println("\n--- Link to a deleted event ---")
+	dangling := &kurrentdb.ResolvedEvent{Link: link}
+	checks.Check(Resolve(dangling, false) == link && Resolve(dangling, true) == link, "an unresolved link should resolve to itself")
+	checks.Check(LinkStatusOf(dangling) == LinkDangling, "a link without its event should be LinkDangling, got %s", LinkStatusOf(dangling))
+	checks.Check(NewEnvelope(dangling).EventType == linkEventType, "the envelope of an unresolved link should be the link")
+	applied, err = projection.Apply(Resolve(dangling, false), Resolve(dangling, true).Position)
+	checks.Check(!applied && err == nil, "projections should skip unresolved links, got %v (%v)", applied, err)
+
+	checks.Done()
+}
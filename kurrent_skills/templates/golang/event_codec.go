@@ -0,0 +1,385 @@
+// KurrentDB Go Client Example - Event codecs for mixed JSON and protobuf streams
+// Demonstrates: Encoding events with a codec that sets the content type, and decoding by content type on read
+package main
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// === CODECS ===
+
+// MetaContentType records a binary event's actual format in its metadata. The server only knows
+// JSON and binary, so protobuf, Avro or anything else is stored as application/octet-stream and
+// told apart by this key.
+const MetaContentType = "contentType"
+
+// ErrUnknownContentType is returned when no codec is registered for an event's content type
+var ErrUnknownContentType = errors.New("unknown content type")
+
+// EventCodec encodes event values in one format
+type EventCodec interface {
+	// MediaType names the format, e.g. application/json
+	MediaType() string
+	// ContentType is how the server stores it: JSON, or binary for everything else
+	ContentType() kurrentdb.ContentType
+	Marshal(value any) ([]byte, error)
+	Unmarshal(data []byte, into any) error
+}
+
+// JSONCodec encodes values with encoding/json
+type JSONCodec struct{}
+
+func (JSONCodec) MediaType() string                     { return "application/json" }
+func (JSONCodec) ContentType() kurrentdb.ContentType    { return kurrentdb.ContentTypeJson }
+func (JSONCodec) Marshal(value any) ([]byte, error)     { return json.Marshal(value) }
+func (JSONCodec) Unmarshal(data []byte, into any) error { return json.Unmarshal(data, into) }
+
+// ProtobufCodec encodes protobuf messages through encoding.BinaryMarshaler, so it needs no
+// generated code itself. Give protoc-gen-go messages a one-line MarshalBinary and UnmarshalBinary
+// calling proto.Marshal and proto.Unmarshal.
+type ProtobufCodec struct{}
+
+func (ProtobufCodec) MediaType() string                  { return "application/x-protobuf" }
+func (ProtobufCodec) ContentType() kurrentdb.ContentType { return kurrentdb.ContentTypeBinary }
+
+func (ProtobufCodec) Marshal(value any) ([]byte, error) {
+	message, ok := value.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("protobuf: %T does not implement encoding.BinaryMarshaler", value)
+	}
+	return message.MarshalBinary()
+}
+
+func (ProtobufCodec) Unmarshal(data []byte, into any) error {
+	message, ok := into.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return fmt.Errorf("protobuf: %T does not implement encoding.BinaryUnmarshaler", into)
+	}
+	return message.UnmarshalBinary(data)
+}
+
+// NewEventData encodes value with codec as an event of eventType, setting the content type the
+// server stores and, for binary formats, MetaContentType. meta may be nil; it is not modified.
+// Streams mixing formats this way can't be guarded by ContentTypeGuard, which pins one per stream.
+func NewEventData(eventType string, value any, codec EventCodec, meta Meta) (kurrentdb.EventData, error) {
+	data, err := codec.Marshal(value)
+	if err != nil {
+		return kurrentdb.EventData{}, fmt.Errorf("encode %s as %s: %w", eventType, codec.MediaType(), err)
+	}
+
+	event := kurrentdb.EventData{
+		EventID:     uuid.New(),
+		EventType:   eventType,
+		ContentType: codec.ContentType(),
+		Data:        data,
+	}
+	if codec.ContentType() != kurrentdb.ContentTypeJson || len(meta) > 0 {
+		withType := NewMeta()
+		for key, value := range meta {
+			withType[key] = value
+		}
+		if codec.ContentType() != kurrentdb.ContentTypeJson {
+			withType[MetaContentType] = codec.MediaType()
+		}
+		if event.Metadata, err = withType.Marshal(); err != nil {
+			return kurrentdb.EventData{}, err
+		}
+	}
+	return event, nil
+}
+
+// EventCodecs picks the codec for a recorded event: JSON events by their content type, binary
+// events by MetaContentType. Safe for concurrent use.
+type EventCodecs struct {
+	mu          sync.RWMutex
+	byMediaType map[string]EventCodec
+}
+
+// NewEventCodecs knows JSON, plus the given codecs
+func NewEventCodecs(codecs ...EventCodec) *EventCodecs {
+	c := &EventCodecs{byMediaType: make(map[string]EventCodec)}
+	c.Register(JSONCodec{})
+	for _, codec := range codecs {
+		c.Register(codec)
+	}
+	return c
+}
+
+func (c *EventCodecs) Register(codec EventCodec) *EventCodecs {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.byMediaType[codec.MediaType()] = codec
+	return c
+}
+
+// For returns the codec event was written with. Binary events without MetaContentType, written
+// by producers that don't record it, are ErrUnknownContentType.
+func (c *EventCodecs) For(event *kurrentdb.RecordedEvent) (EventCodec, error) {
+	mediaType := event.ContentType
+	if recordedContentType(event) != kurrentdb.ContentTypeJson {
+		var meta Meta
+		if err := meta.UnmarshalFrom(event); err != nil {
+			return nil, err
+		}
+		if mediaType = meta[MetaContentType]; mediaType == "" {
+			return nil, fmt.Errorf("%w: binary %s %s@%d has no %s metadata", ErrUnknownContentType,
+				event.EventType, event.StreamID, event.EventNumber, MetaContentType)
+		}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	codec, ok := c.byMediaType[mediaType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s for %s %s@%d", ErrUnknownContentType, mediaType, event.EventType, event.StreamID, event.EventNumber)
+	}
+	return codec, nil
+}
+
+// Decode decodes event's data into into with the codec it was written with
+func (c *EventCodecs) Decode(event *kurrentdb.RecordedEvent, into any) error {
+	codec, err := c.For(event)
+	if err != nil {
+		return err
+	}
+	if err := codec.Unmarshal(event.Data, into); err != nil {
+		return fmt.Errorf("decode %s %s@%d as %s: %w", event.EventType, event.StreamID, event.EventNumber, codec.MediaType(), err)
+	}
+	return nil
+}
+
+// DecodeAs decodes event into a new T
+func DecodeAs[T any](codecs *EventCodecs, event *kurrentdb.RecordedEvent) (T, error) {
+	var value T
+	err := codecs.Decode(event, &value)
+	return value, err
+}
+
+// === PROTOBUF MESSAGE ===
+
+// OrderPriced is the protobuf message
+//
+//	message OrderPriced {
+//	  string order_id = 1;
+//	  int64 amount_cents = 2;
+//	  string currency = 3;
+//	}
+//
+// encoded by hand with protowire, standing in for protoc-gen-go output
+type OrderPriced struct {
+	OrderID     string
+	AmountCents int64
+	Currency    string
+}
+
+func (m *OrderPriced) MarshalBinary() ([]byte, error) {
+	var b []byte
+	if m.OrderID != "" {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, m.OrderID)
+	}
+	if m.AmountCents != 0 {
+		b = protowire.AppendTag(b, 2, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(m.AmountCents))
+	}
+	if m.Currency != "" {
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		b = protowire.AppendString(b, m.Currency)
+	}
+	return b, nil
+}
+
+func (m *OrderPriced) UnmarshalBinary(b []byte) error {
+	*m = OrderPriced{}
+	for len(b) > 0 {
+		number, wireType, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		switch {
+		case number == 1 && wireType == protowire.BytesType:
+			m.OrderID, n = protowire.ConsumeString(b)
+		case number == 2 && wireType == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(b)
+			m.AmountCents = int64(v)
+		case number == 3 && wireType == protowire.BytesType:
+			m.Currency, n = protowire.ConsumeString(b)
+		default:
+			// Unknown fields are skipped, so newer producers don't break this reader
+			n = protowire.ConsumeFieldValue(number, wireType, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// RunEventCodecChecks round-trips JSON and protobuf events through the in-memory store, no server required
+func RunEventCodecChecks() {
+	checks := newChecker("event codec")
+
+	ctx := context.Background()
+	store := NewMemoryEventStore()
+	codecs := NewEventCodecs(ProtobufCodec{})
+	appendValue := func(eventType string, value any, codec EventCodec, meta Meta) *kurrentdb.RecordedEvent {
+		event, err := NewEventData(eventType, value, codec, meta)
+		checks.Check(err == nil, "encoding %s: %v", eventType, err)
+		return appendEventData(ctx, store, event)
+	}
+
+	// --- JSON ---
+	fmt.Println("\n--- JSON ---")
+	created := appendValue("OrderCreated", OrderCreated{OrderID: "1", CustomerID: "cust-1", Amount: 100}, JSONCodec{}, NewMeta().SetTenant("acme"))
+	checks.Check(created.ContentType == "application/json", "a JSON event should be stored as application/json, got %s", created.ContentType)
+	var createdMeta Meta
+	createdMeta.UnmarshalFrom(created)
+	checks.Check(createdMeta.Tenant() == "acme" && createdMeta[MetaContentType] == "", "JSON metadata should keep the caller's keys and need no content type, got %v", createdMeta)
+	decodedCreated, err := DecodeAs[OrderCreated](codecs, created)
+	checks.Check(err == nil && decodedCreated == OrderCreated{OrderID: "1", CustomerID: "cust-1", Amount: 100}, "JSON should round-trip, got %+v (%v)", decodedCreated, err)
+	plain, err := NewEventData("OrderCreated", OrderCreated{OrderID: "2"}, JSONCodec{}, nil)
+	checks.Check(err == nil && plain.Metadata == nil, "a JSON event without metadata should get none, got %s", plain.Metadata)
+
+	// --- Protobuf ---
+	fmt.Println("\n--- Protobuf ---")
+	priced := appendValue("OrderPriced", &OrderPriced{OrderID: "1", AmountCents: 12550, Currency: "EUR"}, ProtobufCodec{}, nil)
+	var pricedMeta Meta
+	pricedMeta.UnmarshalFrom(priced)
+	checks.Check(priced.ContentType != "application/json" && pricedMeta[MetaContentType] == "application/x-protobuf",
+		"a protobuf event should be binary with its media type in metadata, got %s and %v", priced.ContentType, pricedMeta)
+	checks.Check(fmt.Sprintf("%x", priced.Data) == "0a0131108662"+"1a03455552", "unexpected protobuf encoding %x", priced.Data)
+	decodedPriced, err := DecodeAs[OrderPriced](codecs, priced)
+	checks.Check(err == nil && decodedPriced == OrderPriced{OrderID: "1", AmountCents: 12550, Currency: "EUR"}, "protobuf should round-trip, got %+v (%v)", decodedPriced, err)
+	fmt.Printf("  %s: %x -> %+v\n", priced.EventType, priced.Data, decodedPriced)
+
+	// A newer producer's extra field is skipped
+	extended := append(append([]byte{}, priced.Data...), protowire.AppendString(protowire.AppendTag(nil, 9, protowire.BytesType), "express")...)
+	var tolerant OrderPriced
+	checks.Check(tolerant.UnmarshalBinary(extended) == nil && tolerant == decodedPriced, "unknown fields should be skipped, got %+v", tolerant)
+	checks.Check(tolerant.UnmarshalBinary([]byte{0x0a, 0x05, 'a'}) != nil, "truncated data should fail")
+
+	// --- Dispatch errors ---
+	fmt.Println("\n--- Dispatch errors ---")
+	_, err = NewEventData("OrderPriced", OrderCreated{}, ProtobufCodec{}, nil)
+	checks.Check(err != nil, "the protobuf codec should refuse values that aren't messages")
+	legacy, _ := NewEventData("OrderPriced", &OrderPriced{OrderID: "2"}, ProtobufCodec{}, nil)
+	legacy.Metadata = nil
+	legacyEvent := appendEventData(ctx, store, legacy)
+	_, err = DecodeAs[OrderPriced](codecs, legacyEvent)
+	checks.Check(errors.Is(err, ErrUnknownContentType), "binary without a media type should be ErrUnknownContentType, got %v", err)
+	_, err = DecodeAs[OrderPriced](NewEventCodecs(), priced)
+	checks.Check(errors.Is(err, ErrUnknownContentType), "an unregistered media type should be ErrUnknownContentType, got %v", err)
+	_, err = DecodeAs[OrderCreated](codecs, priced)
+	checks.Check(err != nil && !errors.Is(err, ErrUnknownContentType), "decoding into a non-message should fail, got %v", err)
+
+	checks.Done()
+}
+
+// appendEventData appends one event to order-1 in store and returns it as recorded
+func appendEventData(ctx context.Context, store *MemoryEventStore, event kurrentdb.EventData) *kurrentdb.RecordedEvent {
+	written, err := store.AppendToStream(ctx, "order-1", kurrentdb.AppendToStreamOptions{}, event)
+	if err != nil {
+		panic(err)
+	}
+	return store.streams["order-1"][written.NextExpectedVersion]
+}
+
+// RunEventCodec writes a JSON OrderCreated and a protobuf OrderPriced to one stream and decodes both
+func RunEventCodec() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	// === APPEND ===
+	orderID := uuid.New().String()
+	stream := Streams.Name("order", orderID)
+	created, err := NewEventData("OrderCreated", OrderCreated{OrderID: orderID, CustomerID: "cust-1", Amount: 125.5}, JSONCodec{}, nil)
+	if err != nil {
+		panic(err)
+	}
+	priced, err := NewEventData("OrderPriced", &OrderPriced{OrderID: orderID, AmountCents: 12550, Currency: "EUR"}, ProtobufCodec{}, nil)
+	if err != nil {
+		panic(err)
+	}
+	if _, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{StreamState: kurrentdb.NoStream{}}, created, priced); err != nil {
+		panic(err)
+	}
+	fmt.Printf("Appended a JSON OrderCreated and a protobuf OrderPriced to %s\n", stream)
+
+	// === READ ===
+	fmt.Println("\n=== Decoding by content type ===")
+	events, _, err := readStreamFrom(ctx, NewClientStore(client), stream, NoVersion)
+	if err != nil {
+		panic(err)
+	}
+	codecs := NewEventCodecs(ProtobufCodec{})
+	var decoded []any
+	var decodeErrs []error
+	for _, event := range events {
+		codec, err := codecs.For(event)
+		if err != nil {
+			decodeErrs = append(decodeErrs, err)
+			continue
+		}
+		var value any
+		switch event.EventType {
+		case "OrderCreated":
+			value, err = DecodeAs[OrderCreated](codecs, event)
+		case "OrderPriced":
+			value, err = DecodeAs[OrderPriced](codecs, event)
+		}
+		if err != nil {
+			decodeErrs = append(decodeErrs, err)
+			continue
+		}
+		fmt.Printf("  #%d %s stored as %s, decoded with %s: %+v\n", event.EventNumber, event.EventType, event.ContentType, codec.MediaType(), value)
+		decoded = append(decoded, value)
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if len(decodeErrs) != 0 {
+		fmt.Printf("FAIL: Both events should decode, got %v\n", decodeErrs)
+		passed = false
+	}
+	if len(events) != 2 || events[0].ContentType != "application/json" || events[1].ContentType == "application/json" {
+		fmt.Printf("FAIL: The stream should hold a JSON and a binary event, got %d events\n", len(events))
+		passed = false
+	}
+	if len(decoded) != 2 ||
+		decoded[0] != (OrderCreated{OrderID: orderID, CustomerID: "cust-1", Amount: 125.5}) ||
+		decoded[1] != (OrderPriced{OrderID: orderID, AmountCents: 12550, Currency: "EUR"}) {
+		fmt.Printf("FAIL: Expected the original OrderCreated and OrderPriced, got %+v\n", decoded)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll event codec tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
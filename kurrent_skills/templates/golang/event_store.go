@@ -0,0 +1,510 @@
+// KurrentDB Go Client Example - Event store interface for testable application code
+// Demonstrates: Depending on an interface over the client, with an in-memory implementation for tests without a server
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === EVENT STORE ===
+
+// EventReader is the part of *kurrentdb.ReadStream readers use
+type EventReader interface {
+	Recv() (*kurrentdb.ResolvedEvent, error)
+	Close()
+}
+
+// EventSubscription is the part of *kurrentdb.Subscription subscribers use
+type EventSubscription interface {
+	Recv() *kurrentdb.SubscriptionEvent
+	Close() error
+}
+
+// EventStore is the subset of *kurrentdb.Client the templates use. The methods take the client's
+// arguments; reads and subscriptions return interfaces rather than the client's concrete types,
+// which only the client can construct. NewClientStore adapts a client, MemoryEventStore is an
+// in-memory implementation for tests.
+type EventStore interface {
+	AppendToStream(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error)
+	ReadStream(ctx context.Context, stream string, options kurrentdb.ReadStreamOptions, count uint64) (EventReader, error)
+	ReadAll(ctx context.Context, options kurrentdb.ReadAllOptions, count uint64) (EventReader, error)
+	SubscribeToAll(ctx context.Context, options kurrentdb.SubscribeToAllOptions) (EventSubscription, error)
+	SubscribeToStream(ctx context.Context, stream string, options kurrentdb.SubscribeToStreamOptions) (EventSubscription, error)
+}
+
+// clientStore adapts *kurrentdb.Client to EventStore
+type clientStore struct {
+	client *kurrentdb.Client
+}
+
+func NewClientStore(client *kurrentdb.Client) EventStore {
+	return clientStore{client: client}
+}
+
+func (s clientStore) AppendToStream(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+	return s.client.AppendToStream(ctx, stream, options, events...)
+}
+
+func (s clientStore) ReadStream(ctx context.Context, stream string, options kurrentdb.ReadStreamOptions, count uint64) (EventReader, error) {
+	// Return a nil interface, not a nil *kurrentdb.ReadStream, on error
+	events, err := s.client.ReadStream(ctx, stream, options, count)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (s clientStore) ReadAll(ctx context.Context, options kurrentdb.ReadAllOptions, count uint64) (EventReader, error) {
+	events, err := s.client.ReadAll(ctx, options, count)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (s clientStore) SubscribeToAll(ctx context.Context, options kurrentdb.SubscribeToAllOptions) (EventSubscription, error) {
+	subscription, err := s.client.SubscribeToAll(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s clientStore) SubscribeToStream(ctx context.Context, stream string, options kurrentdb.SubscribeToStreamOptions) (EventSubscription, error) {
+	subscription, err := s.client.SubscribeToStream(ctx, stream, options)
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// The adapter only forwards: these fail to compile if the client's signatures move away from
+// EventStore's, or its read and subscription types stop satisfying the interfaces
+var (
+	_ EventStore        = clientStore{}
+	_ EventStore        = (*MemoryEventStore)(nil)
+	_ EventReader       = (*kurrentdb.ReadStream)(nil)
+	_ EventSubscription = (*kurrentdb.Subscription)(nil)
+
+	_ func(*kurrentdb.Client, context.Context, string, kurrentdb.AppendToStreamOptions, ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) = (*kurrentdb.Client).AppendToStream
+	_ func(*kurrentdb.Client, context.Context, string, kurrentdb.ReadStreamOptions, uint64) (*kurrentdb.ReadStream, error)                      = (*kurrentdb.Client).ReadStream
+	_ func(*kurrentdb.Client, context.Context, kurrentdb.ReadAllOptions, uint64) (*kurrentdb.ReadStream, error)                                 = (*kurrentdb.Client).ReadAll
+	_ func(*kurrentdb.Client, context.Context, kurrentdb.SubscribeToAllOptions) (*kurrentdb.Subscription, error)                                = (*kurrentdb.Client).SubscribeToAll
+	_ func(*kurrentdb.Client, context.Context, string, kurrentdb.SubscribeToStreamOptions) (*kurrentdb.Subscription, error)                     = (*kurrentdb.Client).SubscribeToStream
+)
+
+// === IN-MEMORY EVENT STORE ===
+
+// sliceReader reads events from a slice, then err, or io.EOF if err is nil
+type sliceReader struct {
+	events []*kurrentdb.RecordedEvent
+	err    error
+}
+
+func (r *sliceReader) Recv() (*kurrentdb.ResolvedEvent, error) {
+	if len(r.events) == 0 {
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, io.EOF
+	}
+	event := r.events[0]
+	r.events = r.events[1:]
+	return &kurrentdb.ResolvedEvent{Event: event}, nil
+}
+
+func (r *sliceReader) Close() {}
+
+// The in-memory store's errors. isWrongExpectedVersion and isStreamNotFound recognise them, so
+// code under test handles them like the server's.
+var (
+	errMemoryWrongExpectedVersion = errors.New("wrong expected version")
+	errMemoryStreamNotFound       = errors.New("stream not found")
+)
+
+// MemoryEventStore is an EventStore in memory, for tests: appends check the expected state,
+// reads honour direction, start and count, and subscriptions deliver existing events and then live
+// ones, filtered like the server does. Links, deletion, metadata and caught-up notifications are
+// not modelled. Safe for concurrent use.
+type MemoryEventStore struct {
+	mu      sync.Mutex
+	all     []*kurrentdb.RecordedEvent
+	streams map[string][]*kurrentdb.RecordedEvent
+	// appended is closed and replaced on every append, waking subscriptions
+	appended chan struct{}
+}
+
+func NewMemoryEventStore() *MemoryEventStore {
+	return &MemoryEventStore{
+		streams:  make(map[string][]*kurrentdb.RecordedEvent),
+		appended: make(chan struct{}),
+	}
+}
+
+func (s *MemoryEventStore) AppendToStream(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := s.streams[stream]
+	current := int64(len(existing)) - 1
+	var ok bool
+	switch state := options.StreamState.(type) {
+	case nil, kurrentdb.Any:
+		ok = true
+	case kurrentdb.NoStream:
+		ok = current == NoVersion
+	case kurrentdb.StreamExists:
+		ok = current != NoVersion
+	case kurrentdb.StreamRevision:
+		ok = current == int64(state.Value)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is at %d, expected %v", errMemoryWrongExpectedVersion, stream, current, options.StreamState)
+	}
+
+	for _, data := range events {
+		commit := uint64(len(s.all)+1) * 100
+		contentType := "application/octet-stream"
+		if data.ContentType == kurrentdb.ContentTypeJson {
+			contentType = "application/json"
+		}
+		eventID := data.EventID
+		if eventID == uuid.Nil {
+			eventID = uuid.New()
+		}
+		event := &kurrentdb.RecordedEvent{
+			EventID:      eventID,
+			EventType:    data.EventType,
+			ContentType:  contentType,
+			StreamID:     stream,
+			EventNumber:  uint64(len(existing)),
+			Position:     kurrentdb.Position{Commit: commit, Prepare: commit},
+			CreatedDate:  time.Now().UTC(),
+			Data:         data.Data,
+			UserMetadata: data.Metadata,
+		}
+		existing = append(existing, event)
+		s.all = append(s.all, event)
+	}
+	if len(existing) == 0 {
+		// Nothing appended to a stream that doesn't exist
+		return &kurrentdb.WriteResult{}, nil
+	}
+	s.streams[stream] = existing
+	close(s.appended)
+	s.appended = make(chan struct{})
+
+	last := existing[len(existing)-1]
+	return &kurrentdb.WriteResult{
+		CommitPosition:      last.Position.Commit,
+		PreparePosition:     last.Position.Prepare,
+		NextExpectedVersion: last.EventNumber,
+	}, nil
+}
+
+// ReadStream reads like the client: a missing stream fails on the first Recv
+func (s *MemoryEventStore) ReadStream(ctx context.Context, stream string, options kurrentdb.ReadStreamOptions, count uint64) (EventReader, error) {
+	s.mu.Lock()
+	events, found := s.streams[stream]
+	events = slices.Clone(events)
+	s.mu.Unlock()
+	if !found {
+		return &sliceReader{err: fmt.Errorf("%w: %s", errMemoryStreamNotFound, stream)}, nil
+	}
+
+	backwards := options.Direction == kurrentdb.Backwards
+	start := 0
+	switch from := options.From.(type) {
+	case kurrentdb.End:
+		start = len(events)
+		if backwards {
+			start = len(events) - 1
+		}
+	case kurrentdb.StreamRevision:
+		start = int(min(from.Value, uint64(len(events))))
+		if backwards && start == len(events) {
+			start--
+		}
+	default:
+		if backwards {
+			start = -1
+		}
+	}
+	return &sliceReader{events: window(events, start, backwards, count)}, nil
+}
+
+func (s *MemoryEventStore) ReadAll(ctx context.Context, options kurrentdb.ReadAllOptions, count uint64) (EventReader, error) {
+	s.mu.Lock()
+	events := slices.Clone(s.all)
+	s.mu.Unlock()
+
+	backwards := options.Direction == kurrentdb.Backwards
+	start := 0
+	switch from := options.From.(type) {
+	case kurrentdb.End:
+		start = len(events)
+		if backwards {
+			start = len(events) - 1
+		}
+	case kurrentdb.Position:
+		// Forwards from a position includes the event at it; backwards starts before it
+		start = len(events)
+		for i, event := range events {
+			if !positionAfter(from, event.Position) {
+				start = i
+				break
+			}
+		}
+		if backwards {
+			start--
+		}
+	default:
+		if backwards {
+			start = -1
+		}
+	}
+	return &sliceReader{events: window(events, start, backwards, count)}, nil
+}
+
+// window returns up to count events from index start in direction, in reading order
+func window(events []*kurrentdb.RecordedEvent, start int, backwards bool, count uint64) []*kurrentdb.RecordedEvent {
+	var result []*kurrentdb.RecordedEvent
+	for i := start; i >= 0 && i < len(events) && uint64(len(result)) < count; {
+		result = append(result, events[i])
+		if backwards {
+			i--
+		} else {
+			i++
+		}
+	}
+	return result
+}
+
+func (s *MemoryEventStore) SubscribeToAll(ctx context.Context, options kurrentdb.SubscribeToAllOptions) (EventSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := 0
+	switch from := options.From.(type) {
+	case kurrentdb.End:
+		next = len(s.all)
+	case kurrentdb.Position:
+		// Subscriptions start after the position
+		for next < len(s.all) && !positionAfter(s.all[next].Position, from) {
+			next++
+		}
+	}
+	return newMemorySubscription(ctx, s, next, func(event *kurrentdb.RecordedEvent) bool {
+		return matchesFilter(options.Filter, event)
+	}), nil
+}
+
+func (s *MemoryEventStore) SubscribeToStream(ctx context.Context, stream string, options kurrentdb.SubscribeToStreamOptions) (EventSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	after := NoVersion
+	switch from := options.From.(type) {
+	case kurrentdb.End:
+		after = int64(len(s.streams[stream])) - 1
+	case kurrentdb.StreamRevision:
+		after = int64(from.Value)
+	}
+	// The position in $all of the stream's first event after the start
+	next := len(s.all)
+	for i, event := range s.all {
+		if event.StreamID == stream && int64(event.EventNumber) > after {
+			next = i
+			break
+		}
+	}
+	return newMemorySubscription(ctx, s, next, func(event *kurrentdb.RecordedEvent) bool {
+		return event.StreamID == stream && int64(event.EventNumber) > after
+	}), nil
+}
+
+// matchesFilter applies a subscription filter as the server does: a prefix or the regex must match
+// the event type or the stream name
+func matchesFilter(filter *kurrentdb.SubscriptionFilter, event *kurrentdb.RecordedEvent) bool {
+	if filter == nil {
+		return true
+	}
+	subject := event.EventType
+	if filter.Type == kurrentdb.StreamFilterType {
+		subject = event.StreamID
+	}
+	for _, prefix := range filter.Prefixes {
+		if strings.HasPrefix(subject, prefix) {
+			return true
+		}
+	}
+	if filter.Regex != "" {
+		matched, _ := regexp.MatchString(filter.Regex, subject)
+		return matched
+	}
+	return len(filter.Prefixes) == 0
+}
+
+// memorySubscription follows the store's $all log from next, delivering the events match accepts
+type memorySubscription struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	store  *MemoryEventStore
+	next   int
+	match  func(*kurrentdb.RecordedEvent) bool
+}
+
+func newMemorySubscription(ctx context.Context, store *MemoryEventStore, next int, match func(*kurrentdb.RecordedEvent) bool) *memorySubscription {
+	ctx, cancel := context.WithCancel(ctx)
+	return &memorySubscription{ctx: ctx, cancel: cancel, store: store, next: next, match: match}
+}
+
+// Recv blocks until the next matching event, or returns SubscriptionDropped once the context ends
+// or the subscription is closed
+func (m *memorySubscription) Recv() *kurrentdb.SubscriptionEvent {
+	for {
+		m.store.mu.Lock()
+		for m.next < len(m.store.all) {
+			event := m.store.all[m.next]
+			m.next++
+			if m.match(event) {
+				m.store.mu.Unlock()
+				return &kurrentdb.SubscriptionEvent{EventAppeared: &kurrentdb.ResolvedEvent{Event: event}}
+			}
+		}
+		appended := m.store.appended
+		m.store.mu.Unlock()
+
+		select {
+		case <-appended:
+		case <-m.ctx.Done():
+			return &kurrentdb.SubscriptionEvent{SubscriptionDropped: &kurrentdb.SubscriptionDropped{Error: m.ctx.Err()}}
+		}
+	}
+}
+
+func (m *memorySubscription) Close() error {
+	m.cancel()
+	return nil
+}
+
+// RunEventStoreChecks runs the order repository and the summary projection on the in-memory
+// store, and checks its read and subscription semantics, no server required
+func RunEventStoreChecks() {
+	checks := newChecker("event store")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	store := NewMemoryEventStore()
+
+	// --- Repository ---
+	fmt.Println("\n--- Repository ---")
+	repository := NewOrderRepository(store)
+	_, err := repository.Execute(ctx, "1", func(o *Order) ([]kurrentdb.EventData, error) { return o.Create("cust-1", 0) })
+	checks.Check(err == nil, "create failed: %v", err)
+	_, err = repository.Execute(ctx, "1", func(o *Order) ([]kurrentdb.EventData, error) { return o.AddItem("Widget", 25) })
+	checks.Check(err == nil, "add item failed: %v", err)
+	order, err := repository.Load(ctx, "1")
+	fmt.Printf("  order 1: %+v (%v)\n", order, err)
+	checks.Check(err == nil && order.Version == 1 && order.Amount == 25 && len(order.Items) == 1, "expected order 1 at version 1 with one item, got %+v (%v)", order, err)
+
+	stale, _ := repository.Load(ctx, "1")
+	repository.Execute(ctx, "1", func(o *Order) ([]kurrentdb.EventData, error) { return o.AddItem("Gadget", 30) })
+	events, _ := stale.AddItem("Gizmo", 5)
+	_, err = repository.Save(ctx, "1", stale.Version, events)
+	fmt.Printf("  stale save: %v\n", err)
+	checks.Check(errors.Is(err, ErrVersionConflict), "a save at a stale version should conflict, got %v", err)
+	missing, err := repository.Load(ctx, "2")
+	checks.Check(err == nil && missing.Version == NoVersion, "a missing order should load empty, got %+v (%v)", missing, err)
+
+	// --- Projection ---
+	fmt.Println("\n--- Projection ---")
+	write := func(stream, eventType string, data interface{}) {
+		payload, _ := json.Marshal(data)
+		_, err := store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventType: eventType, ContentType: kurrentdb.ContentTypeJson, Data: payload,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	write("order-a", "OrderCreated", ProjectionOrderCreated{OrderID: "a", CustomerID: "cust-1", Amount: 100})
+	write("order-a", "ItemAdded", ProjectionItemAdded{Item: "Widget", Price: 25})
+
+	projection := NewOrderSummaryProjection()
+	followed := make(chan error, 1)
+	go func() {
+		followed <- projection.Follow(ctx, store, kurrentdb.SubscribeToAllOptions{From: kurrentdb.Start{}},
+			func(event *kurrentdb.RecordedEvent) bool { return event.EventType == "OrderShipped" })
+	}()
+	// Written while the projection is following
+	write("order-a", "OrderShipped", ProjectionOrderShipped{ShippedAt: "2024-01-15T10:00:00Z"})
+	err = <-followed
+	var state map[string]interface{}
+	projection.Read(func(p *Projection) { state = p.getLocked("order-a") })
+	fmt.Printf("  order-a: %v (%v)\n", state, err)
+	checks.Check(err == nil && state != nil && state["status"] == "shipped" && state["amount"] == 125.0,
+		"the live shipment should be projected, got %v (%v)", state, err)
+
+	// --- Reads and subscriptions ---
+	fmt.Println("\n--- Reads and subscriptions ---")
+	types := func(reader EventReader, err error) []string {
+		var result []string
+		for err == nil {
+			var event *kurrentdb.ResolvedEvent
+			if event, err = reader.Recv(); err == nil {
+				result = append(result, fmt.Sprintf("%s@%d", event.Event.EventType, event.Event.EventNumber))
+			}
+		}
+		if !errors.Is(err, io.EOF) {
+			result = append(result, err.Error())
+		}
+		return result
+	}
+	last := types(store.ReadStream(ctx, "order-a", kurrentdb.ReadStreamOptions{Direction: kurrentdb.Backwards, From: kurrentdb.End{}}, 2))
+	checks.Check(fmt.Sprint(last) == "[OrderShipped@2 ItemAdded@1]", "backwards from the end should read the last two, got %v", last)
+	middle := types(store.ReadStream(ctx, "order-a", kurrentdb.ReadStreamOptions{From: kurrentdb.Revision(1)}, 1))
+	checks.Check(fmt.Sprint(middle) == "[ItemAdded@1]", "forwards from revision 1 should read it, got %v", middle)
+	_, notFound := store.ReadStream(ctx, "order-none", kurrentdb.ReadStreamOptions{}, 1)
+	reader, _ := store.ReadStream(ctx, "order-none", kurrentdb.ReadStreamOptions{}, 1)
+	_, notFoundErr := reader.Recv()
+	checks.Check(notFound == nil && isStreamNotFound(notFoundErr), "a missing stream should fail on Recv like the client, got %v", notFoundErr)
+	_, conflict := store.AppendToStream(ctx, "order-a", kurrentdb.AppendToStreamOptions{StreamState: kurrentdb.NoStream{}})
+	checks.Check(isWrongExpectedVersion(conflict), "NoStream on an existing stream should conflict, got %v", conflict)
+
+	subscribeCtx, stop := context.WithCancel(ctx)
+	subscription, _ := store.SubscribeToAll(subscribeCtx, kurrentdb.SubscribeToAllOptions{
+		From:   kurrentdb.Start{},
+		Filter: &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{"order-a"}},
+	})
+	var received []string
+	for len(received) < 3 {
+		event := subscription.Recv()
+		if event.SubscriptionDropped != nil {
+			break
+		}
+		received = append(received, event.EventAppeared.Event.EventType)
+	}
+	stop()
+	dropped := subscription.Recv()
+	fmt.Printf("  order-a: %v, then dropped: %v\n", received, dropped.SubscriptionDropped != nil)
+	checks.Check(fmt.Sprint(received) == "[OrderCreated ItemAdded OrderShipped]", "the filter should deliver only order-a, got %v", received)
+	checks.Check(dropped.SubscriptionDropped != nil, "cancelling the context should drop the subscription")
+
+	checks.Done()
+}
@@ -0,0 +1,329 @@
+// KurrentDB Go Client Example - Reading events by type
+// Demonstrates: Fetching every event of one type across streams from $et-{eventType}, and following it live
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === READ BY EVENT TYPE ===
+
+// ErrEventTypeNotFound is returned when $et-{eventType} doesn't exist. As with category streams,
+// the server can't tell why: nothing of the type has been written, the $by_event_type projection
+// hasn't linked it yet, or system projections are disabled (start the server with
+// --run-projections=System and make sure $by_event_type is enabled).
+var ErrEventTypeNotFound = errors.New("event type stream not found")
+
+// eventTypePageSize is how many links ReadByEventType reads per request
+const eventTypePageSize = 500
+
+// EventTypeReadOptions configures ReadByEventType
+type EventTypeReadOptions struct {
+	// From is the revision in $et-{eventType} to start at; nil reads from the start, or from the end
+	// when reading backwards
+	From kurrentdb.StreamPosition
+	// Backwards returns the newest events first
+	Backwards bool
+	// MaxCount stops after this many links; 0 reads them all
+	MaxCount uint64
+}
+
+// ReadByEventType returns the events of eventType across all streams, in the order they were
+// linked into $et-{eventType}, which is the order they were written. Links are resolved, so each
+// event carries its own stream, number and data. Links to events that have since been deleted or
+// truncated away are skipped, so fewer than MaxCount events may come back.
+//
+// $et- streams are built asynchronously by the $by_event_type system projection: an event written
+// moments ago may not be returned yet.
+func ReadByEventType(ctx context.Context, client *kurrentdb.Client, eventType string, options EventTypeReadOptions) ([]*kurrentdb.RecordedEvent, error) {
+	stream := Streams.EventType(eventType)
+	direction := kurrentdb.Forwards
+	var from kurrentdb.StreamPosition = kurrentdb.Start{}
+	if options.Backwards {
+		direction, from = kurrentdb.Backwards, kurrentdb.End{}
+	}
+	if options.From != nil {
+		from = options.From
+	}
+
+	var found []*kurrentdb.RecordedEvent
+	var read uint64
+	for {
+		pageSize := uint64(eventTypePageSize)
+		if options.MaxCount > 0 {
+			pageSize = min(pageSize, options.MaxCount-read)
+		}
+		events, err := client.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{
+			Direction:      direction,
+			From:           from,
+			ResolveLinkTos: true,
+		}, pageSize)
+		if err != nil {
+			return nil, eventTypeReadError(stream, read, err)
+		}
+
+		var page, last uint64
+		for {
+			event, err := events.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				events.Close()
+				return nil, eventTypeReadError(stream, read, err)
+			}
+			page++
+			last = Resolve(event, true).EventNumber
+			if recorded := Resolve(event, false); recorded.EventType != linkEventType {
+				found = append(found, recorded)
+			}
+		}
+		events.Close()
+
+		read += page
+		if page < pageSize || (options.MaxCount > 0 && read == options.MaxCount) {
+			return found, nil
+		}
+		if options.Backwards {
+			if last == 0 {
+				return found, nil
+			}
+			from = kurrentdb.StreamRevision{Value: last - 1}
+		} else {
+			from = kurrentdb.StreamRevision{Value: last + 1}
+		}
+	}
+}
+
+func eventTypeReadError(stream string, read uint64, err error) error {
+	if !isStreamNotFound(err) {
+		return fmt.Errorf("read %s: %w", stream, err)
+	}
+	if read > 0 {
+		return fmt.Errorf("%s deleted while reading: %w", stream, err)
+	}
+	return fmt.Errorf("%w: %s", ErrEventTypeNotFound, stream)
+}
+
+// SubscribeToEventType calls handler with each event of eventType appended after from, across all
+// streams, until ctx ends (which returns nil), the subscription drops or handler fails. revision is
+// the link's revision in $et-{eventType}: save it and pass kurrentdb.Revision(revision) as from to
+// resume after it. Pass kurrentdb.End{} to follow only new events.
+//
+// Subscribing doesn't fail when the $et- stream doesn't exist; it waits for it. With system
+// projections disabled it therefore waits forever, so a caller that expects events should check
+// with ReadByEventType first or watch for silence.
+func SubscribeToEventType(ctx context.Context, client *kurrentdb.Client, eventType string, from kurrentdb.StreamPosition,
+	handler func(event *kurrentdb.RecordedEvent, revision uint64) error) error {
+	subscription, err := client.SubscribeToStream(ctx, Streams.EventType(eventType), kurrentdb.SubscribeToStreamOptions{
+		From:           from,
+		ResolveLinkTos: true,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer subscription.Close()
+
+	for {
+		message := subscription.Recv()
+		if message.SubscriptionDropped != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if message.SubscriptionDropped.Error == nil {
+				return errors.New("subscription dropped")
+			}
+			return message.SubscriptionDropped.Error
+		}
+		if message.EventAppeared == nil {
+			continue
+		}
+
+		revision := Resolve(message.EventAppeared, true).EventNumber
+		recorded := Resolve(message.EventAppeared, false)
+		if recorded.EventType == linkEventType {
+			continue // deleted before we got to it
+		}
+		if err := handler(recorded, revision); err != nil {
+			return fmt.Errorf("handle %s@%s: %w", recorded.EventType, recorded.StreamID, err)
+		}
+	}
+}
+
+// RunReadByEventType reads every OrderShipped across the order streams, then follows the type live
+func RunReadByEventType() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	orderEvent := func(eventType string, orderID string) kurrentdb.EventData {
+		return kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(fmt.Sprintf(`{"orderId":%q}`, orderID)),
+		}
+	}
+
+	// === WRITE ===
+	fmt.Println("\n=== Shipping 3 orders ===")
+	var shippedIDs []uuid.UUID
+	for i := 0; i < 3; i++ {
+		orderID := uuid.New().String()
+		shipped := orderEvent("OrderShipped", orderID)
+		shippedIDs = append(shippedIDs, shipped.EventID)
+		_, err := client.AppendToStream(ctx, Streams.Name("order", orderID), kurrentdb.AppendToStreamOptions{},
+			orderEvent("OrderPlaced", orderID), shipped)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("  %s\n", Streams.Name("order", orderID))
+	}
+
+	// === READ ===
+	// $by_event_type links the events shortly after they are written
+	fmt.Println("\n=== Every OrderShipped ===")
+	var shipped []*kurrentdb.RecordedEvent
+	var err error
+	containsAll := func(events []*kurrentdb.RecordedEvent) bool {
+		for _, id := range shippedIDs {
+			if !slices.ContainsFunc(events, func(e *kurrentdb.RecordedEvent) bool { return e.EventID == id }) {
+				return false
+			}
+		}
+		return true
+	}
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(200 * time.Millisecond) {
+		shipped, err = ReadByEventType(ctx, client, "OrderShipped", EventTypeReadOptions{})
+		if err != nil && !errors.Is(err, ErrEventTypeNotFound) {
+			panic(err)
+		}
+		if containsAll(shipped) {
+			break
+		}
+	}
+	if errors.Is(err, ErrEventTypeNotFound) {
+		fmt.Printf("%v\n", err)
+	}
+	fmt.Printf("Found %d OrderShipped events\n", len(shipped))
+	for _, event := range shipped[max(0, len(shipped)-3):] {
+		fmt.Printf("  ... %s #%d\n", event.StreamID, event.EventNumber)
+	}
+
+	latest, err := ReadByEventType(ctx, client, "OrderShipped", EventTypeReadOptions{Backwards: true, MaxCount: 2})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Latest 2: %d returned\n", len(latest))
+
+	_, missingErr := ReadByEventType(ctx, client, "Nothing"+strings.ReplaceAll(uuid.New().String(), "-", ""), EventTypeReadOptions{})
+	fmt.Printf("Unknown type: %v\n", missingErr)
+
+	// === FOLLOW ===
+	fmt.Println("\n=== Following OrderShipped ===")
+	followCtx, stopFollowing := context.WithCancel(ctx)
+	received := make(chan *kurrentdb.RecordedEvent, 10)
+	followed := make(chan error, 1)
+	go func() {
+		followed <- SubscribeToEventType(followCtx, client, "OrderShipped", kurrentdb.End{},
+			func(event *kurrentdb.RecordedEvent, revision uint64) error {
+				received <- event
+				return nil
+			})
+	}()
+	time.Sleep(500 * time.Millisecond) // let the subscription start before writing
+
+	liveID := uuid.New().String()
+	live := orderEvent("OrderShipped", liveID)
+	if _, err := client.AppendToStream(ctx, Streams.Name("order", liveID), kurrentdb.AppendToStreamOptions{},
+		orderEvent("OrderPlaced", liveID), live); err != nil {
+		panic(err)
+	}
+
+	var liveEvents []*kurrentdb.RecordedEvent
+	timeout := time.After(10 * time.Second)
+waiting:
+	for {
+		select {
+		case event := <-received:
+			fmt.Printf("  live: %s %s\n", event.EventType, event.StreamID)
+			liveEvents = append(liveEvents, event)
+			if event.EventID == live.EventID {
+				break waiting
+			}
+		case <-timeout:
+			break waiting
+		}
+	}
+	stopFollowing()
+	followErr := <-followed
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if !containsAll(shipped) {
+		fmt.Println("FAIL: Expected all 3 OrderShipped events to be read")
+		passed = false
+	}
+	for _, event := range shipped {
+		if event.EventType != "OrderShipped" {
+			fmt.Printf("FAIL: Expected only OrderShipped events, got %s from %s\n", event.EventType, event.StreamID)
+			passed = false
+			break
+		}
+		if strings.HasPrefix(event.StreamID, "$et-") {
+			fmt.Printf("FAIL: Expected resolved events from their own streams, got a link in %s\n", event.StreamID)
+			passed = false
+			break
+		}
+	}
+	if len(latest) != 2 || (len(shipped) >= 2 && latest[0].EventID != shipped[len(shipped)-1].EventID) {
+		fmt.Printf("FAIL: Expected the 2 newest OrderShipped, newest first, got %d\n", len(latest))
+		passed = false
+	}
+	if !errors.Is(missingErr, ErrEventTypeNotFound) {
+		fmt.Printf("FAIL: Expected ErrEventTypeNotFound for an unknown type, got %v\n", missingErr)
+		passed = false
+	}
+	if len(liveEvents) == 0 || liveEvents[len(liveEvents)-1].EventID != live.EventID {
+		fmt.Println("FAIL: Expected the subscription to deliver the new OrderShipped")
+		passed = false
+	}
+	for _, event := range liveEvents {
+		if event.EventType != "OrderShipped" {
+			fmt.Printf("FAIL: The subscription should only deliver OrderShipped, got %s\n", event.EventType)
+			passed = false
+		}
+	}
+	if followErr != nil {
+		fmt.Printf("FAIL: Cancelling should stop the subscription cleanly, got %v\n", followErr)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll read by event type tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,446 @@
+// KurrentDB Go Client Example - Subscription fan-out to multiple sinks
+// Demonstrates: Independent per-sink retries and buffers, and a shared checkpoint that only advances when all sinks succeed
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === FAN-OUT ===
+
+// FailureAction is what a sink does once its retries are exhausted
+type FailureAction int
+
+const (
+	// FailureStop stops the whole fan-out and surfaces the error
+	FailureStop FailureAction = iota
+	// FailureSkip drops the event for this sink only and carries on
+	FailureSkip
+	// FailureDisable stops delivering to this sink; it no longer holds back the shared checkpoint
+	FailureDisable
+)
+
+// SinkPolicy controls retries for one sink
+type SinkPolicy struct {
+	MaxRetries int
+	Backoff    Backoff
+	OnFailure  FailureAction
+}
+
+// SinkHandler delivers one event to a downstream system
+type SinkHandler func(ctx context.Context, envelope Envelope) error
+
+// FanOutOptions configures buffering and checkpointing
+type FanOutOptions struct {
+	// BufferSize is how far a slow sink may lag before Publish blocks
+	BufferSize int
+	// PerSinkCheckpoints makes each sink skip events at or before its own restored position,
+	// so a restart from the shared checkpoint doesn't redeliver to sinks that were ahead
+	PerSinkCheckpoints bool
+}
+
+type fanOutSink struct {
+	name    string
+	handler SinkHandler
+	policy  SinkPolicy
+	queue   chan Envelope
+
+	// guarded by FanOut.mu
+	done      *kurrentdb.Position
+	restored  *kurrentdb.Position
+	disabled  bool
+	delivered int
+	failed    int
+}
+
+// ErrFanOutClosed is returned by Publish once Close has been called
+var ErrFanOutClosed = errors.New("fan-out closed")
+
+// FanOut delivers each published event to every registered sink on its own goroutine
+type FanOut struct {
+	options FanOutOptions
+	sinks   []*fanOutSink
+
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	cancel  context.CancelFunc
+	stopErr error
+	stopped chan struct{}
+	once    sync.Once
+	// closed is set by Close; guarded by mu
+	closed bool
+	// publishing is held for reading by Publish and for writing by Close while it closes the
+	// queues, so no Publish sends on a closed queue
+	publishing sync.RWMutex
+}
+
+func NewFanOut(options FanOutOptions) *FanOut {
+	if options.BufferSize <= 0 {
+		options.BufferSize = 100
+	}
+	return &FanOut{options: options, stopped: make(chan struct{})}
+}
+
+// Register adds a sink; call before Start
+func (f *FanOut) Register(name string, policy SinkPolicy, handler SinkHandler) *FanOut {
+	f.sinks = append(f.sinks, &fanOutSink{
+		name:    name,
+		handler: handler,
+		policy:  policy,
+		queue:   make(chan Envelope, f.options.BufferSize),
+	})
+	return f
+}
+
+// Restore sets per-sink positions saved from SinkCheckpoints (used with PerSinkCheckpoints)
+func (f *FanOut) Restore(positions map[string]kurrentdb.Position) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, sink := range f.sinks {
+		if position, ok := positions[sink.name]; ok {
+			sink.restored = &position
+			sink.done = &position
+		}
+	}
+}
+
+// Start launches one worker per sink
+func (f *FanOut) Start(ctx context.Context) {
+	ctx, f.cancel = context.WithCancel(ctx)
+	for _, sink := range f.sinks {
+		f.wg.Add(1)
+		go f.work(ctx, sink)
+	}
+}
+
+// Publish queues the event for every enabled sink, blocking only while a sink's buffer is full.
+// After Close it returns ErrFanOutClosed.
+func (f *FanOut) Publish(ctx context.Context, envelope Envelope) error {
+	f.publishing.RLock()
+	defer f.publishing.RUnlock()
+
+	f.mu.Lock()
+	closed := f.closed
+	f.mu.Unlock()
+	if closed {
+		return ErrFanOutClosed
+	}
+
+	for _, sink := range f.sinks {
+		f.mu.Lock()
+		disabled := sink.disabled
+		f.mu.Unlock()
+		if disabled {
+			continue
+		}
+
+		select {
+		case sink.queue <- envelope:
+		case <-f.stopped:
+			return f.Err()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Close stops accepting events, waits for queued deliveries to finish, and returns the stop error if any.
+// It may be called before Start or more than once.
+func (f *FanOut) Close() error {
+	f.publishing.Lock()
+	f.mu.Lock()
+	closed := f.closed
+	f.closed = true
+	f.mu.Unlock()
+	if !closed {
+		for _, sink := range f.sinks {
+			close(sink.queue)
+		}
+	}
+	f.publishing.Unlock()
+
+	f.wg.Wait()
+	// Nil when Start was never called
+	if f.cancel != nil {
+		f.cancel()
+	}
+	return f.Err()
+}
+
+// Err returns the error that stopped the fan-out, if any
+func (f *FanOut) Err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.stopErr
+}
+
+func (f *FanOut) stop(err error) {
+	f.once.Do(func() {
+		f.mu.Lock()
+		f.stopErr = err
+		f.mu.Unlock()
+		close(f.stopped)
+		f.cancel()
+	})
+}
+
+func (f *FanOut) work(ctx context.Context, sink *fanOutSink) {
+	defer f.wg.Done()
+
+	for envelope := range sink.queue {
+		f.mu.Lock()
+		skip := sink.disabled || (f.options.PerSinkCheckpoints && sink.restored != nil && !positionAfter(envelope.Position, *sink.restored))
+		f.mu.Unlock()
+		if skip || ctx.Err() != nil {
+			continue
+		}
+
+		err := f.deliver(ctx, sink, envelope)
+
+		f.mu.Lock()
+		position := envelope.Position
+		switch {
+		case err == nil:
+			sink.delivered++
+			sink.done = &position
+		case sink.policy.OnFailure == FailureSkip:
+			sink.failed++
+			sink.done = &position
+		case sink.policy.OnFailure == FailureDisable:
+			sink.failed++
+			sink.disabled = true
+		}
+		f.mu.Unlock()
+
+		if err != nil {
+			fmt.Printf("  [fanout] sink %s failed on %s@%d: %v\n", sink.name, envelope.StreamID, envelope.EventNumber, err)
+			if sink.policy.OnFailure == FailureStop {
+				f.stop(fmt.Errorf("sink %s: %w", sink.name, err))
+				return
+			}
+		}
+	}
+}
+
+func (f *FanOut) deliver(ctx context.Context, sink *fanOutSink, envelope Envelope) error {
+	return sink.policy.Backoff.Retry(ctx, sink.policy.MaxRetries+1, func(int) error {
+		return sink.handler(ctx, envelope)
+	}, nil)
+}
+
+// Checkpoint is the position every enabled sink has handled; persist this for a shared restart point.
+// ok is false until all enabled sinks have handled at least one event.
+func (f *FanOut) Checkpoint() (kurrentdb.Position, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var lowest *kurrentdb.Position
+	for _, sink := range f.sinks {
+		if sink.disabled {
+			continue
+		}
+		if sink.done == nil {
+			return kurrentdb.Position{}, false
+		}
+		if lowest == nil || positionAfter(*lowest, *sink.done) {
+			lowest = sink.done
+		}
+	}
+	if lowest == nil {
+		return kurrentdb.Position{}, false
+	}
+	return *lowest, true
+}
+
+// SinkCheckpoints returns each sink's own position, for PerSinkCheckpoints restarts
+func (f *FanOut) SinkCheckpoints() map[string]kurrentdb.Position {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	positions := make(map[string]kurrentdb.Position)
+	for _, sink := range f.sinks {
+		if sink.done != nil {
+			positions[sink.name] = *sink.done
+		}
+	}
+	return positions
+}
+
+// FanOutStat is a per-sink progress snapshot
+type FanOutStat struct {
+	Delivered int
+	Failed    int
+	Queued    int
+	Disabled  bool
+}
+
+func (f *FanOut) Stats() map[string]FanOutStat {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stats := make(map[string]FanOutStat)
+	for _, sink := range f.sinks {
+		stats[sink.name] = FanOutStat{
+			Delivered: sink.delivered,
+			Failed:    sink.failed,
+			Queued:    len(sink.queue),
+			Disabled:  sink.disabled,
+		}
+	}
+	return stats
+}
+
+// FanOutFromAll relays a $all subscription into the fan-out until ctx is cancelled or a sink stops it
+func FanOutFromAll(ctx context.Context, client *kurrentdb.Client, fanOut *FanOut, options kurrentdb.SubscribeToAllOptions) error {
+	if position, ok := fanOut.Checkpoint(); ok {
+		options.From = position
+	}
+
+	subscription, err := client.SubscribeToAll(ctx, options)
+	if err != nil {
+		return err
+	}
+	defer subscription.Close()
+
+	for {
+		event := subscription.Recv()
+
+		if event.SubscriptionDropped != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return event.SubscriptionDropped.Error
+		}
+
+		if event.EventAppeared != nil {
+			if err := fanOut.Publish(ctx, NewEnvelope(event.EventAppeared)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// RunFanOut demonstrates a slow and a failing sink not blocking the others, using synthetic events
+func RunFanOut() {
+	ctx := context.Background()
+
+	fmt.Println("=== Fan-out to search, cache, webhook and audit sinks ===")
+
+	var mu sync.Mutex
+	firstEventsDoneAt := map[string]time.Time{}
+	record := func(name string, envelope Envelope) {
+		mu.Lock()
+		defer mu.Unlock()
+		if envelope.EventNumber == 19 {
+			firstEventsDoneAt[name] = time.Now()
+		}
+	}
+
+	fanOut := NewFanOut(FanOutOptions{BufferSize: 10}).
+		Register("search", SinkPolicy{}, func(ctx context.Context, e Envelope) error {
+			record("search", e)
+			return nil
+		}).
+		Register("cache", SinkPolicy{}, func(ctx context.Context, e Envelope) error {
+			record("cache", e)
+			return nil
+		}).
+		Register("webhook", SinkPolicy{MaxRetries: 3, Backoff: NewBackoff(5*time.Millisecond, 20*time.Millisecond), OnFailure: FailureSkip},
+			func(ctx context.Context, e Envelope) error {
+				time.Sleep(20 * time.Millisecond) // slow downstream
+				record("webhook", e)
+				return nil
+			}).
+		Register("audit", SinkPolicy{MaxRetries: 2, Backoff: NewBackoff(5*time.Millisecond, 20*time.Millisecond), OnFailure: FailureDisable},
+			func(ctx context.Context, e Envelope) error {
+				return errors.New("audit endpoint unavailable")
+			})
+
+	fanOut.Start(ctx)
+
+	startedAt := time.Now()
+	streamName := Streams.Name("order", uuid.New().String())
+	for i := 0; i < 40; i++ {
+		commit := uint64(i+1) * 100
+		err := fanOut.Publish(ctx, Envelope{
+			EventID:     uuid.New(),
+			EventType:   "OrderUpdated",
+			StreamID:    streamName,
+			EventNumber: uint64(i),
+			Position:    kurrentdb.Position{Commit: commit, Prepare: commit},
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	publishedIn := time.Since(startedAt)
+
+	if err := fanOut.Close(); err != nil {
+		panic(err)
+	}
+
+	stats := fanOut.Stats()
+	for _, name := range []string{"search", "cache", "webhook", "audit"} {
+		s := stats[name]
+		fmt.Printf("  %-8s delivered=%d failed=%d disabled=%v\n", name, s.Delivered, s.Failed, s.Disabled)
+	}
+	fmt.Printf("  Published 40 events in %s (webhook buffer of 10 applied back-pressure)\n", publishedIn.Round(time.Millisecond))
+
+	checkpoint, ok := fanOut.Checkpoint()
+	fmt.Printf("  Shared checkpoint: %d (ok=%v)\n", checkpoint.Commit, ok)
+
+	// === AFTER CLOSE ===
+	lateErr := fanOut.Publish(ctx, Envelope{StreamID: "order-late", Position: kurrentdb.Position{Commit: 4100, Prepare: 4100}})
+	secondCloseErr := fanOut.Close()
+	neverStartedErr := NewFanOut(FanOutOptions{}).Register("idle", SinkPolicy{}, func(context.Context, Envelope) error { return nil }).Close()
+	fmt.Printf("  Publish after Close: %v; second Close: %v; Close without Start: %v\n", lateErr, secondCloseErr, neverStartedErr)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if stats["search"].Delivered != 40 || stats["cache"].Delivered != 40 || stats["webhook"].Delivered != 40 {
+		fmt.Printf("FAIL: Healthy sinks should receive every event, got %v\n", stats)
+		passed = false
+	}
+	if !stats["audit"].Disabled {
+		fmt.Println("FAIL: Permanently failing audit sink should be disabled")
+		passed = false
+	}
+	// Fast sinks reached event 19 while the webhook was still at most one buffer behind
+	if firstEventsDoneAt["search"].After(firstEventsDoneAt["webhook"]) {
+		fmt.Println("FAIL: Fast sinks should not wait for the slow webhook")
+		passed = false
+	}
+	if !ok || checkpoint.Commit != 4000 {
+		fmt.Printf("FAIL: Shared checkpoint should reach the last event once all enabled sinks finish, got %d\n", checkpoint.Commit)
+		passed = false
+	}
+	if !errors.Is(lateErr, ErrFanOutClosed) {
+		fmt.Printf("FAIL: Publish after Close should return ErrFanOutClosed, got %v\n", lateErr)
+		passed = false
+	}
+	if secondCloseErr != nil || neverStartedErr != nil {
+		fmt.Printf("FAIL: Closing twice or without Start should succeed, got %v / %v\n", secondCloseErr, neverStartedErr)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll fan-out tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
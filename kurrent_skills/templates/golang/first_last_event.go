@@ -0,0 +1,212 @@
+// KurrentDB Go Client Example - First and last event of a stream
+// Demonstrates: Reading a stream's creation event and current tip with a single-event read, treating a missing stream as empty
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === FIRST AND LAST EVENT ===
+
+// FirstEvent returns the oldest event still in stream, usually the one that created it. It
+// returns nil and no error when the stream doesn't exist, holds no events, or has been deleted,
+// soft or hard. Once $maxAge, $maxCount or $tb has truncated the stream, the first event left is
+// returned, not the original creation event.
+func FirstEvent(ctx context.Context, client *kurrentdb.Client, stream string) (*kurrentdb.RecordedEvent, error) {
+	return readSingleEvent(ctx, NewClientStore(client), stream, kurrentdb.ReadStreamOptions{From: kurrentdb.Start{}})
+}
+
+// LastEvent returns the newest event in stream, whose EventNumber is the stream's current revision.
+// It returns nil and no error in the same cases as FirstEvent, tombstones included.
+func LastEvent(ctx context.Context, client *kurrentdb.Client, stream string) (*kurrentdb.RecordedEvent, error) {
+	return readSingleEvent(ctx, NewClientStore(client), stream, kurrentdb.ReadStreamOptions{
+		Direction: kurrentdb.Backwards,
+		From:      kurrentdb.End{},
+	})
+}
+
+// readSingleEvent reads one event of stream with options. A link comes back as the link record the
+// stream holds, so EventNumber and Position are always the stream's own. A missing, soft-deleted or
+// tombstoned stream is no event rather than an error: reading a tombstoned stream fails with
+// ErrorCodeStreamDeleted where a soft-deleted one just reads as not found.
+func readSingleEvent(ctx context.Context, store EventStore, stream string, options kurrentdb.ReadStreamOptions) (*kurrentdb.RecordedEvent, error) {
+	events, err := store.ReadStream(ctx, stream, options, 1)
+	if err != nil {
+		if isStreamNotFound(err) || isStreamDeleted(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", stream, err)
+	}
+	defer events.Close()
+
+	event, err := events.Recv()
+	if errors.Is(err, io.EOF) || isStreamNotFound(err) || isStreamDeleted(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", stream, err)
+	}
+	return event.OriginalEvent(), nil
+}
+
+// isStreamDeleted reports whether err is the client's error for a tombstoned stream
+func isStreamDeleted(err error) bool {
+	var deleted *kurrentdb.StreamDeletedError
+	return err != nil && (errorCode(err) == kurrentdb.ErrorCodeStreamDeleted || errors.As(err, &deleted))
+}
+
+// brokenReadStore fails every read with err, standing in for a server that can't be reached
+type brokenReadStore struct {
+	EventStore
+	err error
+}
+
+func (s brokenReadStore) ReadStream(ctx context.Context, stream string, options kurrentdb.ReadStreamOptions, count uint64) (EventReader, error) {
+	return &sliceReader{err: s.err}, nil
+}
+
+// === CHECKS ===
+
+// RunFirstLastEventChecks reads the ends of missing, single-event and longer streams in memory
+func RunFirstLastEventChecks() {
+	checks := newChecker("first/last event")
+
+	ctx := context.Background()
+	store := NewMemoryEventStore()
+	first := kurrentdb.ReadStreamOptions{From: kurrentdb.Start{}}
+	last := kurrentdb.ReadStreamOptions{Direction: kurrentdb.Backwards, From: kurrentdb.End{}}
+	write := func(stream string, eventTypes ...string) {
+		for _, eventType := range eventTypes {
+			store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+				EventID: uuid.New(), EventType: eventType, ContentType: kurrentdb.ContentTypeJson, Data: []byte(`{}`),
+			})
+		}
+	}
+
+	fmt.Println("\n--- Missing stream ---")
+	for name, options := range map[string]kurrentdb.ReadStreamOptions{"first": first, "last": last} {
+		event, err := readSingleEvent(ctx, store, "order-missing", options)
+		checks.Check(event == nil && err == nil, "the %s event of a missing stream should be nil without an error, got %v (%v)", name, event, err)
+	}
+
+	fmt.Println("\n--- Single event ---")
+	write("order-1", "OrderPlaced")
+	head, headErr := readSingleEvent(ctx, store, "order-1", first)
+	tip, tipErr := readSingleEvent(ctx, store, "order-1", last)
+	checks.Check(headErr == nil && tipErr == nil, "reading a stream should succeed: %v / %v", headErr, tipErr)
+	checks.Check(head != nil && tip != nil && head.EventID == tip.EventID && head.EventNumber == 0,
+		"a single event should be both the first and the last, got %v and %v", head, tip)
+
+	fmt.Println("\n--- Several events ---")
+	write("order-2", "OrderPlaced", "ItemAdded", "ItemAdded", "OrderShipped")
+	write("order-1", "OrderCancelled")
+	head, _ = readSingleEvent(ctx, store, "order-2", first)
+	tip, _ = readSingleEvent(ctx, store, "order-2", last)
+	checks.Check(head != nil && head.EventType == "OrderPlaced" && head.EventNumber == 0, "the first event should be the creation, got %v", head)
+	checks.Check(tip != nil && tip.EventType == "OrderShipped" && tip.EventNumber == 3, "the last event should be the tip at revision 3, got %v", tip)
+	tip, _ = readSingleEvent(ctx, store, "order-1", last)
+	checks.Check(tip != nil && tip.EventType == "OrderCancelled" && tip.StreamID == "order-1",
+		"the last event should follow later appends to its own stream only, got %v", tip)
+	if head != nil && tip != nil {
+		fmt.Printf("  order-2 first: %s@%d, order-1 last: %s@%d\n", head.EventType, head.EventNumber, tip.EventType, tip.EventNumber)
+	}
+
+	fmt.Println("\n--- Read errors ---")
+	errDown := errors.New("connection refused")
+	event, err := readSingleEvent(ctx, brokenReadStore{EventStore: store, err: errDown}, "order-2", last)
+	checks.Check(event == nil && errors.Is(err, errDown), "other read errors should be returned, got %v (%v)", event, err)
+	event, err = readSingleEvent(ctx, brokenReadStore{EventStore: store, err: &kurrentdb.StreamDeletedError{Stream: "order-2"}}, "order-2", first)
+	checks.Check(event == nil && err == nil, "a tombstoned stream should read as empty, got %v (%v)", event, err)
+
+	checks.Done()
+}
+
+// === DEMO ===
+
+// RunFirstLastEvent reads the ends of a fresh stream, a missing one, a deleted one and a tombstoned one
+func RunFirstLastEvent() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	stream := Streams.Name("order", uuid.New().String())
+	var events []kurrentdb.EventData
+	for _, eventType := range []string{"OrderPlaced", "ItemAdded", "OrderShipped"} {
+		events = append(events, kurrentdb.EventData{
+			EventID: uuid.New(), EventType: eventType, ContentType: kurrentdb.ContentTypeJson, Data: []byte(`{}`),
+		})
+	}
+	_, appendErr := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{StreamState: kurrentdb.NoStream{}}, events...)
+
+	first, firstErr := FirstEvent(ctx, client, stream)
+	last, lastErr := LastEvent(ctx, client, stream)
+	if first != nil && last != nil {
+		fmt.Printf("%s: first %s@%d, last %s@%d\n", stream, first.EventType, first.EventNumber, last.EventType, last.EventNumber)
+	}
+
+	missing, missingErr := LastEvent(ctx, client, Streams.Name("order", uuid.New().String()))
+	fmt.Printf("Missing stream: %v (%v)\n", missing, missingErr)
+
+	_, deleteErr := client.DeleteStream(ctx, stream, kurrentdb.DeleteStreamOptions{})
+	deleted, deletedErr := FirstEvent(ctx, client, stream)
+	fmt.Printf("Deleted stream: %v (%v)\n", deleted, deletedErr)
+
+	tombstoned := Streams.Name("order", uuid.New().String())
+	_, tombstoneErr := client.AppendToStream(ctx, tombstoned, kurrentdb.AppendToStreamOptions{StreamState: kurrentdb.NoStream{}}, events[0])
+	if tombstoneErr == nil {
+		_, tombstoneErr = client.TombstoneStream(ctx, tombstoned, kurrentdb.TombstoneStreamOptions{})
+	}
+	tombstonedFirst, tombstonedFirstErr := FirstEvent(ctx, client, tombstoned)
+	tombstonedLast, tombstonedLastErr := LastEvent(ctx, client, tombstoned)
+	fmt.Printf("Tombstoned stream: %v / %v (%v / %v)\n", tombstonedFirst, tombstonedLast, tombstonedFirstErr, tombstonedLastErr)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+	if appendErr != nil || firstErr != nil || lastErr != nil {
+		fmt.Printf("FAIL: Appending and reading should succeed, got %v / %v / %v\n", appendErr, firstErr, lastErr)
+		passed = false
+	}
+	if first == nil || first.EventType != "OrderPlaced" || first.EventNumber != 0 {
+		fmt.Printf("FAIL: The first event should be OrderPlaced at 0, got %v\n", first)
+		passed = false
+	}
+	if last == nil || last.EventType != "OrderShipped" || last.EventNumber != 2 {
+		fmt.Printf("FAIL: The last event should be OrderShipped at 2, got %v\n", last)
+		passed = false
+	}
+	if missing != nil || missingErr != nil {
+		fmt.Printf("FAIL: A missing stream should have no last event and no error, got %v (%v)\n", missing, missingErr)
+		passed = false
+	}
+	if deleteErr != nil || deleted != nil || deletedErr != nil {
+		fmt.Printf("FAIL: A deleted stream should read as empty, got %v (%v, delete %v)\n", deleted, deletedErr, deleteErr)
+		passed = false
+	}
+	if tombstoneErr != nil || tombstonedFirst != nil || tombstonedLast != nil || tombstonedFirstErr != nil || tombstonedLastErr != nil {
+		fmt.Printf("FAIL: A tombstoned stream should read as empty, got %v / %v (%v / %v, tombstone %v)\n",
+			tombstonedFirst, tombstonedLast, tombstonedFirstErr, tombstonedLastErr, tombstoneErr)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll first/last event tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
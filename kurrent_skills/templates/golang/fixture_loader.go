@@ -0,0 +1,493 @@
+// KurrentDB Go Client Example - Seeding integration tests from fixture files
+// Demonstrates: Appending a described event history with deterministic ids, re-seeding idempotently and asserting on its positions
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === FIXTURES ===
+
+// ErrFixtureConflict is returned when a fixture stream already holds events that aren't the
+// fixture's: the stream name collides with other data, or the fixture changed since it was seeded
+var ErrFixtureConflict = errors.New("stream holds events not in the fixture")
+
+// fixtureRunPlaceholder in a fixture's stream names is replaced with the loader's run id, so each
+// test run seeds its own streams
+const fixtureRunPlaceholder = "{run}"
+
+// Fixture is a described event history. Fixtures are JSON; the template has no YAML dependency,
+// so convert YAML fixtures first (e.g. yq -o json).
+//
+//	{
+//	  "name": "orders-with-items",
+//	  "streams": [
+//	    {"stream": "order-{run}-1", "events": [
+//	      {"type": "OrderCreated", "data": {"amount": 100}, "metadata": {"$correlationId": "c-1"}}
+//	    ]}
+//	  ]
+//	}
+type Fixture struct {
+	Name    string          `json:"name"`
+	Streams []FixtureStream `json:"streams"`
+}
+
+// FixtureStream is one stream's events, appended in order. Stream may contain {run}.
+type FixtureStream struct {
+	Stream string         `json:"stream"`
+	Events []FixtureEvent `json:"events"`
+}
+
+// FixtureEvent is one event. Data and Metadata are written as given; ID is derived from the fixture,
+// stream and index when empty.
+type FixtureEvent struct {
+	ID       string          `json:"id,omitempty"`
+	Type     string          `json:"type"`
+	Data     json.RawMessage `json:"data,omitempty"`
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+}
+
+// ParseFixture decodes and validates a fixture. Unknown fields are rejected, so a typo doesn't
+// silently seed less than the test expects.
+func ParseFixture(data []byte) (Fixture, error) {
+	var fixture Fixture
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fixture); err != nil {
+		return Fixture{}, fmt.Errorf("decode fixture: %w", err)
+	}
+	if fixture.Name == "" {
+		return Fixture{}, errors.New("fixture has no name")
+	}
+
+	seen := make(map[string]bool)
+	for _, stream := range fixture.Streams {
+		if stream.Stream == "" {
+			return Fixture{}, fmt.Errorf("fixture %s has a stream without a name", fixture.Name)
+		}
+		if seen[stream.Stream] {
+			return Fixture{}, fmt.Errorf("fixture %s lists %s twice", fixture.Name, stream.Stream)
+		}
+		seen[stream.Stream] = true
+		for i, event := range stream.Events {
+			if event.Type == "" {
+				return Fixture{}, fmt.Errorf("%s event %d has no type", stream.Stream, i)
+			}
+			if event.ID != "" {
+				if _, err := uuid.Parse(event.ID); err != nil {
+					return Fixture{}, fmt.Errorf("%s event %d: id: %w", stream.Stream, i, err)
+				}
+			}
+		}
+	}
+	return fixture, nil
+}
+
+// LoadFixtureFile reads and parses the fixture at path
+func LoadFixtureFile(path string) (Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Fixture{}, err
+	}
+	fixture, err := ParseFixture(data)
+	if err != nil {
+		return Fixture{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return fixture, nil
+}
+
+// === LOADER ===
+
+// SeededEvent is where a fixture event ended up
+type SeededEvent struct {
+	EventID  uuid.UUID
+	Type     string
+	Stream   string
+	Revision uint64
+	Position kurrentdb.Position
+}
+
+// SeedResult is what FixtureLoader.Seed wrote, for assertions
+type SeedResult struct {
+	// Streams maps each stream as named in the fixture, {run} and all, to its events
+	Streams map[string][]SeededEvent
+	// Last is the highest position of any fixture event: wait for a projection to reach it
+	Last kurrentdb.Position
+	// Appended and Existing count the events written by this call and those already there
+	Appended, Existing int
+}
+
+// Stream returns the name a fixture stream was seeded as
+func (r SeedResult) Stream(name string) string {
+	if events := r.Streams[name]; len(events) > 0 {
+		return events[0].Stream
+	}
+	return ""
+}
+
+// FixtureLoader seeds fixtures into a store. Event ids are derived from the fixture name, the
+// seeded stream name and the event's index, so seeding the same fixture again in a run finds the
+// events already there and appends only what is missing.
+type FixtureLoader struct {
+	store EventStore
+	// Run replaces {run} in stream names. Use a fresh one per test run to isolate runs on a shared
+	// server, and the same one to re-seed within a run.
+	Run string
+}
+
+func NewFixtureLoader(store EventStore, run string) *FixtureLoader {
+	return &FixtureLoader{store: store, Run: run}
+}
+
+// StreamName returns the stream a fixture stream name is seeded as
+func (l *FixtureLoader) StreamName(name string) string {
+	return strings.ReplaceAll(name, fixtureRunPlaceholder, l.Run)
+}
+
+// FixtureEventID is the id given to the index-th event of stream when the fixture has none
+func FixtureEventID(fixture, stream string, index int) uuid.UUID {
+	namespace := uuid.NewSHA1(uuid.NameSpaceURL, []byte("kurrentdb-fixture:"+fixture))
+	return uuid.NewSHA1(namespace, []byte(fmt.Sprintf("%s#%d", stream, index)))
+}
+
+// Seed appends the fixture's events, stream by stream. A stream already holding a prefix of the
+// fixture's events, matched by id, gets the rest appended after it; a stream holding anything else
+// fails with ErrFixtureConflict. The rest is appended in one call expecting the revision the stream
+// was read at, and read again if another seeder got there first, so tests seeding the same run
+// concurrently don't duplicate events.
+func (l *FixtureLoader) Seed(ctx context.Context, fixture Fixture) (SeedResult, error) {
+	result := SeedResult{Streams: make(map[string][]SeededEvent, len(fixture.Streams))}
+	for _, fixtureStream := range fixture.Streams {
+		stream := l.StreamName(fixtureStream.Stream)
+		events := make([]kurrentdb.EventData, len(fixtureStream.Events))
+		for i, event := range fixtureStream.Events {
+			events[i] = l.eventData(fixture.Name, stream, i, event)
+		}
+
+		recorded, existing, err := l.seedStream(ctx, stream, events)
+		if err != nil {
+			return result, err
+		}
+		result.Existing += existing
+		result.Appended += len(events) - existing
+
+		seeded := make([]SeededEvent, len(recorded))
+		for i, event := range recorded {
+			seeded[i] = SeededEvent{EventID: event.EventID, Type: event.EventType, Stream: stream, Revision: event.EventNumber, Position: event.Position}
+			if positionAfter(event.Position, result.Last) {
+				result.Last = event.Position
+			}
+		}
+		result.Streams[fixtureStream.Stream] = seeded
+	}
+	return result, nil
+}
+
+// seedStreamAttempts bounds how often seedStream re-reads a stream another seeder appended to
+const seedStreamAttempts = 3
+
+// seedStream makes stream hold events and returns its recorded events and how many were already there
+func (l *FixtureLoader) seedStream(ctx context.Context, stream string, events []kurrentdb.EventData) ([]*kurrentdb.RecordedEvent, int, error) {
+	for attempt := 1; ; attempt++ {
+		recorded, _, err := readStreamFrom(ctx, l.store, stream, NoVersion)
+		if err != nil {
+			return nil, 0, fmt.Errorf("read %s: %w", stream, err)
+		}
+		if len(recorded) > len(events) {
+			return nil, 0, fmt.Errorf("%w: %s has %d events, the fixture %d", ErrFixtureConflict, stream, len(recorded), len(events))
+		}
+		for i, event := range recorded {
+			if event.EventID != events[i].EventID {
+				return nil, 0, fmt.Errorf("%w: %s event %d is %s, expected %s", ErrFixtureConflict, stream, i, event.EventID, events[i].EventID)
+			}
+		}
+		existing := len(recorded)
+		if existing == len(events) {
+			return recorded, existing, nil
+		}
+
+		var state kurrentdb.StreamState = kurrentdb.NoStream{}
+		if existing > 0 {
+			state = kurrentdb.Revision(uint64(existing - 1))
+		}
+		_, err = l.store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{StreamState: state}, events[existing:]...)
+		if isWrongExpectedVersion(err) && attempt < seedStreamAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("seed %s: %w", stream, err)
+		}
+
+		// The write result only has the last event's position; read the stream back for all of them
+		if recorded, _, err = readStreamFrom(ctx, l.store, stream, NoVersion); err != nil {
+			return nil, 0, fmt.Errorf("read back %s: %w", stream, err)
+		}
+		return recorded[:len(events)], existing, nil
+	}
+}
+
+func (l *FixtureLoader) eventData(fixture, stream string, index int, event FixtureEvent) kurrentdb.EventData {
+	id := FixtureEventID(fixture, stream, index)
+	if event.ID != "" {
+		id = uuid.MustParse(event.ID) // validated by ParseFixture
+	}
+	data := []byte(event.Data)
+	if data == nil {
+		data = []byte("{}")
+	}
+	return kurrentdb.EventData{
+		EventID:     id,
+		EventType:   event.Type,
+		ContentType: kurrentdb.ContentTypeJson,
+		Data:        data,
+		Metadata:    event.Metadata,
+	}
+}
+
+// ProjectSeeded runs projection over $all from the start until its checkpoint has passed every
+// event the seed wrote, so a test can assert on its state next. Events from earlier runs on the
+// same server are projected too, into their own streams.
+func ProjectSeeded(ctx context.Context, store EventStore, projection *Projection, seeded SeedResult) error {
+	followCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	followed := make(chan error, 1)
+	go func() {
+		followed <- projection.Follow(followCtx, store, kurrentdb.SubscribeToAllOptions{
+			From:   kurrentdb.Start{},
+			Filter: kurrentdb.ExcludeSystemEventsFilter(),
+		}, func(*kurrentdb.RecordedEvent) bool { return false })
+	}()
+	reached := make(chan error, 1)
+	go func() { reached <- projection.WaitFor(followCtx, seeded.Last) }()
+
+	select {
+	case err := <-reached:
+		stop()
+		<-followed
+		return err
+	case err := <-followed:
+		return fmt.Errorf("projection %s stopped before the fixture: %w", projection.Name, err)
+	}
+}
+
+// ordersWithItemsFixture is two orders: one with two items that shipped, one with an item still open
+const ordersWithItemsFixture = `{
+  "name": "orders-with-items",
+  "streams": [
+    {"stream": "order-{run}-1", "events": [
+      {"type": "OrderCreated", "id": "6f1c3a52-4d0e-4b8a-9a51-0c2b7f1e9d01",
+       "data": {"orderId": "1", "customerId": "cust-1", "amount": 100}, "metadata": {"$correlationId": "fixture-order-1"}},
+      {"type": "ItemAdded", "data": {"item": "Widget", "price": 25}, "metadata": {"$correlationId": "fixture-order-1"}},
+      {"type": "ItemAdded", "data": {"item": "Gadget", "price": 15}, "metadata": {"$correlationId": "fixture-order-1"}},
+      {"type": "OrderShipped", "data": {"shippedAt": "2024-01-15T10:00:00Z"}, "metadata": {"$correlationId": "fixture-order-1"}}
+    ]},
+    {"stream": "order-{run}-2", "events": [
+      {"type": "OrderCreated", "data": {"orderId": "2", "customerId": "cust-2", "amount": 50}},
+      {"type": "ItemAdded", "data": {"item": "Gizmo", "price": 10}}
+    ]}
+  ]
+}`
+
+// === CHECKS ===
+
+// RunFixtureLoaderChecks seeds the orders fixture into an in-memory store, re-seeds it and projects it
+func RunFixtureLoaderChecks() {
+	checks := newChecker("fixture loader")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	fmt.Println("\n--- Parsing ---")
+	path := fmt.Sprintf("%s/orders-with-items-%s.json", os.TempDir(), uuid.New())
+	if err := os.WriteFile(path, []byte(ordersWithItemsFixture), 0o644); err != nil {
+		panic(err)
+	}
+	defer os.Remove(path)
+	fixture, err := LoadFixtureFile(path)
+	checks.Check(err == nil && fixture.Name == "orders-with-items" && len(fixture.Streams) == 2 && len(fixture.Streams[0].Events) == 4,
+		"the orders fixture should load, got %+v (%v)", fixture, err)
+	for _, bad := range []struct{ name, data string }{
+		{"unknown field", `{"name": "f", "streams": [{"stream": "s", "evnts": []}]}`},
+		{"no name", `{"streams": []}`},
+		{"no type", `{"name": "f", "streams": [{"stream": "s", "events": [{"data": {}}]}]}`},
+		{"bad id", `{"name": "f", "streams": [{"stream": "s", "events": [{"type": "T", "id": "42"}]}]}`},
+		{"stream twice", `{"name": "f", "streams": [{"stream": "s"}, {"stream": "s"}]}`},
+		{"bad data", `{"name": "f", "streams": [{"stream": "s", "events": [{"type": "T", "data": {]}]}`},
+	} {
+		_, err := ParseFixture([]byte(bad.data))
+		fmt.Printf("  %s: %v\n", bad.name, err)
+		checks.Check(err != nil, "a fixture with %s should be rejected", bad.name)
+	}
+	_, err = LoadFixtureFile(path + ".missing")
+	checks.Check(errors.Is(err, os.ErrNotExist), "a missing file should fail with ErrNotExist, got %v", err)
+
+	fmt.Println("\n--- Seeding ---")
+	store := NewMemoryEventStore()
+	loader := NewFixtureLoader(store, "r1")
+	seeded, err := loader.Seed(ctx, fixture)
+	checks.Check(err == nil && seeded.Appended == 6 && seeded.Existing == 0, "the first seed should append 6 events, got %d/%d (%v)", seeded.Appended, seeded.Existing, err)
+	checks.Check(seeded.Stream("order-{run}-1") == "order-r1-1" && seeded.Stream("order-{run}-2") == "order-r1-2",
+		"streams should be named for the run, got %q and %q", seeded.Stream("order-{run}-1"), seeded.Stream("order-{run}-2"))
+	order1 := seeded.Streams["order-{run}-1"]
+	checks.Check(len(order1) == 4 && order1[0].EventID == uuid.MustParse("6f1c3a52-4d0e-4b8a-9a51-0c2b7f1e9d01"),
+		"a fixture's explicit id should be used, got %+v", order1)
+	checks.Check(len(order1) == 4 && order1[1].EventID == FixtureEventID("orders-with-items", "order-r1-1", 1) && order1[3].Revision == 3 && order1[3].Type == "OrderShipped",
+		"other ids should be derived from the fixture, stream and index, got %+v", order1)
+	order2 := seeded.Streams["order-{run}-2"]
+	checks.Check(len(order2) == 2 && seeded.Last == order2[1].Position, "Last should be the last event written, got %+v", seeded.Last)
+	recorded, _, _ := readStreamFrom(ctx, store, "order-r1-1", NoVersion)
+	meta := ""
+	if len(recorded) > 0 {
+		meta = string(recorded[0].UserMetadata)
+	}
+	checks.Check(meta == `{"$correlationId": "fixture-order-1"}`, "metadata should be written as given, got %s", meta)
+
+	fmt.Println("\n--- Re-seeding ---")
+	again, err := loader.Seed(ctx, fixture)
+	fmt.Printf("  appended %d, existing %d\n", again.Appended, again.Existing)
+	checks.Check(err == nil && again.Appended == 0 && again.Existing == 6, "re-seeding should append nothing, got %d/%d (%v)", again.Appended, again.Existing, err)
+	checks.Check(again.Last == seeded.Last && len(again.Streams["order-{run}-1"]) == 4 && again.Streams["order-{run}-1"][2] == order1[2],
+		"re-seeding should report the same positions, got %+v", again.Streams["order-{run}-1"])
+
+	// A seed cut short after the first event finishes where it stopped
+	partial := NewFixtureLoader(store, "r2")
+	first := partial.eventData(fixture.Name, "order-r2-1", 0, fixture.Streams[0].Events[0])
+	store.AppendToStream(ctx, "order-r2-1", kurrentdb.AppendToStreamOptions{}, first)
+	resumed, err := partial.Seed(ctx, fixture)
+	checks.Check(err == nil && resumed.Appended == 5 && resumed.Existing == 1, "a partial seed should be completed, got %d/%d (%v)", resumed.Appended, resumed.Existing, err)
+	checks.Check(resumed.Streams["order-{run}-1"][1].EventID != order1[1].EventID, "another run should get other event ids")
+
+	fmt.Println("\n--- Conflicts ---")
+	store.AppendToStream(ctx, "order-r3-2", kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+		EventType: "OrderCreated", ContentType: kurrentdb.ContentTypeJson, Data: []byte(`{"orderId":"x"}`),
+	})
+	_, err = NewFixtureLoader(store, "r3").Seed(ctx, fixture)
+	fmt.Printf("  %v\n", err)
+	checks.Check(errors.Is(err, ErrFixtureConflict), "a stream holding other events should conflict, got %v", err)
+	extended := fixture
+	extended.Streams = []FixtureStream{{Stream: "order-{run}-1", Events: fixture.Streams[0].Events[:2]}}
+	_, err = loader.Seed(ctx, extended)
+	checks.Check(errors.Is(err, ErrFixtureConflict), "a stream holding more than the fixture should conflict, got %v", err)
+
+	fmt.Println("\n--- Projecting ---")
+	projection := NewOrderSummaryProjection()
+	err = ProjectSeeded(ctx, store, projection, seeded)
+	var shipped, open map[string]interface{}
+	projection.Read(func(p *Projection) {
+		shipped, open = p.getLocked("order-r1-1"), p.getLocked("order-r1-2")
+	})
+	fmt.Printf("  order-r1-1: %v\n  order-r1-2: %v\n", shipped, open)
+	checks.Check(err == nil, "projecting should reach the fixture, got %v", err)
+	checks.Check(shipped != nil && shipped["status"] == "shipped" && shipped["amount"] == 140.0 && len(stringSlice(shipped["items"])) == 2,
+		"order 1 should be shipped with 2 items for 140, got %v", shipped)
+	checks.Check(open != nil && open["status"] == "created" && open["amount"] == 60.0, "order 2 should be open for 60, got %v", open)
+
+	checks.Done()
+}
+
+// === DEMO ===
+
+// RunFixtureLoader seeds the orders fixture for a fresh run, seeds it again and projects it
+func RunFixtureLoader() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	// Fixtures normally live next to the tests; the demo writes its own
+	path := fmt.Sprintf("%s/orders-with-items-%s.json", os.TempDir(), uuid.New())
+	if err := os.WriteFile(path, []byte(ordersWithItemsFixture), 0o644); err != nil {
+		panic(err)
+	}
+	defer os.Remove(path)
+	fixture, err := LoadFixtureFile(path)
+	if err != nil {
+		panic(err)
+	}
+
+	// === SEED ===
+	store := NewClientStore(client)
+	loader := NewFixtureLoader(store, strings.ReplaceAll(uuid.New().String(), "-", "")[:12])
+	fmt.Printf("\n=== Seeding %s for run %s ===\n", fixture.Name, loader.Run)
+	seeded, err := loader.Seed(ctx, fixture)
+	if err != nil {
+		panic(err)
+	}
+	for _, stream := range fixture.Streams {
+		for _, event := range seeded.Streams[stream.Stream] {
+			fmt.Printf("  %s@%d %-12s %s at %d\n", event.Stream, event.Revision, event.Type, event.EventID, event.Position.Commit)
+		}
+	}
+
+	fmt.Println("\n=== Seeding again ===")
+	again, err := loader.Seed(ctx, fixture)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("  appended %d, already there %d\n", again.Appended, again.Existing)
+
+	// === PROJECT ===
+	fmt.Println("\n=== Projecting ===")
+	projection := NewOrderSummaryProjection()
+	projectErr := ProjectSeeded(ctx, store, projection, seeded)
+	var shipped, open map[string]interface{}
+	projection.Read(func(p *Projection) {
+		shipped = p.getLocked(seeded.Stream("order-{run}-1"))
+		open = p.getLocked(seeded.Stream("order-{run}-2"))
+	})
+	fmt.Printf("  %s: %v\n  %s: %v\n", seeded.Stream("order-{run}-1"), shipped, seeded.Stream("order-{run}-2"), open)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if seeded.Appended != 6 || seeded.Existing != 0 {
+		fmt.Printf("FAIL: Expected the first seed to append 6 events, got %d appended, %d existing\n", seeded.Appended, seeded.Existing)
+		passed = false
+	}
+	if again.Appended != 0 || again.Existing != 6 || again.Last != seeded.Last {
+		fmt.Printf("FAIL: Expected re-seeding to find all 6 events at the same positions, got %+v\n", again)
+		passed = false
+	}
+	order1 := seeded.Streams["order-{run}-1"]
+	if len(order1) != 4 || order1[0].EventID != uuid.MustParse("6f1c3a52-4d0e-4b8a-9a51-0c2b7f1e9d01") ||
+		order1[1].EventID != FixtureEventID(fixture.Name, order1[1].Stream, 1) {
+		fmt.Printf("FAIL: Expected the fixture's ids, got %+v\n", order1)
+		passed = false
+	}
+	if projectErr != nil {
+		fmt.Printf("FAIL: Expected the projection to reach the fixture, got %v\n", projectErr)
+		passed = false
+	}
+	if shipped == nil || shipped["status"] != "shipped" || shipped["amount"] != 140.0 {
+		fmt.Printf("FAIL: Expected order 1 shipped for 140, got %v\n", shipped)
+		passed = false
+	}
+	if open == nil || open["status"] != "created" || open["amount"] != 60.0 {
+		fmt.Printf("FAIL: Expected order 2 open for 60, got %v\n", open)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll fixture loader tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
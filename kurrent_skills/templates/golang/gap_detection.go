@@ -0,0 +1,401 @@
+// KurrentDB Go Client Example - Gap detection in stream event numbers
+// Demonstrates: Verifying event numbers increase by exactly one per stream, resumably, over one stream or $all
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === ANOMALIES ===
+
+// EventNumberAnomalyKind is what is wrong with an event number
+type EventNumberAnomalyKind int
+
+const (
+	// EventNumberGap is an event number more than one past the previous one in its stream
+	EventNumberGap EventNumberAnomalyKind = iota
+	// EventNumberDuplicate is an event number at or below the previous one in its stream
+	EventNumberDuplicate
+)
+
+func (k EventNumberAnomalyKind) String() string {
+	if k == EventNumberDuplicate {
+		return "duplicate"
+	}
+	return "gap"
+}
+
+// EventNumberAnomaly is one event whose number doesn't follow the previous event of its stream
+type EventNumberAnomaly struct {
+	Kind     EventNumberAnomalyKind `json:"kind"`
+	Stream   string                 `json:"stream"`
+	Expected uint64                 `json:"expected"`
+	Got      uint64                 `json:"got"`
+	Position kurrentdb.Position     `json:"position"`
+}
+
+func (a EventNumberAnomaly) String() string {
+	if a.Kind == EventNumberGap {
+		return fmt.Sprintf("%s: gap, expected %d, got %d (%d missing) at %d/%d",
+			a.Stream, a.Expected, a.Got, a.Got-a.Expected, a.Position.Commit, a.Position.Prepare)
+	}
+	return fmt.Sprintf("%s: duplicate, expected %d, got %d again at %d/%d",
+		a.Stream, a.Expected, a.Got, a.Position.Commit, a.Position.Prepare)
+}
+
+// === SCAN STATE ===
+
+// GapScan is the progress and findings of a scan. It marshals to JSON, so a long scan can be saved
+// between runs and resumed with everything it knew: where it stopped and the last event number of
+// every stream, so a gap spanning the resume point is still found.
+type GapScan struct {
+	// Position is the last $all position checked by CheckAll; nil before it starts
+	Position *kurrentdb.Position `json:"position,omitempty"`
+	// Last is the last event number seen per stream
+	Last      map[string]uint64    `json:"last"`
+	Events    int                  `json:"events"`
+	Anomalies []EventNumberAnomaly `json:"anomalies"`
+}
+
+func NewGapScan() *GapScan {
+	return &GapScan{Last: make(map[string]uint64)}
+}
+
+// observe checks one event against the last of its stream. The first event of a stream only sets
+// the baseline: truncated and scavenged streams legitimately start above 0.
+func (s *GapScan) observe(event *kurrentdb.RecordedEvent) {
+	// A hard delete's tombstone is numbered at the maximum, not after the last event
+	if event.EventType == "$streamDeleted" {
+		return
+	}
+	s.Events++
+	last, seen := s.Last[event.StreamID]
+	switch {
+	case !seen:
+	case event.EventNumber == last+1:
+	case event.EventNumber > last+1:
+		s.Anomalies = append(s.Anomalies, EventNumberAnomaly{
+			Kind: EventNumberGap, Stream: event.StreamID, Expected: last + 1, Got: event.EventNumber, Position: event.Position,
+		})
+	default:
+		s.Anomalies = append(s.Anomalies, EventNumberAnomaly{
+			Kind: EventNumberDuplicate, Stream: event.StreamID, Expected: last + 1, Got: event.EventNumber, Position: event.Position,
+		})
+		// Keep the highest number as the baseline, so one stray event is reported once
+		return
+	}
+	s.Last[event.StreamID] = event.EventNumber
+}
+
+// First returns the first anomaly found, if any
+func (s *GapScan) First() (EventNumberAnomaly, bool) {
+	if len(s.Anomalies) == 0 {
+		return EventNumberAnomaly{}, false
+	}
+	return s.Anomalies[0], true
+}
+
+// Summary describes the scan in one line
+func (s *GapScan) Summary() string {
+	gaps := 0
+	for _, anomaly := range s.Anomalies {
+		if anomaly.Kind == EventNumberGap {
+			gaps++
+		}
+	}
+	return fmt.Sprintf("%d events in %d streams checked: %d gaps, %d duplicates",
+		s.Events, len(s.Last), gaps, len(s.Anomalies)-gaps)
+}
+
+// === GAP DETECTOR ===
+
+// GapDetector reads streams, or $all, and records event number anomalies in a GapScan
+type GapDetector struct {
+	// Limit, if set, makes each call stop after checking that many events, to run a long scan in
+	// slices and save it between them
+	Limit int
+
+	readStream func(ctx context.Context, stream string, from kurrentdb.StreamPosition) (EventReader, error)
+	readAll    func(ctx context.Context, from kurrentdb.AllPosition) (EventReader, error)
+}
+
+func NewGapDetector(client *kurrentdb.Client) *GapDetector {
+	return &GapDetector{
+		readStream: func(ctx context.Context, stream string, from kurrentdb.StreamPosition) (EventReader, error) {
+			return client.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{From: from}, ^uint64(0))
+		},
+		readAll: func(ctx context.Context, from kurrentdb.AllPosition) (EventReader, error) {
+			return client.ReadAll(ctx, kurrentdb.ReadAllOptions{From: from}, ^uint64(0))
+		},
+	}
+}
+
+// CheckStream checks stream from the event after the last one scan has seen in it. It returns true
+// once the end of the stream was reached, false if Limit stopped it first.
+func (d *GapDetector) CheckStream(ctx context.Context, stream string, scan *GapScan) (bool, error) {
+	var from kurrentdb.StreamPosition = kurrentdb.Start{}
+	if last, ok := scan.Last[stream]; ok {
+		from = kurrentdb.Revision(last + 1)
+	}
+	events, err := d.readStream(ctx, stream, from)
+	if err != nil {
+		if isStreamNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	defer events.Close()
+
+	for checked := 0; d.Limit == 0 || checked < d.Limit; checked++ {
+		event, err := events.Recv()
+		if errors.Is(err, io.EOF) || isStreamNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		scan.observe(Resolve(event, false))
+	}
+	return false, nil
+}
+
+// CheckAll checks every user stream in $all, from the position after scan.Position. System streams,
+// whose names start with "$", are skipped. It returns true once the end of $all was reached, false
+// if Limit stopped it first.
+func (d *GapDetector) CheckAll(ctx context.Context, scan *GapScan) (bool, error) {
+	var from kurrentdb.AllPosition = kurrentdb.Start{}
+	if scan.Position != nil {
+		from = *scan.Position
+	}
+	events, err := d.readAll(ctx, from)
+	if err != nil {
+		return false, err
+	}
+	defer events.Close()
+
+	for checked := 0; d.Limit == 0 || checked < d.Limit; {
+		resolved, err := events.Recv()
+		if errors.Is(err, io.EOF) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		event := Resolve(resolved, false)
+		// Reading from a position includes the event at it, already checked
+		if scan.Position != nil && !positionAfter(event.Position, *scan.Position) {
+			continue
+		}
+		position := event.Position
+		scan.Position = &position
+		if strings.HasPrefix(event.StreamID, "$") {
+			continue
+		}
+		scan.observe(event)
+		checked++
+	}
+	return false, nil
+}
+
+// === FAKE CLIENT ===
+
+// memoryLog is a fake client for reads: events in $all order, read back as the server would,
+// without checking that their numbers make sense, so a corrupted fixture reads as written
+type memoryLog struct {
+	events []*kurrentdb.RecordedEvent
+}
+
+// append adds events for stream with the given numbers, at the next $all positions
+func (l *memoryLog) append(stream string, numbers ...uint64) *memoryLog {
+	for _, number := range numbers {
+		commit := uint64(len(l.events)+1) * 100
+		l.events = append(l.events, syntheticEvent(stream, "Event", number, commit, `{}`))
+	}
+	return l
+}
+
+func (l *memoryLog) readStream(ctx context.Context, stream string, from kurrentdb.StreamPosition) (EventReader, error) {
+	revision := uint64(0)
+	if r, ok := from.(kurrentdb.StreamRevision); ok {
+		revision = r.Value
+	}
+	var events []*kurrentdb.RecordedEvent
+	for _, event := range l.events {
+		if event.StreamID == stream && event.EventNumber >= revision {
+			events = append(events, event)
+		}
+	}
+	return &sliceReader{events: events}, nil
+}
+
+func (l *memoryLog) readAll(ctx context.Context, from kurrentdb.AllPosition) (EventReader, error) {
+	var events []*kurrentdb.RecordedEvent
+	for _, event := range l.events {
+		if position, ok := from.(kurrentdb.Position); !ok || !positionAfter(position, event.Position) {
+			events = append(events, event)
+		}
+	}
+	return &sliceReader{events: events}, nil
+}
+
+// RunGapDetectionChecks scans a corrupted in-memory fixture, whole and in resumed slices, no server required
+func RunGapDetectionChecks() {
+	checks := newChecker("gap detection")
+
+	// order-1 lost event 3, order-2 has event 1 twice, order-3 is intact, order-4 was truncated
+	// before 5, and $ce-order holds links whose numbers are the system's business
+	log := (&memoryLog{}).
+		append("order-1", 0, 1).
+		append("order-2", 0, 1).
+		append("order-3", 0).
+		append("order-1", 2).
+		append("order-2", 1, 2).
+		append("$ce-order", 0, 7).
+		append("order-3", 1, 2).
+		append("order-1", 4, 5).
+		append("order-4", 5, 6)
+	detector := &GapDetector{readStream: log.readStream, readAll: log.readAll}
+	ctx := context.Background()
+
+	// --- Single stream ---
+	fmt.Println("\n--- Single stream ---")
+	streamScan := NewGapScan()
+	complete, err := detector.CheckStream(ctx, "order-1", streamScan)
+	first, found := streamScan.First()
+	fmt.Printf("  order-1: %s\n  first: %v\n", streamScan.Summary(), first)
+	checks.Check(complete && err == nil, "the stream should be checked to the end, got %v (%v)", complete, err)
+	checks.Check(found && first.Kind == EventNumberGap && first.Expected == 3 && first.Got == 4 && len(streamScan.Anomalies) == 1,
+		"order-1 should have one gap from 3 to 4, got %v", streamScan.Anomalies)
+	clean := NewGapScan()
+	detector.CheckStream(ctx, "order-3", clean)
+	detector.CheckStream(ctx, "order-4", clean)
+	detector.CheckStream(ctx, "order-9", clean)
+	checks.Check(len(clean.Anomalies) == 0 && clean.Events == 5, "intact, truncated and missing streams should be clean, got %s", clean.Summary())
+
+	// --- $all ---
+	fmt.Println("\n--- $all ---")
+	allScan := NewGapScan()
+	complete, err = detector.CheckAll(ctx, allScan)
+	fmt.Printf("  %s\n", allScan.Summary())
+	for _, anomaly := range allScan.Anomalies {
+		fmt.Printf("  %v\n", anomaly)
+	}
+	checks.Check(complete && err == nil, "$all should be checked to the end, got %v (%v)", complete, err)
+	checks.Check(allScan.Events == 14 && len(allScan.Last) == 4, "14 events in 4 user streams should be checked, got %s", allScan.Summary())
+	checks.Check(len(allScan.Anomalies) == 2, "expected 2 anomalies, got %v", allScan.Anomalies)
+	first, _ = allScan.First()
+	checks.Check(first.Kind == EventNumberDuplicate && first.Stream == "order-2" && first.Got == 1 && first.Position.Commit == 700,
+		"the first anomaly should be order-2's duplicate 1 at 700, got %v", first)
+	checks.Check(allScan.Last["order-2"] == 2, "a duplicate should not move the baseline back, got %d", allScan.Last["order-2"])
+
+	// --- Resuming ---
+	fmt.Println("\n--- Resuming in slices of 4 ---")
+	sliced := &GapDetector{Limit: 4, readStream: log.readStream, readAll: log.readAll}
+	var saved []byte
+	runs := 0
+	for complete = false; !complete && runs < 10; runs++ {
+		// Every run starts from the saved scan, as a fresh process would
+		scan := NewGapScan()
+		if saved != nil {
+			if err := json.Unmarshal(saved, scan); err != nil {
+				panic(err)
+			}
+		}
+		if complete, err = sliced.CheckAll(ctx, scan); err != nil {
+			panic(err)
+		}
+		if saved, err = json.Marshal(scan); err != nil {
+			panic(err)
+		}
+		fmt.Printf("  run %d: %s\n", runs+1, scan.Summary())
+	}
+	resumed := NewGapScan()
+	json.Unmarshal(saved, resumed)
+	checks.Check(runs == 4, "14 events in slices of 4 should take 4 runs, the last finding the end, got %d", runs)
+	checks.Check(fmt.Sprint(resumed.Anomalies) == fmt.Sprint(allScan.Anomalies) && resumed.Events == allScan.Events,
+		"a resumed scan should find what a single pass does, got %v", resumed.Anomalies)
+
+	// Resuming a stream scan picks up after its last event; more events appear meanwhile
+	log.append("order-3", 4)
+	complete, _ = detector.CheckStream(ctx, "order-3", clean)
+	last, _ := clean.First()
+	checks.Check(complete && len(clean.Anomalies) == 1 && last.Expected == 3 && last.Got == 4,
+		"resuming order-3 should find the new gap from 3 to 4, got %v", clean.Anomalies)
+
+	checks.Done()
+}
+
+// RunGapDetection writes a stream and checks it and $all from the stream's first event on a live server
+func RunGapDetection() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	stream := Streams.Name("order", uuid.New().String())
+	var events []kurrentdb.EventData
+	for i := 0; i < 5; i++ {
+		events = append(events, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   "ItemAdded",
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(fmt.Sprintf(`{"item":"item-%d","price":1}`, i)),
+		})
+	}
+	written, err := AppendAndPosition(ctx, client, stream, kurrentdb.AppendToStreamOptions{}, events[0])
+	if err != nil {
+		panic(err)
+	}
+	if _, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, events[1:]...); err != nil {
+		panic(err)
+	}
+
+	// === STREAM ===
+	detector := NewGapDetector(client)
+	streamScan := NewGapScan()
+	streamComplete, streamErr := detector.CheckStream(ctx, stream, streamScan)
+	fmt.Printf("\n%s: %s (%v)\n", stream, streamScan.Summary(), streamErr)
+
+	// === $ALL ===
+	// Start just before the stream's first event, as a resumed scan would
+	allScan := NewGapScan()
+	allScan.Position = &kurrentdb.Position{Commit: written.Position.Commit - 1, Prepare: written.Position.Prepare - 1}
+	allComplete, allErr := detector.CheckAll(ctx, allScan)
+	fmt.Printf("$all: %s (%v)\n", allScan.Summary(), allErr)
+	for _, anomaly := range allScan.Anomalies {
+		fmt.Printf("  %v\n", anomaly)
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if !streamComplete || streamErr != nil || streamScan.Events != 5 || len(streamScan.Anomalies) != 0 {
+		fmt.Printf("FAIL: The stream should be checked to the end without anomalies, got %s (%v)\n", streamScan.Summary(), streamErr)
+		passed = false
+	}
+	if !allComplete || allErr != nil || allScan.Last[stream] != 4 {
+		fmt.Printf("FAIL: $all should be checked to the end including the stream, got %s (%v)\n", allScan.Summary(), allErr)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll gap detection tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,263 @@
+// KurrentDB Go Client Example - Per-event-type handler metrics
+// Demonstrates: Counting calls, errors and latency per event type to find the slow or failing handler
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === HANDLER METRICS ===
+
+// HandlerLatencyBuckets are the upper bounds of the latency histogram; a final bucket counts
+// anything slower than the last bound
+var HandlerLatencyBuckets = []time.Duration{
+	100 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+// HandlerStat is what one event type's handler has done so far
+type HandlerStat struct {
+	Count  int64
+	Errors int64
+	Total  time.Duration
+	Max    time.Duration
+	// Buckets[i] counts calls that took at most HandlerLatencyBuckets[i], and the last entry the
+	// calls slower than every bound
+	Buckets []int64
+}
+
+// Mean returns the average latency
+func (s HandlerStat) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// Quantile estimates the latency below which a fraction q of calls finished, as the upper bound of
+// the bucket holding that call, capped at Max
+func (s HandlerStat) Quantile(q float64) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	rank := int64(q*float64(s.Count) + 0.5)
+	var seen int64
+	for i, count := range s.Buckets {
+		seen += count
+		if seen >= max(rank, 1) && i < len(HandlerLatencyBuckets) {
+			return min(HandlerLatencyBuckets[i], s.Max)
+		}
+	}
+	return s.Max
+}
+
+// HandlerMetrics records handler calls per event type. Safe for concurrent use.
+type HandlerMetrics struct {
+	mu    sync.Mutex
+	stats map[string]*HandlerStat
+}
+
+func NewHandlerMetrics() *HandlerMetrics {
+	return &HandlerMetrics{stats: make(map[string]*HandlerStat)}
+}
+
+// Record counts one call for eventType that took elapsed and ended with err
+func (m *HandlerMetrics) Record(eventType string, elapsed time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stat := m.stats[eventType]
+	if stat == nil {
+		stat = &HandlerStat{Buckets: make([]int64, len(HandlerLatencyBuckets)+1)}
+		m.stats[eventType] = stat
+	}
+	stat.Count++
+	if err != nil {
+		stat.Errors++
+	}
+	stat.Total += elapsed
+	stat.Max = max(stat.Max, elapsed)
+	bucket, _ := slices.BinarySearch(HandlerLatencyBuckets, elapsed)
+	stat.Buckets[bucket]++
+}
+
+// HandlerStats returns a copy of the stats per event type, safe to read while recording continues
+func (m *HandlerMetrics) HandlerStats() map[string]HandlerStat {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make(map[string]HandlerStat, len(m.stats))
+	for eventType, stat := range m.stats {
+		snapshot := *stat
+		snapshot.Buckets = slices.Clone(stat.Buckets)
+		stats[eventType] = snapshot
+	}
+	return stats
+}
+
+// HandlerStats returns the stats of the projection's handlers per event type. Decode failures
+// and handler panics count as errors; unhandled event types are not recorded.
+func (p *Projection) HandlerStats() map[string]HandlerStat {
+	return p.metrics.HandlerStats()
+}
+
+// MetricsMiddleware wraps a subscription handler, recording each call in metrics under the event's
+// type. Unlike Projection.HandlerStats it sees every event the handler gets, including types the
+// handler ignores, and times whatever the handler does besides projecting.
+func MetricsMiddleware(metrics *HandlerMetrics, handler func(*kurrentdb.ResolvedEvent) error) func(*kurrentdb.ResolvedEvent) error {
+	return func(event *kurrentdb.ResolvedEvent) error {
+		started := time.Now()
+		err := handler(event)
+		metrics.Record(Resolve(event, false).EventType, time.Since(started), err)
+		return err
+	}
+}
+
+// printHandlerStats prints stats as a table, slowest mean first
+func printHandlerStats(stats map[string]HandlerStat) {
+	eventTypes := make([]string, 0, len(stats))
+	for eventType := range stats {
+		eventTypes = append(eventTypes, eventType)
+	}
+	slices.SortFunc(eventTypes, func(a, b string) int {
+		return int(stats[b].Mean() - stats[a].Mean())
+	})
+
+	fmt.Printf("  %-18s %6s %6s %10s %10s %10s\n", "event type", "count", "errors", "mean", "p95", "max")
+	for _, eventType := range eventTypes {
+		stat := stats[eventType]
+		fmt.Printf("  %-18s %6d %6d %10s %10s %10s\n", eventType, stat.Count, stat.Errors,
+			stat.Mean().Round(time.Microsecond), stat.Quantile(0.95).Round(time.Microsecond), stat.Max.Round(time.Microsecond))
+	}
+}
+
+// RunHandlerMetricsChecks runs an order projection with a slow ItemAdded handler and a failing
+// OrderShipped handler, and checks the stats point at both, no server required
+func RunHandlerMetricsChecks() {
+	checks := newChecker("handler metrics")
+
+	// ItemAdded looks up the price in a slow catalogue; OrderShipped panics without a shippedAt
+	projection := NewProjection("OrderSummary").
+		On("OrderCreated", func(state, data map[string]interface{}) map[string]interface{} {
+			return map[string]interface{}{"items": 0.0, "status": "created"}
+		}).
+		On("ItemAdded", func(state, data map[string]interface{}) map[string]interface{} {
+			time.Sleep(15 * time.Millisecond)
+			state["items"] = state["items"].(float64) + 1
+			return state
+		}).
+		On("OrderShipped", func(state, data map[string]interface{}) map[string]interface{} {
+			state["shippedAt"] = data["shippedAt"].(string)
+			state["status"] = "shipped"
+			return state
+		})
+
+	var events []*kurrentdb.RecordedEvent
+	for order := 0; order < 4; order++ {
+		stream := fmt.Sprintf("order-%d", order)
+		events = append(events,
+			syntheticEvent(stream, "OrderCreated", 0, 0, `{}`),
+			syntheticEvent(stream, "ItemAdded", 1, 0, `{"item":"Widget"}`),
+			syntheticEvent(stream, "ItemAdded", 2, 0, `{"item":"Gadget"}`),
+			syntheticEvent(stream, "GiftWrapRequested", 3, 0, `{}`))
+		if order%2 == 0 {
+			events = append(events, syntheticEvent(stream, "OrderShipped", 4, 0, `{"shippedAt":"2024-01-15T10:00:00Z"}`))
+		} else {
+			events = append(events, syntheticEvent(stream, "OrderShipped", 4, 0, `{}`))
+		}
+	}
+	events = append(events, syntheticEvent("order-9", "ItemAdded", 0, 0, `not json`))
+
+	// --- Projection stats ---
+	fmt.Println("\n--- Projection stats ---")
+	// The subscription's handler: middleware-timed, feeding the projection
+	middleware := NewHandlerMetrics()
+	handler := MetricsMiddleware(middleware, func(event *kurrentdb.ResolvedEvent) error {
+		recorded := Resolve(event, false)
+		_, err := projection.Apply(recorded, recorded.Position)
+		return err
+	})
+
+	// Read the stats while events are applied, as a metrics endpoint would
+	stop := make(chan struct{})
+	var reads sync.WaitGroup
+	reads.Add(1)
+	go func() {
+		defer reads.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				projection.HandlerStats()
+				middleware.HandlerStats()
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+	var failures int
+	for i, event := range events {
+		event.Position = kurrentdb.Position{Commit: uint64(i+1) * 100, Prepare: uint64(i+1) * 100}
+		if handler(&kurrentdb.ResolvedEvent{Event: event}) != nil {
+			failures++
+		}
+	}
+	close(stop)
+	reads.Wait()
+
+	stats := projection.HandlerStats()
+	printHandlerStats(stats)
+
+	created, added, shipped := stats["OrderCreated"], stats["ItemAdded"], stats["OrderShipped"]
+	checks.Check(created.Count == 4 && added.Count == 9 && shipped.Count == 4, "expected 4, 9 and 4 calls, got %d, %d and %d", created.Count, added.Count, shipped.Count)
+	_, giftWrap := stats["GiftWrapRequested"]
+	checks.Check(!giftWrap, "unhandled types should not be recorded by the projection")
+	checks.Check(shipped.Errors == 2 && added.Errors == 1 && created.Errors == 0,
+		"expected 2 panics in OrderShipped and 1 decode failure in ItemAdded, got %d and %d", shipped.Errors, added.Errors)
+	checks.Check(failures == 3, "3 events should have failed, got %d", failures)
+
+	// --- Finding the slow handler ---
+	fmt.Println("\n--- Finding the slow handler ---")
+	slowest := ""
+	for eventType, stat := range stats {
+		if slowest == "" || stat.Mean() > stats[slowest].Mean() {
+			slowest = eventType
+		}
+	}
+	fmt.Printf("  slowest: %s, p50 %s\n", slowest, added.Quantile(0.5).Round(time.Microsecond))
+	checks.Check(slowest == "ItemAdded", "ItemAdded should be the slowest handler, got %s", slowest)
+	checks.Check(added.Quantile(0.5) >= 10*time.Millisecond && added.Max >= 15*time.Millisecond,
+		"ItemAdded's median should be at least 10ms, got %s (max %s)", added.Quantile(0.5), added.Max)
+	checks.Check(created.Quantile(0.99) <= 5*time.Millisecond, "OrderCreated should be fast, got p99 %s", created.Quantile(0.99))
+	var bucketed int64
+	for _, count := range added.Buckets {
+		bucketed += count
+	}
+	checks.Check(bucketed == added.Count, "the histogram should hold every call, got %d of %d", bucketed, added.Count)
+
+	// --- Middleware stats ---
+	fmt.Println("\n--- Middleware stats ---")
+	wrapped := middleware.HandlerStats()
+	printHandlerStats(wrapped)
+	checks.Check(wrapped["GiftWrapRequested"].Count == 4, "the middleware should see unhandled types too, got %+v", wrapped["GiftWrapRequested"])
+	checks.Check(wrapped["OrderShipped"].Errors == 2 && wrapped["ItemAdded"].Count == 9, "the middleware should count the same calls and errors, got %+v", wrapped)
+	checks.Check(wrapped["ItemAdded"].Total >= added.Total, "middleware time should include the projection's, got %s < %s", wrapped["ItemAdded"].Total, added.Total)
+
+	var panicErr *HandlerPanicError
+	_, err := projection.Apply(syntheticEvent("order-1", "OrderShipped", 5, 9000, `{}`), kurrentdb.Position{Commit: 9000, Prepare: 9000})
+	checks.Check(errors.As(err, &panicErr) && projection.HandlerStats()["OrderShipped"].Errors == 3, "a later panic should be counted, got %v", err)
+
+	checks.Done()
+}
@@ -0,0 +1,664 @@
+// KurrentDB Go Client Example - Hot-reloading projection handlers
+// Demonstrates: Re-registering handlers in a running process and rebuilding only the streams their events touched, keyed by a handler hash saved with the snapshot
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === RELOADABLE PROJECTION ===
+
+// RebuildMode is how much of a projection Rebuild had to redo
+type RebuildMode int
+
+const (
+	// RebuildNone means the state already reflects the registered handlers
+	RebuildNone RebuildMode = iota
+	// RebuildTargeted re-read only the streams holding events whose handler changed
+	RebuildTargeted
+	// RebuildFull replayed $all from the start
+	RebuildFull
+)
+
+func (m RebuildMode) String() string {
+	switch m {
+	case RebuildNone:
+		return "none"
+	case RebuildTargeted:
+		return "targeted"
+	case RebuildFull:
+		return "full"
+	}
+	return fmt.Sprintf("RebuildMode(%d)", int(m))
+}
+
+// RebuildResult describes what Rebuild did
+type RebuildResult struct {
+	Mode RebuildMode
+	// Changed lists the event types whose handler was added, edited or removed
+	Changed []string
+	// Streams counts the streams re-read by a targeted rebuild, Events the events read either way
+	Streams int
+	Events  int
+	Elapsed time.Duration
+}
+
+// ReloadableProjection is a stream-keyed Projection whose handlers can be swapped while the process
+// runs, for development. Each handler is registered with a configuration value, its version label
+// and any parameters, and the projection remembers the configuration hashes its state was built
+// with. After handlers are re-registered, Rebuild re-reads just the streams that hold events of the
+// changed types, up to the checkpoint, instead of the whole log; CatchUp then applies what came
+// after. Save and Load carry the hashes with a snapshot, so a restarted process with edited
+// handlers also rebuilds only what changed.
+//
+// Limits of hot-reload:
+//   - Go can't replace compiled code: "editing a handler" means registering a different function
+//     in the same process, e.g. one built from a config file the developer edits, or from a test
+//   - the hash sees only the configuration passed to Handle, never the function's code. An edit
+//     that keeps the configuration is invisible, so bump the version label with every change
+//   - state must be keyed by stream, one stream per key, as with plain On handlers: KeyBy,
+//     cross-stream keys and reactions aren't supported, and side effects are never replayed
+//   - a stream is rebuilt from whatever reading it returns now; events truncated or scavenged since
+//     they were first applied are gone from its rebuilt state
+//   - the index of event types seen per stream grows with the log and is saved in the snapshot
+//   - changing the projection-wide configuration forces a full rebuild
+//
+// Not safe for concurrent use.
+type ReloadableProjection struct {
+	Projection *Projection
+
+	configHash string
+	// handlers holds the configuration hash of each registered handler, by event type
+	handlers map[string]string
+	// builtConfig and builtWith are the hashes the current state was built with
+	builtConfig string
+	builtWith   map[string]string
+	// seen records the event types each stream has had, handled or not
+	seen map[string]map[string]bool
+}
+
+// NewReloadableProjection starts an empty projection. config describes settings that affect every
+// handler; a change to it can't be targeted and forces a full rebuild.
+func NewReloadableProjection(name string, config interface{}) *ReloadableProjection {
+	hash := configurationHash(config)
+	return &ReloadableProjection{
+		Projection:  NewProjection(name),
+		configHash:  hash,
+		handlers:    make(map[string]string),
+		builtConfig: hash,
+		builtWith:   make(map[string]string),
+		seen:        make(map[string]map[string]bool),
+	}
+}
+
+// configurationHash is the content hash of a configuration value's JSON encoding. It panics on a
+// value that doesn't encode, like regexp.MustCompile on a bad pattern: it is a registration bug.
+func configurationHash(config interface{}) string {
+	raw, err := json.Marshal(config)
+	if err != nil {
+		panic(fmt.Sprintf("handler configuration %v: %v", config, err))
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Handle registers handler for eventType, replacing any earlier one, with config describing it:
+// a version label at least, and any parameters the handler is built from. Events applied from now
+// on use it; state built before needs Rebuild.
+func (r *ReloadableProjection) Handle(eventType string, config interface{}, handler EventHandler) *ReloadableProjection {
+	hash := configurationHash(config)
+	r.handlers[eventType] = hash
+	r.Projection.On(eventType, handler)
+	if r.empty() {
+		r.builtWith[eventType] = hash
+	}
+	return r
+}
+
+// Remove unregisters eventType's handler; Rebuild then drops what it contributed
+func (r *ReloadableProjection) Remove(eventType string) *ReloadableProjection {
+	delete(r.handlers, eventType)
+	delete(r.Projection.handlers, eventType)
+	if r.empty() {
+		delete(r.builtWith, eventType)
+	}
+	return r
+}
+
+// empty reports whether nothing has been applied yet, so there is no state to rebuild
+func (r *ReloadableProjection) empty() bool {
+	return r.Projection.Checkpoint == nil && len(r.seen) == 0
+}
+
+// HandlerHash is the content hash of the whole handler configuration: the projection's own and
+// every handler's, by event type
+func (r *ReloadableProjection) HandlerHash() string {
+	return combinedHandlerHash(r.configHash, r.handlers)
+}
+
+func combinedHandlerHash(configHash string, handlers map[string]string) string {
+	var lines []string
+	for _, eventType := range slices.Sorted(maps.Keys(handlers)) {
+		lines = append(lines, eventType+"="+handlers[eventType])
+	}
+	return configurationHash(append([]string{configHash}, lines...))
+}
+
+// Changed returns the event types whose handler differs from the one the state was built with
+func (r *ReloadableProjection) Changed() []string {
+	current := r.handlers
+	var changed []string
+	for _, eventType := range slices.Sorted(maps.Keys(current)) {
+		if r.builtWith[eventType] != current[eventType] {
+			changed = append(changed, eventType)
+		}
+	}
+	for _, eventType := range slices.Sorted(maps.Keys(r.builtWith)) {
+		if _, ok := current[eventType]; !ok {
+			changed = append(changed, eventType)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+// Apply applies event like Projection.Apply, recording its type against its stream
+func (r *ReloadableProjection) Apply(event *kurrentdb.RecordedEvent, position kurrentdb.Position) (bool, error) {
+	types := r.seen[event.StreamID]
+	if types == nil {
+		types = make(map[string]bool)
+		r.seen[event.StreamID] = types
+	}
+	types[event.EventType] = true
+
+	applied, err := r.Projection.Apply(event, position)
+	if !applied && err == nil {
+		r.Projection.Advance(position)
+	}
+	return applied, err
+}
+
+// Rebuild brings the state in line with the registered handlers. A changed projection
+// configuration replays $all from the start; changed handlers re-read, from the start of each
+// stream to the checkpoint, only the streams that have had events of those types. It doesn't move
+// the checkpoint: call CatchUp for what was appended since.
+func (r *ReloadableProjection) Rebuild(ctx context.Context, store EventStore) (RebuildResult, error) {
+	started := time.Now()
+	result := RebuildResult{Changed: r.Changed()}
+
+	switch {
+	case r.builtConfig != r.configHash:
+		result.Mode = RebuildFull
+		checkpoint := r.Projection.Checkpoint
+		r.reset()
+		if checkpoint != nil {
+			var err error
+			if result.Events, err = r.replayAll(ctx, store, nil, checkpoint); err != nil {
+				return result, err
+			}
+		}
+	case len(result.Changed) > 0:
+		result.Mode = RebuildTargeted
+		streams, events, err := r.rebuildStreams(ctx, store, result.Changed)
+		result.Streams, result.Events = streams, events
+		if err != nil {
+			return result, err
+		}
+	}
+
+	r.builtConfig = r.configHash
+	r.builtWith = maps.Clone(r.handlers)
+	result.Elapsed = time.Since(started)
+	return result, nil
+}
+
+// reset empties the state and the index, keeping the handlers
+func (r *ReloadableProjection) reset() {
+	r.Projection.Read(func(p *Projection) {
+		p.State = make(map[string]map[string]interface{})
+		p.Checkpoint = nil
+	})
+	r.seen = make(map[string]map[string]bool)
+}
+
+// rebuildStreams replaces the state of every stream that has had an event of one of types with the
+// state the current handlers build from its events up to the checkpoint
+func (r *ReloadableProjection) rebuildStreams(ctx context.Context, store EventStore, types []string) (int, int, error) {
+	checkpoint := r.Projection.Checkpoint
+	if checkpoint == nil {
+		return 0, 0, nil
+	}
+
+	streams, read := 0, 0
+	for _, stream := range slices.Sorted(maps.Keys(r.seen)) {
+		if !slices.ContainsFunc(types, func(eventType string) bool { return r.seen[stream][eventType] }) {
+			continue
+		}
+		events, _, err := readStreamFrom(ctx, store, stream, NoVersion)
+		if err != nil {
+			return streams, read, fmt.Errorf("rebuild %s: %w", stream, err)
+		}
+		streams++
+
+		// A scratch projection with the same handlers, so the checkpoint stays where it is
+		scratch := NewProjection(r.Projection.Name)
+		scratch.handlers = maps.Clone(r.Projection.handlers)
+		scratch.useNumber = r.Projection.useNumber
+		for _, event := range events {
+			if positionAfter(event.Position, *checkpoint) {
+				break
+			}
+			read++
+			if _, err := scratch.Apply(event, event.Position); err != nil {
+				fmt.Printf("  Skipped: %v\n", err)
+			}
+		}
+		r.Projection.Read(func(p *Projection) {
+			if state, ok := scratch.State[stream]; ok {
+				p.State[stream] = state
+			} else {
+				delete(p.State, stream)
+			}
+		})
+	}
+	return streams, read, nil
+}
+
+// CatchUp applies the events in $all after the checkpoint, up to the end, and returns how many
+func (r *ReloadableProjection) CatchUp(ctx context.Context, store EventStore) (int, error) {
+	return r.replayAll(ctx, store, r.Projection.Checkpoint, nil)
+}
+
+// replayAll applies $all after after, or from the start when it is nil, up to and including until,
+// or to the end when it is nil, a page at a time
+func (r *ReloadableProjection) replayAll(ctx context.Context, store EventStore, after, until *kurrentdb.Position) (int, error) {
+	var from kurrentdb.AllPosition = kurrentdb.Start{}
+	if after != nil {
+		from = *after
+	}
+	read := 0
+	for {
+		reader, err := store.ReadAll(ctx, kurrentdb.ReadAllOptions{From: from}, replayPageSize)
+		if err != nil {
+			return read, err
+		}
+		page := 0
+		for {
+			resolved, err := reader.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				reader.Close()
+				return read, err
+			}
+			page++
+			event := resolved.OriginalEvent()
+			if after != nil && !positionAfter(event.Position, *after) {
+				// Reads from a position include the event at it
+				continue
+			}
+			if until != nil && positionAfter(event.Position, *until) {
+				reader.Close()
+				return read, nil
+			}
+			read++
+			position := event.Position
+			after = &position
+			if _, err := r.Apply(event, position); err != nil {
+				fmt.Printf("  Skipped: %v\n", err)
+			}
+		}
+		reader.Close()
+		if page < replayPageSize || after == nil {
+			return read, nil
+		}
+		from = *after
+	}
+}
+
+// === SNAPSHOTS WITH HANDLER HASHES ===
+
+// ReloadSnapshot is a ReloadableProjection saved with the hashes its state was built with
+type ReloadSnapshot struct {
+	Name       string              `json:"name"`
+	Checkpoint *kurrentdb.Position `json:"checkpoint"`
+	State      json.RawMessage     `json:"state"`
+	// HandlerHash covers ConfigHash and Handlers, so a snapshot edited by hand or truncated is
+	// caught and rebuilt in full
+	HandlerHash string            `json:"handlerHash"`
+	ConfigHash  string            `json:"configHash"`
+	Handlers    map[string]string `json:"handlers"`
+	// Streams lists the event types each stream has had, for targeted rebuilds
+	Streams map[string][]string `json:"streams"`
+}
+
+// Save writes the state, checkpoint, hashes and index to path
+func (r *ReloadableProjection) Save(path string) error {
+	var snapshot ReloadSnapshot
+	var err error
+	r.Projection.Read(func(p *Projection) {
+		snapshot.Name, snapshot.Checkpoint = p.Name, p.Checkpoint
+		snapshot.State, err = json.Marshal(p.State)
+	})
+	if err != nil {
+		return err
+	}
+	snapshot.ConfigHash, snapshot.Handlers = r.builtConfig, r.builtWith
+	snapshot.HandlerHash = combinedHandlerHash(r.builtConfig, r.builtWith)
+	snapshot.Streams = make(map[string][]string, len(r.seen))
+	for stream, types := range r.seen {
+		snapshot.Streams[stream] = slices.Sorted(maps.Keys(types))
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load restores a snapshot saved by Save, reporting false if there is none. The restored state
+// keeps the hashes it was built with, so a Rebuild afterwards redoes only what the registered
+// handlers changed. A snapshot whose hashes don't add up restores nothing usable for targeting
+// and is marked for a full rebuild.
+func (r *ReloadableProjection) Load(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	var snapshot ReloadSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return false, fmt.Errorf("corrupt snapshot %s: %w", path, err)
+	}
+	if snapshot.Name != r.Projection.Name {
+		return false, fmt.Errorf("%w: %s is for %q, not %q", ErrSnapshotNameChange, path, snapshot.Name, r.Projection.Name)
+	}
+	state := make(map[string]map[string]interface{})
+	if err := json.Unmarshal(snapshot.State, &state); err != nil {
+		return false, fmt.Errorf("decode snapshot %s: %w", path, err)
+	}
+
+	r.Projection.Read(func(p *Projection) {
+		p.State, p.Checkpoint = state, snapshot.Checkpoint
+	})
+	r.builtConfig, r.builtWith = snapshot.ConfigHash, snapshot.Handlers
+	if r.builtWith == nil || snapshot.HandlerHash != combinedHandlerHash(snapshot.ConfigHash, snapshot.Handlers) {
+		r.builtConfig, r.builtWith = "", make(map[string]string)
+	}
+	r.seen = make(map[string]map[string]bool, len(snapshot.Streams))
+	for stream, types := range snapshot.Streams {
+		r.seen[stream] = make(map[string]bool, len(types))
+		for _, eventType := range types {
+			r.seen[stream][eventType] = true
+		}
+	}
+	return true, nil
+}
+
+// === ORDER SUMMARY HANDLERS ===
+
+// orderSummaryReloadable registers the order summary's handlers; shippedStatus is the status
+// OrderShipped sets, standing in for the edit a developer makes
+func orderSummaryReloadable(shippedStatus string) *ReloadableProjection {
+	summary := NewOrderSummaryProjection()
+	r := NewReloadableProjection("OrderSummary", map[string]interface{}{"stateVersion": 1})
+	for _, eventType := range []string{"OrderCreated", "ItemAdded", "OrderCompleted"} {
+		handler := summary.handlers[eventType]
+		r.Handle(eventType, "v1", func(state, data map[string]interface{}) map[string]interface{} {
+			next, _ := handler(state, data)
+			return next
+		})
+	}
+	return r.Handle("OrderShipped", map[string]string{"version": "v1", "status": shippedStatus},
+		func(state, data map[string]interface{}) map[string]interface{} {
+			state["status"] = shippedStatus
+			state["shippedAt"] = data["shippedAt"]
+			return state
+		})
+}
+
+// writeHotReloadOrders writes count orders under category, shipping every shipEvery-th and adding
+// a note to every noteEvery-th
+func writeHotReloadOrders(ctx context.Context, store EventStore, category string, count, shipEvery, noteEvery int) error {
+	event := func(eventType string, data interface{}) kurrentdb.EventData {
+		raw, _ := json.Marshal(data)
+		return kurrentdb.EventData{EventID: uuid.New(), EventType: eventType, ContentType: kurrentdb.ContentTypeJson, Data: raw}
+	}
+	for i := 0; i < count; i++ {
+		id := fmt.Sprintf("%d", i)
+		events := []kurrentdb.EventData{
+			event("OrderCreated", ProjectionOrderCreated{OrderID: id, CustomerID: "c-" + id, Amount: 10}),
+			event("ItemAdded", ProjectionItemAdded{Item: "widget", Price: 5}),
+			event("ItemAdded", ProjectionItemAdded{Item: "gadget", Price: 7}),
+		}
+		if i%shipEvery == 0 {
+			events = append(events, event("OrderShipped", ProjectionOrderShipped{ShippedAt: "2026-10-01"}))
+		}
+		if i%noteEvery == 0 {
+			events = append(events, event("OrderNoteAdded", map[string]string{"note": "gift wrap"}))
+		}
+		if _, err := store.AppendToStream(ctx, Streams.Name(category, id), kurrentdb.AppendToStreamOptions{}, events...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// === CHECKS ===
+
+// RunHotReloadChecks edits, adds and removes handlers over an in-memory log and compares every
+// targeted rebuild with a full replay under the same handlers
+func RunHotReloadChecks() {
+	checks := newChecker("hot reload")
+
+	ctx := context.Background()
+	store := NewMemoryEventStore()
+	if err := writeHotReloadOrders(ctx, store, "order", 40, 4, 10); err != nil {
+		panic(err)
+	}
+	stateOf := func(projection *Projection) map[string]any {
+		result, err := projection.Result()
+		checks.Check(err == nil, "the result of %s should encode: %v", projection.Name, err)
+		return result
+	}
+	// replayed builds r's handlers from nothing, for comparison
+	replayed := func(configure func(*ReloadableProjection)) map[string]any {
+		fresh := orderSummaryReloadable("shipped")
+		configure(fresh)
+		fresh.CatchUp(ctx, store)
+		return stateOf(fresh.Projection)
+	}
+
+	fmt.Println("\n--- Initial build ---")
+	live := orderSummaryReloadable("shipped")
+	read, err := live.CatchUp(ctx, store)
+	checks.Check(err == nil && read == 134 && len(live.Projection.State) == 40, "the first catch-up should read all 134 events into 40 orders, got %d (%v)", read, err)
+	result, _ := live.Rebuild(ctx, store)
+	checks.Check(result.Mode == RebuildNone && result.Events == 0, "unchanged handlers shouldn't rebuild, got %+v", result)
+	dir, _ := os.MkdirTemp("", "hot-reload-")
+	defer os.RemoveAll(dir)
+	snapshotPath := filepath.Join(dir, "order-summary.json")
+	checks.Check(live.Save(snapshotPath) == nil, "the snapshot should save")
+
+	fmt.Println("\n--- Editing a handler ---")
+	edited := func(r *ReloadableProjection) {
+		r.Handle("OrderShipped", map[string]string{"version": "v2", "status": "dispatched"},
+			func(state, data map[string]interface{}) map[string]interface{} {
+				state["status"] = "dispatched"
+				state["shippedOn"] = data["shippedAt"]
+				return state
+			})
+	}
+	before := live.HandlerHash()
+	edited(live)
+	checks.Check(live.HandlerHash() != before, "re-registering with a new configuration should change the hash")
+	checks.Check(slices.Equal(live.Changed(), []string{"OrderShipped"}), "only OrderShipped should have changed, got %v", live.Changed())
+	result, err = live.Rebuild(ctx, store)
+	fmt.Printf("  %s: %v, %d streams, %d events\n", result.Mode, result.Changed, result.Streams, result.Events)
+	checks.Check(err == nil && result.Mode == RebuildTargeted && result.Streams == 10 && result.Events == 10*4+2,
+		"editing OrderShipped should re-read only the 10 shipped orders, got %+v (%v)", result, err)
+	checks.Check(reflect.DeepEqual(stateOf(live.Projection), replayed(edited)), "the targeted rebuild should match a full replay")
+	checks.Check(live.Projection.Get("order-0")["status"] == "dispatched" && live.Projection.Get("order-1")["status"] == "created",
+		"shipped orders should be dispatched, others untouched, got %v / %v", live.Projection.Get("order-0"), live.Projection.Get("order-1"))
+
+	fmt.Println("\n--- Adding and removing handlers ---")
+	noted := func(r *ReloadableProjection) {
+		edited(r)
+		r.Handle("OrderNoteAdded", "v1", func(state, data map[string]interface{}) map[string]interface{} {
+			state["note"] = data["note"]
+			return state
+		})
+	}
+	noted(live)
+	result, _ = live.Rebuild(ctx, store)
+	checks.Check(result.Mode == RebuildTargeted && slices.Equal(result.Changed, []string{"OrderNoteAdded"}) && result.Streams == 4,
+		"a handler for a type seen but unhandled should rebuild the 4 streams with notes, got %+v", result)
+	checks.Check(reflect.DeepEqual(stateOf(live.Projection), replayed(noted)), "adding a handler should match a full replay")
+
+	unshipped := func(r *ReloadableProjection) {
+		noted(r)
+		r.Remove("OrderShipped")
+	}
+	live.Remove("OrderShipped")
+	result, _ = live.Rebuild(ctx, store)
+	checks.Check(result.Mode == RebuildTargeted && result.Streams == 10, "removing a handler should rebuild the streams it touched, got %+v", result)
+	checks.Check(reflect.DeepEqual(stateOf(live.Projection), replayed(unshipped)), "removing a handler should match a full replay")
+
+	fmt.Println("\n--- Restarting from the snapshot ---")
+	writeHotReloadOrders(ctx, store, "late", 2, 1, 100)
+	restarted := orderSummaryReloadable("shipped")
+	edited(restarted)
+	loaded, err := restarted.Load(snapshotPath)
+	checks.Check(loaded && err == nil, "the snapshot should load: %v", err)
+	result, _ = restarted.Rebuild(ctx, store)
+	checks.Check(result.Mode == RebuildTargeted && result.Streams == 10, "a restart with an edited handler should rebuild only its streams, got %+v", result)
+	caughtUp, _ := restarted.CatchUp(ctx, store)
+	checks.Check(caughtUp == 9, "catching up should apply only the 9 events after the snapshot, got %d", caughtUp)
+	checks.Check(reflect.DeepEqual(stateOf(restarted.Projection), replayed(edited)), "the restarted projection should match a full replay")
+
+	unchanged := orderSummaryReloadable("shipped")
+	unchanged.Load(snapshotPath)
+	result, _ = unchanged.Rebuild(ctx, store)
+	checks.Check(result.Mode == RebuildNone, "a snapshot built by the same handlers should be used as is, got %+v", result)
+
+	fmt.Println("\n--- Forcing a full rebuild ---")
+	reconfigured := orderSummaryReloadable("shipped")
+	reconfigured.configHash = configurationHash(map[string]interface{}{"stateVersion": 2})
+	reconfigured.Load(snapshotPath)
+	result, _ = reconfigured.Rebuild(ctx, store)
+	checks.Check(result.Mode == RebuildFull && result.Events == 134, "a new projection configuration should replay to the checkpoint, got %+v", result)
+
+	raw, _ := os.ReadFile(snapshotPath)
+	var tampered ReloadSnapshot
+	json.Unmarshal(raw, &tampered)
+	tampered.Handlers["OrderShipped"] = "0000"
+	raw, _ = json.Marshal(tampered)
+	os.WriteFile(snapshotPath, raw, 0o644)
+	mismatched := orderSummaryReloadable("shipped")
+	mismatched.Load(snapshotPath)
+	result, _ = mismatched.Rebuild(ctx, store)
+	checks.Check(result.Mode == RebuildFull, "a snapshot whose hash doesn't match its handlers should force a full rebuild, got %+v", result)
+	checks.Check(mismatched.Projection.Get("order-0")["status"] == "shipped", "the full rebuild should use the registered handlers, got %v", mismatched.Projection.Get("order-0"))
+
+	checks.Done()
+}
+
+// === DEMO ===
+
+// RunHotReload builds an order summary, edits the OrderShipped handler and compares the targeted
+// rebuild with a full one
+func RunHotReload() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	store := NewClientStore(client)
+	category := "hotreload" + strings.ReplaceAll(uuid.New().String()[:8], "-", "")
+	writeErr := writeHotReloadOrders(ctx, store, category, 500, 25, 1000)
+
+	live := orderSummaryReloadable("shipped")
+	started := time.Now()
+	initial, buildErr := live.CatchUp(ctx, store)
+	fmt.Printf("Initial build: %d events in %s\n", initial, time.Since(started).Round(time.Millisecond))
+
+	// The developer edits the OrderShipped handler
+	edit := func(r *ReloadableProjection) {
+		r.Handle("OrderShipped", map[string]string{"version": "v2", "status": "dispatched"},
+			func(state, data map[string]interface{}) map[string]interface{} {
+				state["status"] = "dispatched"
+				state["shippedOn"] = data["shippedAt"]
+				return state
+			})
+	}
+	edit(live)
+	targeted, targetedErr := live.Rebuild(ctx, store)
+	fmt.Printf("Targeted rebuild: %v, %d streams, %d events in %s\n", targeted.Changed, targeted.Streams, targeted.Events, targeted.Elapsed.Round(time.Millisecond))
+
+	full := orderSummaryReloadable("shipped")
+	edit(full)
+	started = time.Now()
+	fullEvents, fullErr := full.CatchUp(ctx, store)
+	fullElapsed := time.Since(started)
+	fmt.Printf("Full rebuild: %d events in %s\n", fullEvents, fullElapsed.Round(time.Millisecond))
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+	if writeErr != nil || buildErr != nil || targetedErr != nil || fullErr != nil {
+		fmt.Printf("FAIL: Writing and rebuilding should succeed, got %v / %v / %v / %v\n", writeErr, buildErr, targetedErr, fullErr)
+		passed = false
+	}
+	if targeted.Mode != RebuildTargeted || targeted.Streams != 20 {
+		fmt.Printf("FAIL: Editing OrderShipped should rebuild the 20 shipped orders, got %+v\n", targeted)
+		passed = false
+	}
+	if targeted.Events >= fullEvents {
+		fmt.Printf("FAIL: The targeted rebuild should read fewer events than the full one, got %d vs %d\n", targeted.Events, fullEvents)
+		passed = false
+	}
+	for i := 0; i < 500; i++ {
+		stream := Streams.Name(category, fmt.Sprintf("%d", i))
+		if got, want := live.Projection.Get(stream), full.Projection.Get(stream); !reflect.DeepEqual(got, want) {
+			fmt.Printf("FAIL: %s should match the full rebuild, got %v want %v\n", stream, got, want)
+			passed = false
+			break
+		}
+	}
+
+	if passed {
+		fmt.Println("\nAll hot reload tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
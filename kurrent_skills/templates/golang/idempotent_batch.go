@@ -0,0 +1,366 @@
+// KurrentDB Go Client Example - Idempotent multi-event batch appends
+// Demonstrates: Deterministic event ids per batch and probing the stream before retrying an ambiguous append
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === IDEMPOTENT BATCH WRITER ===
+
+// BatchEventID is the id of the index-th event of a batch. The same batch id always yields the same
+// event ids, so a retry - even from another process - is recognisable in the stream.
+func BatchEventID(batchID uuid.UUID, index int) uuid.UUID {
+	return uuid.NewSHA1(batchID, []byte(fmt.Sprintf("batch-event-%d", index)))
+}
+
+// BatchWriteResult describes how a batch ended up in the stream
+type BatchWriteResult struct {
+	// NextExpectedVersion is the revision of the batch's last event
+	NextExpectedVersion uint64
+	// Attempts counts the append calls made, including retries
+	Attempts int
+	// AlreadyWritten counts events the probe found in the stream instead of appending them again
+	AlreadyWritten int
+}
+
+// IdempotentBatchWriter appends a batch so that a retry after an ambiguous failure (a timeout or a
+// dropped connection, where the append may or may not have landed) never duplicates events.
+//
+// The server deduplicates retried event ids only for an identical append at the same expected
+// revision, and only while the ids are in its cache. Instead the writer reads the stream tail
+// before every retry and appends just the events that aren't there. Batches larger than
+// MaxChunkSize are written as several appends, so a failure can leave a prefix of the batch written;
+// the probe picks up after that prefix.
+type IdempotentBatchWriter struct {
+	client *kurrentdb.Client
+
+	// MaxAttempts bounds the appends tried for one chunk
+	MaxAttempts    int
+	AttemptTimeout time.Duration
+	Backoff        Backoff
+	// MaxChunkSize bounds the events per append; 0 writes the whole batch in one atomic append
+	MaxChunkSize int
+	// ProbeDepth is how many events from the end of the stream the probe reads; it must cover
+	// anything other writers may append between a failure and the retry
+	ProbeDepth uint64
+
+	// append is swappable to simulate ambiguous failures
+	append func(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error)
+}
+
+func NewIdempotentBatchWriter(client *kurrentdb.Client) *IdempotentBatchWriter {
+	return &IdempotentBatchWriter{
+		client:         client,
+		MaxAttempts:    3,
+		AttemptTimeout: 5 * time.Second,
+		Backoff:        NewBackoff(200*time.Millisecond, 5*time.Second),
+		ProbeDepth:     1000,
+		append:         client.AppendToStream,
+	}
+}
+
+// Write appends events as the batch batchID, assigning each its BatchEventID. state is checked for
+// the first append only; once part of the batch is written, later appends expect the revision of
+// the batch's own last written event.
+func (w *IdempotentBatchWriter) Write(ctx context.Context, stream string, batchID uuid.UUID, state kurrentdb.StreamState, events ...kurrentdb.EventData) (BatchWriteResult, error) {
+	batch := make([]kurrentdb.EventData, len(events))
+	for i, event := range events {
+		event.EventID = BatchEventID(batchID, i)
+		batch[i] = event
+	}
+
+	var result BatchWriteResult
+	failures := 0
+	backoff := w.Backoff
+	// A previous process may have written some or all of the batch already
+	written, revision, err := w.probe(ctx, stream, batch)
+	if err != nil {
+		return result, err
+	}
+	result.AlreadyWritten = written
+
+	for written < len(batch) {
+		if written > 0 {
+			state = kurrentdb.Revision(revision)
+		}
+		chunk := batch[written:]
+		if w.MaxChunkSize > 0 && len(chunk) > w.MaxChunkSize {
+			chunk = chunk[:w.MaxChunkSize]
+		}
+
+		result.Attempts++
+		appended, err := w.appendOnce(ctx, stream, kurrentdb.AppendToStreamOptions{StreamState: state}, chunk...)
+		if err == nil {
+			written += len(chunk)
+			revision = appended.NextExpectedVersion
+			failures = 0
+			backoff.Reset()
+			continue
+		}
+		failures++
+		if !isAmbiguousAppendError(ctx, err) || failures >= w.MaxAttempts {
+			return result, fmt.Errorf("append batch %s to %s (%d/%d events written): %w", batchID, stream, written, len(batch), err)
+		}
+
+		if err := backoff.Wait(ctx); err != nil {
+			return result, err
+		}
+
+		// The append may have landed: find out how much of the batch is in the stream now
+		probed, probedRevision, probeErr := w.probe(ctx, stream, batch)
+		if probeErr != nil {
+			return result, fmt.Errorf("probe %s after %v: %w", stream, err, probeErr)
+		}
+		result.AlreadyWritten += probed - written
+		written, revision = probed, probedRevision
+	}
+
+	result.NextExpectedVersion = revision
+	return result, nil
+}
+
+func (w *IdempotentBatchWriter) appendOnce(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, w.AttemptTimeout)
+	defer cancel()
+	return w.append(attemptCtx, stream, options, events...)
+}
+
+// probe returns how many events of batch are at the end of the stream and the revision of the last
+// of them. The written events must be a prefix of the batch; anything else means another writer used
+// the same batch id and is reported as an error rather than papered over.
+func (w *IdempotentBatchWriter) probe(ctx context.Context, stream string, batch []kurrentdb.EventData) (int, uint64, error) {
+	index := make(map[uuid.UUID]int, len(batch))
+	for i, event := range batch {
+		index[event.EventID] = i
+	}
+
+	events, err := w.client.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{
+		Direction: kurrentdb.Backwards,
+		From:      kurrentdb.End{},
+	}, w.ProbeDepth)
+	if err != nil {
+		if isStreamNotFound(err) {
+			return 0, 0, nil
+		}
+		return 0, 0, err
+	}
+	defer events.Close()
+
+	found := make(map[int]uint64)
+	for {
+		event, err := events.Recv()
+		if errors.Is(err, io.EOF) || isStreamNotFound(err) {
+			break
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		recorded := Resolve(event, false)
+		if i, ok := index[recorded.EventID]; ok {
+			found[i] = recorded.EventNumber
+		}
+	}
+
+	written := 0
+	for ; written < len(batch); written++ {
+		if _, ok := found[written]; !ok {
+			break
+		}
+	}
+	if len(found) != written {
+		return 0, 0, fmt.Errorf("stream %s holds %d of the %d batch events, but not as a prefix of the batch", stream, len(found), len(batch))
+	}
+	if written == 0 {
+		return 0, 0, nil
+	}
+	return written, found[written-1], nil
+}
+
+// isAmbiguousAppendError reports whether an append may have been applied despite failing. Errors
+// the server answered with (wrong expected version, access denied, deleted stream) are definitive.
+func isAmbiguousAppendError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		// The caller gave up, not the attempt
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	esErr, ok := kurrentdb.FromError(err)
+	if ok {
+		return false
+	}
+	switch esErr.Code() {
+	case kurrentdb.ErrorCodeDeadlineExceeded, kurrentdb.ErrorCodeConnectionClosed:
+		return true
+	}
+	return false
+}
+
+// RunIdempotentBatch simulates ambiguous timeouts around batch appends and checks nothing is duplicated
+func RunIdempotentBatch() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	newBatch := func(count int) []kurrentdb.EventData {
+		events := make([]kurrentdb.EventData, count)
+		for i := range events {
+			events[i] = kurrentdb.EventData{
+				EventType:   "ItemAdded",
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        []byte(fmt.Sprintf(`{"line":%d}`, i)),
+			}
+		}
+		return events
+	}
+	// streamIDs lists the event ids of a stream in order
+	streamIDs := func(stream string) []uuid.UUID {
+		events, err := client.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{From: kurrentdb.Start{}}, 1000)
+		if err != nil {
+			panic(err)
+		}
+		defer events.Close()
+		var ids []uuid.UUID
+		for {
+			event, err := events.Recv()
+			if err != nil {
+				return ids
+			}
+			ids = append(ids, Resolve(event, false).EventID)
+		}
+	}
+	batchIDs := func(batchID uuid.UUID, count int) []uuid.UUID {
+		ids := make([]uuid.UUID, count)
+		for i := range ids {
+			ids[i] = BatchEventID(batchID, i)
+		}
+		return ids
+	}
+	timeout := fmt.Errorf("simulated timeout: %w", context.DeadlineExceeded)
+
+	// === TIMEOUT AFTER THE BATCH LANDED ===
+	fmt.Println("\n=== Timeout after the append landed ===")
+	landedStream := Streams.Name("order", uuid.New().String())
+	landedBatch := uuid.New()
+	landed := NewIdempotentBatchWriter(client)
+	failed := false
+	landed.append = func(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+		result, err := client.AppendToStream(ctx, stream, options, events...)
+		if err == nil && !failed {
+			// The server applied the append but the response never arrived
+			failed = true
+			return nil, timeout
+		}
+		return result, err
+	}
+	landedResult, err := landed.Write(ctx, landedStream, landedBatch, kurrentdb.NoStream{}, newBatch(3)...)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("attempts=%d already written=%d, stream holds %d events\n", landedResult.Attempts, landedResult.AlreadyWritten, len(streamIDs(landedStream)))
+
+	// === TIMEOUT BEFORE THE BATCH LANDED ===
+	fmt.Println("\n=== Timeout before the append landed ===")
+	lostStream := Streams.Name("order", uuid.New().String())
+	lostBatch := uuid.New()
+	lost := NewIdempotentBatchWriter(client)
+	dropped := false
+	lost.append = func(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+		if !dropped {
+			// The request never reached the server
+			dropped = true
+			return nil, timeout
+		}
+		return client.AppendToStream(ctx, stream, options, events...)
+	}
+	lostResult, err := lost.Write(ctx, lostStream, lostBatch, kurrentdb.NoStream{}, newBatch(3)...)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("attempts=%d already written=%d, stream holds %d events\n", lostResult.Attempts, lostResult.AlreadyWritten, len(streamIDs(lostStream)))
+
+	// === PARTIAL APPEND ===
+	fmt.Println("\n=== Chunked batch failing after a partial append ===")
+	partialStream := Streams.Name("order", uuid.New().String())
+	partialBatch := uuid.New()
+	partial := NewIdempotentBatchWriter(client)
+	partial.MaxChunkSize = 2
+	chunks := 0
+	partial.append = func(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+		chunks++
+		result, err := client.AppendToStream(ctx, stream, options, events...)
+		if chunks == 2 && err == nil {
+			// Chunks 1 and 2 are in; the caller only hears a timeout
+			return nil, timeout
+		}
+		return result, err
+	}
+	partialResult, err := partial.Write(ctx, partialStream, partialBatch, kurrentdb.NoStream{}, newBatch(5)...)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("attempts=%d already written=%d, stream holds %d events\n", partialResult.Attempts, partialResult.AlreadyWritten, len(streamIDs(partialStream)))
+
+	// === PROCESS RESTART ===
+	// A new process retrying the whole batch finds it written and appends nothing
+	fmt.Println("\n=== Whole batch retried after a restart ===")
+	restartResult, err := NewIdempotentBatchWriter(client).Write(ctx, partialStream, partialBatch, kurrentdb.NoStream{}, newBatch(5)...)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("attempts=%d already written=%d, stream holds %d events\n", restartResult.Attempts, restartResult.AlreadyWritten, len(streamIDs(partialStream)))
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	expectStream := func(name, stream string, want []uuid.UUID) {
+		got := streamIDs(stream)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			fmt.Printf("FAIL: %s: expected the batch exactly once in order\n  got  %v\n  want %v\n", name, got, want)
+			passed = false
+		}
+	}
+	expectStream("landed", landedStream, batchIDs(landedBatch, 3))
+	expectStream("lost", lostStream, batchIDs(lostBatch, 3))
+	expectStream("partial", partialStream, batchIDs(partialBatch, 5))
+
+	if landedResult.Attempts != 1 || landedResult.AlreadyWritten != 3 {
+		fmt.Printf("FAIL: A landed batch should not be appended again, got %+v\n", landedResult)
+		passed = false
+	}
+	if lostResult.Attempts != 2 || lostResult.AlreadyWritten != 0 {
+		fmt.Printf("FAIL: A lost batch should be appended again, got %+v\n", lostResult)
+		passed = false
+	}
+	if partialResult.AlreadyWritten != 2 || partialResult.NextExpectedVersion != 4 {
+		fmt.Printf("FAIL: Only the unwritten chunk should be retried, got %+v\n", partialResult)
+		passed = false
+	}
+	if restartResult.Attempts != 0 || restartResult.AlreadyWritten != 5 || restartResult.NextExpectedVersion != 4 {
+		fmt.Printf("FAIL: A restarted writer should find the whole batch written, got %+v\n", restartResult)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll idempotent batch tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,412 @@
+// KurrentDB Go Client Example - Partial updates from patch events
+// Demonstrates: Applying JSON merge patches (RFC 7386) and JSON Patches (RFC 6902) from events to projection state
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// === MERGE PATCH (RFC 7386) ===
+
+// ErrInvalidPatch wraps every reason a JSON Patch can't be applied
+var ErrInvalidPatch = errors.New("invalid JSON patch")
+
+// MergePatch merges patch into target in place and returns it: objects merge recursively, null
+// removes the key, and anything else (arrays included) replaces the value. A merge patch can't
+// fail; it also can't set a value to null or change one array element.
+func MergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	if target == nil {
+		target = make(map[string]interface{})
+	}
+	for key, value := range patch {
+		switch v := value.(type) {
+		case nil:
+			delete(target, key)
+		case map[string]interface{}:
+			existing, _ := target[key].(map[string]interface{})
+			target[key] = MergePatch(existing, v)
+		default:
+			target[key] = v
+		}
+	}
+	return target
+}
+
+// OnMergePatch registers eventType as a merge patch of the stream's state: its whole payload,
+// e.g. {"status":"shipped","notes":null}, is merged in
+func (p *Projection) OnMergePatch(eventType string) *Projection {
+	return p.On(eventType, func(state, data map[string]interface{}) map[string]interface{} {
+		return MergePatch(state, data)
+	})
+}
+
+// === JSON PATCH (RFC 6902) ===
+
+// ApplyJSONPatch applies operations (add, remove, replace, move, copy, test) to a copy of state.
+// The patch is atomic: on any error, including a failed test, state is returned untouched with an
+// error wrapping ErrInvalidPatch.
+func ApplyJSONPatch(state map[string]interface{}, operations []interface{}) (map[string]interface{}, error) {
+	var doc interface{} = deepCopyJSON(state)
+	if state == nil {
+		doc = make(map[string]interface{})
+	}
+
+	for i, raw := range operations {
+		var err error
+		if doc, err = applyPatchOperation(doc, raw); err != nil {
+			return state, fmt.Errorf("%w: operation %d: %v", ErrInvalidPatch, i, err)
+		}
+	}
+
+	patched, ok := doc.(map[string]interface{})
+	if !ok {
+		return state, fmt.Errorf("%w: result is a %T, not an object", ErrInvalidPatch, doc)
+	}
+	return patched, nil
+}
+
+// OnJSONPatch registers eventType as a JSON Patch carried in the payload's field, e.g.
+// {"patch":[{"op":"replace","path":"/status","value":"shipped"}]}. An invalid patch fails the event:
+// Apply returns an error matching ErrInvalidPatch and the state is unchanged.
+func (p *Projection) OnJSONPatch(eventType, field string) *Projection {
+	return p.On(eventType, func(state, data map[string]interface{}) map[string]interface{} {
+		operations, ok := data[field].([]interface{})
+		if !ok {
+			panic(fmt.Errorf("%w: %q should be an array of operations, got %T", ErrInvalidPatch, field, data[field]))
+		}
+		patched, err := ApplyJSONPatch(state, operations)
+		if err != nil {
+			panic(err)
+		}
+		return patched
+	})
+}
+
+func applyPatchOperation(doc interface{}, raw interface{}) (interface{}, error) {
+	operation, ok := raw.(map[string]interface{})
+	if !ok {
+		return doc, fmt.Errorf("not an object: %v", raw)
+	}
+	op, _ := operation["op"].(string)
+	path, err := parsePointer(operation["path"])
+	if err != nil {
+		return doc, fmt.Errorf("path: %v", err)
+	}
+	value, hasValue := operation["value"]
+
+	switch op {
+	case "add", "replace", "test":
+		if !hasValue {
+			return doc, fmt.Errorf("%s without a value", op)
+		}
+	case "move", "copy":
+		from, err := parsePointer(operation["from"])
+		if err != nil {
+			return doc, fmt.Errorf("from: %v", err)
+		}
+		if op == "move" && len(path) > len(from) && reflect.DeepEqual(path[:len(from)], from) {
+			return doc, errors.New("can't move a value into itself")
+		}
+		if value, err = pointerGet(doc, from); err != nil {
+			return doc, err
+		}
+		if op == "move" {
+			if doc, err = pointerRemove(doc, from); err != nil {
+				return doc, err
+			}
+		}
+	}
+
+	switch op {
+	case "add", "move", "copy":
+		return pointerAdd(doc, path, deepCopyJSON(value))
+	case "remove":
+		return pointerRemove(doc, path)
+	case "replace":
+		if _, err := pointerGet(doc, path); err != nil {
+			return doc, err
+		}
+		if len(path) == 0 {
+			return deepCopyJSON(value), nil
+		}
+		if doc, err = pointerRemove(doc, path); err != nil {
+			return doc, err
+		}
+		return pointerAdd(doc, path, deepCopyJSON(value))
+	case "test":
+		current, err := pointerGet(doc, path)
+		if err != nil {
+			return doc, err
+		}
+		if !jsonEqual(current, value) {
+			return doc, fmt.Errorf("test failed at /%s: have %v, want %v", strings.Join(path, "/"), current, value)
+		}
+		return doc, nil
+	}
+	return doc, fmt.Errorf("unknown op %q", op)
+}
+
+// parsePointer splits an RFC 6901 JSON pointer into unescaped tokens; "" is the whole document
+func parsePointer(raw interface{}) ([]string, error) {
+	pointer, ok := raw.(string)
+	if !ok {
+		return nil, fmt.Errorf("missing or not a string: %v", raw)
+	}
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%q doesn't start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses an array index token; "-" (past the end) is allowed only when appending
+func arrayIndex(token string, length int, appending bool) (int, error) {
+	if token == "-" && appending {
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("bad array index %q", token)
+	}
+	limit := length - 1
+	if appending {
+		limit = length
+	}
+	if index > limit {
+		return 0, fmt.Errorf("array index %d out of range", index)
+	}
+	return index, nil
+}
+
+func pointerGet(node interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("no member %q", token)
+			}
+			node = child
+		case []interface{}:
+			index, err := arrayIndex(token, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			node = n[index]
+		default:
+			return nil, fmt.Errorf("can't descend into %T at %q", node, token)
+		}
+	}
+	return node, nil
+}
+
+// pointerAdd sets or inserts value at path and returns the updated node; slices grow, so parents
+// store the returned child
+func pointerAdd(node interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	token, rest := path[0], path[1:]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if len(rest) == 0 {
+			n[token] = value
+			return n, nil
+		}
+		child, ok := n[token]
+		if !ok {
+			return node, fmt.Errorf("no member %q", token)
+		}
+		updated, err := pointerAdd(child, rest, value)
+		if err != nil {
+			return node, err
+		}
+		n[token] = updated
+		return n, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(n), len(rest) == 0)
+		if err != nil {
+			return node, err
+		}
+		if len(rest) == 0 {
+			return append(n[:index], append([]interface{}{value}, n[index:]...)...), nil
+		}
+		updated, err := pointerAdd(n[index], rest, value)
+		if err != nil {
+			return node, err
+		}
+		n[index] = updated
+		return n, nil
+	}
+	return node, fmt.Errorf("can't descend into %T at %q", node, token)
+}
+
+func pointerRemove(node interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return node, errors.New("can't remove the whole document")
+	}
+	token, rest := path[0], path[1:]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[token]
+		if !ok {
+			return node, fmt.Errorf("no member %q", token)
+		}
+		if len(rest) == 0 {
+			delete(n, token)
+			return n, nil
+		}
+		updated, err := pointerRemove(child, rest)
+		if err != nil {
+			return node, err
+		}
+		n[token] = updated
+		return n, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(n), false)
+		if err != nil {
+			return node, err
+		}
+		if len(rest) == 0 {
+			return append(n[:index:index], n[index+1:]...), nil
+		}
+		updated, err := pointerRemove(n[index], rest)
+		if err != nil {
+			return node, err
+		}
+		n[index] = updated
+		return n, nil
+	}
+	return node, fmt.Errorf("can't descend into %T at %q", node, token)
+}
+
+// deepCopyJSON copies the maps and slices of a decoded JSON value
+func deepCopyJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			copied[key] = deepCopyJSON(child)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, child := range v {
+			copied[i] = deepCopyJSON(child)
+		}
+		return copied
+	}
+	return value
+}
+
+// jsonEqual compares two values as JSON, so 1, 1.0 and json.Number("1") are equal
+func jsonEqual(a, b interface{}) bool {
+	normalize := func(value interface{}) (interface{}, bool) {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, false
+		}
+		var normalized interface{}
+		return normalized, json.Unmarshal(raw, &normalized) == nil
+	}
+	na, okA := normalize(a)
+	nb, okB := normalize(b)
+	return okA && okB && reflect.DeepEqual(na, nb)
+}
+
+// RunJSONPatchChecks projects documents from merge patch and JSON Patch events, no server required
+func RunJSONPatchChecks() {
+	checks := newChecker("JSON patch")
+
+	asJSON := func(value interface{}) string {
+		raw, _ := json.Marshal(value)
+		return string(raw)
+	}
+
+	projection := NewProjection("Documents").
+		On("DocumentCreated", func(state, data map[string]interface{}) map[string]interface{} {
+			return data
+		}).
+		OnMergePatch("DocumentUpdated").
+		OnJSONPatch("FieldsChanged", "patch")
+
+	var commit uint64
+	apply := func(eventType, data string) error {
+		commit += 100
+		event := syntheticEvent("order-1", eventType, commit/100-1, commit, data)
+		_, err := projection.Apply(event, event.Position)
+		fmt.Printf("  %-15s %s\n  %15s -> %s\n", eventType, data, "", asJSON(projection.Get("order-1")))
+		return err
+	}
+
+	// --- Merge patch ---
+	fmt.Println("\n--- DocumentUpdated (merge patch) ---")
+	checks.Check(apply("DocumentCreated", `{"status":"created","amount":100,"notes":"leave at door","shipping":{"carrier":"ups","speed":"ground"},"items":["Widget"]}`) == nil, "create failed")
+	checks.Check(apply("DocumentUpdated", `{"status":"shipped"}`) == nil, "merge failed")
+	checks.Check(asJSON(projection.Get("order-1")) == `{"amount":100,"items":["Widget"],"notes":"leave at door","shipping":{"carrier":"ups","speed":"ground"},"status":"shipped"}`,
+		"a one-field merge should only change that field, got %s", asJSON(projection.Get("order-1")))
+
+	checks.Check(apply("DocumentUpdated", `{"notes":null,"shipping":{"speed":"express","tracking":"1Z999"},"items":["Widget","Gadget"]}`) == nil, "merge failed")
+	state := projection.Get("order-1")
+	_, hasNotes := state["notes"]
+	checks.Check(!hasNotes, "null should remove notes, got %v", state["notes"])
+	checks.Check(asJSON(state["shipping"]) == `{"carrier":"ups","speed":"express","tracking":"1Z999"}`, "nested objects should merge, got %s", asJSON(state["shipping"]))
+	checks.Check(asJSON(state["items"]) == `["Widget","Gadget"]`, "arrays should be replaced whole, got %s", asJSON(state["items"]))
+
+	// --- JSON Patch ---
+	fmt.Println("\n--- FieldsChanged (JSON Patch) ---")
+	checks.Check(apply("FieldsChanged", `{"patch":[
+		{"op":"test","path":"/status","value":"shipped"},
+		{"op":"add","path":"/items/1","value":"Gizmo"},
+		{"op":"remove","path":"/shipping/tracking"},
+		{"op":"move","from":"/shipping/carrier","path":"/carrier"},
+		{"op":"copy","from":"/amount","path":"/originalAmount"},
+		{"op":"replace","path":"/amount","value":115},
+		{"op":"add","path":"/tags","value":{"a/b":true,"c~d":false}},
+		{"op":"remove","path":"/tags/a~1b"},
+		{"op":"add","path":"/items/-","value":"Doohickey"}
+	]}`) == nil, "patch failed")
+	checks.Check(asJSON(projection.Get("order-1")) == `{"amount":115,"carrier":"ups","items":["Widget","Gizmo","Gadget","Doohickey"],"originalAmount":100,"shipping":{"speed":"express"},"status":"shipped","tags":{"c~d":false}}`,
+		"unexpected state after JSON Patch: %s", asJSON(projection.Get("order-1")))
+
+	// --- Invalid patches ---
+	fmt.Println("\n--- Invalid patches leave the state unchanged ---")
+	before := asJSON(projection.Get("order-1"))
+	invalid := []string{
+		`{"patch":[{"op":"replace","path":"/status","value":"cancelled"},{"op":"test","path":"/amount","value":999}]}`,
+		`{"patch":[{"op":"remove","path":"/missing"}]}`,
+		`{"patch":[{"op":"add","path":"/items/9","value":"x"}]}`,
+		`{"patch":[{"op":"add","path":"/items/01","value":"x"}]}`,
+		`{"patch":[{"op":"replace","path":"status","value":"x"}]}`,
+		`{"patch":[{"op":"add","path":"/status"}]}`,
+		`{"patch":[{"op":"move","from":"/shipping","path":"/shipping/inner"}]}`,
+		`{"patch":[{"op":"frobnicate","path":"/status"}]}`,
+		`{"patch":[{"op":"replace","path":"","value":[1,2]}]}`,
+		`{"patch":{"op":"remove","path":"/status"}}`,
+	}
+	for _, data := range invalid {
+		err := apply("FieldsChanged", data)
+		fmt.Printf("  rejected: %v\n", err)
+		checks.Check(errors.Is(err, ErrInvalidPatch), "%s should fail with ErrInvalidPatch, got %v", data, err)
+	}
+	checks.Check(asJSON(projection.Get("order-1")) == before, "failed patches should not change state, got %s", asJSON(projection.Get("order-1")))
+	checks.Check(projection.Checkpoint.Commit == 400, "failed patches should not move the checkpoint, got %v", projection.Checkpoint)
+
+	// --- Numbers ---
+	checks.Check(jsonEqual(json.Number("1"), float64(1)) && !jsonEqual("1", float64(1)), "test should compare numbers by value and not across types")
+
+	checks.Done()
+}
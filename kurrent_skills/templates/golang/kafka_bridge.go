@@ -0,0 +1,334 @@
+// KurrentDB Go Client Example - Kafka bridge
+// Demonstrates: Mirroring $all to Kafka topics per category, keyed by stream, checkpointing after the produce ack
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === KAFKA PRODUCER ===
+
+// KafkaHeader is one record header
+type KafkaHeader struct {
+	Key   string
+	Value []byte
+}
+
+// KafkaRecord is what the bridge hands to the producer
+type KafkaRecord struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers []KafkaHeader
+}
+
+// KafkaProducer sends a record and returns once the broker acknowledged it.
+//
+// Wrap your Kafka client of choice (franz-go, confluent-kafka-go, segmentio/kafka-go) with
+// acks=all and idempotence enabled. Without idempotence, set max in-flight requests to 1,
+// otherwise a retried batch can land behind a later one and break per-stream order.
+type KafkaProducer interface {
+	Produce(ctx context.Context, record KafkaRecord) error
+}
+
+// === KAFKA BRIDGE ===
+
+// KafkaBridge mirrors events to Kafka: the topic comes from the stream's category, the key is the
+// stream id so every event of a stream lands on the same partition in order.
+//
+// Delivery is at-least-once: the checkpoint is saved only after the produce is acknowledged, so a
+// crash (or a lost ack that is retried) can write the same event twice. Consumers dedupe on the
+// kurrent-event-id header.
+type KafkaBridge struct {
+	client     *kurrentdb.Client
+	producer   KafkaProducer
+	checkpoint FileCheckpoint
+
+	// TopicPrefix is prepended to the category, e.g. "kurrent." + "order"
+	TopicPrefix string
+	// DefaultTopic receives events from streams without a {category}-{id} name
+	DefaultTopic string
+	// MaxAttempts bounds produce retries for one event before the bridge stops
+	MaxAttempts int
+	Backoff     Backoff
+
+	Produced int
+}
+
+func NewKafkaBridge(client *kurrentdb.Client, producer KafkaProducer, checkpoint FileCheckpoint) *KafkaBridge {
+	return &KafkaBridge{
+		client:       client,
+		producer:     producer,
+		checkpoint:   checkpoint,
+		TopicPrefix:  "kurrent.",
+		DefaultTopic: "kurrent.uncategorized",
+		MaxAttempts:  5,
+		Backoff:      NewBackoff(500*time.Millisecond, 10*time.Second),
+	}
+}
+
+// Record maps an event to a Kafka record
+func (b *KafkaBridge) Record(envelope Envelope) KafkaRecord {
+	topic := b.DefaultTopic
+	if category, _, ok := Streams.Parse(envelope.StreamID); ok {
+		topic = b.TopicPrefix + category
+	}
+
+	headers := []KafkaHeader{
+		{Key: "kurrent-event-id", Value: []byte(envelope.EventID.String())},
+		{Key: "kurrent-event-type", Value: []byte(envelope.EventType)},
+		{Key: "kurrent-stream-id", Value: []byte(envelope.StreamID)},
+		{Key: "kurrent-event-number", Value: []byte(strconv.FormatUint(envelope.EventNumber, 10))},
+		{Key: "kurrent-commit-position", Value: []byte(strconv.FormatUint(envelope.Position.Commit, 10))},
+		{Key: "content-type", Value: []byte(envelope.ContentType)},
+	}
+
+	// Top-level metadata fields become headers; string values are sent unquoted
+	var metadata map[string]json.RawMessage
+	if json.Unmarshal(envelope.Metadata, &metadata) == nil {
+		for key, raw := range metadata {
+			value := []byte(raw)
+			var s string
+			if json.Unmarshal(raw, &s) == nil {
+				value = []byte(s)
+			}
+			headers = append(headers, KafkaHeader{Key: key, Value: value})
+		}
+	}
+
+	return KafkaRecord{
+		Topic:   topic,
+		Key:     []byte(envelope.StreamID),
+		Value:   envelope.Data,
+		Headers: headers,
+	}
+}
+
+// Run mirrors events matching filter until done returns true, ctx is cancelled, or a produce
+// exhausts its retries. Events are produced one at a time, so per-stream order is preserved.
+func (b *KafkaBridge) Run(ctx context.Context, filter *kurrentdb.SubscriptionFilter, done func(Envelope) bool) error {
+	checkpoint, err := b.checkpoint.Load()
+	if err != nil {
+		return err
+	}
+
+	options := kurrentdb.SubscribeToAllOptions{Filter: filter, From: kurrentdb.Start{}}
+	if checkpoint != nil {
+		options.From = *checkpoint
+		fmt.Printf("  Resuming from checkpoint %d/%d\n", checkpoint.Commit, checkpoint.Prepare)
+	}
+
+	subscription, err := b.client.SubscribeToAll(ctx, options)
+	if err != nil {
+		return err
+	}
+	defer subscription.Close()
+
+	for {
+		event := subscription.Recv()
+
+		if event.SubscriptionDropped != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return event.SubscriptionDropped.Error
+		}
+
+		if event.EventAppeared != nil {
+			envelope := NewEnvelope(event.EventAppeared)
+			if err := b.produce(ctx, b.Record(envelope)); err != nil {
+				return fmt.Errorf("produce %s@%d: %w", envelope.StreamID, envelope.EventNumber, err)
+			}
+			b.Produced++
+			if err := b.checkpoint.Save(envelope.Position); err != nil {
+				return err
+			}
+			if done != nil && done(envelope) {
+				return nil
+			}
+		}
+	}
+}
+
+func (b *KafkaBridge) produce(ctx context.Context, record KafkaRecord) error {
+	return b.Backoff.Retry(ctx, b.MaxAttempts, func(attempt int) error {
+		err := b.producer.Produce(ctx, record)
+		if err != nil {
+			fmt.Printf("  Produce attempt %d failed: %v\n", attempt, err)
+		}
+		return err
+	}, nil)
+}
+
+// === IN-MEMORY PRODUCER ===
+
+// errAckLost simulates a broker that stored the record but whose ack never reached the producer
+var errAckLost = errors.New("request timed out waiting for ack")
+
+// memoryProducer is a fake Kafka: key-hashed partitions per topic, with injectable lost acks
+type memoryProducer struct {
+	mu         sync.Mutex
+	partitions int
+	topics     map[string][][]KafkaRecord
+	// loseAck makes Produce store the record but report errAckLost
+	loseAck func(KafkaRecord) bool
+}
+
+func newMemoryProducer(partitions int) *memoryProducer {
+	return &memoryProducer{partitions: partitions, topics: make(map[string][][]KafkaRecord)}
+}
+
+func (p *memoryProducer) Produce(ctx context.Context, record KafkaRecord) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.topics[record.Topic]; !ok {
+		p.topics[record.Topic] = make([][]KafkaRecord, p.partitions)
+	}
+	hash := fnv.New32a()
+	hash.Write(record.Key)
+	partition := int(hash.Sum32() % uint32(p.partitions))
+	p.topics[record.Topic][partition] = append(p.topics[record.Topic][partition], record)
+
+	if p.loseAck != nil && p.loseAck(record) {
+		return errAckLost
+	}
+	return nil
+}
+
+func kafkaHeader(record KafkaRecord, key string) string {
+	for _, header := range record.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+// RunKafkaBridge mirrors two interleaved order streams to an in-memory Kafka with one lost ack
+func RunKafkaBridge() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	// === APPEND INTERLEAVED EVENTS ===
+	streams := []string{
+		Streams.Name("order", uuid.New().String()),
+		Streams.Name("order", uuid.New().String()),
+	}
+	eventTypes := []string{"OrderCreated", "ItemAdded", "OrderShipped"}
+
+	for _, eventType := range eventTypes {
+		for _, stream := range streams {
+			_, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+				EventID:     uuid.New(),
+				EventType:   eventType,
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        []byte(`{}`),
+				Metadata:    []byte(`{"tenant":"acme","correlationId":"` + stream + `"}`),
+			})
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+	fmt.Printf("Appended %d events across %d streams\n", len(eventTypes)*len(streams), len(streams))
+
+	// === BRIDGE ===
+	dir, err := os.MkdirTemp("", "kafka-bridge")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	producer := newMemoryProducer(3)
+	lost := false
+	producer.loseAck = func(record KafkaRecord) bool {
+		if !lost && kafkaHeader(record, "kurrent-event-type") == "ItemAdded" {
+			lost = true
+			return true
+		}
+		return false
+	}
+
+	bridge := NewKafkaBridge(client, producer, FileCheckpoint{Path: filepath.Join(dir, "checkpoint.json")})
+	bridge.Backoff = NewBackoff(10*time.Millisecond, 100*time.Millisecond)
+
+	total := len(eventTypes) * len(streams)
+	seen := 0
+	filter := &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: streams}
+	err = bridge.Run(ctx, filter, func(e Envelope) bool {
+		seen++
+		return seen >= total
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// === INSPECT TOPIC ===
+	byStream := map[string][]string{}
+	records := 0
+	for partition, log := range producer.topics["kurrent.order"] {
+		for _, record := range log {
+			records++
+			stream := string(record.Key)
+			byStream[stream] = append(byStream[stream], kafkaHeader(record, "kurrent-event-type"))
+			fmt.Printf("  p%d %s %s #%s tenant=%s\n", partition, stream[:14], kafkaHeader(record, "kurrent-event-type"),
+				kafkaHeader(record, "kurrent-event-number"), kafkaHeader(record, "tenant"))
+		}
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if len(producer.topics) != 1 || producer.topics["kurrent.order"] == nil {
+		fmt.Printf("FAIL: All events should go to kurrent.order, got %d topics\n", len(producer.topics))
+		passed = false
+	}
+	if records != total+1 {
+		fmt.Printf("FAIL: Lost ack should cause exactly one duplicate, got %d records for %d events\n", records, total)
+		passed = false
+	}
+	for _, stream := range streams {
+		// Dedupe consecutive repeats as a consumer would, then the order must match the stream
+		var deduped []string
+		for _, t := range byStream[stream] {
+			if len(deduped) == 0 || deduped[len(deduped)-1] != t {
+				deduped = append(deduped, t)
+			}
+		}
+		if fmt.Sprint(deduped) != fmt.Sprint(eventTypes) {
+			fmt.Printf("FAIL: %s should be in stream order, got %v\n", stream, byStream[stream])
+			passed = false
+		}
+	}
+	if bridge.Produced != total {
+		fmt.Printf("FAIL: Bridge should report %d produced events, got %d\n", total, bridge.Produced)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll Kafka bridge tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,309 @@
+// KurrentDB Go Client Example - Append deduplication by business key
+// Demonstrates: Skipping an append when a recent event in the stream carries the same idempotency key
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === KEYED APPEND ===
+
+// KeyedEventID derives an event ID from the stream and idempotency key, so the same command always
+// produces the same event ID
+func KeyedEventID(stream, key string) uuid.UUID {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte("kurrentdb:"+stream+"#"+key))
+}
+
+// KeyedAppendResult is what KeyedAppender.Append did
+type KeyedAppendResult struct {
+	// Duplicate is set when an event with the key was found and nothing was appended
+	Duplicate bool
+	// Existing is the event already carrying the key, when Duplicate
+	Existing *kurrentdb.RecordedEvent
+	// Write is the append's result, when not Duplicate
+	Write *kurrentdb.WriteResult
+	// Scanned is how many events were read looking for the key, over all attempts
+	Scanned int
+}
+
+// KeyedAppender appends an event only if none of the stream's last Window events carries the same
+// idempotencyKey in its metadata, so a command received twice appends once.
+//
+// Each Append reads up to Window events backwards from the end of the stream before writing: one
+// extra round trip, and a scan that grows with Window, not with the stream. Only duplicates within
+// the window are caught, so size it above the number of events a stream gets while a command may
+// still be retried. The append expects the revision the scan ended at, so a concurrent writer makes
+// it scan again rather than slip a duplicate in between.
+//
+// Events without an EventID get KeyedEventID, which backs the scan up: a duplicate that gets past the
+// window still has the original's ID, so consumers deduplicating by EventID (see Deduper) drop it,
+// and the server itself treats a re-append of the same ID at the same expected revision as done.
+type KeyedAppender struct {
+	store EventStore
+	// Window is how many of the stream's most recent events are searched for the key
+	Window uint64
+	// MaxAttempts bounds the scans when other writers keep appending between scan and append
+	MaxAttempts int
+}
+
+func NewKeyedAppender(store EventStore) *KeyedAppender {
+	return &KeyedAppender{store: store, Window: 100, MaxAttempts: 5}
+}
+
+// Append writes event to stream with key in its metadata, unless a recent event already has it
+func (a *KeyedAppender) Append(ctx context.Context, stream, key string, event kurrentdb.EventData) (KeyedAppendResult, error) {
+	var result KeyedAppendResult
+	if key == "" {
+		return result, errors.New("keyed append: empty idempotency key")
+	}
+
+	var meta Meta
+	if err := meta.UnmarshalJSON(event.Metadata); err != nil {
+		return result, err
+	}
+	metadata, err := meta.SetIdempotencyKey(key).Marshal()
+	if err != nil {
+		return result, err
+	}
+	event.Metadata = metadata
+	if event.EventID == uuid.Nil {
+		event.EventID = KeyedEventID(stream, key)
+	}
+
+	for attempt := 1; ; attempt++ {
+		existing, revision, scanned, err := a.find(ctx, stream, key)
+		result.Scanned += scanned
+		if err != nil {
+			return result, err
+		}
+		if existing != nil {
+			result.Duplicate, result.Existing = true, existing
+			return result, nil
+		}
+
+		result.Write, err = a.store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{StreamState: expectedState(revision)}, event)
+		if err == nil || !isWrongExpectedVersion(err) {
+			return result, err
+		}
+		if attempt >= a.MaxAttempts {
+			return result, fmt.Errorf("keyed append to %s: stream still moving after %d attempts: %w", stream, attempt, err)
+		}
+	}
+}
+
+// find scans the last Window events of stream for key, returning the event that has it, the
+// stream's current revision (NoVersion if it doesn't exist) and how many events were read
+func (a *KeyedAppender) find(ctx context.Context, stream, key string) (*kurrentdb.RecordedEvent, int64, int, error) {
+	events, err := a.store.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{
+		Direction: kurrentdb.Backwards,
+		From:      kurrentdb.End{},
+	}, a.Window)
+	if err != nil {
+		if isStreamNotFound(err) {
+			return nil, NoVersion, 0, nil
+		}
+		return nil, 0, 0, err
+	}
+	defer events.Close()
+
+	revision, scanned := int64(NoVersion), 0
+	for {
+		resolved, err := events.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil, revision, scanned, nil
+		}
+		if isStreamNotFound(err) {
+			return nil, NoVersion, scanned, nil
+		}
+		if err != nil {
+			return nil, 0, scanned, err
+		}
+		recorded := resolved.OriginalEvent()
+		if scanned == 0 {
+			revision = int64(recorded.EventNumber)
+		}
+		scanned++
+
+		// Events with unreadable metadata can't carry the key
+		var meta Meta
+		if meta.UnmarshalFrom(recorded) == nil && meta.IdempotencyKey() == key {
+			return recorded, revision, scanned, nil
+		}
+	}
+}
+
+// === CHECKS ===
+
+// RunKeyedAppendChecks checks duplicate suppression, the window bound and concurrent duplicates
+// against the in-memory store, no server required
+func RunKeyedAppendChecks() {
+	checks := newChecker("keyed append")
+
+	ctx := context.Background()
+	payment := func(amount int, meta Meta) kurrentdb.EventData {
+		var metadata []byte
+		if meta != nil {
+			metadata, _ = meta.Marshal()
+		}
+		return kurrentdb.EventData{
+			EventType:   "PaymentReceived",
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(fmt.Sprintf(`{"amount":%d}`, amount)),
+			Metadata:    metadata,
+		}
+	}
+	length := func(store *MemoryEventStore, stream string) int {
+		events, _, _ := readStreamFrom(ctx, store, stream, NoVersion)
+		return len(events)
+	}
+
+	fmt.Println("\n--- Duplicate suppressed ---")
+	store := NewMemoryEventStore()
+	appender := NewKeyedAppender(store)
+	first, err := appender.Append(ctx, "payment-1", "pay-42", payment(100, NewMeta().SetCorrelation("order-7")))
+	checks.Check(err == nil && !first.Duplicate && first.Write != nil, "the first append should write, got %+v (%v)", first, err)
+	second, err := appender.Append(ctx, "payment-1", "pay-42", payment(100, nil))
+	fmt.Printf("  first: duplicate=%v, second: duplicate=%v after scanning %d events\n", first.Duplicate, second.Duplicate, second.Scanned)
+	checks.Check(err == nil && second.Duplicate && second.Existing != nil && second.Existing.EventNumber == 0, "the second append should be suppressed, got %+v (%v)", second, err)
+	checks.Check(length(store, "payment-1") == 1, "the stream should hold one payment, got %d", length(store, "payment-1"))
+
+	other, err := appender.Append(ctx, "payment-1", "pay-43", payment(50, nil))
+	checks.Check(err == nil && !other.Duplicate, "another key should append, got %+v (%v)", other, err)
+
+	events, _, _ := readStreamFrom(ctx, store, "payment-1", NoVersion)
+	var meta Meta
+	meta.UnmarshalFrom(events[0])
+	checks.Check(meta.IdempotencyKey() == "pay-42" && meta.Correlation() == "order-7", "the key should be added to the existing metadata, got %v", meta)
+	checks.Check(events[0].EventID == KeyedEventID("payment-1", "pay-42"), "an event without an ID should get the keyed ID")
+
+	fmt.Println("\n--- Window ---")
+	windowed := NewKeyedAppender(store)
+	windowed.Window = 3
+	for i := 0; i < 3; i++ {
+		appender.Append(ctx, "payment-1", fmt.Sprintf("filler-%d", i), payment(1, nil))
+	}
+	beyond, err := windowed.Append(ctx, "payment-1", "pay-42", payment(100, nil))
+	fmt.Printf("  pay-42 is 4 events back: with a window of 3, duplicate=%v after scanning %d\n", beyond.Duplicate, beyond.Scanned)
+	checks.Check(err == nil && !beyond.Duplicate && beyond.Scanned == 3, "a key beyond the window should not be found, got %+v (%v)", beyond, err)
+
+	fmt.Println("\n--- Concurrent duplicates ---")
+	racing := NewMemoryEventStore()
+	racer := NewKeyedAppender(racing)
+	racer.MaxAttempts = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	written, duplicates := 0, 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := racer.Append(ctx, "payment-2", "pay-99", payment(100, nil))
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				fmt.Printf("  append failed: %v\n", err)
+			case result.Duplicate:
+				duplicates++
+			default:
+				written++
+			}
+		}()
+	}
+	wg.Wait()
+	fmt.Printf("  10 concurrent deliveries: %d written, %d suppressed\n", written, duplicates)
+	checks.Check(written == 1 && duplicates == 9 && length(racing, "payment-2") == 1, "exactly one of the concurrent deliveries should be written, got %d written", written)
+
+	_, err = appender.Append(ctx, "payment-1", "", payment(1, nil))
+	checks.Check(err != nil, "an empty key should be rejected")
+
+	checks.Done()
+}
+
+// === DEMO ===
+
+// RunKeyedAppend appends a PaymentReceived, then the same command again
+func RunKeyedAppend() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	appender := NewKeyedAppender(NewClientStore(client))
+	stream := Streams.Name("payment", uuid.New().String())
+	// The payment provider's ID for the payment is the business key: a webhook redelivery repeats it
+	providerID := "ch_" + uuid.New().String()[:8]
+
+	receive := func(amount float64) KeyedAppendResult {
+		data, _ := json.Marshal(map[string]interface{}{"providerId": providerID, "amount": amount})
+		result, err := appender.Append(ctx, stream, providerID, kurrentdb.EventData{
+			EventType:   "PaymentReceived",
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        data,
+		})
+		if err != nil {
+			panic(err)
+		}
+		return result
+	}
+
+	// === FIRST DELIVERY ===
+	fmt.Println("\n=== PaymentReceived ===")
+	first := receive(100)
+	fmt.Printf("  Appended to %s at revision %d\n", stream, first.Write.NextExpectedVersion)
+
+	// === REDELIVERY ===
+	fmt.Println("\n=== The same PaymentReceived, redelivered ===")
+	second := receive(100)
+	if second.Duplicate {
+		fmt.Printf("  Suppressed: %s@%d already has %s %q (scanned %d events)\n",
+			second.Existing.StreamID, second.Existing.EventNumber, MetaIdempotencyKey, providerID, second.Scanned)
+	}
+
+	events, _, err := readStreamFrom(ctx, NewClientStore(client), stream, NoVersion)
+	if err != nil {
+		panic(err)
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if first.Duplicate || first.Write == nil {
+		fmt.Println("FAIL: The first PaymentReceived should be appended")
+		passed = false
+	}
+	if !second.Duplicate || second.Write != nil {
+		fmt.Println("FAIL: The redelivered PaymentReceived should be suppressed")
+		passed = false
+	}
+	if len(events) != 1 {
+		fmt.Printf("FAIL: The stream should hold one PaymentReceived, got %d events\n", len(events))
+		passed = false
+	}
+	var meta Meta
+	if len(events) > 0 && (meta.UnmarshalFrom(events[0]) != nil || meta.IdempotencyKey() != providerID || events[0].EventID != KeyedEventID(stream, providerID)) {
+		fmt.Printf("FAIL: The event should carry the key and the keyed ID, got %v and %s\n", meta, events[0].EventID)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll keyed append tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,259 @@
+// KurrentDB Go Client Example - Large payloads via chunk events and a manifest
+// Demonstrates: Splitting a blob into Chunk events, committing it with a Manifest, and verified reassembly
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === CHUNKED STORAGE ===
+
+// DefaultChunkSize keeps every append well below the server's default 1MB max append size
+const DefaultChunkSize = 512 * 1024
+
+var (
+	ErrManifestNotFound = errors.New("manifest not found")
+	ErrChunkMissing     = errors.New("chunk missing")
+	ErrChunkCorrupted   = errors.New("chunk corrupted")
+)
+
+// ManifestPart records one chunk event of a payload
+type ManifestPart struct {
+	Index   int       `json:"index"`
+	EventID uuid.UUID `json:"eventId"`
+	Size    int       `json:"size"`
+	SHA256  string    `json:"sha256"`
+}
+
+// Manifest is appended after all chunks; a payload without a manifest is incomplete and never read
+type Manifest struct {
+	PayloadID string         `json:"payloadId"`
+	TotalSize int            `json:"totalSize"`
+	SHA256    string         `json:"sha256"`
+	Parts     []ManifestPart `json:"parts"`
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ChunkedStore stores payloads larger than the max event size as Chunk + Manifest events
+type ChunkedStore struct {
+	client    *kurrentdb.Client
+	chunkSize int
+}
+
+func NewChunkedStore(client *kurrentdb.Client, chunkSize int) *ChunkedStore {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	return &ChunkedStore{client: client, chunkSize: chunkSize}
+}
+
+// Store appends the payload's chunks one per append, then the manifest that makes them visible
+func (s *ChunkedStore) Store(ctx context.Context, stream string, payload []byte) (*Manifest, error) {
+	manifest := &Manifest{
+		PayloadID: uuid.New().String(),
+		TotalSize: len(payload),
+		SHA256:    sha256Hex(payload),
+	}
+
+	for index, offset := 0, 0; offset < len(payload); index, offset = index+1, offset+s.chunkSize {
+		end := offset + s.chunkSize
+		if end > len(payload) {
+			end = len(payload)
+		}
+		chunk := payload[offset:end]
+
+		part := ManifestPart{Index: index, EventID: uuid.New(), Size: len(chunk), SHA256: sha256Hex(chunk)}
+		metadata, _ := json.Marshal(map[string]interface{}{"payloadId": manifest.PayloadID, "index": index})
+
+		_, err := s.client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     part.EventID,
+			ContentType: kurrentdb.ContentTypeBinary,
+			EventType:   "Chunk",
+			Data:        chunk,
+			Metadata:    metadata,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("append chunk %d: %w", index, err)
+		}
+		manifest.Parts = append(manifest.Parts, part)
+	}
+
+	if err := s.appendManifest(ctx, stream, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (s *ChunkedStore) appendManifest(ctx context.Context, stream string, manifest *Manifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+		EventID:     uuid.New(),
+		ContentType: kurrentdb.ContentTypeJson,
+		EventType:   "Manifest",
+		Data:        data,
+	})
+	return err
+}
+
+// Load reassembles the payload described by the stream's latest manifest, verifying every checksum
+func (s *ChunkedStore) Load(ctx context.Context, stream string) ([]byte, error) {
+	events, err := s.client.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{
+		Direction: kurrentdb.Backwards,
+		From:      kurrentdb.End{},
+	}, ^uint64(0))
+	if err != nil {
+		return nil, err
+	}
+	defer events.Close()
+
+	// Reading backwards finds the latest manifest first; its chunks always precede it
+	var manifest *Manifest
+	chunks := make(map[uuid.UUID][]byte)
+	for {
+		event, err := events.Recv()
+		if err == io.EOF {
+			break
+		}
+		if isStreamNotFound(err) {
+			return nil, fmt.Errorf("%w: stream %s does not exist", ErrManifestNotFound, stream)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		recorded := Resolve(event, false)
+		switch recorded.EventType {
+		case "Manifest":
+			if manifest == nil {
+				manifest = &Manifest{}
+				if err := json.Unmarshal(recorded.Data, manifest); err != nil {
+					return nil, fmt.Errorf("decode manifest: %w", err)
+				}
+			}
+		case "Chunk":
+			if manifest != nil {
+				chunks[recorded.EventID] = recorded.Data
+			}
+		}
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("%w in stream %s", ErrManifestNotFound, stream)
+	}
+
+	var payload bytes.Buffer
+	payload.Grow(manifest.TotalSize)
+	for _, part := range manifest.Parts {
+		chunk, ok := chunks[part.EventID]
+		if !ok {
+			return nil, fmt.Errorf("%w: part %d (event %s) of payload %s", ErrChunkMissing, part.Index, part.EventID, manifest.PayloadID)
+		}
+		if len(chunk) != part.Size || sha256Hex(chunk) != part.SHA256 {
+			return nil, fmt.Errorf("%w: part %d (event %s) of payload %s failed its checksum", ErrChunkCorrupted, part.Index, part.EventID, manifest.PayloadID)
+		}
+		payload.Write(chunk)
+	}
+
+	if payload.Len() != manifest.TotalSize || sha256Hex(payload.Bytes()) != manifest.SHA256 {
+		return nil, fmt.Errorf("%w: payload %s failed its checksum", ErrChunkCorrupted, manifest.PayloadID)
+	}
+	return payload.Bytes(), nil
+}
+
+// RunLargePayload demonstrates storing and retrieving a multi-megabyte document
+func RunLargePayload() {
+	ctx := context.Background()
+
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	store := NewChunkedStore(client, DefaultChunkSize)
+	streamName := fmt.Sprintf("blob-%s", uuid.New().String())
+
+	// === STORE ===
+	document := make([]byte, 3*1024*1024+123)
+	rand.Read(document)
+
+	manifest, err := store.Store(ctx, streamName, document)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Stored %d bytes as %d chunks in %s\n", manifest.TotalSize, len(manifest.Parts), streamName)
+
+	// === LOAD ===
+	loaded, err := store.Load(ctx, streamName)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Loaded %d bytes, sha256 %s\n", len(loaded), sha256Hex(loaded)[:16])
+
+	// === MISSING CHUNK ===
+	missing := *manifest
+	missing.Parts = append([]ManifestPart{}, manifest.Parts...)
+	missing.Parts[1].EventID = uuid.New()
+	if err := store.appendManifest(ctx, streamName, &missing); err != nil {
+		panic(err)
+	}
+	_, missingErr := store.Load(ctx, streamName)
+	fmt.Printf("Manifest with missing chunk: %v\n", missingErr)
+
+	// === CORRUPTED CHUNK ===
+	corrupted := *manifest
+	corrupted.Parts = append([]ManifestPart{}, manifest.Parts...)
+	corrupted.Parts[2].SHA256 = sha256Hex([]byte("tampered"))
+	if err := store.appendManifest(ctx, streamName, &corrupted); err != nil {
+		panic(err)
+	}
+	_, corruptedErr := store.Load(ctx, streamName)
+	fmt.Printf("Manifest with corrupted chunk: %v\n", corruptedErr)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if !bytes.Equal(loaded, document) {
+		fmt.Println("FAIL: Loaded payload should equal the stored document")
+		passed = false
+	}
+	if len(manifest.Parts) != 7 {
+		fmt.Printf("FAIL: Expected 7 chunks, got %d\n", len(manifest.Parts))
+		passed = false
+	}
+	if !errors.Is(missingErr, ErrChunkMissing) {
+		fmt.Printf("FAIL: Expected ErrChunkMissing, got %v\n", missingErr)
+		passed = false
+	}
+	if !errors.Is(corruptedErr, ErrChunkCorrupted) {
+		fmt.Printf("FAIL: Expected ErrChunkCorrupted, got %v\n", corruptedErr)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll large payload tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
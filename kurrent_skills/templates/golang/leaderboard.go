@@ -0,0 +1,350 @@
+// KurrentDB Go Client Example - Live top customers by spend
+// Demonstrates: A global projection keeping customers ranked incrementally, with a TopN query and deterministic ties
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === LEADERBOARD ===
+
+// LeaderboardEntry is one customer's place. Customers with the same total share a rank
+// (1, 2, 2, 4) and are listed by customer id.
+type LeaderboardEntry struct {
+	Rank       int    `json:"rank"`
+	CustomerID string `json:"customerId"`
+	Total      Money  `json:"total"`
+}
+
+// LeaderboardProjection ranks customers by their total spend across all their orders: the
+// OrderCreated amount plus the price of every ItemAdded. Customers stay sorted as events arrive,
+// so TopN copies the first n entries instead of sorting every customer per query. An update costs
+// a binary search plus moving the entries between the customer's old and new place.
+type LeaderboardProjection struct {
+	Name       string
+	Checkpoint *kurrentdb.Position
+
+	customers map[string]string // order stream -> customer id
+	totals    map[string]Money
+	ranked    []LeaderboardEntry // by Total descending, then CustomerID; Rank unset
+}
+
+func NewLeaderboardProjection() *LeaderboardProjection {
+	return &LeaderboardProjection{
+		Name:      "CustomerLeaderboard",
+		customers: make(map[string]string),
+		totals:    make(map[string]Money),
+	}
+}
+
+// Apply folds an OrderCreated or ItemAdded into the leaderboard. Like Projection.Apply it returns
+// false for other event types and an error, with state and checkpoint unchanged, for events it
+// can't use. Amounts are decoded exactly as Money.
+func (p *LeaderboardProjection) Apply(event *kurrentdb.RecordedEvent, position kurrentdb.Position) (bool, error) {
+	var field string
+	switch event.EventType {
+	case "OrderCreated":
+		field = "amount"
+	case "ItemAdded":
+		field = "price"
+	default:
+		return false, nil
+	}
+
+	data, err := decodeNumbers(event.Data)
+	if err != nil {
+		return false, fmt.Errorf("decode %s on %s: %w", event.EventType, event.StreamID, err)
+	}
+	amount, err := MoneyField(data, field)
+	if err != nil {
+		return false, fmt.Errorf("%s on %s: %w", event.EventType, event.StreamID, err)
+	}
+
+	customer, known := p.customers[event.StreamID]
+	switch {
+	case event.EventType == "OrderCreated" && known:
+		return false, fmt.Errorf("duplicate OrderCreated on %s", event.StreamID)
+	case event.EventType == "OrderCreated":
+		customer, _ = data["customerId"].(string)
+		if customer == "" {
+			return false, fmt.Errorf("OrderCreated on %s has no customerId", event.StreamID)
+		}
+	case !known:
+		return false, fmt.Errorf("ItemAdded on %s before OrderCreated", event.StreamID)
+	}
+
+	total, err := p.totals[customer].Add(amount)
+	if err != nil {
+		return false, fmt.Errorf("%s on %s: %w", event.EventType, event.StreamID, err)
+	}
+
+	p.customers[event.StreamID] = customer
+	p.move(customer, total)
+	p.Checkpoint = &position
+	return true, nil
+}
+
+// move re-ranks customer at its new total
+func (p *LeaderboardProjection) move(customer string, total Money) {
+	if previous, ok := p.totals[customer]; ok {
+		i, found := slices.BinarySearchFunc(p.ranked, LeaderboardEntry{CustomerID: customer, Total: previous}, compareLeaderboard)
+		if found {
+			p.ranked = slices.Delete(p.ranked, i, i+1)
+		}
+	}
+
+	entry := LeaderboardEntry{CustomerID: customer, Total: total}
+	i, _ := slices.BinarySearchFunc(p.ranked, entry, compareLeaderboard)
+	p.ranked = slices.Insert(p.ranked, i, entry)
+	p.totals[customer] = total
+}
+
+// compareLeaderboard orders by total descending, then customer id, so ties always list the same way
+func compareLeaderboard(a, b LeaderboardEntry) int {
+	switch {
+	case a.Total > b.Total:
+		return -1
+	case a.Total < b.Total:
+		return 1
+	}
+	return strings.Compare(a.CustomerID, b.CustomerID)
+}
+
+// TopN returns the n highest spenders with their ranks. A tie spanning position n is cut by
+// customer id.
+func (p *LeaderboardProjection) TopN(n int) []LeaderboardEntry {
+	n = min(max(n, 0), len(p.ranked))
+	top := make([]LeaderboardEntry, n)
+	for i := range top {
+		top[i] = p.ranked[i]
+		if i > 0 && top[i].Total == top[i-1].Total {
+			top[i].Rank = top[i-1].Rank
+		} else {
+			top[i].Rank = i + 1
+		}
+	}
+	return top
+}
+
+// Total returns one customer's spend; ok is false for customers without orders
+func (p *LeaderboardProjection) Total(customer string) (Money, bool) {
+	total, ok := p.totals[customer]
+	return total, ok
+}
+
+// RunLeaderboardChecks checks rankings, ties and the incremental order against a full sort,
+// no server required
+func RunLeaderboardChecks() {
+	checks := newChecker("leaderboard")
+
+	describe := func(entries []LeaderboardEntry) string {
+		var parts []string
+		for _, entry := range entries {
+			parts = append(parts, fmt.Sprintf("%d:%s=%s", entry.Rank, entry.CustomerID, entry.Total))
+		}
+		return strings.Join(parts, " ")
+	}
+
+	// --- Ranking and ties ---
+	fmt.Println("\n--- Ranking and ties ---")
+	{
+		projection := NewLeaderboardProjection()
+		events := []*kurrentdb.RecordedEvent{
+			syntheticEvent("order-1", "OrderCreated", 0, 100, `{"orderId":"1","customerId":"carol","amount":50}`),
+			syntheticEvent("order-2", "OrderCreated", 0, 200, `{"orderId":"2","customerId":"alice","amount":30}`),
+			syntheticEvent("order-3", "OrderCreated", 0, 300, `{"orderId":"3","customerId":"bob","amount":30}`),
+			syntheticEvent("order-4", "OrderCreated", 0, 400, `{"orderId":"4","customerId":"dave","amount":10.5}`),
+			// alice's item ties her with carol; alice sorts first
+			syntheticEvent("order-2", "ItemAdded", 1, 500, `{"item":"Widget","price":20}`),
+			// A second order for dave
+			syntheticEvent("order-5", "OrderCreated", 0, 600, `{"orderId":"5","customerId":"dave","amount":19.5}`),
+			syntheticEvent("order-1", "OrderShipped", 1, 700, `{"shippedAt":"2024-01-15T10:00:00Z"}`),
+		}
+		for _, event := range events {
+			_, err := projection.Apply(event, event.Position)
+			checks.Check(err == nil, "%s on %s: %v", event.EventType, event.StreamID, err)
+		}
+
+		top := describe(projection.TopN(5))
+		fmt.Printf("  top 5: %s\n", top)
+		checks.Check(top == "1:alice=50.00 1:carol=50.00 3:bob=30.00 3:dave=30.00", "unexpected ranking %s", top)
+		checks.Check(describe(projection.TopN(3)) == "1:alice=50.00 1:carol=50.00 3:bob=30.00", "a tie at the cut should be broken by id, got %s", describe(projection.TopN(3)))
+		checks.Check(len(projection.TopN(0)) == 0 && len(projection.TopN(-1)) == 0, "TopN of no entries should be empty")
+		checks.Check(projection.Checkpoint.Commit == 600, "checkpoint should follow the last applied event, got %v", projection.Checkpoint)
+
+		bad := []*kurrentdb.RecordedEvent{
+			syntheticEvent("order-9", "ItemAdded", 0, 800, `{"item":"Widget","price":5}`),
+			syntheticEvent("order-8", "OrderCreated", 0, 900, `{"orderId":"8","amount":5}`),
+			syntheticEvent("order-1", "OrderCreated", 2, 1000, `{"orderId":"1","customerId":"carol","amount":5}`),
+			syntheticEvent("order-1", "ItemAdded", 2, 1100, `{"item":"Widget","price":0.001}`),
+		}
+		for _, event := range bad {
+			applied, err := projection.Apply(event, event.Position)
+			fmt.Printf("  rejected: %v\n", err)
+			checks.Check(!applied && err != nil, "%s %s should be rejected", event.EventType, event.Data)
+		}
+		checks.Check(describe(projection.TopN(5)) == top, "rejected events should leave the ranking unchanged, got %s", describe(projection.TopN(5)))
+	}
+
+	// --- Incremental order matches a full sort ---
+	fmt.Println("\n--- Incremental order matches a full sort ---")
+	{
+		projection := NewLeaderboardProjection()
+		random := rand.New(rand.NewSource(42))
+		var commit uint64
+		for order := 0; order < 300; order++ {
+			stream := fmt.Sprintf("order-%d", order)
+			customer := fmt.Sprintf("customer-%02d", random.Intn(40))
+			commit++
+			created := syntheticEvent(stream, "OrderCreated", 0, commit,
+				fmt.Sprintf(`{"customerId":%q,"amount":%d}`, customer, random.Intn(5)*10))
+			projection.Apply(created, created.Position)
+			for item := 1; item <= random.Intn(3); item++ {
+				commit++
+				added := syntheticEvent(stream, "ItemAdded", uint64(item), commit, fmt.Sprintf(`{"price":%d}`, random.Intn(3)*5))
+				projection.Apply(added, added.Position)
+			}
+		}
+
+		var expected []LeaderboardEntry
+		for customer, total := range projection.totals {
+			expected = append(expected, LeaderboardEntry{CustomerID: customer, Total: total})
+		}
+		slices.SortFunc(expected, compareLeaderboard)
+
+		got := projection.TopN(len(expected))
+		for i := range got {
+			got[i].Rank = 0
+		}
+		fmt.Printf("  %d customers, top 3: %s\n", len(expected), describe(projection.TopN(3)))
+		checks.Check(slices.Equal(got, expected), "incremental ranking differs from a full sort")
+	}
+
+	checks.Done()
+}
+
+// RunLeaderboard seeds orders for a handful of customers and prints the top 5 from $all
+func RunLeaderboard() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	// === SEED ORDERS ===
+	// A run-specific id prefix keeps other runs' orders out of this demo's leaderboard
+	run := uuid.New().String()[:8]
+	type seedOrder struct {
+		customer string
+		amounts  []string // OrderCreated amount, then ItemAdded prices
+	}
+	seed := []seedOrder{
+		{"ana", []string{"120.00", "15.25"}},
+		{"ben", []string{"80.10"}},
+		{"cho", []string{"200.00"}},
+		{"dev", []string{"99.99", "0.01"}},
+		{"eli", []string{"45.00"}},
+		{"ben", []string{"19.90", "35.25"}},
+		{"fay", []string{"10.00", "5.00", "5.00"}},
+		{"gus", []string{"135.25"}},
+	}
+
+	total := 0
+	for i, order := range seed {
+		stream := Streams.Name("order", fmt.Sprintf("%s-%d", run, i))
+		for j, amount := range order.amounts {
+			eventType, data := "OrderCreated", fmt.Sprintf(`{"orderId":"%d","customerId":%q,"amount":%s}`, i, order.customer, amount)
+			if j > 0 {
+				eventType, data = "ItemAdded", fmt.Sprintf(`{"item":"Widget","price":%s}`, amount)
+			}
+			_, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+				EventID:     uuid.New(),
+				EventType:   eventType,
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        []byte(data),
+			})
+			if err != nil {
+				panic(err)
+			}
+			total++
+		}
+	}
+	fmt.Printf("Appended %d events for %d orders\n", total, len(seed))
+
+	// === RUN PROJECTION ===
+	projection := NewLeaderboardProjection()
+
+	subscription, err := client.SubscribeToAll(ctx, kurrentdb.SubscribeToAllOptions{
+		From: kurrentdb.Start{},
+		Filter: &kurrentdb.SubscriptionFilter{
+			Type:     kurrentdb.StreamFilterType,
+			Prefixes: []string{Streams.Name("order", run)},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	for applied := 0; applied < total; {
+		message := subscription.Recv()
+		if message.SubscriptionDropped != nil {
+			panic(message.SubscriptionDropped.Error)
+		}
+		if message.EventAppeared == nil {
+			continue
+		}
+
+		event := Resolve(message.EventAppeared, false)
+		ok, err := projection.Apply(event, Resolve(message.EventAppeared, true).Position)
+		if err != nil {
+			fmt.Printf("  Skipped: %v\n", err)
+		}
+		if ok {
+			applied++
+		}
+	}
+	subscription.Close()
+
+	top := projection.TopN(5)
+	fmt.Println("\n=== Top 5 customers by spend ===")
+	for _, entry := range top {
+		fmt.Printf("  #%d %-4s %10s\n", entry.Rank, entry.CustomerID, entry.Total)
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	// ana 135.25 and gus 135.25 tie; ben's two orders add up to 135.25 too
+	expected := []LeaderboardEntry{
+		{1, "cho", 20000},
+		{2, "ana", 13525},
+		{2, "ben", 13525},
+		{2, "gus", 13525},
+		{5, "dev", 10000},
+	}
+	if !slices.Equal(top, expected) {
+		fmt.Printf("FAIL: Expected %v, got %v\n", expected, top)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll leaderboard tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,261 @@
+// KurrentDB Go Client Example - Link resolution per consumer
+// Demonstrates: Subscribing to $ce-order with and without ResolveLinkTos, and detecting links whose event was deleted
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === LINKS ===
+
+// LinkStatus is what a read or subscription delivered for one record of a stream that may hold links
+type LinkStatus int
+
+const (
+	// NotALink is a regular event: Event is set, Link is nil
+	NotALink LinkStatus = iota
+	// LinkResolved is a link read with ResolveLinkTos: Event is the event it points to, Link the link
+	LinkResolved
+	// LinkUnresolved is a link read without ResolveLinkTos: Event is the link record itself
+	LinkUnresolved
+	// LinkDangling is a link read with ResolveLinkTos whose event was deleted, truncated or scavenged:
+	// Link is set and Event is nil
+	LinkDangling
+)
+
+func (s LinkStatus) String() string {
+	switch s {
+	case NotALink:
+		return "NotALink"
+	case LinkResolved:
+		return "LinkResolved"
+	case LinkUnresolved:
+		return "LinkUnresolved"
+	case LinkDangling:
+		return "LinkDangling"
+	}
+	return fmt.Sprintf("LinkStatus(%d)", int(s))
+}
+
+// LinkStatusOf classifies a read or subscription result
+func LinkStatusOf(resolved *kurrentdb.ResolvedEvent) LinkStatus {
+	switch {
+	case resolved.Link != nil && resolved.Event != nil:
+		return LinkResolved
+	case resolved.Link != nil:
+		return LinkDangling
+	case resolved.Event != nil && resolved.Event.EventType == linkEventType:
+		return LinkUnresolved
+	}
+	return NotALink
+}
+
+// ParseLink returns the stream and event number a link record points to, from its
+// "{eventNumber}@{stream}" data. ok is false for anything that isn't a well-formed link.
+func ParseLink(link *kurrentdb.RecordedEvent) (stream string, eventNumber uint64, ok bool) {
+	if link == nil || link.EventType != linkEventType {
+		return "", 0, false
+	}
+	number, stream, found := strings.Cut(string(link.Data), "@")
+	if !found || stream == "" {
+		return "", 0, false
+	}
+	eventNumber, err := strconv.ParseUint(number, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return stream, eventNumber, true
+}
+
+// SubscribeToCategory subscribes to $ce-{category} after from, or from the start when from is nil.
+// resolveLinks is passed on as ResolveLinkTos, so each consumer picks what it receives: the events
+// the links point to, for their data, or just the links, which is cheaper when the stream and
+// number they carry are all a consumer needs. Either way OriginalEvent() is the link, for
+// checkpoints in the category stream.
+func SubscribeToCategory(ctx context.Context, store EventStore, category string, from *uint64, resolveLinks bool) (EventSubscription, error) {
+	options := kurrentdb.SubscribeToStreamOptions{From: kurrentdb.Start{}, ResolveLinkTos: resolveLinks}
+	if from != nil {
+		options.From = kurrentdb.Revision(*from)
+	}
+	return store.SubscribeToStream(ctx, Streams.Category(category), options)
+}
+
+// describeRecord renders a record as "Type stream@number", or "<nil>"
+func describeRecord(event *kurrentdb.RecordedEvent) string {
+	if event == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%s %s@%d", event.EventType, event.StreamID, event.EventNumber)
+}
+
+// RunLinkResolution subscribes to $ce-order twice, resolving links in one consumer and not in the
+// other, after deleting one of the orders the links point to
+func RunLinkResolution() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+	store := NewClientStore(client)
+
+	// Start after the current end of $ce-order, so only this run's links are read
+	var from *uint64
+	last, _, err := readTail(ctx, client, Streams.Category("order"), 1, false)
+	if err != nil {
+		panic(err)
+	}
+	if len(last) > 0 {
+		from = &Resolve(last[0], true).EventNumber
+	}
+
+	kept := Streams.Name("order", uuid.New().String())
+	deleted := Streams.Name("order", uuid.New().String())
+	for _, stream := range []string{kept, deleted} {
+		_, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   "OrderPlaced",
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(`{"amount":100}`),
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	fmt.Printf("Placed %s and %s\n", kept, deleted)
+
+	// Wait for $by_category to link both orders, then delete one: its link stays in $ce-order
+	waitCtx, stopWaiting := context.WithTimeout(ctx, 10*time.Second)
+	linked, linkErr := collectCategory(waitCtx, store, "order", from, false, kept, deleted)
+	stopWaiting()
+	if linkErr != nil {
+		fmt.Printf("$ce-order never linked both orders (are system projections running?): %v\n", linkErr)
+		os.Exit(1)
+	}
+	if _, err := client.DeleteStream(ctx, deleted, kurrentdb.DeleteStreamOptions{StreamState: kurrentdb.Any{}}); err != nil {
+		panic(err)
+	}
+	fmt.Printf("Deleted %s; $ce-order still links to it at revision %d\n", deleted, linked[deleted].Event.EventNumber)
+
+	// === BOTH MODES ===
+	results := make(map[bool]map[string]*kurrentdb.ResolvedEvent)
+	for _, resolveLinks := range []bool{true, false} {
+		fmt.Printf("\n=== ResolveLinkTos: %v ===\n", resolveLinks)
+		consumerCtx, stopConsumer := context.WithTimeout(ctx, 10*time.Second)
+		received, err := collectCategory(consumerCtx, store, "order", from, resolveLinks, kept, deleted)
+		stopConsumer()
+		if err != nil {
+			panic(err)
+		}
+		results[resolveLinks] = received
+
+		for _, stream := range []string{kept, deleted} {
+			resolved := received[stream]
+			status := LinkStatusOf(resolved)
+			fmt.Printf("  %s\n    status:          %s\n    Event:           %s\n    Link:            %s\n    OriginalEvent(): %s\n",
+				stream, status, describeRecord(resolved.Event), describeRecord(resolved.Link), describeRecord(resolved.OriginalEvent()))
+
+			// How a consumer handles each case
+			switch status {
+			case LinkResolved, NotALink:
+				fmt.Printf("    -> handle %s from %s\n", resolved.Event.EventType, resolved.Event.StreamID)
+			case LinkDangling:
+				target, number, _ := ParseLink(resolved.Link)
+				fmt.Printf("    -> skip: %s@%d was deleted, checkpoint past %s@%d\n", target, number, resolved.Link.StreamID, resolved.Link.EventNumber)
+			case LinkUnresolved:
+				target, number, _ := ParseLink(resolved.Event)
+				fmt.Printf("    -> link only: points to %s@%d, read it if the data is needed\n", target, number)
+			}
+		}
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+	categoryStream := Streams.Category("order")
+
+	resolvedKept, resolvedDeleted := results[true][kept], results[true][deleted]
+	if LinkStatusOf(resolvedKept) != LinkResolved || resolvedKept.Event.StreamID != kept || resolvedKept.Event.EventType != "OrderPlaced" {
+		fmt.Printf("FAIL: With ResolveLinkTos Event should be the order's event, got %s\n", describeRecord(resolvedKept.Event))
+		passed = false
+	}
+	if original := resolvedKept.OriginalEvent(); original != resolvedKept.Link || original.StreamID != categoryStream {
+		fmt.Printf("FAIL: With ResolveLinkTos OriginalEvent() should be the link in %s, got %s\n", categoryStream, describeRecord(original))
+		passed = false
+	}
+	if LinkStatusOf(resolvedDeleted) != LinkDangling || resolvedDeleted.Event != nil {
+		fmt.Printf("FAIL: The link to the deleted order should resolve to nothing, got %s with Event %s\n", LinkStatusOf(resolvedDeleted), describeRecord(resolvedDeleted.Event))
+		passed = false
+	}
+	if target, _, ok := ParseLink(resolvedDeleted.OriginalEvent()); !ok || target != deleted {
+		fmt.Printf("FAIL: A dangling link should still name the deleted stream, got %q\n", target)
+		passed = false
+	}
+	if Resolve(resolvedDeleted, false) != resolvedDeleted.Link {
+		fmt.Println("FAIL: Resolve should fall back to the link when its event is gone")
+		passed = false
+	}
+	for _, stream := range []string{kept, deleted} {
+		raw := results[false][stream]
+		if LinkStatusOf(raw) != LinkUnresolved || raw.Link != nil || raw.OriginalEvent() != raw.Event || raw.Event.StreamID != categoryStream {
+			fmt.Printf("FAIL: Without ResolveLinkTos Event and OriginalEvent() should both be the link, got %s / %s\n", describeRecord(raw.Event), describeRecord(raw.OriginalEvent()))
+			passed = false
+		}
+		if raw.Event.EventNumber != results[true][stream].OriginalEvent().EventNumber {
+			fmt.Printf("FAIL: Both modes should deliver the same link for %s\n", stream)
+			passed = false
+		}
+	}
+
+	if passed {
+		fmt.Println("\nAll link resolution tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
+
+// collectCategory subscribes to category and returns the record delivered for the link to each of
+// streams, keyed by stream, once it has one for every stream
+func collectCategory(ctx context.Context, store EventStore, category string, from *uint64, resolveLinks bool, streams ...string) (map[string]*kurrentdb.ResolvedEvent, error) {
+	subscription, err := SubscribeToCategory(ctx, store, category, from, resolveLinks)
+	if err != nil {
+		return nil, err
+	}
+	defer subscription.Close()
+
+	wanted := make(map[string]bool, len(streams))
+	for _, stream := range streams {
+		wanted[stream] = true
+	}
+	received := make(map[string]*kurrentdb.ResolvedEvent)
+	for len(received) < len(streams) {
+		message := subscription.Recv()
+		if message.SubscriptionDropped != nil {
+			if message.SubscriptionDropped.Error == nil {
+				return received, errors.New("subscription dropped")
+			}
+			return received, message.SubscriptionDropped.Error
+		}
+		if message.EventAppeared == nil {
+			continue
+		}
+		// The link names its event's stream whether or not it was resolved
+		if target, _, ok := ParseLink(message.EventAppeared.OriginalEvent()); ok && wanted[target] {
+			received[target] = message.EventAppeared
+		}
+	}
+	return received, nil
+}
@@ -21,35 +21,409 @@ type OrderCreated struct {
 
 func main() {
 	// Handle command line arguments
-	if len(os.Args) > 1 && os.Args[1] == "projection" {
-		RunProjection()
-		return
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "stream-namer":
+			RunStreamNamerChecks()
+			return
+		case "projection":
+			RunProjection()
+			return
+		case "content-type-guard":
+			RunContentTypeGuard()
+			return
+		case "subscription-metrics":
+			RunSubscriptionMetrics()
+			return
+		case "projection-result":
+			RunProjectionResult()
+			return
+		case "enriched-writer":
+			RunEnrichedWriter()
+			return
+		case "sqlite-readmodel":
+			RunSQLiteReadModel()
+			return
+		case "transactional-sink":
+			RunTransactionalSinkChecks()
+			return
+		case "context-handling":
+			RunContextHandling()
+			return
+		case "verify-order":
+			RunVerifyOrder()
+			return
+		case "large-payload":
+			RunLargePayload()
+			return
+		case "projection-checks":
+			RunProjectionChecks()
+			return
+		case "persistent":
+			RunPersistentSubscription()
+			return
+		case "fanout":
+			RunFanOut()
+			return
+		case "webhook":
+			RunWebhook()
+			return
+		case "kafka-bridge":
+			RunKafkaBridge()
+			return
+		case "stream-diff":
+			RunStreamDiff()
+			return
+		case "subscription-watchdog":
+			RunSubscriptionWatchdog()
+			return
+		case "aggregate":
+			RunAggregate()
+			return
+		case "aggregate-checks":
+			RunAggregateChecks()
+			return
+		case "decoder":
+			RunDecoder()
+			return
+		case "category-projections":
+			RunCategoryProjections()
+			return
+		case "start-from":
+			RunStartFromChecks()
+			return
+		case "append-position":
+			RunAppendPosition()
+			return
+		case "recent-activity":
+			RunRecentActivity()
+			return
+		case "persistent-processor":
+			RunPersistentProcessor()
+			return
+		case "bulk-delete":
+			RunBulkDelete()
+			return
+		case "side-effects":
+			RunSideEffects()
+			return
+		case "connection-string-checks":
+			RunConnectionStringChecks()
+			return
+		case "aggregates-projection":
+			RunAggregatesProjection()
+			return
+		case "aggregates-projection-checks":
+			RunAggregatesProjectionChecks()
+			return
+		case "revision-cache":
+			RunRevisionCache()
+			return
+		case "subscription-pause":
+			RunSubscriptionPause()
+			return
+		case "redaction-checks":
+			RunRedactionChecks()
+			return
+		case "stream-snapshot":
+			RunStreamSnapshot()
+			return
+		case "persistent-settings":
+			RunPersistentSettings()
+			return
+		case "list-streams":
+			RunListStreams()
+			return
+		case "idempotent-batch":
+			RunIdempotentBatch()
+			return
+		case "tenant-projections":
+			RunTenantProjections()
+			return
+		case "money-checks":
+			RunMoneyChecks()
+			return
+		case "reader-append":
+			RunReaderAppend()
+			return
+		case "leaderboard":
+			RunLeaderboard()
+			return
+		case "leaderboard-checks":
+			RunLeaderboardChecks()
+			return
+		case "caught-up-checkpoint":
+			RunCaughtUpCheckpoint()
+			return
+		case "json-patch-checks":
+			RunJSONPatchChecks()
+			return
+		case "causation-trace":
+			RunCausationTrace()
+			return
+		case "resolve-checks":
+			RunResolveChecks()
+			return
+		case "rate-alerts":
+			RunRateAlerts()
+			return
+		case "rate-alert-checks":
+			RunRateAlertChecks()
+			return
+		case "startup-mode":
+			RunStartupMode()
+			return
+		case "meta-checks":
+			RunMetaChecks()
+			return
+		case "time-lag":
+			RunTimeLag()
+			return
+		case "transact":
+			RunTransact()
+			return
+		case "ordering-checks":
+			RunOrderingChecks()
+			return
+		case "projection-lifecycle":
+			RunProjectionLifecycle()
+			return
+		case "batch-appender":
+			RunBatchAppender()
+			return
+		case "read-your-writes":
+			RunReadYourWrites()
+			return
+		case "type-mapper-checks":
+			RunTypeMapperChecks()
+			return
+		case "type-mapper":
+			RunTypeMapper()
+			return
+		case "end-of-day-report":
+			RunEndOfDayReport()
+			return
+		case "handler-metrics-checks":
+			RunHandlerMetricsChecks()
+			return
+		case "reshape":
+			RunReshape()
+			return
+		case "shared-client":
+			RunSharedClient()
+			return
+		case "gap-detection-checks":
+			RunGapDetectionChecks()
+			return
+		case "gap-detection":
+			RunGapDetection()
+			return
+		case "projection-filter-checks":
+			RunProjectionFilterChecks()
+			return
+		case "projection-filter":
+			RunProjectionFilter()
+			return
+		case "persistent-ensure-checks":
+			RunPersistentEnsureChecks()
+			return
+		case "persistent-ensure":
+			RunPersistentEnsure()
+			return
+		case "event-store-checks":
+			RunEventStoreChecks()
+			return
+		case "snapshot-compaction":
+			RunSnapshotCompaction()
+			return
+		case "error-policy-checks":
+			RunErrorPolicyChecks()
+			return
+		case "error-policy":
+			RunErrorPolicy()
+			return
+		case "windowed-projection-checks":
+			RunWindowedProjectionChecks()
+			return
+		case "windowed-projection":
+			RunWindowedProjection()
+			return
+		case "tail":
+			RunTail()
+			return
+		case "readmodel-grpc":
+			RunReadModelGRPC()
+			return
+		case "event-codec-checks":
+			RunEventCodecChecks()
+			return
+		case "event-codec":
+			RunEventCodec()
+			return
+		case "backoff-checks":
+			RunBackoffChecks()
+			return
+		case "link-resolution":
+			RunLinkResolution()
+			return
+		case "spill-projection-checks":
+			RunSpillProjectionChecks()
+			return
+		case "spill-projection":
+			RunSpillProjection()
+			return
+		case "keyed-append-checks":
+			RunKeyedAppendChecks()
+			return
+		case "keyed-append":
+			RunKeyedAppend()
+			return
+		case "sharded-subscription-checks":
+			RunShardedSubscriptionChecks()
+			return
+		case "sharded-subscription":
+			RunShardedSubscription()
+			return
+		case "append-limits-checks":
+			RunAppendLimitsChecks()
+			return
+		case "append-limits":
+			RunAppendLimits()
+			return
+		case "schema-registry-checks":
+			RunSchemaRegistryChecks()
+			return
+		case "schema-registry":
+			RunSchemaRegistry()
+			return
+		case "region-merge-checks":
+			RunRegionMergeChecks()
+			return
+		case "read-by-event-type":
+			RunReadByEventType()
+			return
+		case "concurrency-limiter-checks":
+			RunConcurrencyLimiterChecks()
+			return
+		case "concurrency-limiter":
+			RunConcurrencyLimiter()
+			return
+		case "stream-acl-checks":
+			RunStreamAclChecks()
+			return
+		case "stream-acl":
+			RunStreamAcl()
+			return
+		case "fixture-loader-checks":
+			RunFixtureLoaderChecks()
+			return
+		case "fixture-loader":
+			RunFixtureLoader()
+			return
+		case "projection-notifier-checks":
+			RunProjectionNotifierChecks()
+			return
+		case "projection-notifier":
+			RunProjectionNotifier()
+			return
+		case "occurred-at":
+			RunOccurredAt()
+			return
+		case "checkpoint-fencing-checks":
+			RunCheckpointFencingChecks()
+			return
+		case "checkpoint-fencing":
+			RunCheckpointFencing()
+			return
+		case "read-enricher-checks":
+			RunReadEnricherChecks()
+			return
+		case "read-enricher":
+			RunReadEnricher()
+			return
+		case "projection-keys-checks":
+			RunProjectionKeysChecks()
+			return
+		case "projection-keys":
+			RunProjectionKeys()
+			return
+		case "delivery-simulator-checks":
+			RunDeliverySimulatorChecks()
+			return
+		case "delivery-simulator":
+			RunDeliverySimulator()
+			return
+		case "transact-checks":
+			RunTransactChecks()
+			return
+		case "state-export-checks":
+			RunStateExportChecks()
+			return
+		case "state-export":
+			RunStateExport()
+			return
+		case "persistent-drain-checks":
+			RunPersistentDrainChecks()
+			return
+		case "persistent-drain":
+			RunPersistentDrain()
+			return
+		case "schema-inference-checks":
+			RunSchemaInferenceChecks()
+			return
+		case "schema-inference":
+			RunSchemaInference()
+			return
+		case "append-breaker-checks":
+			RunAppendBreakerChecks()
+			return
+		case "append-breaker":
+			RunAppendBreaker()
+			return
+		case "parallel-replay-checks":
+			RunParallelReplayChecks()
+			return
+		case "parallel-replay":
+			RunParallelReplay()
+			return
+		case "first-last-event-checks":
+			RunFirstLastEventChecks()
+			return
+		case "first-last-event":
+			RunFirstLastEvent()
+			return
+		case "hot-reload-checks":
+			RunHotReloadChecks()
+			return
+		case "hot-reload":
+			RunHotReload()
+			return
+		case "transport-compression-checks":
+			RunTransportCompressionChecks()
+			return
+		case "transport-compression":
+			RunTransportCompression()
+			return
+		case "compacted-view-checks":
+			RunCompactedViewChecks()
+			return
+		case "compacted-view":
+			RunCompactedView()
+			return
+		}
 	}
 
 	ctx := context.Background()
 
 	// === CONNECTION ===
-	connectionString := os.Getenv("KURRENTDB_CONNECTION_STRING")
-	if connectionString == "" {
-		connectionString = "esdb://localhost:2113?tls=false"
-	}
-
-	settings, err := kurrentdb.ParseConnectionString(connectionString)
-	if err != nil {
-		panic(err)
-	}
-
-	client, err := kurrentdb.NewClient(settings)
-	if err != nil {
-		panic(err)
-	}
+	client, connectionString := connect()
 	defer client.Close()
 
 	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
 
 	// === APPEND EVENTS ===
 	orderID := uuid.New().String()
-	streamName := fmt.Sprintf("order-%s", orderID)
+	streamName := Streams.Name("order", orderID)
 
 	order := OrderCreated{
 		OrderID:    orderID,
@@ -107,8 +481,9 @@ func main() {
 			panic(err)
 		}
 
-		fmt.Printf("  Event #%d: %s\n", event.Event.EventNumber, event.Event.EventType)
-		fmt.Printf("  Data: %s\n", string(event.Event.Data))
+		recorded := Resolve(event, false)
+		fmt.Printf("  Event #%d: %s\n", recorded.EventNumber, recorded.EventType)
+		fmt.Printf("  Data: %s\n", string(recorded.Data))
 	}
 
 	// === CATCH-UP SUBSCRIPTION (Stream) ===
@@ -130,9 +505,10 @@ func main() {
 		event := subscription.Recv()
 
 		if event.EventAppeared != nil {
+			recorded := Resolve(event.EventAppeared, false)
 			fmt.Printf("  [Sub] Received: %s @ revision %d\n",
-				event.EventAppeared.Event.EventType,
-				event.EventAppeared.Event.EventNumber)
+				recorded.EventType,
+				recorded.EventNumber)
 			count++
 			if count >= 1 {
 				break
@@ -166,11 +542,12 @@ func main() {
 		event := allSubscription.Recv()
 
 		if event.EventAppeared != nil {
-			streamID := event.EventAppeared.OriginalEvent().StreamID
+			recorded := Resolve(event.EventAppeared, false)
+			streamID := recorded.StreamID
 			if len(streamID) > 0 && streamID[0] != '$' {
 				fmt.Printf("  [Sub] Stream: %s, Type: %s\n",
 					streamID,
-					event.EventAppeared.OriginalEvent().EventType)
+					recorded.EventType)
 				count++
 				if count >= 3 {
 					break
@@ -200,9 +577,10 @@ func main() {
 		event := filteredSub.Recv()
 
 		if event.EventAppeared != nil {
+			recorded := Resolve(event.EventAppeared, false)
 			fmt.Printf("  [Filtered] Stream: %s, Type: %s\n",
-				event.EventAppeared.OriginalEvent().StreamID,
-				event.EventAppeared.OriginalEvent().EventType)
+				recorded.StreamID,
+				recorded.EventType)
 			count++
 			if count >= 3 {
 				break
@@ -234,9 +612,10 @@ func main() {
 		event := prefixSub.Recv()
 
 		if event.EventAppeared != nil {
+			recorded := Resolve(event.EventAppeared, false)
 			fmt.Printf("  [Prefix Filter] Stream: %s, Type: %s\n",
-				event.EventAppeared.OriginalEvent().StreamID,
-				event.EventAppeared.OriginalEvent().EventType)
+				recorded.StreamID,
+				recorded.EventType)
 			count++
 			if count >= 2 {
 				break
@@ -268,9 +647,10 @@ func main() {
 		event := eventTypeSub.Recv()
 
 		if event.EventAppeared != nil {
+			recorded := Resolve(event.EventAppeared, false)
 			fmt.Printf("  [Event Type Filter] Stream: %s, Type: %s\n",
-				event.EventAppeared.OriginalEvent().StreamID,
-				event.EventAppeared.OriginalEvent().EventType)
+				recorded.StreamID,
+				recorded.EventType)
 			count++
 			if count >= 2 {
 				break
@@ -285,3 +665,26 @@ func main() {
 
 	fmt.Println("\nAll tests passed!")
 }
+
+// defaultConnectionString targets a local node started with --insecure
+const defaultConnectionString = "esdb://localhost:2113?tls=false"
+
+// connect creates a client from KURRENTDB_CONNECTION_STRING, falling back to a local insecure node
+func connect() (*kurrentdb.Client, string) {
+	connectionString := os.Getenv("KURRENTDB_CONNECTION_STRING")
+	if connectionString == "" {
+		connectionString = defaultConnectionString
+	}
+
+	settings, err := kurrentdb.ParseConnectionString(connectionString)
+	if err != nil {
+		panic(err)
+	}
+
+	client, err := kurrentdb.NewClient(settings)
+	if err != nil {
+		panic(err)
+	}
+
+	return client, connectionString
+}
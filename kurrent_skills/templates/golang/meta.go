@@ -0,0 +1,284 @@
+// KurrentDB Go Client Example - Standard event metadata
+// Demonstrates: Typed access to correlation, causation, schema version, tenant and trace context in event metadata
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === METADATA KEYS ===
+
+const (
+	// MetaCorrelationID and MetaCausationID are the keys KurrentDB's own projections understand,
+	// e.g. $by_correlation_id
+	MetaCorrelationID = "$correlationId"
+	MetaCausationID   = "$causationId"
+	MetaSchemaVersion = "schemaVersion"
+	MetaTenantID      = "tenantId"
+	// MetaTraceParent is a W3C trace context header, carried so consumers can continue the trace
+	MetaTraceParent = "traceparent"
+	// MetaIdempotencyKey is the business key of the command that produced the event, see KeyedAppender
+	MetaIdempotencyKey = "idempotencyKey"
+	// MetaOccurredAt is when the event happened in the business, for events appended later than that,
+	// e.g. by a migration; see EventTime
+	MetaOccurredAt = "occurredAt"
+)
+
+// ErrInvalidMeta is returned for metadata that isn't a JSON object, or a key with a malformed value
+var ErrInvalidMeta = errors.New("invalid event metadata")
+
+// traceParentPattern matches version-00 traceparent headers: 00-{trace id}-{parent id}-{flags}
+var traceParentPattern = regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+// === META ===
+
+// Meta is event metadata as string values. Keys other than the standard ones are kept, so reading
+// and re-writing metadata doesn't drop what other producers added.
+type Meta map[string]string
+
+func NewMeta() Meta {
+	return make(Meta)
+}
+
+// CausedBy starts the metadata for an event caused by event: the causation is event's ID and the
+// correlation is carried over, or started from event's ID when it has none. Tenant and trace
+// context are carried over too.
+func CausedBy(event *kurrentdb.RecordedEvent) Meta {
+	var parent Meta
+	parent.UnmarshalFrom(event)
+
+	correlation := parent.Correlation()
+	if correlation == "" {
+		correlation = event.EventID.String()
+	}
+	return NewMeta().
+		SetCorrelation(correlation).
+		SetCausation(event.EventID.String()).
+		SetTenant(parent.Tenant()).
+		SetTraceParent(parent.TraceParent())
+}
+
+// set stores value under key; an empty value removes the key
+func (m Meta) set(key, value string) Meta {
+	if value == "" {
+		delete(m, key)
+	} else {
+		m[key] = value
+	}
+	return m
+}
+
+// Correlation returns the $correlationId, or "" when there is none
+func (m Meta) Correlation() string { return m[MetaCorrelationID] }
+
+// SetCorrelation sets the $correlationId; "" removes it
+func (m Meta) SetCorrelation(id string) Meta { return m.set(MetaCorrelationID, id) }
+
+// Causation returns the $causationId, or "" when there is none
+func (m Meta) Causation() string { return m[MetaCausationID] }
+
+// SetCausation sets the $causationId; "" removes it
+func (m Meta) SetCausation(id string) Meta { return m.set(MetaCausationID, id) }
+
+// SchemaVersion returns the schemaVersion, 1 for events written before versioning started
+func (m Meta) SchemaVersion() (int, error) {
+	value, ok := m[MetaSchemaVersion]
+	if !ok {
+		return 1, nil
+	}
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("%w: %s %q is not a positive integer", ErrInvalidMeta, MetaSchemaVersion, value)
+	}
+	return version, nil
+}
+
+// SetSchemaVersion sets the schemaVersion
+func (m Meta) SetSchemaVersion(version int) Meta {
+	return m.set(MetaSchemaVersion, strconv.Itoa(version))
+}
+
+// Tenant returns the tenantId, or "" when there is none
+func (m Meta) Tenant() string { return m[MetaTenantID] }
+
+// SetTenant sets the tenantId; "" removes it
+func (m Meta) SetTenant(id string) Meta { return m.set(MetaTenantID, id) }
+
+// TraceParent returns the traceparent, or "" when there is none or it is malformed: W3C trace
+// context says to start a new trace rather than continue a broken one
+func (m Meta) TraceParent() string {
+	if value := m[MetaTraceParent]; traceParentPattern.MatchString(value) {
+		return value
+	}
+	return ""
+}
+
+// SetTraceParent sets the traceparent; "" removes it
+func (m Meta) SetTraceParent(header string) Meta { return m.set(MetaTraceParent, header) }
+
+// IdempotencyKey returns the idempotencyKey, or "" when there is none
+func (m Meta) IdempotencyKey() string { return m[MetaIdempotencyKey] }
+
+// SetIdempotencyKey sets the idempotencyKey; "" removes it
+func (m Meta) SetIdempotencyKey(key string) Meta { return m.set(MetaIdempotencyKey, key) }
+
+// OccurredAt returns the occurredAt, or the zero time when there is none
+func (m Meta) OccurredAt() (time.Time, error) {
+	value, ok := m[MetaOccurredAt]
+	if !ok {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s %q is not an RFC 3339 time", ErrInvalidMeta, MetaOccurredAt, value)
+	}
+	return t, nil
+}
+
+// SetOccurredAt sets the occurredAt, in UTC; the zero time removes it
+func (m Meta) SetOccurredAt(t time.Time) Meta {
+	if t.IsZero() {
+		return m.set(MetaOccurredAt, "")
+	}
+	return m.set(MetaOccurredAt, t.UTC().Format(time.RFC3339Nano))
+}
+
+// Marshal encodes the metadata as a JSON object for EventData.Metadata
+func (m Meta) Marshal() ([]byte, error) {
+	return json.Marshal(map[string]string(m))
+}
+
+// UnmarshalJSON replaces the contents with a JSON object's members. Non-string values, like the
+// numeric schemaVersion other writers use, are kept as their JSON text; nulls are dropped. Empty
+// input is empty metadata.
+func (m *Meta) UnmarshalJSON(data []byte) error {
+	*m = NewMeta()
+	if len(data) == 0 {
+		return nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMeta, err)
+	}
+	for key, value := range raw {
+		var s string
+		switch {
+		case string(value) == "null":
+		case json.Unmarshal(value, &s) == nil:
+			(*m)[key] = s
+		default:
+			(*m)[key] = string(value)
+		}
+	}
+	return nil
+}
+
+// UnmarshalFrom replaces the contents with event's user metadata
+func (m *Meta) UnmarshalFrom(event *kurrentdb.RecordedEvent) error {
+	if err := m.UnmarshalJSON(event.UserMetadata); err != nil {
+		return fmt.Errorf("%s@%d: %w", event.StreamID, event.EventNumber, err)
+	}
+	return nil
+}
+
+// RunMetaChecks round-trips standard metadata and reads metadata with missing and foreign keys,
+// no server required
+func RunMetaChecks() {
+	checks := newChecker("metadata")
+
+	// --- Round trip ---
+	fmt.Println("\n--- Round trip ---")
+	correlation, causation := uuid.New().String(), uuid.New().String()
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	raw, err := NewMeta().
+		SetCorrelation(correlation).
+		SetCausation(causation).
+		SetSchemaVersion(3).
+		SetTenant("acme").
+		SetTraceParent(traceParent).
+		Marshal()
+	checks.Check(err == nil, "marshal failed: %v", err)
+	fmt.Printf("  %s\n", raw)
+
+	event := syntheticEvent("order-1", "OrderPlaced", 0, 100, `{}`)
+	event.UserMetadata = raw
+	var meta Meta
+	checks.Check(meta.UnmarshalFrom(event) == nil, "unmarshal failed")
+	version, err := meta.SchemaVersion()
+	checks.Check(meta.Correlation() == correlation && meta.Causation() == causation, "ids should round-trip, got %v", meta)
+	checks.Check(version == 3 && err == nil, "schema version should round-trip, got %d (%v)", version, err)
+	checks.Check(meta.Tenant() == "acme" && meta.TraceParent() == traceParent, "tenant and traceparent should round-trip, got %v", meta)
+
+	placedAt := time.Date(2019, 3, 4, 15, 30, 0, 500, time.FixedZone("CET", 3600))
+	raw, _ = NewMeta().SetOccurredAt(placedAt).Marshal()
+	event.UserMetadata = raw
+	meta.UnmarshalFrom(event)
+	occurredAt, err := meta.OccurredAt()
+	checks.Check(err == nil && occurredAt.Equal(placedAt) && occurredAt.Location() == time.UTC, "occurredAt should round-trip in UTC, got %v (%v) from %s", occurredAt, err, raw)
+
+	// --- Missing keys ---
+	fmt.Println("\n--- Missing keys ---")
+	for _, metadata := range []string{"", "{}", `{"$correlationId":null}`} {
+		event.UserMetadata = []byte(metadata)
+		err := meta.UnmarshalFrom(event)
+		version, versionErr := meta.SchemaVersion()
+		checks.Check(err == nil && len(meta) == 0, "%q should be empty metadata, got %v (%v)", metadata, meta, err)
+		checks.Check(meta.Correlation() == "" && meta.Causation() == "" && meta.Tenant() == "" && meta.TraceParent() == "",
+			"%q should have no standard values, got %v", metadata, meta)
+		checks.Check(version == 1 && versionErr == nil, "a missing schemaVersion should read as 1, got %d (%v)", version, versionErr)
+		occurredAt, err := meta.OccurredAt()
+		checks.Check(occurredAt.IsZero() && err == nil, "a missing occurredAt should read as the zero time, got %v (%v)", occurredAt, err)
+	}
+	checks.Check(len(NewMeta().SetTenant("acme").SetTenant("")) == 0, "setting an empty value should remove the key")
+	checks.Check(len(NewMeta().SetOccurredAt(placedAt).SetOccurredAt(time.Time{})) == 0, "setting the zero time should remove occurredAt")
+
+	// --- Foreign and malformed values ---
+	fmt.Println("\n--- Foreign and malformed values ---")
+	// As written by EnrichedWriter: numeric schemaVersion and extra keys
+	event.UserMetadata = []byte(`{"schemaVersion":2,"producer":"order-service","retries":[1,2],"traceparent":"not-a-trace"}`)
+	checks.Check(meta.UnmarshalFrom(event) == nil, "unmarshal of foreign metadata failed")
+	version, err = meta.SchemaVersion()
+	checks.Check(version == 2 && err == nil, "a numeric schemaVersion should be read, got %d (%v)", version, err)
+	checks.Check(meta["producer"] == "order-service" && meta["retries"] == "[1,2]", "foreign keys should be kept, got %v", meta)
+	checks.Check(meta.TraceParent() == "", "a malformed traceparent should be ignored, got %q", meta.TraceParent())
+	rewritten, _ := meta.SetCorrelation(correlation).Marshal()
+	checks.Check(json.Valid(rewritten) && meta["producer"] == "order-service", "re-marshalling should keep foreign keys, got %s", rewritten)
+
+	meta.set(MetaSchemaVersion, "two")
+	_, err = meta.SchemaVersion()
+	checks.Check(errors.Is(err, ErrInvalidMeta), "a non-numeric schemaVersion should fail, got %v", err)
+	meta.set(MetaOccurredAt, "04/03/2019")
+	_, err = meta.OccurredAt()
+	checks.Check(errors.Is(err, ErrInvalidMeta), "a malformed occurredAt should fail, got %v", err)
+
+	event.UserMetadata = []byte(`not json`)
+	err = meta.UnmarshalFrom(event)
+	fmt.Printf("  invalid metadata: %v\n", err)
+	checks.Check(errors.Is(err, ErrInvalidMeta), "non-JSON metadata should fail with ErrInvalidMeta, got %v", err)
+
+	// --- Caused by ---
+	fmt.Println("\n--- Caused by ---")
+	command := syntheticEvent("orderCommand-1", "PlaceOrder", 0, 100, `{}`)
+	command.UserMetadata, _ = NewMeta().SetTenant("acme").SetTraceParent(traceParent).Marshal()
+	placed := CausedBy(command)
+	checks.Check(placed.Causation() == command.EventID.String() && placed.Correlation() == command.EventID.String(),
+		"an event without a correlation should start one from its own ID, got %v", placed)
+	checks.Check(placed.Tenant() == "acme" && placed.TraceParent() == traceParent, "tenant and trace should carry over, got %v", placed)
+
+	placedEvent := syntheticEvent("order-1", "OrderPlaced", 0, 200, `{}`)
+	placedEvent.UserMetadata, _ = placed.Marshal()
+	shipped := CausedBy(placedEvent)
+	checks.Check(shipped.Causation() == placedEvent.EventID.String() && shipped.Correlation() == command.EventID.String(),
+		"the correlation should be carried down the chain, got %v", shipped)
+
+	checks.Done()
+}
@@ -0,0 +1,186 @@
+// KurrentDB Go Client Example - Exact money amounts in projections
+// Demonstrates: Decoding event data with json.Number and summing amounts in fixed point without float drift
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// === MONEY ===
+
+// Money is an amount in cents. Integer arithmetic keeps sums exact where float64 drifts
+// (0.1 + 0.2 != 0.3) and loses whole cents above 2^53.
+type Money int64
+
+// moneyScale is the number of cents in one unit
+const moneyScale = 100
+
+var (
+	// ErrMoneyPrecision is returned for amounts with fractions of a cent
+	ErrMoneyPrecision = errors.New("amount has more than 2 decimal places")
+	// ErrMoneyOverflow is returned when an amount or sum doesn't fit in Money
+	ErrMoneyOverflow = errors.New("amount out of range")
+)
+
+// ParseMoney converts a JSON number exactly, including exponent forms like 1.5e2. It never rounds:
+// fractions of a cent are an error rather than silently lost.
+func ParseMoney(n json.Number) (Money, error) {
+	amount, ok := new(big.Rat).SetString(n.String())
+	if !ok {
+		return 0, fmt.Errorf("%q is not a number", n)
+	}
+	cents := amount.Mul(amount, big.NewRat(moneyScale, 1))
+	if !cents.IsInt() {
+		return 0, fmt.Errorf("%s: %w", n, ErrMoneyPrecision)
+	}
+	if !cents.Num().IsInt64() {
+		return 0, fmt.Errorf("%s: %w", n, ErrMoneyOverflow)
+	}
+	return Money(cents.Num().Int64()), nil
+}
+
+// MoneyField returns data[key] as Money. data must come from a projection with UseNumber, or from
+// decodeNumbers: a float64 has already lost the exact value and is rejected.
+func MoneyField(data map[string]interface{}, key string) (Money, error) {
+	switch v := data[key].(type) {
+	case json.Number:
+		money, err := ParseMoney(v)
+		if err != nil {
+			return 0, fmt.Errorf("%q: %w", key, err)
+		}
+		return money, nil
+	case Money:
+		return v, nil
+	case nil:
+		return 0, fmt.Errorf("missing %q", key)
+	default:
+		return 0, fmt.Errorf("%q should be a json.Number, got %T %v", key, v, v)
+	}
+}
+
+// Add returns m + other, or ErrMoneyOverflow instead of wrapping around
+func (m Money) Add(other Money) (Money, error) {
+	if (other > 0 && m > math.MaxInt64-other) || (other < 0 && m < math.MinInt64-other) {
+		return 0, fmt.Errorf("%s + %s: %w", m, other, ErrMoneyOverflow)
+	}
+	return m + other, nil
+}
+
+// String formats the amount with exactly 2 decimal places
+func (m Money) String() string {
+	sign := ""
+	cents := new(big.Int).SetInt64(int64(m))
+	if cents.Sign() < 0 {
+		sign = "-"
+		cents.Neg(cents)
+	}
+	units, rest := new(big.Int).QuoRem(cents, big.NewInt(moneyScale), new(big.Int))
+	return fmt.Sprintf("%s%s.%02d", sign, units, rest.Int64())
+}
+
+// MarshalJSON writes the amount as a JSON number with 2 decimal places, so it reads back exactly
+// through ParseMoney
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// RunMoneyChecks sums order amounts as float64 and as Money and checks only Money is exact,
+// no server required
+func RunMoneyChecks() {
+	checks := newChecker("money")
+
+	// --- Parsing ---
+	fmt.Println("\n--- Parsing ---")
+	for input, want := range map[string]Money{"19.99": 1999, "0.1": 10, "-2.50": -250, "1.5e2": 15000, "7": 700, "1.230": 123} {
+		got, err := ParseMoney(json.Number(input))
+		checks.Check(err == nil && got == want, "ParseMoney(%s) = %v, %v; want %v", input, got, err, want)
+	}
+	_, err := ParseMoney("1.005")
+	checks.Check(errors.Is(err, ErrMoneyPrecision), "sub-cent amounts should be rejected, got %v", err)
+	_, err = ParseMoney("92233720368547758.08")
+	checks.Check(errors.Is(err, ErrMoneyOverflow), "amounts beyond int64 cents should be rejected, got %v", err)
+	_, err = Money(math.MaxInt64).Add(1)
+	checks.Check(errors.Is(err, ErrMoneyOverflow), "overflowing sums should be rejected, got %v", err)
+	checks.Check(Money(-5).String() == "-0.05" && Money(123456).String() == "1234.56", "String() = %s, %s", Money(-5), Money(123456))
+
+	// --- Summing without drift ---
+	fmt.Println("\n--- Summing without drift ---")
+	floatTotals := NewProjection("FloatTotals").
+		On("PaymentReceived", func(state, data map[string]interface{}) map[string]interface{} {
+			total, _ := state["total"].(float64)
+			state["total"] = total + data["amount"].(float64)
+			return state
+		})
+	exactTotals := NewProjection("ExactTotals").
+		UseNumber().
+		On("PaymentReceived", func(state, data map[string]interface{}) map[string]interface{} {
+			amount, err := MoneyField(data, "amount")
+			if err != nil {
+				// Recovered by Apply as a HandlerPanicError; state stays unchanged
+				panic(err)
+			}
+			total, _ := state["total"].(Money)
+			if total, err = total.Add(amount); err != nil {
+				panic(err)
+			}
+			state["total"] = total
+			return state
+		})
+
+	var commit uint64
+	apply := func(data string) (floatErr, exactErr error) {
+		commit += 100
+		event := syntheticEvent("account-1", "PaymentReceived", commit/100-1, commit, data)
+		_, floatErr = floatTotals.Apply(event, event.Position)
+		_, exactErr = exactTotals.Apply(event, event.Position)
+		return floatErr, exactErr
+	}
+
+	// Ten payments of 0.10
+	for i := 0; i < 10; i++ {
+		apply(`{"amount":0.10}`)
+	}
+
+	floatTotal := floatTotals.Get("account-1")["total"].(float64)
+	exactTotal := exactTotals.Get("account-1")["total"].(Money)
+	fmt.Printf("float64 total: %.17g\nMoney total:   %s\n", floatTotal, exactTotal)
+	checks.Check(floatTotal != 1, "float64 is expected to drift here, got exactly %v", floatTotal)
+	checks.Check(exactTotal == 100 && exactTotal.String() == "1.00", "Money total should be exactly 1.00, got %s", exactTotal)
+
+	_, exactErr := apply(`{"amount":0.005}`)
+	var panicErr *HandlerPanicError
+	checks.Check(errors.As(exactErr, &panicErr) && exactTotals.Get("account-1")["total"] == Money(100),
+		"a sub-cent amount should fail the event and leave the total unchanged, got %v", exactErr)
+
+	// --- Large integers ---
+	fmt.Println("\n--- Large integers ---")
+	// 2^53 + 1 is the first integer float64 can't represent
+	const large = "9007199254740993"
+	raw := []byte(`{"sequence":` + large + `,"amount":90071992547409.93}`)
+
+	var asFloat map[string]interface{}
+	json.Unmarshal(raw, &asFloat)
+	fmt.Printf("encoding/json default: sequence=%.0f\n", asFloat["sequence"])
+	checks.Check(fmt.Sprintf("%.0f", asFloat["sequence"]) != large, "float64 is expected to round %s", large)
+
+	exact, err := decodeNumbers(raw)
+	checks.Check(err == nil, "decodeNumbers failed: %v", err)
+	sequence, err := exact["sequence"].(json.Number).Int64()
+	fmt.Printf("UseNumber:             sequence=%d\n", sequence)
+	checks.Check(err == nil && fmt.Sprint(sequence) == large, "json.Number should keep %s exactly, got %d (%v)", large, sequence, err)
+
+	amount, err := MoneyField(exact, "amount")
+	checks.Check(err == nil && amount.String() == "90071992547409.93", "large amounts should keep every cent, got %s (%v)", amount, err)
+	_, err = MoneyField(asFloat, "amount")
+	checks.Check(err != nil, "MoneyField should reject float64 values that already lost precision")
+
+	encoded, _ := json.Marshal(map[string]interface{}{"total": amount})
+	checks.Check(strings.Contains(string(encoded), `"total":90071992547409.93`), "Money should encode as an exact JSON number, got %s", encoded)
+
+	checks.Done()
+}
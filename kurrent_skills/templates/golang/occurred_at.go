@@ -0,0 +1,192 @@
+// KurrentDB Go Client Example - Business timestamps for imported events
+// Demonstrates: Carrying the time an event really happened in occurredAt metadata, and projecting by it instead of Created
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === OCCURRED AT ===
+
+// WithOccurredAt returns event with occurredAt set to at in its metadata, keeping the other keys.
+// The server stamps Created when the event is appended, which for a migration or backfill is the
+// import's time; occurredAt keeps the original one, whether earlier or, for scheduled events, later.
+func WithOccurredAt(event kurrentdb.EventData, at time.Time) (kurrentdb.EventData, error) {
+	var meta Meta
+	if err := meta.UnmarshalJSON(event.Metadata); err != nil {
+		return event, err
+	}
+	metadata, err := meta.SetOccurredAt(at).Marshal()
+	if err != nil {
+		return event, err
+	}
+	event.Metadata = metadata
+	return event, nil
+}
+
+// EventTime returns when the event happened: its occurredAt, or Created for events written without
+// one. Use it for business time, like windows and reports. Lag and throughput are about when events
+// were written, and keep using Created. Malformed metadata is an error rather than a silent fallback,
+// which would put the event at its import time.
+func EventTime(event *kurrentdb.RecordedEvent) (time.Time, error) {
+	var meta Meta
+	if err := meta.UnmarshalFrom(event); err != nil {
+		return time.Time{}, err
+	}
+	occurredAt, err := meta.OccurredAt()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s@%d: %w", event.StreamID, event.EventNumber, err)
+	}
+	if occurredAt.IsZero() {
+		return event.CreatedDate, nil
+	}
+	return occurredAt, nil
+}
+
+// === DEMO ===
+
+// RunOccurredAt imports orders from an old system with the dates they were placed, places one now,
+// and totals orders per day by event time
+func RunOccurredAt() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	// === IMPORT ===
+	// Rows exported from the legacy order system, with the time each order was placed
+	legacy := []struct {
+		OrderID  string
+		PlacedAt time.Time
+		Amount   float64
+	}{
+		{"L-1001", time.Date(2021, 3, 1, 9, 15, 0, 0, time.UTC), 120},
+		{"L-1002", time.Date(2021, 3, 1, 17, 40, 0, 0, time.UTC), 80},
+		{"L-1003", time.Date(2021, 3, 2, 11, 5, 0, 0, time.UTC), 45},
+		{"L-1004", time.Date(2021, 3, 4, 8, 30, 0, 0, time.UTC), 200},
+	}
+	stream := Streams.Name("orderImport", uuid.New().String())
+	orderPlaced := func(orderID string, amount float64) kurrentdb.EventData {
+		data, _ := json.Marshal(map[string]interface{}{"orderId": orderID, "amount": amount})
+		metadata, _ := NewMeta().SetCorrelation("legacy-import").Marshal()
+		return kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   "OrderPlaced",
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        data,
+			Metadata:    metadata,
+		}
+	}
+
+	fmt.Printf("\n=== Importing %d legacy orders into %s ===\n", len(legacy), stream)
+	var imported []kurrentdb.EventData
+	for _, row := range legacy {
+		event, err := WithOccurredAt(orderPlaced(row.OrderID, row.Amount), row.PlacedAt)
+		if err != nil {
+			panic(err)
+		}
+		imported = append(imported, event)
+	}
+	if _, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{StreamState: kurrentdb.NoStream{}}, imported...); err != nil {
+		panic(err)
+	}
+
+	// A new order, after the migration, has no occurredAt
+	if _, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, orderPlaced("N-1", 60)); err != nil {
+		panic(err)
+	}
+
+	events, _, err := readStreamFrom(ctx, NewClientStore(client), stream, NoVersion)
+	if err != nil {
+		panic(err)
+	}
+	var eventTimes []time.Time
+	for _, event := range events {
+		at, err := EventTime(event)
+		if err != nil {
+			panic(err)
+		}
+		eventTimes = append(eventTimes, at)
+		fmt.Printf("  #%d created %s, occurred %s\n", event.EventNumber, event.CreatedDate.Format(time.RFC3339), at.Format(time.RFC3339))
+	}
+
+	// === DAILY TOTALS ===
+	// Windows use EventTime, so the imports land on the days they were placed. The grace period
+	// lets the import run in any order; the new order closes the legacy days.
+	fmt.Println("\n=== Daily totals by event time ===")
+	daily := NewWindowedProjection("DailyOrderTotals", 24*time.Hour, 7*24*time.Hour).
+		On("OrderPlaced", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			orders, _ := state["orders"].(float64)
+			total, _ := state["total"].(float64)
+			state["orders"] = orders + 1
+			state["total"] = total + data["amount"].(float64)
+			return state
+		})
+	for _, event := range events {
+		if _, err := daily.Apply(event, event.Position); err != nil {
+			panic(err)
+		}
+	}
+	var summary []string
+	for _, window := range daily.Windows() {
+		fmt.Printf("  %s: %v orders, total %v, closed %v\n", window.Start.Format("2006-01-02"), window.State["orders"], window.State["total"], window.Closed)
+		summary = append(summary, fmt.Sprintf("%s:%v/%v", window.Start.Format("2006-01-02"), window.State["orders"], window.State["total"]))
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if len(events) != len(legacy)+1 {
+		fmt.Printf("FAIL: Expected %d events, got %d\n", len(legacy)+1, len(events))
+		passed = false
+	}
+	for i, row := range legacy {
+		if i >= len(events) {
+			break
+		}
+		if !eventTimes[i].Equal(row.PlacedAt) {
+			fmt.Printf("FAIL: Expected %s to have occurred at %s, got %s\n", row.OrderID, row.PlacedAt, eventTimes[i])
+			passed = false
+		}
+		if time.Since(events[i].CreatedDate) > time.Hour {
+			fmt.Printf("FAIL: Expected Created to be the import time, got %s\n", events[i].CreatedDate)
+			passed = false
+		}
+		var meta Meta
+		meta.UnmarshalFrom(events[i])
+		if meta.Correlation() != "legacy-import" {
+			fmt.Printf("FAIL: Expected WithOccurredAt to keep the correlation id, got %v\n", meta)
+			passed = false
+		}
+	}
+	if len(events) == len(legacy)+1 && !eventTimes[len(legacy)].Equal(events[len(legacy)].CreatedDate) {
+		fmt.Println("FAIL: Expected the event without occurredAt to fall back to Created")
+		passed = false
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour).Format("2006-01-02")
+	expected := fmt.Sprint([]string{"2021-03-01:2/200", "2021-03-02:1/45", "2021-03-04:1/200", today + ":1/60"})
+	if fmt.Sprint(summary) != expected {
+		fmt.Printf("FAIL: Expected daily orders/total %s, got %v\n", expected, summary)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll occurred at tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,319 @@
+// KurrentDB Go Client Example - Parallel handling with per-stream ordering
+// Demonstrates: Partitioning events by stream across workers, and randomized checks that each stream stays in order
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === ORDERED DISPATCHER ===
+
+// ErrDispatcherClosed is returned by Dispatch after Close
+var ErrDispatcherClosed = errors.New("dispatcher closed")
+
+// OrderedDispatcher runs a handler on several workers at once while keeping each stream's events
+// in order: events are partitioned by stream, and each partition is handled by one worker in the
+// order Dispatch was called. Streams in different partitions are handled concurrently.
+//
+// Dispatch returns once the event is queued, not handled, so a subscription must not checkpoint
+// the event's position on Dispatch returning; checkpoint after Close (or track completions).
+type OrderedDispatcher struct {
+	handler    func(ctx context.Context, event *kurrentdb.ResolvedEvent) error
+	partitions []chan *kurrentdb.ResolvedEvent
+	// partition picks the queue for an event; swappable to break ordering on purpose
+	partition func(event *kurrentdb.ResolvedEvent) int
+
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewOrderedDispatcher starts workers goroutines, each with a queue of queueSize events. A full
+// queue makes Dispatch wait, which pushes back on the subscription. The first handler error
+// stops the dispatcher: later events are dropped and Dispatch and Close return the error.
+func NewOrderedDispatcher(ctx context.Context, workers, queueSize int, handler func(ctx context.Context, event *kurrentdb.ResolvedEvent) error) *OrderedDispatcher {
+	workers = max(workers, 1)
+	d := &OrderedDispatcher{
+		handler:    handler,
+		partitions: make([]chan *kurrentdb.ResolvedEvent, workers),
+	}
+	d.ctx, d.cancel = context.WithCancelCause(ctx)
+	d.partition = d.streamPartition
+
+	for i := range d.partitions {
+		d.partitions[i] = make(chan *kurrentdb.ResolvedEvent, queueSize)
+		d.wg.Add(1)
+		go d.work(d.partitions[i])
+	}
+	return d
+}
+
+// streamPartition hashes the stream ID, so a stream always lands on the same worker
+func (d *OrderedDispatcher) streamPartition(event *kurrentdb.ResolvedEvent) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(Resolve(event, false).StreamID))
+	return int(hash.Sum32() % uint32(len(d.partitions)))
+}
+
+func (d *OrderedDispatcher) work(queue chan *kurrentdb.ResolvedEvent) {
+	defer d.wg.Done()
+	for event := range queue {
+		if d.ctx.Err() != nil {
+			// Stopped: drain so Dispatch never blocks on a dead worker
+			continue
+		}
+		if err := d.handler(d.ctx, event); err != nil {
+			recorded := Resolve(event, false)
+			d.cancel(fmt.Errorf("handle %s@%d: %w", recorded.StreamID, recorded.EventNumber, err))
+		}
+	}
+}
+
+// Dispatch queues event on its stream's worker. Call it from a single goroutine, such as the
+// subscription loop: the order of calls is the order each stream is handled in. It can be used
+// directly as a MeteredSubscription handler.
+func (d *OrderedDispatcher) Dispatch(event *kurrentdb.ResolvedEvent) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return ErrDispatcherClosed
+	}
+
+	select {
+	case d.partitions[d.partition(event)] <- event:
+		return nil
+	case <-d.ctx.Done():
+		return context.Cause(d.ctx)
+	}
+}
+
+// Close waits for every queued event to be handled and returns the first handler error, or the
+// context's error if it was cancelled and events were dropped
+func (d *OrderedDispatcher) Close() error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, queue := range d.partitions {
+			close(queue)
+		}
+	}
+	d.mu.Unlock()
+
+	d.wg.Wait()
+	err := context.Cause(d.ctx)
+	d.cancel(nil)
+	return err
+}
+
+// === ORDERING CHECK ===
+
+// DispatchOrderingOptions shapes one randomized run of CheckDispatchOrdering
+type DispatchOrderingOptions struct {
+	Streams         int
+	EventsPerStream int
+	Workers         int
+	QueueSize       int
+	// MaxHandlerDelay is the longest a handler sleeps, to shuffle how workers interleave
+	MaxHandlerDelay time.Duration
+
+	// roundRobin spreads events over workers ignoring their stream, which must be caught
+	roundRobin bool
+}
+
+// DispatchOrderingReport is what one run observed
+type DispatchOrderingReport struct {
+	Events int
+	// MaxConcurrent is the most handlers seen running at once: above 1 shows the run was parallel
+	MaxConcurrent int
+	Violations    []string
+}
+
+// CheckDispatchOrdering interleaves opts.Streams streams at random, runs them through an
+// OrderedDispatcher with handlers of random duration, and reports every event handled out of
+// EventNumber order, handled concurrently with another event of its stream, or not exactly once.
+// Run it under -race to also catch unsynchronized state in the dispatcher.
+func CheckDispatchOrdering(rng *rand.Rand, opts DispatchOrderingOptions) (DispatchOrderingReport, error) {
+	// A random merge of the streams: each stream's events keep their order, the rest is shuffled
+	remaining := make([]int, opts.Streams)
+	for i := range remaining {
+		remaining[i] = opts.EventsPerStream
+	}
+	var events []*kurrentdb.ResolvedEvent
+	delays := make(map[*kurrentdb.RecordedEvent]time.Duration)
+	for left := opts.Streams * opts.EventsPerStream; left > 0; left-- {
+		pick := rng.Intn(left)
+		stream := 0
+		for pick >= remaining[stream] {
+			pick -= remaining[stream]
+			stream++
+		}
+		number := uint64(opts.EventsPerStream - remaining[stream])
+		remaining[stream]--
+
+		event := syntheticEvent("order-"+strconv.Itoa(stream), "OrderUpdated", number, uint64(len(events)+1)*100, `{}`)
+		if opts.MaxHandlerDelay > 0 {
+			delays[event] = time.Duration(rng.Int63n(int64(opts.MaxHandlerDelay)))
+		}
+		events = append(events, &kurrentdb.ResolvedEvent{Event: event})
+	}
+
+	var mu sync.Mutex
+	var report DispatchOrderingReport
+	next := make(map[string]uint64)
+	running := make(map[string]bool)
+	concurrent := 0
+	violate := func(format string, args ...interface{}) {
+		report.Violations = append(report.Violations, fmt.Sprintf(format, args...))
+	}
+
+	handler := func(ctx context.Context, resolved *kurrentdb.ResolvedEvent) error {
+		event := Resolve(resolved, false)
+		mu.Lock()
+		if running[event.StreamID] {
+			violate("%s@%d started while another %s event was running", event.StreamID, event.EventNumber, event.StreamID)
+		}
+		if want := next[event.StreamID]; event.EventNumber != want {
+			violate("%s@%d handled when #%d was next", event.StreamID, event.EventNumber, want)
+		}
+		next[event.StreamID] = event.EventNumber + 1
+		running[event.StreamID] = true
+		concurrent++
+		report.MaxConcurrent = max(report.MaxConcurrent, concurrent)
+		report.Events++
+		mu.Unlock()
+
+		time.Sleep(delays[event])
+
+		mu.Lock()
+		running[event.StreamID] = false
+		concurrent--
+		mu.Unlock()
+		return nil
+	}
+
+	dispatcher := NewOrderedDispatcher(context.Background(), opts.Workers, opts.QueueSize, handler)
+	if opts.roundRobin {
+		turn := 0
+		dispatcher.partition = func(*kurrentdb.ResolvedEvent) int {
+			turn++
+			return turn % len(dispatcher.partitions)
+		}
+	}
+	for _, event := range events {
+		if err := dispatcher.Dispatch(event); err != nil {
+			return report, err
+		}
+	}
+	if err := dispatcher.Close(); err != nil {
+		return report, err
+	}
+
+	for stream := 0; stream < opts.Streams; stream++ {
+		id := "order-" + strconv.Itoa(stream)
+		if got := next[id]; got != uint64(opts.EventsPerStream) && len(report.Violations) == 0 {
+			violate("%s: handled up to #%d of %d events", id, got, opts.EventsPerStream)
+		}
+	}
+	if report.Events != len(events) {
+		violate("handled %d events, dispatched %d", report.Events, len(events))
+	}
+	return report, nil
+}
+
+// RunOrderingChecks runs randomized CheckDispatchOrdering iterations, no server required.
+// Usage: ordering-checks [iterations] [seed]; run with `go run -race .` to add the race detector.
+func RunOrderingChecks() {
+	checks := newChecker("dispatch ordering")
+
+	iterations := 200
+	seed := time.Now().UnixNano()
+	if len(os.Args) > 2 {
+		n, err := strconv.Atoi(os.Args[2])
+		if err != nil || n < 1 {
+			panic(fmt.Sprintf("iterations must be a positive integer, got %q", os.Args[2]))
+		}
+		iterations = n
+	}
+	if len(os.Args) > 3 {
+		s, err := strconv.ParseInt(os.Args[3], 10, 64)
+		if err != nil {
+			panic(fmt.Sprintf("seed must be an integer, got %q", os.Args[3]))
+		}
+		seed = s
+	}
+	// Printed so a failing run can be replayed
+	fmt.Printf("Seed %d, %d iterations\n", seed, iterations)
+	rng := rand.New(rand.NewSource(seed))
+
+	randomOptions := func() DispatchOrderingOptions {
+		return DispatchOrderingOptions{
+			Streams:         1 + rng.Intn(12),
+			EventsPerStream: 1 + rng.Intn(20),
+			Workers:         1 + rng.Intn(8),
+			QueueSize:       rng.Intn(5),
+			MaxHandlerDelay: time.Duration(rng.Intn(100)) * time.Microsecond,
+		}
+	}
+
+	// --- Randomized runs ---
+	fmt.Println("\n--- Randomized runs ---")
+	events, parallel := 0, 0
+	for i := 0; i < iterations && checks.Passed(); i++ {
+		opts := randomOptions()
+		report, err := CheckDispatchOrdering(rng, opts)
+		checks.Check(err == nil, "iteration %d %+v failed: %v", i, opts, err)
+		checks.Check(len(report.Violations) == 0, "iteration %d %+v: %v", i, opts, report.Violations)
+		events += report.Events
+		if report.MaxConcurrent > 1 {
+			parallel++
+		}
+	}
+	fmt.Printf("  %d events, %d of %d runs had handlers running in parallel\n", events, parallel, iterations)
+	checks.Check(parallel > 0, "no run handled events in parallel, so ordering was never under test")
+
+	// --- The check catches broken partitioning ---
+	fmt.Println("\n--- Round-robin dispatch is caught ---")
+	broken, err := CheckDispatchOrdering(rng, DispatchOrderingOptions{
+		Streams: 3, EventsPerStream: 50, Workers: 4, QueueSize: 4, MaxHandlerDelay: 200 * time.Microsecond, roundRobin: true,
+	})
+	checks.Check(err == nil, "round-robin run failed: %v", err)
+	if len(broken.Violations) > 0 {
+		fmt.Printf("  %d violations, e.g. %s\n", len(broken.Violations), broken.Violations[0])
+	}
+	checks.Check(len(broken.Violations) > 0, "ignoring streams when dispatching should be reported")
+
+	// --- Handler failure ---
+	fmt.Println("\n--- Handler failure stops the dispatcher ---")
+	errBroken := errors.New("simulated handler failure")
+	failing := NewOrderedDispatcher(context.Background(), 2, 1, func(ctx context.Context, event *kurrentdb.ResolvedEvent) error {
+		if Resolve(event, false).EventNumber == 3 {
+			return errBroken
+		}
+		return nil
+	})
+	var dispatchErr error
+	for i := uint64(0); i < 100 && dispatchErr == nil; i++ {
+		dispatchErr = failing.Dispatch(&kurrentdb.ResolvedEvent{Event: syntheticEvent("order-1", "OrderUpdated", i, (i+1)*100, `{}`)})
+	}
+	closeErr := failing.Close()
+	fmt.Printf("  Close: %v\n", closeErr)
+	checks.Check(errors.Is(closeErr, errBroken), "Close should return the handler error, got %v", closeErr)
+	checks.Check(dispatchErr == nil || errors.Is(dispatchErr, errBroken), "Dispatch should fail with the handler error, got %v", dispatchErr)
+	checks.Check(errors.Is(failing.Dispatch(&kurrentdb.ResolvedEvent{Event: syntheticEvent("order-1", "OrderUpdated", 100, 10100, `{}`)}), ErrDispatcherClosed),
+		"Dispatch after Close should return ErrDispatcherClosed")
+
+	checks.Done()
+}
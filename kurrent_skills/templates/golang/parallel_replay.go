@@ -0,0 +1,724 @@
+// KurrentDB Go Client Example - Parallel projection replay over position ranges of $all
+// Demonstrates: Rebuilding a read model by reading $all in concurrent partitions, merging the partial states, then following live
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === MERGEABLE FOLDS ===
+
+// ErrNotMergeable is returned for a fold without a merge, or one whose partitioned replay differs
+// from its serial replay
+var ErrNotMergeable = errors.New("fold is not mergeable")
+
+// StateMerge combines the states two partitions built under the same key. earlier is from the
+// partition lower in the log; the result may reuse either map.
+type StateMerge func(key string, earlier, later map[string]interface{}) map[string]interface{}
+
+// MergeableFold is a projection that can be replayed in partitions. Each partition folds its
+// slice of the log into a projection of its own, starting from empty state, and Merge then combines
+// the partial states in log order. That only reproduces the serial result when:
+//
+//   - the handlers fold into an accumulation, like a count or a sum, rather than reading the state
+//     to decide what to do: a handler that refuses to ship an order that isn't placed sees no order
+//     when the placement is in an earlier partition
+//   - Merge is associative, so the grouping of partitions doesn't matter, and merging with the empty
+//     state changes nothing
+//
+// Sums, counts, minimums, maximums and set unions qualify; commutative ones also survive reordering
+// within the log. A "latest status" or a running balance that rejects overdrafts does not. The
+// partitioning only sees the order of $all, so events of one stream may land in different partitions.
+// VerifyMergeable checks a fold against sample events before trusting it with a rebuild.
+type MergeableFold struct {
+	Name string
+	// New returns an empty projection with the fold's handlers
+	New   func() *Projection
+	Merge StateMerge
+}
+
+// mergeStates merges the partitions' projections, in log order, into a new projection of the fold
+func (f MergeableFold) mergeStates(partials []*Projection) (*Projection, error) {
+	merged := f.New()
+	for _, partial := range partials {
+		for _, key := range slices.Sorted(maps.Keys(partial.State)) {
+			state := partial.State[key]
+			if existing, ok := merged.State[key]; ok {
+				state = f.Merge(key, existing, state)
+			}
+			merged.State[key] = state
+		}
+		if partial.keys != nil && merged.keys != nil {
+			for key, streams := range partial.keys.streams {
+				for _, streamID := range streams {
+					if _, err := merged.keys.claim(streamID); err != nil {
+						return nil, err
+					}
+					merged.keys.own(key, streamID)
+				}
+			}
+		}
+		merged.processed += partial.processed
+		merged.skipped += partial.skipped
+		merged.failed += partial.failed
+	}
+	return merged, nil
+}
+
+// VerifyMergeable replays events serially and then split into 2 up to partitions contiguous
+// partitions, and returns ErrNotMergeable naming the first key whose merged state differs from the
+// serial one, or the error of a state that can't be encoded. Run it in tests with events that
+// exercise every handler; passing on a sample doesn't prove the fold mergeable, failing proves it isn't.
+func VerifyMergeable(fold MergeableFold, events []*kurrentdb.RecordedEvent, partitions int) error {
+	if fold.Merge == nil {
+		return fmt.Errorf("%w: %s has no merge", ErrNotMergeable, fold.Name)
+	}
+	serial := fold.New()
+	for _, event := range events {
+		serial.Apply(event, event.Position)
+	}
+	want, err := serial.Result()
+	if err != nil {
+		return fmt.Errorf("%s serially: %w", fold.Name, err)
+	}
+
+	for count := 2; count <= partitions; count++ {
+		partials := make([]*Projection, count)
+		for i := range partials {
+			partials[i] = fold.New()
+			for _, event := range events[len(events)*i/count : len(events)*(i+1)/count] {
+				partials[i].Apply(event, event.Position)
+			}
+		}
+		merged, err := fold.mergeStates(partials)
+		if err != nil {
+			return fmt.Errorf("%w: %s in %d partitions: %w", ErrNotMergeable, fold.Name, count, err)
+		}
+		got, err := merged.Result()
+		if err != nil {
+			return fmt.Errorf("%s in %d partitions: %w", fold.Name, count, err)
+		}
+		for _, key := range slices.Sorted(maps.Keys(want)) {
+			if !reflect.DeepEqual(got[key], want[key]) {
+				return fmt.Errorf("%w: %s in %d partitions: %s is %v, serially %v", ErrNotMergeable, fold.Name, count, key, got[key], want[key])
+			}
+		}
+		if len(got) != len(want) {
+			return fmt.Errorf("%w: %s in %d partitions: %d keys, serially %d", ErrNotMergeable, fold.Name, count, len(got), len(want))
+		}
+	}
+	return nil
+}
+
+// === PARALLEL REPLAY ===
+
+// replayPageSize is how many events each partition reads per request
+const replayPageSize = 500
+
+// ParallelReplayOptions configures ParallelReplay
+type ParallelReplayOptions struct {
+	// Partitions is how many ranges of $all are read at once; 1 replays serially
+	Partitions int
+	// Boundaries are the positions the partitions after the first start at, in order. Nil splits the
+	// commit range up to the head evenly and snaps each split to the next event, one short read per
+	// partition, so ranges hold about as many bytes; pass the Boundaries of an earlier result to
+	// reuse its split. A boundary needn't fall on an event, as a partition skips anything before its
+	// start, but a server that refuses to read from between records needs real event positions.
+	Boundaries []kurrentdb.Position
+}
+
+// ReplayPartition is what one partition read
+type ReplayPartition struct {
+	From kurrentdb.Position
+	// To is where the next partition starts, or the head for the last one
+	To      kurrentdb.Position
+	Events  int
+	Elapsed time.Duration
+}
+
+// ParallelReplayResult is the rebuilt projection and how the log was split to build it
+type ParallelReplayResult struct {
+	Projection *Projection
+	// Head is the position of the last event replayed; follow live from it
+	Head       kurrentdb.Position
+	Partitions []ReplayPartition
+	Elapsed    time.Duration
+}
+
+// Boundaries returns where the partitions after the first started, to split a later replay the same way
+func (r *ParallelReplayResult) Boundaries() []kurrentdb.Position {
+	var bounds []kurrentdb.Position
+	for _, partition := range r.Partitions[min(1, len(r.Partitions)):] {
+		bounds = append(bounds, partition.From)
+	}
+	return bounds
+}
+
+// ParallelReplay rebuilds fold from $all up to its current head, reading the partitions' ranges
+// concurrently and merging their states, and returns the projection checkpointed at the head.
+// Events appended after the head is read are left for the live subscription, started from
+// Head with Follow. Events that fail to apply are skipped and counted, as Follow does. It returns
+// ErrNotMergeable for a fold without a merge, and the first read error of any partition.
+func ParallelReplay(ctx context.Context, store EventStore, fold MergeableFold, options ParallelReplayOptions) (*ParallelReplayResult, error) {
+	if fold.Merge == nil {
+		return nil, fmt.Errorf("%w: %s has no merge", ErrNotMergeable, fold.Name)
+	}
+	started := time.Now()
+	head, empty, err := readAllHead(ctx, store)
+	if err != nil {
+		return nil, fmt.Errorf("read the head of $all: %w", err)
+	}
+	if empty {
+		return &ParallelReplayResult{Projection: fold.New(), Elapsed: time.Since(started)}, nil
+	}
+
+	bounds := options.Boundaries
+	if bounds == nil {
+		if bounds, err = estimateBoundaries(ctx, store, head, max(options.Partitions, 1)); err != nil {
+			return nil, fmt.Errorf("estimate the boundaries: %w", err)
+		}
+	}
+	ranges := make([]ReplayPartition, len(bounds)+1)
+	for i := range ranges {
+		if i > 0 {
+			ranges[i].From = bounds[i-1]
+		}
+		ranges[i].To = head
+		if i < len(bounds) {
+			ranges[i].To = bounds[i]
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	partials := make([]*Projection, len(ranges))
+	errs := make([]error, len(ranges))
+	var wg sync.WaitGroup
+	for i := range ranges {
+		partials[i] = fold.New()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			partitionStarted := time.Now()
+			last := i == len(ranges)-1
+			ranges[i].Events, errs[i] = replayRange(ctx, store, partials[i], ranges[i].From, ranges[i].To, last)
+			ranges[i].Elapsed = time.Since(partitionStarted)
+			if errs[i] != nil {
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return nil, fmt.Errorf("partition %d from %d: %w", i, ranges[i].From.Commit, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	merged, err := fold.mergeStates(partials)
+	if err != nil {
+		return nil, err
+	}
+	merged.Advance(head)
+	return &ParallelReplayResult{Projection: merged, Head: head, Partitions: ranges, Elapsed: time.Since(started)}, nil
+}
+
+// estimateBoundaries splits the commit range from the first event of $all to head into count
+// ranges by arithmetic, then snaps each split to the first event at or after it with a one-event
+// read, so it costs count reads of one event however long $all is. Commit positions grow with the
+// bytes written, so the ranges hold about as much data rather than exactly as many events. A split
+// the store won't read from is dropped, leaving its range to the partition before.
+func estimateBoundaries(ctx context.Context, store EventStore, head kurrentdb.Position, count int) ([]kurrentdb.Position, error) {
+	if count < 2 {
+		return nil, nil
+	}
+	first, found, err := firstEventFrom(ctx, store, kurrentdb.Start{})
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var bounds []kurrentdb.Position
+	for i := 1; i < count; i++ {
+		estimate := first.Commit + (head.Commit-first.Commit)*uint64(i)/uint64(count)
+		position, found, err := firstEventFrom(ctx, store, kurrentdb.Position{Commit: estimate, Prepare: estimate})
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			fmt.Printf("  [replay] no partition from %d: %v\n", estimate, err)
+			continue
+		}
+		if !found || !positionAfter(position, first) || positionAfter(position, head) ||
+			(len(bounds) > 0 && !positionAfter(position, bounds[len(bounds)-1])) {
+			continue
+		}
+		bounds = append(bounds, position)
+	}
+	return bounds, nil
+}
+
+// firstEventFrom returns the position of the first event of $all at or after from, and false if
+// there is none
+func firstEventFrom(ctx context.Context, store EventStore, from kurrentdb.AllPosition) (kurrentdb.Position, bool, error) {
+	reader, err := store.ReadAll(ctx, kurrentdb.ReadAllOptions{From: from}, 1)
+	if err != nil {
+		return kurrentdb.Position{}, false, err
+	}
+	defer reader.Close()
+
+	event, err := reader.Recv()
+	if errors.Is(err, io.EOF) {
+		return kurrentdb.Position{}, false, nil
+	}
+	if err != nil {
+		return kurrentdb.Position{}, false, err
+	}
+	return event.OriginalEvent().Position, true, nil
+}
+
+// replayRange applies the events from from up to, not including, to, or including it when
+// inclusive. It returns how many events it read.
+func replayRange(ctx context.Context, store EventStore, projection *Projection, from, to kurrentdb.Position, inclusive bool) (int, error) {
+	read := 0
+	err := readAllPages(ctx, store, from, func(event *kurrentdb.RecordedEvent) bool {
+		position := event.Position
+		if positionAfter(from, position) {
+			// A boundary between records: the first page can start before it
+			return true
+		}
+		if positionAfter(position, to) || (!inclusive && position == to) {
+			return false
+		}
+		read++
+		if _, err := projection.Apply(event, position); err != nil {
+			fmt.Printf("  Skipped: %v\n", err)
+		}
+		return true
+	})
+	return read, err
+}
+
+// readAllPages reads $all forwards from from (the zero position for its start) a page at a time,
+// calling visit with each event once until visit returns false or $all ends
+func readAllPages(ctx context.Context, store EventStore, from kurrentdb.Position, visit func(event *kurrentdb.RecordedEvent) bool) error {
+	var start kurrentdb.AllPosition = kurrentdb.Start{}
+	if from != (kurrentdb.Position{}) {
+		start = from
+	}
+	var previous *kurrentdb.Position
+	for {
+		reader, err := store.ReadAll(ctx, kurrentdb.ReadAllOptions{From: start}, replayPageSize)
+		if err != nil {
+			return err
+		}
+		page := 0
+		for {
+			resolved, err := reader.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				reader.Close()
+				return err
+			}
+			page++
+			event := resolved.OriginalEvent()
+			position := event.Position
+			if previous != nil && !positionAfter(position, *previous) {
+				// The page starts at the event that ended the last one
+				continue
+			}
+			previous = &position
+			if !visit(event) {
+				reader.Close()
+				return nil
+			}
+		}
+		reader.Close()
+		if page < replayPageSize || previous == nil {
+			return nil
+		}
+		start = *previous
+	}
+}
+
+// === ACCOUNT TOTALS ===
+
+// NewAccountTotalsFold sums deposits and withdrawals per account stream. Every handler adds to the
+// state, never reads it to decide, so the partial totals of any split of the log add up.
+func NewAccountTotalsFold() MergeableFold {
+	add := func(field string) EventHandler {
+		return func(state, data map[string]interface{}) map[string]interface{} {
+			amount, _ := data["amount"].(float64)
+			state[field] = toFloat(state[field]) + amount
+			state["transactions"] = toFloat(state["transactions"]) + 1
+			return state
+		}
+	}
+	return MergeableFold{
+		Name: "account-totals",
+		New: func() *Projection {
+			return NewProjection("account-totals").On("FundsDeposited", add("deposited")).On("FundsWithdrawn", add("withdrawn"))
+		},
+		Merge: func(key string, earlier, later map[string]interface{}) map[string]interface{} {
+			for _, field := range []string{"deposited", "withdrawn", "transactions"} {
+				if _, ok := later[field]; ok {
+					earlier[field] = toFloat(earlier[field]) + toFloat(later[field])
+				}
+			}
+			return earlier
+		},
+	}
+}
+
+// newAccountStatusFold counts the deposits accepted before an account was frozen. Its handler reads
+// the state to decide: a partition that starts after the freeze doesn't know about it and accepts
+// the deposits, so no merge can recover the serial count.
+func newAccountStatusFold() MergeableFold {
+	return MergeableFold{
+		Name: "account-status",
+		New: func() *Projection {
+			return NewProjection("account-status").
+				On("AccountFrozen", func(state, data map[string]interface{}) map[string]interface{} {
+					state["frozen"] = true
+					return state
+				}).
+				On("FundsDeposited", func(state, data map[string]interface{}) map[string]interface{} {
+					if state["frozen"] != true {
+						state["accepted"] = toFloat(state["accepted"]) + 1
+					}
+					return state
+				})
+		},
+		Merge: func(key string, earlier, later map[string]interface{}) map[string]interface{} {
+			if later["frozen"] == true {
+				earlier["frozen"] = true
+			}
+			earlier["accepted"] = toFloat(earlier["accepted"]) + toFloat(later["accepted"])
+			return earlier
+		},
+	}
+}
+
+// toFloat reads a number from state, treating a missing one as 0
+func toFloat(value interface{}) float64 {
+	number, _ := value.(float64)
+	return number
+}
+
+// accountEvents returns count deposits and withdrawals spread over accounts streams under prefix
+func accountEvents(prefix string, accounts, count int) map[string][]kurrentdb.EventData {
+	byStream := make(map[string][]kurrentdb.EventData)
+	for i := 0; i < count; i++ {
+		stream := Streams.Name("account", fmt.Sprintf("%s%d", prefix, i%accounts))
+		eventType := "FundsDeposited"
+		if i%3 == 2 {
+			eventType = "FundsWithdrawn"
+		}
+		byStream[stream] = append(byStream[stream], kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(fmt.Sprintf(`{"amount":%d}`, i%50+1)),
+		})
+	}
+	return byStream
+}
+
+// pagedLatencyStore delays every ReadAll call by latency, standing in for a round trip per page
+type pagedLatencyStore struct {
+	EventStore
+	latency time.Duration
+}
+
+func (s pagedLatencyStore) ReadAll(ctx context.Context, options kurrentdb.ReadAllOptions, count uint64) (EventReader, error) {
+	if options.Direction != kurrentdb.Backwards {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(s.latency):
+		}
+	}
+	return s.EventStore.ReadAll(ctx, options, count)
+}
+
+// readCountingStore counts the events every ReadAll delivers
+type readCountingStore struct {
+	EventStore
+	read *atomic.Int64
+}
+
+func (s readCountingStore) ReadAll(ctx context.Context, options kurrentdb.ReadAllOptions, count uint64) (EventReader, error) {
+	reader, err := s.EventStore.ReadAll(ctx, options, count)
+	if err != nil {
+		return nil, err
+	}
+	return readCountingReader{EventReader: reader, read: s.read}, nil
+}
+
+type readCountingReader struct {
+	EventReader
+	read *atomic.Int64
+}
+
+func (r readCountingReader) Recv() (*kurrentdb.ResolvedEvent, error) {
+	event, err := r.EventReader.Recv()
+	if err == nil {
+		r.read.Add(1)
+	}
+	return event, err
+}
+
+// === CHECKS ===
+
+// RunParallelReplayChecks compares parallel replays against serial ones over an in-memory log
+func RunParallelReplayChecks() {
+	checks := newChecker("parallel replay")
+
+	ctx := context.Background()
+	fold := NewAccountTotalsFold()
+	stateOf := func(projection *Projection) map[string]any {
+		result, err := projection.Result()
+		checks.Check(err == nil, "the result of %s should encode: %v", projection.Name, err)
+		return result
+	}
+
+	store := NewMemoryEventStore()
+	// Interleave the streams in $all, a few events per append
+	byStream := accountEvents("a", 7, 2400)
+	for round := 0; ; round++ {
+		appended := false
+		for _, stream := range slices.Sorted(maps.Keys(byStream)) {
+			events := byStream[stream]
+			if round*3 >= len(events) {
+				continue
+			}
+			store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, events[round*3:min(round*3+3, len(events))]...)
+			appended = true
+		}
+		if !appended {
+			break
+		}
+	}
+
+	fmt.Println("\n--- Matches serial replay ---")
+	serial, err := ParallelReplay(ctx, store, fold, ParallelReplayOptions{Partitions: 1})
+	checks.Check(err == nil && len(serial.Projection.State) == 7, "the serial replay should build 7 accounts, got %v", err)
+	want := stateOf(serial.Projection)
+	for _, partitions := range []int{2, 3, 4, 8, 5000} {
+		parallel, err := ParallelReplay(ctx, store, fold, ParallelReplayOptions{Partitions: partitions})
+		if err != nil {
+			checks.Check(false, "%d partitions should replay: %v", partitions, err)
+			continue
+		}
+		checks.Check(reflect.DeepEqual(stateOf(parallel.Projection), want), "%d partitions should match the serial replay, got %v want %v",
+			partitions, stateOf(parallel.Projection), want)
+		total := 0
+		for _, partition := range parallel.Partitions {
+			total += partition.Events
+		}
+		checks.Check(total == 2400, "%d partitions should read every event exactly once, got %d", partitions, total)
+		checks.Check(parallel.Head == serial.Head && *parallel.Projection.Checkpoint == serial.Head,
+			"%d partitions should checkpoint at the head", partitions)
+	}
+	account := serial.Projection.Get(Streams.Name("account", "a0"))
+	fmt.Printf("  account-a0: %v\n", account)
+	checks.Check(account["transactions"] == float64(343), "account a0 should have 343 transactions, got %v", account["transactions"])
+
+	fmt.Println("\n--- Estimated boundaries ---")
+	var positions []kurrentdb.Position
+	readAllPages(ctx, store, kurrentdb.Position{}, func(event *kurrentdb.RecordedEvent) bool {
+		positions = append(positions, event.Position)
+		return true
+	})
+	var read atomic.Int64
+	estimated, err := ParallelReplay(ctx, readCountingStore{EventStore: store, read: &read}, fold, ParallelReplayOptions{Partitions: 4})
+	checks.Check(err == nil && len(estimated.Partitions) == 4, "4 partitions should be estimated, got %v", err)
+	fmt.Printf("  %d events read to replay %d\n", read.Load(), len(positions))
+	// Each partition reads one event past its range and one again at each page start; estimating
+	// the splits adds one event per partition, where sampling $all would add the whole log
+	checks.Check(read.Load() <= int64(len(positions))+20, "estimating the boundaries shouldn't read $all again, read %d events for %d", read.Load(), len(positions))
+	if err == nil {
+		for i, partition := range estimated.Partitions {
+			fmt.Printf("  partition %d: %d events from %d\n", i, partition.Events, partition.From.Commit)
+			checks.Check(i == 0 || slices.Contains(positions, partition.From), "partition %d should start at an event, got %v", i, partition.From)
+			checks.Check(partition.Events >= 590 && partition.Events <= 610, "partition %d should have about 600 events, got %d", i, partition.Events)
+		}
+	}
+
+	uneven, err := ParallelReplay(ctx, store, fold, ParallelReplayOptions{Boundaries: []kurrentdb.Position{
+		{Commit: 150, Prepare: 150}, {Commit: 100_000, Prepare: 100_000}, {Commit: 100_050, Prepare: 100_050},
+	}})
+	checks.Check(err == nil && reflect.DeepEqual(stateOf(uneven.Projection), want),
+		"boundaries between records and uneven ranges should still match the serial replay: %v", err)
+	if err == nil {
+		checks.Check(uneven.Partitions[0].Events == 1 && uneven.Partitions[2].Events == 1,
+			"the boundaries should split off single events, got %+v", uneven.Partitions)
+	}
+
+	fmt.Println("\n--- Faster ---")
+	slow := pagedLatencyStore{EventStore: store, latency: 20 * time.Millisecond}
+	serialSlow, _ := ParallelReplay(ctx, slow, fold, ParallelReplayOptions{Partitions: 1})
+	// Boundaries kept from an earlier rebuild, so this one doesn't estimate them again
+	split, _ := ParallelReplay(ctx, store, fold, ParallelReplayOptions{Partitions: 5})
+	parallelSlow, err := ParallelReplay(ctx, slow, fold, ParallelReplayOptions{Boundaries: split.Boundaries()})
+	checks.Check(err == nil, "the slow replay should succeed: %v", err)
+	if err == nil {
+		speedup := float64(serialSlow.Elapsed) / float64(parallelSlow.Elapsed)
+		fmt.Printf("  serial %s, 5 partitions %s: %.1fx\n", serialSlow.Elapsed.Round(time.Millisecond), parallelSlow.Elapsed.Round(time.Millisecond), speedup)
+		checks.Check(speedup > 1.5, "5 partitions should beat the serial replay, got %.1fx", speedup)
+		checks.Check(reflect.DeepEqual(stateOf(parallelSlow.Projection), want), "the slow replay should match too")
+	}
+
+	fmt.Println("\n--- Then following live ---")
+	live, _ := ParallelReplay(ctx, store, fold, ParallelReplayOptions{Partitions: 4})
+	a0 := Streams.Name("account", "a0")
+	store.AppendToStream(ctx, a0, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+		EventID: uuid.New(), EventType: "FundsDeposited", ContentType: kurrentdb.ContentTypeJson, Data: []byte(`{"amount":1000}`),
+	})
+	followCtx, cancelFollow := context.WithTimeout(ctx, 5*time.Second)
+	err = live.Projection.Follow(followCtx, store, kurrentdb.SubscribeToAllOptions{From: live.Head}, func(*kurrentdb.RecordedEvent) bool { return true })
+	cancelFollow()
+	checks.Check(err == nil, "following from the head should pick up the new deposit: %v", err)
+	checks.Check(live.Projection.Get(a0)["transactions"] == float64(344) && live.Projection.Get(a0)["deposited"] == toFloat(account["deposited"])+1000,
+		"the deposit after the head should be applied once, got %v", live.Projection.Get(a0))
+
+	fmt.Println("\n--- Only mergeable folds ---")
+	_, err = ParallelReplay(ctx, store, MergeableFold{Name: "no-merge", New: fold.New}, ParallelReplayOptions{Partitions: 2})
+	checks.Check(errors.Is(err, ErrNotMergeable), "a fold without a merge should be refused, got %v", err)
+
+	var sample []*kurrentdb.RecordedEvent
+	sample = append(sample,
+		syntheticEvent("account-x", "FundsDeposited", 0, 100, `{"amount":10}`),
+		syntheticEvent("account-x", "AccountFrozen", 1, 200, `{}`),
+		syntheticEvent("account-x", "FundsDeposited", 2, 300, `{"amount":5}`),
+		syntheticEvent("account-x", "FundsDeposited", 3, 400, `{"amount":5}`),
+	)
+	err = VerifyMergeable(newAccountStatusFold(), sample, 4)
+	fmt.Printf("  %v\n", err)
+	checks.Check(errors.Is(err, ErrNotMergeable), "a fold whose handlers read the state should fail verification, got %v", err)
+	checks.Check(VerifyMergeable(fold, sample, 4) == nil, "the totals fold should pass verification: %v", VerifyMergeable(fold, sample, 4))
+
+	fmt.Println("\n--- Empty log ---")
+	empty, err := ParallelReplay(ctx, NewMemoryEventStore(), fold, ParallelReplayOptions{Partitions: 4})
+	checks.Check(err == nil && len(empty.Projection.State) == 0 && empty.Projection.Checkpoint == nil,
+		"an empty log should replay to an empty projection, got %v", err)
+
+	checks.Done()
+}
+
+// === DEMO ===
+
+// RunParallelReplay writes a large log of account events, then rebuilds the totals serially and in
+// partitions and compares them
+func RunParallelReplay() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	store := NewClientStore(client)
+	prefix := uuid.New().String()[:8]
+	const accounts, count = 50, 20000
+	byStream := accountEvents(prefix, accounts, count)
+	var appendErr error
+	for _, stream := range slices.Sorted(maps.Keys(byStream)) {
+		events := byStream[stream]
+		for start := 0; start < len(events) && appendErr == nil; start += 100 {
+			_, appendErr = store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, events[start:min(start+100, len(events))]...)
+		}
+	}
+	fmt.Printf("Wrote %d events to %d accounts\n", count, accounts)
+
+	fold := NewAccountTotalsFold()
+	serial, serialErr := ParallelReplay(ctx, store, fold, ParallelReplayOptions{Partitions: 1})
+	estimated, parallelErr := ParallelReplay(ctx, store, fold, ParallelReplayOptions{Partitions: 8})
+	// A later rebuild can reuse the same split
+	var parallel *ParallelReplayResult
+	if parallelErr == nil {
+		parallel, parallelErr = ParallelReplay(ctx, store, fold, ParallelReplayOptions{Boundaries: estimated.Boundaries()})
+	}
+	if serialErr == nil && parallelErr == nil {
+		fmt.Printf("Serial replay:   %s\n", serial.Elapsed.Round(time.Millisecond))
+		fmt.Printf("Parallel replay: %s with 8 partitions, estimating the boundaries first\n", estimated.Elapsed.Round(time.Millisecond))
+		fmt.Printf("Parallel replay: %s with the same boundaries (%.1fx)\n",
+			parallel.Elapsed.Round(time.Millisecond), float64(serial.Elapsed)/float64(parallel.Elapsed))
+		for i, partition := range parallel.Partitions {
+			fmt.Printf("  partition %d: %6d events from %d in %s\n", i, partition.Events, partition.From.Commit, partition.Elapsed.Round(time.Millisecond))
+		}
+	}
+
+	// Follow live from the merged checkpoint
+	var liveErr error
+	first := Streams.Name("account", prefix+"0")
+	if parallelErr == nil {
+		_, liveErr = store.AppendToStream(ctx, first, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID: uuid.New(), EventType: "FundsDeposited", ContentType: kurrentdb.ContentTypeJson, Data: []byte(`{"amount":1000}`),
+		})
+		if liveErr == nil {
+			followCtx, cancelFollow := context.WithTimeout(ctx, 10*time.Second)
+			liveErr = parallel.Projection.Follow(followCtx, store, kurrentdb.SubscribeToAllOptions{From: parallel.Head},
+				func(event *kurrentdb.RecordedEvent) bool { return event.StreamID == first })
+			cancelFollow()
+		}
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+	if appendErr != nil || serialErr != nil || parallelErr != nil {
+		fmt.Printf("FAIL: Writing and replaying should succeed, got %v / %v / %v\n", appendErr, serialErr, parallelErr)
+		passed = false
+	} else {
+		for i := 0; i < accounts; i++ {
+			stream := Streams.Name("account", fmt.Sprintf("%s%d", prefix, i))
+			want, got := serial.Projection.Get(stream), parallel.Projection.Get(stream)
+			if i == 0 {
+				want = maps.Clone(want)
+				want["deposited"] = toFloat(want["deposited"]) + 1000
+				want["transactions"] = toFloat(want["transactions"]) + 1
+			}
+			if !reflect.DeepEqual(got, want) {
+				fmt.Printf("FAIL: %s should match the serial replay, got %v want %v\n", stream, got, want)
+				passed = false
+				break
+			}
+		}
+		if got := parallel.Projection.Get(Streams.Name("account", prefix+"1"))["transactions"]; got != float64(count/accounts) {
+			fmt.Printf("FAIL: Each account should have %d transactions, got %v\n", count/accounts, got)
+			passed = false
+		}
+	}
+	if liveErr != nil {
+		fmt.Printf("FAIL: Following from the merged checkpoint should apply the new deposit, got %v\n", liveErr)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll parallel replay tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,359 @@
+// KurrentDB Go Client Example - Draining a persistent subscription consumer on shutdown
+// Demonstrates: Stopping mid-batch without leaving any received event unacked, so the group redelivers at once instead of after its message timeout
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === IN-MEMORY SUBSCRIPTION ===
+
+// memoryPersistentSubscription delivers a fixed list of events and records how each was settled.
+// It doesn't redeliver nacked events, so every delivery can be checked for exactly one settlement.
+type memoryPersistentSubscription struct {
+	mu        sync.Mutex
+	pending   []*kurrentdb.ResolvedEvent
+	delivered []uuid.UUID
+	settled   map[uuid.UUID][]string
+	ackErr    error
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newMemoryPersistentSubscription(stream string, count int) *memoryPersistentSubscription {
+	s := &memoryPersistentSubscription{settled: make(map[uuid.UUID][]string), closed: make(chan struct{})}
+	for i := 0; i < count; i++ {
+		data := fmt.Sprintf(`{"sequence":%d}`, i)
+		s.pending = append(s.pending, &kurrentdb.ResolvedEvent{Event: syntheticEvent(stream, "OrderUpdated", uint64(i), uint64(i+1), data)})
+	}
+	return s
+}
+
+func (s *memoryPersistentSubscription) Recv() *kurrentdb.PersistentSubscriptionEvent {
+	s.mu.Lock()
+	select {
+	case <-s.closed:
+		s.mu.Unlock()
+		return &kurrentdb.PersistentSubscriptionEvent{SubscriptionDropped: &kurrentdb.SubscriptionDropped{}}
+	default:
+	}
+	if len(s.pending) > 0 {
+		event := s.pending[0]
+		s.pending = s.pending[1:]
+		s.delivered = append(s.delivered, event.Event.EventID)
+		s.mu.Unlock()
+		return &kurrentdb.PersistentSubscriptionEvent{EventAppeared: &kurrentdb.EventAppeared{Event: event}}
+	}
+	s.mu.Unlock()
+
+	// Nothing left: wait for the consumer to close, as a caught-up subscription would
+	<-s.closed
+	return &kurrentdb.PersistentSubscriptionEvent{SubscriptionDropped: &kurrentdb.SubscriptionDropped{}}
+}
+
+func (s *memoryPersistentSubscription) Ack(messages ...*kurrentdb.ResolvedEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ackErr != nil {
+		return s.ackErr
+	}
+	for _, message := range messages {
+		s.settled[message.Event.EventID] = append(s.settled[message.Event.EventID], "ack")
+	}
+	return nil
+}
+
+func (s *memoryPersistentSubscription) Nack(reason string, action kurrentdb.NackAction, messages ...*kurrentdb.ResolvedEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, message := range messages {
+		s.settled[message.Event.EventID] = append(s.settled[message.Event.EventID], "nack:"+nackActionName(action))
+	}
+	return nil
+}
+
+func (s *memoryPersistentSubscription) Close() error {
+	s.closeOnce.Do(func() { close(s.closed) })
+	return nil
+}
+
+// unsettled returns the delivered events not settled exactly once, and how many were delivered
+func (s *memoryPersistentSubscription) unsettled() (problems []string, delivered int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range s.delivered {
+		if outcomes := s.settled[id]; len(outcomes) != 1 {
+			problems = append(problems, fmt.Sprintf("%s settled %v", id.String()[:8], outcomes))
+		}
+	}
+	return problems, len(s.delivered)
+}
+
+// === CHECKS ===
+
+// RunPersistentDrainChecks shuts processors down mid-batch on an in-memory subscription and checks
+// every delivered event is acked or nacked exactly once. No server required.
+func RunPersistentDrainChecks() {
+	checks := newChecker("persistent drain")
+
+	sequenceOf := func(event *kurrentdb.ResolvedEvent) int {
+		var payload struct {
+			Sequence int `json:"sequence"`
+		}
+		json.Unmarshal(event.Event.Data, &payload)
+		return payload.Sequence
+	}
+	start := func(subscription *memoryPersistentSubscription, options PersistentProcessorOptions, handler PersistentHandler) (*PersistentProcessor, chan error, context.CancelFunc) {
+		processor := newPersistentProcessor(func(context.Context) (persistentEvents, error) { return subscription, nil }, options)
+		ctx, cancel := context.WithCancel(context.Background())
+		runDone := make(chan error, 1)
+		go func() { runDone <- processor.Run(ctx, handler) }()
+		return processor, runDone, cancel
+	}
+	waitForAcks := func(processor *PersistentProcessor, acked int) {
+		for deadline := time.Now().Add(5 * time.Second); processor.Stats().Acked < acked && time.Now().Before(deadline); {
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	fmt.Println("\n--- Drained within the timeout ---")
+	subscription := newMemoryPersistentSubscription("order-drain", 8)
+	var mu sync.Mutex
+	var startedSequences []int
+	processor, runDone, cancel := start(subscription, PersistentProcessorOptions{Concurrency: 4}, func(ctx context.Context, event *kurrentdb.ResolvedEvent, retryCount int) error {
+		mu.Lock()
+		startedSequences = append(startedSequences, sequenceOf(event))
+		mu.Unlock()
+		select {
+		case <-time.After(100 * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		mu.Lock()
+		n := len(startedSequences)
+		mu.Unlock()
+		if n == 4 {
+			break
+		}
+	}
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	shutdownErr := processor.Shutdown(shutdownCtx)
+	shutdownCancel()
+	runErr := <-runDone
+	cancel()
+	stats := processor.Stats()
+	problems, delivered := subscription.unsettled()
+	fmt.Printf("  %+v, %d delivered\n", stats, delivered)
+	checks.Check(shutdownErr == nil && runErr == nil, "a drain within the timeout should succeed, got %v / %v", shutdownErr, runErr)
+	checks.Check(stats.Acked == 4 && len(startedSequences) == 4, "the 4 in-flight handlers should finish and be acked, got %+v after starting %v", stats, startedSequences)
+	checks.Check(stats.Requeued == delivered-4, "events received but not started should be nacked for retry, got %+v of %d", stats, delivered)
+	checks.Check(len(problems) == 0, "every delivered event should be settled exactly once, got %v", problems)
+
+	fmt.Println("\n--- Cut off mid-batch ---")
+	// Every third event hangs, until the three slots are all taken by hanging handlers
+	subscription = newMemoryPersistentSubscription("order-cut", 12)
+	var cancelled int
+	processor, runDone, cancel = start(subscription, PersistentProcessorOptions{Concurrency: 3}, func(ctx context.Context, event *kurrentdb.ResolvedEvent, retryCount int) error {
+		if sequenceOf(event)%3 == 2 {
+			<-ctx.Done()
+			mu.Lock()
+			cancelled++
+			mu.Unlock()
+			return ctx.Err()
+		}
+		return nil
+	})
+	waitForAcks(processor, 6)
+	time.Sleep(20 * time.Millisecond)
+	shutdownCtx, shutdownCancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	started := time.Now()
+	shutdownErr = processor.Shutdown(shutdownCtx)
+	shutdownCancel()
+	runErr = <-runDone
+	cancel()
+	stats = processor.Stats()
+	problems, delivered = subscription.unsettled()
+	fmt.Printf("  %+v, %d delivered, shutdown took %s\n", stats, delivered, time.Since(started).Round(time.Millisecond))
+	checks.Check(errors.Is(shutdownErr, context.DeadlineExceeded) && runErr == nil, "a drain cut off should return the deadline, got %v / %v", shutdownErr, runErr)
+	checks.Check(stats.Acked == 6 && cancelled == 3, "the completed events should be acked and the hanging handlers cancelled, got %+v, %d cancelled", stats, cancelled)
+	checks.Check(stats.Requeued == delivered-6 && delivered == 12, "cut-off and unstarted events should be nacked for retry, got %+v of %d", stats, delivered)
+	checks.Check(len(problems) == 0, "every delivered event should be settled exactly once, got %v", problems)
+	checks.Check(time.Since(started) < time.Second, "shutdown should be bounded by its timeout")
+
+	fmt.Println("\n--- Cancelled without Shutdown ---")
+	// Cancelling Run's context leaves in-flight events to the server's message timeout
+	subscription = newMemoryPersistentSubscription("order-cancel", 4)
+	processor, runDone, cancel = start(subscription, PersistentProcessorOptions{Concurrency: 2}, func(ctx context.Context, event *kurrentdb.ResolvedEvent, retryCount int) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	runErr = <-runDone
+	problems, _ = subscription.unsettled()
+	checks.Check(runErr == nil && processor.Stats() == (PersistentProcessorStats{}) && len(problems) >= 2, "cancelling should settle nothing, got %+v, %v", processor.Stats(), problems)
+
+	fmt.Println("\n--- Failed ack ---")
+	subscription = newMemoryPersistentSubscription("order-ack", 3)
+	subscription.ackErr = errors.New("connection reset")
+	processor, runDone, cancel = start(subscription, PersistentProcessorOptions{}, func(ctx context.Context, event *kurrentdb.ResolvedEvent, retryCount int) error {
+		return nil
+	})
+	select {
+	case runErr = <-runDone:
+	case <-time.After(5 * time.Second):
+		runErr = errors.New("still running")
+	}
+	cancel()
+	fmt.Printf("  %v\n", runErr)
+	checks.Check(runErr != nil && errors.Is(runErr, subscription.ackErr), "a failed ack should stop Run with its error, got %v", runErr)
+
+	checks.Done()
+}
+
+// === DEMO ===
+
+// RunPersistentDrain shuts a consumer down mid-batch, then starts another that picks up the
+// requeued events at once rather than after the group's 30s message timeout
+func RunPersistentDrain() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	streamName := Streams.Name("order", uuid.New().String())
+	groupName := "drain-demo"
+
+	// === CREATE GROUP ===
+	settings := kurrentdb.SubscriptionSettingsDefault()
+	settings.MessageTimeout = 30_000
+	settings.MaxRetryCount = 5
+	if err := client.CreatePersistentSubscription(ctx, streamName, groupName, kurrentdb.PersistentStreamSubscriptionOptions{
+		Settings:  &settings,
+		StartFrom: kurrentdb.Start{},
+	}); err != nil {
+		panic(err)
+	}
+	defer client.DeletePersistentSubscription(context.Background(), streamName, groupName, kurrentdb.DeletePersistentSubscriptionOptions{})
+
+	// === APPEND EVENTS ===
+	const total = 12
+	var events []kurrentdb.EventData
+	for i := 0; i < total; i++ {
+		data, _ := json.Marshal(map[string]interface{}{"sequence": i})
+		events = append(events, kurrentdb.EventData{EventID: uuid.New(), EventType: "OrderUpdated", ContentType: kurrentdb.ContentTypeJson, Data: data})
+	}
+	if _, err := client.AppendToStream(ctx, streamName, kurrentdb.AppendToStreamOptions{}, events...); err != nil {
+		panic(err)
+	}
+	fmt.Printf("Appended %d events to %s\n", total, streamName)
+
+	var mu sync.Mutex
+	handled := map[uint64]int{}
+	handler := func(delay time.Duration) PersistentHandler {
+		return func(ctx context.Context, event *kurrentdb.ResolvedEvent, retryCount int) error {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			mu.Lock()
+			handled[Resolve(event, false).EventNumber]++
+			mu.Unlock()
+			return nil
+		}
+	}
+	handledCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(handled)
+	}
+
+	// === FIRST CONSUMER ===
+	// Slow handlers, three at a time; shut down once a few are done, with the rest of the batch
+	// in flight or buffered
+	fmt.Println("\n=== Shutting down mid-batch ===")
+	first := NewPersistentProcessor(client, streamName, groupName, PersistentProcessorOptions{Concurrency: 3})
+	firstDone := make(chan error, 1)
+	go func() { firstDone <- first.Run(ctx, handler(300*time.Millisecond)) }()
+	for handledCount() < 3 && ctx.Err() == nil {
+		time.Sleep(20 * time.Millisecond)
+	}
+	shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 2*time.Second)
+	shutdownErr := first.Shutdown(shutdownCtx)
+	shutdownCancel()
+	firstErr := <-firstDone
+	firstStats := first.Stats()
+	fmt.Printf("  Acked=%d Requeued=%d (shutdown: %v)\n", firstStats.Acked, firstStats.Requeued, shutdownErr)
+
+	// === SECOND CONSUMER ===
+	fmt.Println("\n=== A new consumer picks up the rest ===")
+	restarted := time.Now()
+	second := NewPersistentProcessor(client, streamName, groupName, PersistentProcessorOptions{Concurrency: 3})
+	secondCtx, stopSecond := context.WithCancel(ctx)
+	secondDone := make(chan error, 1)
+	go func() { secondDone <- second.Run(secondCtx, handler(10*time.Millisecond)) }()
+	for handledCount() < total && time.Since(restarted) < 10*time.Second {
+		time.Sleep(20 * time.Millisecond)
+	}
+	took := time.Since(restarted)
+	stopSecond()
+	<-secondDone
+	fmt.Printf("  Acked=%d in %s\n", second.Stats().Acked, took.Round(time.Millisecond))
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if shutdownErr != nil || firstErr != nil {
+		fmt.Printf("FAIL: Expected the in-flight handlers to drain within 2s, got %v / %v\n", shutdownErr, firstErr)
+		passed = false
+	}
+	if firstStats.Acked < 3 || firstStats.Requeued == 0 {
+		fmt.Printf("FAIL: Expected the first consumer to ack what it finished and requeue what it received, got %+v\n", firstStats)
+		passed = false
+	}
+	if handledCount() != total {
+		fmt.Printf("FAIL: Expected all %d events handled across both consumers, got %d\n", total, handledCount())
+		passed = false
+	}
+	if took > 5*time.Second {
+		fmt.Printf("FAIL: Expected the requeued events well before the 30s message timeout, took %s\n", took)
+		passed = false
+	}
+	mu.Lock()
+	for number, count := range handled {
+		if count != 1 {
+			fmt.Printf("FAIL: Expected event %d handled once, got %d\n", number, count)
+			passed = false
+		}
+	}
+	mu.Unlock()
+
+	if passed {
+		fmt.Println("\nAll persistent drain tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,345 @@
+// KurrentDB Go Client Example - Create-if-missing for persistent subscription groups
+// Demonstrates: Idempotent startup that creates a group once, accepts it existing, and detects settings drift
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === ENSURE ===
+
+// ErrPersistentSettingsDrift is returned under FailOnDrift when the existing group's settings differ
+var ErrPersistentSettingsDrift = errors.New("persistent subscription settings differ")
+
+// SettingsDriftPolicy decides what EnsurePersistentSubscription does when the group exists with
+// other settings than requested, e.g. after a deploy changed them
+type SettingsDriftPolicy int
+
+const (
+	// KeepExistingSettings reports the drift and leaves the group alone
+	KeepExistingSettings SettingsDriftPolicy = iota
+	// UpdateDriftedSettings updates the group to the requested settings. Consumers connected to the
+	// group are disconnected by the update and have to reconnect.
+	UpdateDriftedSettings
+	// FailOnDrift returns ErrPersistentSettingsDrift
+	FailOnDrift
+)
+
+// EnsuredSubscription is what EnsurePersistentSubscription found and did
+type EnsuredSubscription struct {
+	Created bool
+	Updated bool
+	// Drift lists the settings that differed, as "Name: existing -> requested"
+	Drift []string
+}
+
+// persistentGroupAdmin is what EnsurePersistentSubscription needs from the client. alreadyExists
+// classifies create errors, so the checks can fake a server without its error types.
+type persistentGroupAdmin struct {
+	create        func(ctx context.Context, stream, group string, options kurrentdb.PersistentStreamSubscriptionOptions) error
+	update        func(ctx context.Context, stream, group string, options kurrentdb.PersistentStreamSubscriptionOptions) error
+	info          func(ctx context.Context, stream, group string) (*kurrentdb.PersistentSubscriptionInfo, error)
+	alreadyExists func(err error) bool
+}
+
+func clientGroupAdmin(client *kurrentdb.Client) persistentGroupAdmin {
+	return persistentGroupAdmin{
+		create: client.CreatePersistentSubscription,
+		update: client.UpdatePersistentSubscription,
+		info: func(ctx context.Context, stream, group string) (*kurrentdb.PersistentSubscriptionInfo, error) {
+			return client.GetPersistentSubscriptionInfo(ctx, stream, group, kurrentdb.GetPersistentSubscriptionOptions{})
+		},
+		alreadyExists: isAlreadyExists,
+	}
+}
+
+// isAlreadyExists reports whether err is the client's "resource already exists" error
+func isAlreadyExists(err error) bool {
+	esErr, ok := kurrentdb.FromError(err)
+	return !ok && esErr.Code() == kurrentdb.ErrorCodeResourceAlreadyExists
+}
+
+// EnsurePersistentSubscription creates the group on stream, or accepts it already existing, so
+// every instance of a service can call it on startup. An existing group's settings are compared
+// with the requested ones (the server defaults when options.Settings is nil) and drift is handled
+// per policy. Any other failure is returned as an error naming the group.
+func EnsurePersistentSubscription(ctx context.Context, client *kurrentdb.Client, stream, group string,
+	options kurrentdb.PersistentStreamSubscriptionOptions, policy SettingsDriftPolicy) (EnsuredSubscription, error) {
+	return ensurePersistentSubscription(ctx, clientGroupAdmin(client), stream, group, options, policy)
+}
+
+func ensurePersistentSubscription(ctx context.Context, admin persistentGroupAdmin, stream, group string,
+	options kurrentdb.PersistentStreamSubscriptionOptions, policy SettingsDriftPolicy) (EnsuredSubscription, error) {
+	var result EnsuredSubscription
+
+	err := admin.create(ctx, stream, group, options)
+	if err == nil {
+		result.Created = true
+		return result, nil
+	}
+	if !admin.alreadyExists(err) {
+		return result, fmt.Errorf("create persistent subscription %s on %s: %w", group, stream, err)
+	}
+
+	info, err := admin.info(ctx, stream, group)
+	if err != nil {
+		return result, fmt.Errorf("read persistent subscription %s on %s: %w", group, stream, err)
+	}
+	requested := kurrentdb.SubscriptionSettingsDefault()
+	if options.Settings != nil {
+		requested = *options.Settings
+	}
+	if info.Settings != nil {
+		result.Drift = persistentSettingsDrift(*info.Settings, requested)
+	}
+	if len(result.Drift) == 0 {
+		return result, nil
+	}
+
+	switch policy {
+	case UpdateDriftedSettings:
+		if err := admin.update(ctx, stream, group, options); err != nil {
+			return result, fmt.Errorf("update persistent subscription %s on %s: %w", group, stream, err)
+		}
+		result.Updated = true
+	case FailOnDrift:
+		return result, fmt.Errorf("%w: %s on %s: %v", ErrPersistentSettingsDrift, group, stream, result.Drift)
+	}
+	return result, nil
+}
+
+// persistentSettingsDrift lists the settings that differ between existing and requested. Where
+// to start from only applies to a new group and is not compared.
+func persistentSettingsDrift(existing, requested kurrentdb.PersistentSubscriptionSettings) []string {
+	compare := []struct {
+		name                string
+		existing, requested interface{}
+	}{
+		{"ResolveLinkTos", existing.ResolveLinkTos, requested.ResolveLinkTos},
+		{"MessageTimeout", existing.MessageTimeout, requested.MessageTimeout},
+		{"MaxRetryCount", existing.MaxRetryCount, requested.MaxRetryCount},
+		{"CheckpointAfter", existing.CheckpointAfter, requested.CheckpointAfter},
+		{"MinCheckpointCount", existing.CheckpointLowerBound, requested.CheckpointLowerBound},
+		{"MaxCheckpointCount", existing.CheckpointUpperBound, requested.CheckpointUpperBound},
+		{"LiveBufferSize", existing.LiveBufferSize, requested.LiveBufferSize},
+		{"ReadBatchSize", existing.ReadBatchSize, requested.ReadBatchSize},
+		{"HistoryBufferSize", existing.HistoryBufferSize, requested.HistoryBufferSize},
+		{"MaxSubscriberCount", existing.MaxSubscriberCount, requested.MaxSubscriberCount},
+		{"ExtraStatistics", existing.ExtraStatistics, requested.ExtraStatistics},
+		{"NamedConsumerStrategy", existing.ConsumerStrategyName, requested.ConsumerStrategyName},
+	}
+	var drift []string
+	for _, c := range compare {
+		if c.existing != c.requested {
+			drift = append(drift, fmt.Sprintf("%s: %v -> %v", c.name, c.existing, c.requested))
+		}
+	}
+	return drift
+}
+
+// memoryGroups is a fake server for persistent subscription groups, recording the calls made
+type memoryGroups struct {
+	groups map[string]kurrentdb.PersistentSubscriptionSettings
+	calls  []string
+	// failCreate, if set, is returned by every create
+	failCreate error
+}
+
+var errGroupExists = errors.New("subscription group already exists")
+
+func (m *memoryGroups) admin() persistentGroupAdmin {
+	settingsOf := func(options kurrentdb.PersistentStreamSubscriptionOptions) kurrentdb.PersistentSubscriptionSettings {
+		if options.Settings != nil {
+			return *options.Settings
+		}
+		return kurrentdb.SubscriptionSettingsDefault()
+	}
+	return persistentGroupAdmin{
+		create: func(ctx context.Context, stream, group string, options kurrentdb.PersistentStreamSubscriptionOptions) error {
+			m.calls = append(m.calls, "create")
+			if m.failCreate != nil {
+				return m.failCreate
+			}
+			if _, ok := m.groups[stream+"::"+group]; ok {
+				return errGroupExists
+			}
+			m.groups[stream+"::"+group] = settingsOf(options)
+			return nil
+		},
+		update: func(ctx context.Context, stream, group string, options kurrentdb.PersistentStreamSubscriptionOptions) error {
+			m.calls = append(m.calls, "update")
+			m.groups[stream+"::"+group] = settingsOf(options)
+			return nil
+		},
+		info: func(ctx context.Context, stream, group string) (*kurrentdb.PersistentSubscriptionInfo, error) {
+			m.calls = append(m.calls, "info")
+			settings := m.groups[stream+"::"+group]
+			return &kurrentdb.PersistentSubscriptionInfo{EventSource: stream, GroupName: group, Settings: &settings}, nil
+		},
+		alreadyExists: func(err error) bool { return errors.Is(err, errGroupExists) },
+	}
+}
+
+// RunPersistentEnsureChecks covers the create, already-exists, drift and failure paths against a
+// fake server, no server required
+func RunPersistentEnsureChecks() {
+	checks := newChecker("persistent subscription ensure")
+
+	ctx := context.Background()
+	server := &memoryGroups{groups: make(map[string]kurrentdb.PersistentSubscriptionSettings)}
+	admin := server.admin()
+	options := func(retries int) kurrentdb.PersistentStreamSubscriptionOptions {
+		settings := kurrentdb.SubscriptionSettingsDefault()
+		settings.MaxRetryCount = int32(retries)
+		return kurrentdb.PersistentStreamSubscriptionOptions{Settings: &settings}
+	}
+
+	// --- Create ---
+	fmt.Println("\n--- Create ---")
+	created, err := ensurePersistentSubscription(ctx, admin, "orders", "billing", options(5), KeepExistingSettings)
+	fmt.Printf("  %+v (%v), calls %v\n", created, err, server.calls)
+	checks.Check(err == nil && created.Created && fmt.Sprint(server.calls) == "[create]", "a missing group should be created, got %+v (%v)", created, err)
+
+	// --- Already exists ---
+	fmt.Println("\n--- Already exists ---")
+	server.calls = nil
+	existed, err := ensurePersistentSubscription(ctx, admin, "orders", "billing", options(5), UpdateDriftedSettings)
+	fmt.Printf("  %+v (%v), calls %v\n", existed, err, server.calls)
+	checks.Check(err == nil && !existed.Created && !existed.Updated && len(existed.Drift) == 0,
+		"an existing group with the same settings should be accepted as is, got %+v (%v)", existed, err)
+	checks.Check(fmt.Sprint(server.calls) == "[create info]", "an unchanged group should not be updated, got calls %v", server.calls)
+	defaults, err := ensurePersistentSubscription(ctx, admin, "orders", "shipping", kurrentdb.PersistentStreamSubscriptionOptions{}, FailOnDrift)
+	again, againErr := ensurePersistentSubscription(ctx, admin, "orders", "shipping", kurrentdb.PersistentStreamSubscriptionOptions{}, FailOnDrift)
+	checks.Check(err == nil && defaults.Created && againErr == nil && len(again.Drift) == 0,
+		"nil settings should compare as the server defaults, got %+v (%v)", again, againErr)
+
+	// --- Settings drift ---
+	fmt.Println("\n--- Settings drift ---")
+	server.calls = nil
+	kept, keptErr := ensurePersistentSubscription(ctx, admin, "orders", "billing", options(10), KeepExistingSettings)
+	fmt.Printf("  keep:   %+v (%v)\n", kept, keptErr)
+	checks.Check(keptErr == nil && fmt.Sprint(kept.Drift) == "[MaxRetryCount: 5 -> 10]" && !kept.Updated,
+		"drift should be reported, got %+v (%v)", kept, keptErr)
+	checks.Check(server.groups["orders::billing"].MaxRetryCount == 5, "KeepExistingSettings should leave the group alone")
+
+	_, failedErr := ensurePersistentSubscription(ctx, admin, "orders", "billing", options(10), FailOnDrift)
+	fmt.Printf("  fail:   %v\n", failedErr)
+	checks.Check(errors.Is(failedErr, ErrPersistentSettingsDrift), "FailOnDrift should fail with ErrPersistentSettingsDrift, got %v", failedErr)
+
+	server.calls = nil
+	updated, updatedErr := ensurePersistentSubscription(ctx, admin, "orders", "billing", options(10), UpdateDriftedSettings)
+	fmt.Printf("  update: %+v (%v), calls %v\n", updated, updatedErr, server.calls)
+	checks.Check(updatedErr == nil && updated.Updated && server.groups["orders::billing"].MaxRetryCount == 10,
+		"UpdateDriftedSettings should update the group, got %+v (%v)", updated, updatedErr)
+	settled, _ := ensurePersistentSubscription(ctx, admin, "orders", "billing", options(10), UpdateDriftedSettings)
+	checks.Check(len(settled.Drift) == 0 && !settled.Updated, "after the update the group should match, got %+v", settled)
+
+	// --- Other failures ---
+	fmt.Println("\n--- Other failures ---")
+	errDenied := errors.New("access denied")
+	server.failCreate = errDenied
+	server.calls = nil
+	_, deniedErr := ensurePersistentSubscription(ctx, admin, "orders", "audit", options(5), KeepExistingSettings)
+	fmt.Printf("  %v\n", deniedErr)
+	checks.Check(errors.Is(deniedErr, errDenied) && fmt.Sprint(server.calls) == "[create]",
+		"other create errors should be returned, not treated as existing, got %v (calls %v)", deniedErr, server.calls)
+
+	checks.Done()
+}
+
+// RunPersistentEnsure starts a consumer service three times against the same group: the first start
+// creates it, a restart finds it, and a deploy with new settings detects and applies the drift
+func RunPersistentEnsure() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	streamName := Streams.Name("order", uuid.New().String())
+	groupName := "order-processor"
+	defer client.DeletePersistentSubscription(ctx, streamName, groupName, kurrentdb.DeletePersistentSubscriptionOptions{})
+
+	options := func(retries int) kurrentdb.PersistentStreamSubscriptionOptions {
+		options, err := NewPersistentSettingsBuilder().
+			StartFrom(kurrentdb.Start{}).
+			MaxRetryCount(retries).
+			MessageTimeout(30 * time.Second).
+			StreamOptions()
+		if err != nil {
+			panic(err)
+		}
+		return options
+	}
+
+	// === FIRST START ===
+	fmt.Println("\n=== First start ===")
+	first, firstErr := EnsurePersistentSubscription(ctx, client, streamName, groupName, options(5), FailOnDrift)
+	fmt.Printf("  %+v (%v)\n", first, firstErr)
+
+	// === RESTART ===
+	fmt.Println("\n=== Restart, same settings ===")
+	restart, restartErr := EnsurePersistentSubscription(ctx, client, streamName, groupName, options(5), FailOnDrift)
+	fmt.Printf("  %+v (%v)\n", restart, restartErr)
+
+	// === DEPLOY WITH NEW SETTINGS ===
+	fmt.Println("\n=== Deploy with MaxRetryCount 10 ===")
+	_, strictErr := EnsurePersistentSubscription(ctx, client, streamName, groupName, options(10), FailOnDrift)
+	fmt.Printf("  FailOnDrift: %v\n", strictErr)
+	deploy, deployErr := EnsurePersistentSubscription(ctx, client, streamName, groupName, options(10), UpdateDriftedSettings)
+	fmt.Printf("  UpdateDriftedSettings: %+v (%v)\n", deploy, deployErr)
+	info, err := client.GetPersistentSubscriptionInfo(ctx, streamName, groupName, kurrentdb.GetPersistentSubscriptionOptions{})
+	if err != nil {
+		panic(err)
+	}
+
+	// === INVALID REQUEST ===
+	fmt.Println("\n=== A create that fails for another reason ===")
+	invalid := options(5)
+	invalid.Settings.ConsumerStrategyName = "NoSuchStrategy"
+	defer client.DeletePersistentSubscription(ctx, streamName, "invalid-processor", kurrentdb.DeletePersistentSubscriptionOptions{})
+	_, invalidErr := EnsurePersistentSubscription(ctx, client, streamName, "invalid-processor", invalid, KeepExistingSettings)
+	fmt.Printf("  %v\n", invalidErr)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if firstErr != nil || !first.Created {
+		fmt.Printf("FAIL: The first start should create the group, got %+v (%v)\n", first, firstErr)
+		passed = false
+	}
+	if restartErr != nil || restart.Created || len(restart.Drift) != 0 {
+		fmt.Printf("FAIL: A restart should accept the existing group unchanged, got %+v (%v)\n", restart, restartErr)
+		passed = false
+	}
+	if !errors.Is(strictErr, ErrPersistentSettingsDrift) {
+		fmt.Printf("FAIL: FailOnDrift should report the changed retry count, got %v\n", strictErr)
+		passed = false
+	}
+	if deployErr != nil || !deploy.Updated || info.Settings == nil || info.Settings.MaxRetryCount != 10 {
+		fmt.Printf("FAIL: The deploy should update the group to 10 retries, got %+v (%v)\n", deploy, deployErr)
+		passed = false
+	}
+	if invalidErr == nil || isAlreadyExists(invalidErr) {
+		fmt.Printf("FAIL: An invalid group should fail with its own error, got %v\n", invalidErr)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll persistent subscription ensure tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
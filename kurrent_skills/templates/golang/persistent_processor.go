@@ -0,0 +1,497 @@
+// KurrentDB Go Client Example - Persistent subscription processor
+// Demonstrates: Ack/nack handling around a handler, with a per-event timeout that nacks before the server times out
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === PERSISTENT PROCESSOR ===
+
+// PersistentHandler processes one event. It must return promptly once ctx is done.
+type PersistentHandler func(ctx context.Context, event *kurrentdb.ResolvedEvent, retryCount int) error
+
+// PersistentProcessorOptions configures ack/nack behaviour
+type PersistentProcessorOptions struct {
+	// Timeout bounds one handler call. Keep it well under the group's MessageTimeout, otherwise the
+	// server redelivers the event to another consumer while this one is still working on it.
+	// Zero means no timeout.
+	Timeout time.Duration
+	// TimeoutAction is how a timed-out event is nacked: NackActionRetry (default) or NackActionPark
+	TimeoutAction kurrentdb.NackAction
+	// ErrorAction is how an event whose handler returned an error is nacked (default NackActionRetry)
+	ErrorAction kurrentdb.NackAction
+	// Concurrency is how many events are handled at once (default 1). Events then complete, and
+	// are acked, out of order; keep it within the group's BufferSize.
+	Concurrency int
+}
+
+// PersistentProcessorStats counts outcomes since the processor started
+type PersistentProcessorStats struct {
+	Acked    int
+	Failed   int
+	TimedOut int
+	// Requeued counts events Shutdown nacked for retry: received but not started, or cut off
+	// when the drain ran out of time
+	Requeued int
+}
+
+// persistentEvents is what the processor needs from *kurrentdb.PersistentSubscription, so the
+// checks can deliver events without a server
+type persistentEvents interface {
+	Recv() *kurrentdb.PersistentSubscriptionEvent
+	Ack(messages ...*kurrentdb.ResolvedEvent) error
+	Nack(reason string, action kurrentdb.NackAction, messages ...*kurrentdb.ResolvedEvent) error
+	Close() error
+}
+
+// PersistentProcessor consumes a persistent subscription group, acking each event its handler
+// completes and nacking the ones that fail or exceed the timeout
+type PersistentProcessor struct {
+	subscribe func(ctx context.Context) (persistentEvents, error)
+	options   PersistentProcessorOptions
+
+	// stopping is closed by Shutdown, finished when Run returns
+	stopping     chan struct{}
+	stopOnce     sync.Once
+	finished     chan struct{}
+	finishedOnce sync.Once
+
+	mu    sync.Mutex
+	stats PersistentProcessorStats
+	// cutOff cancels the handlers of the current Run; cut records that Shutdown did so
+	cutOff context.CancelFunc
+	cut    bool
+}
+
+func NewPersistentProcessor(client *kurrentdb.Client, stream, group string, options PersistentProcessorOptions) *PersistentProcessor {
+	return newPersistentProcessor(func(ctx context.Context) (persistentEvents, error) {
+		subscription, err := client.SubscribeToPersistentSubscription(ctx, stream, group, kurrentdb.SubscribeToPersistentSubscriptionOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return subscription, nil
+	}, options)
+}
+
+func newPersistentProcessor(subscribe func(ctx context.Context) (persistentEvents, error), options PersistentProcessorOptions) *PersistentProcessor {
+	if options.TimeoutAction == kurrentdb.NackActionUnknown {
+		options.TimeoutAction = kurrentdb.NackActionRetry
+	}
+	if options.ErrorAction == kurrentdb.NackActionUnknown {
+		options.ErrorAction = kurrentdb.NackActionRetry
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 1
+	}
+	return &PersistentProcessor{
+		subscribe: subscribe,
+		options:   options,
+		stopping:  make(chan struct{}),
+		finished:  make(chan struct{}),
+	}
+}
+
+func (p *PersistentProcessor) Stats() PersistentProcessorStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.stats
+}
+
+// Run processes events until ctx is cancelled, the subscription drops or Shutdown completes.
+// Cancelling ctx stops at once, leaving in-flight events unacked for the server to redeliver after
+// the group's MessageTimeout; Shutdown settles them first. Run returns nil in both cases.
+func (p *PersistentProcessor) Run(ctx context.Context, handler PersistentHandler) error {
+	defer p.finishedOnce.Do(func() { close(p.finished) })
+
+	subscription, err := p.subscribe(ctx)
+	if err != nil {
+		return err
+	}
+
+	handlersCtx, cutOff := context.WithCancel(ctx)
+	defer cutOff()
+	p.mu.Lock()
+	p.cutOff = cutOff
+	p.mu.Unlock()
+
+	// Recv blocks, so a reader feeds the loop below, which can then stop pulling the moment
+	// Shutdown is called. What the reader receives after that is nacked straight back.
+	messages := make(chan *kurrentdb.PersistentSubscriptionEvent)
+	dispatched := make(chan struct{})
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		for {
+			message := subscription.Recv()
+			select {
+			case messages <- message:
+			case <-p.stopping:
+				if message.EventAppeared != nil {
+					p.requeue(subscription, message.EventAppeared.Event)
+				}
+			case <-dispatched:
+				// Without Shutdown, the server redelivers what is left; with it, both cases may be
+				// ready, and the event must still go back
+				if message.EventAppeared != nil && p.isStopping() {
+					p.requeue(subscription, message.EventAppeared.Event)
+				}
+			}
+			if message.SubscriptionDropped != nil {
+				return
+			}
+		}
+	}()
+
+	var inflight sync.WaitGroup
+	slots := make(chan struct{}, p.options.Concurrency)
+	failed := make(chan error, 1)
+	result := p.dispatch(ctx, handlersCtx, subscription, handler, messages, slots, &inflight, failed)
+	close(dispatched)
+
+	// Draining: every handler started returns, and its event is settled, before the subscription
+	// closes. Shutdown cuts off handlers that take too long.
+	inflight.Wait()
+	subscription.Close()
+	<-readerDone
+	select {
+	case err := <-failed:
+		return err
+	default:
+	}
+	return result
+}
+
+// dispatch starts a handler for each event received, up to Concurrency at once, until Shutdown,
+// ctx or the subscription ends it. It returns what Run should return once the handlers are done.
+func (p *PersistentProcessor) dispatch(ctx, handlersCtx context.Context, subscription persistentEvents, handler PersistentHandler,
+	messages <-chan *kurrentdb.PersistentSubscriptionEvent, slots chan struct{}, inflight *sync.WaitGroup, failed chan error) error {
+	for {
+		var message *kurrentdb.PersistentSubscriptionEvent
+		select {
+		case <-p.stopping:
+			return nil
+		case <-ctx.Done():
+			return nil
+		case err := <-failed:
+			// Put it back for Run, which returns it once the other handlers are done
+			failed <- err
+			return nil
+		case message = <-messages:
+		}
+
+		if message.SubscriptionDropped != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return message.SubscriptionDropped.Error
+		}
+		if message.EventAppeared == nil {
+			continue
+		}
+
+		event, retryCount := message.EventAppeared.Event, message.EventAppeared.RetryCount
+		select {
+		case slots <- struct{}{}:
+		case <-p.stopping:
+			p.requeue(subscription, event)
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+		// Shutdown may have been called while the slot was free; don't start anything after it
+		select {
+		case <-p.stopping:
+			<-slots
+			p.requeue(subscription, event)
+			return nil
+		default:
+		}
+
+		inflight.Add(1)
+		go func() {
+			defer inflight.Done()
+			defer func() { <-slots }()
+
+			err := p.invoke(handlersCtx, handler, event, retryCount)
+			if err := p.settle(ctx, subscription, event, retryCount, err); err != nil {
+				select {
+				case failed <- err:
+				default:
+				}
+			}
+		}()
+	}
+}
+
+// settle acks or nacks event according to how its handler ended, and returns an error only if the
+// ack or nack itself failed
+func (p *PersistentProcessor) settle(ctx context.Context, subscription persistentEvents, event *kurrentdb.ResolvedEvent, retryCount int, err error) error {
+	recorded := Resolve(event, false)
+	p.mu.Lock()
+	cut := p.cut
+	p.mu.Unlock()
+
+	switch {
+	case err == nil:
+		if err := subscription.Ack(event); err != nil {
+			return fmt.Errorf("ack %s@%d: %w", recorded.StreamID, recorded.EventNumber, err)
+		}
+		p.record(func(s *PersistentProcessorStats) { s.Acked++ })
+
+	case ctx.Err() != nil:
+		// Stopped without Shutdown: leave the event unacked so the server redelivers it
+
+	case cut:
+		// Cut off by Shutdown, whatever the handler made of its cancelled context
+		fmt.Printf("  [processor] %s@%d still running at shutdown, nacking with retry\n", recorded.StreamID, recorded.EventNumber)
+		p.requeue(subscription, event)
+
+	case errors.Is(err, context.DeadlineExceeded):
+		fmt.Printf("  [processor] WARNING: %s@%d exceeded %s (retry %d), nacking with %s\n",
+			recorded.StreamID, recorded.EventNumber, p.options.Timeout, retryCount, nackActionName(p.options.TimeoutAction))
+		if err := subscription.Nack("handler timed out", p.options.TimeoutAction, event); err != nil {
+			return err
+		}
+		p.record(func(s *PersistentProcessorStats) { s.TimedOut++ })
+
+	default:
+		fmt.Printf("  [processor] %s@%d failed: %v\n", recorded.StreamID, recorded.EventNumber, err)
+		if err := subscription.Nack(err.Error(), p.options.ErrorAction, event); err != nil {
+			return err
+		}
+		p.record(func(s *PersistentProcessorStats) { s.Failed++ })
+	}
+	return nil
+}
+
+// requeue nacks an event the processor won't finish for retry, so the server redelivers it now
+// rather than after the MessageTimeout. The subscription is closing, so a failed nack is only
+// logged: the server redelivers the event after the timeout anyway.
+func (p *PersistentProcessor) requeue(subscription persistentEvents, event *kurrentdb.ResolvedEvent) {
+	if err := subscription.Nack("consumer shutting down", kurrentdb.NackActionRetry, event); err != nil {
+		recorded := Resolve(event, false)
+		fmt.Printf("  [processor] nack %s@%d at shutdown: %v\n", recorded.StreamID, recorded.EventNumber, err)
+		return
+	}
+	p.record(func(s *PersistentProcessorStats) { s.Requeued++ })
+}
+
+func (p *PersistentProcessor) isStopping() bool {
+	select {
+	case <-p.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// Shutdown stops Run pulling events, waits for the handlers in flight and settles each: acked if it
+// completed, nacked as usual if it failed. Events received but not started are nacked for retry.
+// If ctx ends first, the handlers still running are cancelled and their events nacked for retry,
+// and Shutdown returns ctx's error once they are settled. Run then closes the subscription and
+// returns nil. Call Shutdown while Run is running; it returns once Run has.
+func (p *PersistentProcessor) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() { close(p.stopping) })
+
+	select {
+	case <-p.finished:
+		return nil
+	case <-ctx.Done():
+	}
+
+	p.mu.Lock()
+	p.cut = true
+	cutOff := p.cutOff
+	p.mu.Unlock()
+	if cutOff != nil {
+		cutOff()
+	}
+	<-p.finished
+	return ctx.Err()
+}
+
+// invoke runs handler with a per-event deadline. The handler's context is cancelled on timeout, and
+// invoke returns context.DeadlineExceeded even if the handler ignores it and keeps running; that
+// goroutine then finishes in the background and its result is discarded.
+func (p *PersistentProcessor) invoke(ctx context.Context, handler PersistentHandler, event *kurrentdb.ResolvedEvent, retryCount int) error {
+	if p.options.Timeout <= 0 {
+		return handler(ctx, event, retryCount)
+	}
+
+	handlerCtx, cancel := context.WithTimeout(ctx, p.options.Timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- handler(handlerCtx, event, retryCount) }()
+
+	select {
+	case err := <-done:
+		if err != nil && handlerCtx.Err() != nil {
+			return handlerCtx.Err()
+		}
+		return err
+	case <-handlerCtx.Done():
+		return handlerCtx.Err()
+	}
+}
+
+func (p *PersistentProcessor) record(update func(*PersistentProcessorStats)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	update(&p.stats)
+}
+
+func nackActionName(action kurrentdb.NackAction) string {
+	switch action {
+	case kurrentdb.NackActionPark:
+		return "park"
+	case kurrentdb.NackActionRetry:
+		return "retry"
+	case kurrentdb.NackActionSkip:
+		return "skip"
+	case kurrentdb.NackActionStop:
+		return "stop"
+	}
+	return "unknown"
+}
+
+// RunPersistentProcessor runs a handler that hangs on the first delivery of some events
+func RunPersistentProcessor() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	streamName := Streams.Name("order", uuid.New().String())
+	groupName := "timeout-demo"
+
+	// === CREATE GROUP ===
+	// The server would redeliver after 30s; the processor gives up after 300ms
+	settings := kurrentdb.SubscriptionSettingsDefault()
+	settings.MessageTimeout = 30_000
+	settings.MaxRetryCount = 5
+
+	err := client.CreatePersistentSubscription(ctx, streamName, groupName, kurrentdb.PersistentStreamSubscriptionOptions{
+		Settings:  &settings,
+		StartFrom: kurrentdb.Start{},
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer client.DeletePersistentSubscription(context.Background(), streamName, groupName, kurrentdb.DeletePersistentSubscriptionOptions{})
+
+	// === APPEND EVENTS ===
+	const total = 5
+	for i := 0; i < total; i++ {
+		data, _ := json.Marshal(map[string]interface{}{"sequence": i, "hang": i == 1 || i == 3})
+		_, err := client.AppendToStream(ctx, streamName, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   "OrderUpdated",
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        data,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	fmt.Printf("Appended %d events to %s (#1 and #3 hang on first delivery)\n", total, streamName)
+
+	// === PROCESS ===
+	processor := NewPersistentProcessor(client, streamName, groupName, PersistentProcessorOptions{
+		Timeout:       300 * time.Millisecond,
+		TimeoutAction: kurrentdb.NackActionRetry,
+	})
+
+	var mu sync.Mutex
+	completed := map[uint64]int{}
+	cancelledHandlers := 0
+
+	startedAt := time.Now()
+	runDone := make(chan error, 1)
+	go func() {
+		runDone <- processor.Run(ctx, func(ctx context.Context, event *kurrentdb.ResolvedEvent, retryCount int) error {
+			var payload struct {
+				Hang bool `json:"hang"`
+			}
+			json.Unmarshal(Resolve(event, false).Data, &payload)
+
+			if payload.Hang && retryCount == 0 {
+				// Simulate a call that never returns, e.g. a stuck downstream request
+				<-ctx.Done()
+				mu.Lock()
+				cancelledHandlers++
+				mu.Unlock()
+				return ctx.Err()
+			}
+
+			mu.Lock()
+			completed[Resolve(event, false).EventNumber]++
+			mu.Unlock()
+			return nil
+		})
+	}()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(completed)
+		mu.Unlock()
+		if n == total {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	elapsed := time.Since(startedAt)
+
+	cancel()
+	if err := <-runDone; err != nil {
+		panic(err)
+	}
+
+	stats := processor.Stats()
+	fmt.Printf("  Acked=%d TimedOut=%d Failed=%d in %s\n", stats.Acked, stats.TimedOut, stats.Failed, elapsed.Round(time.Millisecond))
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(completed) != total {
+		fmt.Printf("FAIL: All %d events should complete after retries, got %d\n", total, len(completed))
+		passed = false
+	}
+	if stats.TimedOut != 2 || cancelledHandlers != 2 {
+		fmt.Printf("FAIL: Expected 2 timeouts with cancelled handler contexts, got %d timeouts, %d cancelled\n", stats.TimedOut, cancelledHandlers)
+		passed = false
+	}
+	if elapsed > 5*time.Second {
+		fmt.Printf("FAIL: Hanging events should be retried long before the server's 30s message timeout, took %s\n", elapsed)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll persistent processor tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
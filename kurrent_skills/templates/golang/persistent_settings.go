@@ -0,0 +1,312 @@
+// KurrentDB Go Client Example - Persistent subscription settings builder
+// Demonstrates: Typed, validated persistent subscription settings and checking them against the server's view
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === SETTINGS BUILDER ===
+
+// ErrInvalidSubscriptionSettings wraps every problem reported by Validate
+var ErrInvalidSubscriptionSettings = errors.New("invalid persistent subscription settings")
+
+// PersistentSettingsBuilder wraps kurrentdb.PersistentSubscriptionSettings with durations instead
+// of millisecond int32s and checks the combinations the server rejects before a round trip.
+// Defaults are the server's own (SubscriptionSettingsDefault), starting from the beginning.
+type PersistentSettingsBuilder struct {
+	settings kurrentdb.PersistentSubscriptionSettings
+	from     interface{}
+	problems []error
+}
+
+func NewPersistentSettingsBuilder() *PersistentSettingsBuilder {
+	return &PersistentSettingsBuilder{
+		settings: kurrentdb.SubscriptionSettingsDefault(),
+		from:     kurrentdb.Start{},
+	}
+}
+
+// StartFrom sets where a new group starts: kurrentdb.Start{}, kurrentdb.End{}, a
+// kurrentdb.StreamRevision for stream groups or a kurrentdb.Position for $all groups.
+// ParseStartFrom produces suitable values from configuration.
+func (b *PersistentSettingsBuilder) StartFrom(from interface{}) *PersistentSettingsBuilder {
+	b.from = from
+	return b
+}
+
+// ResolveLinkTos delivers the events links point to, e.g. for $ce- groups
+func (b *PersistentSettingsBuilder) ResolveLinkTos(resolve bool) *PersistentSettingsBuilder {
+	b.settings.ResolveLinkTos = resolve
+	return b
+}
+
+// MaxRetryCount is how many times an event is retried before it is parked
+func (b *PersistentSettingsBuilder) MaxRetryCount(count int) *PersistentSettingsBuilder {
+	b.settings.MaxRetryCount = b.int32("MaxRetryCount", count)
+	return b
+}
+
+// MessageTimeout is how long the server waits for an ack before retrying the event
+func (b *PersistentSettingsBuilder) MessageTimeout(timeout time.Duration) *PersistentSettingsBuilder {
+	b.settings.MessageTimeout = b.milliseconds("MessageTimeout", timeout)
+	return b
+}
+
+// CheckpointAfter is the longest the server goes between checkpoints of acked events
+func (b *PersistentSettingsBuilder) CheckpointAfter(interval time.Duration) *PersistentSettingsBuilder {
+	b.settings.CheckpointAfter = b.milliseconds("CheckpointAfter", interval)
+	return b
+}
+
+// MinCheckpointCount is the fewest acked events before a checkpoint is written
+func (b *PersistentSettingsBuilder) MinCheckpointCount(count int) *PersistentSettingsBuilder {
+	b.settings.CheckpointLowerBound = b.int32("MinCheckpointCount", count)
+	return b
+}
+
+// MaxCheckpointCount forces a checkpoint once this many events are acked
+func (b *PersistentSettingsBuilder) MaxCheckpointCount(count int) *PersistentSettingsBuilder {
+	b.settings.CheckpointUpperBound = b.int32("MaxCheckpointCount", count)
+	return b
+}
+
+// LiveBufferSize is how many live events the server buffers per group
+func (b *PersistentSettingsBuilder) LiveBufferSize(size int) *PersistentSettingsBuilder {
+	b.settings.LiveBufferSize = b.int32("LiveBufferSize", size)
+	return b
+}
+
+// ReadBatchSize is how many events the server reads per batch while catching up
+func (b *PersistentSettingsBuilder) ReadBatchSize(size int) *PersistentSettingsBuilder {
+	b.settings.ReadBatchSize = b.int32("ReadBatchSize", size)
+	return b
+}
+
+// HistoryBufferSize is how many historical events the server keeps in memory while catching up
+func (b *PersistentSettingsBuilder) HistoryBufferSize(size int) *PersistentSettingsBuilder {
+	b.settings.HistoryBufferSize = b.int32("HistoryBufferSize", size)
+	return b
+}
+
+// ExtraStatistics enables latency histograms in the group's stats, at some cost
+func (b *PersistentSettingsBuilder) ExtraStatistics(enabled bool) *PersistentSettingsBuilder {
+	b.settings.ExtraStatistics = enabled
+	return b
+}
+
+// NamedConsumerStrategy chooses how events are spread across the group's consumers
+func (b *PersistentSettingsBuilder) NamedConsumerStrategy(strategy kurrentdb.ConsumerStrategy) *PersistentSettingsBuilder {
+	b.settings.ConsumerStrategyName = strategy
+	return b
+}
+
+func (b *PersistentSettingsBuilder) int32(name string, value int) int32 {
+	if value < 0 || value > math.MaxInt32 {
+		b.problems = append(b.problems, fmt.Errorf("%w: %s %d out of range", ErrInvalidSubscriptionSettings, name, value))
+		return 0
+	}
+	return int32(value)
+}
+
+func (b *PersistentSettingsBuilder) milliseconds(name string, d time.Duration) int32 {
+	if d%time.Millisecond != 0 {
+		b.problems = append(b.problems, fmt.Errorf("%w: %s %s is not a whole number of milliseconds", ErrInvalidSubscriptionSettings, name, d))
+	}
+	return b.int32(name, int(d.Milliseconds()))
+}
+
+// Validate reports every problem at once, each wrapping ErrInvalidSubscriptionSettings
+func (b *PersistentSettingsBuilder) Validate() error {
+	problems := append([]error(nil), b.problems...)
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidSubscriptionSettings}, args...)...))
+	}
+
+	s := b.settings
+	if s.MessageTimeout <= 0 {
+		problem("MessageTimeout must be positive")
+	}
+	if s.CheckpointAfter <= 0 {
+		problem("CheckpointAfter must be positive")
+	}
+	if s.CheckpointLowerBound <= 0 || s.CheckpointUpperBound <= 0 {
+		problem("checkpoint counts must be positive")
+	} else if s.CheckpointLowerBound > s.CheckpointUpperBound {
+		problem("MinCheckpointCount %d is greater than MaxCheckpointCount %d", s.CheckpointLowerBound, s.CheckpointUpperBound)
+	}
+	if s.LiveBufferSize <= 0 || s.ReadBatchSize <= 0 || s.HistoryBufferSize <= 0 {
+		problem("buffer and batch sizes must be positive")
+	} else if s.ReadBatchSize >= s.HistoryBufferSize {
+		// The server rejects this: a read batch has to fit in the history buffer
+		problem("ReadBatchSize %d must be smaller than HistoryBufferSize %d", s.ReadBatchSize, s.HistoryBufferSize)
+	}
+
+	switch s.ConsumerStrategyName {
+	case kurrentdb.ConsumerStrategyRoundRobin, kurrentdb.ConsumerStrategyDispatchToSingle, kurrentdb.ConsumerStrategyPinned:
+	default:
+		problem("unknown consumer strategy %q", s.ConsumerStrategyName)
+	}
+
+	switch b.from.(type) {
+	case kurrentdb.Start, kurrentdb.End, kurrentdb.StreamRevision, kurrentdb.Position:
+	default:
+		problem("StartFrom %T is not a start position", b.from)
+	}
+
+	return errors.Join(problems...)
+}
+
+// StreamOptions returns create/update options for a group on one stream
+func (b *PersistentSettingsBuilder) StreamOptions() (kurrentdb.PersistentStreamSubscriptionOptions, error) {
+	if err := b.Validate(); err != nil {
+		return kurrentdb.PersistentStreamSubscriptionOptions{}, err
+	}
+	from, ok := b.from.(kurrentdb.StreamPosition)
+	if !ok {
+		return kurrentdb.PersistentStreamSubscriptionOptions{}, fmt.Errorf("%w: a stream group can't start from %T", ErrInvalidSubscriptionSettings, b.from)
+	}
+
+	settings := b.settings
+	settings.StartFrom = from
+	return kurrentdb.PersistentStreamSubscriptionOptions{Settings: &settings, StartFrom: from}, nil
+}
+
+// AllOptions returns create options for a group on $all, optionally filtered
+func (b *PersistentSettingsBuilder) AllOptions(filter *kurrentdb.SubscriptionFilter) (kurrentdb.PersistentAllSubscriptionOptions, error) {
+	if err := b.Validate(); err != nil {
+		return kurrentdb.PersistentAllSubscriptionOptions{}, err
+	}
+	from, ok := b.from.(kurrentdb.AllPosition)
+	if !ok {
+		return kurrentdb.PersistentAllSubscriptionOptions{}, fmt.Errorf("%w: a $all group can't start from %T", ErrInvalidSubscriptionSettings, b.from)
+	}
+
+	settings := b.settings
+	settings.StartFrom = from
+	return kurrentdb.PersistentAllSubscriptionOptions{Settings: &settings, StartFrom: from, Filter: filter}, nil
+}
+
+// RunPersistentSettings tunes a high-throughput group, creates it, and reads the settings back
+func RunPersistentSettings() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	passed := true
+	fail := func(format string, args ...interface{}) {
+		fmt.Printf("FAIL: "+format+"\n", args...)
+		passed = false
+	}
+
+	// === VALIDATION ===
+	fmt.Println("\n=== Validation ===")
+	mistakes := map[string]*PersistentSettingsBuilder{
+		"batch >= history buffer": NewPersistentSettingsBuilder().ReadBatchSize(500).HistoryBufferSize(500),
+		"min > max checkpoint":    NewPersistentSettingsBuilder().MinCheckpointCount(1000).MaxCheckpointCount(10),
+		"sub-millisecond timeout": NewPersistentSettingsBuilder().MessageTimeout(1500 * time.Microsecond),
+		"negative retries":        NewPersistentSettingsBuilder().MaxRetryCount(-1),
+		"zero message timeout":    NewPersistentSettingsBuilder().MessageTimeout(0),
+		"unknown strategy":        NewPersistentSettingsBuilder().NamedConsumerStrategy("Fastest"),
+		"start from a string":     NewPersistentSettingsBuilder().StartFrom("start"),
+	}
+	for name, builder := range mistakes {
+		err := builder.Validate()
+		fmt.Printf("  %s: %v\n", name, err)
+		if !errors.Is(err, ErrInvalidSubscriptionSettings) {
+			fail("%s should fail validation", name)
+		}
+	}
+	if _, err := NewPersistentSettingsBuilder().StartFrom(kurrentdb.Position{Commit: 1, Prepare: 1}).StreamOptions(); err == nil {
+		fail("a stream group should reject a $all position")
+	}
+	if err := NewPersistentSettingsBuilder().Validate(); err != nil {
+		fail("server defaults should be valid, got %v", err)
+	}
+
+	// === HIGH-THROUGHPUT GROUP ===
+	fmt.Println("\n=== High-throughput group ===")
+	streamName := Streams.Name("order", uuid.New().String())
+	groupName := "bulk-processor"
+
+	builder := NewPersistentSettingsBuilder().
+		StartFrom(kurrentdb.Start{}).
+		// Larger batches and buffers: fewer reads while catching up, more in flight per consumer
+		ReadBatchSize(500).
+		HistoryBufferSize(2000).
+		LiveBufferSize(2000).
+		// Checkpoint less often: each checkpoint is a write
+		CheckpointAfter(5 * time.Second).
+		MinCheckpointCount(100).
+		MaxCheckpointCount(10000).
+		// Allow slow batches more time before a retry, but park poison events after fewer retries
+		MessageTimeout(60 * time.Second).
+		MaxRetryCount(5).
+		NamedConsumerStrategy(kurrentdb.ConsumerStrategyRoundRobin).
+		ExtraStatistics(false)
+
+	options, err := builder.StreamOptions()
+	if err != nil {
+		panic(err)
+	}
+	if err := client.CreatePersistentSubscription(ctx, streamName, groupName, options); err != nil {
+		panic(err)
+	}
+	defer client.DeletePersistentSubscription(ctx, streamName, groupName, kurrentdb.DeletePersistentSubscriptionOptions{})
+	fmt.Printf("Created group %s on %s\n", groupName, streamName)
+
+	// === ROUND TRIP ===
+	info, err := client.GetPersistentSubscriptionInfo(ctx, streamName, groupName, kurrentdb.GetPersistentSubscriptionOptions{})
+	if err != nil {
+		panic(err)
+	}
+	if info.Settings == nil {
+		fail("group info should include settings")
+	} else {
+		want, got := options.Settings, info.Settings
+		fmt.Printf("  sent:     %+v\n  returned: %+v\n", *want, *got)
+
+		compare := []struct {
+			name      string
+			want, got interface{}
+		}{
+			{"MessageTimeout", want.MessageTimeout, got.MessageTimeout},
+			{"MaxRetryCount", want.MaxRetryCount, got.MaxRetryCount},
+			{"CheckpointAfter", want.CheckpointAfter, got.CheckpointAfter},
+			{"MinCheckpointCount", want.CheckpointLowerBound, got.CheckpointLowerBound},
+			{"MaxCheckpointCount", want.CheckpointUpperBound, got.CheckpointUpperBound},
+			{"LiveBufferSize", want.LiveBufferSize, got.LiveBufferSize},
+			{"ReadBatchSize", want.ReadBatchSize, got.ReadBatchSize},
+			{"HistoryBufferSize", want.HistoryBufferSize, got.HistoryBufferSize},
+			{"ExtraStatistics", want.ExtraStatistics, got.ExtraStatistics},
+			{"NamedConsumerStrategy", want.ConsumerStrategyName, got.ConsumerStrategyName},
+		}
+		for _, c := range compare {
+			if c.want != c.got {
+				fail("%s should round-trip: sent %v, got %v", c.name, c.want, c.got)
+			}
+		}
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	if passed {
+		fmt.Println("\nAll persistent settings tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -5,8 +5,16 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
@@ -16,48 +24,434 @@ import (
 
 type EventHandler func(state map[string]interface{}, data map[string]interface{}) map[string]interface{}
 
+// FallibleHandler is an EventHandler that reports failure with an error rather than a panic, e.g.
+// when an update to an external system it mirrors is refused
+type FallibleHandler func(state map[string]interface{}, data map[string]interface{}) (map[string]interface{}, error)
+
 type Projection struct {
 	Name       string
 	State      map[string]map[string]interface{}
 	Checkpoint *kurrentdb.Position
-	handlers   map[string]EventHandler
+	handlers   map[string]FallibleHandler
+	reactions  map[string]Reaction
+	onPanic    func(err *HandlerPanicError)
+	useNumber  bool
+	metrics    *HandlerMetrics
+	notifier   *Notifier
+	keys       *stateKeys
+
+	// processed counts applied events, skipped those without a handler and failed those whose
+	// handler or decode failed; all guarded by mu
+	processed int64
+	skipped   int64
+	failed    int64
+
+	// mu guards State and Checkpoint while Apply runs, for Read and WaitFor on other goroutines
+	mu sync.Mutex
+	// advanced is closed and replaced whenever the checkpoint moves, waking WaitFor
+	advanced chan struct{}
+}
+
+// HandlerPanicError is returned by Apply when a handler panics, e.g. on a failed type assertion
+type HandlerPanicError struct {
+	EventType string
+	StreamID  string
+	Value     interface{}
+	Stack     []byte
+}
+
+func (e *HandlerPanicError) Error() string {
+	return fmt.Sprintf("handler for %s on %s panicked: %v", e.EventType, e.StreamID, e.Value)
+}
+
+// Unwrap returns the panic value when it is an error, so errors.Is sees through handlers that
+// panic with a sentinel
+func (e *HandlerPanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
 }
 
 func NewProjection(name string) *Projection {
 	return &Projection{
-		Name:     name,
-		State:    make(map[string]map[string]interface{}),
-		handlers: make(map[string]EventHandler),
+		Name:      name,
+		State:     make(map[string]map[string]interface{}),
+		handlers:  make(map[string]FallibleHandler),
+		reactions: make(map[string]Reaction),
+		metrics:   NewHandlerMetrics(),
 	}
 }
 
 func (p *Projection) On(eventType string, handler EventHandler) *Projection {
+	return p.OnFallible(eventType, func(state, data map[string]interface{}) (map[string]interface{}, error) {
+		return handler(state, data), nil
+	})
+}
+
+// OnFallible registers a handler that may return an error. Like a panic, the error fails the event
+// and rolls back whatever the handler changed in the state before returning it.
+func (p *Projection) OnFallible(eventType string, handler FallibleHandler) *Projection {
 	p.handlers[eventType] = handler
 	return p
 }
 
+// UseNumber makes handlers receive numbers as json.Number instead of float64, so integers beyond
+// 2^53 and decimal amounts arrive exactly as written. Handlers then convert each number themselves,
+// e.g. with ParseMoney for amounts.
+func (p *Projection) UseNumber() *Projection {
+	p.useNumber = true
+	return p
+}
+
+// OnPanic registers a callback invoked with every recovered handler panic, e.g. for logging the stack
+func (p *Projection) OnPanic(callback func(err *HandlerPanicError)) *Projection {
+	p.onPanic = callback
+	return p
+}
+
+// Get returns the state of streamID, encoding it first when the projection is keyed with KeyBy.
+// It locks against Apply, so inside Read use getLocked instead.
 func (p *Projection) Get(streamID string) map[string]interface{} {
-	return p.State[streamID]
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.getLocked(streamID)
+}
+
+// getLocked is Get for callers already holding p.mu, i.e. inside Read
+func (p *Projection) getLocked(streamID string) map[string]interface{} {
+	return p.State[p.Key(streamID)]
+}
+
+// Result returns the state in the shape of a server-side partitioned projection
+// (fromCategory(...).foreachStream()): one entry per partition key (the stream ID),
+// each holding the state exactly as GET /projection/{name}/state?partition={key} returns it.
+//
+// Divergences from the server:
+//   - values are normalized through JSON, so numbers become float64 and typed slices become []interface{}
+//   - partitions with no handled events are absent rather than holding the $init state
+//   - there is no separate "result" (transformBy/outputState); the state doubles as the result
+//
+// It returns an error naming the first partition, in key order, whose state can't be encoded as
+// JSON, e.g. one holding a NaN or a channel. Like Get it locks against Apply, so don't call it
+// inside Read.
+func (p *Projection) Result() (map[string]any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make(map[string]any, len(p.State))
+	for _, partition := range slices.Sorted(maps.Keys(p.State)) {
+		raw, err := json.Marshal(p.State[partition])
+		if err != nil {
+			return nil, fmt.Errorf("encode partition %s: %w", partition, err)
+		}
+		var normalized any
+		if err := json.Unmarshal(raw, &normalized); err != nil {
+			return nil, fmt.Errorf("decode partition %s: %w", partition, err)
+		}
+		result[partition] = normalized
+	}
+	return result, nil
+}
+
+// MarshalJSON encodes the projection as its server-compatible Result
+func (p *Projection) MarshalJSON() ([]byte, error) {
+	result, err := p.Result()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// Apply runs the handler registered for the event type. It returns false for unhandled types, and an
+// error if the data isn't JSON or the handler fails or panics; on error the state and checkpoint are left
+// unchanged, even if the handler had already mutated the state, so the caller decides whether to skip,
+// park, or stop. Side effects from reactions are discarded; use
+// ApplyDry for reactive projections.
+func (p *Projection) Apply(event *kurrentdb.RecordedEvent, position kurrentdb.Position) (bool, error) {
+	applied, _, err := p.apply(event, position)
+	return applied, err
+}
+
+func (p *Projection) apply(event *kurrentdb.RecordedEvent, position kurrentdb.Position) (bool, SideEffects, error) {
+	applied, effects, err := p.applyLocked(event, position)
+	// Published once the lock is released, so subscribers reading the state see the change and a
+	// blocking subscriber doesn't hold up queries
+	if applied && p.notifier != nil {
+		p.notifier.Publish(ProjectionNotification{StreamID: event.StreamID, Position: position, EventType: event.EventType})
+	}
+	return applied, effects, err
 }
 
-func (p *Projection) Apply(event *kurrentdb.RecordedEvent, position kurrentdb.Position) bool {
-	handler, ok := p.handlers[event.EventType]
-	if !ok {
-		return false
+func (p *Projection) applyLocked(event *kurrentdb.RecordedEvent, position kurrentdb.Position) (bool, SideEffects, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	handler := p.handlers[event.EventType]
+	reaction := p.reactions[event.EventType]
+	if handler == nil && reaction == nil {
+		p.skipped++
+		return false, nil, nil
 	}
 
+	started := time.Now()
+	effects, err := p.handle(event, position, handler, reaction)
+	p.metrics.Record(event.EventType, time.Since(started), err)
+	if err != nil {
+		p.failed++
+		return false, nil, err
+	}
+	p.processed++
+	return true, effects, nil
+}
+
+// handle runs the handler and reaction registered for the event, either of which may be nil, and
+// advances state and checkpoint; p.mu must be held
+func (p *Projection) handle(event *kurrentdb.RecordedEvent, position kurrentdb.Position, handler FallibleHandler, reaction Reaction) (effects SideEffects, err error) {
 	streamID := event.StreamID
-	current := p.State[streamID]
+	key, err := p.keys.claim(streamID)
+	if err != nil {
+		return nil, fmt.Errorf("key %s on %s: %w", event.EventType, streamID, err)
+	}
+	current, existed := p.State[key]
 	if current == nil {
 		current = make(map[string]interface{})
 	}
 
-	var data map[string]interface{}
-	json.Unmarshal(event.Data, &data)
+	data, err := p.decode(event.Data)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s on %s: %w", event.EventType, streamID, err)
+	}
 
-	p.State[streamID] = handler(current, data)
+	// Handlers mutate state in place, so a failure part-way through would leave the stream half
+	// updated. Restore a copy taken before the event instead, making each event all or nothing.
+	snapshot := snapshotState(current)
+	defer func() {
+		if err == nil {
+			return
+		}
+		if existed {
+			p.State[key] = snapshot
+		} else {
+			delete(p.State, key)
+		}
+	}()
+
+	// Handlers may mutate state in place, so reactions get a copy of the state from before
+	var before map[string]interface{}
+	if reaction != nil {
+		before = maps.Clone(current)
+	}
+
+	next := current
+	if handler != nil {
+		if next, err = p.invoke(handler, event, current, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if reaction != nil {
+		if effects, err = p.react(reaction, event, before, next, data); err != nil {
+			return nil, err
+		}
+	}
+
+	p.State[key] = next
+	p.keys.own(key, streamID)
+	p.setCheckpoint(position)
+	return effects, nil
+}
+
+// setCheckpoint moves the checkpoint and wakes WaitFor; p.mu must be held
+func (p *Projection) setCheckpoint(position kurrentdb.Position) {
 	p.Checkpoint = &position
-	return true
+	if p.advanced != nil {
+		close(p.advanced)
+		p.advanced = nil
+	}
+}
+
+// Advance moves the checkpoint to position for an event the projection doesn't handle, or a
+// filter checkpoint, so WaitFor isn't left waiting on positions that will never be applied. An
+// earlier position is ignored.
+func (p *Projection) Advance(position kurrentdb.Position) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Checkpoint == nil || positionAfter(position, *p.Checkpoint) {
+		p.setCheckpoint(position)
+	}
+}
+
+// WaitFor blocks until the checkpoint has reached position, e.g. the position AppendAndPosition
+// returned, so a query made next reflects that write. It returns at once if the checkpoint is
+// already there, even when ctx is done, and otherwise wraps ctx's error when ctx ends first.
+func (p *Projection) WaitFor(ctx context.Context, position kurrentdb.Position) error {
+	for {
+		p.mu.Lock()
+		checkpoint := p.Checkpoint
+		if checkpoint != nil && !positionAfter(position, *checkpoint) {
+			p.mu.Unlock()
+			return nil
+		}
+		if p.advanced == nil {
+			p.advanced = make(chan struct{})
+		}
+		advanced := p.advanced
+		p.mu.Unlock()
+
+		select {
+		case <-advanced:
+		case <-ctx.Done():
+			at := "no checkpoint"
+			if checkpoint != nil {
+				at = fmt.Sprintf("%d/%d", checkpoint.Commit, checkpoint.Prepare)
+			}
+			return fmt.Errorf("projection %s at %s has not reached %d/%d: %w", p.Name, at, position.Commit, position.Prepare, context.Cause(ctx))
+		}
+	}
+}
+
+// Changed returns a channel closed the next time the checkpoint moves, after an event is applied
+// or Advance. Take it before reading the state, then read again once it closes, so no change
+// slips in between; each change needs a fresh call.
+func (p *Projection) Changed() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.advanced == nil {
+		p.advanced = make(chan struct{})
+	}
+	return p.advanced
+}
+
+// Read runs fn with the projection locked against Apply, for queries while a subscription applies
+// events on another goroutine. fn reads State directly or through getLocked; Get and Result would
+// deadlock.
+func (p *Projection) Read(fn func(p *Projection)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fn(p)
+}
+
+// Follow subscribes to $all on store with options and applies every event until done returns true
+// for an applied event, then returns nil. Events that fail to apply are logged and skipped;
+// unhandled events and filter checkpoints advance the checkpoint. It returns the drop error if
+// the subscription ends first, e.g. when ctx is cancelled.
+func (p *Projection) Follow(ctx context.Context, store EventStore, options kurrentdb.SubscribeToAllOptions, done func(event *kurrentdb.RecordedEvent) bool) error {
+	subscription, err := store.SubscribeToAll(ctx, options)
+	if err != nil {
+		return err
+	}
+	defer subscription.Close()
+
+	for {
+		event := subscription.Recv()
+		if event.SubscriptionDropped != nil {
+			if event.SubscriptionDropped.Error != nil {
+				return event.SubscriptionDropped.Error
+			}
+			return errors.New("subscription dropped")
+		}
+		if event.CheckPointReached != nil {
+			p.Advance(*event.CheckPointReached)
+		}
+		if event.EventAppeared == nil {
+			continue
+		}
+
+		recorded := Resolve(event.EventAppeared, false)
+		position := Resolve(event.EventAppeared, true).Position
+		applied, err := p.Apply(recorded, position)
+		if err != nil {
+			// Skip the poison event and keep projecting
+			fmt.Printf("  Skipped: %v\n", err)
+		}
+		if !applied {
+			p.Advance(position)
+			continue
+		}
+		if done(recorded) {
+			return nil
+		}
+	}
+}
+
+// decode unmarshals event data into the map handlers receive
+func (p *Projection) decode(raw []byte) (map[string]interface{}, error) {
+	if p.useNumber {
+		return decodeNumbers(raw)
+	}
+	data := make(map[string]interface{})
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// invoke calls handler, wrapping its error and converting a panic into a *HandlerPanicError
+func (p *Projection) invoke(handler FallibleHandler, event *kurrentdb.RecordedEvent, state, data map[string]interface{}) (next map[string]interface{}, err error) {
+	defer p.recoverHandler(event, &err)
+
+	if next, err = handler(state, data); err != nil {
+		return nil, fmt.Errorf("handle %s on %s: %w", event.EventType, event.StreamID, err)
+	}
+	return next, nil
+}
+
+// snapshotState copies state deeply enough to restore it after a handler mutated it in place:
+// nested maps and slices are copied with their types, other values are shared
+func snapshotState(state map[string]interface{}) map[string]interface{} {
+	return deepCopyValue(reflect.ValueOf(state)).Interface().(map[string]interface{})
+}
+
+func deepCopyValue(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(deepCopyValue(value.Elem()))
+		return copied
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.MakeMapWithSize(value.Type(), value.Len())
+		for iter := value.MapRange(); iter.Next(); {
+			copied.SetMapIndex(iter.Key(), deepCopyValue(iter.Value()))
+		}
+		return copied
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			copied.Index(i).Set(deepCopyValue(value.Index(i)))
+		}
+		return copied
+	}
+	return value
+}
+
+// recoverHandler converts a panic in a handler or reaction into a *HandlerPanicError in *err
+func (p *Projection) recoverHandler(event *kurrentdb.RecordedEvent, err *error) {
+	if r := recover(); r != nil {
+		panicErr := &HandlerPanicError{
+			EventType: event.EventType,
+			StreamID:  event.StreamID,
+			Value:     r,
+			Stack:     debug.Stack(),
+		}
+		if p.onPanic != nil {
+			p.onPanic(panicErr)
+		}
+		*err = panicErr
+	}
 }
 
 // === ORDER EVENTS (for projection) ===
@@ -77,31 +471,9 @@ type ProjectionOrderShipped struct {
 	ShippedAt string `json:"shippedAt"`
 }
 
-// RunProjection runs the in-memory projection example
-func RunProjection() {
-	ctx := context.Background()
-
-	// === SETUP ===
-	connectionString := os.Getenv("KURRENTDB_CONNECTION_STRING")
-	if connectionString == "" {
-		connectionString = "esdb://localhost:2113?tls=false"
-	}
-
-	settings, err := kurrentdb.ParseConnectionString(connectionString)
-	if err != nil {
-		panic(err)
-	}
-
-	client, err := kurrentdb.NewClient(settings)
-	if err != nil {
-		panic(err)
-	}
-	defer client.Close()
-
-	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
-
-	// === DEFINE PROJECTION ===
-	orderProjection := NewProjection("OrderSummary").
+// NewOrderSummaryProjection builds the per-order summary projection used by the examples
+func NewOrderSummaryProjection() *Projection {
+	return NewProjection("OrderSummary").
 		On("OrderCreated", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
 			return map[string]interface{}{
 				"orderId":    data["orderId"],
@@ -112,7 +484,7 @@ func RunProjection() {
 			}
 		}).
 		On("ItemAdded", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
-			items := state["items"].([]string)
+			items := stringSlice(state["items"])
 			amount := state["amount"].(float64)
 			state["items"] = append(items, data["item"].(string))
 			state["amount"] = amount + data["price"].(float64)
@@ -127,14 +499,44 @@ func RunProjection() {
 			state["status"] = "completed"
 			return state
 		})
+}
+
+// stringSlice accepts both the []string handlers build and the []interface{} that decoding a
+// JSON snapshot produces; any other value panics like a failed type assertion
+func stringSlice(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, item.(string))
+		}
+		return items
+	}
+	panic(fmt.Sprintf("expected a string list, got %T", value))
+}
+
+// RunProjection runs the in-memory projection example
+func RunProjection() {
+	ctx := context.Background()
+
+	// === SETUP ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	// === DEFINE PROJECTION ===
+	orderProjection := NewOrderSummaryProjection()
 
 	// === TEST: Append test events ===
 	fmt.Println("\n=== Appending test events ===")
 
 	orderId1 := uuid.New().String()
 	orderId2 := uuid.New().String()
-	stream1 := fmt.Sprintf("order-%s", orderId1)
-	stream2 := fmt.Sprintf("order-%s", orderId2)
+	stream1 := Streams.Name("order", orderId1)
+	stream2 := Streams.Name("order", orderId2)
 
 	makeEvent := func(eventType string, data interface{}) kurrentdb.EventData {
 		jsonData, _ := json.Marshal(data)
@@ -167,52 +569,30 @@ func RunProjection() {
 	// === RUN PROJECTION ===
 	fmt.Println("\n=== Running projection ===")
 
-	subscription, err := client.SubscribeToAll(ctx, kurrentdb.SubscribeToAllOptions{
-		From:   kurrentdb.Start{},
-		Filter: kurrentdb.ExcludeSystemEventsFilter(),
-	})
-	if err != nil {
-		panic(err)
-	}
-
 	processedCount := 0
 	targetStreams := map[string]bool{stream1: true, stream2: true}
 	targetEventsCount := map[string]int{stream1: 0, stream2: 0}
 	expectedCounts := map[string]int{stream1: 4, stream2: 2} // Order 1: 4 events, Order 2: 2 events
 
-	for {
-		event := subscription.Recv()
+	err := orderProjection.Follow(ctx, NewClientStore(client), kurrentdb.SubscribeToAllOptions{
+		From:   kurrentdb.Start{},
+		Filter: kurrentdb.ExcludeSystemEventsFilter(),
+	}, func(evt *kurrentdb.RecordedEvent) bool {
+		processedCount++
+		fmt.Printf("  Processed: %s on %s\n", evt.EventType, evt.StreamID)
 
-		if event.SubscriptionDropped != nil {
-			break
+		if targetStreams[evt.StreamID] {
+			targetEventsCount[evt.StreamID]++
 		}
 
-		if event.EventAppeared != nil {
-			evt := event.EventAppeared.OriginalEvent()
-			position := event.EventAppeared.OriginalEvent().Position
-
-			if orderProjection.Apply(evt, position) {
-				processedCount++
-				fmt.Printf("  Processed: %s on %s\n", evt.EventType, evt.StreamID)
-
-				if targetStreams[evt.StreamID] {
-					targetEventsCount[evt.StreamID]++
-				}
-			}
-
-			// Stop when we've processed all our test events for both streams
-			if targetEventsCount[stream1] >= expectedCounts[stream1] &&
-				targetEventsCount[stream2] >= expectedCounts[stream2] {
-				break
-			}
-
-			// Safety limit
-			if processedCount > 200 {
-				break
-			}
-		}
+		// Stop when we've processed all our test events for both streams, or at the safety limit
+		return targetEventsCount[stream1] >= expectedCounts[stream1] &&
+			targetEventsCount[stream2] >= expectedCounts[stream2] ||
+			processedCount > 200
+	})
+	if err != nil {
+		fmt.Printf("  Subscription dropped: %v\n", err)
 	}
-	subscription.Close()
 
 	// === VERIFY RESULTS ===
 	fmt.Println("\n=== Projection Results ===")
@@ -226,6 +606,29 @@ func RunProjection() {
 	fmt.Printf("\nOrder 1 (%s):\n%s\n", stream1, string(order1JSON))
 	fmt.Printf("\nOrder 2 (%s):\n%s\n", stream2, string(order2JSON))
 
+	// === QUERY ===
+	// Every order this projection has seen, including earlier runs', filtered in memory
+	shippedOver100 := orderProjection.Query(ShippedOrdersOver(100))
+	fmt.Printf("\nShipped orders over $100: %d\n", len(shippedOver100))
+	queried := make(map[string]bool)
+	for _, result := range shippedOver100 {
+		queried[result.StreamID] = true
+	}
+
+	// === DIAGNOSTICS DUMP ===
+	// The file to attach to a support ticket when the read model looks wrong
+	dumpPath := filepath.Join(os.TempDir(), fmt.Sprintf("%s-%s.json", orderProjection.Name, time.Now().UTC().Format("20060102T150405Z")))
+	dumpFile, err := os.Create(dumpPath)
+	if err == nil {
+		err = errors.Join(orderProjection.Dump(dumpFile), dumpFile.Close())
+	}
+	var dump ProjectionDump
+	if err == nil {
+		raw, _ := os.ReadFile(dumpPath)
+		err = json.Unmarshal(raw, &dump)
+	}
+	fmt.Printf("\nDiagnostics dump written to %s: %d processed, %d skipped, %d streams\n", dumpPath, dump.Processed, dump.Skipped, len(dump.Streams))
+
 	// === ASSERTIONS ===
 	fmt.Println("\n=== Running assertions ===")
 
@@ -261,6 +664,18 @@ func RunProjection() {
 		passed = false
 	}
 
+	// Query assertions: order 1 shipped at 125, order 2 never shipped
+	if !queried[stream1] || queried[stream2] {
+		fmt.Printf("FAIL: Shipped orders over $100 should include order 1 but not order 2, got %v / %v\n", queried[stream1], queried[stream2])
+		passed = false
+	}
+
+	// Dump assertions
+	if err != nil || dump.Processed < 6 || dump.Streams[stream1] == nil || dump.Checkpoint == nil || dump.Checkpoint.Commit != orderProjection.Checkpoint.Commit {
+		fmt.Printf("FAIL: Dump should hold the checkpoint, counts and both orders, got %+v (%v)\n", dump, err)
+		passed = false
+	}
+
 	if passed {
 		fmt.Println("\nAll projection tests passed!")
 	} else {
@@ -0,0 +1,672 @@
+// KurrentDB Go Projection Framework Checks
+// Exercises the in-memory projection framework with synthetic events, no server required
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// syntheticEvent builds a recorded event as a subscription would deliver it
+func syntheticEvent(streamID, eventType string, eventNumber uint64, commit uint64, data string) *kurrentdb.RecordedEvent {
+	return &kurrentdb.RecordedEvent{
+		EventID:     uuid.New(),
+		EventType:   eventType,
+		ContentType: "application/json",
+		StreamID:    streamID,
+		EventNumber: eventNumber,
+		Position:    kurrentdb.Position{Commit: commit, Prepare: commit},
+		Data:        []byte(data),
+	}
+}
+
+// checker collects the failures of a checks command and reports them when it is done
+type checker struct {
+	name   string
+	passed bool
+}
+
+// newChecker announces the named checks
+func newChecker(name string) *checker {
+	fmt.Printf("=== Running %s checks ===\n", name)
+	return &checker{name: name, passed: true}
+}
+
+// Check records a failure when ok is false, without stopping the remaining checks
+func (c *checker) Check(ok bool, format string, args ...interface{}) {
+	if !ok {
+		fmt.Printf("FAIL: "+format+"\n", args...)
+		c.passed = false
+	}
+}
+
+// Passed reports whether every check so far succeeded
+func (c *checker) Passed() bool {
+	return c.passed
+}
+
+// Done prints the summary and exits non-zero if any check failed
+func (c *checker) Done() {
+	if c.passed {
+		fmt.Printf("\nAll %s tests passed!\n", c.name)
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
+
+// RunProjectionChecks runs the framework checks and exits non-zero on failure
+func RunProjectionChecks() {
+	checks := newChecker("projection framework")
+
+	// === HANDLER PANIC RECOVERY ===
+	fmt.Println("\n--- Handler panic recovery ---")
+	{
+		var recovered []*HandlerPanicError
+		projection := NewOrderSummaryProjection().OnPanic(func(err *HandlerPanicError) {
+			recovered = append(recovered, err)
+			fmt.Printf("  OnPanic: %v (%d byte stack)\n", err, len(err.Stack))
+		})
+
+		events := []*kurrentdb.RecordedEvent{
+			syntheticEvent("order-1", "OrderCreated", 0, 100, `{"orderId":"1","customerId":"c-1","amount":100}`),
+			// price is a string, so the ItemAdded handler's .(float64) assertion panics
+			syntheticEvent("order-1", "ItemAdded", 1, 200, `{"item":"Widget","price":"25"}`),
+			syntheticEvent("order-1", "OrderShipped", 2, 300, `{"shippedAt":"2024-01-15T10:00:00Z"}`),
+		}
+
+		var errs []error
+		for _, event := range events {
+			if _, err := projection.Apply(event, event.Position); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		var panicErr *HandlerPanicError
+		checks.Check(len(errs) == 1 && errors.As(errs[0], &panicErr), "expected one HandlerPanicError, got %v", errs)
+		checks.Check(len(recovered) == 1, "OnPanic should be called once, got %d", len(recovered))
+		checks.Check(projection.Get("order-1")["status"] == "shipped", "projection should continue past the panic, status = %v", projection.Get("order-1")["status"])
+		checks.Check(projection.Get("order-1")["amount"] == float64(100), "panicking event should not change the amount, got %v", projection.Get("order-1")["amount"])
+		checks.Check(projection.Checkpoint != nil && projection.Checkpoint.Commit == 300, "checkpoint should advance past the panic, got %v", projection.Checkpoint)
+	}
+
+	// === ROLLBACK ON HANDLER ERROR ===
+	fmt.Println("\n--- Rollback on handler error ---")
+	{
+		projection := NewOrderSummaryProjection()
+		for _, event := range []*kurrentdb.RecordedEvent{
+			syntheticEvent("order-1", "OrderCreated", 0, 100, `{"orderId":"1","customerId":"c-1","amount":100}`),
+			syntheticEvent("order-1", "ItemAdded", 1, 200, `{"item":"Widget","price":25}`),
+		} {
+			projection.Apply(event, event.Position)
+		}
+		before, _ := json.Marshal(projection.Get("order-1"))
+
+		// The handler appends the item before it reads the missing price and panics
+		failing := syntheticEvent("order-1", "ItemAdded", 2, 300, `{"item":"Gadget"}`)
+		_, err := projection.Apply(failing, failing.Position)
+		after, _ := json.Marshal(projection.Get("order-1"))
+		fmt.Printf("  failed ItemAdded: %v\n  state before: %s\n  state after:  %s\n", err, before, after)
+		checks.Check(err != nil, "an ItemAdded without a price should fail")
+		checks.Check(string(after) == string(before), "a failed ItemAdded should leave the order as it was, got %s instead of %s", after, before)
+		checks.Check(projection.Checkpoint.Commit == 200, "a failed event should not move the checkpoint, got %d", projection.Checkpoint.Commit)
+
+		// A fallible handler that changes nested state, then reports an error
+		errRefused := errors.New("inventory service refused the reservation")
+		reservations := NewProjection("reservations").
+			OnFallible("Reserved", func(state, data map[string]interface{}) (map[string]interface{}, error) {
+				skus, _ := state["skus"].(map[string]interface{})
+				if skus == nil {
+					skus = make(map[string]interface{})
+					state["skus"] = skus
+				}
+				sku := data["sku"].(string)
+				count, _ := skus[sku].(float64)
+				skus[sku] = count + 1
+				history, _ := state["history"].([]string)
+				state["history"] = append(history, sku)
+				if data["refuse"] == true {
+					return nil, errRefused
+				}
+				return state, nil
+			})
+		reserve := func(stream string, number, commit uint64, data string) error {
+			event := syntheticEvent(stream, "Reserved", number, commit, data)
+			_, err := reservations.Apply(event, event.Position)
+			return err
+		}
+		checks.Check(reserve("cart-1", 0, 100, `{"sku":"A"}`) == nil, "a reservation should apply")
+		err = reserve("cart-1", 1, 200, `{"sku":"A","refuse":true}`)
+		checks.Check(errors.Is(err, errRefused), "a handler's error should be returned, got %v", err)
+		cart, _ := json.Marshal(reservations.Get("cart-1"))
+		checks.Check(string(cart) == `{"history":["A"],"skus":{"A":1}}`, "nested changes of a failed event should be rolled back, got %s", cart)
+		err = reserve("cart-2", 0, 300, `{"sku":"B","refuse":true}`)
+		_, exists := reservations.State["cart-2"]
+		checks.Check(err != nil && !exists, "a stream whose first event fails should have no state")
+
+		// A reaction failing after the handler succeeded fails the whole event
+		reacting := NewOrderSummaryProjection().React("OrderShipped", func(before, after, data map[string]interface{}) SideEffects {
+			panic("notifier misconfigured")
+		})
+		created := syntheticEvent("order-2", "OrderCreated", 0, 100, `{"orderId":"2","customerId":"c-2","amount":50}`)
+		shipped := syntheticEvent("order-2", "OrderShipped", 1, 200, `{"shippedAt":"2024-01-15T10:00:00Z"}`)
+		reacting.Apply(created, created.Position)
+		_, err = reacting.Apply(shipped, shipped.Position)
+		checks.Check(err != nil && reacting.Get("order-2")["status"] == "created", "a failed reaction should roll back its handler's changes, status = %v", reacting.Get("order-2")["status"])
+
+		// Atomicity per event: interleave failures with successes and compare with a projection
+		// that only ever saw the successes
+		counter := func() *Projection {
+			return NewProjection("atomic").OnFallible("Counted", func(state, data map[string]interface{}) (map[string]interface{}, error) {
+				count, _ := state["count"].(float64)
+				state["count"] = count + 1
+				totals, _ := state["totals"].(map[string]interface{})
+				if totals == nil {
+					totals = make(map[string]interface{})
+					state["totals"] = totals
+				}
+				totals[data["kind"].(string)] = count + 1
+				seen, _ := state["seen"].([]string)
+				state["seen"] = append(seen, data["kind"].(string))
+				if data["fail"] == true {
+					return nil, errors.New("refused")
+				}
+				return state, nil
+			})
+		}
+		mixed, reference := counter(), counter()
+		failures := 0
+		for i := uint64(0); i < 300; i++ {
+			stream := fmt.Sprintf("counter-%d", i%4)
+			fail := i%3 == 1 || i%7 == 0
+			data := fmt.Sprintf(`{"kind":"k%d","fail":%v}`, i%5, fail)
+			event := syntheticEvent(stream, "Counted", i, (i+1)*10, data)
+			_, err := mixed.Apply(event, event.Position)
+			checks.Check((err != nil) == fail, "event %d should fail only when told to, got %v", i, err)
+			if fail {
+				failures++
+				continue
+			}
+			reference.Apply(event, event.Position)
+		}
+		got, _ := json.Marshal(mixed.State)
+		want, _ := json.Marshal(reference.State)
+		fmt.Printf("  %d of 300 events failed part-way; state matches the %d that succeeded: %v\n", failures, 300-failures, string(got) == string(want))
+		checks.Check(string(got) == string(want), "failed events should leave no trace in the state")
+	}
+
+	// === SNAPSHOT MIGRATION ===
+	fmt.Println("\n--- Snapshot migration ---")
+	{
+		dir, err := os.MkdirTemp("", "projection-snapshots")
+		if err != nil {
+			panic(err)
+		}
+		defer os.RemoveAll(dir)
+
+		writeSnapshot := func(name, content string) string {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				panic(err)
+			}
+			return path
+		}
+
+		v1 := writeSnapshot("v1.json", `{
+			"version": 1,
+			"name": "OrderSummary",
+			"checkpoint": {"Commit": 500, "Prepare": 500},
+			"state": {"order-1": {"orderId": "1", "total": 125, "state": "created", "itemCount": 1}}
+		}`)
+
+		projection := NewOrderSummaryProjection()
+		loaded, err := LoadProjectionSnapshot(v1, projection, OrderSummaryMigrations())
+		checks.Check(loaded && err == nil, "v1 snapshot should load, got %v", err)
+
+		order := projection.Get("order-1")
+		fmt.Printf("  v1 migrated to v%d: %v\n", CurrentSnapshotVersion, order)
+		checks.Check(order["amount"] == float64(125) && order["status"] == "created", "v1 fields should be renamed, got %v", order)
+		checks.Check(order["total"] == nil && order["itemCount"] == nil, "v1-only fields should be gone, got %v", order)
+		checks.Check(projection.Checkpoint != nil && projection.Checkpoint.Commit == 500, "checkpoint should be restored, got %v", projection.Checkpoint)
+
+		// The migrated state keeps projecting, including items restored from JSON
+		event := syntheticEvent("order-1", "ItemAdded", 2, 600, `{"item":"Gadget","price":30}`)
+		_, err = projection.Apply(event, event.Position)
+		checks.Check(err == nil && projection.Get("order-1")["amount"] == float64(155), "migrated state should accept new events, got %v", err)
+
+		resaved := filepath.Join(dir, "resaved.json")
+		checks.Check(SaveProjectionSnapshot(resaved, projection) == nil, "save failed")
+		reloaded := NewOrderSummaryProjection()
+		_, err = LoadProjectionSnapshot(resaved, reloaded, NewSnapshotMigrator())
+		checks.Check(err == nil, "current-version snapshot should load without migrations, got %v", err)
+		checks.Check(len(stringSlice(reloaded.Get("order-1")["items"])) == 1, "items should round-trip, got %v", reloaded.Get("order-1"))
+
+		// No path and too-new snapshots fail clearly
+		_, err = LoadProjectionSnapshot(v1, NewOrderSummaryProjection(), NewSnapshotMigrator())
+		fmt.Printf("  v1 without migrations: %v\n", err)
+		checks.Check(errors.Is(err, ErrNoMigrationPath), "missing migration should fail with ErrNoMigrationPath, got %v", err)
+
+		v9 := writeSnapshot("v9.json", `{"version": 9, "name": "OrderSummary", "state": {}}`)
+		_, err = LoadProjectionSnapshot(v9, NewOrderSummaryProjection(), OrderSummaryMigrations())
+		fmt.Printf("  v9: %v\n", err)
+		checks.Check(errors.Is(err, ErrSnapshotTooNew), "newer snapshot should fail with ErrSnapshotTooNew, got %v", err)
+
+		loaded, err = LoadProjectionSnapshot(filepath.Join(dir, "missing.json"), NewOrderSummaryProjection(), OrderSummaryMigrations())
+		checks.Check(!loaded && err == nil, "missing snapshot should mean replay from the start, got %v", err)
+	}
+
+	// === DIAGNOSTICS DUMP ===
+	fmt.Println("\n--- Diagnostics dump ---")
+	{
+		dir, err := os.MkdirTemp("", "projection-dumps")
+		if err != nil {
+			panic(err)
+		}
+		defer os.RemoveAll(dir)
+
+		projection := NewOrderSummaryProjection()
+		for _, event := range []*kurrentdb.RecordedEvent{
+			syntheticEvent("order-1", "OrderCreated", 0, 100, `{"orderId":"1","customerId":"c-1","amount":100}`),
+			syntheticEvent("order-1", "OrderViewed", 1, 200, `{}`),
+			syntheticEvent("order-1", "ItemAdded", 2, 300, `not json`),
+			syntheticEvent("order-2", "OrderCreated", 0, 400, `{"orderId":"2","customerId":"c-2","amount":50}`),
+		} {
+			projection.Apply(event, event.Position)
+		}
+
+		// What a support ticket would get attached
+		path := filepath.Join(dir, "order-summary.json")
+		file, err := os.Create(path)
+		if err != nil {
+			panic(err)
+		}
+		dumpErr := projection.Dump(file)
+		file.Close()
+		raw, _ := os.ReadFile(path)
+		fmt.Printf("  %s:\n%s", filepath.Base(path), raw)
+
+		var dump ProjectionDump
+		decodeErr := json.Unmarshal(raw, &dump)
+		checks.Check(dumpErr == nil && decodeErr == nil, "dump should be valid JSON, got %v / %v", dumpErr, decodeErr)
+		checks.Check(dump.Projection == "OrderSummary" && time.Since(dump.DumpedAt) < time.Minute, "dump should carry the name and a timestamp, got %q at %v", dump.Projection, dump.DumpedAt)
+		checks.Check(dump.Checkpoint != nil && dump.Checkpoint.Commit == 400, "dump checkpoint = %+v", dump.Checkpoint)
+		checks.Check(dump.Processed == 2 && dump.Skipped == 1 && dump.Failed == 1, "dump counts = %d/%d/%d", dump.Processed, dump.Skipped, dump.Failed)
+		checks.Check(fmt.Sprint(dump.Handlers) == "[ItemAdded OrderCompleted OrderCreated OrderShipped]", "dump handlers = %v", dump.Handlers)
+		var order2 bytes.Buffer
+		json.Compact(&order2, dump.Streams["order-2"])
+		checks.Check(len(dump.Streams) == 2 && order2.String() == `{"amount":50,"customerId":"c-2","items":[],"orderId":"2","status":"created"}`,
+			"dump streams = %s", dump.Streams)
+
+		// Dumps taken while another goroutine applies events must each describe one moment:
+		// the counts in the state add up to the processed count and match the checkpoint
+		counter := NewProjection("counter").On("Counted", func(state, _ map[string]interface{}) map[string]interface{} {
+			count, _ := state["count"].(float64)
+			state["count"] = count + 1
+			return state
+		})
+		const events = 2000
+		go func() {
+			for i := uint64(1); i <= events; i++ {
+				event := syntheticEvent(fmt.Sprintf("counter-%d", i%7), "Counted", i, i*10, `{}`)
+				counter.Apply(event, event.Position)
+			}
+		}()
+		dumps, incoherent := 0, 0
+		for done := false; !done; dumps++ {
+			var buffer bytes.Buffer
+			var dump ProjectionDump
+			if err := counter.Dump(&buffer); err != nil || json.Unmarshal(buffer.Bytes(), &dump) != nil {
+				incoherent++
+				break
+			}
+			var total int64
+			for _, state := range dump.Streams {
+				var counted struct{ Count int64 }
+				json.Unmarshal(state, &counted)
+				total += counted.Count
+			}
+			if total != dump.Processed || dump.Processed > 0 && (dump.Checkpoint == nil || dump.Checkpoint.Commit != uint64(dump.Processed)*10) {
+				incoherent++
+			}
+			done = dump.Processed == events
+		}
+		fmt.Printf("  %d dumps taken while applying %d events, %d incoherent\n", dumps, events, incoherent)
+		checks.Check(incoherent == 0, "every dump should be a coherent snapshot, %d of %d were not", incoherent, dumps)
+	}
+
+	// === WARM START ===
+	fmt.Println("\n--- Warm start ---")
+	{
+		dir, err := os.MkdirTemp("", "projection-warm-start")
+		if err != nil {
+			panic(err)
+		}
+		defer os.RemoveAll(dir)
+
+		history := []*kurrentdb.RecordedEvent{
+			syntheticEvent("order-1", "OrderCreated", 0, 100, `{"orderId":"1","customerId":"c-1","amount":100}`),
+			syntheticEvent("order-1", "ItemAdded", 1, 200, `{"item":"Widget","price":25}`),
+			syntheticEvent("order-1", "OrderShipped", 2, 300, `{"shippedAt":"2024-01-15T10:00:00Z"}`),
+			syntheticEvent("order-1", "OrderCompleted", 3, 400, `{}`),
+			syntheticEvent("order-2", "OrderCreated", 0, 500, `{"orderId":"2","customerId":"c-2","amount":40}`),
+			syntheticEvent("order-2", "OrderShipped", 1, 600, `{"shippedAt":"2024-01-16T09:00:00Z"}`),
+			syntheticEvent("order-3", "OrderCreated", 0, 700, `{"orderId":"3","customerId":"c-1","amount":60}`),
+			syntheticEvent("order-3", "ItemAdded", 1, 800, `{"item":"Gadget","price":15}`),
+		}
+		later := []*kurrentdb.RecordedEvent{
+			syntheticEvent("order-3", "ItemAdded", 2, 900, `{"item":"Gizmo","price":5}`),
+			syntheticEvent("order-3", "OrderShipped", 3, 1000, `{"shippedAt":"2024-01-17T12:00:00Z"}`),
+			syntheticEvent("order-4", "OrderCreated", 0, 1100, `{"orderId":"4","customerId":"c-3","amount":10}`),
+		}
+		applyAll := func(p *Projection, events []*kurrentdb.RecordedEvent) {
+			for _, event := range events {
+				p.Apply(event, event.Position)
+			}
+		}
+
+		summary := NewOrderSummaryProjection()
+		applyAll(summary, history)
+		snapshot := filepath.Join(dir, "order-summary.json")
+		checks.Check(SaveProjectionSnapshot(snapshot, summary) == nil, "save failed")
+
+		// What a rebuild from the start of $all would produce
+		rebuilt := NewShippedOrdersProjection()
+		applyAll(rebuilt, history)
+
+		warm := NewShippedOrdersProjection()
+		result, err := ShippedOrdersWarmStart().LoadWarmStart(snapshot, warm)
+		got, _ := json.Marshal(warm.State)
+		want, _ := json.Marshal(rebuilt.State)
+		fmt.Printf("  warm-started %d streams at %v: %s\n", result.Streams, warm.Checkpoint, got)
+		checks.Check(err == nil && result.Warm && result.Fallback == nil, "OrderSummary snapshot should seed ShippedOrders, got %v / %v", err, result.Fallback)
+		checks.Check(string(got) == string(want), "warm start should match a rebuild\n  got  %s\n  want %s", got, want)
+		checks.Check(warm.Checkpoint != nil && warm.Checkpoint.Commit == 800, "warm start should continue from the snapshot's checkpoint, got %v", warm.Checkpoint)
+
+		// Continuing from the checkpoint stays in step with the rebuild
+		applyAll(warm, later)
+		applyAll(rebuilt, later)
+		got, _ = json.Marshal(warm.State)
+		want, _ = json.Marshal(rebuilt.State)
+		checks.Check(string(got) == string(want), "events after the checkpoint should keep it in step\n  got  %s\n  want %s", got, want)
+		checks.Check(warm.Get("order-3")["shipped"] == true && warm.Get("order-3")["amount"] == float64(80), "order-3 should ship with 80, got %v", warm.Get("order-3"))
+
+		// A v1 snapshot is migrated first; it has no shippedAt, so only the flag carries over
+		v1 := filepath.Join(dir, "v1.json")
+		os.WriteFile(v1, []byte(`{"version": 1, "name": "OrderSummary", "checkpoint": {"Commit": 500, "Prepare": 500},
+			"state": {"order-1": {"total": 125, "state": "shipped", "itemCount": 1}}}`), 0o644)
+		fromV1 := NewShippedOrdersProjection()
+		result, err = ShippedOrdersWarmStart().LoadWarmStart(v1, fromV1)
+		checks.Check(err == nil && result.Warm && fromV1.Get("order-1")["shipped"] == true && fromV1.Get("order-1")["amount"] == float64(125),
+			"v1 snapshot should migrate and seed, got %v / %v / %v", err, result.Fallback, fromV1.Get("order-1"))
+
+		// Every mismatch falls back to a rebuild and leaves the target untouched
+		untouched := func(p *Projection) bool { return len(p.State) == 0 && p.Checkpoint == nil }
+		fellBack := func(name string, spec WarmStart, path string, target *Projection) {
+			result, err := spec.LoadWarmStart(path, target)
+			fmt.Printf("  %s: %v\n", name, result.Fallback)
+			checks.Check(err == nil && !result.Warm && errors.Is(result.Fallback, ErrIncompatibleSnapshot) && untouched(target),
+				"%s should fall back to a rebuild, got %v / %+v", name, err, result)
+		}
+
+		// Cancellations happened before the checkpoint, but OrderSummary ignored them
+		withCancel := NewShippedOrdersProjection().On("OrderCancelled", func(state, _ map[string]interface{}) map[string]interface{} {
+			state["cancelled"] = true
+			return state
+		})
+		fellBack("extra event type", ShippedOrdersWarmStart(), snapshot, withCancel)
+
+		otherName := filepath.Join(dir, "other.json")
+		os.WriteFile(otherName, []byte(`{"version": 2, "name": "PaymentSummary", "state": {}}`), 0o644)
+		fellBack("other projection", ShippedOrdersWarmStart(), otherName, NewShippedOrdersProjection())
+
+		noMigrations := ShippedOrdersWarmStart()
+		noMigrations.Migrator = NewSnapshotMigrator()
+		fellBack("no migration path", noMigrations, v1, NewShippedOrdersProjection())
+
+		tooNew := filepath.Join(dir, "v9.json")
+		os.WriteFile(tooNew, []byte(`{"version": 9, "name": "OrderSummary", "state": {}}`), 0o644)
+		fellBack("newer snapshot", ShippedOrdersWarmStart(), tooNew, NewShippedOrdersProjection())
+
+		// order-2 is fine, but order-1 lacks a field, so nothing is seeded
+		missingField := filepath.Join(dir, "missing-field.json")
+		os.WriteFile(missingField, []byte(`{"version": 2, "name": "OrderSummary", "checkpoint": {"Commit": 5, "Prepare": 5},
+			"state": {"order-2": {"amount": 40, "status": "shipped"}, "order-1": {"status": "created"}}}`), 0o644)
+		fellBack("missing field", ShippedOrdersWarmStart(), missingField, NewShippedOrdersProjection())
+
+		fellBack("no snapshot", ShippedOrdersWarmStart(), filepath.Join(dir, "missing.json"), NewShippedOrdersProjection())
+
+		corrupt := filepath.Join(dir, "corrupt.json")
+		os.WriteFile(corrupt, []byte(`{"version": 2,`), 0o644)
+		_, err = ShippedOrdersWarmStart().LoadWarmStart(corrupt, NewShippedOrdersProjection())
+		checks.Check(err != nil && !errors.Is(err, ErrIncompatibleSnapshot), "a corrupt snapshot should be an error, not a fallback, got %v", err)
+	}
+
+	// === QUERIES ===
+	fmt.Println("\n--- Query ---")
+	{
+		orders := NewOrderSummaryProjection()
+		commit := uint64(0)
+		apply := func(streamID, eventType string, number uint64, data string) {
+			commit += 100
+			event := syntheticEvent(streamID, eventType, number, commit, data)
+			orders.Apply(event, event.Position)
+		}
+		// order-N: amount and whether it shipped, completed or neither
+		for i, order := range []struct {
+			amount float64
+			status string
+		}{{150, "shipped"}, {90, "shipped"}, {100, "shipped"}, {250, "created"}, {300, "completed"}, {101, "shipped"}} {
+			stream := fmt.Sprintf("order-%d", i+1)
+			apply(stream, "OrderCreated", 0, fmt.Sprintf(`{"orderId":"%d","customerId":"c-1","amount":%g}`, i+1, order.amount))
+			if order.status != "created" {
+				apply(stream, "OrderShipped", 1, `{"shippedAt":"2024-01-15T10:00:00Z"}`)
+			}
+			if order.status == "completed" {
+				apply(stream, "OrderCompleted", 2, `{}`)
+			}
+		}
+
+		shipped := orders.Query(ShippedOrdersOver(100))
+		var ids []string
+		for _, result := range shipped {
+			ids = append(ids, result.StreamID)
+		}
+		fmt.Printf("  shipped orders over $100: %v\n", ids)
+		checks.Check(fmt.Sprint(ids) == "[order-1 order-5 order-6]", "shipped over 100 should be orders 1, 5 and 6 in stream order, got %v", ids)
+		checks.Check(len(shipped) == 3 && shipped[0].State["amount"] == 150.0, "results should carry the state, got %+v", shipped)
+		checks.Check(len(orders.Query(func(string, map[string]interface{}) bool { return false })) == 0, "a predicate matching nothing should return no results")
+		checks.Check(len(orders.Query(func(string, map[string]interface{}) bool { return true })) == 6, "a predicate matching everything should return every stream")
+
+		seen := make(map[string]bool)
+		orders.Query(func(streamID string, state map[string]interface{}) bool {
+			seen[streamID] = true
+			return false
+		})
+		checks.Check(len(seen) == 6, "the predicate should see every stream, saw %d", len(seen))
+
+		shipped[0].State["status"] = "tampered"
+		checks.Check(orders.Get("order-1")["status"] == "shipped", "changing a result should not change the projection")
+
+		// Nested values are copied too, so a caller editing a result's items can't reach the state
+		apply("order-1", "ItemAdded", 2, `{"item":"Widget","price":25}`)
+		withItems := orders.Query(func(streamID string, _ map[string]interface{}) bool { return streamID == "order-1" })
+		items, ok := withItems[0].State["items"].([]string)
+		if ok && len(items) == 1 {
+			items[0] = "tampered"
+		}
+		checks.Check(ok && fmt.Sprint(orders.Get("order-1")["items"]) == "[Widget]", "changing a result's items should not change the projection, got %v", orders.Get("order-1")["items"])
+	}
+
+	fmt.Println("\n--- Pagination ---")
+	{
+		counter := NewProjection("counter").On("Counted", func(state, _ map[string]interface{}) map[string]interface{} {
+			count, _ := state["count"].(float64)
+			state["count"] = count + 1
+			return state
+		})
+		commit := uint64(0)
+		count := func(streamID string) {
+			commit += 10
+			event := syntheticEvent(streamID, "Counted", 0, commit, `{}`)
+			counter.Apply(event, event.Position)
+		}
+		for i := 0; i < 25; i++ {
+			count(fmt.Sprintf("counter-%02d", i))
+		}
+		even := func(streamID string, state map[string]interface{}) bool {
+			var n int
+			fmt.Sscanf(streamID, "counter-%d", &n)
+			return n%2 == 0
+		}
+
+		// Page through all 13 even counters 5 at a time, changing state between pages
+		var pages [][]string
+		after := ""
+		for {
+			page := counter.QueryPage(even, after, 5)
+			var ids []string
+			for _, result := range page.Results {
+				ids = append(ids, result.StreamID)
+			}
+			pages = append(pages, ids)
+			// Writes between pages: an existing stream before the cursor changes, one after it is created
+			count("counter-00")
+			count(fmt.Sprintf("counter-%02d", 30+2*len(pages)))
+			if page.Next == "" {
+				break
+			}
+			after = page.Next
+		}
+		fmt.Printf("  pages: %v\n", pages)
+		checks.Check(len(pages) == 3 && fmt.Sprint(pages[2]) == "[counter-20 counter-22 counter-24 counter-32 counter-34]",
+			"streams created ahead of the cursor should show up on later pages, got %v", pages)
+		checks.Check(len(pages) > 0 && fmt.Sprint(pages[0]) == "[counter-00 counter-02 counter-04 counter-06 counter-08]", "first page = %v", pages)
+
+		returned := make(map[string]int)
+		for _, page := range pages {
+			for _, id := range page {
+				returned[id]++
+			}
+		}
+		for i := 0; i < 25; i += 2 {
+			id := fmt.Sprintf("counter-%02d", i)
+			checks.Check(returned[id] == 1, "%s existed throughout and should be returned exactly once, got %d", id, returned[id])
+		}
+		var flat []string
+		for _, page := range pages {
+			flat = append(flat, page...)
+		}
+		checks.Check(slices.IsSorted(flat), "pages should continue in stream order, got %v", flat)
+
+		last := counter.QueryPage(even, "counter-99", 5)
+		checks.Check(len(last.Results) == 0 && last.Next == "", "a page after every stream should be empty, got %+v", last)
+		all := counter.QueryPage(even, "", 0)
+		checks.Check(all.Next == "" && len(all.Results) == len(counter.Query(even)), "limit 0 should return every match in one page")
+		exact := counter.QueryPage(func(streamID string, _ map[string]interface{}) bool { return streamID < "counter-05" }, "", 5)
+		checks.Check(len(exact.Results) == 5 && exact.Next == "", "a page holding exactly the last matches should have no Next, got %q", exact.Next)
+	}
+
+	// === SIDE EFFECT DESCRIPTORS ===
+	fmt.Println("\n--- Side effect descriptors ---")
+	{
+		// No client anywhere: the reaction only describes the append
+		projection := NewInventoryProjection(10)
+		events := []*kurrentdb.RecordedEvent{
+			syntheticEvent("inventory-sku1", "StockReceived", 0, 100, `{"sku":"sku1","quantity":20}`),
+			syntheticEvent("inventory-sku1", "StockReserved", 1, 200, `{"sku":"sku1","quantity":8}`),
+			syntheticEvent("inventory-sku1", "StockReserved", 2, 300, `{"sku":"sku1","quantity":6}`),
+			syntheticEvent("inventory-sku1", "StockReserved", 3, 400, `{"sku":"sku1","quantity":2}`),
+		}
+
+		var all []SideEffects
+		for _, event := range events {
+			effects, err := projection.ApplyDry(event, event.Position)
+			checks.Check(err == nil, "ApplyDry failed: %v", err)
+			all = append(all, effects)
+		}
+
+		checks.Check(len(all[0]) == 0 && len(all[1]) == 0, "no effects expected above the threshold, got %v %v", all[0], all[1])
+		checks.Check(len(all[3]) == 0, "an already-low reservation should not alert again, got %v", all[3])
+
+		alerts := all[2].OfType("LowStockDetected")
+		checks.Check(len(alerts) == 1, "expected one LowStockDetected descriptor, got %v", all[2])
+		if len(alerts) == 1 {
+			alert := alerts[0]
+			fmt.Printf("  descriptor: %s %s %v\n", alert.Stream, alert.EventType, alert.Data)
+			checks.Check(alert.Stream == "stockalert-sku1", "alert stream = %s", alert.Stream)
+			checks.Check(alert.Data["onHand"] == float64(6) && alert.Data["threshold"] == float64(10), "alert data = %v", alert.Data)
+			checks.Check(alert.CausationID == events[2].EventID, "alert should be caused by the crossing event")
+			checks.Check(alert.EventID != uuid.Nil && alert.EventID == uuid.NewSHA1(events[2].EventID, []byte("side-effect-0")), "alert event ID should be derived from the causing event")
+		}
+		checks.Check(len(all[2]) == 2 && all[2][1].Kind == ExternalCall && all[2][1].Target == "reorder", "expected a reorder call descriptor, got %v", all[2])
+		checks.Check(projection.Get("inventory-sku1")["onHand"] == float64(4), "state should advance under ApplyDry, got %v", projection.Get("inventory-sku1"))
+	}
+
+	// === RESULT ===
+	fmt.Println("\n--- Result ---")
+	{
+		projection := NewOrderSummaryProjection()
+		created := syntheticEvent("order-1", "OrderCreated", 0, 100, `{"orderId":"1","customerId":"c-1","amount":100}`)
+		projection.Apply(created, created.Position)
+		result, err := projection.Result()
+		order, _ := result["order-1"].(map[string]interface{})
+		checks.Check(err == nil && order["amount"] == float64(100), "the result should hold the order, got %v (%v)", result, err)
+
+		// A handler that leaves a value JSON can't encode, such as a NaN from dividing by zero
+		projection.State["order-2"] = map[string]interface{}{"average": math.NaN()}
+		result, err = projection.Result()
+		fmt.Printf("  %v\n", err)
+		checks.Check(err != nil && strings.Contains(err.Error(), "order-2") && result == nil, "a state JSON can't encode should fail the result, got %v", result)
+		_, err = json.Marshal(projection)
+		checks.Check(err != nil, "encoding the projection should fail too")
+
+		// Get and Result lock against Apply, so they're safe while another goroutine applies events;
+		// unlocked, the map reads race the handler's writes
+		counter := NewProjection("counter").On("Counted", func(state, _ map[string]interface{}) map[string]interface{} {
+			count, _ := state["count"].(float64)
+			state["count"] = count + 1
+			return state
+		})
+		const events = 2000
+		applied := make(chan struct{})
+		go func() {
+			defer close(applied)
+			for i := uint64(1); i <= events; i++ {
+				event := syntheticEvent(fmt.Sprintf("counter-%d", i%7), "Counted", i, i*10, `{}`)
+				counter.Apply(event, event.Position)
+			}
+		}()
+		failures := 0
+		for running := true; running; {
+			select {
+			case <-applied:
+				running = false
+			default:
+			}
+			if _, err := counter.Result(); err != nil {
+				failures++
+			}
+			counter.Get("counter-1")
+		}
+		result, err = counter.Result()
+		total := 0.0
+		for _, state := range result {
+			count, _ := state.(map[string]interface{})["count"].(float64)
+			total += count
+		}
+		checks.Check(failures == 0 && err == nil && total == events, "results taken while applying should succeed and end with every event, got %d failures, total %v", failures, total)
+	}
+
+	checks.Done()
+}
@@ -0,0 +1,266 @@
+// KurrentDB Go Client Example - Server-side event type filters derived from a projection
+// Demonstrates: Subscribing to $all for only the event types a projection handles, with a fallback for large sets
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === EVENT TYPE FILTER ===
+
+// EventTypeFilterMaxLength caps the regex EventTypeFilter builds. The server evaluates the filter
+// against every event in $all, so a long alternation costs server CPU on each one; past this length
+// the filter falls back to a common prefix, or to no filter.
+var EventTypeFilterMaxLength = 1000
+
+// eventTypePrefixMinLength is the shortest common prefix worth filtering on; shorter ones, like
+// "O", let through nearly as much as no filter
+const eventTypePrefixMinLength = 3
+
+// EventTypes returns the event types the projection has a handler or a reaction for, sorted
+func (p *Projection) EventTypes() []string {
+	var eventTypes []string
+	for eventType := range p.handlers {
+		eventTypes = append(eventTypes, eventType)
+	}
+	for eventType := range p.reactions {
+		if _, handled := p.handlers[eventType]; !handled {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	slices.Sort(eventTypes)
+	return eventTypes
+}
+
+// SubscriptionFilter returns a server-side filter delivering only the event types the projection
+// handles, as EventTypeFilter builds it. Register every handler first: a type added afterwards
+// isn't delivered until the subscription is restarted with a new filter.
+func (p *Projection) SubscriptionFilter() *kurrentdb.SubscriptionFilter {
+	return EventTypeFilter(p.EventTypes())
+}
+
+// EventTypeFilter returns a server-side filter for the given event types, in decreasing precision:
+//   - a regex matching exactly these types, while it fits in EventTypeFilterMaxLength
+//   - otherwise the types' longest common prefix, a superset the handlers narrow down
+//   - otherwise nil, for no filter: every event is delivered and unhandled ones are ignored
+//
+// Whatever it returns, events it lets through may still be unhandled, so Apply's false return
+// stays meaningful. A filtered subscription sees few events, so set CheckpointInterval and advance
+// the projection on CheckPointReached, or its checkpoint lags far behind the head.
+func EventTypeFilter(eventTypes []string) *kurrentdb.SubscriptionFilter {
+	if len(eventTypes) == 0 {
+		return nil
+	}
+
+	quoted := make([]string, len(eventTypes))
+	for i, eventType := range eventTypes {
+		quoted[i] = regexp.QuoteMeta(eventType)
+	}
+	if regex := "^(?:" + strings.Join(quoted, "|") + ")$"; len(regex) <= EventTypeFilterMaxLength {
+		return &kurrentdb.SubscriptionFilter{Type: kurrentdb.EventFilterType, Regex: regex}
+	}
+
+	prefix := eventTypes[0]
+	for _, eventType := range eventTypes[1:] {
+		for !strings.HasPrefix(eventType, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if len(prefix) >= eventTypePrefixMinLength {
+		return &kurrentdb.SubscriptionFilter{Type: kurrentdb.EventFilterType, Prefixes: []string{prefix}}
+	}
+	return nil
+}
+
+// describeFilter prints a filter for the demos
+func describeFilter(filter *kurrentdb.SubscriptionFilter) string {
+	switch {
+	case filter == nil:
+		return "no filter"
+	case filter.Regex != "":
+		return fmt.Sprintf("regex %s", filter.Regex)
+	default:
+		return fmt.Sprintf("prefixes %v", filter.Prefixes)
+	}
+}
+
+// RunProjectionFilterChecks verifies the filters derived from small, large and irregular type sets, no server required
+func RunProjectionFilterChecks() {
+	checks := newChecker("projection filter")
+
+	// --- Exact types ---
+	fmt.Println("\n--- Exact types ---")
+	projection := NewOrderSummaryProjection().
+		React("OrderCancelled", func(before, after, data map[string]interface{}) SideEffects { return nil })
+	filter := projection.SubscriptionFilter()
+	fmt.Printf("  %s\n", describeFilter(filter))
+	checks.Check(fmt.Sprint(projection.EventTypes()) == "[ItemAdded OrderCancelled OrderCompleted OrderCreated OrderShipped]",
+		"handlers and reactions should both count, got %v", projection.EventTypes())
+	checks.Check(filter != nil && filter.Type == kurrentdb.EventFilterType && filter.Regex != "", "a small set should give an event type regex, got %+v", filter)
+	if filter != nil && filter.Regex != "" {
+		regex := regexp.MustCompile(filter.Regex)
+		for _, c := range []struct {
+			eventType string
+			match     bool
+		}{
+			{"OrderCreated", true},
+			{"OrderCancelled", true},
+			{"OrderCreatedV2", false},
+			{"LegacyOrderCreated", false},
+			{"PageViewed", false},
+			{"$metadata", false},
+		} {
+			checks.Check(regex.MatchString(c.eventType) == c.match, "%s: expected match %v", c.eventType, c.match)
+		}
+	}
+	dotted := EventTypeFilter([]string{"Orders.Contracts.OrderCreated"})
+	checks.Check(dotted != nil && !regexp.MustCompile(dotted.Regex).MatchString("Orders-Contracts-OrderCreated"),
+		"dots in type names should be matched literally, got %+v", dotted)
+	checks.Check(EventTypeFilter(nil) == nil, "no types should give no filter")
+
+	// --- Large sets ---
+	fmt.Println("\n--- Large sets ---")
+	var contracts, mixed []string
+	for i := 0; i < 60; i++ {
+		contracts = append(contracts, fmt.Sprintf("Orders.Contracts.Event%02dHappenedToTheOrder", i))
+		mixed = append(mixed, fmt.Sprintf("%cEvent%02dHappenedSomewhere", 'A'+i%26, i))
+	}
+	large := EventTypeFilter(contracts)
+	irregular := EventTypeFilter(mixed)
+	fmt.Printf("  60 contract types: %s\n  60 unrelated types: %s\n", describeFilter(large), describeFilter(irregular))
+	checks.Check(large != nil && large.Regex == "" && fmt.Sprint(large.Prefixes) == "[Orders.Contracts.Event]",
+		"a set past the regex limit should fall back to its common prefix, got %+v", large)
+	checks.Check(irregular == nil, "a large set without a useful common prefix should fall back to no filter, got %+v", irregular)
+
+	checks.Done()
+}
+
+// RunProjectionFilter runs the order summary projection over $all with and without the derived
+// filter, amid unrelated traffic, and compares what each receives
+func RunProjectionFilter() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	head, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+	appendJSON := func(stream, eventType string, data interface{}) AppendPosition {
+		payload, _ := json.Marshal(data)
+		written, err := AppendAndPosition(ctx, client, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        payload,
+		})
+		if err != nil {
+			panic(err)
+		}
+		return written
+	}
+
+	// === TRAFFIC ===
+	// Two orders among a busy clickstream and an unhandled note
+	orders := []string{Streams.Name("order", uuid.New().String()), Streams.Name("order", uuid.New().String())}
+	clicks := Streams.Name("clickstream", uuid.New().String())
+	for i, order := range orders {
+		appendJSON(order, "OrderCreated", ProjectionOrderCreated{OrderID: order, CustomerID: "cust-1", Amount: 0})
+		for j := 0; j < 100; j++ {
+			appendJSON(clicks, "PageViewed", map[string]string{"page": fmt.Sprintf("/products/%d", j), "session": order})
+		}
+		appendJSON(order, "ItemAdded", ProjectionItemAdded{Item: "Widget", Price: float64(10 * (i + 1))})
+		appendJSON(order, "OrderNoteAdded", map[string]string{"note": "gift"})
+	}
+	last := appendJSON(orders[0], "OrderShipped", ProjectionOrderShipped{ShippedAt: "2024-01-15T10:00:00Z"})
+	fmt.Println("Wrote 7 order events and 200 page views")
+
+	// run catches the order summary up to the last write, counting what the server delivered
+	type delivery struct {
+		Events, Bytes, Applied int
+		Types                  map[string]int
+		Orders                 []map[string]interface{}
+	}
+	run := func(filter *kurrentdb.SubscriptionFilter) delivery {
+		projection := NewOrderSummaryProjection()
+		options := kurrentdb.SubscribeToAllOptions{From: head}
+		if filter != nil {
+			options.Filter = filter
+		}
+		subscription := NewMeteredSubscription(client, options)
+		subscription.StopAt = &last.Position
+
+		result := delivery{Types: make(map[string]int)}
+		err := subscription.Run(ctx, func(event *kurrentdb.ResolvedEvent) error {
+			recorded := Resolve(event, false)
+			result.Events++
+			result.Bytes += len(recorded.Data) + len(recorded.UserMetadata)
+			result.Types[recorded.EventType]++
+			if applied, _ := projection.Apply(recorded, Resolve(event, true).Position); applied {
+				result.Applied++
+			}
+			return nil
+		})
+		if err != nil {
+			panic(err)
+		}
+		for _, order := range orders {
+			result.Orders = append(result.Orders, projection.Get(order))
+		}
+		return result
+	}
+
+	// === UNFILTERED ===
+	fmt.Println("\n=== Unfiltered $all ===")
+	unfiltered := run(nil)
+	fmt.Printf("  %d events (%d bytes) delivered, %d applied\n", unfiltered.Events, unfiltered.Bytes, unfiltered.Applied)
+
+	// === FILTERED ===
+	filter := NewOrderSummaryProjection().SubscriptionFilter()
+	fmt.Printf("\n=== Filtered by %s ===\n", describeFilter(filter))
+	filtered := run(filter)
+	fmt.Printf("  %d events (%d bytes) delivered, %d applied\n", filtered.Events, filtered.Bytes, filtered.Applied)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if filtered.Events >= unfiltered.Events-200 {
+		fmt.Printf("FAIL: The filter should keep at least the 200 page views off the wire, got %d vs %d\n", filtered.Events, unfiltered.Events)
+		passed = false
+	}
+	if filtered.Types["PageViewed"] != 0 || filtered.Types["OrderNoteAdded"] != 0 {
+		fmt.Printf("FAIL: Only handled types should be delivered, got %v\n", filtered.Types)
+		passed = false
+	}
+	if filtered.Applied != unfiltered.Applied || filtered.Events != filtered.Applied {
+		fmt.Printf("FAIL: Every delivered event should be applied, as many as unfiltered, got %d of %d vs %d\n",
+			filtered.Applied, filtered.Events, unfiltered.Applied)
+		passed = false
+	}
+	if fmt.Sprint(filtered.Orders) != fmt.Sprint(unfiltered.Orders) || filtered.Orders[0] == nil || filtered.Orders[0]["status"] != "shipped" {
+		fmt.Printf("FAIL: Both runs should build the same order summaries, got %v and %v\n", filtered.Orders, unfiltered.Orders)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll projection filter tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,323 @@
+// KurrentDB Go Client Example - Encoded projection state keys
+// Demonstrates: Keying projected state by a normalized form of the stream ID, and what happens when two streams share a key
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === KEY ENCODING ===
+
+// KeyEncoder turns a stream ID into the key its state is stored under, e.g. lowercased and
+// prefixed for a read-model store whose keys are case-insensitive
+type KeyEncoder func(streamID string) string
+
+// KeyDecoder turns a key back into the identifier queries are made by, e.g. the customer ID. An
+// encoder need not be reversible, so this isn't necessarily the stream ID.
+type KeyDecoder func(key string) string
+
+// KeyCollisionPolicy decides what happens when two streams encode to the same key
+type KeyCollisionPolicy int
+
+const (
+	// RejectCollisions keeps a key for the first stream applied to it: events of any other stream
+	// encoding to the key fail with ErrKeyCollision, leaving the state as it was. Follow logs and
+	// skips them like any other failed event. Use it when a collision means the encoder is wrong.
+	RejectCollisions KeyCollisionPolicy = iota
+	// MergeCollisions folds every stream encoding to a key into one state, in the order their
+	// events are applied. Use it when streams differing only in the encoded-away part, like case,
+	// are meant to be one entity.
+	MergeCollisions
+)
+
+// ErrKeyCollision is returned by Apply under RejectCollisions for an event whose stream encodes to a
+// key another stream already holds
+var ErrKeyCollision = errors.New("state key already held by another stream")
+
+// stateKeys encodes stream IDs for a projection and remembers which streams have been applied to
+// each key; guarded by the projection's mu
+type stateKeys struct {
+	encode  KeyEncoder
+	decode  KeyDecoder
+	policy  KeyCollisionPolicy
+	streams map[string][]string
+}
+
+// KeyBy stores each stream's state under encode(streamID) instead of the stream ID. Get encodes its
+// argument the same way; State, Query, Result and Dump hold the encoded keys, which DecodeKey turns
+// back into IDs. decode may be nil when queries use the keys as they are. Set it before applying
+// events: state already held stays under its old keys.
+func (p *Projection) KeyBy(encode KeyEncoder, decode KeyDecoder, policy KeyCollisionPolicy) *Projection {
+	p.keys = &stateKeys{encode: encode, decode: decode, policy: policy, streams: make(map[string][]string)}
+	return p
+}
+
+// Key returns the key the state of streamID is stored under
+func (p *Projection) Key(streamID string) string {
+	if p.keys == nil {
+		return streamID
+	}
+	return p.keys.encode(streamID)
+}
+
+// DecodeKey returns the identifier for a key from State or a query result; without a decoder it
+// returns the key
+func (p *Projection) DecodeKey(key string) string {
+	if p.keys == nil || p.keys.decode == nil {
+		return key
+	}
+	return p.keys.decode(key)
+}
+
+// StreamsFor returns the streams applied to key, in the order they were first applied. Under
+// MergeCollisions there may be several; state restored from a snapshot has none until its streams
+// are applied again.
+func (p *Projection) StreamsFor(key string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys == nil {
+		if _, ok := p.State[key]; ok {
+			return []string{key}
+		}
+		return nil
+	}
+	return slices.Clone(p.keys.streams[key])
+}
+
+// claim returns the key for streamID, or ErrKeyCollision if the policy doesn't let the stream use
+// it. A nil k keys state by stream ID.
+func (k *stateKeys) claim(streamID string) (string, error) {
+	if k == nil {
+		return streamID, nil
+	}
+	key := k.encode(streamID)
+	if key == "" {
+		return "", fmt.Errorf("stream %s encodes to an empty key", streamID)
+	}
+	streams := k.streams[key]
+	if k.policy == RejectCollisions && len(streams) > 0 && streams[0] != streamID {
+		return "", fmt.Errorf("%w: %s encodes to %q, held by %s", ErrKeyCollision, streamID, key, streams[0])
+	}
+	return key, nil
+}
+
+// own records that streamID was applied to key, once claim succeeded and the event was applied
+func (k *stateKeys) own(key, streamID string) {
+	if k == nil || slices.Contains(k.streams[key], streamID) {
+		return
+	}
+	k.streams[key] = append(k.streams[key], streamID)
+}
+
+// CustomerKey keys customer-{id} streams by their customer ID, trimmed and lowercased, so
+// customer-ACME-01 and customer-acme-01 share the key customer:acme-01. Streams of other categories
+// keep their stream ID.
+func CustomerKey(streamID string) string {
+	category, id, ok := Streams.Parse(streamID)
+	if !ok || category != "customer" {
+		return streamID
+	}
+	return "customer:" + strings.ToLower(strings.TrimSpace(id))
+}
+
+// CustomerIDFromKey returns the normalized customer ID in a key from CustomerKey
+func CustomerIDFromKey(key string) string {
+	return strings.TrimPrefix(key, "customer:")
+}
+
+// NewCustomerProfileProjection tracks each customer's name and order count, keyed by the normalized
+// customer ID under policy
+func NewCustomerProfileProjection(policy KeyCollisionPolicy) *Projection {
+	return NewProjection("CustomerProfile").
+		KeyBy(CustomerKey, CustomerIDFromKey, policy).
+		On("CustomerRegistered", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			state["name"] = data["name"]
+			if _, ok := state["orders"]; !ok {
+				state["orders"] = float64(0)
+			}
+			return state
+		}).
+		On("CustomerOrdered", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			orders, _ := state["orders"].(float64)
+			state["orders"] = orders + 1
+			return state
+		})
+}
+
+// === CHECKS ===
+
+// RunProjectionKeysChecks applies customer events to projections keyed by the normalized customer
+// ID. No server required.
+func RunProjectionKeysChecks() {
+	checks := newChecker("projection keys")
+
+	var commit uint64
+	event := func(stream, eventType, data string) *kurrentdb.RecordedEvent {
+		commit++
+		return syntheticEvent(stream, eventType, commit, commit, data)
+	}
+	apply := func(p *Projection, e *kurrentdb.RecordedEvent) error {
+		_, err := p.Apply(e, e.Position)
+		return err
+	}
+
+	fmt.Println("\n--- Encoding ---")
+	checks.Check(CustomerKey("customer-ACME-01") == "customer:acme-01" && CustomerKey("customer- Acme-01 ") == "customer:acme-01",
+		"customer IDs should be trimmed and lowercased, got %q", CustomerKey("customer-ACME-01"))
+	checks.Check(CustomerKey("order-ACME-01") == "order-ACME-01", "other categories should keep their stream ID")
+	checks.Check(CustomerIDFromKey(CustomerKey("customer-ACME-01")) == "acme-01", "decoding should give the customer ID")
+
+	profiles := NewCustomerProfileProjection(RejectCollisions)
+	err := apply(profiles, event("customer-ACME-01", "CustomerRegistered", `{"name":"Acme"}`))
+	apply(profiles, event("customer-ACME-01", "CustomerOrdered", `{}`))
+	_, stored := profiles.State["customer:acme-01"]
+	_, raw := profiles.State["customer-ACME-01"]
+	result, resultErr := profiles.Result()
+	fmt.Printf("  keys: %v\n", result)
+	checks.Check(resultErr == nil, "the result should encode: %v", resultErr)
+	checks.Check(err == nil && stored && !raw, "state should be stored under the encoded key, got %v (%v)", profiles.State, err)
+	checks.Check(profiles.Get("customer-ACME-01")["orders"] == float64(1), "Get should encode the stream ID, got %v", profiles.Get("customer-ACME-01"))
+	checks.Check(profiles.Get("customer-acme-01")["orders"] == float64(1), "Get should find the state by any stream encoding to its key")
+	checks.Check(slices.Equal(profiles.StreamsFor("customer:acme-01"), []string{"customer-ACME-01"}), "the key should record its stream, got %v", profiles.StreamsFor("customer:acme-01"))
+
+	fmt.Println("\n--- Queries ---")
+	apply(profiles, event("customer-Globex", "CustomerRegistered", `{"name":"Globex"}`))
+	var ids []string
+	for _, result := range profiles.Query(func(key string, state map[string]interface{}) bool { return true }) {
+		ids = append(ids, profiles.DecodeKey(result.StreamID))
+	}
+	checks.Check(slices.Equal(ids, []string{"acme-01", "globex"}), "queries should decode to customer IDs, got %v", ids)
+	checks.Check(NewOrderSummaryProjection().DecodeKey("order-1") == "order-1" && NewOrderSummaryProjection().Key("order-1") == "order-1",
+		"projections without KeyBy should key by stream ID")
+
+	fmt.Println("\n--- Reject collisions ---")
+	before, _ := json.Marshal(profiles.Get("customer-ACME-01"))
+	err = apply(profiles, event("customer-acme-01", "CustomerOrdered", `{}`))
+	after, _ := json.Marshal(profiles.Get("customer-ACME-01"))
+	fmt.Printf("  %v\n", err)
+	checks.Check(errors.Is(err, ErrKeyCollision), "a second stream on the key should be rejected, got %v", err)
+	checks.Check(string(before) == string(after), "a rejected event should leave the state unchanged, got %s", after)
+	checks.Check(apply(profiles, event("customer-ACME-01", "CustomerOrdered", `{}`)) == nil && profiles.Get("customer-ACME-01")["orders"] == float64(2),
+		"the stream holding the key should keep applying")
+
+	// A failed first event doesn't take the key
+	failing := NewCustomerProfileProjection(RejectCollisions)
+	apply(failing, event("customer-Initech", "CustomerRegistered", `not json`))
+	err = apply(failing, event("customer-INITECH", "CustomerRegistered", `{"name":"Initech"}`))
+	checks.Check(err == nil && failing.Get("customer-initech")["name"] == "Initech", "a key should only be held once an event is applied, got %v", err)
+
+	fmt.Println("\n--- Merge collisions ---")
+	merged := NewCustomerProfileProjection(MergeCollisions)
+	apply(merged, event("customer-ACME-01", "CustomerRegistered", `{"name":"Acme"}`))
+	apply(merged, event("customer-ACME-01", "CustomerOrdered", `{}`))
+	err = apply(merged, event("customer-acme-01", "CustomerOrdered", `{}`))
+	streams := merged.StreamsFor("customer:acme-01")
+	fmt.Printf("  %v: %v\n", streams, merged.Get("customer-acme-01"))
+	checks.Check(err == nil && merged.Get("customer-ACME-01")["orders"] == float64(2) && len(merged.State) == 1,
+		"colliding streams should fold into one state, got %v (%v)", merged.State, err)
+	checks.Check(slices.Equal(streams, []string{"customer-ACME-01", "customer-acme-01"}), "the key should record both streams, got %v", streams)
+
+	checks.Done()
+}
+
+// === DEMO ===
+
+// RunProjectionKeys projects customers written by two systems that disagree on the case of
+// customer IDs, keyed by the normalized ID
+func RunProjectionKeys() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	makeEvent := func(eventType string, data interface{}) kurrentdb.EventData {
+		payload, _ := json.Marshal(data)
+		return kurrentdb.EventData{EventID: uuid.New(), EventType: eventType, ContentType: kurrentdb.ContentTypeJson, Data: payload}
+	}
+
+	// === WRITE ===
+	// The CRM registers customers in upper case; the shop records orders against the lower-case ID
+	id := "ACME-" + strings.ToUpper(uuid.New().String()[:8])
+	crm := Streams.Name("customer", id)
+	shop := Streams.Name("customer", strings.ToLower(id))
+	fmt.Printf("\n=== Writing %s and %s ===\n", crm, shop)
+	if _, err := client.AppendToStream(ctx, crm, kurrentdb.AppendToStreamOptions{}, makeEvent("CustomerRegistered", map[string]interface{}{"name": "Acme"})); err != nil {
+		panic(err)
+	}
+	result, err := client.AppendToStream(ctx, shop, kurrentdb.AppendToStreamOptions{},
+		makeEvent("CustomerOrdered", map[string]interface{}{}), makeEvent("CustomerOrdered", map[string]interface{}{}))
+	if err != nil {
+		panic(err)
+	}
+
+	// === PROJECT ===
+	project := func(policy KeyCollisionPolicy) *Projection {
+		profiles := NewCustomerProfileProjection(policy)
+		options := kurrentdb.SubscribeToAllOptions{From: kurrentdb.Start{}, Filter: profiles.SubscriptionFilter()}
+		followCtx, stop := context.WithCancel(ctx)
+		defer stop()
+		go profiles.Follow(followCtx, NewClientStore(client), options, func(*kurrentdb.RecordedEvent) bool { return false })
+		if err := profiles.WaitFor(ctx, kurrentdb.Position{Commit: result.CommitPosition, Prepare: result.PreparePosition}); err != nil {
+			panic(err)
+		}
+		return profiles
+	}
+
+	fmt.Println("\n=== Merging both streams into one customer ===")
+	merged := project(MergeCollisions)
+	var mergedState map[string]interface{}
+	var mergedStreams []string
+	merged.Read(func(p *Projection) { mergedState = p.getLocked(shop) })
+	mergedStreams = merged.StreamsFor(merged.Key(crm))
+	fmt.Printf("  %s (%s): %v from %v\n", merged.Key(crm), merged.DecodeKey(merged.Key(crm)), mergedState, mergedStreams)
+
+	fmt.Println("\n=== Rejecting the second stream ===")
+	strict := project(RejectCollisions)
+	var strictState map[string]interface{}
+	strict.Read(func(p *Projection) { strictState = p.getLocked(crm) })
+	fmt.Printf("  %s: %v\n", strict.Key(crm), strictState)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if mergedState["name"] != "Acme" || mergedState["orders"] != float64(2) {
+		fmt.Printf("FAIL: Expected the merged customer to be Acme with 2 orders, got %v\n", mergedState)
+		passed = false
+	}
+	if !slices.Equal(mergedStreams, []string{crm, shop}) {
+		fmt.Printf("FAIL: Expected the key to hold %s and %s, got %v\n", crm, shop, mergedStreams)
+		passed = false
+	}
+	if merged.DecodeKey(merged.Key(crm)) != strings.ToLower(id) {
+		fmt.Printf("FAIL: Expected the key to decode to %s, got %s\n", strings.ToLower(id), merged.DecodeKey(merged.Key(crm)))
+		passed = false
+	}
+	if strictState["name"] != "Acme" || strictState["orders"] != float64(0) {
+		fmt.Printf("FAIL: Expected the rejected orders to leave Acme with 0 orders, got %v\n", strictState)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll projection keys tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
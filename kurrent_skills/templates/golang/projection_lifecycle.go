@@ -0,0 +1,298 @@
+// KurrentDB Go Client Example - Projection lifecycle hooks
+// Demonstrates: Opening a SQLite read model before replay and closing it however the subscription ends
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+	_ "modernc.org/sqlite"
+)
+
+// === SHIPMENT READ MODEL ===
+
+const shipmentSchema = `
+CREATE TABLE IF NOT EXISTS shipments (
+	stream_id TEXT PRIMARY KEY,
+	parcels   INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS shipment_projection (
+	projection       TEXT PRIMARY KEY,
+	parcels          INTEGER NOT NULL,
+	commit_position  INTEGER,
+	prepare_position INTEGER
+);`
+
+// ShipmentReadModel counts shipped parcels per order in SQLite, with its checkpoint in the same
+// database. It holds a connection only between Open and Close, which MeteredSubscription calls as
+// its Init and Close hooks.
+type ShipmentReadModel struct {
+	Path string
+	Name string
+
+	db *sql.DB
+}
+
+// Open connects, creates the schema and seeds the projection's row, so the first Load and Save
+// find it in place
+func (r *ShipmentReadModel) Open() error {
+	r.db = nil
+	db, err := sql.Open("sqlite", r.Path)
+	if err != nil {
+		return err
+	}
+	db.SetMaxOpenConns(1)
+	r.db = db
+
+	if _, err := db.Exec(shipmentSchema); err != nil {
+		return fmt.Errorf("create schema: %w", err)
+	}
+	_, err = db.Exec(`INSERT OR IGNORE INTO shipment_projection (projection, parcels) VALUES (?, 0)`, r.Name)
+	return err
+}
+
+// Close releases the connection; it is safe after an Open that failed part way
+func (r *ShipmentReadModel) Close() error {
+	if r.db == nil {
+		return nil
+	}
+	return r.db.Close()
+}
+
+// Load returns the saved position, or nil before the first save
+func (r *ShipmentReadModel) Load() (*kurrentdb.Position, error) {
+	var commit, prepare sql.NullInt64
+	err := r.db.QueryRow(`SELECT commit_position, prepare_position FROM shipment_projection WHERE projection = ?`, r.Name).
+		Scan(&commit, &prepare)
+	if err != nil || !commit.Valid {
+		return nil, err
+	}
+	return &kurrentdb.Position{Commit: uint64(commit.Int64), Prepare: uint64(prepare.Int64)}, nil
+}
+
+func (r *ShipmentReadModel) Save(position kurrentdb.Position) error {
+	_, err := r.db.Exec(`UPDATE shipment_projection SET commit_position = ?, prepare_position = ? WHERE projection = ?`,
+		int64(position.Commit), int64(position.Prepare), r.Name)
+	return err
+}
+
+// Apply counts an OrderShipped parcel against its order and the projection total
+func (r *ShipmentReadModel) Apply(event *kurrentdb.RecordedEvent) error {
+	if event.EventType != "OrderShipped" {
+		return nil
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO shipments (stream_id, parcels) VALUES (?, 1)
+		ON CONFLICT (stream_id) DO UPDATE SET parcels = parcels + 1`, event.StreamID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE shipment_projection SET parcels = parcels + 1 WHERE projection = ?`, r.Name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Parcels returns the parcels counted for stream and in total
+func (r *ShipmentReadModel) Parcels(stream string) (int, int, error) {
+	var forStream, total int
+	err := r.db.QueryRow(`SELECT parcels FROM shipment_projection WHERE projection = ?`, r.Name).Scan(&total)
+	if err != nil {
+		return 0, 0, err
+	}
+	err = r.db.QueryRow(`SELECT parcels FROM shipments WHERE stream_id = ?`, stream).Scan(&forStream)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	return forStream, total, err
+}
+
+// RunProjectionLifecycle runs a SQLite read model through a clean stop, a handler error, a handler
+// panic and a failed Init, checking the connection is closed after each
+func RunProjectionLifecycle() {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	dir, err := os.MkdirTemp("", "projection-lifecycle")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	orderStream := Streams.Name("order", uuid.New().String())
+	sequence := 0
+	ship := func(count int) {
+		for i := 0; i < count; i++ {
+			sequence++
+			_, err := client.AppendToStream(ctx, orderStream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+				EventID:     uuid.New(),
+				EventType:   "OrderShipped",
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        []byte(fmt.Sprintf(`{"parcel":%d}`, sequence)),
+			})
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	errCourierDown := errors.New("courier API down")
+
+	// run opens model through the Init hook and closes it through the Close hook, handing each parcel
+	// to fail and stopping after want parcels. It returns the lifecycle as seen by the hooks and
+	// handler, and Run's error; a handler panic is recovered into the error.
+	run := func(name string, model *ShipmentReadModel, want int, fail func(parcel int) error) (lifecycle []string, err error) {
+		subscription := NewMeteredSubscription(client, kurrentdb.SubscribeToAllOptions{
+			Filter: &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{orderStream}},
+		})
+		subscription.Checkpoints = model
+		subscription.
+			Init(func() error {
+				lifecycle = append(lifecycle, "open")
+				return model.Open()
+			}).
+			Close(func() error {
+				lifecycle = append(lifecycle, "close")
+				return model.Close()
+			})
+
+		runCtx, stop := context.WithTimeout(ctx, 10*time.Second)
+		defer stop()
+		defer func() {
+			if value := recover(); value != nil {
+				err = fmt.Errorf("panic: %v", value)
+			}
+			fmt.Printf("  %-14s %v, err %v\n", name, lifecycle, err)
+		}()
+
+		handled := 0
+		err = subscription.Run(runCtx, func(event *kurrentdb.ResolvedEvent) error {
+			recorded := Resolve(event, false)
+			var parcel int
+			fmt.Sscanf(string(recorded.Data), `{"parcel":%d}`, &parcel)
+			if fail != nil {
+				if err := fail(parcel); err != nil {
+					return err
+				}
+			}
+			if err := model.Apply(recorded); err != nil {
+				return err
+			}
+			lifecycle = append(lifecycle, fmt.Sprintf("parcel %d", parcel))
+			if handled++; handled == want {
+				stop()
+			}
+			return nil
+		})
+		return lifecycle, err
+	}
+
+	model := &ShipmentReadModel{Path: filepath.Join(dir, "shipments.db"), Name: "Shipments"}
+	closed := func() bool {
+		return model.db != nil && model.db.Ping() != nil
+	}
+
+	// === CLEAN STOP ===
+	fmt.Println("\n=== Replay, then stop ===")
+	ship(3)
+	clean, cleanErr := run("clean stop", model, 3, nil)
+	closedAfterClean := closed()
+
+	// === HANDLER ERROR ===
+	fmt.Println("\n=== Handler error ===")
+	ship(2)
+	failed, failedErr := run("handler error", model, 2, func(parcel int) error {
+		if parcel == 5 {
+			return errCourierDown
+		}
+		return nil
+	})
+	closedAfterError := closed()
+
+	// === HANDLER PANIC ===
+	fmt.Println("\n=== Handler panic ===")
+	panicked, panicErr := run("handler panic", model, 1, func(parcel int) error {
+		panic(fmt.Sprintf("no label for parcel %d", parcel))
+	})
+	closedAfterPanic := closed()
+
+	// === FAILED INIT ===
+	fmt.Println("\n=== Init fails ===")
+	missing := &ShipmentReadModel{Path: filepath.Join(dir, "missing", "shipments.db"), Name: "Shipments"}
+	initFailed, initErr := run("failed init", missing, 1, nil)
+
+	// === RECOVERED ===
+	fmt.Println("\n=== Restart after the failures ===")
+	recovered, recoveredErr := run("recovered", model, 1, nil)
+
+	if err := model.Open(); err != nil {
+		panic(err)
+	}
+	forStream, total, err := model.Parcels(orderStream)
+	model.Close()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("\nRead model: %d parcels for %s, %d in total\n", forStream, orderStream, total)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if cleanErr != nil || !slices.Equal(clean, []string{"open", "parcel 1", "parcel 2", "parcel 3", "close"}) {
+		fmt.Printf("FAIL: A clean run should open, replay parcels 1-3 and close, got %v (%v)\n", clean, cleanErr)
+		passed = false
+	}
+	if !errors.Is(failedErr, errCourierDown) || !slices.Equal(failed, []string{"open", "parcel 4", "close"}) {
+		fmt.Printf("FAIL: A handler error should still close after resuming at parcel 4, got %v (%v)\n", failed, failedErr)
+		passed = false
+	}
+	if panicErr == nil || !strings.Contains(panicErr.Error(), "no label for parcel 5") || !slices.Equal(panicked, []string{"open", "close"}) {
+		fmt.Printf("FAIL: A handler panic should still close, got %v (%v)\n", panicked, panicErr)
+		passed = false
+	}
+	if !closedAfterClean || !closedAfterError || !closedAfterPanic {
+		fmt.Printf("FAIL: The connection should be closed after every run, got clean=%v error=%v panic=%v\n",
+			closedAfterClean, closedAfterError, closedAfterPanic)
+		passed = false
+	}
+	if initErr == nil || !strings.HasPrefix(initErr.Error(), "init: ") || !slices.Equal(initFailed, []string{"open", "close"}) {
+		fmt.Printf("FAIL: A failed Init should stop before any event and still close, got %v (%v)\n", initFailed, initErr)
+		passed = false
+	}
+	if recoveredErr != nil || !slices.Equal(recovered, []string{"open", "parcel 5", "close"}) {
+		fmt.Printf("FAIL: The restart should resume at parcel 5, got %v (%v)\n", recovered, recoveredErr)
+		passed = false
+	}
+	if forStream != 5 || total != 5 {
+		fmt.Printf("FAIL: Expected 5 parcels counted once each, got %d for the order and %d in total\n", forStream, total)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll projection lifecycle tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
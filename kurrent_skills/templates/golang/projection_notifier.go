@@ -0,0 +1,594 @@
+// KurrentDB Go Client Example - Push notifications of projection changes
+// Demonstrates: Publishing each applied event to buffered subscribers and pushing order updates to a browser over a websocket
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+	"golang.org/x/net/websocket"
+)
+
+// === NOTIFIER ===
+
+// ProjectionNotification says an event changed a projected entity
+type ProjectionNotification struct {
+	StreamID  string             `json:"streamId"`
+	Position  kurrentdb.Position `json:"position"`
+	EventType string             `json:"eventType"`
+}
+
+// ErrSlowConsumer is a Block subscription's Err after it was cut off for not keeping up
+var ErrSlowConsumer = errors.New("subscriber too slow")
+
+// SlowConsumerPolicy is what Publish does when a subscriber's buffer is full
+type SlowConsumerPolicy int
+
+const (
+	// DropOldest discards the oldest buffered notification to make room, so the subscriber sees the
+	// latest changes and counts what it missed. Publish never waits.
+	DropOldest SlowConsumerPolicy = iota
+	// Block waits for room, up to the notifier's BlockTimeout, then cuts the subscriber off. Nothing
+	// is missed while the subscription lasts.
+	Block
+)
+
+// NotifySubscribeOptions configures a subscription
+type NotifySubscribeOptions struct {
+	// Buffer is how many notifications wait for the subscriber; at least 1
+	Buffer int
+	Policy SlowConsumerPolicy
+	// StreamIDs limits the subscription to these entities; empty means all
+	StreamIDs []string
+}
+
+// Notifier fans projection changes out to subscribers. Attach it with Projection.Notify and it
+// publishes after every event the projection applies, from the goroutine applying it: the
+// projection waits on a notification only while a Block subscriber's buffer is full, and never
+// longer than BlockTimeout in total per event. Safe for concurrent use.
+type Notifier struct {
+	// BlockTimeout bounds how long one Publish waits on Block subscribers with full buffers
+	BlockTimeout time.Duration
+
+	mu          sync.Mutex
+	subscribers map[*NotificationSubscription]struct{}
+}
+
+func NewNotifier() *Notifier {
+	return &Notifier{
+		BlockTimeout: time.Second,
+		subscribers:  make(map[*NotificationSubscription]struct{}),
+	}
+}
+
+// Notify makes the projection publish to notifier after each successful apply. Failed and unhandled
+// events publish nothing.
+func (p *Projection) Notify(notifier *Notifier) *Projection {
+	p.notifier = notifier
+	return p
+}
+
+// Subscribe registers a subscriber; Close it when done
+func (n *Notifier) Subscribe(options NotifySubscribeOptions) *NotificationSubscription {
+	subscription := &NotificationSubscription{
+		notifier: n,
+		policy:   options.Policy,
+		c:        make(chan ProjectionNotification, max(options.Buffer, 1)),
+		done:     make(chan struct{}),
+	}
+	if len(options.StreamIDs) > 0 {
+		subscription.streams = make(map[string]bool, len(options.StreamIDs))
+		for _, id := range options.StreamIDs {
+			subscription.streams[id] = true
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.subscribers[subscription] = struct{}{}
+	return subscription
+}
+
+// Subscribers counts the open subscriptions
+func (n *Notifier) Subscribers() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.subscribers)
+}
+
+// Publish delivers notification to every matching subscriber, by their policies
+func (n *Notifier) Publish(notification ProjectionNotification) {
+	n.mu.Lock()
+	subscribers := make([]*NotificationSubscription, 0, len(n.subscribers))
+	for subscription := range n.subscribers {
+		subscribers = append(subscribers, subscription)
+	}
+	n.mu.Unlock()
+
+	// One deadline for all Block subscribers, so several slow ones don't add up
+	var deadline <-chan time.Time
+	for _, subscription := range subscribers {
+		if subscription.streams != nil && !subscription.streams[notification.StreamID] {
+			continue
+		}
+		if subscription.policy == Block && deadline == nil {
+			timer := time.NewTimer(n.BlockTimeout)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+		if !subscription.deliver(notification, deadline) {
+			subscription.stop(ErrSlowConsumer)
+		}
+	}
+}
+
+func (n *Notifier) remove(subscription *NotificationSubscription) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.subscribers, subscription)
+}
+
+// NotificationSubscription receives notifications on C until closed, by Close or by the notifier
+// cutting off a slow Block subscriber; C is then closed.
+type NotificationSubscription struct {
+	notifier *Notifier
+	policy   SlowConsumerPolicy
+	streams  map[string]bool
+	dropped  atomic.Int64
+
+	// mu serialises delivery with closing c; done is closed first, to wake a blocked delivery
+	mu       sync.Mutex
+	c        chan ProjectionNotification
+	closed   bool
+	err      error
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// C delivers the notifications, oldest first
+func (s *NotificationSubscription) C() <-chan ProjectionNotification {
+	return s.c
+}
+
+// Dropped counts the notifications DropOldest discarded
+func (s *NotificationSubscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Err returns ErrSlowConsumer once the notifier cut the subscription off, and nil otherwise
+func (s *NotificationSubscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close unsubscribes and closes C. Buffered notifications can still be received.
+func (s *NotificationSubscription) Close() {
+	s.stop(nil)
+}
+
+func (s *NotificationSubscription) stop(err error) {
+	s.stopOnce.Do(func() { close(s.done) })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed, s.err = true, err
+	close(s.c)
+	s.notifier.remove(s)
+}
+
+// deliver buffers notification, returning false if a Block subscriber's buffer stayed full until
+// deadline
+func (s *NotificationSubscription) deliver(notification ProjectionNotification, deadline <-chan time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return true
+	}
+
+	if s.policy == Block {
+		select {
+		case s.c <- notification:
+			return true
+		case <-s.done:
+			return true
+		case <-deadline:
+			return false
+		}
+	}
+
+	for {
+		select {
+		case s.c <- notification:
+			return true
+		default:
+		}
+		// Full: discard the oldest, unless the subscriber took it in the meantime
+		select {
+		case <-s.c:
+			s.dropped.Add(1)
+		default:
+		}
+	}
+}
+
+// === WEBSOCKET ===
+
+// OrderUpdate is what the websocket pushes for each change: the notification and the order's state
+// after it
+type OrderUpdate struct {
+	ProjectionNotification
+	State json.RawMessage `json:"state"`
+	// Dropped counts updates skipped so far because the browser fell behind; each update carries the
+	// whole state, so only orders without a later update are stale
+	Dropped int64 `json:"dropped"`
+}
+
+// orderUpdatesBuffer is how many updates wait for one browser
+const orderUpdatesBuffer = 64
+
+// NewOrderUpdatesHandler serves a page at / that shows live order updates, and the websocket it
+// reads them from at /updates. Both take ?stream=order-... to watch particular orders; without it
+// every order is pushed. Each browser gets its own DropOldest subscription, so a slow one only
+// misses updates itself.
+func NewOrderUpdatesHandler(projection *Projection, notifier *Notifier) http.Handler {
+	updates := websocket.Server{
+		// Reject pages from other sites, which could otherwise read the feed with the visitor's cookies
+		Handshake: func(config *websocket.Config, r *http.Request) error {
+			origin, err := websocket.Origin(config, r)
+			if err != nil {
+				return err
+			}
+			if origin == nil || origin.Host != r.Host {
+				return fmt.Errorf("origin %v not allowed", origin)
+			}
+			return nil
+		},
+		Handler: func(conn *websocket.Conn) {
+			defer conn.Close()
+			subscription := notifier.Subscribe(NotifySubscribeOptions{
+				Buffer:    orderUpdatesBuffer,
+				Policy:    DropOldest,
+				StreamIDs: conn.Request().URL.Query()["stream"],
+			})
+			defer subscription.Close()
+
+			// The browser sends nothing; reading only notices it going away
+			go func() {
+				io.Copy(io.Discard, conn)
+				subscription.Close()
+			}()
+
+			for notification := range subscription.C() {
+				update := OrderUpdate{ProjectionNotification: notification, Dropped: subscription.Dropped()}
+				projection.Read(func(p *Projection) {
+					update.State, _ = json.Marshal(p.getLocked(notification.StreamID))
+				})
+				if err := websocket.JSON.Send(conn, update); err != nil {
+					return
+				}
+			}
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/updates", updates)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, orderUpdatesPage)
+	})
+	return mux
+}
+
+const orderUpdatesPage = `<!doctype html>
+<title>Order updates</title>
+<table><thead><tr><th>Order</th><th>Event</th><th>Status</th><th>Amount</th></tr></thead><tbody id="orders"></tbody></table>
+<script>
+const rows = {};
+const scheme = location.protocol === "https:" ? "wss:" : "ws:";
+const socket = new WebSocket(scheme + "//" + location.host + "/updates" + location.search);
+socket.onmessage = (message) => {
+  const update = JSON.parse(message.data);
+  let row = rows[update.streamId];
+  if (!row) {
+    row = rows[update.streamId] = document.getElementById("orders").insertRow();
+    for (let i = 0; i < 4; i++) row.insertCell();
+  }
+  const state = update.state || {};
+  [update.streamId, update.eventType, state.status, state.amount].forEach((text, i) => row.cells[i].textContent = text);
+};
+socket.onclose = () => document.title = "Order updates (disconnected)";
+</script>
+`
+
+// dialOrderUpdates opens the websocket of a NewOrderUpdatesHandler at baseURL, as the page does
+func dialOrderUpdates(baseURL string, streams ...string) (*websocket.Conn, error) {
+	query := url.Values{"stream": streams}.Encode()
+	location := strings.Replace(baseURL, "http", "ws", 1) + "/updates?" + query
+	return websocket.Dial(location, "", baseURL)
+}
+
+// receiveOrderUpdates reads updates from conn until it has count or timeout passes
+func receiveOrderUpdates(conn *websocket.Conn, count int, timeout time.Duration) []OrderUpdate {
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	var updates []OrderUpdate
+	for len(updates) < count {
+		var update OrderUpdate
+		if err := websocket.JSON.Receive(conn, &update); err != nil {
+			break
+		}
+		updates = append(updates, update)
+	}
+	return updates
+}
+
+// === CHECKS ===
+
+// RunProjectionNotifierChecks exercises both slow consumer policies, filtering and the websocket
+// handler without a server
+func RunProjectionNotifierChecks() {
+	checks := newChecker("projection notifier")
+
+	notification := func(stream string, commit uint64) ProjectionNotification {
+		return ProjectionNotification{StreamID: stream, Position: kurrentdb.Position{Commit: commit, Prepare: commit}, EventType: "ItemAdded"}
+	}
+	drain := func(subscription *NotificationSubscription) []uint64 {
+		var commits []uint64
+		for {
+			select {
+			case n, ok := <-subscription.C():
+				if !ok {
+					return commits
+				}
+				commits = append(commits, n.Position.Commit)
+			default:
+				return commits
+			}
+		}
+	}
+
+	fmt.Println("\n--- Drop oldest ---")
+	notifier := NewNotifier()
+	latest := notifier.Subscribe(NotifySubscribeOptions{Buffer: 2, Policy: DropOldest})
+	started := time.Now()
+	for commit := uint64(1); commit <= 5; commit++ {
+		notifier.Publish(notification("order-1", commit))
+	}
+	elapsed := time.Since(started)
+	got := drain(latest)
+	fmt.Printf("  kept %v, dropped %d in %v\n", got, latest.Dropped(), elapsed)
+	checks.Check(slices.Equal(got, []uint64{4, 5}) && latest.Dropped() == 3, "a full buffer should keep the newest, got %v with %d dropped", got, latest.Dropped())
+	checks.Check(elapsed < 100*time.Millisecond, "dropping shouldn't wait, took %v", elapsed)
+	latest.Close()
+	checks.Check(notifier.Subscribers() == 0, "Close should unsubscribe, %d left", notifier.Subscribers())
+	notifier.Publish(notification("order-1", 6))
+	_, open := <-latest.C()
+	checks.Check(!open && latest.Err() == nil, "a closed subscription's channel should be closed, with no error")
+
+	fmt.Println("\n--- Block ---")
+	notifier = NewNotifier()
+	notifier.BlockTimeout = 2 * time.Second
+	blocking := notifier.Subscribe(NotifySubscribeOptions{Buffer: 1, Policy: Block})
+	notifier.Publish(notification("order-1", 1))
+	published := make(chan time.Duration)
+	go func() {
+		started := time.Now()
+		notifier.Publish(notification("order-1", 2))
+		published <- time.Since(started)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	first := <-blocking.C()
+	waited := <-published
+	second := <-blocking.C()
+	fmt.Printf("  second publish waited %v\n", waited.Round(10*time.Millisecond))
+	checks.Check(first.Position.Commit == 1 && second.Position.Commit == 2, "Block should deliver everything in order, got %d then %d", first.Position.Commit, second.Position.Commit)
+	checks.Check(waited >= 50*time.Millisecond && waited < time.Second, "the publish should wait until the subscriber read, waited %v", waited)
+
+	notifier.BlockTimeout = 100 * time.Millisecond
+	stalled := notifier.Subscribe(NotifySubscribeOptions{Buffer: 1, Policy: Block})
+	blocking.Close()
+	notifier.Publish(notification("order-1", 3))
+	started = time.Now()
+	notifier.Publish(notification("order-1", 4))
+	elapsed = time.Since(started)
+	got = drain(stalled)
+	fmt.Printf("  stalled subscriber: %v after %v, err %v\n", got, elapsed.Round(10*time.Millisecond), stalled.Err())
+	checks.Check(elapsed < time.Second, "a stalled subscriber should hold a publish up for BlockTimeout at most, took %v", elapsed)
+	checks.Check(errors.Is(stalled.Err(), ErrSlowConsumer) && slices.Equal(got, []uint64{3}), "a stalled subscriber should be cut off after what it buffered, got %v (%v)", got, stalled.Err())
+	checks.Check(notifier.Subscribers() == 0, "a cut off subscriber should be unsubscribed, %d left", notifier.Subscribers())
+
+	fmt.Println("\n--- Filtering ---")
+	notifier = NewNotifier()
+	one := notifier.Subscribe(NotifySubscribeOptions{Buffer: 10, StreamIDs: []string{"order-1"}})
+	all := notifier.Subscribe(NotifySubscribeOptions{Buffer: 10})
+	notifier.Publish(notification("order-1", 1))
+	notifier.Publish(notification("order-2", 2))
+	gotOne, gotAll := drain(one), drain(all)
+	checks.Check(slices.Equal(gotOne, []uint64{1}) && slices.Equal(gotAll, []uint64{1, 2}), "subscriptions should get their streams, got %v and %v", gotOne, gotAll)
+
+	fmt.Println("\n--- Projection ---")
+	projection := NewOrderSummaryProjection().Notify(notifier)
+	var seen []ProjectionNotification
+	apply := func(stream, eventType, data string, commit uint64) {
+		event := syntheticEvent(stream, eventType, 0, commit, data)
+		projection.Apply(event, event.Position)
+	}
+	apply("order-1", "OrderCreated", `{"orderId":"1","amount":10}`, 100)
+	apply("order-1", "ItemAdded", `{"item":"Widget","price":"free"}`, 200) // handler panics
+	apply("order-1", "OrderNoted", `{}`, 300)                              // unhandled
+	apply("order-2", "OrderCreated", `{"orderId":"2","amount":20}`, 400)
+	for _, subscription := range []*NotificationSubscription{one, all} {
+		for done := false; !done; {
+			select {
+			case n := <-subscription.C():
+				seen = append(seen, n)
+			default:
+				done = true
+			}
+		}
+	}
+	fmt.Printf("  %d notifications\n", len(seen))
+	checks.Check(len(seen) == 3 && seen[0].StreamID == "order-1" && seen[0].EventType == "OrderCreated" && seen[0].Position.Commit == 100 &&
+		seen[2].StreamID == "order-2", "only successful applies should publish, got %+v", seen)
+
+	fmt.Println("\n--- Websocket ---")
+	server := httptest.NewServer(NewOrderUpdatesHandler(projection, notifier))
+	defer server.Close()
+	conn, err := dialOrderUpdates(server.URL, "order-2")
+	checks.Check(err == nil, "the websocket should connect, got %v", err)
+	if err == nil {
+		for deadline := time.Now().Add(time.Second); notifier.Subscribers() < 3 && time.Now().Before(deadline); {
+			time.Sleep(10 * time.Millisecond)
+		}
+		apply("order-1", "OrderShipped", `{"shippedAt":"2024-01-15T10:00:00Z"}`, 500)
+		apply("order-2", "ItemAdded", `{"item":"Gadget","price":5}`, 600)
+		updates := receiveOrderUpdates(conn, 1, 2*time.Second)
+		var state map[string]interface{}
+		if len(updates) == 1 {
+			json.Unmarshal(updates[0].State, &state)
+		}
+		fmt.Printf("  pushed %+v\n", updates)
+		checks.Check(len(updates) == 1 && updates[0].StreamID == "order-2" && updates[0].EventType == "ItemAdded" && state["amount"] == 25.0,
+			"the watched order's update should be pushed with its state, got %+v", updates)
+		conn.Close()
+		for deadline := time.Now().Add(time.Second); notifier.Subscribers() > 2 && time.Now().Before(deadline); {
+			time.Sleep(10 * time.Millisecond)
+		}
+		checks.Check(notifier.Subscribers() == 2, "disconnecting should unsubscribe, %d subscribers", notifier.Subscribers())
+	}
+	_, err = websocket.Dial(strings.Replace(server.URL, "http", "ws", 1)+"/updates", "", "http://evil.example")
+	checks.Check(err != nil, "another site's page should be refused")
+
+	checks.Done()
+}
+
+// === DEMO ===
+
+// RunProjectionNotifier follows orders into a notifying projection and watches one order's updates
+// arrive over the websocket, alongside a subscriber too slow to keep up
+func RunProjectionNotifier() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	// === PROJECTION ===
+	notifier := NewNotifier()
+	projection := NewOrderSummaryProjection().Notify(notifier)
+	followCtx, stopFollowing := context.WithCancel(ctx)
+	defer stopFollowing()
+	go projection.Follow(followCtx, NewClientStore(client), kurrentdb.SubscribeToAllOptions{
+		From:   kurrentdb.End{},
+		Filter: projection.SubscriptionFilter(),
+	}, func(*kurrentdb.RecordedEvent) bool { return false })
+
+	server := httptest.NewServer(NewOrderUpdatesHandler(projection, notifier))
+	defer server.Close()
+
+	orderID := uuid.New().String()
+	stream := Streams.Name("order", orderID)
+	fmt.Printf("\nWatch it in a browser while the demo runs: %s/?stream=%s\n", server.URL, stream)
+
+	// === SUBSCRIBERS ===
+	conn, err := dialOrderUpdates(server.URL, stream)
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+	// Never reads: its buffer fills and the oldest notifications give way
+	stalled := notifier.Subscribe(NotifySubscribeOptions{Buffer: 1, Policy: DropOldest, StreamIDs: []string{stream}})
+	defer stalled.Close()
+	for deadline := time.Now().Add(5 * time.Second); notifier.Subscribers() < 2 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(500 * time.Millisecond) // let the subscription to $all start before writing
+
+	// === WRITE ===
+	fmt.Println("\n=== Placing an order ===")
+	makeEvent := func(eventType string, data interface{}) kurrentdb.EventData {
+		payload, _ := json.Marshal(data)
+		return kurrentdb.EventData{EventID: uuid.New(), EventType: eventType, ContentType: kurrentdb.ContentTypeJson, Data: payload}
+	}
+	result, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{},
+		makeEvent("OrderCreated", ProjectionOrderCreated{OrderID: orderID, CustomerID: "cust-1", Amount: 100}),
+		makeEvent("ItemAdded", ProjectionItemAdded{Item: "Widget", Price: 25}),
+		makeEvent("OrderShipped", ProjectionOrderShipped{ShippedAt: "2024-01-15T10:00:00Z"}))
+	if err != nil {
+		panic(err)
+	}
+
+	// === RECEIVE ===
+	fmt.Println("\n=== Updates pushed over the websocket ===")
+	updates := receiveOrderUpdates(conn, 3, 10*time.Second)
+	for _, update := range updates {
+		fmt.Printf("  %s at %d: %s\n", update.EventType, update.Position.Commit, update.State)
+	}
+	waitErr := projection.WaitFor(ctx, kurrentdb.Position{Commit: result.CommitPosition, Prepare: result.PreparePosition})
+	stalledGot := len(stalled.C())
+	fmt.Printf("Stalled subscriber: %d buffered, %d dropped\n", stalledGot, stalled.Dropped())
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	var types []string
+	for _, update := range updates {
+		types = append(types, update.EventType)
+	}
+	if !slices.Equal(types, []string{"OrderCreated", "ItemAdded", "OrderShipped"}) {
+		fmt.Printf("FAIL: Expected an update per event, in order, got %v\n", types)
+		passed = false
+	}
+	for i := 1; i < len(updates); i++ {
+		if !positionAfter(updates[i].Position, updates[i-1].Position) {
+			fmt.Println("FAIL: Expected update positions to increase")
+			passed = false
+			break
+		}
+	}
+	if len(updates) == 3 {
+		var state map[string]interface{}
+		json.Unmarshal(updates[2].State, &state)
+		if state["status"] != "shipped" || state["amount"] != 125.0 || updates[2].StreamID != stream {
+			fmt.Printf("FAIL: Expected the last update to carry the shipped order, got %s\n", updates[2].State)
+			passed = false
+		}
+	}
+	if waitErr != nil {
+		fmt.Printf("FAIL: Expected the projection to keep up despite the stalled subscriber, got %v\n", waitErr)
+		passed = false
+	}
+	if stalledGot != 1 || stalled.Dropped() != 2 {
+		fmt.Printf("FAIL: Expected the stalled subscriber to hold the newest update and drop 2, got %d and %d\n", stalledGot, stalled.Dropped())
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll projection notifier tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
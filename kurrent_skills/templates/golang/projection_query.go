@@ -0,0 +1,75 @@
+// KurrentDB Go Client Example - Projection queries
+// Demonstrates: Filtering a projection's in-memory state with a predicate, and paging through the matches
+package main
+
+import (
+	"slices"
+	"strings"
+)
+
+// === QUERIES ===
+
+// Result is one stream's state as a query returns it
+type Result struct {
+	StreamID string
+	State    map[string]interface{}
+}
+
+// Page is one page of QueryPage's results
+type Page struct {
+	Results []Result
+	// Next is the After of the following page, or "" when this is the last one
+	Next string
+}
+
+// Query returns the state of every stream predicate accepts, ordered by stream ID. The predicate
+// runs with the projection locked against Apply, so every result is from the same moment; it must
+// not call back into the projection. Each State is a deep copy the caller may keep, since handlers
+// can mutate nested values such as an items list in place. For a projection keyed with KeyBy,
+// streamID and Result.StreamID are the encoded keys; DecodeKey turns them into IDs.
+//
+// A query visits every stream, so it costs O(n) in the number of streams and holds up Apply while it
+// runs. That is fine for thousands of streams. Beyond that, keep the rows in an indexed store such
+// as SQLiteOrderReadModel and query the index, or have the projection maintain its own secondary
+// index (e.g. stream IDs by status) alongside its state.
+func (p *Projection) Query(predicate func(streamID string, state map[string]interface{}) bool) []Result {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var results []Result
+	for streamID, state := range p.State {
+		if predicate(streamID, state) {
+			results = append(results, Result{StreamID: streamID, State: snapshotState(state)})
+		}
+	}
+	slices.SortFunc(results, func(a, b Result) int { return strings.Compare(a.StreamID, b.StreamID) })
+	return results
+}
+
+// QueryPage returns up to limit of the results Query would, starting after the stream ID after;
+// pass "" for the first page and Page.Next for each one after it. Pages are keyed on the stream ID
+// rather than an offset or a field of the state: neither changes as events are applied between
+// pages, so a stream that exists throughout is returned exactly once, however its state or the
+// streams before it change. A stream created meanwhile shows up only if it sorts after the page
+// being read.
+//
+// Each page costs the same O(n) as Query.
+func (p *Projection) QueryPage(predicate func(streamID string, state map[string]interface{}) bool, after string, limit int) Page {
+	results := p.Query(func(streamID string, state map[string]interface{}) bool {
+		return streamID > after && predicate(streamID, state)
+	})
+	if limit <= 0 || len(results) <= limit {
+		return Page{Results: results}
+	}
+	return Page{Results: results[:limit], Next: results[limit-1].StreamID}
+}
+
+// ShippedOrdersOver matches order summaries that have shipped, whether or not they have completed
+// since, with an amount above minimum
+func ShippedOrdersOver(minimum float64) func(streamID string, state map[string]interface{}) bool {
+	return func(streamID string, state map[string]interface{}) bool {
+		amount, _ := state["amount"].(float64)
+		_, shipped := state["shippedAt"]
+		return shipped && amount > minimum
+	}
+}
@@ -0,0 +1,266 @@
+// KurrentDB Go Client Example - Client-side projection result vs server-side projection state
+// Demonstrates: Projection.Result() matching the partitioned state of an equivalent server-side projection
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// orderSummaryQuery is the server-side equivalent of NewOrderSummaryProjection
+const orderSummaryQuery = `
+fromCategory('order')
+  .foreachStream()
+  .when({
+    OrderCreated: function (s, e) {
+      return {
+        orderId: e.body.orderId,
+        customerId: e.body.customerId,
+        amount: e.body.amount,
+        status: 'created',
+        items: []
+      };
+    },
+    ItemAdded: function (s, e) {
+      s.items.push(e.body.item);
+      s.amount += e.body.price;
+      return s;
+    },
+    OrderShipped: function (s, e) {
+      s.status = 'shipped';
+      s.shippedAt = e.body.shippedAt;
+      return s;
+    },
+    OrderCompleted: function (s, e) {
+      s.status = 'completed';
+      return s;
+    }
+  });
+`
+
+// === SERVER-SIDE PROJECTION HTTP API ===
+
+// httpBaseURL derives the node's HTTP address from a connection string
+func httpBaseURL(connectionString string) string {
+	parsed, err := url.Parse(connectionString)
+	if err != nil || parsed.Host == "" {
+		return "http://localhost:2113"
+	}
+
+	scheme := "https"
+	if strings.Contains(strings.ToLower(parsed.RawQuery), "tls=false") {
+		scheme = "http"
+	}
+	// Use the first host of a cluster connection string
+	host := strings.Split(parsed.Host, ",")[0]
+	return fmt.Sprintf("%s://%s", scheme, host)
+}
+
+func createServerProjection(ctx context.Context, baseURL, name, query string) error {
+	endpoint := fmt.Sprintf("%s/projections/continuous?name=%s&type=js&enabled=true&emit=false&trackemittedstreams=false",
+		baseURL, url.QueryEscape(name))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBufferString(query))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth("admin", "changeit")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("create projection %s: %s: %s", name, resp.Status, body)
+	}
+	return nil
+}
+
+// serverProjectionState fetches the state of one partition, decoded the same way Result() normalizes
+func serverProjectionState(ctx context.Context, baseURL, name, partition string) (any, error) {
+	endpoint := fmt.Sprintf("%s/projection/%s/state?partition=%s",
+		baseURL, url.PathEscape(name), url.QueryEscape(partition))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth("admin", "changeit")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get state %s/%s: %s", name, partition, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil
+	}
+
+	var state any
+	if err := json.Unmarshal(body, &state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// deleteServerProjection stops a projection, then deletes it with its state and checkpoint streams
+func deleteServerProjection(ctx context.Context, baseURL, name string) error {
+	for _, step := range []struct{ method, path string }{
+		{http.MethodPost, "/command/disable"},
+		{http.MethodDelete, "?deleteStateStream=true&deleteCheckpointStream=true&deleteEmittedStreams=false"},
+	} {
+		endpoint := fmt.Sprintf("%s/projection/%s%s", baseURL, url.PathEscape(name), step.path)
+		req, err := http.NewRequestWithContext(ctx, step.method, endpoint, nil)
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth("admin", "changeit")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("%s projection %s%s: %s: %s", step.method, name, step.path, resp.Status, body)
+		}
+	}
+	return nil
+}
+
+// RunProjectionResult compares a client-side order summary with the equivalent server-side projection
+func RunProjectionResult() {
+	ctx := context.Background()
+
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	baseURL := httpBaseURL(connectionString)
+
+	// === SERVER-SIDE PROJECTION ===
+	projectionName := fmt.Sprintf("order-summary-%s", uuid.New().String()[:8])
+	if err := createServerProjection(ctx, baseURL, projectionName, orderSummaryQuery); err != nil {
+		panic(err)
+	}
+	fmt.Printf("Created server-side projection %s\n", projectionName)
+	// A continuous projection keeps running on the server, so remove it once the states are compared
+	defer func() {
+		if err := deleteServerProjection(context.Background(), baseURL, projectionName); err != nil {
+			fmt.Printf("Failed to delete server-side projection %s: %v\n", projectionName, err)
+		}
+	}()
+
+	// === APPEND TEST EVENTS ===
+	orderID := uuid.New().String()
+	streamName := Streams.Name("order", orderID)
+
+	makeEvent := func(eventType string, data interface{}) kurrentdb.EventData {
+		jsonData, _ := json.Marshal(data)
+		return kurrentdb.EventData{
+			EventID:     uuid.New(),
+			ContentType: kurrentdb.ContentTypeJson,
+			EventType:   eventType,
+			Data:        jsonData,
+		}
+	}
+
+	_, err := client.AppendToStream(ctx, streamName, kurrentdb.AppendToStreamOptions{},
+		makeEvent("OrderCreated", ProjectionOrderCreated{OrderID: orderID, CustomerID: "cust-1", Amount: 100}),
+		makeEvent("ItemAdded", ProjectionItemAdded{Item: "Widget", Price: 25}),
+		makeEvent("OrderShipped", ProjectionOrderShipped{ShippedAt: "2024-01-15T10:00:00Z"}))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Appended 3 events to %s\n", streamName)
+
+	// === CLIENT-SIDE PROJECTION ===
+	orderProjection := NewOrderSummaryProjection()
+
+	stream, err := client.ReadStream(ctx, streamName, kurrentdb.ReadStreamOptions{
+		Direction: kurrentdb.Forwards,
+		From:      kurrentdb.Start{},
+	}, 100)
+	if err != nil {
+		panic(err)
+	}
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			panic(err)
+		}
+		if _, err := orderProjection.Apply(Resolve(event, false), Resolve(event, true).Position); err != nil {
+			panic(err)
+		}
+	}
+	stream.Close()
+
+	clientResult, err := orderProjection.Result()
+	if err != nil {
+		panic(err)
+	}
+	clientState := clientResult[streamName]
+
+	resultJSON, _ := json.MarshalIndent(orderProjection, "", "  ")
+	fmt.Printf("\nClient-side result:\n%s\n", resultJSON)
+
+	// === WAIT FOR THE SERVER TO CATCH UP ===
+	var serverState any
+	deadline := time.Now().Add(15 * time.Second)
+	for time.Now().Before(deadline) {
+		serverState, err = serverProjectionState(ctx, baseURL, projectionName, streamName)
+		if err == nil && reflect.DeepEqual(serverState, clientState) {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	serverJSON, _ := json.MarshalIndent(serverState, "", "  ")
+	fmt.Printf("\nServer-side state (partition %s):\n%s\n", streamName, serverJSON)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if !reflect.DeepEqual(serverState, clientState) {
+		fmt.Println("FAIL: Client-side result should match the server-side partition state")
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll projection result tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
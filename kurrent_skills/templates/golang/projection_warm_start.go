@@ -0,0 +1,159 @@
+// KurrentDB Go Client Example - Warm-starting a projection from another projection's snapshot
+// Demonstrates: Deriving a narrower read model from a broader one's snapshot instead of replaying $all
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// === WARM START ===
+
+// ErrIncompatibleSnapshot is why a warm start fell back to a rebuild: the snapshot can't be
+// trusted to hold what the target projection would have built by its checkpoint
+var ErrIncompatibleSnapshot = errors.New("snapshot can't seed this projection")
+
+// WarmStart derives a projection's initial state from a snapshot of a broader one, so a new read
+// model that is a subset of an existing one starts at the existing one's checkpoint instead of
+// replaying $all. It is only correct when, for every stream, Derive of the source's state equals
+// what the target's own handlers would have built from the same events. That is up to Derive;
+// LoadWarmStart checks what it can about the snapshot.
+type WarmStart struct {
+	// Source is the projection the snapshot was taken of, e.g. NewOrderSummaryProjection(). Only its
+	// name and event types are used.
+	Source *Projection
+	// Migrator brings older snapshots of Source to the current version
+	Migrator *SnapshotMigrator
+	// RequiredFields must be present in every stream's source state for Derive to work
+	RequiredFields []string
+	// Derive builds the target's state for one stream from the source's. A nil state leaves the
+	// stream out.
+	Derive func(streamID string, source map[string]interface{}) (map[string]interface{}, error)
+}
+
+// WarmStartResult is what LoadWarmStart did
+type WarmStartResult struct {
+	// Warm is set when the target was seeded and should continue from its checkpoint
+	Warm bool
+	// Fallback is why it wasn't, wrapping ErrIncompatibleSnapshot; the target is untouched and should
+	// be rebuilt from the start of $all
+	Fallback error
+	// Streams counts the streams seeded
+	Streams int
+}
+
+// LoadWarmStart seeds target from the Source snapshot at path and sets its checkpoint to the
+// snapshot's. The snapshot has to be of Source, at or migratable to the current version, and hold
+// every required field; and target may only handle event types Source handles, because the
+// snapshot's checkpoint is past events Source ignored. Anything else falls back, leaving target as
+// it was. The error is only for snapshots that can't be read or decoded.
+func (w WarmStart) LoadWarmStart(path string, target *Projection) (WarmStartResult, error) {
+	fallback := func(format string, args ...interface{}) (WarmStartResult, error) {
+		return WarmStartResult{Fallback: fmt.Errorf("%w: "+format, append([]interface{}{ErrIncompatibleSnapshot}, args...)...)}, nil
+	}
+
+	if extra := eventTypesMissingFrom(target.EventTypes(), w.Source.EventTypes()); len(extra) > 0 {
+		return fallback("%s handles %v, which %s doesn't", target.Name, extra, w.Source.Name)
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fallback("no snapshot at %s", path)
+	}
+	if err != nil {
+		return WarmStartResult{}, err
+	}
+
+	// Decode into a scratch projection, so a failure part way leaves Source alone too
+	source := NewProjection(w.Source.Name)
+	err = restoreSnapshot(path, data, source, w.Migrator)
+	if errors.Is(err, ErrSnapshotNameChange) || errors.Is(err, ErrNoMigrationPath) || errors.Is(err, ErrSnapshotTooNew) {
+		return fallback("%w", err)
+	}
+	if err != nil {
+		return WarmStartResult{}, err
+	}
+
+	state := make(map[string]map[string]interface{}, len(source.State))
+	for streamID, sourceState := range source.State {
+		for _, field := range w.RequiredFields {
+			if _, ok := sourceState[field]; !ok {
+				return fallback("%s in %s has no %q", streamID, path, field)
+			}
+		}
+		derived, err := w.Derive(streamID, sourceState)
+		if err != nil {
+			return fallback("derive %s: %w", streamID, err)
+		}
+		if derived != nil {
+			state[streamID] = derived
+		}
+	}
+
+	target.mu.Lock()
+	defer target.mu.Unlock()
+	target.State = state
+	if source.Checkpoint != nil {
+		target.setCheckpoint(*source.Checkpoint)
+	}
+	return WarmStartResult{Warm: true, Streams: len(state)}, nil
+}
+
+// eventTypesMissingFrom returns the types in eventTypes that aren't in handled
+func eventTypesMissingFrom(eventTypes, handled []string) []string {
+	var missing []string
+	for _, eventType := range eventTypes {
+		if !slices.Contains(handled, eventType) {
+			missing = append(missing, eventType)
+		}
+	}
+	return missing
+}
+
+// === SHIPPED ORDERS ===
+
+// NewShippedOrdersProjection tracks the amount of every order and whether and when it shipped:
+// the part of OrderSummary a shipping report needs. Orders that haven't shipped are kept so their
+// amount is known when they do.
+func NewShippedOrdersProjection() *Projection {
+	return NewProjection("ShippedOrders").
+		On("OrderCreated", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			return map[string]interface{}{"amount": data["amount"], "shipped": false}
+		}).
+		On("ItemAdded", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			state["amount"] = state["amount"].(float64) + data["price"].(float64)
+			return state
+		}).
+		On("OrderShipped", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			state["shipped"] = true
+			state["shippedAt"] = data["shippedAt"]
+			return state
+		})
+}
+
+// ShippedOrdersWarmStart seeds ShippedOrders from an OrderSummary snapshot. Shipped comes from the
+// status rather than shippedAt, which summaries migrated from v1 snapshots don't have.
+func ShippedOrdersWarmStart() WarmStart {
+	return WarmStart{
+		Source:         NewOrderSummaryProjection(),
+		Migrator:       OrderSummaryMigrations(),
+		RequiredFields: []string{"amount", "status"},
+		Derive: func(streamID string, source map[string]interface{}) (map[string]interface{}, error) {
+			amount, ok := source["amount"].(float64)
+			if !ok {
+				return nil, fmt.Errorf("amount is %T, not a number", source["amount"])
+			}
+			state := map[string]interface{}{"amount": amount, "shipped": false}
+			switch source["status"] {
+			case "shipped", "completed":
+				state["shipped"] = true
+				if shippedAt, ok := source["shippedAt"]; ok {
+					state["shippedAt"] = shippedAt
+				}
+			}
+			return state, nil
+		},
+	}
+}
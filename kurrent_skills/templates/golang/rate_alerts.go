@@ -0,0 +1,473 @@
+// KurrentDB Go Client Example - Runaway stream alerting
+// Demonstrates: Tracking events per stream in a sliding window with bounded memory and alerting above a threshold
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === RATE MONITOR ===
+
+// ErrInvalidRateOptions is returned by NewRateMonitor for a missing window or threshold
+var ErrInvalidRateOptions = errors.New("invalid rate monitor options")
+
+// RateAlert reports a stream that wrote more than Threshold events within Window
+type RateAlert struct {
+	StreamID string
+	// Events is the number of events counted, Threshold+1: counting stops once the threshold is crossed
+	Events int
+	Window time.Duration
+	// Since and At are the event times of the oldest and newest events counted
+	Since time.Time
+	At    time.Time
+	// EventNumber is the stream revision of the event that crossed the threshold
+	EventNumber uint64
+}
+
+// RateMonitorOptions configures a RateMonitor
+type RateMonitorOptions struct {
+	// Window is the sliding window events are counted in, e.g. a minute
+	Window time.Duration
+	// Threshold is the most events a stream may write within Window without an alert
+	Threshold int
+	// IdleAfter evicts streams with no events for this long, in event time; 10 x Window when zero
+	IdleAfter time.Duration
+	// MaxSkew is how far event timestamps may jump back, or ahead of the local clock, and still be
+	// trusted; 5s when zero
+	MaxSkew time.Duration
+	// OnAlert is called once when a stream crosses the threshold, and again only after its rate
+	// has dropped back to the threshold. It runs on the caller's goroutine.
+	OnAlert func(RateAlert)
+}
+
+// streamRate is a ring of the event times of a stream's last Threshold+1 events
+type streamRate struct {
+	times    []time.Time
+	next     int
+	full     bool
+	last     time.Time
+	alerting bool
+}
+
+func (s *streamRate) push(t time.Time) {
+	s.times[s.next] = t
+	s.next = (s.next + 1) % len(s.times)
+	if s.next == 0 {
+		s.full = true
+	}
+	s.last = t
+}
+
+// oldest returns the earliest time still in the ring
+func (s *streamRate) oldest() time.Time {
+	if s.full {
+		return s.times[s.next]
+	}
+	return s.times[0]
+}
+
+func (s *streamRate) reset() {
+	s.next, s.full, s.alerting = 0, false, false
+}
+
+// RateMonitor counts events per stream in a sliding window using each event's Created time, so
+// replaying history finds the same bursts as watching live. A runaway writer is about when events
+// were written, so occurredAt is ignored: an import counts as the burst it was. Memory is Threshold+1 timestamps per
+// active stream; streams idle for IdleAfter are evicted.
+type RateMonitor struct {
+	opts RateMonitorOptions
+	now  func() time.Time
+
+	mu        sync.Mutex
+	streams   map[string]*streamRate
+	watermark time.Time
+	lastSweep time.Time
+	evicted   int
+	resets    int
+}
+
+func NewRateMonitor(opts RateMonitorOptions) (*RateMonitor, error) {
+	var errs []error
+	if opts.Window <= 0 {
+		errs = append(errs, fmt.Errorf("%w: Window must be positive, got %s", ErrInvalidRateOptions, opts.Window))
+	}
+	if opts.Threshold < 1 {
+		errs = append(errs, fmt.Errorf("%w: Threshold must be at least 1, got %d", ErrInvalidRateOptions, opts.Threshold))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if opts.IdleAfter <= 0 {
+		opts.IdleAfter = 10 * opts.Window
+	}
+	if opts.MaxSkew <= 0 {
+		opts.MaxSkew = 5 * time.Second
+	}
+	return &RateMonitor{
+		opts:    opts,
+		now:     time.Now,
+		streams: make(map[string]*streamRate),
+	}, nil
+}
+
+// eventTime returns the time to count event at. Created comes from the server's clock, which
+// can step between nodes or after a leader change:
+//   - times ahead of the local clock by more than MaxSkew are capped at the local clock, so one
+//     bad timestamp can't make every other stream look idle
+//   - small steps back within a stream count as simultaneous with the stream's last event
+//   - larger steps back (a clock reset, or events copied in from elsewhere) restart the stream's
+//     window rather than compare times from two different clocks
+func (m *RateMonitor) eventTime(stream *streamRate, created time.Time) time.Time {
+	t := created
+	if limit := m.now().Add(m.opts.MaxSkew); t.After(limit) {
+		t = limit
+	}
+	if stream.last.IsZero() || !t.Before(stream.last) {
+		return t
+	}
+	if stream.last.Sub(t) <= m.opts.MaxSkew {
+		return stream.last
+	}
+	stream.reset()
+	m.resets++
+	return t
+}
+
+// Observe counts one event, calling OnAlert if its stream crosses the threshold
+func (m *RateMonitor) Observe(event *kurrentdb.RecordedEvent) {
+	alert, fire := m.observe(event)
+	if fire && m.opts.OnAlert != nil {
+		m.opts.OnAlert(alert)
+	}
+}
+
+func (m *RateMonitor) observe(event *kurrentdb.RecordedEvent) (RateAlert, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stream, ok := m.streams[event.StreamID]
+	if !ok {
+		stream = &streamRate{times: make([]time.Time, m.opts.Threshold+1)}
+		m.streams[event.StreamID] = stream
+	}
+
+	t := m.eventTime(stream, event.CreatedDate)
+	stream.push(t)
+	if t.After(m.watermark) {
+		m.watermark = t
+	}
+	defer m.sweep()
+
+	// A full ring spanning less than Window means Threshold+1 events within Window
+	over := stream.full && t.Sub(stream.oldest()) < m.opts.Window
+	if !over {
+		stream.alerting = false
+		return RateAlert{}, false
+	}
+	if stream.alerting {
+		return RateAlert{}, false
+	}
+	stream.alerting = true
+	return RateAlert{
+		StreamID:    event.StreamID,
+		Events:      len(stream.times),
+		Window:      m.opts.Window,
+		Since:       stream.oldest(),
+		At:          t,
+		EventNumber: event.EventNumber,
+	}, true
+}
+
+// sweep evicts idle streams, at most once per Window of event time
+func (m *RateMonitor) sweep() {
+	if m.watermark.Sub(m.lastSweep) < m.opts.Window {
+		return
+	}
+	m.lastSweep = m.watermark
+	for id, stream := range m.streams {
+		if m.watermark.Sub(stream.last) >= m.opts.IdleAfter {
+			delete(m.streams, id)
+			m.evicted++
+		}
+	}
+}
+
+// Handle observes a subscription event; system events are skipped. Use it as a MeteredSubscription
+// handler, or call it from any other.
+func (m *RateMonitor) Handle(event *kurrentdb.ResolvedEvent) error {
+	recorded := Resolve(event, false)
+	if !strings.HasPrefix(recorded.StreamID, "$") {
+		m.Observe(recorded)
+	}
+	return nil
+}
+
+// Rate returns how many of the stream's recent events fall within Window of its latest, capped at
+// Threshold+1
+func (m *RateMonitor) Rate(streamID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stream, ok := m.streams[streamID]
+	if !ok {
+		return 0
+	}
+	count := stream.next
+	if stream.full {
+		count = len(stream.times)
+	}
+	rate := 0
+	for _, t := range stream.times[:count] {
+		if stream.last.Sub(t) < m.opts.Window {
+			rate++
+		}
+	}
+	return rate
+}
+
+// Tracked returns the number of streams held in memory
+func (m *RateMonitor) Tracked() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.streams)
+}
+
+// Evicted returns the number of idle streams dropped so far
+func (m *RateMonitor) Evicted() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.evicted
+}
+
+// SkewResets returns how many times a stream's window restarted after its clock stepped back
+func (m *RateMonitor) SkewResets() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.resets
+}
+
+// RunRateAlerts writes a runaway stream next to normal traffic and checks only the runaway
+// stream raises an alert
+func RunRateAlerts() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	start, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+
+	var mu sync.Mutex
+	var alerts []RateAlert
+	monitor, err := NewRateMonitor(RateMonitorOptions{
+		Window:    time.Minute,
+		Threshold: 50,
+		OnAlert: func(alert RateAlert) {
+			mu.Lock()
+			defer mu.Unlock()
+			alerts = append(alerts, alert)
+			fmt.Printf("  ALERT: %s wrote %d events in %s (revision %d)\n",
+				alert.StreamID, alert.Events, alert.At.Sub(alert.Since).Round(time.Millisecond), alert.EventNumber)
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// === TRAFFIC ===
+	runaway := Streams.Name("order", uuid.New().String())
+	normal := []string{Streams.Name("order", uuid.New().String()), Streams.Name("order", uuid.New().String())}
+	appendTo := func(stream string, count int) {
+		for i := 0; i < count; i++ {
+			_, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+				EventID:     uuid.New(),
+				EventType:   "OrderUpdated",
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        []byte(fmt.Sprintf(`{"sequence":%d}`, i)),
+			})
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+	for _, stream := range normal {
+		appendTo(stream, 10)
+	}
+	// A handler stuck in a retry loop re-emitting the same update
+	appendTo(runaway, 200)
+	fmt.Printf("Wrote 10 events to each of %d normal streams and 200 to %s\n", len(normal), runaway)
+
+	// === MONITOR ===
+	fmt.Println("\n=== Monitoring $all ===")
+	prefixes := append([]string{runaway}, normal...)
+	subscription := NewMeteredSubscription(client, kurrentdb.SubscribeToAllOptions{
+		From:   start,
+		Filter: &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: prefixes},
+	})
+
+	runCtx, stop := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	seen := 0
+	go func() {
+		done <- subscription.Run(runCtx, func(event *kurrentdb.ResolvedEvent) error {
+			monitor.Handle(event)
+			mu.Lock()
+			seen++
+			mu.Unlock()
+			return nil
+		})
+	}()
+
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		mu.Lock()
+		all := seen == 220
+		mu.Unlock()
+		if all {
+			break
+		}
+	}
+	stop()
+	if err := <-done; err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Observed %d events; %s at %d/min, normal streams at %d/min\n", seen, runaway, monitor.Rate(runaway), monitor.Rate(normal[0]))
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if seen != 220 {
+		fmt.Printf("FAIL: Expected 220 events observed, got %d\n", seen)
+		passed = false
+	}
+	if len(alerts) != 1 || alerts[0].StreamID != runaway {
+		fmt.Printf("FAIL: Expected one alert for %s, got %v\n", runaway, alerts)
+		passed = false
+	} else if alerts[0].EventNumber != 50 || alerts[0].Events != 51 {
+		fmt.Printf("FAIL: Expected the alert on the 51st event, got revision %d with %d events\n", alerts[0].EventNumber, alerts[0].Events)
+		passed = false
+	}
+	if monitor.Rate(normal[0]) != 10 {
+		fmt.Printf("FAIL: Expected a rate of 10 for %s, got %d\n", normal[0], monitor.Rate(normal[0]))
+		passed = false
+	}
+	if monitor.Rate(runaway) != 51 {
+		fmt.Printf("FAIL: Expected the runaway rate to be capped at 51, got %d\n", monitor.Rate(runaway))
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll rate alert tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
+
+// RunRateAlertChecks exercises re-arming, eviction and clock skew with synthetic timestamps,
+// no server required
+func RunRateAlertChecks() {
+	checks := newChecker("rate alert")
+
+	var alerts []RateAlert
+	monitor, err := NewRateMonitor(RateMonitorOptions{
+		Window:    time.Minute,
+		Threshold: 5,
+		IdleAfter: 5 * time.Minute,
+		MaxSkew:   2 * time.Second,
+		OnAlert:   func(alert RateAlert) { alerts = append(alerts, alert) },
+	})
+	if err != nil {
+		panic(err)
+	}
+	// The local clock follows the events, as when watching live
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := base
+	monitor.now = func() time.Time { return clock }
+
+	revisions := make(map[string]uint64)
+	observeAt := func(stream string, created time.Time) {
+		event := syntheticEvent(stream, "Updated", revisions[stream], 0, `{}`)
+		event.CreatedDate = created
+		revisions[stream]++
+		monitor.Observe(event)
+	}
+	observe := func(stream string, at time.Duration) {
+		if t := base.Add(at); t.After(clock) {
+			clock = t
+		}
+		observeAt(stream, base.Add(at))
+	}
+
+	// --- Threshold ---
+	fmt.Println("\n--- Threshold ---")
+	for i := 0; i < 5; i++ {
+		observe("steady-1", time.Duration(i)*15*time.Second)
+	}
+	checks.Check(len(alerts) == 0, "5 events a minute is at the threshold, got %v", alerts)
+	observe("steady-1", 61*time.Second)
+	checks.Check(len(alerts) == 0, "6 events spread over more than a minute should not alert, got %v", alerts)
+
+	for i := 0; i < 20; i++ {
+		observe("loop-1", time.Duration(i)*time.Second)
+	}
+	checks.Check(len(alerts) == 1 && alerts[0].StreamID == "loop-1" && alerts[0].EventNumber == 5,
+		"expected one alert on loop-1's 6th event, got %v", alerts)
+
+	// --- Re-arming ---
+	fmt.Println("\n--- Re-arming ---")
+	observe("loop-1", 5*time.Minute)
+	checks.Check(monitor.Rate("loop-1") == 1, "after a quiet spell the rate should drop, got %d", monitor.Rate("loop-1"))
+	for i := 0; i < 6; i++ {
+		observe("loop-1", 5*time.Minute+time.Duration(i+1)*time.Second)
+	}
+	checks.Check(len(alerts) == 2, "a second burst after recovering should alert again, got %d alerts", len(alerts))
+
+	// --- Clock skew ---
+	fmt.Println("\n--- Clock skew ---")
+	for i := 0; i < 4; i++ {
+		observe("skewed-1", 10*time.Minute+time.Duration(i)*15*time.Second)
+	}
+	// 1s back: counted as simultaneous with the previous event, not as older
+	observeAt("skewed-1", base.Add(10*time.Minute+44*time.Second))
+	checks.Check(monitor.SkewResets() == 0 && monitor.Rate("skewed-1") == 5, "a small step back should be clamped, resets %d rate %d", monitor.SkewResets(), monitor.Rate("skewed-1"))
+	// An hour back: a different clock, so the window restarts instead of spanning both
+	observeAt("skewed-1", base.Add(-50*time.Minute))
+	checks.Check(monitor.SkewResets() == 1 && monitor.Rate("skewed-1") == 1, "a large step back should restart the window, resets %d rate %d", monitor.SkewResets(), monitor.Rate("skewed-1"))
+	// A day ahead of the local clock: capped, so the watermark can't jump ahead and evict everything
+	observeAt("future-1", clock.Add(24*time.Hour))
+	checks.Check(monitor.watermark.Equal(clock.Add(2*time.Second)), "a future timestamp should be capped at the local clock, watermark %s", monitor.watermark)
+	checks.Check(len(alerts) == 2, "skew should not cause alerts, got %d", len(alerts))
+
+	// --- Eviction ---
+	fmt.Println("\n--- Eviction ---")
+	for i := 0; i < 100; i++ {
+		observe(fmt.Sprintf("burst-%d", i), 30*time.Minute)
+	}
+	tracked := monitor.Tracked()
+	observe("late-1", 40*time.Minute)
+	fmt.Printf("  tracked %d streams, %d after 10 idle minutes (%d evicted)\n", tracked, monitor.Tracked(), monitor.Evicted())
+	checks.Check(monitor.Tracked() < tracked && monitor.Rate("burst-0") == 0, "idle streams should be evicted, tracked %d -> %d", tracked, monitor.Tracked())
+
+	_, err = NewRateMonitor(RateMonitorOptions{})
+	checks.Check(errors.Is(err, ErrInvalidRateOptions), "missing options should be rejected, got %v", err)
+
+	checks.Done()
+}
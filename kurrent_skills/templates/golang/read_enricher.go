@@ -0,0 +1,506 @@
+// KurrentDB Go Client Example - Enriching events on read with data from another stream
+// Demonstrates: Joining order lines with product names from a bounded, lazily loaded cache of a product projection
+package main
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === JOIN CACHE ===
+
+// JoinLoader loads the state of one entity on a cache miss. A nil state means the entity doesn't
+// exist.
+type JoinLoader func(ctx context.Context, key string) (map[string]interface{}, error)
+
+// JoinCacheStats counts lookups since the cache was created
+type JoinCacheStats struct {
+	Hits, Misses, Loads, Evictions int64
+}
+
+// JoinCache holds the projected state of the entities events are joined with, e.g. products, keeping
+// the Capacity most recently used. A miss calls the loader and caches the result, including that the
+// entity doesn't exist; without a loader misses are left for the caller to skip, and the cache is
+// filled by Put. Safe for concurrent use.
+type JoinCache struct {
+	capacity int
+	load     JoinLoader
+
+	mu      sync.Mutex
+	order   *list.List // front = most recently used
+	entries map[string]*list.Element
+	stats   JoinCacheStats
+}
+
+type joinEntry struct {
+	key   string
+	state map[string]interface{}
+}
+
+// NewJoinCache caches up to capacity entities, loading misses with load when it isn't nil
+func NewJoinCache(capacity int, load JoinLoader) *JoinCache {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &JoinCache{
+		capacity: capacity,
+		load:     load,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns the state of key. found is false for entities that don't exist and, without a loader,
+// for those not cached. A load error is returned and nothing is cached.
+func (c *JoinCache) Get(ctx context.Context, key string) (state map[string]interface{}, found bool, err error) {
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		c.stats.Hits++
+		state = element.Value.(*joinEntry).state
+		c.mu.Unlock()
+		return state, state != nil, nil
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	if c.load == nil {
+		return nil, false, nil
+	}
+	// Loaded outside the lock: a slow load shouldn't hold up hits on other keys. Two concurrent
+	// misses on one key both load it, and the later result is kept.
+	state, err = c.load(ctx, key)
+	if err != nil {
+		return nil, false, fmt.Errorf("load %s: %w", key, err)
+	}
+	c.mu.Lock()
+	c.stats.Loads++
+	c.mu.Unlock()
+	c.Put(key, state)
+	return state, state != nil, nil
+}
+
+// Put caches the state of key; nil records that it doesn't exist
+func (c *JoinCache) Put(key string, state map[string]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*joinEntry).state = state
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&joinEntry{key: key, state: state})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*joinEntry).key)
+		c.stats.Evictions++
+	}
+}
+
+// Refresh replaces the state of key if it is cached, and returns whether it was. Call it from a
+// subscription to the joined streams, so cached entities follow their changes without filling the
+// cache with entities no event refers to.
+func (c *JoinCache) Refresh(key string, state map[string]interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if ok {
+		element.Value.(*joinEntry).state = state
+	}
+	return ok
+}
+
+// Len returns the number of cached entities
+func (c *JoinCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *JoinCache) Stats() JoinCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// StreamLoader loads an entity by folding its stream, streamOf(key), into a fresh projection; a
+// missing stream is an entity that doesn't exist
+func StreamLoader(store EventStore, streamOf func(key string) string, newProjection func() *Projection) JoinLoader {
+	return func(ctx context.Context, key string) (map[string]interface{}, error) {
+		stream := streamOf(key)
+		events, version, err := readStreamFrom(ctx, store, stream, NoVersion)
+		if err != nil {
+			return nil, err
+		}
+		if version == NoVersion {
+			return nil, nil
+		}
+		projection := newProjection()
+		for _, event := range events {
+			if _, err := projection.Apply(event, event.Position); err != nil {
+				return nil, err
+			}
+		}
+		return projection.Get(stream), nil
+	}
+}
+
+// === ENRICHER ===
+
+// enrichJoin is one join registered with Enricher.Join
+type enrichJoin struct {
+	name   string
+	cache  *JoinCache
+	key    func(data map[string]interface{}) string
+	fields []string
+}
+
+// EnricherStats counts what Enrich did per join
+type EnricherStats struct {
+	// Enriched counts joins attached; Skipped those left out because the entity wasn't found or,
+	// for a cache without a loader, wasn't cached
+	Enriched, Skipped int64
+}
+
+// Enricher attaches data from other streams to events as they are read, before the handler sees
+// them: an order line gets its product's name from a product projection. The joined data is the
+// entity's current state, not its state when the event was written; join at write time instead
+// when the historical value matters.
+type Enricher struct {
+	joins map[string][]enrichJoin
+
+	mu    sync.Mutex
+	stats EnricherStats
+}
+
+func NewEnricher() *Enricher {
+	return &Enricher{joins: make(map[string][]enrichJoin)}
+}
+
+// Join enriches events of eventType with fields of the entity key returns from the event's data,
+// looked up in cache and attached under name. An empty key skips the join.
+func (e *Enricher) Join(eventType, name string, cache *JoinCache, key func(data map[string]interface{}) string, fields ...string) *Enricher {
+	e.joins[eventType] = append(e.joins[eventType], enrichJoin{name: name, cache: cache, key: key, fields: fields})
+	return e
+}
+
+// Enrich returns envelope with the joins for its type attached to Enrichment. Entities that can't be
+// found are skipped, leaving the event as read. A failed load is an error, so the caller's retry
+// or error policy decides; use a cache without a loader where a handler must never wait on one.
+func (e *Enricher) Enrich(ctx context.Context, envelope Envelope) (Envelope, error) {
+	joins := e.joins[envelope.EventType]
+	if len(joins) == 0 {
+		return envelope, nil
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(envelope.Data, &data); err != nil {
+		return envelope, fmt.Errorf("decode %s on %s: %w", envelope.EventType, envelope.StreamID, err)
+	}
+
+	for _, join := range joins {
+		key := join.key(data)
+		state, found := map[string]interface{}(nil), false
+		if key != "" {
+			var err error
+			if state, found, err = join.cache.Get(ctx, key); err != nil {
+				return envelope, fmt.Errorf("enrich %s on %s with %s: %w", envelope.EventType, envelope.StreamID, join.name, err)
+			}
+		}
+		e.mu.Lock()
+		if found {
+			e.stats.Enriched++
+		} else {
+			e.stats.Skipped++
+		}
+		e.mu.Unlock()
+		if !found {
+			continue
+		}
+
+		attached := make(map[string]interface{}, len(join.fields))
+		for _, field := range join.fields {
+			if value, ok := state[field]; ok {
+				attached[field] = value
+			}
+		}
+		// Copy the map, so enriching doesn't change an envelope the caller still holds
+		enrichment := make(map[string]map[string]interface{}, len(envelope.Enrichment)+1)
+		for name, values := range envelope.Enrichment {
+			enrichment[name] = values
+		}
+		enrichment[join.name] = attached
+		envelope.Enrichment = enrichment
+	}
+	return envelope, nil
+}
+
+// Handler wraps next so it receives enriched envelopes
+func (e *Enricher) Handler(next SinkHandler) SinkHandler {
+	return func(ctx context.Context, envelope Envelope) error {
+		enriched, err := e.Enrich(ctx, envelope)
+		if err != nil {
+			return err
+		}
+		return next(ctx, enriched)
+	}
+}
+
+func (e *Enricher) Stats() EnricherStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.stats
+}
+
+// === PRODUCT CATALOG ===
+
+// NewProductCatalogProjection tracks each product's name and list price from its product-{sku}
+// stream
+func NewProductCatalogProjection() *Projection {
+	return NewProjection("ProductCatalog").
+		On("ProductAdded", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			return map[string]interface{}{"sku": data["sku"], "name": data["name"], "listPrice": data["listPrice"]}
+		}).
+		On("ProductRenamed", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			state["name"] = data["name"]
+			return state
+		})
+}
+
+// productStream returns the stream of the product with sku
+func productStream(sku string) string {
+	return Streams.Name("product", sku)
+}
+
+// NewProductNameEnricher enriches ItemAdded, whose data has a sku, with the product's name under
+// "product", loading products missing from cache from store
+func NewProductNameEnricher(cache *JoinCache) *Enricher {
+	return NewEnricher().Join("ItemAdded", "product", cache, func(data map[string]interface{}) string {
+		sku, _ := data["sku"].(string)
+		return sku
+	}, "name")
+}
+
+// productName returns the joined product name, or "" when the line wasn't enriched
+func productName(envelope Envelope) string {
+	name, _ := envelope.Enrichment["product"]["name"].(string)
+	return name
+}
+
+// === CHECKS ===
+
+// RunReadEnricherChecks joins order lines with products in memory: loading, hits, eviction, misses,
+// refreshes and failed loads. No server required.
+func RunReadEnricherChecks() {
+	checks := newChecker("read enricher")
+
+	ctx := context.Background()
+	store := NewMemoryEventStore()
+	write := func(stream, eventType string, data interface{}) {
+		payload, _ := json.Marshal(data)
+		if _, err := store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventType: eventType, ContentType: kurrentdb.ContentTypeJson, Data: payload,
+		}); err != nil {
+			panic(err)
+		}
+	}
+	for _, product := range []struct{ sku, name string }{{"SKU-1", "Widget"}, {"SKU-2", "Gadget"}, {"SKU-3", "Gizmo"}} {
+		write(productStream(product.sku), "ProductAdded", map[string]interface{}{"sku": product.sku, "name": product.name, "listPrice": 10})
+	}
+	write(productStream("SKU-2"), "ProductRenamed", map[string]interface{}{"name": "Gadget Pro"})
+	line := func(sku string) Envelope {
+		return Envelope{EventID: uuid.New(), EventType: "ItemAdded", StreamID: "order-1", Data: []byte(fmt.Sprintf(`{"sku":%q,"price":25}`, sku))}
+	}
+
+	fmt.Println("\n--- Lazy loading ---")
+	cache := NewJoinCache(2, StreamLoader(store, productStream, NewProductCatalogProjection))
+	enricher := NewProductNameEnricher(cache)
+	original := line("SKU-1")
+	first, err := enricher.Enrich(ctx, original)
+	second, _ := enricher.Enrich(ctx, line("SKU-1"))
+	renamed, _ := enricher.Enrich(ctx, line("SKU-2"))
+	fmt.Printf("  %s, %s, %s: %+v\n", productName(first), productName(second), productName(renamed), cache.Stats())
+	checks.Check(err == nil && productName(first) == "Widget" && productName(second) == "Widget", "order lines should get the product name, got %q and %q (%v)", productName(first), productName(second), err)
+	checks.Check(productName(renamed) == "Gadget Pro", "the name should come from the whole product stream, got %q", productName(renamed))
+	checks.Check(cache.Stats().Loads == 2 && cache.Stats().Hits == 1, "the second line for a product should hit the cache, got %+v", cache.Stats())
+	checks.Check(original.Enrichment == nil && len(first.Enrichment["product"]) == 1, "enriching should copy the envelope and attach only the joined fields, got %v and %v", original.Enrichment, first.Enrichment)
+	passthrough, _ := enricher.Enrich(ctx, Envelope{EventType: "OrderShipped", Data: []byte(`not json`)})
+	checks.Check(passthrough.Enrichment == nil, "events without a join should pass as they are")
+
+	fmt.Println("\n--- Bounded ---")
+	enricher.Enrich(ctx, line("SKU-3"))
+	enricher.Enrich(ctx, line("SKU-1"))
+	stats := cache.Stats()
+	fmt.Printf("  %d cached, %+v\n", cache.Len(), stats)
+	checks.Check(cache.Len() == 2 && stats.Evictions >= 1, "the cache should stay at its capacity, got %d entries and %+v", cache.Len(), stats)
+	checks.Check(stats.Loads == 4, "an evicted product should be loaded again, got %d loads", stats.Loads)
+
+	fmt.Println("\n--- Misses ---")
+	unknown, err := enricher.Enrich(ctx, line("SKU-404"))
+	enricher.Enrich(ctx, line("SKU-404"))
+	noSku, _ := enricher.Enrich(ctx, Envelope{EventType: "ItemAdded", Data: []byte(`{"item":"Legacy","price":1}`)})
+	checks.Check(err == nil && unknown.Enrichment == nil && noSku.Enrichment == nil, "unknown products should be skipped, got %v (%v)", unknown.Enrichment, err)
+	checks.Check(cache.Stats().Loads == 5, "an unknown product should be remembered, got %d loads", cache.Stats().Loads)
+	checks.Check(enricher.Stats().Skipped == 3, "skipped joins should be counted, got %+v", enricher.Stats())
+
+	// Without a loader, only what a subscription put in the cache is joined
+	warm := NewJoinCache(10, nil)
+	skipping := NewProductNameEnricher(warm)
+	cold, _ := skipping.Enrich(ctx, line("SKU-1"))
+	warm.Put("SKU-1", map[string]interface{}{"name": "Widget"})
+	hot, _ := skipping.Enrich(ctx, line("SKU-1"))
+	checks.Check(cold.Enrichment == nil && productName(hot) == "Widget", "a cache without a loader should skip misses, got %q then %q", productName(cold), productName(hot))
+
+	fmt.Println("\n--- Refresh ---")
+	checks.Check(warm.Refresh("SKU-1", map[string]interface{}{"name": "Widget Mk2"}), "a cached product should be refreshed")
+	checks.Check(!warm.Refresh("SKU-9", map[string]interface{}{"name": "Nothing"}) && warm.Len() == 1, "refreshing an uncached product should not cache it")
+	refreshed, _ := skipping.Enrich(ctx, line("SKU-1"))
+	checks.Check(productName(refreshed) == "Widget Mk2", "lines after a refresh should get the new name, got %q", productName(refreshed))
+
+	fmt.Println("\n--- Failed loads ---")
+	unavailable := errors.New("server unavailable")
+	failing := NewProductNameEnricher(NewJoinCache(10, func(ctx context.Context, key string) (map[string]interface{}, error) {
+		return nil, unavailable
+	}))
+	var handled []Envelope
+	handler := failing.Handler(func(ctx context.Context, envelope Envelope) error {
+		handled = append(handled, envelope)
+		return nil
+	})
+	err = handler(ctx, line("SKU-1"))
+	fmt.Printf("  %v\n", err)
+	checks.Check(errors.Is(err, unavailable) && len(handled) == 0, "a failed load should fail the event before the handler, got %v", err)
+	checks.Check(NewProductNameEnricher(warm).Handler(func(ctx context.Context, envelope Envelope) error {
+		handled = append(handled, envelope)
+		return nil
+	})(ctx, line("SKU-1")) == nil && len(handled) == 1 && productName(handled[0]) == "Widget Mk2", "the handler should get the enriched envelope")
+
+	checks.Done()
+}
+
+// === DEMO ===
+
+// RunReadEnricher reads an order whose lines only have SKUs and hands them to a handler with the
+// product names joined in
+func RunReadEnricher() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	makeEvent := func(eventType string, data interface{}) kurrentdb.EventData {
+		payload, _ := json.Marshal(data)
+		return kurrentdb.EventData{EventID: uuid.New(), EventType: eventType, ContentType: kurrentdb.ContentTypeJson, Data: payload}
+	}
+	appendEvents := func(stream string, events ...kurrentdb.EventData) {
+		if _, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, events...); err != nil {
+			panic(err)
+		}
+	}
+
+	// === CATALOG ===
+	fmt.Println("\n=== Product catalog ===")
+	run := uuid.New().String()[:8]
+	skus := []string{"W-" + run, "G-" + run}
+	appendEvents(productStream(skus[0]), makeEvent("ProductAdded", map[string]interface{}{"sku": skus[0], "name": "Widget", "listPrice": 25}))
+	appendEvents(productStream(skus[1]),
+		makeEvent("ProductAdded", map[string]interface{}{"sku": skus[1], "name": "Gadget", "listPrice": 30}),
+		makeEvent("ProductRenamed", map[string]interface{}{"name": "Gadget Pro"}))
+	for _, sku := range skus {
+		fmt.Printf("  %s\n", productStream(sku))
+	}
+
+	// === ORDER ===
+	orderID := uuid.New().String()
+	stream := Streams.Name("order", orderID)
+	appendEvents(stream,
+		makeEvent("OrderCreated", ProjectionOrderCreated{OrderID: orderID, CustomerID: "cust-1", Amount: 0}),
+		makeEvent("ItemAdded", map[string]interface{}{"sku": skus[0], "price": 25}),
+		makeEvent("ItemAdded", map[string]interface{}{"sku": skus[1], "price": 30}),
+		makeEvent("ItemAdded", map[string]interface{}{"sku": skus[0], "price": 25}),
+		makeEvent("ItemAdded", map[string]interface{}{"sku": "discontinued-" + run, "price": 5}))
+
+	// === ENRICH ===
+	fmt.Printf("\n=== Reading %s with product names ===\n", stream)
+	cache := NewJoinCache(100, StreamLoader(NewClientStore(client), productStream, NewProductCatalogProjection))
+	enricher := NewProductNameEnricher(cache)
+	var lines []Envelope
+	handler := enricher.Handler(func(ctx context.Context, envelope Envelope) error {
+		if envelope.EventType != "ItemAdded" {
+			return nil
+		}
+		lines = append(lines, envelope)
+		name := productName(envelope)
+		if name == "" {
+			name = "(unknown product)"
+		}
+		fmt.Printf("  line %d: %s\n", envelope.EventNumber, name)
+		return nil
+	})
+
+	events, err := client.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{From: kurrentdb.Start{}}, ^uint64(0))
+	if err != nil {
+		panic(err)
+	}
+	var handleErr error
+	for {
+		event, err := events.Recv()
+		if err != nil {
+			break
+		}
+		if handleErr = handler(ctx, NewEnvelope(event)); handleErr != nil {
+			break
+		}
+	}
+	events.Close()
+	stats := cache.Stats()
+	fmt.Printf("Cache: %d loads, %d hits; enricher: %+v\n", stats.Loads, stats.Hits, enricher.Stats())
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	var names []string
+	for _, line := range lines {
+		names = append(names, productName(line))
+	}
+	if handleErr != nil {
+		fmt.Printf("FAIL: Expected every event to be handled, got %v\n", handleErr)
+		passed = false
+	}
+	if fmt.Sprint(names) != fmt.Sprint([]string{"Widget", "Gadget Pro", "Widget", ""}) {
+		fmt.Printf("FAIL: Expected the lines to be named Widget, Gadget Pro, Widget and none, got %q\n", names)
+		passed = false
+	}
+	if stats.Loads != 3 || stats.Hits != 1 {
+		fmt.Printf("FAIL: Expected 3 product loads and 1 cache hit, got %+v\n", stats)
+		passed = false
+	}
+	if enricher.Stats().Skipped != 1 {
+		fmt.Printf("FAIL: Expected the unknown product to be skipped, got %+v\n", enricher.Stats())
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll read enricher tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
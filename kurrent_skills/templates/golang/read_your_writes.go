@@ -0,0 +1,173 @@
+// KurrentDB Go Client Example - Read-your-writes against a projection
+// Demonstrates: Waiting for a projection to reach an append's $all position before querying it
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// RunReadYourWrites writes orders and queries an asynchronously updated summary projection,
+// waiting on each write's position so the query sees it
+func RunReadYourWrites() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	makeEvent := func(eventType string, data interface{}) kurrentdb.EventData {
+		jsonData, _ := json.Marshal(data)
+		return kurrentdb.EventData{
+			EventID:     uuid.New(),
+			ContentType: kurrentdb.ContentTypeJson,
+			EventType:   eventType,
+			Data:        jsonData,
+		}
+	}
+
+	// === PROJECTION RUNNER ===
+	// The projection follows order streams from the current head on its own goroutine, as a read
+	// model service would; events it doesn't handle and filter checkpoints still advance it
+	projection := NewOrderSummaryProjection()
+	head, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+	subscription, err := client.SubscribeToAll(ctx, kurrentdb.SubscribeToAllOptions{
+		From:   head,
+		Filter: &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{"order-"}},
+	})
+	if err != nil {
+		panic(err)
+	}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			event := subscription.Recv()
+			if event.SubscriptionDropped != nil {
+				return
+			}
+			if event.CheckPointReached != nil {
+				projection.Advance(*event.CheckPointReached)
+			}
+			if event.EventAppeared != nil {
+				position := Resolve(event.EventAppeared, true).Position
+				applied, err := projection.Apply(Resolve(event.EventAppeared, false), position)
+				if err != nil {
+					fmt.Printf("  [projection] skipped: %v\n", err)
+				}
+				if !applied {
+					projection.Advance(position)
+				}
+			}
+		}
+	}()
+
+	query := func(stream string) map[string]interface{} {
+		var state map[string]interface{}
+		projection.Read(func(p *Projection) {
+			if current := p.getLocked(stream); current != nil {
+				state = map[string]interface{}{"amount": current["amount"], "status": current["status"]}
+			}
+		})
+		return state
+	}
+
+	// === WRITE, WAIT, READ ===
+	fmt.Println("\n=== Write, then read the projection ===")
+	orderID := uuid.New().String()
+	orderStream := Streams.Name("order", orderID)
+	created, err := AppendAndPosition(ctx, client, orderStream, kurrentdb.AppendToStreamOptions{StreamState: kurrentdb.NoStream{}},
+		makeEvent("OrderCreated", ProjectionOrderCreated{OrderID: orderID, CustomerID: "cust-1", Amount: 100}),
+		makeEvent("ItemAdded", ProjectionItemAdded{Item: "Widget", Price: 25}))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("  Appended at %d/%d; read without waiting: %v\n", created.Position.Commit, created.Position.Prepare, query(orderStream))
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 5*time.Second)
+	started := time.Now()
+	waitErr := projection.WaitFor(waitCtx, created.Position)
+	waitCancel()
+	afterWait := query(orderStream)
+	fmt.Printf("  Waited %s (%v); read after waiting: %v\n", time.Since(started).Round(time.Millisecond), waitErr, afterWait)
+
+	// === ALREADY PASSED ===
+	fmt.Println("\n=== Waiting for a position already passed ===")
+	done, doneCancel := context.WithCancel(ctx)
+	doneCancel()
+	passedErr := projection.WaitFor(done, created.Position)
+	fmt.Printf("  With a cancelled context: %v\n", passedErr)
+
+	// === UNHANDLED EVENT TYPE ===
+	fmt.Println("\n=== Waiting on an event the projection doesn't handle ===")
+	note, err := AppendAndPosition(ctx, client, orderStream, kurrentdb.AppendToStreamOptions{},
+		makeEvent("OrderNoteAdded", map[string]string{"note": "leave at the door"}))
+	if err != nil {
+		panic(err)
+	}
+	noteCtx, noteCancel := context.WithTimeout(ctx, 5*time.Second)
+	noteErr := projection.WaitFor(noteCtx, note.Position)
+	noteCancel()
+	fmt.Printf("  OrderNoteAdded at %d/%d: %v\n", note.Position.Commit, note.Position.Prepare, noteErr)
+
+	// === TIMEOUT ===
+	fmt.Println("\n=== Projection stopped: the wait times out ===")
+	subscription.Close()
+	<-stopped
+	shipped, err := AppendAndPosition(ctx, client, orderStream, kurrentdb.AppendToStreamOptions{},
+		makeEvent("OrderShipped", ProjectionOrderShipped{ShippedAt: "2024-01-15T10:00:00Z"}))
+	if err != nil {
+		panic(err)
+	}
+	timeoutCtx, timeoutCancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	timeoutErr := projection.WaitFor(timeoutCtx, shipped.Position)
+	timeoutCancel()
+	stale := query(orderStream)
+	fmt.Printf("  %v\n  Read anyway (stale): %v\n", timeoutErr, stale)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if waitErr != nil || afterWait == nil || afterWait["amount"] != 125.0 {
+		fmt.Printf("FAIL: After WaitFor the projection should show amount 125, got %v (%v)\n", afterWait, waitErr)
+		passed = false
+	}
+	if passedErr != nil {
+		fmt.Printf("FAIL: A position already reached should return nil even with a cancelled context, got %v\n", passedErr)
+		passed = false
+	}
+	if noteErr != nil {
+		fmt.Printf("FAIL: Unhandled events should advance the checkpoint, got %v\n", noteErr)
+		passed = false
+	}
+	if !errors.Is(timeoutErr, context.DeadlineExceeded) {
+		fmt.Printf("FAIL: Expected the wait to end with context.DeadlineExceeded, got %v\n", timeoutErr)
+		passed = false
+	}
+	if stale == nil || stale["status"] != "created" {
+		fmt.Printf("FAIL: The stopped projection should not show the shipment, got %v\n", stale)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll read-your-writes tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,388 @@
+// KurrentDB Go Client Example - Appending events streamed from an io.Reader
+// Demonstrates: Batching NDJSON records into appends with a flush interval, backpressure and per-record decode errors
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === READER APPENDER ===
+
+// DecodeErrorAction decides what happens to a record that fails to decode
+type DecodeErrorAction int
+
+const (
+	// DecodeAbort appends the records before the bad one, then stops with an error naming its line
+	DecodeAbort DecodeErrorAction = iota
+	// DecodeSkip records the error in the report and carries on with the next record
+	DecodeSkip
+)
+
+// RecordError is a record that failed to decode
+type RecordError struct {
+	Line int
+	Err  error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record on line %d: %v", e.Line, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// ReaderAppendReport summarises one Append call
+type ReaderAppendReport struct {
+	Records  int
+	Appended int
+	Batches  int
+	Skipped  []*RecordError
+}
+
+// ReaderAppender appends one event per line of a reader. Records are read only as fast as they
+// are appended: while a batch is being appended, reading waits, so memory holds one batch at most.
+type ReaderAppender struct {
+	client *kurrentdb.Client
+	decode func([]byte) (kurrentdb.EventData, error)
+
+	// BatchSize is the most events per append
+	BatchSize int
+	// FlushInterval appends a partial batch once it has waited this long, for slow producers;
+	// 0 only appends full batches and the last one
+	FlushInterval time.Duration
+	// OnDecodeError defaults to DecodeAbort
+	OnDecodeError DecodeErrorAction
+	// MaxRecordSize bounds one line; longer lines fail the read
+	MaxRecordSize int
+}
+
+func NewReaderAppender(client *kurrentdb.Client, decode func([]byte) (kurrentdb.EventData, error)) *ReaderAppender {
+	return &ReaderAppender{
+		client:        client,
+		decode:        decode,
+		BatchSize:     100,
+		FlushInterval: time.Second,
+		MaxRecordSize: 1 << 20,
+	}
+}
+
+// AppendFromReader appends every line of r to stream with the default batching and aborts on the
+// first record that fails to decode
+func AppendFromReader(ctx context.Context, client *kurrentdb.Client, stream string, r io.Reader, decode func([]byte) (kurrentdb.EventData, error)) (*ReaderAppendReport, error) {
+	return NewReaderAppender(client, decode).Append(ctx, stream, r)
+}
+
+type readerRecord struct {
+	line int
+	data []byte
+	err  error
+}
+
+// Append reads r to the end, appending decoded records to stream in order. Blank lines are
+// ignored and events without an EventID get a random one. On error the report still counts the
+// events appended so far; they stay in the stream.
+func (a *ReaderAppender) Append(ctx context.Context, stream string, r io.Reader) (*ReaderAppendReport, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	records := make(chan readerRecord)
+	go a.scan(ctx, r, records)
+
+	report := &ReaderAppendReport{}
+	var batch []kurrentdb.EventData
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := a.client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, batch...); err != nil {
+			return fmt.Errorf("append %d events to %s: %w", len(batch), stream, err)
+		}
+		report.Appended += len(batch)
+		report.Batches++
+		batch = batch[:0]
+		return nil
+	}
+
+	var tick <-chan time.Time
+	var ticker *time.Ticker
+	if a.FlushInterval > 0 {
+		ticker = time.NewTicker(a.FlushInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return report, ctx.Err()
+		case <-tick:
+			if err := flush(); err != nil {
+				return report, err
+			}
+		case record, ok := <-records:
+			if !ok {
+				return report, flush()
+			}
+			if record.err != nil {
+				if err := flush(); err != nil {
+					return report, err
+				}
+				return report, fmt.Errorf("read line %d: %w", record.line, record.err)
+			}
+
+			report.Records++
+			event, err := a.decode(record.data)
+			if err != nil {
+				recordErr := &RecordError{Line: record.line, Err: err}
+				if a.OnDecodeError == DecodeSkip {
+					report.Skipped = append(report.Skipped, recordErr)
+					continue
+				}
+				if err := flush(); err != nil {
+					return report, err
+				}
+				return report, recordErr
+			}
+			if event.EventID == uuid.Nil {
+				event.EventID = uuid.New()
+			}
+
+			batch = append(batch, event)
+			if len(batch) >= a.BatchSize {
+				if err := flush(); err != nil {
+					return report, err
+				}
+				if ticker != nil {
+					ticker.Reset(a.FlushInterval)
+				}
+			}
+		}
+	}
+}
+
+// scan sends each non-blank line of r, blocking until Append takes it
+func (a *ReaderAppender) scan(ctx context.Context, r io.Reader, records chan<- readerRecord) {
+	defer close(records)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), a.MaxRecordSize)
+	line := 0
+	send := func(record readerRecord) bool {
+		select {
+		case records <- record:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for scanner.Scan() {
+		line++
+		data := scanner.Bytes()
+		if len(strings.TrimSpace(string(data))) == 0 {
+			continue
+		}
+		// The scanner reuses its buffer for the next line
+		if !send(readerRecord{line: line, data: append([]byte(nil), data...)}) {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		send(readerRecord{line: line + 1, err: err})
+	}
+}
+
+// DecodeNDJSONEvent decodes a line of the form {"type": "...", "data": {...}, "metadata": {...}}
+func DecodeNDJSONEvent(line []byte) (kurrentdb.EventData, error) {
+	var record struct {
+		ID       uuid.UUID       `json:"id"`
+		Type     string          `json:"type"`
+		Data     json.RawMessage `json:"data"`
+		Metadata json.RawMessage `json:"metadata"`
+	}
+	if err := json.Unmarshal(line, &record); err != nil {
+		return kurrentdb.EventData{}, err
+	}
+	if record.Type == "" {
+		return kurrentdb.EventData{}, errors.New("missing type")
+	}
+	if len(record.Data) == 0 {
+		record.Data = json.RawMessage(`{}`)
+	}
+	return kurrentdb.EventData{
+		EventID:     record.ID,
+		EventType:   record.Type,
+		ContentType: kurrentdb.ContentTypeJson,
+		Data:        record.Data,
+		Metadata:    record.Metadata,
+	}, nil
+}
+
+// RunReaderAppend pipes an NDJSON file and a slow producer into streams
+func RunReaderAppend() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	// readSequences returns the sequence numbers of a stream's events in order
+	readSequences := func(stream string) []int {
+		events, err := client.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{From: kurrentdb.Start{}}, 1000)
+		if err != nil {
+			if isStreamNotFound(err) {
+				return nil
+			}
+			panic(err)
+		}
+		defer events.Close()
+		var sequences []int
+		for {
+			event, err := events.Recv()
+			if err != nil {
+				return sequences
+			}
+			var data struct {
+				Sequence int `json:"sequence"`
+			}
+			json.Unmarshal(Resolve(event, false).Data, &data)
+			sequences = append(sequences, data.Sequence)
+		}
+	}
+
+	// === NDJSON FILE ===
+	dir, err := os.MkdirTemp("", "reader-append")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var lines []string
+	for i := 1; i <= 25; i++ {
+		switch i {
+		case 8:
+			lines = append(lines, `{"type":"ItemAdded","data":{"sequence":8,`)
+		case 17:
+			lines = append(lines, `{"data":{"sequence":17}}`)
+		default:
+			lines = append(lines, fmt.Sprintf(`{"type":"ItemAdded","data":{"sequence":%d}}`, i))
+		}
+	}
+	path := filepath.Join(dir, "items.ndjson")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n\n"), 0o644); err != nil {
+		panic(err)
+	}
+	fmt.Printf("Wrote %d records to %s (lines 8 and 17 are malformed)\n", len(lines), path)
+
+	// === SKIP BAD RECORDS ===
+	fmt.Println("\n=== Skipping bad records ===")
+	skipStream := Streams.Name("import", uuid.New().String())
+	file, err := os.Open(path)
+	if err != nil {
+		panic(err)
+	}
+	skipper := NewReaderAppender(client, DecodeNDJSONEvent)
+	skipper.BatchSize = 10
+	skipper.OnDecodeError = DecodeSkip
+	skipReport, skipErr := skipper.Append(ctx, skipStream, file)
+	file.Close()
+	fmt.Printf("records=%d appended=%d batches=%d err=%v\n", skipReport.Records, skipReport.Appended, skipReport.Batches, skipErr)
+	for _, skipped := range skipReport.Skipped {
+		fmt.Printf("  skipped %v\n", skipped)
+	}
+	skipped := readSequences(skipStream)
+
+	// === ABORT ON A BAD RECORD ===
+	fmt.Println("\n=== Aborting on a bad record ===")
+	abortStream := Streams.Name("import", uuid.New().String())
+	file, err = os.Open(path)
+	if err != nil {
+		panic(err)
+	}
+	abortReport, abortErr := AppendFromReader(ctx, client, abortStream, file, DecodeNDJSONEvent)
+	file.Close()
+	fmt.Printf("records=%d appended=%d err=%v\n", abortReport.Records, abortReport.Appended, abortErr)
+	aborted := readSequences(abortStream)
+
+	// === SLOW PRODUCER ===
+	fmt.Println("\n=== Slow producer flushed on interval ===")
+	pipeStream := Streams.Name("import", uuid.New().String())
+	reader, writer := io.Pipe()
+	piped := NewReaderAppender(client, DecodeNDJSONEvent)
+	piped.FlushInterval = 100 * time.Millisecond
+
+	pipeDone := make(chan error, 1)
+	go func() {
+		_, err := piped.Append(ctx, pipeStream, reader)
+		pipeDone <- err
+	}()
+
+	for i := 1; i <= 3; i++ {
+		fmt.Fprintf(writer, `{"type":"ItemAdded","data":{"sequence":%d}}`+"\n", i)
+	}
+	// The batch isn't full, but the flush interval appends it while the producer is still open
+	time.Sleep(500 * time.Millisecond)
+	beforeClose := readSequences(pipeStream)
+	fmt.Printf("Appended before the producer finished: %v\n", beforeClose)
+	writer.Close()
+	if err := <-pipeDone; err != nil {
+		panic(err)
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	var want []int
+	for i := 1; i <= 25; i++ {
+		if i != 8 && i != 17 {
+			want = append(want, i)
+		}
+	}
+	if skipErr != nil || fmt.Sprint(skipped) != fmt.Sprint(want) {
+		fmt.Printf("FAIL: Skip mode should append every good record in order, got %v (%v)\n", skipped, skipErr)
+		passed = false
+	}
+	if skipReport.Records != 25 || skipReport.Batches != 3 || len(skipReport.Skipped) != 2 ||
+		skipReport.Skipped[0].Line != 8 || skipReport.Skipped[1].Line != 17 {
+		fmt.Printf("FAIL: Expected 25 records in 3 batches with lines 8 and 17 skipped, got %+v\n", skipReport)
+		passed = false
+	}
+
+	var recordErr *RecordError
+	if !errors.As(abortErr, &recordErr) || recordErr.Line != 8 {
+		fmt.Printf("FAIL: Abort mode should fail on line 8, got %v\n", abortErr)
+		passed = false
+	}
+	if fmt.Sprint(aborted) != "[1 2 3 4 5 6 7]" {
+		fmt.Printf("FAIL: Abort mode should append exactly the records before line 8, got %v\n", aborted)
+		passed = false
+	}
+	if fmt.Sprint(beforeClose) != "[1 2 3]" {
+		fmt.Printf("FAIL: The flush interval should append a partial batch, got %v\n", beforeClose)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll reader append tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
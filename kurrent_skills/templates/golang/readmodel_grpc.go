@@ -0,0 +1,387 @@
+// KurrentDB Go Client Example - Read model queries over gRPC
+// Demonstrates: Serving projected order state with a unary lookup and a server-streaming feed of live changes
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/status"
+)
+
+// === SERVICE ===
+//
+// The service is described by hand and its messages travel as JSON, so the template builds without
+// protoc or generated code. It is equivalent to this .proto, which teams with clients in other
+// languages can generate from, switching the codec to protobuf:
+//
+//	syntax = "proto3";
+//	package readmodel;
+//
+//	import "google/protobuf/struct.proto";
+//
+//	service OrderQuery {
+//	  rpc GetOrder(OrderRequest) returns (OrderReply);
+//	  rpc StreamUpdates(OrderRequest) returns (stream OrderReply);
+//	}
+//	message OrderRequest { string id = 1; }
+//	message OrderReply {
+//	  string id = 1;
+//	  bool found = 2;
+//	  google.protobuf.Struct state = 3;
+//	  uint64 commit_position = 4;
+//	}
+
+// jsonCodecName is the content subtype clients select the JSON codec with
+const jsonCodecName = "json"
+
+// jsonCodec marshals gRPC messages as JSON
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                       { return jsonCodecName }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// OrderRequest names an order by its ID
+type OrderRequest struct {
+	ID string `json:"id"`
+}
+
+// OrderReply is an order's projected state as of CommitPosition; Found is false while the
+// projection has no state for it
+type OrderReply struct {
+	ID             string                 `json:"id"`
+	Found          bool                   `json:"found"`
+	State          map[string]interface{} `json:"state,omitempty"`
+	CommitPosition uint64                 `json:"commitPosition"`
+}
+
+// OrderQueryService is the server side of readmodel.OrderQuery
+type OrderQueryService interface {
+	GetOrder(ctx context.Context, request *OrderRequest) (*OrderReply, error)
+	StreamUpdates(request *OrderRequest, stream grpc.ServerStream) error
+}
+
+var orderQueryServiceDesc = grpc.ServiceDesc{
+	ServiceName: "readmodel.OrderQuery",
+	HandlerType: (*OrderQueryService)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "GetOrder",
+		Handler: func(srv any, ctx context.Context, decode func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			var request OrderRequest
+			if err := decode(&request); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return srv.(OrderQueryService).GetOrder(ctx, &request)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/readmodel.OrderQuery/GetOrder"}
+			return interceptor(ctx, &request, info, func(ctx context.Context, request any) (any, error) {
+				return srv.(OrderQueryService).GetOrder(ctx, request.(*OrderRequest))
+			})
+		},
+	}},
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "StreamUpdates",
+		ServerStreams: true,
+		Handler: func(srv any, stream grpc.ServerStream) error {
+			var request OrderRequest
+			if err := stream.RecvMsg(&request); err != nil {
+				return err
+			}
+			return srv.(OrderQueryService).StreamUpdates(&request, stream)
+		},
+	}},
+	Metadata: "readmodel.proto",
+}
+
+// RegisterOrderQueryService registers service on server
+func RegisterOrderQueryService(server *grpc.Server, service OrderQueryService) {
+	server.RegisterService(&orderQueryServiceDesc, service)
+}
+
+// === SERVER ===
+
+// OrderQueryServer answers order queries from a live order summary projection, which a
+// subscription keeps applying events to on another goroutine
+type OrderQueryServer struct {
+	projection *Projection
+	// streams counts the StreamUpdates calls in progress
+	streams atomic.Int64
+}
+
+func NewOrderQueryServer(projection *Projection) *OrderQueryServer {
+	return &OrderQueryServer{projection: projection}
+}
+
+// GetOrder returns the order's current state, or NotFound
+func (s *OrderQueryServer) GetOrder(ctx context.Context, request *OrderRequest) (*OrderReply, error) {
+	reply := s.read(request.ID)
+	if !reply.Found {
+		return nil, status.Errorf(codes.NotFound, "order %s not found", request.ID)
+	}
+	return reply, nil
+}
+
+// StreamUpdates sends the order's current state, found or not, then the state again each time it
+// changes, until the client cancels or disconnects. Changes arriving faster than the client reads
+// are coalesced: it always gets the latest state, not every intermediate one.
+func (s *OrderQueryServer) StreamUpdates(request *OrderRequest, stream grpc.ServerStream) error {
+	s.streams.Add(1)
+	defer s.streams.Add(-1)
+
+	var sent []byte
+	for {
+		// Taken before reading, so a change between the read and the wait still wakes us
+		changed := s.projection.Changed()
+
+		reply := s.read(request.ID)
+		// The checkpoint moves for every order; only send when this one changed
+		state, err := json.Marshal(reply.State)
+		if err != nil {
+			return status.Errorf(codes.Internal, "encode order %s: %v", request.ID, err)
+		}
+		if sent == nil || !bytes.Equal(state, sent) {
+			if err := stream.SendMsg(reply); err != nil {
+				return err
+			}
+			sent = state
+		}
+
+		select {
+		case <-changed:
+		case <-stream.Context().Done():
+			// Client cancelled or disconnected: nothing to send it, so just release the stream
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+	}
+}
+
+// Streams returns how many StreamUpdates calls are in progress
+func (s *OrderQueryServer) Streams() int64 {
+	return s.streams.Load()
+}
+
+// read deep-copies the order's state under the projection lock, so the reply can be encoded after
+// the lock is released while Apply keeps mutating the original
+func (s *OrderQueryServer) read(id string) *OrderReply {
+	reply := &OrderReply{ID: id}
+	s.projection.Read(func(p *Projection) {
+		if state := p.getLocked(Streams.Name("order", id)); state != nil {
+			reply.Found = true
+			reply.State = snapshotState(state)
+		}
+		if p.Checkpoint != nil {
+			reply.CommitPosition = p.Checkpoint.Commit
+		}
+	})
+	return reply
+}
+
+// === CLIENT ===
+
+// OrderQueryClient calls readmodel.OrderQuery
+type OrderQueryClient struct {
+	conn *grpc.ClientConn
+}
+
+func NewOrderQueryClient(conn *grpc.ClientConn) *OrderQueryClient {
+	return &OrderQueryClient{conn: conn}
+}
+
+// GetOrder returns the order's state; a missing order is a NotFound status error
+func (c *OrderQueryClient) GetOrder(ctx context.Context, id string) (*OrderReply, error) {
+	var reply OrderReply
+	err := c.conn.Invoke(ctx, "/readmodel.OrderQuery/GetOrder", &OrderRequest{ID: id}, &reply, grpc.CallContentSubtype(jsonCodecName))
+	if err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// StreamUpdates opens the feed of the order's state; cancel ctx to close it
+func (c *OrderQueryClient) StreamUpdates(ctx context.Context, id string) (*OrderUpdates, error) {
+	stream, err := c.conn.NewStream(ctx, &orderQueryServiceDesc.Streams[0], "/readmodel.OrderQuery/StreamUpdates", grpc.CallContentSubtype(jsonCodecName))
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(&OrderRequest{ID: id}); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return &OrderUpdates{stream: stream}, nil
+}
+
+// OrderUpdates receives a StreamUpdates feed
+type OrderUpdates struct {
+	stream grpc.ClientStream
+}
+
+// Recv blocks for the next state; it returns io.EOF if the server ends the feed, and a Canceled
+// status error once the client's ctx is cancelled
+func (u *OrderUpdates) Recv() (*OrderReply, error) {
+	var reply OrderReply
+	if err := u.stream.RecvMsg(&reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// RunReadModelGRPC serves a live order summary over gRPC and follows one order's updates from a client
+func RunReadModelGRPC() {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	// === LIVE PROJECTION ===
+	head, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+	projection := NewOrderSummaryProjection()
+	followCtx, stopFollowing := context.WithCancel(ctx)
+	defer stopFollowing()
+	go projection.Follow(followCtx, NewClientStore(client), kurrentdb.SubscribeToAllOptions{
+		From:   head,
+		Filter: &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{"order-"}},
+	}, func(*kurrentdb.RecordedEvent) bool { return false })
+
+	// === GRPC SERVER ===
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	server := grpc.NewServer()
+	queries := NewOrderQueryServer(projection)
+	RegisterOrderQueryService(server, queries)
+	go server.Serve(listener)
+	defer server.Stop()
+	fmt.Printf("Serving readmodel.OrderQuery on %s\n", listener.Addr())
+
+	conn, err := grpc.NewClient("passthrough:///"+listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+	orders := NewOrderQueryClient(conn)
+
+	orderID := uuid.New().String()
+	orderStream := Streams.Name("order", orderID)
+	appendJSON := func(stream, eventType string, data interface{}) {
+		payload, _ := json.Marshal(data)
+		_, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        payload,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// === UNARY LOOKUP ===
+	fmt.Println("\n=== GetOrder before the order exists ===")
+	_, missingErr := orders.GetOrder(ctx, orderID)
+	fmt.Printf("  %v\n", missingErr)
+
+	// === LIVE UPDATES ===
+	fmt.Println("\n=== StreamUpdates while the order is written ===")
+	feedCtx, closeFeed := context.WithCancel(ctx)
+	updates, err := orders.StreamUpdates(feedCtx, orderID)
+	if err != nil {
+		panic(err)
+	}
+	var received []string
+	next := func() {
+		reply, err := updates.Recv()
+		if err != nil {
+			received = append(received, fmt.Sprintf("error: %v", err))
+			return
+		}
+		summary := "not found"
+		if reply.Found {
+			summary = fmt.Sprintf("%v/%v", reply.State["status"], reply.State["amount"])
+		}
+		fmt.Printf("  update: %s at %d\n", summary, reply.CommitPosition)
+		received = append(received, summary)
+	}
+
+	next() // the state when the feed opened
+	appendJSON(orderStream, "OrderCreated", ProjectionOrderCreated{OrderID: orderID, CustomerID: "cust-1", Amount: 100})
+	next()
+	// Another order's event moves the checkpoint but is not sent to this feed
+	appendJSON(Streams.Name("order", uuid.New().String()), "OrderCreated", ProjectionOrderCreated{OrderID: "other", CustomerID: "cust-2", Amount: 5})
+	appendJSON(orderStream, "ItemAdded", ProjectionItemAdded{Item: "Widget", Price: 25})
+	next()
+	appendJSON(orderStream, "OrderShipped", ProjectionOrderShipped{ShippedAt: "2024-01-15T10:00:00Z"})
+	next()
+
+	// === DISCONNECT ===
+	fmt.Println("\n=== Client disconnects ===")
+	activeBefore := queries.Streams()
+	closeFeed()
+	_, closedErr := updates.Recv()
+	for deadline := time.Now().Add(5 * time.Second); queries.Streams() > 0 && time.Now().Before(deadline); {
+		time.Sleep(20 * time.Millisecond)
+	}
+	fmt.Printf("  streams in progress: %d before, %d after; client sees %v\n", activeBefore, queries.Streams(), status.Code(closedErr))
+
+	shipped, shippedErr := orders.GetOrder(ctx, orderID)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if status.Code(missingErr) != codes.NotFound {
+		fmt.Printf("FAIL: GetOrder for a missing order should return NotFound, got %v\n", missingErr)
+		passed = false
+	}
+	if fmt.Sprint(received) != "[not found created/100 created/125 shipped/125]" {
+		fmt.Printf("FAIL: The feed should send the initial state and each change to this order only, got %v\n", received)
+		passed = false
+	}
+	if activeBefore != 1 || queries.Streams() != 0 {
+		fmt.Printf("FAIL: Disconnecting should end the server's stream, got %d before and %d after\n", activeBefore, queries.Streams())
+		passed = false
+	}
+	if status.Code(closedErr) != codes.Canceled {
+		fmt.Printf("FAIL: The client should see its own cancellation, got %v\n", closedErr)
+		passed = false
+	}
+	if shippedErr != nil || shipped.State["status"] != "shipped" || shipped.State["amount"] != float64(125) {
+		fmt.Printf("FAIL: GetOrder should return the shipped order, got %+v (%v)\n", shipped, shippedErr)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll read model gRPC tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
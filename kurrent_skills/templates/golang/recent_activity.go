@@ -0,0 +1,254 @@
+// KurrentDB Go Client Example - Last N events plus live updates
+// Demonstrates: Reading the tail of a stream backwards, then subscribing live from that exact revision
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === RECENT + LIVE ===
+
+// errStopTail ends TailWithHistory cleanly when returned from the callback
+var errStopTail = errors.New("stop tail")
+
+// TailWithHistory delivers the last n events of stream oldest first, then every new event as it
+// is appended. live is false for the historical events.
+//
+// The subscription starts from the revision of the newest event in the stream, read even when n is
+// 0 (subscriptions from a revision are exclusive), so events appended between the backward read and
+// the subscription are delivered once, by the subscription. An empty or missing stream is
+// subscribed from the start for the same reason. Returning an error from emit stops the tail and
+// returns it; errStopTail stops it with nil.
+func TailWithHistory(ctx context.Context, client *kurrentdb.Client, stream string, n uint64, emit func(event *kurrentdb.RecordedEvent, live bool) error) error {
+	return tailWithHistory(ctx, client, stream, n, nil, emit)
+}
+
+// tailWithHistory takes a hook run between the read and the subscribe, used to exercise the boundary
+func tailWithHistory(ctx context.Context, client *kurrentdb.Client, stream string, n uint64, afterRead func(), emit func(event *kurrentdb.RecordedEvent, live bool) error) error {
+	history, from, err := readTail(ctx, client, stream, n, false)
+	if err != nil {
+		return err
+	}
+
+	if afterRead != nil {
+		afterRead()
+	}
+
+	for _, event := range history {
+		if err := emit(Resolve(event, false), false); err != nil {
+			return stopTail(err)
+		}
+	}
+
+	return stopTail(followStream(ctx, client, stream, from, false, func(event *kurrentdb.ResolvedEvent) error {
+		return emit(Resolve(event, false), true)
+	}))
+}
+
+func stopTail(err error) error {
+	if errors.Is(err, errStopTail) {
+		return nil
+	}
+	return err
+}
+
+// readTail returns up to n of the newest events in stream, oldest first, and the position to
+// subscribe from to get every event after them: the revision of the newest event in the stream,
+// which is read even when n is 0, or the start when the stream is empty or missing
+func readTail(ctx context.Context, client *kurrentdb.Client, stream string, n uint64, resolveLinks bool) ([]*kurrentdb.ResolvedEvent, kurrentdb.StreamPosition, error) {
+	events, err := client.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{
+		Direction:      kurrentdb.Backwards,
+		From:           kurrentdb.End{},
+		ResolveLinkTos: resolveLinks,
+	}, max(n, 1))
+	if isStreamNotFound(err) {
+		return nil, kurrentdb.Start{}, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer events.Close()
+
+	var last []*kurrentdb.ResolvedEvent
+	for {
+		event, err := events.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if isStreamNotFound(err) {
+			return nil, kurrentdb.Start{}, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		last = append(last, event)
+	}
+	if len(last) == 0 {
+		return nil, kurrentdb.Start{}, nil
+	}
+
+	// The revision in stream itself, not in the stream a resolved link points to
+	from := kurrentdb.Revision(Resolve(last[0], true).EventNumber)
+	history := last[:min(uint64(len(last)), n)]
+	slices.Reverse(history)
+	return history, from, nil
+}
+
+// followStream subscribes to stream from from and passes every event to emit until emit returns an
+// error, which followStream returns, or ctx ends, which is a clean stop
+func followStream(ctx context.Context, client *kurrentdb.Client, stream string, from kurrentdb.StreamPosition, resolveLinks bool, emit func(event *kurrentdb.ResolvedEvent) error) error {
+	subscription, err := client.SubscribeToStream(ctx, stream, kurrentdb.SubscribeToStreamOptions{From: from, ResolveLinkTos: resolveLinks})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer subscription.Close()
+
+	for {
+		message := subscription.Recv()
+
+		if message.SubscriptionDropped != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return message.SubscriptionDropped.Error
+		}
+		if message.EventAppeared != nil {
+			if err := emit(message.EventAppeared); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// RunRecentActivity renders a five-line "recent activity" feed for an order stream
+func RunRecentActivity() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	streamName := Streams.Name("order", uuid.New().String())
+	appendActivity := func(description string) {
+		_, err := client.AppendToStream(ctx, streamName, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   "ActivityRecorded",
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(fmt.Sprintf(`{"description":%q}`, description)),
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// === HISTORY ===
+	for i := 0; i < 8; i++ {
+		appendActivity(fmt.Sprintf("history %d", i))
+	}
+	fmt.Printf("Appended 8 events to %s\n", streamName)
+
+	// === FEED ===
+	const window = 5
+	const expected = window + 4 // 5 recent, 2 appended in the read/subscribe gap, 2 live
+
+	var feed []string
+	var numbers []uint64
+	historical := 0
+
+	err := tailWithHistory(ctx, client, streamName, window,
+		func() {
+			// Written after the backward read but before the subscription starts
+			appendActivity("gap 0")
+			appendActivity("gap 1")
+		},
+		func(event *kurrentdb.RecordedEvent, live bool) error {
+			numbers = append(numbers, event.EventNumber)
+			if !live {
+				historical++
+			}
+
+			label := "recent"
+			if live {
+				label = "live"
+			}
+			feed = append(feed, fmt.Sprintf("#%-2d %-6s %s", event.EventNumber, label, event.Data))
+			if len(feed) > window {
+				feed = feed[1:]
+			}
+			fmt.Printf("\n--- Recent activity ---\n  %s\n", strings.Join(feed, "\n  "))
+
+			// Once caught up with the gap events, append two more live
+			if event.EventNumber == 9 {
+				go func() {
+					appendActivity("live 0")
+					appendActivity("live 1")
+				}()
+			}
+			if len(numbers) == expected {
+				return errStopTail
+			}
+			return nil
+		})
+	if err != nil {
+		panic(err)
+	}
+
+	// === NO HISTORY ===
+	// A window of 0 still starts after the newest event, so only the next one arrives
+	var liveOnly []uint64
+	allLive := true
+	err = tailWithHistory(ctx, client, streamName, 0,
+		func() { appendActivity("after an empty window") },
+		func(event *kurrentdb.RecordedEvent, live bool) error {
+			liveOnly = append(liveOnly, event.EventNumber)
+			allLive = allLive && live
+			return errStopTail
+		})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("\nWith no history: %v\n", liveOnly)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if historical != window {
+		fmt.Printf("FAIL: Expected %d historical events, got %d\n", window, historical)
+		passed = false
+	}
+	for i, number := range numbers {
+		if number != uint64(3+i) {
+			fmt.Printf("FAIL: Expected consecutive events #3..#%d without gaps or duplicates, got %v\n", 3+expected-1, numbers)
+			passed = false
+			break
+		}
+	}
+
+	if len(liveOnly) != 1 || liveOnly[0] != uint64(3+expected) || !allLive {
+		fmt.Printf("FAIL: With no history only the event appended after the read should arrive, live, got %v (live %v)\n", liveOnly, allLive)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll recent activity tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,165 @@
+// KurrentDB Go Client Example - Payload redaction for logs
+// Demonstrates: Masking PII fields per event type before event data reaches a log line
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === REDACTOR ===
+
+// RedactedValue replaces every masked field
+const RedactedValue = "[REDACTED]"
+
+// anyEventType registers paths redacted on every event type
+const anyEventType = "*"
+
+// Redactor produces log-safe copies of event payloads. Configure it once at startup; it is safe
+// for concurrent use afterwards.
+type Redactor struct {
+	paths map[string][][]string
+}
+
+func NewRedactor() *Redactor {
+	return &Redactor{paths: make(map[string][][]string)}
+}
+
+// Mask registers dotted field paths (e.g. "customer.email") to redact on eventType, or on every
+// type when eventType is "*". A path crossing an array applies to each element, so "items.sku"
+// masks the sku of every item.
+func (r *Redactor) Mask(eventType string, paths ...string) *Redactor {
+	for _, path := range paths {
+		r.paths[eventType] = append(r.paths[eventType], strings.Split(path, "."))
+	}
+	return r
+}
+
+// Redact returns a copy of data with the registered fields replaced by RedactedValue. Absent fields
+// are left absent. Data that isn't a JSON object is never logged raw: it becomes a size placeholder.
+func (r *Redactor) Redact(eventType string, data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	object, err := decodeNumbers(data)
+	if err != nil {
+		return fmt.Sprintf("<%d bytes, not a JSON object>", len(data))
+	}
+
+	for _, paths := range [][][]string{r.paths[anyEventType], r.paths[eventType]} {
+		for _, path := range paths {
+			maskJSONPath(object, path)
+		}
+	}
+
+	redacted, err := json.Marshal(object)
+	if err != nil {
+		return fmt.Sprintf("<%d bytes, unencodable>", len(data))
+	}
+	return string(redacted)
+}
+
+// maskJSONPath replaces the value at path, descending through objects and fanning out over arrays
+func maskJSONPath(value interface{}, path []string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		child, ok := v[path[0]]
+		if !ok {
+			return
+		}
+		if len(path) == 1 {
+			v[path[0]] = RedactedValue
+			return
+		}
+		maskJSONPath(child, path[1:])
+	case []interface{}:
+		for _, element := range v {
+			maskJSONPath(element, path)
+		}
+	}
+}
+
+// === LOGGING MIDDLEWARE ===
+
+// LoggingMiddleware wraps a subscription handler, logging each event through logf with its data
+// redacted. The handler still receives the original, unredacted event.
+func LoggingMiddleware(redactor *Redactor, logf func(format string, args ...interface{}), handler func(*kurrentdb.ResolvedEvent) error) func(*kurrentdb.ResolvedEvent) error {
+	return func(event *kurrentdb.ResolvedEvent) error {
+		recorded := Resolve(event, false)
+		err := handler(event)
+
+		outcome := "ok"
+		if err != nil {
+			outcome = "error: " + err.Error()
+		}
+		logf("event %s %s@%d %s %s", recorded.EventType, recorded.StreamID, recorded.EventNumber,
+			redactor.Redact(recorded.EventType, recorded.Data), outcome)
+		return err
+	}
+}
+
+// RunRedactionChecks logs synthetic order events through the middleware and checks no PII leaks,
+// no server required
+func RunRedactionChecks() {
+	checks := newChecker("redaction")
+
+	redactor := NewRedactor().
+		Mask(anyEventType, "email").
+		Mask("OrderCreated", "customerId", "customer.email", "customer.address.street", "contacts.phone").
+		Mask("ItemAdded", "giftMessage")
+
+	var logged []string
+	logf := func(format string, args ...interface{}) {
+		line := fmt.Sprintf(format, args...)
+		logged = append(logged, line)
+		fmt.Printf("  %s\n", line)
+	}
+
+	var handled []string
+	handler := LoggingMiddleware(redactor, logf, func(event *kurrentdb.ResolvedEvent) error {
+		handled = append(handled, string(Resolve(event, false).Data))
+		return nil
+	})
+
+	events := []*kurrentdb.RecordedEvent{
+		syntheticEvent("order-1", "OrderCreated", 0, 100, `{
+			"orderId": "1",
+			"customerId": "cust-42",
+			"email": "jane@example.com",
+			"amount": 100,
+			"customer": {"name": "Jane", "email": "jane@example.com", "address": {"street": "1 Main St", "city": "Springfield"}},
+			"contacts": [{"type": "mobile", "phone": "555-0100"}, {"type": "home", "phone": "555-0199"}]
+		}`),
+		// customerId and customer are absent: nothing to mask, nothing added
+		syntheticEvent("order-1", "ItemAdded", 1, 200, `{"item": "Widget", "price": 25, "giftMessage": "Happy birthday Jane"}`),
+		syntheticEvent("order-1", "OrderShipped", 2, 300, `{"shippedAt": "2024-01-15T10:00:00Z", "email": "jane@example.com"}`),
+		syntheticEvent("order-1", "LegacyImported", 3, 400, `customerId=cust-42;email=jane@example.com`),
+	}
+
+	for _, event := range events {
+		checks.Check(handler(&kurrentdb.ResolvedEvent{Event: event}) == nil, "handler failed on %s", event.EventType)
+	}
+
+	all := strings.Join(logged, "\n")
+	for _, pii := range []string{"cust-42", "jane@example.com", "1 Main St", "555-01", "Happy birthday"} {
+		checks.Check(!strings.Contains(all, pii), "log should not contain %q", pii)
+	}
+
+	checks.Check(strings.Contains(logged[0], `"customerId":"[REDACTED]"`), "customerId should be masked: %s", logged[0])
+	checks.Check(strings.Contains(logged[0], `"city":"Springfield"`) && strings.Contains(logged[0], `"name":"Jane"`),
+		"unregistered nested fields should be kept: %s", logged[0])
+	checks.Check(strings.Count(logged[0], `"phone":"[REDACTED]"`) == 2, "every array element should be masked: %s", logged[0])
+	checks.Check(strings.Contains(logged[0], `"amount":100`), "numbers should be logged as written: %s", logged[0])
+	checks.Check(!strings.Contains(logged[1], "customerId") && !strings.Contains(logged[1], "customer"),
+		"absent fields should stay absent: %s", logged[1])
+	checks.Check(strings.Contains(logged[2], `"email":"[REDACTED]"`), "paths registered for any type should apply: %s", logged[2])
+	checks.Check(strings.Contains(logged[3], "not a JSON object"), "non-JSON data should not be logged raw: %s", logged[3])
+
+	checks.Check(len(handled) == len(events) && strings.Contains(handled[0], "jane@example.com"),
+		"the handler should receive the original data")
+
+	checks.Done()
+}
@@ -0,0 +1,508 @@
+// KurrentDB Go Client Example - Reconciling a stream that diverged across regions
+// Demonstrates: Finding the common ancestor of two regions' copies of a stream by EventID and merging their tails
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === DIVERGENCE ===
+
+// This is application-level reconciliation for deployments that run a KurrentDB cluster per region
+// and copy events between them themselves, preserving EventIDs. The server knows nothing about the
+// other region: each cluster accepts its own appends, so two writers appending to the same stream
+// in different regions at about the same time leave two histories that share a prefix and then
+// differ. Reconcile finds where they part and brings both regions to the same set of events; it
+// does not make their revisions line up, which only a single writable region can guarantee.
+
+// StreamMergedEventType is the marker Reconcile appends to both regions after merging a conflict
+const StreamMergedEventType = "StreamMerged"
+
+// Region is one region's cluster
+type Region struct {
+	Name  string
+	Store EventStore
+}
+
+// Divergence is how two regions' copies of a stream differ. Events are matched by EventID, so an
+// event copied from one region to the other counts as shared wherever it landed, and an event a
+// StreamMerged marker in either copy lists as superseded counts as settled.
+type Divergence struct {
+	Stream string
+	// Common counts the events at the start of the stream that both regions have in the same order
+	Common int
+	// Ancestor is the last of them, or nil when the copies share no prefix
+	Ancestor *kurrentdb.RecordedEvent
+	// Left and Right are each region's events after the ancestor that the other region lacks
+	Left, Right []*kurrentdb.RecordedEvent
+
+	// leftLast and rightLast are each copy's last revision, or NoVersion when it is empty
+	leftLast, rightLast int64
+}
+
+// Conflict reports whether both regions appended events the other lacks. Otherwise one copy is
+// just behind the other, or they already hold the same events.
+func (d *Divergence) Conflict() bool {
+	return len(d.Left) > 0 && len(d.Right) > 0
+}
+
+func (d *Divergence) String() string {
+	switch {
+	case d.Conflict():
+		return fmt.Sprintf("%s diverged after %d common events: %d only on the left, %d only on the right", d.Stream, d.Common, len(d.Left), len(d.Right))
+	case len(d.Left) > 0:
+		return fmt.Sprintf("%s: right is %d events behind", d.Stream, len(d.Left))
+	case len(d.Right) > 0:
+		return fmt.Sprintf("%s: left is %d events behind", d.Stream, len(d.Right))
+	}
+	return fmt.Sprintf("%s: in sync", d.Stream)
+}
+
+// FindDivergence compares two copies of a stream, oldest first
+func FindDivergence(stream string, left, right []*kurrentdb.RecordedEvent) *Divergence {
+	d := &Divergence{Stream: stream, leftLast: lastRevision(left), rightLast: lastRevision(right)}
+	for d.Common < min(len(left), len(right)) && left[d.Common].EventID == right[d.Common].EventID {
+		d.Common++
+	}
+	if d.Common > 0 {
+		d.Ancestor = left[d.Common-1]
+	}
+
+	// A superseded event stays in the region that wrote it; it must not be copied to the other one
+	settled := supersededIDs(slices.Concat(left[d.Common:], right[d.Common:]))
+	d.Left = onlyIn(left[d.Common:], right[d.Common:], settled)
+	d.Right = onlyIn(right[d.Common:], left[d.Common:], settled)
+	return d
+}
+
+// onlyIn returns the events of tail whose IDs neither other nor settled have
+func onlyIn(tail, other []*kurrentdb.RecordedEvent, settled map[uuid.UUID]bool) []*kurrentdb.RecordedEvent {
+	ids := maps.Clone(settled)
+	for _, event := range other {
+		ids[event.EventID] = true
+	}
+	var only []*kurrentdb.RecordedEvent
+	for _, event := range tail {
+		if !ids[event.EventID] {
+			only = append(only, event)
+		}
+	}
+	return only
+}
+
+// supersededIDs collects the events the StreamMerged markers among events list as superseded
+func supersededIDs(events []*kurrentdb.RecordedEvent) map[uuid.UUID]bool {
+	ids := make(map[uuid.UUID]bool)
+	for _, event := range events {
+		if event.EventType != StreamMergedEventType {
+			continue
+		}
+		var marker StreamMerged
+		if json.Unmarshal(event.Data, &marker) == nil {
+			for _, id := range marker.Superseded {
+				ids[id] = true
+			}
+		}
+	}
+	return ids
+}
+
+func lastRevision(events []*kurrentdb.RecordedEvent) int64 {
+	if len(events) == 0 {
+		return NoVersion
+	}
+	return int64(events[len(events)-1].EventNumber)
+}
+
+// === MERGE ===
+
+// MergeFunc reconciles a conflict. It returns the events that follow the ancestor once merged, in
+// order: events kept from either side, converted with KeepEvent so they keep their EventIDs, and any
+// new events that resolve the conflict, with IDs derived from the divergence (see MergeEventID) so
+// running the merge in both regions produces the same ones. Events it leaves out stay in the region
+// that wrote them, followed by what supersedes them.
+type MergeFunc func(d *Divergence) ([]kurrentdb.EventData, error)
+
+// KeepEvent converts a recorded event back into one to append, keeping its ID
+func KeepEvent(event *kurrentdb.RecordedEvent) kurrentdb.EventData {
+	contentType := kurrentdb.ContentTypeBinary
+	if event.ContentType == "application/json" {
+		contentType = kurrentdb.ContentTypeJson
+	}
+	return kurrentdb.EventData{
+		EventID:     event.EventID,
+		EventType:   event.EventType,
+		ContentType: contentType,
+		Data:        event.Data,
+		Metadata:    event.UserMetadata,
+	}
+}
+
+// MergeEventID is a deterministic ID for the index-th new event of a merge: the same divergence
+// always yields the same IDs, so a merge run twice, or in both regions, appends each event once
+func MergeEventID(d *Divergence, index int) uuid.UUID {
+	var ids []string
+	for _, event := range slices.Concat(d.Left, d.Right) {
+		ids = append(ids, event.EventID.String())
+	}
+	slices.Sort(ids)
+	namespace := uuid.NameSpaceOID
+	if d.Ancestor != nil {
+		namespace = d.Ancestor.EventID
+	}
+	return uuid.NewSHA1(namespace, []byte(fmt.Sprintf("%s/%s/%d", d.Stream, strings.Join(ids, ","), index)))
+}
+
+// LastWriterWins keeps the side whose last event was created most recently and drops the other
+// side's events, except merge markers from an earlier reconcile, which are kept and don't count as
+// writes. Ties go to the left. CreatedDate is each region's own clock, so clock skew between
+// regions decides close calls; use a custom MergeFunc when that matters.
+//
+// It suits events that overwrite state, where the winner's events, appended after the loser's, have
+// the last word anyway. With additive events the losing region still has its own, so a fold over
+// that region must skip the events the StreamMerged marker lists as superseded.
+func LastWriterWins(d *Divergence) ([]kurrentdb.EventData, error) {
+	winner, loser := d.Left, d.Right
+	if lastWrite(d.Right).After(lastWrite(d.Left)) {
+		winner, loser = d.Right, d.Left
+	}
+	var merged []kurrentdb.EventData
+	for _, event := range loser {
+		if event.EventType == StreamMergedEventType {
+			merged = append(merged, KeepEvent(event))
+		}
+	}
+	return append(merged, keepAll(winner)...), nil
+}
+
+// lastWrite is when the last event of tail other than a merge marker was created
+func lastWrite(tail []*kurrentdb.RecordedEvent) time.Time {
+	for i := len(tail) - 1; i >= 0; i-- {
+		if tail[i].EventType != StreamMergedEventType {
+			return tail[i].CreatedDate
+		}
+	}
+	return time.Time{}
+}
+
+// StreamMerged is the data of the marker appended after a merge, so consumers and operators can
+// tell which events were superseded
+type StreamMerged struct {
+	Ancestor   *uuid.UUID  `json:"ancestor"`
+	Kept       []uuid.UUID `json:"kept"`
+	Superseded []uuid.UUID `json:"superseded"`
+}
+
+// MergeReport is what Reconcile did
+type MergeReport struct {
+	Divergence *Divergence
+	// Merged is set when the regions conflicted and merge ran; false is the fast path
+	Merged bool
+	// Appended counts the events appended to each region, by name
+	Appended map[string]int
+}
+
+// Reconcile reads the stream from both regions and brings them to the same events. When only one
+// region has events the other lacks, they are copied across and merge is not called. When both do,
+// merge decides the merged tail; each region then gets the merged events it lacks, followed by a
+// StreamMerged marker.
+//
+// Appends expect the revision each copy was read at, so a write that lands during reconciliation
+// fails it with a wrong expected version rather than being merged blindly. If that happens after
+// one region was appended to, its marker is simply part of the next comparison; run Reconcile again.
+// Copying events with their IDs also means a replicator that later copies them again is appending
+// an event the region already has.
+func Reconcile(ctx context.Context, stream string, left, right Region, merge MergeFunc) (*MergeReport, error) {
+	leftEvents, err := readRegion(ctx, left, stream)
+	if err != nil {
+		return nil, err
+	}
+	rightEvents, err := readRegion(ctx, right, stream)
+	if err != nil {
+		return nil, err
+	}
+
+	d := FindDivergence(stream, leftEvents, rightEvents)
+	report := &MergeReport{Divergence: d, Appended: map[string]int{left.Name: 0, right.Name: 0}}
+
+	if !d.Conflict() {
+		// Fast path: one side is behind, or both are in sync
+		if err := appendToRegion(ctx, right, stream, d.rightLast, keepAll(d.Left), report); err != nil {
+			return report, err
+		}
+		return report, appendToRegion(ctx, left, stream, d.leftLast, keepAll(d.Right), report)
+	}
+
+	merged, err := merge(d)
+	if err != nil {
+		return report, fmt.Errorf("merge %s: %w", stream, err)
+	}
+	report.Merged = true
+
+	marker, err := mergeMarker(d, merged)
+	if err != nil {
+		return report, err
+	}
+	if err := appendToRegion(ctx, left, stream, d.leftLast, append(missingFrom(merged, d.Left), marker), report); err != nil {
+		return report, err
+	}
+	return report, appendToRegion(ctx, right, stream, d.rightLast, append(missingFrom(merged, d.Right), marker), report)
+}
+
+func readRegion(ctx context.Context, region Region, stream string) ([]*kurrentdb.RecordedEvent, error) {
+	events, _, err := readStreamFrom(ctx, region.Store, stream, NoVersion)
+	if err != nil {
+		return nil, fmt.Errorf("read %s in %s: %w", stream, region.Name, err)
+	}
+	return events, nil
+}
+
+// appendToRegion appends events to the region's copy, expecting it still to end at last
+func appendToRegion(ctx context.Context, region Region, stream string, last int64, events []kurrentdb.EventData, report *MergeReport) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if _, err := region.Store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{StreamState: expectedState(last)}, events...); err != nil {
+		return fmt.Errorf("append %d events to %s in %s: %w", len(events), stream, region.Name, err)
+	}
+	report.Appended[region.Name] += len(events)
+	return nil
+}
+
+func keepAll(events []*kurrentdb.RecordedEvent) []kurrentdb.EventData {
+	kept := make([]kurrentdb.EventData, len(events))
+	for i, event := range events {
+		kept[i] = KeepEvent(event)
+	}
+	return kept
+}
+
+// missingFrom returns the merged events the region doesn't already have in its tail
+func missingFrom(merged []kurrentdb.EventData, tail []*kurrentdb.RecordedEvent) []kurrentdb.EventData {
+	has := make(map[uuid.UUID]bool, len(tail))
+	for _, event := range tail {
+		has[event.EventID] = true
+	}
+	var missing []kurrentdb.EventData
+	for _, event := range merged {
+		if !has[event.EventID] {
+			missing = append(missing, event)
+		}
+	}
+	return missing
+}
+
+// mergeMarker records which tail events the merge kept and which it superseded
+func mergeMarker(d *Divergence, merged []kurrentdb.EventData) (kurrentdb.EventData, error) {
+	kept := make(map[uuid.UUID]bool, len(merged))
+	for _, event := range merged {
+		kept[event.EventID] = true
+	}
+	marker := StreamMerged{Kept: []uuid.UUID{}, Superseded: []uuid.UUID{}}
+	if d.Ancestor != nil {
+		marker.Ancestor = &d.Ancestor.EventID
+	}
+	for _, event := range merged {
+		marker.Kept = append(marker.Kept, event.EventID)
+	}
+	for _, event := range slices.Concat(d.Left, d.Right) {
+		if !kept[event.EventID] {
+			marker.Superseded = append(marker.Superseded, event.EventID)
+		}
+	}
+
+	data, err := json.Marshal(marker)
+	if err != nil {
+		return kurrentdb.EventData{}, err
+	}
+	return kurrentdb.EventData{
+		EventID:     MergeEventID(d, len(merged)),
+		EventType:   StreamMergedEventType,
+		ContentType: kurrentdb.ContentTypeJson,
+		Data:        data,
+	}, nil
+}
+
+// === CHECKS ===
+
+// cartEvent is an event of a shopping cart stream
+func cartEvent(eventType, data string) kurrentdb.EventData {
+	return kurrentdb.EventData{
+		EventID:     uuid.New(),
+		EventType:   eventType,
+		ContentType: kurrentdb.ContentTypeJson,
+		Data:        []byte(data),
+	}
+}
+
+// cartState folds a cart stream: items added and the last address set, skipping superseded events
+func cartState(events []*kurrentdb.RecordedEvent) string {
+	var items []string
+	address := ""
+	superseded := supersededIDs(events)
+	for _, event := range events {
+		if superseded[event.EventID] {
+			continue
+		}
+		var data struct{ Item, Address string }
+		json.Unmarshal(event.Data, &data)
+		switch event.EventType {
+		case "ItemAdded":
+			items = append(items, data.Item)
+		case "AddressChanged":
+			address = data.Address
+		}
+	}
+	slices.Sort(items)
+	return fmt.Sprintf("items=%v address=%q", items, address)
+}
+
+// RunRegionMergeChecks diverges a stream between two in-memory regions and reconciles it
+func RunRegionMergeChecks() {
+	checks := newChecker("region merge")
+
+	ctx := context.Background()
+	// newRegions starts both regions with the same replicated history
+	newRegions := func(stream string, shared ...kurrentdb.EventData) (Region, Region) {
+		eu, us := Region{Name: "eu", Store: NewMemoryEventStore()}, Region{Name: "us", Store: NewMemoryEventStore()}
+		for _, region := range []Region{eu, us} {
+			if _, err := region.Store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, shared...); err != nil {
+				panic(err)
+			}
+		}
+		return eu, us
+	}
+	appendTo := func(region Region, stream string, events ...kurrentdb.EventData) {
+		if _, err := region.Store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, events...); err != nil {
+			panic(err)
+		}
+		time.Sleep(2 * time.Millisecond) // so CreatedDate orders the regions' writes
+	}
+	read := func(region Region, stream string) []*kurrentdb.RecordedEvent {
+		events, _, _ := readStreamFrom(ctx, region.Store, stream, NoVersion)
+		return events
+	}
+	ids := func(events []*kurrentdb.RecordedEvent) map[uuid.UUID]bool {
+		set := make(map[uuid.UUID]bool)
+		for _, event := range events {
+			set[event.EventID] = true
+		}
+		return set
+	}
+	failMerge := func(*Divergence) ([]kurrentdb.EventData, error) {
+		return nil, fmt.Errorf("merge should not be called")
+	}
+
+	fmt.Println("\n--- Common ancestor ---")
+	created := cartEvent("CartCreated", `{}`)
+	widget := cartEvent("ItemAdded", `{"item":"widget"}`)
+	eu, us := newRegions("cart-1", created, widget)
+	gadget, gizmo := cartEvent("ItemAdded", `{"item":"gadget"}`), cartEvent("ItemAdded", `{"item":"gizmo"}`)
+	appendTo(eu, "cart-1", gadget)
+	appendTo(us, "cart-1", gizmo)
+	// A later eu event already replicated to us is shared, not part of either tail
+	shipped := cartEvent("AddressChanged", `{"address":"1 Main St"}`)
+	appendTo(eu, "cart-1", shipped)
+	appendTo(us, "cart-1", shipped)
+	d := FindDivergence("cart-1", read(eu, "cart-1"), read(us, "cart-1"))
+	fmt.Printf("  %s\n", d)
+	checks.Check(d.Conflict() && d.Common == 2 && d.Ancestor.EventID == widget.EventID, "the ancestor should be the widget, the 2nd event, got %d / %v", d.Common, d.Ancestor)
+	checks.Check(len(d.Left) == 1 && d.Left[0].EventID == gadget.EventID && len(d.Right) == 1 && d.Right[0].EventID == gizmo.EventID,
+		"each tail should hold only the region's own unreplicated event, got %d and %d", len(d.Left), len(d.Right))
+	checks.Check(MergeEventID(d, 0) == MergeEventID(FindDivergence("cart-1", read(us, "cart-1"), read(eu, "cart-1")), 0),
+		"merge event IDs should not depend on which region is left")
+	none := FindDivergence("cart-9", nil, read(us, "cart-1"))
+	checks.Check(none.Ancestor == nil && none.Common == 0 && !none.Conflict() && len(none.Right) == 4, "an empty copy should just be behind, got %s", none)
+
+	fmt.Println("\n--- No-conflict fast path ---")
+	eu, us = newRegions("cart-2", created)
+	appendTo(eu, "cart-2", widget, gadget)
+	report, err := Reconcile(ctx, "cart-2", eu, us, failMerge)
+	fmt.Printf("  %s: appended %v\n", report.Divergence, report.Appended)
+	checks.Check(err == nil && !report.Merged, "a region that is only behind should catch up without a merge, got %v", err)
+	checks.Check(report.Appended["us"] == 2 && report.Appended["eu"] == 0, "the 2 missing events should be copied to us, got %v", report.Appended)
+	checks.Check(cartState(read(eu, "cart-2")) == cartState(read(us, "cart-2")) && len(read(us, "cart-2")) == 3, "both regions should hold the same 3 events")
+	report, err = Reconcile(ctx, "cart-2", eu, us, failMerge)
+	checks.Check(err == nil && !report.Merged && report.Appended["eu"]+report.Appended["us"] == 0, "regions in sync should be left alone, got %v (%v)", report.Appended, err)
+
+	fmt.Println("\n--- Last writer wins ---")
+	eu, us = newRegions("cart-3", created, widget)
+	euAddress := cartEvent("AddressChanged", `{"address":"Dublin"}`)
+	usAddress := cartEvent("AddressChanged", `{"address":"Boston"}`)
+	appendTo(eu, "cart-3", euAddress)
+	appendTo(us, "cart-3", usAddress) // later, so it wins
+	report, err = Reconcile(ctx, "cart-3", eu, us, LastWriterWins)
+	euState, usState := cartState(read(eu, "cart-3")), cartState(read(us, "cart-3"))
+	fmt.Printf("  %s\n  eu: %s\n  us: %s\n", report.Divergence, euState, usState)
+	checks.Check(err == nil && report.Merged, "a conflict should be merged: %v", err)
+	checks.Check(euState == usState && strings.Contains(usState, "Boston"), "both regions should end with the later address, got %s / %s", euState, usState)
+	checks.Check(report.Appended["eu"] == 2 && report.Appended["us"] == 1, "eu should get the winning event and a marker, us only the marker, got %v", report.Appended)
+	euEvents := read(eu, "cart-3")
+	marker := euEvents[len(euEvents)-1]
+	var merged StreamMerged
+	json.Unmarshal(marker.Data, &merged)
+	checks.Check(marker.EventType == StreamMergedEventType && len(merged.Superseded) == 1 && merged.Superseded[0] == euAddress.EventID,
+		"the marker should record Dublin as superseded, got %s %s", marker.EventType, marker.Data)
+	usEvents := read(us, "cart-3")
+	checks.Check(usEvents[len(usEvents)-1].EventID == marker.EventID, "both regions should append the same marker")
+	report, err = Reconcile(ctx, "cart-3", eu, us, failMerge)
+	checks.Check(err == nil && report.Appended["eu"]+report.Appended["us"] == 0, "the superseded address should stay in eu only, got %s (%v)", report.Divergence, err)
+
+	fmt.Println("\n--- Custom merge ---")
+	// Items added in either region are all kept, in the order they were created
+	union := func(d *Divergence) ([]kurrentdb.EventData, error) {
+		tails := slices.Concat(d.Left, d.Right)
+		slices.SortStableFunc(tails, func(a, b *kurrentdb.RecordedEvent) int { return a.CreatedDate.Compare(b.CreatedDate) })
+		merged := make([]kurrentdb.EventData, 0, len(tails)+1)
+		for _, event := range tails {
+			merged = append(merged, KeepEvent(event))
+		}
+		// A resolution event of its own, with an ID both regions agree on
+		resolution := cartEvent("CartMerged", fmt.Sprintf(`{"items":%d}`, len(tails)))
+		resolution.EventID = MergeEventID(d, 0)
+		return append(merged, resolution), nil
+	}
+	eu, us = newRegions("cart-4", created, widget)
+	appendTo(eu, "cart-4", gadget)
+	appendTo(us, "cart-4", gizmo, cartEvent("ItemAdded", `{"item":"doohickey"}`))
+	report, err = Reconcile(ctx, "cart-4", eu, us, union)
+	euState, usState = cartState(read(eu, "cart-4")), cartState(read(us, "cart-4"))
+	fmt.Printf("  %s\n  eu: %s\n  us: %s\n", report.Divergence, euState, usState)
+	checks.Check(err == nil && euState == usState && strings.Contains(euState, "[doohickey gadget gizmo widget]"), "both regions should hold every item, got %s / %s", euState, usState)
+	euIDs, usIDs := ids(read(eu, "cart-4")), ids(read(us, "cart-4"))
+	checks.Check(len(euIDs) == len(usIDs) && len(euIDs) == 7, "both regions should hold the same 7 events, got %d and %d", len(euIDs), len(usIDs))
+	for id := range euIDs {
+		checks.Check(usIDs[id], "event %s should be in both regions", id)
+	}
+	report, err = Reconcile(ctx, "cart-4", eu, us, failMerge)
+	checks.Check(err == nil && !report.Merged && report.Appended["eu"]+report.Appended["us"] == 0, "a merged stream should be in sync, got %s (%v)", report.Divergence, err)
+
+	fmt.Println("\n--- Concurrent write during reconcile ---")
+	eu, us = newRegions("cart-5", created)
+	appendTo(eu, "cart-5", widget)
+	appendTo(us, "cart-5", gadget)
+	racing := func(d *Divergence) ([]kurrentdb.EventData, error) {
+		appendTo(us, "cart-5", gizmo) // lands between the read and the merge's append
+		return LastWriterWins(d)
+	}
+	_, err = Reconcile(ctx, "cart-5", eu, us, racing)
+	fmt.Printf("  %v\n", err)
+	checks.Check(isWrongExpectedVersion(err), "a write landing mid-merge should fail the merge with a wrong expected version, got %v", err)
+	report, err = Reconcile(ctx, "cart-5", eu, us, LastWriterWins)
+	euState, usState = cartState(read(eu, "cart-5")), cartState(read(us, "cart-5"))
+	fmt.Printf("  retried: eu: %s, us: %s\n", euState, usState)
+	checks.Check(err == nil && report.Merged && euState == usState && strings.Contains(euState, "[gadget gizmo]"), "running Reconcile again should converge on us's items, got %s / %s (%v)", euState, usState, err)
+	report, err = Reconcile(ctx, "cart-5", eu, us, failMerge)
+	checks.Check(err == nil && report.Appended["eu"]+report.Appended["us"] == 0, "superseded events should not be copied across afterwards, got %s (%v)", report.Divergence, err)
+
+	checks.Done()
+}
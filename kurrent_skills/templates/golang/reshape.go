@@ -0,0 +1,363 @@
+// KurrentDB Go Client Example - Reshaping an event model by replaying into new streams
+// Demonstrates: Splitting a monolithic stream into per-entity streams, resumable and without duplicates
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === RESHAPE ===
+
+// Metadata keys recording where a reshaped event was copied from
+const (
+	MetaReshapeSource   = "reshapeSource"
+	MetaReshapeRevision = "reshapeRevision"
+)
+
+// ReshapeOptions configures a Reshaper
+type ReshapeOptions struct {
+	// Source is the stream to replay
+	Source string
+	// Route returns the destination stream for a source event, or "" to leave it out
+	Route func(event *kurrentdb.RecordedEvent) string
+	// Transform rewrites a source event for its destination; nil copies type, content type, data
+	// and metadata. Either way the Reshaper sets the event ID and adds causation and the reshape keys.
+	Transform func(event *kurrentdb.RecordedEvent) (kurrentdb.EventData, error)
+	// Checkpoints stores the $all position of the last source event handled, to resume from
+	Checkpoints CheckpointStore
+}
+
+// ReshapeResult counts what one Run did with the source events it read
+type ReshapeResult struct {
+	Copied int
+	// AlreadyCopied counts events found in their destination already, from a run interrupted
+	// between the append and its checkpoint
+	AlreadyCopied int
+	// Dropped counts events Route left out
+	Dropped int
+}
+
+// Reshaper replays a source stream into the streams Route chooses. Events are handled in source
+// order, so each destination receives its events in their original order.
+//
+// Every copied event records its source revision in its metadata. Before the first append to a
+// destination, the Reshaper reads the destination's last event to learn how far the source was
+// copied there, and skips anything up to that: resuming from a checkpoint that lags the appends
+// never duplicates an event. Appends expect the destination's revision, so a second Reshaper
+// running at the same time fails instead of interleaving.
+type Reshaper struct {
+	client  *kurrentdb.Client
+	options ReshapeOptions
+
+	// destinations caches each destination's revision and the source revision copied into it
+	destinations map[string]*reshapeDestination
+	result       ReshapeResult
+
+	// afterAppend runs after each append; the demo fails it to interrupt a run before the checkpoint
+	afterAppend func(copied int) error
+}
+
+type reshapeDestination struct {
+	revision int64
+	// copied is the last source revision in the destination, or NoVersion
+	copied int64
+}
+
+func NewReshaper(client *kurrentdb.Client, options ReshapeOptions) *Reshaper {
+	return &Reshaper{client: client, options: options}
+}
+
+// Run copies the source events appended before Run was called and not yet handled by an earlier
+// run, then returns. The checkpoint is saved as it goes and on return, including on error.
+func (r *Reshaper) Run(ctx context.Context) (ReshapeResult, error) {
+	r.destinations = make(map[string]*reshapeDestination)
+	r.result = ReshapeResult{}
+
+	head, _, err := readAllHead(ctx, NewClientStore(r.client))
+	if err != nil {
+		return r.result, err
+	}
+	subscription := NewMeteredSubscription(r.client, kurrentdb.SubscribeToAllOptions{
+		Filter: &kurrentdb.SubscriptionFilter{
+			Type:  kurrentdb.StreamFilterType,
+			Regex: "^" + regexp.QuoteMeta(r.options.Source) + "$",
+		},
+	})
+	subscription.Checkpoints = r.options.Checkpoints
+	subscription.StopAt = &head
+
+	err = subscription.Run(ctx, func(event *kurrentdb.ResolvedEvent) error {
+		return r.copy(ctx, Resolve(event, false))
+	})
+	return r.result, err
+}
+
+// copy appends one source event to its destination, unless it is already there
+func (r *Reshaper) copy(ctx context.Context, event *kurrentdb.RecordedEvent) error {
+	stream := r.options.Route(event)
+	if stream == "" {
+		r.result.Dropped++
+		return nil
+	}
+	destination, err := r.destination(ctx, stream)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", stream, err)
+	}
+	if int64(event.EventNumber) <= destination.copied {
+		r.result.AlreadyCopied++
+		return nil
+	}
+
+	data, err := r.transform(event)
+	if err != nil {
+		return fmt.Errorf("transform %s@%d: %w", event.StreamID, event.EventNumber, err)
+	}
+	result, err := r.client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{
+		StreamState: expectedState(destination.revision),
+	}, data)
+	if err != nil {
+		// The destination may have changed under us; read it again if the run is retried
+		delete(r.destinations, stream)
+		return fmt.Errorf("append %s@%d to %s: %w", event.StreamID, event.EventNumber, stream, err)
+	}
+	destination.revision = int64(result.NextExpectedVersion)
+	destination.copied = int64(event.EventNumber)
+	r.result.Copied++
+
+	if r.afterAppend != nil {
+		return r.afterAppend(r.result.Copied)
+	}
+	return nil
+}
+
+// transform builds the destination event: a deterministic ID derived from the source event, and
+// the source's metadata with causation and the reshape keys added
+func (r *Reshaper) transform(event *kurrentdb.RecordedEvent) (kurrentdb.EventData, error) {
+	data := kurrentdb.EventData{
+		EventType:   event.EventType,
+		ContentType: recordedContentType(event),
+		Data:        event.Data,
+		Metadata:    event.UserMetadata,
+	}
+	if r.options.Transform != nil {
+		var err error
+		if data, err = r.options.Transform(event); err != nil {
+			return data, err
+		}
+	}
+
+	var meta Meta
+	if err := meta.UnmarshalJSON(data.Metadata); err != nil {
+		return data, err
+	}
+	for key, value := range CausedBy(event) {
+		meta[key] = value
+	}
+	meta[MetaReshapeSource] = event.StreamID
+	meta[MetaReshapeRevision] = strconv.FormatUint(event.EventNumber, 10)
+
+	metadata, err := meta.Marshal()
+	if err != nil {
+		return data, err
+	}
+	data.EventID = uuid.NewSHA1(event.EventID, []byte("reshape"))
+	data.Metadata = metadata
+	return data, nil
+}
+
+// destination returns the cached state of stream, reading its last event the first time
+func (r *Reshaper) destination(ctx context.Context, stream string) (*reshapeDestination, error) {
+	if destination, ok := r.destinations[stream]; ok {
+		return destination, nil
+	}
+
+	destination := &reshapeDestination{revision: NoVersion, copied: NoVersion}
+	last, err := LastEvent(ctx, r.client, stream)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil {
+		destination.revision = int64(last.EventNumber)
+
+		var meta Meta
+		if meta.UnmarshalFrom(last) == nil && meta[MetaReshapeSource] == r.options.Source {
+			if copied, parseErr := strconv.ParseInt(meta[MetaReshapeRevision], 10, 64); parseErr == nil {
+				destination.copied = copied
+			}
+		}
+	}
+
+	r.destinations[stream] = destination
+	return destination, nil
+}
+
+// RunReshape splits a monolithic orders stream into order-{id} streams, interrupting the first run
+// between an append and its checkpoint
+func RunReshape() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	dir, err := os.MkdirTemp("", "reshape")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// === THE MONOLITHIC STREAM ===
+	source := Streams.Name("orders", uuid.New().String())
+	orderIDs := []string{uuid.New().String(), uuid.New().String(), uuid.New().String()}
+	write := func(eventType string, order int, data string) {
+		payload := `{}`
+		if order >= 0 {
+			payload = fmt.Sprintf(`{"orderId":%q%s}`, orderIDs[order], data)
+		}
+		_, err := client.AppendToStream(ctx, source, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(payload),
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	write("OrderCreated", 0, `,"customerId":"cust-1","amount":0`)
+	write("OrderCreated", 1, `,"customerId":"cust-2","amount":0`)
+	write("ItemAdded", 0, `,"item":"Widget","price":25`)
+	write("SystemHeartbeat", -1, "")
+	write("ItemAdded", 1, `,"item":"Gadget","price":30`)
+	write("OrderCreated", 2, `,"customerId":"cust-3","amount":0`)
+	write("OrderShipped", 0, `,"shippedAt":"2024-01-15T10:00:00Z"`)
+	write("ItemAdded", 2, `,"item":"Gizmo","price":5`)
+	fmt.Printf("Wrote 8 events for 3 orders to %s\n", source)
+
+	options := ReshapeOptions{
+		Source: source,
+		Route: func(event *kurrentdb.RecordedEvent) string {
+			var order struct {
+				OrderID string `json:"orderId"`
+			}
+			if json.Unmarshal(event.Data, &order) != nil || order.OrderID == "" {
+				return ""
+			}
+			return Streams.Name("order", order.OrderID)
+		},
+		Checkpoints: FileCheckpoint{Path: filepath.Join(dir, "reshape.json")},
+	}
+
+	// === INTERRUPTED RUN ===
+	fmt.Println("\n=== First run, interrupted after the 4th append ===")
+	errCrash := errors.New("simulated crash after append")
+	interrupted := NewReshaper(client, options)
+	interrupted.afterAppend = func(copied int) error {
+		if copied == 4 {
+			return errCrash
+		}
+		return nil
+	}
+	first, firstErr := interrupted.Run(ctx)
+	fmt.Printf("  %+v, err %v\n", first, firstErr)
+
+	// === RESUMED RUN ===
+	fmt.Println("\n=== Restarted ===")
+	resumed, resumedErr := NewReshaper(client, options).Run(ctx)
+	fmt.Printf("  %+v, err %v\n", resumed, resumedErr)
+
+	// === INCREMENTAL RUN ===
+	fmt.Println("\n=== More events, run again ===")
+	write("OrderShipped", 1, `,"shippedAt":"2024-01-16T10:00:00Z"`)
+	write("ItemAdded", 2, `,"item":"Doohickey","price":7`)
+	incremental, incrementalErr := NewReshaper(client, options).Run(ctx)
+	fmt.Printf("  %+v, err %v\n", incremental, incrementalErr)
+
+	// === RESULT ===
+	fmt.Println("\n=== Per-order streams ===")
+	readTypes := func(stream string) ([]string, []*kurrentdb.RecordedEvent) {
+		events, err := client.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{From: kurrentdb.Start{}}, ^uint64(0))
+		if err != nil {
+			panic(err)
+		}
+		defer events.Close()
+		var types []string
+		var recorded []*kurrentdb.RecordedEvent
+		for {
+			event, err := events.Recv()
+			if errors.Is(err, io.EOF) {
+				return types, recorded
+			}
+			if err != nil {
+				panic(err)
+			}
+			types = append(types, Resolve(event, false).EventType)
+			recorded = append(recorded, Resolve(event, false))
+		}
+	}
+	var orderTypes [3][]string
+	var firstCopied *kurrentdb.RecordedEvent
+	for i, orderID := range orderIDs {
+		var events []*kurrentdb.RecordedEvent
+		orderTypes[i], events = readTypes(Streams.Name("order", orderID))
+		if i == 0 {
+			firstCopied = events[0]
+		}
+		fmt.Printf("  %s: %v\n", Streams.Name("order", orderID), orderTypes[i])
+	}
+	var meta Meta
+	meta.UnmarshalFrom(firstCopied)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if !errors.Is(firstErr, errCrash) || first.Copied != 4 {
+		fmt.Printf("FAIL: The first run should stop after 4 copies with the crash, got %+v (%v)\n", first, firstErr)
+		passed = false
+	}
+	if resumedErr != nil || resumed.AlreadyCopied != 1 || resumed.Copied != 3 || resumed.Dropped != 0 {
+		fmt.Printf("FAIL: The restart should skip the event copied before the crash and copy the other 3, got %+v (%v)\n", resumed, resumedErr)
+		passed = false
+	}
+	if incrementalErr != nil || incremental != (ReshapeResult{Copied: 2}) {
+		fmt.Printf("FAIL: The incremental run should copy only the 2 new events, got %+v (%v)\n", incremental, incrementalErr)
+		passed = false
+	}
+	expected := [3]string{
+		"[OrderCreated ItemAdded OrderShipped]",
+		"[OrderCreated ItemAdded OrderShipped]",
+		"[OrderCreated ItemAdded ItemAdded]",
+	}
+	for i := range expected {
+		if fmt.Sprint(orderTypes[i]) != expected[i] {
+			fmt.Printf("FAIL: Order %d should hold %s once each in source order, got %v\n", i+1, expected[i], orderTypes[i])
+			passed = false
+		}
+	}
+	if meta[MetaReshapeSource] != source || meta[MetaReshapeRevision] != "0" || meta.Causation() == "" {
+		fmt.Printf("FAIL: Copied events should record their source and causation, got %v\n", meta)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll reshape tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
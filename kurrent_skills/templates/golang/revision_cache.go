@@ -0,0 +1,286 @@
+// KurrentDB Go Client Example - Stream revision cache for high-throughput appenders
+// Demonstrates: Appending with a cached expected revision and reading only after a version conflict
+package main
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === REVISION CACHE ===
+
+// RevisionCache remembers the last known revision of recently written streams, evicting the least
+// recently used beyond Capacity. NoVersion records a stream known not to exist. Safe for concurrent use.
+type RevisionCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // front = most recently used
+	entries  map[string]*list.Element
+}
+
+type revisionEntry struct {
+	stream   string
+	revision int64
+}
+
+func NewRevisionCache(capacity int) *RevisionCache {
+	if capacity <= 0 {
+		capacity = 10000
+	}
+	return &RevisionCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns the cached revision of stream
+func (c *RevisionCache) Get(stream string) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[stream]
+	if !ok {
+		return 0, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*revisionEntry).revision, true
+}
+
+// Set records the revision of stream, e.g. from WriteResult.NextExpectedVersion
+func (c *RevisionCache) Set(stream string, revision int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[stream]; ok {
+		element.Value.(*revisionEntry).revision = revision
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[stream] = c.order.PushFront(&revisionEntry{stream: stream, revision: revision})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*revisionEntry).stream)
+	}
+}
+
+// Invalidate forgets stream, so the next append reads its revision again
+func (c *RevisionCache) Invalidate(stream string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[stream]; ok {
+		c.order.Remove(element)
+		delete(c.entries, stream)
+	}
+}
+
+// Len returns the number of cached streams
+func (c *RevisionCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// === CACHED APPENDER ===
+
+// AppenderStats counts round trips to the server
+type AppenderStats struct {
+	Reads     int64
+	Appends   int64
+	Conflicts int64
+}
+
+// CachedAppender appends at the stream's expected revision without reading it first when the
+// revision is cached. On WrongExpectedVersion it invalidates the entry, reads the real revision
+// and retries.
+//
+// Like read-then-append, this writes at whatever the stream's current revision turns out to be: the
+// expected revision saves the read, it doesn't protect a decision made from state. Use
+// OrderRepository's load-decide-save for that.
+type CachedAppender struct {
+	client *kurrentdb.Client
+	cache  *RevisionCache
+	// MaxAttempts bounds appends per call when other writers keep winning the race
+	MaxAttempts int
+
+	reads, appends, conflicts atomic.Int64
+}
+
+func NewCachedAppender(client *kurrentdb.Client, cache *RevisionCache) *CachedAppender {
+	return &CachedAppender{client: client, cache: cache, MaxAttempts: 3}
+}
+
+func (a *CachedAppender) Stats() AppenderStats {
+	return AppenderStats{Reads: a.reads.Load(), Appends: a.appends.Load(), Conflicts: a.conflicts.Load()}
+}
+
+// Append appends events at the cached (or freshly read) revision of stream
+func (a *CachedAppender) Append(ctx context.Context, stream string, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+	for attempt := 1; ; attempt++ {
+		revision, ok := a.cache.Get(stream)
+		if !ok {
+			var err error
+			if revision, err = a.readRevision(ctx, stream); err != nil {
+				return nil, err
+			}
+		}
+
+		a.appends.Add(1)
+		result, err := a.client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{StreamState: expectedState(revision)}, events...)
+		if err == nil {
+			a.cache.Set(stream, int64(result.NextExpectedVersion))
+			return result, nil
+		}
+
+		a.cache.Invalidate(stream)
+		if !isWrongExpectedVersion(err) {
+			return nil, err
+		}
+		a.conflicts.Add(1)
+		if attempt >= a.MaxAttempts {
+			return nil, fmt.Errorf("append to %s: still conflicting after %d attempts: %w", stream, attempt, err)
+		}
+	}
+}
+
+// readRevision is the read-before-append round trip the cache avoids
+func (a *CachedAppender) readRevision(ctx context.Context, stream string) (int64, error) {
+	a.reads.Add(1)
+	revision, err := currentRevision(ctx, a.client, stream)
+	if err != nil {
+		return 0, fmt.Errorf("read revision of %s: %w", stream, err)
+	}
+	return revision, nil
+}
+
+// currentRevision returns the revision of the last event in stream, or NoVersion if it doesn't exist or
+// has been deleted; appending to a tombstoned stream still fails, with the server's deleted error
+func currentRevision(ctx context.Context, client *kurrentdb.Client, stream string) (int64, error) {
+	event, err := LastEvent(ctx, client, stream)
+	if err != nil {
+		return 0, err
+	}
+	if event == nil {
+		return NoVersion, nil
+	}
+	return int64(event.EventNumber), nil
+}
+
+func expectedState(revision int64) kurrentdb.StreamState {
+	if revision == NoVersion {
+		return kurrentdb.NoStream{}
+	}
+	return kurrentdb.Revision(uint64(revision))
+}
+
+// isWrongExpectedVersion reports whether err is a failed expected-state check, from the client or
+// the in-memory store
+func isWrongExpectedVersion(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, errMemoryWrongExpectedVersion) {
+		return true
+	}
+	return errorCode(err) == kurrentdb.ErrorCodeWrongExpectedVersion
+}
+
+// RunRevisionCache compares round trips with and without the cache, including a conflicting writer
+func RunRevisionCache() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	const appends = 20
+	event := func(i int) kurrentdb.EventData {
+		return kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   "ItemAdded",
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(fmt.Sprintf(`{"item":"Widget %d","price":1}`, i)),
+		}
+	}
+
+	// === WITHOUT CACHE ===
+	// Invalidating after every append reproduces the read-before-append pattern
+	uncachedStream := Streams.Name("order", uuid.New().String())
+	uncached := NewCachedAppender(client, NewRevisionCache(1))
+	for i := 0; i < appends; i++ {
+		if _, err := uncached.Append(ctx, uncachedStream, event(i)); err != nil {
+			panic(err)
+		}
+		uncached.cache.Invalidate(uncachedStream)
+	}
+	before := uncached.Stats()
+	fmt.Printf("Read before every append: %d reads + %d appends = %d round trips\n", before.Reads, before.Appends, before.Reads+before.Appends)
+
+	// === WITH CACHE ===
+	cachedStream := Streams.Name("order", uuid.New().String())
+	appender := NewCachedAppender(client, NewRevisionCache(1000))
+	for i := 0; i < appends; i++ {
+		if _, err := appender.Append(ctx, cachedStream, event(i)); err != nil {
+			panic(err)
+		}
+	}
+	after := appender.Stats()
+	fmt.Printf("Cached revisions:         %d reads + %d appends = %d round trips\n", after.Reads, after.Appends, after.Reads+after.Appends)
+
+	// === CONFLICTING WRITER ===
+	// Another process appends, so the cached revision is stale
+	if _, err := client.AppendToStream(ctx, cachedStream, kurrentdb.AppendToStreamOptions{}, event(-1)); err != nil {
+		panic(err)
+	}
+	result, err := appender.Append(ctx, cachedStream, event(appends))
+	if err != nil {
+		panic(err)
+	}
+	conflict := appender.Stats()
+	fmt.Printf("After a foreign append:   %d conflict, %d extra read, written at revision %d\n",
+		conflict.Conflicts, conflict.Reads-after.Reads, result.NextExpectedVersion)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if before.Reads != appends {
+		fmt.Printf("FAIL: Uncached appender should read %d times, got %d\n", appends, before.Reads)
+		passed = false
+	}
+	if after.Reads != 1 || after.Appends != appends {
+		fmt.Printf("FAIL: Cached appender should read once and append %d times, got %d reads, %d appends\n", appends, after.Reads, after.Appends)
+		passed = false
+	}
+	if conflict.Conflicts != 1 || conflict.Reads != 2 || result.NextExpectedVersion != appends+1 {
+		fmt.Printf("FAIL: Stale revision should cost one conflict and one read, got %+v at revision %d\n", conflict, result.NextExpectedVersion)
+		passed = false
+	}
+	if revision, ok := appender.cache.Get(cachedStream); !ok || revision != appends+1 {
+		fmt.Printf("FAIL: Cache should hold revision %d after the retry, got %d (%v)\n", appends+1, revision, ok)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll revision cache tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,446 @@
+// KurrentDB Go Client Example - Inferring event schemas from sampled payloads
+// Demonstrates: Sampling the events of an unknown stream and drafting a JSON Schema per event type to seed a schema registry
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === SCHEMA INFERENCE ===
+
+// shape accumulates every value seen at one position in a payload: the root, a property, or the
+// items of an array
+type shape struct {
+	// seen counts the values observed here
+	seen int
+	// types counts them by JSON type, with whole numbers counted as integer
+	types map[string]int
+	// objects counts the object values; a property is required when it was in every one
+	objects    int
+	properties map[string]*shape
+	items      *shape
+}
+
+func newShape() *shape {
+	return &shape{types: make(map[string]int)}
+}
+
+func (s *shape) observe(value interface{}) {
+	s.seen++
+	kind := jsonTypeName(value)
+	if number, ok := value.(float64); ok && number == math.Trunc(number) {
+		kind = "integer"
+	}
+	s.types[kind]++
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		s.objects++
+		if s.properties == nil {
+			s.properties = make(map[string]*shape)
+		}
+		for name, property := range v {
+			if s.properties[name] == nil {
+				s.properties[name] = newShape()
+			}
+			s.properties[name].observe(property)
+		}
+	case []interface{}:
+		if s.items == nil {
+			s.items = newShape()
+		}
+		for _, item := range v {
+			s.items.observe(item)
+		}
+	}
+}
+
+// typeName is the single type every value here had, or "" with the types they disagreed on.
+// Integers widen to number when both were seen; anything else that differs, null included,
+// is a union the schema subset can't express.
+func (s *shape) typeName() (string, []string) {
+	kinds := slices.Sorted(maps.Keys(s.types))
+	if len(kinds) == 2 && kinds[0] == "integer" && kinds[1] == "number" {
+		return "number", nil
+	}
+	if len(kinds) == 1 {
+		return kinds[0], nil
+	}
+	return "", kinds
+}
+
+// draft renders the shape as a schema. A union leaves Type empty, so the draft accepts anything
+// there, and says what was seen in the description for whoever finishes the schema.
+func (s *shape) draft() *JSONSchema {
+	schema := &JSONSchema{}
+	kind, union := s.typeName()
+	schema.Type = kind
+	if len(union) > 0 {
+		counts := make([]string, len(union))
+		for i, kind := range union {
+			counts[i] = fmt.Sprintf("%s: %d", kind, s.types[kind])
+		}
+		schema.Description = "unknown: samples disagree (" + strings.Join(counts, ", ") + ")"
+	}
+
+	if s.objects > 0 {
+		schema.Properties = make(map[string]*JSONSchema, len(s.properties))
+		for name, property := range s.properties {
+			schema.Properties[name] = property.draft()
+			if property.seen == s.objects {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+		slices.Sort(schema.Required)
+	}
+	if s.items != nil && s.items.seen > 0 {
+		schema.Items = s.items.draft()
+	}
+	return schema
+}
+
+// unions lists the paths under this shape whose samples disagreed on a type
+func (s *shape) unions(path string) []string {
+	var found []string
+	if _, union := s.typeName(); len(union) > 0 {
+		found = append(found, path+": "+strings.Join(union, " | "))
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.properties)) {
+		found = append(found, s.properties[name].unions(path+"."+name)...)
+	}
+	if s.items != nil {
+		found = append(found, s.items.unions(path+"[]")...)
+	}
+	return found
+}
+
+// SchemaInferrer drafts a JSON Schema for each event type from the payloads it is shown: the
+// properties seen, their types, and which were present in every sample. A draft only describes
+// the samples; it is a starting point for the schema registry and serializers, not a contract.
+// Not safe for concurrent use.
+type SchemaInferrer struct {
+	// SamplesPerType caps how many events of each type are looked at, so a long stream is
+	// sampled rather than read in full; 0 looks at all of them
+	SamplesPerType int
+
+	shapes  map[string]*shape
+	skipped map[string]int
+}
+
+func NewSchemaInferrer(samplesPerType int) *SchemaInferrer {
+	return &SchemaInferrer{
+		SamplesPerType: samplesPerType,
+		shapes:         make(map[string]*shape),
+		skipped:        make(map[string]int),
+	}
+}
+
+// Observe adds one payload of eventType. It reports false once the type has all the samples it
+// needs, and an error for data that isn't JSON, which is counted as skipped.
+func (i *SchemaInferrer) Observe(eventType string, data []byte) (bool, error) {
+	root := i.shapes[eventType]
+	if root == nil {
+		root = newShape()
+		i.shapes[eventType] = root
+	}
+	if i.SamplesPerType > 0 && root.seen >= i.SamplesPerType {
+		return false, nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		i.skipped[eventType]++
+		return false, fmt.Errorf("%s: data is not JSON: %w", eventType, err)
+	}
+	root.observe(value)
+	return true, nil
+}
+
+// Sample observes the events reader returns until it runs out, skipping system events ($-prefixed
+// types) and payloads that aren't JSON. Links are sampled as the events they resolve to. It returns
+// how many events were sampled.
+func (i *SchemaInferrer) Sample(ctx context.Context, reader EventReader) (int, error) {
+	defer reader.Close()
+
+	sampled := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return sampled, err
+		}
+		resolved, err := reader.Recv()
+		if errors.Is(err, io.EOF) {
+			return sampled, nil
+		}
+		if err != nil {
+			return sampled, err
+		}
+		event := resolved.Event
+		if event == nil || strings.HasPrefix(event.EventType, "$") {
+			continue
+		}
+		if ok, _ := i.Observe(event.EventType, event.Data); ok {
+			sampled++
+		}
+	}
+}
+
+// EventTypes returns the event types seen, sorted
+func (i *SchemaInferrer) EventTypes() []string {
+	return slices.Sorted(maps.Keys(i.shapes))
+}
+
+// Samples returns how many payloads of eventType were observed and how many were skipped as not JSON
+func (i *SchemaInferrer) Samples(eventType string) (observed, skipped int) {
+	if root := i.shapes[eventType]; root != nil {
+		observed = root.seen
+	}
+	return observed, i.skipped[eventType]
+}
+
+// Draft returns the schema inferred for eventType, or nil if none of its payloads were JSON
+func (i *SchemaInferrer) Draft(eventType string) *JSONSchema {
+	root := i.shapes[eventType]
+	if root == nil || root.seen == 0 {
+		return nil
+	}
+	schema := root.draft()
+	schema.Schema = "https://json-schema.org/draft/2020-12/schema"
+	schema.Title = eventType
+	return schema
+}
+
+// DraftJSON returns the draft for eventType as an indented document, ready to review and register
+func (i *SchemaInferrer) DraftJSON(eventType string) ([]byte, error) {
+	schema := i.Draft(eventType)
+	if schema == nil {
+		return nil, fmt.Errorf("%w: no JSON samples of %s", ErrSchemaNotFound, eventType)
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// Unions lists the paths in eventType's payloads whose samples disagreed on a type, like
+// "$.amount: integer | string"; each needs a decision before the draft is registered
+func (i *SchemaInferrer) Unions(eventType string) []string {
+	if root := i.shapes[eventType]; root != nil {
+		return root.unions("$")
+	}
+	return nil
+}
+
+// printSchemaDrafts prints the draft schema of every event type sampled, with what needs review
+func printSchemaDrafts(inferrer *SchemaInferrer) {
+	for _, eventType := range inferrer.EventTypes() {
+		observed, skipped := inferrer.Samples(eventType)
+		fmt.Printf("\n# %s (%d samples, %d skipped)\n", eventType, observed, skipped)
+		draft, err := inferrer.DraftJSON(eventType)
+		if err != nil {
+			fmt.Printf("  %v\n", err)
+			continue
+		}
+		fmt.Println(string(draft))
+		for _, union := range inferrer.Unions(eventType) {
+			fmt.Printf("  review %s\n", union)
+		}
+	}
+}
+
+// orderCreatedSamples are OrderCreated payloads as an unfamiliar stream might hold them: a
+// coupon on some orders, and a legacy writer that sent the total as a string
+var orderCreatedSamples = []string{
+	`{"orderId":"o-1","customerId":"c-1","total":120,"currency":"EUR","lines":[{"sku":"A","quantity":2}]}`,
+	`{"orderId":"o-2","customerId":"c-2","total":35.5,"currency":"EUR","lines":[{"sku":"B","quantity":1}],"coupon":"SPRING"}`,
+	`{"orderId":"o-3","customerId":"c-1","total":"80.00","currency":"USD","lines":[]}`,
+}
+
+// schemaInferenceEvents returns the sample orders with a shipment and an event that isn't JSON
+func schemaInferenceEvents() []kurrentdb.EventData {
+	var events []kurrentdb.EventData
+	for _, data := range orderCreatedSamples {
+		events = append(events, schemaEvent("OrderCreated", data, ""))
+	}
+	events = append(events,
+		schemaEvent("OrderShipped", `{"orderId":"o-1","carrier":"DHL","trackingNumber":null}`, ""),
+		kurrentdb.EventData{EventID: uuid.New(), EventType: "OrderNote", ContentType: kurrentdb.ContentTypeBinary, Data: []byte("gift wrap")},
+	)
+	return events
+}
+
+// === CHECKS ===
+
+// RunSchemaInferenceChecks infers drafts from an in-memory stream and checks them against the samples
+func RunSchemaInferenceChecks() {
+	checks := newChecker("schema inference")
+
+	ctx := context.Background()
+
+	fmt.Println("\n--- Types and optionality ---")
+	inferrer := NewSchemaInferrer(0)
+	for _, data := range orderCreatedSamples[:2] {
+		_, err := inferrer.Observe("OrderCreated", []byte(data))
+		checks.Check(err == nil, "a JSON payload should be observed: %v", err)
+	}
+	draft := inferrer.Draft("OrderCreated")
+	checks.Check(draft != nil && draft.Type == "object", "the draft should describe an object, got %+v", draft)
+	if draft != nil {
+		checks.Check(slices.Equal(draft.Required, []string{"currency", "customerId", "lines", "orderId", "total"}),
+			"every property but the coupon should be required, got %v", draft.Required)
+		checks.Check(draft.Properties["coupon"] != nil && draft.Properties["coupon"].Type == "string",
+			"the coupon should be an optional string, got %+v", draft.Properties["coupon"])
+		checks.Check(draft.Properties["total"].Type == "number", "120 and 35.5 should widen to number, got %q", draft.Properties["total"].Type)
+		lines := draft.Properties["lines"]
+		checks.Check(lines.Type == "array" && lines.Items != nil && lines.Items.Type == "object",
+			"lines should be an array of objects, got %+v", lines)
+		if lines.Items != nil {
+			checks.Check(lines.Items.Properties["quantity"].Type == "integer", "a whole quantity should be an integer, got %q", lines.Items.Properties["quantity"].Type)
+			checks.Check(slices.Equal(lines.Items.Required, []string{"quantity", "sku"}), "line properties should be required, got %v", lines.Items.Required)
+		}
+		checks.Check(len(inferrer.Unions("OrderCreated")) == 0, "agreeing samples should have no unions, got %v", inferrer.Unions("OrderCreated"))
+	}
+
+	fmt.Println("\n--- Conflicting types ---")
+	_, err := inferrer.Observe("OrderCreated", []byte(orderCreatedSamples[2]))
+	checks.Check(err == nil, "the legacy payload should be observed: %v", err)
+	draft = inferrer.Draft("OrderCreated")
+	total := draft.Properties["total"]
+	checks.Check(total.Type == "" && strings.Contains(total.Description, "integer: 1") && strings.Contains(total.Description, "string: 1"),
+		"a string total should make the total unknown with the types seen, got %q %q", total.Type, total.Description)
+	checks.Check(slices.Equal(inferrer.Unions("OrderCreated"), []string{"$.total: integer | number | string"}),
+		"the total should be listed for review, got %v", inferrer.Unions("OrderCreated"))
+	checks.Check(draft.Properties["lines"].Items != nil, "an empty array shouldn't lose the items seen before")
+
+	nullable := NewSchemaInferrer(0)
+	nullable.Observe("OrderShipped", []byte(`{"trackingNumber":"T-1"}`))
+	nullable.Observe("OrderShipped", []byte(`{"trackingNumber":null}`))
+	checks.Check(nullable.Draft("OrderShipped").Properties["trackingNumber"].Type == "",
+		"a sometimes-null property should be unknown, not a string")
+
+	fmt.Println("\n--- Drafts are registrable ---")
+	raw, err := inferrer.DraftJSON("OrderCreated")
+	checks.Check(err == nil, "the draft should render: %v", err)
+	parsed, err := ParseJSONSchema(raw)
+	checks.Check(err == nil, "the draft should parse as a registry schema: %v\n%s", err, raw)
+	if parsed != nil {
+		for _, data := range orderCreatedSamples {
+			var value interface{}
+			json.Unmarshal([]byte(data), &value)
+			checks.Check(len(parsed.Check(value)) == 0, "the draft should accept its own sample %s, got %v", data, parsed.Check(value))
+		}
+		var missing interface{}
+		json.Unmarshal([]byte(`{"orderId":"o-4","total":1}`), &missing)
+		checks.Check(len(parsed.Check(missing)) > 0, "the draft should reject an order without its required properties")
+	}
+	registry := NewCachingSchemaRegistry(NewMemorySchemaSource().Register("OrderCreated", "1", string(raw)))
+	checks.Check(registry.Validate("OrderCreated", "1", []byte(orderCreatedSamples[1])) == nil, "the registered draft should validate a sample")
+	_, err = NewSchemaInferrer(0).DraftJSON("OrderCreated")
+	checks.Check(errors.Is(err, ErrSchemaNotFound), "an unseen type should have no draft, got %v", err)
+
+	fmt.Println("\n--- Sampling a stream ---")
+	store := NewMemoryEventStore()
+	stream := Streams.Name("order", "inference")
+	events := schemaInferenceEvents()
+	events = append(events, schemaEvent("$metadata", `{"$maxCount":10}`, ""))
+	store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, events...)
+
+	sampler := NewSchemaInferrer(0)
+	reader, err := store.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{From: kurrentdb.Start{}}, ^uint64(0))
+	checks.Check(err == nil, "the stream should be readable: %v", err)
+	sampled, err := sampler.Sample(ctx, reader)
+	checks.Check(err == nil && sampled == 4, "the JSON events should be sampled, got %d (%v)", sampled, err)
+	checks.Check(slices.Equal(sampler.EventTypes(), []string{"OrderCreated", "OrderNote", "OrderShipped"}),
+		"system events should be skipped, got %v", sampler.EventTypes())
+	if observed, skipped := sampler.Samples("OrderNote"); observed != 0 || skipped != 1 {
+		checks.Check(false, "the binary note should be skipped, got %d observed, %d skipped", observed, skipped)
+	}
+	checks.Check(sampler.Draft("OrderNote") == nil, "a type with no JSON samples should have no draft")
+
+	capped := NewSchemaInferrer(2)
+	reader, _ = store.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{From: kurrentdb.Start{}}, ^uint64(0))
+	sampled, _ = capped.Sample(ctx, reader)
+	observed, _ := capped.Samples("OrderCreated")
+	checks.Check(sampled == 3 && observed == 2, "the cap should stop at 2 OrderCreated, got %d sampled, %d observed", sampled, observed)
+	checks.Check(len(capped.Unions("OrderCreated")) == 0, "the legacy order is past the cap, got %v", capped.Unions("OrderCreated"))
+
+	checks.Done()
+}
+
+// === DEMO ===
+
+// RunSchemaInference writes orders to a fresh stream, samples it back and prints a draft schema per type
+func RunSchemaInference() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	store := NewClientStore(client)
+	stream := Streams.Name("order", uuid.New().String())
+	_, appendErr := store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, schemaInferenceEvents()...)
+
+	inferrer := NewSchemaInferrer(100)
+	var sampled int
+	reader, sampleErr := store.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{From: kurrentdb.Start{}}, ^uint64(0))
+	if sampleErr == nil {
+		sampled, sampleErr = inferrer.Sample(ctx, reader)
+	}
+	fmt.Printf("Sampled %d events from %s\n", sampled, stream)
+	printSchemaDrafts(inferrer)
+
+	raw, draftErr := inferrer.DraftJSON("OrderCreated")
+	var parsed *JSONSchema
+	if draftErr == nil {
+		parsed, draftErr = ParseJSONSchema(raw)
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+	if appendErr != nil || sampleErr != nil {
+		fmt.Printf("FAIL: The events should be written and sampled, got %v / %v\n", appendErr, sampleErr)
+		passed = false
+	}
+	if sampled != 4 {
+		fmt.Printf("FAIL: The 4 JSON events should be sampled, got %d\n", sampled)
+		passed = false
+	}
+	if draftErr != nil || parsed == nil {
+		fmt.Printf("FAIL: The OrderCreated draft should parse as a registry schema, got %v\n", draftErr)
+		passed = false
+	} else {
+		if !slices.Contains(parsed.Required, "orderId") || slices.Contains(parsed.Required, "coupon") {
+			fmt.Printf("FAIL: orderId should be required and the coupon optional, got %v\n", parsed.Required)
+			passed = false
+		}
+		if parsed.Properties["total"] == nil || parsed.Properties["total"].Type != "" {
+			fmt.Printf("FAIL: The total should be unknown, as one sample sent a string\n")
+			passed = false
+		}
+	}
+	if shipped := inferrer.Draft("OrderShipped"); shipped == nil || shipped.Properties["trackingNumber"].Type != "null" {
+		fmt.Printf("FAIL: A tracking number only ever null should be typed null, got %+v\n", shipped)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll schema inference tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
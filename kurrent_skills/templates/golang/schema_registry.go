@@ -0,0 +1,685 @@
+// KurrentDB Go Client Example - Schema registry validation on append and read
+// Demonstrates: Validating event data against centrally managed JSON Schemas, stamping schemaVersion, and caching lookups
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === SCHEMA REGISTRY ===
+
+var (
+	// ErrSchemaViolation is returned for event data its schema rejects
+	ErrSchemaViolation = errors.New("schema violation")
+	// ErrSchemaNotFound is returned for an event type or version the registry doesn't have
+	ErrSchemaNotFound = errors.New("schema not found")
+)
+
+// SchemaRegistry validates event data against registered schemas. Versions are opaque strings
+// chosen by the registry; numeric ones also read back through Meta.SchemaVersion.
+type SchemaRegistry interface {
+	// Validate checks data against the schema of eventType at version
+	Validate(eventType, version string, data []byte) error
+	// Latest returns the newest version of eventType's schema, or "" if it has none
+	Latest(eventType string) (version string)
+}
+
+// SchemaSource is the central registry's API, e.g. an HTTP client for it. Every call is assumed to
+// be a network round trip; CachingSchemaRegistry keeps them off the append path.
+type SchemaSource interface {
+	// Schema returns the JSON Schema document of eventType at version, or ErrSchemaNotFound
+	Schema(eventType, version string) ([]byte, error)
+	// LatestVersion returns eventType's newest version, or "" if it has none
+	LatestVersion(eventType string) (string, error)
+}
+
+// schemaKey identifies one registered schema
+type schemaKey struct {
+	eventType, version string
+}
+
+// cachedLatest is a LatestVersion answer and when it was fetched
+type cachedLatest struct {
+	version   string
+	fetchedAt time.Time
+}
+
+// CachingSchemaRegistry is a SchemaRegistry over a SchemaSource. A registered version never
+// changes, so each schema is fetched and compiled once and kept. The latest version does change
+// as new ones are registered, so it is trusted for LatestTTL and then asked for again; until
+// then appends keep stamping the version they saw. Safe for concurrent use.
+type CachingSchemaRegistry struct {
+	source SchemaSource
+	// LatestTTL is how long a latest version, or the lack of one, is reused; defaults to a minute
+	LatestTTL time.Duration
+
+	mu      sync.Mutex
+	schemas map[schemaKey]*JSONSchema
+	latest  map[string]cachedLatest
+	now     func() time.Time
+}
+
+func NewCachingSchemaRegistry(source SchemaSource) *CachingSchemaRegistry {
+	return &CachingSchemaRegistry{
+		source:    source,
+		LatestTTL: time.Minute,
+		schemas:   make(map[schemaKey]*JSONSchema),
+		latest:    make(map[string]cachedLatest),
+		now:       time.Now,
+	}
+}
+
+func (r *CachingSchemaRegistry) Validate(eventType, version string, data []byte) error {
+	schema, err := r.schema(eventType, version)
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("%w: %s v%s: data is not JSON: %v", ErrSchemaViolation, eventType, version, err)
+	}
+	if problems := schema.Check(value); len(problems) > 0 {
+		return fmt.Errorf("%w: %s v%s: %s", ErrSchemaViolation, eventType, version, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// schema returns the compiled schema, fetching it on first use. Lookups run without the lock, so
+// a slow registry holds up only the events that need it.
+func (r *CachingSchemaRegistry) schema(eventType, version string) (*JSONSchema, error) {
+	key := schemaKey{eventType, version}
+	r.mu.Lock()
+	schema, ok := r.schemas[key]
+	r.mu.Unlock()
+	if ok {
+		return schema, nil
+	}
+
+	raw, err := r.source.Schema(eventType, version)
+	if err != nil {
+		return nil, fmt.Errorf("fetch schema %s v%s: %w", eventType, version, err)
+	}
+	schema, err = ParseJSONSchema(raw)
+	if err != nil {
+		return nil, fmt.Errorf("schema %s v%s: %w", eventType, version, err)
+	}
+
+	r.mu.Lock()
+	r.schemas[key] = schema
+	r.mu.Unlock()
+	return schema, nil
+}
+
+// Latest returns the cached latest version while it is fresh. When the registry can't be reached
+// it keeps answering with the last version it saw, however old, or "" if it never saw one.
+func (r *CachingSchemaRegistry) Latest(eventType string) string {
+	r.mu.Lock()
+	cached, ok := r.latest[eventType]
+	now := r.now()
+	r.mu.Unlock()
+	if ok && now.Sub(cached.fetchedAt) < r.LatestTTL {
+		return cached.version
+	}
+
+	version, err := r.source.LatestVersion(eventType)
+	if err != nil {
+		fmt.Printf("  [schemas] latest version of %s: %v\n", eventType, err)
+		return cached.version
+	}
+
+	r.mu.Lock()
+	r.latest[eventType] = cachedLatest{version: version, fetchedAt: now}
+	r.mu.Unlock()
+	return version
+}
+
+// MemorySchemaSource is a SchemaSource in memory, standing in for the central registry in tests
+// and demos. It counts lookups, so callers can see what caching saves.
+type MemorySchemaSource struct {
+	mu       sync.Mutex
+	schemas  map[schemaKey][]byte
+	versions map[string][]string
+	lookups  int
+}
+
+func NewMemorySchemaSource() *MemorySchemaSource {
+	return &MemorySchemaSource{schemas: make(map[schemaKey][]byte), versions: make(map[string][]string)}
+}
+
+// Register adds version of eventType's schema; the last version registered is the latest
+func (s *MemorySchemaSource) Register(eventType, version, schema string) *MemorySchemaSource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schemas[schemaKey{eventType, version}] = []byte(schema)
+	s.versions[eventType] = append(s.versions[eventType], version)
+	return s
+}
+
+func (s *MemorySchemaSource) Schema(eventType, version string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lookups++
+	schema, ok := s.schemas[schemaKey{eventType, version}]
+	if !ok {
+		return nil, ErrSchemaNotFound
+	}
+	return schema, nil
+}
+
+func (s *MemorySchemaSource) LatestVersion(eventType string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lookups++
+	versions := s.versions[eventType]
+	if len(versions) == 0 {
+		return "", nil
+	}
+	return versions[len(versions)-1], nil
+}
+
+// Lookups counts the calls made to the source
+func (s *MemorySchemaSource) Lookups() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lookups
+}
+
+// === JSON SCHEMA ===
+
+// JSONSchema is the subset of JSON Schema event contracts need: types, required and additional
+// properties, nested objects and arrays, enums, numeric ranges, string lengths and patterns.
+// ParseJSONSchema rejects any other keyword rather than silently not enforcing it.
+type JSONSchema struct {
+	// Annotations, accepted and ignored
+	Schema      string        `json:"$schema,omitempty"`
+	ID          string        `json:"$id,omitempty"`
+	Title       string        `json:"title,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Examples    []interface{} `json:"examples,omitempty"`
+
+	// Type is one of object, array, string, number, integer, boolean or null; "" allows any
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// ParseJSONSchema decodes and compiles a schema document
+func ParseJSONSchema(raw []byte) (*JSONSchema, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	var schema JSONSchema
+	if err := decoder.Decode(&schema); err != nil {
+		return nil, fmt.Errorf("unsupported or malformed schema: %w", err)
+	}
+	if err := schema.compile("$"); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+func (s *JSONSchema) compile(path string) error {
+	switch s.Type {
+	case "", "object", "array", "string", "number", "integer", "boolean", "null":
+	default:
+		return fmt.Errorf("%s: unknown type %q", path, s.Type)
+	}
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: pattern: %w", path, err)
+		}
+		s.pattern = pattern
+	}
+	for name, property := range s.Properties {
+		if err := property.compile(path + "." + name); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile(path + "[]")
+	}
+	return nil
+}
+
+// Check returns every way value, as decoded by encoding/json, breaks the schema; none means valid
+func (s *JSONSchema) Check(value interface{}) []string {
+	var problems []string
+	s.check("$", value, &problems)
+	return problems
+}
+
+func (s *JSONSchema) check(path string, value interface{}, problems *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if s.Type != "" && !jsonTypeMatches(s.Type, value) {
+		fail("want %s, got %s", s.Type, jsonTypeName(value))
+		return
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(allowed interface{}) bool { return jsonEqual(allowed, value) }) {
+		fail("%v is not one of %v", value, s.Enum)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(v)) {
+			if property, ok := s.Properties[name]; ok {
+				property.check(path+"."+name, v[name], problems)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				fail("unexpected property %q", name)
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.check(fmt.Sprintf("%s[%d]", path, i), item, problems)
+			}
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("%v is below the minimum %v", v, *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("%v is above the maximum %v", v, *s.Maximum)
+		}
+	case string:
+		length := len([]rune(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("%d characters, want at least %d", length, *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("%d characters, want at most %d", length, *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("%q does not match %s", v, s.Pattern)
+		}
+	}
+}
+
+func jsonTypeMatches(want string, value interface{}) bool {
+	if want == "integer" {
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	}
+	return jsonTypeName(value) == want
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// === APPEND AND READ VALIDATION ===
+
+// SchemaCheckedStore is an EventStore that validates every appended event against the registry
+// and stamps the version it was validated against into the event's schemaVersion metadata. An
+// event whose metadata already names a version is validated against that one instead, so a
+// producer can keep writing an older version while its consumers catch up.
+//
+// One invalid event fails the whole append before anything is sent. Event types without a schema
+// are appended unchecked, unless RequireSchema is set. Reads and subscriptions pass straight through.
+type SchemaCheckedStore struct {
+	EventStore
+	registry SchemaRegistry
+	// RequireSchema rejects events whose type has no schema with ErrSchemaNotFound
+	RequireSchema bool
+}
+
+func NewSchemaCheckedStore(store EventStore, registry SchemaRegistry) *SchemaCheckedStore {
+	return &SchemaCheckedStore{EventStore: store, registry: registry}
+}
+
+func (s *SchemaCheckedStore) AppendToStream(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+	checked := make([]kurrentdb.EventData, len(events))
+	for i, event := range events {
+		var err error
+		if checked[i], err = s.check(event); err != nil {
+			return nil, fmt.Errorf("%s event %d: %w", stream, i, err)
+		}
+	}
+	return s.EventStore.AppendToStream(ctx, stream, options, checked...)
+}
+
+// check validates event and returns it stamped with its schema version
+func (s *SchemaCheckedStore) check(event kurrentdb.EventData) (kurrentdb.EventData, error) {
+	var meta Meta
+	if err := meta.UnmarshalJSON(event.Metadata); err != nil {
+		return event, err
+	}
+	version := meta[MetaSchemaVersion]
+	if version == "" {
+		version = s.registry.Latest(event.EventType)
+	}
+	if version == "" {
+		if s.RequireSchema {
+			return event, fmt.Errorf("%w: no schema registered for %s", ErrSchemaNotFound, event.EventType)
+		}
+		return event, nil
+	}
+	if err := s.registry.Validate(event.EventType, version, event.Data); err != nil {
+		return event, err
+	}
+	return stampSchemaVersion(event, version)
+}
+
+// stampSchemaVersion sets schemaVersion in event's metadata, keeping the other keys as they are
+func stampSchemaVersion(event kurrentdb.EventData, version string) (kurrentdb.EventData, error) {
+	metadata := make(map[string]interface{})
+	if len(event.Metadata) > 0 {
+		if err := json.Unmarshal(event.Metadata, &metadata); err != nil {
+			return event, fmt.Errorf("%w: %v", ErrInvalidMeta, err)
+		}
+	}
+	metadata[MetaSchemaVersion] = version
+
+	stamped, err := json.Marshal(metadata)
+	if err != nil {
+		return event, err
+	}
+	event.Metadata = stamped
+	return event, nil
+}
+
+// SchemaValidationMiddleware wraps a subscription handler so events whose data no longer matches
+// the schema version stamped on them fail with ErrSchemaViolation instead of reaching handler,
+// e.g. for a dead-letter policy to park. Events without a schemaVersion were written before
+// validation started, or for types without a schema, and are passed on unchecked.
+func SchemaValidationMiddleware(registry SchemaRegistry, handler func(*kurrentdb.ResolvedEvent) error) func(*kurrentdb.ResolvedEvent) error {
+	return func(event *kurrentdb.ResolvedEvent) error {
+		recorded := Resolve(event, false)
+		var meta Meta
+		if err := meta.UnmarshalFrom(recorded); err != nil {
+			return err
+		}
+		if version := meta[MetaSchemaVersion]; version != "" {
+			if err := registry.Validate(recorded.EventType, version, recorded.Data); err != nil {
+				return fmt.Errorf("%s@%d: %w", recorded.StreamID, recorded.EventNumber, err)
+			}
+		}
+		return handler(event)
+	}
+}
+
+// === ORDER SCHEMAS ===
+
+// orderPlacedSchemaV1 and V2 are the OrderPlaced contract before and after currency was added
+const (
+	orderPlacedSchemaV1 = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "OrderPlaced v1",
+  "type": "object",
+  "required": ["orderId", "amount"],
+  "properties": {
+    "orderId": {"type": "string", "minLength": 1},
+    "amount": {"type": "number", "minimum": 0}
+  }
+}`
+	orderPlacedSchemaV2 = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "OrderPlaced v2",
+  "type": "object",
+  "required": ["orderId", "amount", "currency"],
+  "additionalProperties": false,
+  "properties": {
+    "orderId": {"type": "string", "minLength": 1},
+    "amount": {"type": "number", "minimum": 0},
+    "currency": {"type": "string", "pattern": "^[A-Z]{3}$"},
+    "lines": {"type": "array", "items": {"type": "object", "required": ["sku", "quantity"],
+      "properties": {"sku": {"type": "string"}, "quantity": {"type": "integer", "minimum": 1}}}}
+  }
+}`
+)
+
+// schemaEvent is a JSON event of eventType with data and optional metadata
+func schemaEvent(eventType, data, metadata string) kurrentdb.EventData {
+	event := kurrentdb.EventData{
+		EventID:     uuid.New(),
+		EventType:   eventType,
+		ContentType: kurrentdb.ContentTypeJson,
+		Data:        []byte(data),
+	}
+	if metadata != "" {
+		event.Metadata = []byte(metadata)
+	}
+	return event
+}
+
+// RunSchemaRegistryChecks validates schemas, appends through the registry and reads back through
+// the middleware against an in-memory store and registry
+func RunSchemaRegistryChecks() {
+	checks := newChecker("schema registry")
+
+	fmt.Println("\n--- JSON Schema subset ---")
+	v2, err := ParseJSONSchema([]byte(orderPlacedSchemaV2))
+	checks.Check(err == nil, "the v2 schema should parse: %v", err)
+	if v2 != nil {
+		for _, valid := range []string{
+			`{"orderId":"o-1","amount":10,"currency":"EUR"}`,
+			`{"orderId":"o-1","amount":0,"currency":"EUR","lines":[{"sku":"A","quantity":2}]}`,
+		} {
+			var value interface{}
+			json.Unmarshal([]byte(valid), &value)
+			checks.Check(len(v2.Check(value)) == 0, "%s should be valid, got %v", valid, v2.Check(value))
+		}
+		for data, want := range map[string]string{
+			`{"orderId":"o-1","amount":10}`:                                                       `missing required property "currency"`,
+			`{"orderId":"","amount":10,"currency":"EUR"}`:                                         `$.orderId: 0 characters`,
+			`{"orderId":"o-1","amount":-1,"currency":"EUR"}`:                                      `below the minimum`,
+			`{"orderId":"o-1","amount":"10","currency":"EUR"}`:                                    `$.amount: want number, got string`,
+			`{"orderId":"o-1","amount":10,"currency":"euro"}`:                                     `does not match`,
+			`{"orderId":"o-1","amount":10,"currency":"EUR","note":"x"}`:                           `unexpected property "note"`,
+			`{"orderId":"o-1","amount":10,"currency":"EUR","lines":[{"sku":"A"}]}`:                `$.lines[0]: missing required property "quantity"`,
+			`{"orderId":"o-1","amount":10,"currency":"EUR","lines":[{"sku":"A","quantity":1.5}]}`: `$.lines[0].quantity: want integer`,
+			`[]`: `$: want object, got array`,
+		} {
+			var value interface{}
+			json.Unmarshal([]byte(data), &value)
+			problems := strings.Join(v2.Check(value), "; ")
+			checks.Check(strings.Contains(problems, want), "%s should fail with %q, got %q", data, want, problems)
+		}
+	}
+	_, err = ParseJSONSchema([]byte(`{"type":"object","oneOf":[{"required":["a"]}]}`))
+	checks.Check(err != nil, "a keyword outside the subset should be rejected, not ignored")
+	_, err = ParseJSONSchema([]byte(`{"type":"object","properties":{"a":{"type":"text"}}}`))
+	checks.Check(err != nil && strings.Contains(err.Error(), "$.a"), "an unknown type should be rejected with its path, got %v", err)
+
+	ctx := context.Background()
+	source := NewMemorySchemaSource().
+		Register("OrderPlaced", "1", orderPlacedSchemaV1).
+		Register("OrderPlaced", "2", orderPlacedSchemaV2)
+	registry := NewCachingSchemaRegistry(source)
+	memory := NewMemoryEventStore()
+	store := NewSchemaCheckedStore(memory, registry)
+
+	fmt.Println("\n--- Append validation ---")
+	_, err = store.AppendToStream(ctx, "order-1", kurrentdb.AppendToStreamOptions{},
+		schemaEvent("OrderPlaced", `{"orderId":"1","amount":10,"currency":"EUR"}`, `{"producer":"checkout","retries":2}`))
+	checks.Check(err == nil, "a valid event should be appended: %v", err)
+	stored, _, _ := readStreamFrom(ctx, memory, "order-1", NoVersion)
+	var meta Meta
+	if len(stored) == 1 {
+		meta.UnmarshalFrom(stored[0])
+		fmt.Printf("  appended with metadata %s\n", stored[0].UserMetadata)
+	}
+	version, versionErr := meta.SchemaVersion()
+	checks.Check(version == 2 && versionErr == nil, "the latest version should be stamped, got %d (%v)", version, versionErr)
+	checks.Check(meta["producer"] == "checkout" && meta["retries"] == "2", "stamping should keep the other metadata, got %v", meta)
+
+	_, err = store.AppendToStream(ctx, "order-1", kurrentdb.AppendToStreamOptions{},
+		schemaEvent("OrderPlaced", `{"orderId":"2","amount":20,"currency":"EUR"}`, ""),
+		schemaEvent("OrderPlaced", `{"orderId":"3","amount":-5}`, ""))
+	fmt.Printf("  %v\n", err)
+	checks.Check(errors.Is(err, ErrSchemaViolation), "an invalid event should fail with ErrSchemaViolation, got %v", err)
+	checks.Check(err != nil && strings.Contains(err.Error(), "event 1") && strings.Contains(err.Error(), "currency"), "the error should name the event and the problem, got %v", err)
+	stored, _, _ = readStreamFrom(ctx, memory, "order-1", NoVersion)
+	checks.Check(len(stored) == 1, "nothing of a batch with an invalid event should be appended, stream has %d", len(stored))
+
+	// A producer pinned to v1 can still omit the currency
+	_, err = store.AppendToStream(ctx, "order-1", kurrentdb.AppendToStreamOptions{},
+		schemaEvent("OrderPlaced", `{"orderId":"4","amount":40}`, `{"schemaVersion":"1"}`))
+	checks.Check(err == nil, "an event pinned to v1 should validate against v1: %v", err)
+	_, err = store.AppendToStream(ctx, "order-1", kurrentdb.AppendToStreamOptions{},
+		schemaEvent("OrderPlaced", `{"orderId":"5","amount":50}`, `{"schemaVersion":"7"}`))
+	checks.Check(errors.Is(err, ErrSchemaNotFound), "an unknown pinned version should fail with ErrSchemaNotFound, got %v", err)
+
+	_, err = store.AppendToStream(ctx, "order-1", kurrentdb.AppendToStreamOptions{}, schemaEvent("OrderNoted", `{"anything":true}`, ""))
+	checks.Check(err == nil, "a type without a schema should pass by default: %v", err)
+	stored, _, _ = readStreamFrom(ctx, memory, "order-1", NoVersion)
+	checks.Check(len(stored) == 3 && len(stored[2].UserMetadata) == 0, "an unchecked event should not be stamped")
+	store.RequireSchema = true
+	_, err = store.AppendToStream(ctx, "order-1", kurrentdb.AppendToStreamOptions{}, schemaEvent("OrderNoted", `{}`, ""))
+	checks.Check(errors.Is(err, ErrSchemaNotFound), "RequireSchema should reject a type without a schema, got %v", err)
+	store.RequireSchema = false
+
+	fmt.Println("\n--- Caching ---")
+	cachedSource := NewMemorySchemaSource().Register("OrderPlaced", "1", orderPlacedSchemaV1)
+	cached := NewCachingSchemaRegistry(cachedSource)
+	clock := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	cached.now = func() time.Time { return clock }
+	cachedStore := NewSchemaCheckedStore(NewMemoryEventStore(), cached)
+	for i := 0; i < 100; i++ {
+		cachedStore.AppendToStream(ctx, "order-cached", kurrentdb.AppendToStreamOptions{},
+			schemaEvent("OrderPlaced", fmt.Sprintf(`{"orderId":"%d","amount":%d}`, i, i), ""),
+			schemaEvent("OrderNoted", `{}`, ""))
+	}
+	fmt.Printf("  200 events appended with %d registry lookups\n", cachedSource.Lookups())
+	checks.Check(cachedSource.Lookups() == 3, "100 appends should take one schema and two latest lookups, took %d", cachedSource.Lookups())
+
+	cachedSource.Register("OrderPlaced", "2", orderPlacedSchemaV2)
+	checks.Check(cached.Latest("OrderPlaced") == "1", "a new version should not be seen within LatestTTL")
+	clock = clock.Add(cached.LatestTTL)
+	checks.Check(cached.Latest("OrderPlaced") == "2", "a new version should be picked up once LatestTTL has passed")
+
+	fmt.Println("\n--- Read validation ---")
+	// Events written around the registry: one stamped but invalid, one from before stamping
+	memory.AppendToStream(ctx, "order-2", kurrentdb.AppendToStreamOptions{},
+		schemaEvent("OrderPlaced", `{"orderId":"6","amount":60,"currency":"EUR"}`, `{"schemaVersion":"2"}`),
+		schemaEvent("OrderPlaced", `{"orderId":"7","amount":"seventy","currency":"EUR"}`, `{"schemaVersion":"2"}`),
+		schemaEvent("OrderPlaced", `{"orderId":"8"}`, ""))
+	var handled []string
+	handler := SchemaValidationMiddleware(registry, func(event *kurrentdb.ResolvedEvent) error {
+		var data struct{ OrderID string }
+		json.Unmarshal(event.Event.Data, &data)
+		handled = append(handled, data.OrderID)
+		return nil
+	})
+	var rejected []error
+	events, _, _ := readStreamFrom(ctx, memory, "order-2", NoVersion)
+	for _, event := range events {
+		if err := handler(&kurrentdb.ResolvedEvent{Event: event}); err != nil {
+			rejected = append(rejected, err)
+			fmt.Printf("  rejected: %v\n", err)
+		}
+	}
+	checks.Check(fmt.Sprint(handled) == "[6 8]", "the valid and the unstamped event should reach the handler, got %v", handled)
+	checks.Check(len(rejected) == 1 && errors.Is(rejected[0], ErrSchemaViolation) && strings.Contains(rejected[0].Error(), "order-2@1"),
+		"the invalid event should be rejected with its position, got %v", rejected)
+
+	checks.Done()
+}
+
+// RunSchemaRegistry appends through the registry to a server: a valid order is stamped with its
+// schema version, and one that violates the schema never leaves the client
+func RunSchemaRegistry() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	source := NewMemorySchemaSource().
+		Register("OrderPlaced", "1", orderPlacedSchemaV1).
+		Register("OrderPlaced", "2", orderPlacedSchemaV2)
+	store := NewSchemaCheckedStore(NewClientStore(client), NewCachingSchemaRegistry(source))
+	stream := Streams.Name("order", uuid.New().String())
+
+	_, validErr := store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{},
+		schemaEvent("OrderPlaced", `{"orderId":"1","amount":99.5,"currency":"EUR"}`, ""))
+	fmt.Printf("Valid order: %v\n", validErr)
+
+	_, invalidErr := store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{},
+		schemaEvent("OrderPlaced", `{"orderId":"2","amount":10,"currency":"euros"}`, ""))
+	fmt.Printf("Invalid order: %v\n", invalidErr)
+
+	stored, _, readErr := readStreamFrom(ctx, store, stream, NoVersion)
+	var meta Meta
+	if len(stored) > 0 {
+		meta.UnmarshalFrom(stored[0])
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+	if validErr != nil {
+		fmt.Printf("FAIL: The valid order should be appended, got %v\n", validErr)
+		passed = false
+	}
+	if !errors.Is(invalidErr, ErrSchemaViolation) || !strings.Contains(invalidErr.Error(), "currency") {
+		fmt.Printf("FAIL: The invalid order should fail with ErrSchemaViolation naming the currency, got %v\n", invalidErr)
+		passed = false
+	}
+	if readErr != nil || len(stored) != 1 {
+		fmt.Printf("FAIL: Only the valid order should be stored, got %d events (%v)\n", len(stored), readErr)
+		passed = false
+	}
+	if version, err := meta.SchemaVersion(); version != 2 || err != nil {
+		fmt.Printf("FAIL: The stored order should be stamped with schemaVersion 2, got %d (%v)\n", version, err)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll schema registry tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
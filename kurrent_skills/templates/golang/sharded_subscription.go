@@ -0,0 +1,738 @@
+// KurrentDB Go Client Example - Sharded catch-up subscriptions
+// Demonstrates: Splitting $all across instances by a hash of the stream id, each with its own checkpoint
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === SHARD LAYOUT ===
+
+// ShardLayout splits streams into Partitions by a hash of the stream id and deals the partitions
+// out round-robin to Instances, of which this process is number Instance, counting from 0. Every
+// instance subscribes to all of $all and handles only the streams it owns, so each stream is
+// handled by exactly one instance, in order, and no coordination is needed at runtime.
+//
+// Partitions is fixed for the life of the deployment; pick it larger than the most instances you
+// expect to run. Instances may change, but only by the restart described on ErrShardLayoutChanged.
+type ShardLayout struct {
+	Partitions int `json:"partitions"`
+	Instances  int `json:"instances"`
+	Instance   int `json:"instance"`
+}
+
+// Validate reports a layout that would leave partitions or instances without an owner or work
+func (l ShardLayout) Validate() error {
+	switch {
+	case l.Instances < 1:
+		return fmt.Errorf("shard layout needs at least 1 instance, got %d", l.Instances)
+	case l.Partitions < l.Instances:
+		return fmt.Errorf("shard layout needs at least as many partitions as instances, got %d for %d", l.Partitions, l.Instances)
+	case l.Instance < 0 || l.Instance >= l.Instances:
+		return fmt.Errorf("shard instance must be in [0, %d), got %d", l.Instances, l.Instance)
+	}
+	return nil
+}
+
+// Partition is the partition streamID hashes to; it depends only on the id and Partitions
+func (l ShardLayout) Partition(streamID string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(streamID))
+	return int(hash.Sum32() % uint32(l.Partitions))
+}
+
+// Owner is the instance that owns partition
+func (l ShardLayout) Owner(partition int) int {
+	return partition % l.Instances
+}
+
+// Owns reports whether this instance handles streamID
+func (l ShardLayout) Owns(streamID string) bool {
+	return l.Owner(l.Partition(streamID)) == l.Instance
+}
+
+// Owned lists the partitions this instance handles
+func (l ShardLayout) Owned() []int {
+	var owned []int
+	for partition := l.Instance; partition < l.Partitions; partition += l.Instances {
+		owned = append(owned, partition)
+	}
+	return owned
+}
+
+func (l ShardLayout) String() string {
+	return fmt.Sprintf("instance %d/%d of %d partitions", l.Instance, l.Instances, l.Partitions)
+}
+
+// sameShards reports whether l and other deal streams out identically, whichever instance they are
+func (l ShardLayout) sameShards(other ShardLayout) bool {
+	return l.Partitions == other.Partitions && l.Instances == other.Instances
+}
+
+// === SHARD CHECKPOINTS ===
+
+// ErrShardLayoutChanged is returned when an instance's checkpoint was saved under a different
+// layout. Changing Instances moves partitions between instances, and an instance's checkpoint only
+// vouches for the streams it owned, so resuming would skip the events of every stream that moved to
+// an instance whose checkpoint is further ahead. To change the instance count:
+//
+//  1. Stop every instance.
+//  2. Take the lowest of their checkpoints with ReshardPosition.
+//  3. Save it for each instance of the new layout with ShardCheckpointStore.Save.
+//  4. Start the new instances.
+//
+// Each instance then replays the events between the lowest checkpoint and its streams' previous
+// owner's checkpoint, so handlers must tolerate seeing those again, e.g. by tracking the last
+// revision applied per stream. Read models held in memory per instance are rebuilt from the
+// start instead: delete the checkpoints and start the new instances.
+var ErrShardLayoutChanged = errors.New("shard layout changed")
+
+// ShardCheckpoint is an instance's position in $all and the layout it was reached under
+type ShardCheckpoint struct {
+	Layout   ShardLayout        `json:"layout"`
+	Position kurrentdb.Position `json:"position"`
+}
+
+// ShardCheckpointStore persists one instance's ShardCheckpoint
+type ShardCheckpointStore interface {
+	// Load returns the stored checkpoint, or nil if nothing has been saved yet
+	Load() (*ShardCheckpoint, error)
+	Save(checkpoint ShardCheckpoint) error
+}
+
+// FileShardCheckpoint keeps a ShardCheckpoint in a JSON file, replaced atomically on every save
+type FileShardCheckpoint struct {
+	Path string
+}
+
+func (c FileShardCheckpoint) Load() (*ShardCheckpoint, error) {
+	data, err := os.ReadFile(c.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoint ShardCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("corrupt shard checkpoint %s: %w", c.Path, err)
+	}
+	return &checkpoint, nil
+}
+
+func (c FileShardCheckpoint) Save(checkpoint ShardCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	tmp := c.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.Path)
+}
+
+// ReshardPosition returns the lowest checkpoint of a stopped deployment, where every instance of a
+// new layout starts. It is nil, meaning the start of $all, if any instance never saved one.
+func ReshardPosition(stores ...ShardCheckpointStore) (*kurrentdb.Position, error) {
+	var lowest *kurrentdb.Position
+	for i, store := range stores {
+		checkpoint, err := store.Load()
+		if err != nil {
+			return nil, fmt.Errorf("load checkpoint %d: %w", i, err)
+		}
+		if checkpoint == nil {
+			return nil, nil
+		}
+		if lowest == nil || positionAfter(*lowest, checkpoint.Position) {
+			lowest = &checkpoint.Position
+		}
+	}
+	return lowest, nil
+}
+
+// === SHARDED SUBSCRIPTION ===
+
+// ShardedSubscriptionOptions configures one instance
+type ShardedSubscriptionOptions struct {
+	// CheckpointEvery saves the checkpoint after this many events, handled or not; defaults to 100
+	CheckpointEvery int
+	// From is where an instance without a checkpoint starts; defaults to the start of $all
+	From kurrentdb.AllPosition
+	// Filter narrows the subscription every instance shares, e.g. to the categories handled
+	Filter *kurrentdb.SubscriptionFilter
+}
+
+// ShardedSubscription is one instance of a catch-up subscription to $all that only hands its
+// handler the events of streams its layout owns. Events of other streams are skipped but still
+// move the checkpoint, so an instance restarts where it stopped rather than where it last handled
+// an event.
+type ShardedSubscription struct {
+	store       EventStore
+	layout      ShardLayout
+	checkpoints ShardCheckpointStore
+	handler     func(ctx context.Context, event *kurrentdb.ResolvedEvent) error
+	options     ShardedSubscriptionOptions
+
+	reconnect Backoff
+
+	mu       sync.Mutex
+	position *kurrentdb.Position
+	handled  int
+	skipped  int
+}
+
+// NewShardedSubscription validates layout; handler receives the owned events in $all order
+func NewShardedSubscription(store EventStore, layout ShardLayout, checkpoints ShardCheckpointStore, handler func(ctx context.Context, event *kurrentdb.ResolvedEvent) error, options ShardedSubscriptionOptions) (*ShardedSubscription, error) {
+	if err := layout.Validate(); err != nil {
+		return nil, err
+	}
+	if options.CheckpointEvery <= 0 {
+		options.CheckpointEvery = 100
+	}
+	if options.From == nil {
+		options.From = kurrentdb.Start{}
+	}
+	return &ShardedSubscription{
+		store:       store,
+		layout:      layout,
+		checkpoints: checkpoints,
+		handler:     handler,
+		options:     options,
+		reconnect:   NewBackoff(time.Second, 30*time.Second),
+	}, nil
+}
+
+// Position is the last position this instance handled or skipped, or nil before the first event
+func (s *ShardedSubscription) Position() *kurrentdb.Position {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.position
+}
+
+// Counts returns how many events were handled and skipped as another instance's since Run began
+func (s *ShardedSubscription) Counts() (handled, skipped int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.handled, s.skipped
+}
+
+// Run resumes from the checkpoint and handles owned events until ctx is cancelled or the handler
+// fails, reconnecting when the subscription drops. It saves the checkpoint before returning; a
+// failed event is not included, so it is retried on the next Run. A checkpoint saved under other
+// partition or instance counts stops Run with ErrShardLayoutChanged before anything is read.
+func (s *ShardedSubscription) Run(ctx context.Context) error {
+	checkpoint, err := s.checkpoints.Load()
+	if err != nil {
+		return fmt.Errorf("load checkpoint of %s: %w", s.layout, err)
+	}
+	if checkpoint != nil && !checkpoint.Layout.sameShards(s.layout) {
+		return fmt.Errorf("%w: checkpoint of %s was saved as %s", ErrShardLayoutChanged, s.layout, checkpoint.Layout)
+	}
+
+	s.mu.Lock()
+	s.position, s.handled, s.skipped = nil, 0, 0
+	if checkpoint != nil {
+		s.position = &checkpoint.Position
+	}
+	s.mu.Unlock()
+
+	reconnect := s.reconnect
+	for {
+		subscribed := time.Now()
+		err := s.subscribe(ctx)
+		saveErr := s.save()
+		var handlerErr *shardHandlerError
+		if errors.As(err, &handlerErr) {
+			return errors.Join(handlerErr.err, saveErr)
+		}
+		if ctx.Err() != nil || saveErr != nil {
+			return saveErr
+		}
+		fmt.Printf("  [%s] subscription dropped, reconnecting: %v\n", s.layout, err)
+
+		if time.Since(subscribed) > reconnect.Max {
+			reconnect.Reset()
+		}
+		if reconnect.Wait(ctx) != nil {
+			return nil
+		}
+	}
+}
+
+// shardHandlerError marks a handler failure, which stops Run rather than reconnecting
+type shardHandlerError struct {
+	err error
+}
+
+func (e *shardHandlerError) Error() string {
+	return e.err.Error()
+}
+
+func (s *ShardedSubscription) subscribe(ctx context.Context) error {
+	from := s.options.From
+	if position := s.Position(); position != nil {
+		from = *position
+	}
+	subscription, err := s.store.SubscribeToAll(ctx, kurrentdb.SubscribeToAllOptions{From: from, Filter: s.options.Filter})
+	if err != nil {
+		return err
+	}
+	defer subscription.Close()
+
+	unsaved := 0
+	for {
+		message := subscription.Recv()
+		if message.SubscriptionDropped != nil {
+			if message.SubscriptionDropped.Error == nil {
+				return errors.New("subscription dropped")
+			}
+			return message.SubscriptionDropped.Error
+		}
+		var position kurrentdb.Position
+		switch {
+		case message.EventAppeared != nil:
+			recorded := Resolve(message.EventAppeared, false)
+			position = Resolve(message.EventAppeared, true).Position
+			owned := s.layout.Owns(recorded.StreamID)
+			if owned {
+				if err := s.handler(ctx, message.EventAppeared); err != nil {
+					return &shardHandlerError{err: fmt.Errorf("%s: handle %s@%d: %w", s.layout, recorded.StreamID, recorded.EventNumber, err)}
+				}
+			}
+			s.mu.Lock()
+			if owned {
+				s.handled++
+			} else {
+				s.skipped++
+			}
+			s.mu.Unlock()
+		case message.CheckPointReached != nil:
+			position = *message.CheckPointReached
+		default:
+			continue
+		}
+
+		s.mu.Lock()
+		s.position = &position
+		s.mu.Unlock()
+		if unsaved++; unsaved >= s.options.CheckpointEvery {
+			if err := s.save(); err != nil {
+				return err
+			}
+			unsaved = 0
+		}
+	}
+}
+
+// save stores the current position under this instance's layout
+func (s *ShardedSubscription) save() error {
+	position := s.Position()
+	if position == nil {
+		return nil
+	}
+	if err := s.checkpoints.Save(ShardCheckpoint{Layout: s.layout, Position: *position}); err != nil {
+		return fmt.Errorf("save checkpoint of %s: %w", s.layout, err)
+	}
+	return nil
+}
+
+// RunShardedSubscription splits a run's order streams between two instances subscribed to $all
+func RunShardedSubscription() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+	store := NewClientStore(client)
+
+	run := uuid.New().String()[:8]
+	prefix := Streams.Name("order", run)
+	var streams []string
+	var end kurrentdb.Position
+	for i := 0; i < 10; i++ {
+		stream := fmt.Sprintf("%s-%d", prefix, i)
+		streams = append(streams, stream)
+		for _, eventType := range []string{"OrderPlaced", "OrderShipped"} {
+			result, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+				EventID:     uuid.New(),
+				EventType:   eventType,
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        []byte(`{}`),
+			})
+			if err != nil {
+				panic(err)
+			}
+			end = kurrentdb.Position{Commit: result.CommitPosition, Prepare: result.PreparePosition}
+		}
+	}
+	fmt.Printf("Appended 2 events to each of %d streams under %s\n", len(streams), prefix)
+
+	dir, err := os.MkdirTemp("", "shards-")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Both instances share the filter; each owns half of the 8 partitions
+	recorder := newShardRecorder()
+	var instances []*ShardedSubscription
+	for i := 0; i < 2; i++ {
+		layout := ShardLayout{Partitions: 8, Instances: 2, Instance: i}
+		instance, err := NewShardedSubscription(store, layout, FileShardCheckpoint{Path: filepath.Join(dir, fmt.Sprintf("instance-%d.json", i))},
+			recorder.handler(fmt.Sprint(i)), ShardedSubscriptionOptions{
+				Filter: &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{prefix + "-"}},
+			})
+		if err != nil {
+			panic(err)
+		}
+		instances = append(instances, instance)
+		fmt.Printf("  %s owns partitions %v\n", layout, layout.Owned())
+	}
+	runErr := runShards(ctx, end, instances...)
+
+	owner := make(map[string]string)
+	handled := 0
+	overlap := false
+	for _, instance := range []string{"0", "1"} {
+		events := recorder.events(instance)
+		fmt.Printf("  instance %s handled %d events from %d streams\n", instance, len(events), countStreams(events))
+		for _, event := range events {
+			handled++
+			if previous, ok := owner[event.StreamID]; ok && previous != instance {
+				overlap = true
+			}
+			owner[event.StreamID] = instance
+		}
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+	if runErr != nil {
+		fmt.Printf("FAIL: Both instances should reach the last append: %v\n", runErr)
+		passed = false
+	}
+	if handled != 2*len(streams) {
+		fmt.Printf("FAIL: Every event should be handled exactly once, got %d of %d\n", handled, 2*len(streams))
+		passed = false
+	}
+	if overlap {
+		fmt.Println("FAIL: No stream should be handled by both instances")
+		passed = false
+	}
+	for _, stream := range streams {
+		want := fmt.Sprint(instances[0].layout.Owner(instances[0].layout.Partition(stream)))
+		if owner[stream] != want {
+			fmt.Printf("FAIL: %s should be handled by instance %s, got %q\n", stream, want, owner[stream])
+			passed = false
+		}
+	}
+	for i := range instances {
+		checkpoint, err := FileShardCheckpoint{Path: filepath.Join(dir, fmt.Sprintf("instance-%d.json", i))}.Load()
+		if err != nil || checkpoint == nil || positionAfter(end, checkpoint.Position) {
+			fmt.Printf("FAIL: Instance %d should have checkpointed through the last append, got %+v (%v)\n", i, checkpoint, err)
+			passed = false
+		}
+	}
+
+	if passed {
+		fmt.Println("\nAll sharded subscription tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
+
+// === CHECKS ===
+
+// shardRecorder collects what each instance handled, for the checks and demo
+type shardRecorder struct {
+	mu      sync.Mutex
+	handled map[string][]*kurrentdb.RecordedEvent
+}
+
+func newShardRecorder() *shardRecorder {
+	return &shardRecorder{handled: make(map[string][]*kurrentdb.RecordedEvent)}
+}
+
+func (r *shardRecorder) handler(instance string) func(ctx context.Context, event *kurrentdb.ResolvedEvent) error {
+	return func(ctx context.Context, event *kurrentdb.ResolvedEvent) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.handled[instance] = append(r.handled[instance], Resolve(event, false))
+		return nil
+	}
+}
+
+// events returns a copy of what instance handled
+func (r *shardRecorder) events(instance string) []*kurrentdb.RecordedEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.handled[instance])
+}
+
+// runShards runs every instance until each has reached through, then stops them all and returns
+// the first error Run returned
+func runShards(ctx context.Context, through kurrentdb.Position, instances ...*ShardedSubscription) error {
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	errs := make(chan error, len(instances))
+	for _, instance := range instances {
+		go func() { errs <- instance.Run(runCtx) }()
+	}
+
+	deadline := time.After(10 * time.Second)
+	for _, instance := range instances {
+		for {
+			if position := instance.Position(); position != nil && !positionAfter(through, *position) {
+				break
+			}
+			select {
+			case err := <-errs:
+				if err == nil {
+					err = errors.New("stopped early")
+				}
+				return fmt.Errorf("%s: %w", instance.layout, err)
+			case <-deadline:
+				return fmt.Errorf("%s did not reach %d/%d", instance.layout, through.Commit, through.Prepare)
+			case <-time.After(5 * time.Millisecond):
+			}
+		}
+	}
+
+	stop()
+	var first error
+	for range instances {
+		if err := <-errs; err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// RunShardedSubscriptionChecks splits an in-memory $all between instances and verifies that every
+// event is handled exactly once, by the same instance on every run, without a server
+func RunShardedSubscriptionChecks() {
+	checks := newChecker("sharded subscription")
+
+	fmt.Println("\n--- Layout ---")
+	for _, invalid := range []ShardLayout{
+		{Partitions: 8, Instances: 0, Instance: 0},
+		{Partitions: 2, Instances: 3, Instance: 0},
+		{Partitions: 8, Instances: 2, Instance: 2},
+		{Partitions: 8, Instances: 2, Instance: -1},
+	} {
+		checks.Check(invalid.Validate() != nil, "%+v should be rejected", invalid)
+	}
+
+	layouts := []ShardLayout{{Partitions: 8, Instances: 2, Instance: 0}, {Partitions: 8, Instances: 2, Instance: 1}}
+	for _, layout := range layouts {
+		checks.Check(layout.Validate() == nil, "%s should be valid", layout)
+		fmt.Printf("  %s owns partitions %v\n", layout, layout.Owned())
+	}
+	checks.Check(fmt.Sprint(layouts[0].Owned()) == "[0 2 4 6]" && fmt.Sprint(layouts[1].Owned()) == "[1 3 5 7]",
+		"Partitions should be dealt out round-robin, got %v and %v", layouts[0].Owned(), layouts[1].Owned())
+
+	seen := make(map[int]int)
+	for i := 0; i < 1000; i++ {
+		streamID := fmt.Sprintf("order-%d", i)
+		partition := layouts[0].Partition(streamID)
+		seen[partition]++
+		checks.Check(partition == layouts[1].Partition(streamID), "Every instance should hash %s to the same partition", streamID)
+		checks.Check(layouts[0].Owns(streamID) != layouts[1].Owns(streamID), "Exactly one instance should own %s", streamID)
+	}
+	checks.Check(len(seen) == 8, "1000 streams should cover all 8 partitions, got %d", len(seen))
+	for partition, count := range seen {
+		checks.Check(count > 60, "Partition %d should get a fair share of 1000 streams, got %d", partition, count)
+	}
+
+	// === Two instances over one store ===
+	ctx := context.Background()
+	store := NewMemoryEventStore()
+	var streams []string
+	for i := 0; i < 24; i++ {
+		streams = append(streams, Streams.Name("order", fmt.Sprintf("shard-%02d", i)))
+	}
+	appendRound := func(round int) kurrentdb.Position {
+		var last kurrentdb.Position
+		for _, stream := range streams {
+			result, err := store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+				EventID:     uuid.New(),
+				EventType:   "OrderUpdated",
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        []byte(fmt.Sprintf(`{"round":%d}`, round)),
+			})
+			if err != nil {
+				panic(err)
+			}
+			last = kurrentdb.Position{Commit: result.CommitPosition, Prepare: result.PreparePosition}
+		}
+		return last
+	}
+	for round := 0; round < 2; round++ {
+		appendRound(round)
+	}
+	end := appendRound(2)
+	total := 3 * len(streams)
+
+	dir, err := os.MkdirTemp("", "shards-")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// run starts a fresh pair of instances with checkpoints in their own directory
+	run := func(name string, through kurrentdb.Position, layouts []ShardLayout) (*shardRecorder, []*ShardedSubscription, error) {
+		recorder := newShardRecorder()
+		var instances []*ShardedSubscription
+		for _, layout := range layouts {
+			checkpoints := FileShardCheckpoint{Path: filepath.Join(dir, fmt.Sprintf("%s-%d.json", name, layout.Instance))}
+			instance, err := NewShardedSubscription(store, layout, checkpoints, recorder.handler(fmt.Sprint(layout.Instance)), ShardedSubscriptionOptions{CheckpointEvery: 10})
+			if err != nil {
+				panic(err)
+			}
+			instances = append(instances, instance)
+		}
+		return recorder, instances, runShards(ctx, through, instances...)
+	}
+
+	fmt.Println("\n--- Two instances split the load ---")
+	recorder, instances, err := run("first", end, layouts)
+	checks.Check(err == nil, "Both instances should run to the end: %v", err)
+
+	owner := make(map[string]string)
+	handledIDs := make(map[uuid.UUID]string)
+	for _, instance := range []string{"0", "1"} {
+		events := recorder.events(instance)
+		fmt.Printf("  instance %s handled %d events from %d streams\n", instance, len(events), countStreams(events))
+		for _, event := range events {
+			if other, dup := handledIDs[event.EventID]; dup {
+				checks.Check(false, "%s@%d handled by instances %s and %s", event.StreamID, event.EventNumber, other, instance)
+			}
+			handledIDs[event.EventID] = instance
+			if previous, ok := owner[event.StreamID]; ok && previous != instance {
+				checks.Check(false, "%s split between instances %s and %s", event.StreamID, previous, instance)
+			}
+			owner[event.StreamID] = instance
+		}
+	}
+	checks.Check(len(handledIDs) == total, "Every one of the %d events should be handled exactly once, got %d", total, len(handledIDs))
+	checks.Check(len(owner) == len(streams), "Every stream should have an owner, got %d of %d", len(owner), len(streams))
+	for i, instance := range instances {
+		handled, skipped := instance.Counts()
+		checks.Check(handled+skipped == total, "Instance %d should see every event, handling or skipping it, got %d+%d", i, handled, skipped)
+		checks.Check(handled > 0, "Instance %d should own some of %d streams", i, len(streams))
+	}
+	for _, instance := range []string{"0", "1"} {
+		revisions := make(map[string]uint64)
+		for _, event := range recorder.events(instance) {
+			if last, ok := revisions[event.StreamID]; ok {
+				checks.Check(event.EventNumber == last+1, "Instance %s should handle %s in order, got %d after %d", instance, event.StreamID, event.EventNumber, last)
+			}
+			revisions[event.StreamID] = event.EventNumber
+		}
+	}
+
+	fmt.Println("\n--- Deterministic assignment ---")
+	again, _, err := run("second", end, layouts)
+	checks.Check(err == nil, "A second pair should run to the end: %v", err)
+	for _, instance := range []string{"0", "1"} {
+		checks.Check(countStreams(again.events(instance)) == countStreams(recorder.events(instance)), "Instance %s should own the same streams on every run", instance)
+		for _, event := range again.events(instance) {
+			checks.Check(owner[event.StreamID] == instance, "%s should go to instance %s on every run, went to %s", event.StreamID, owner[event.StreamID], instance)
+		}
+	}
+	fmt.Println("  a fresh pair assigned every stream to the same instance")
+
+	fmt.Println("\n--- Resume from checkpoints ---")
+	end = appendRound(3)
+	resumed, instances, err := run("first", end, layouts)
+	checks.Check(err == nil, "Restarted instances should run to the end: %v", err)
+	resumedCount := 0
+	for _, instance := range []string{"0", "1"} {
+		for _, event := range resumed.events(instance) {
+			resumedCount++
+			checks.Check(event.EventNumber == 3, "A restarted instance should only handle new events, got %s@%d", event.StreamID, event.EventNumber)
+			checks.Check(owner[event.StreamID] == instance, "%s should stay with instance %s after a restart", event.StreamID, owner[event.StreamID])
+		}
+	}
+	checks.Check(resumedCount == len(streams), "Restarted instances should handle the %d new events once each, got %d", len(streams), resumedCount)
+	fmt.Printf("  restarted instances handled only the %d new events\n", resumedCount)
+
+	fmt.Println("\n--- Instance count change ---")
+	grown := ShardLayout{Partitions: 8, Instances: 3, Instance: 0}
+	stale, err := NewShardedSubscription(store, grown, FileShardCheckpoint{Path: filepath.Join(dir, "first-0.json")}, newShardRecorder().handler("0"), ShardedSubscriptionOptions{})
+	if err != nil {
+		panic(err)
+	}
+	err = stale.Run(ctx)
+	fmt.Printf("  %v\n", err)
+	checks.Check(errors.Is(err, ErrShardLayoutChanged), "Resuming under a new instance count should fail with ErrShardLayoutChanged, got %v", err)
+	checks.Check(stale.Position() == nil, "A refused instance should not read anything")
+
+	// The documented restart: stop everything, start all new instances from the lowest checkpoint
+	end = appendRound(4)
+	lowest, err := ReshardPosition(FileShardCheckpoint{Path: filepath.Join(dir, "first-0.json")}, FileShardCheckpoint{Path: filepath.Join(dir, "first-1.json")})
+	checks.Check(err == nil && lowest != nil, "Both old checkpoints should load: %v", err)
+	var regrown []ShardLayout
+	for i := 0; i < 3; i++ {
+		layout := ShardLayout{Partitions: 8, Instances: 3, Instance: i}
+		regrown = append(regrown, layout)
+		if lowest != nil {
+			if err := (FileShardCheckpoint{Path: filepath.Join(dir, fmt.Sprintf("third-%d.json", i))}).Save(ShardCheckpoint{Layout: layout, Position: *lowest}); err != nil {
+				panic(err)
+			}
+		}
+	}
+	resharded, _, err := run("third", end, regrown)
+	checks.Check(err == nil, "Three resharded instances should run to the end: %v", err)
+	reshardedIDs := make(map[uuid.UUID]bool)
+	for _, instance := range []string{"0", "1", "2"} {
+		events := resharded.events(instance)
+		fmt.Printf("  instance %s of 3 handled %d events from %d streams\n", instance, len(events), countStreams(events))
+		checks.Check(len(events) > 0, "Instance %s of 3 should own some streams", instance)
+		for _, event := range events {
+			checks.Check(!reshardedIDs[event.EventID], "%s@%d handled twice after resharding", event.StreamID, event.EventNumber)
+			reshardedIDs[event.EventID] = true
+		}
+	}
+	newEvents := 0
+	for _, instance := range []string{"0", "1", "2"} {
+		for _, event := range resharded.events(instance) {
+			if event.EventNumber == 4 {
+				newEvents++
+			}
+		}
+	}
+	checks.Check(newEvents == len(streams), "Every event appended while resharding should be handled once, got %d of %d", newEvents, len(streams))
+
+	checks.Done()
+}
+
+// countStreams counts the distinct streams among events
+func countStreams(events []*kurrentdb.RecordedEvent) int {
+	streams := make(map[string]bool)
+	for _, event := range events {
+		streams[event.StreamID] = true
+	}
+	return len(streams)
+}
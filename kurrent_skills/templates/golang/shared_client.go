@@ -0,0 +1,289 @@
+// KurrentDB Go Client Example - Sharing one client across producers and subscribers
+// Demonstrates: Concurrent appends, reads and subscriptions on one client, and the cost of a client per goroutine
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === CONCURRENCY ===
+//
+// A *kurrentdb.Client is safe for concurrent use. It holds one gRPC connection to the node it
+// selected, and gRPC multiplexes every call over it as HTTP/2 streams: appends, reads and
+// long-lived subscriptions from any number of goroutines share the connection without blocking
+// each other. Create one client per process at startup, pass it to everything that needs it, and
+// close it at shutdown.
+//
+// A client per goroutine buys nothing: each dials, and with discovery or gossip first asks the
+// cluster for a node, before its first call, and each keeps its own connection open.
+//
+// Closing the client ends every user. Subscriptions receive a SubscriptionDropped, reads in
+// progress fail on their next Recv, and calls made after Close return an error, so goroutines
+// that treat those errors as the end of their work stop on their own.
+
+// RunSharedClient runs producers, readers and subscribers concurrently on one client, compares
+// appends on a shared client against a client per goroutine, then closes a shared client under load
+func RunSharedClient() {
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	const producers, perProducer, readers, subscribers = 8, 50, 4, 4
+	newEvent := func(producer, seq int) kurrentdb.EventData {
+		data, _ := json.Marshal(map[string]int{"producer": producer, "seq": seq})
+		return kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   "SensorReading",
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        data,
+		}
+	}
+
+	// === MIXED WORKLOAD ===
+	fmt.Printf("\n=== %d producers, %d readers, %d subscribers on one client ===\n", producers, readers, subscribers)
+	// A unique prefix keeps this run's streams apart
+	prefix := "sensor" + uuid.New().String()[:8]
+	streams := make([]string, producers)
+	for i := range streams {
+		streams[i] = Streams.Name(prefix, fmt.Sprint(i))
+	}
+
+	var workers sync.WaitGroup
+	var failures atomic.Int64
+	fail := func(who string, err error) {
+		failures.Add(1)
+		fmt.Printf("  %s: %v\n", who, err)
+	}
+
+	// Subscribers count this run's events from the head until they have all of them
+	head, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+	received := make([]int, subscribers)
+	subscribed := make(chan struct{}, subscribers)
+	for s := 0; s < subscribers; s++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			subscription, err := client.SubscribeToAll(ctx, kurrentdb.SubscribeToAllOptions{
+				From:   head,
+				Filter: &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{prefix + "-"}},
+			})
+			subscribed <- struct{}{}
+			if err != nil {
+				fail(fmt.Sprintf("subscriber %d", s), err)
+				return
+			}
+			defer subscription.Close()
+			for received[s] < producers*perProducer {
+				event := subscription.Recv()
+				if event.SubscriptionDropped != nil {
+					fail(fmt.Sprintf("subscriber %d", s), event.SubscriptionDropped.Error)
+					return
+				}
+				if event.EventAppeared != nil {
+					received[s]++
+				}
+			}
+		}()
+	}
+	for s := 0; s < subscribers; s++ {
+		<-subscribed
+	}
+
+	started := time.Now()
+	var writing sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		writing.Add(1)
+		go func() {
+			defer writing.Done()
+			for seq := 0; seq < perProducer; seq++ {
+				if _, err := client.AppendToStream(ctx, streams[p], kurrentdb.AppendToStreamOptions{}, newEvent(p, seq)); err != nil {
+					fail(fmt.Sprintf("producer %d", p), err)
+				}
+			}
+		}()
+	}
+
+	// Readers read the streams back while they are being written, until the producers finish
+	done := make(chan struct{})
+	var reads atomic.Int64
+	for r := 0; r < readers; r++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for i := r; ; i++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if _, _, err := readStreamFrom(ctx, NewClientStore(client), streams[i%producers], NoVersion); err != nil {
+					fail(fmt.Sprintf("reader %d", r), err)
+					return
+				}
+				reads.Add(1)
+			}
+		}()
+	}
+
+	writing.Wait()
+	close(done)
+	workers.Wait()
+	elapsed := time.Since(started)
+	fmt.Printf("  %d appends, %d stream reads, %d subscription deliveries in %s, %d failures\n",
+		producers*perProducer, reads.Load(), subscribers*producers*perProducer, elapsed.Round(time.Millisecond), failures.Load())
+
+	// === SHARED VS PER-GOROUTINE CLIENTS ===
+	fmt.Println("\n=== Shared client vs a client per goroutine ===")
+	// bench runs the producers again, each on the client clientFor gives it
+	bench := func(clientFor func() (*kurrentdb.Client, func())) (time.Duration, int64) {
+		stream := Streams.Name("sensor", uuid.New().String())
+		var wg sync.WaitGroup
+		var failed atomic.Int64
+		started := time.Now()
+		for p := 0; p < producers; p++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				client, release := clientFor()
+				defer release()
+				for seq := 0; seq < perProducer; seq++ {
+					if _, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, newEvent(p, seq)); err != nil {
+						failed.Add(1)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		return time.Since(started), failed.Load()
+	}
+	sharedElapsed, sharedFailed := bench(func() (*kurrentdb.Client, func()) {
+		return client, func() {}
+	})
+	perGoroutineElapsed, perGoroutineFailed := bench(func() (*kurrentdb.Client, func()) {
+		own, _ := connect()
+		return own, func() { own.Close() }
+	})
+	fmt.Printf("  shared:        %s\n", sharedElapsed.Round(time.Millisecond))
+	fmt.Printf("  per-goroutine: %s (%d connections, %.1fx)\n",
+		perGoroutineElapsed.Round(time.Millisecond), producers, perGoroutineElapsed.Seconds()/sharedElapsed.Seconds())
+
+	// === CLOSING THE SHARED CLIENT ===
+	fmt.Println("\n=== Closing a shared client under load ===")
+	closing, _ := connect()
+	closeStream := Streams.Name("sensor", uuid.New().String())
+	var users sync.WaitGroup
+	var subscriberEnded, producersEnded atomic.Int64
+	ready := make(chan struct{}, subscribers)
+	for s := 0; s < subscribers; s++ {
+		users.Add(1)
+		go func() {
+			defer users.Done()
+			defer subscriberEnded.Add(1)
+			subscription, err := closing.SubscribeToStream(ctx, closeStream, kurrentdb.SubscribeToStreamOptions{})
+			ready <- struct{}{}
+			if err != nil {
+				return
+			}
+			defer subscription.Close()
+			for {
+				if event := subscription.Recv(); event.SubscriptionDropped != nil {
+					return
+				}
+			}
+		}()
+	}
+	for p := 0; p < producers; p++ {
+		users.Add(1)
+		go func() {
+			defer users.Done()
+			defer producersEnded.Add(1)
+			for seq := 0; ; seq++ {
+				if _, err := closing.AppendToStream(ctx, closeStream, kurrentdb.AppendToStreamOptions{}, newEvent(p, seq)); err != nil {
+					return
+				}
+			}
+		}()
+	}
+	for s := 0; s < subscribers; s++ {
+		<-ready
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	closeStarted := time.Now()
+	closing.Close()
+	stoppedAll := make(chan struct{})
+	go func() {
+		users.Wait()
+		close(stoppedAll)
+	}()
+	var closeElapsed time.Duration
+	select {
+	case <-stoppedAll:
+		closeElapsed = time.Since(closeStarted)
+	case <-time.After(10 * time.Second):
+		closeElapsed = -1
+	}
+	_, afterCloseErr := closing.AppendToStream(ctx, closeStream, kurrentdb.AppendToStreamOptions{}, newEvent(0, 0))
+	fmt.Printf("  %d subscribers and %d producers stopped in %s; append after close: %v\n",
+		subscriberEnded.Load(), producersEnded.Load(), closeElapsed.Round(time.Millisecond), afterCloseErr)
+
+	// The other client is unaffected, and sees what the closed one appended
+	written, _, stillOpenErr := readStreamFrom(ctx, NewClientStore(client), closeStream, NoVersion)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if failures.Load() != 0 {
+		fmt.Printf("FAIL: The mixed workload should run without failures, got %d\n", failures.Load())
+		passed = false
+	}
+	for s, count := range received {
+		if count != producers*perProducer {
+			fmt.Printf("FAIL: Subscriber %d should receive all %d events, got %d\n", s, producers*perProducer, count)
+			passed = false
+		}
+	}
+	if sharedFailed != 0 || perGoroutineFailed != 0 {
+		fmt.Printf("FAIL: Both benchmarks should append without failures, got %d and %d\n", sharedFailed, perGoroutineFailed)
+		passed = false
+	}
+	if closeElapsed < 0 || subscriberEnded.Load() != subscribers || producersEnded.Load() != producers {
+		fmt.Printf("FAIL: Closing the client should stop every user, got %d subscribers and %d producers\n",
+			subscriberEnded.Load(), producersEnded.Load())
+		passed = false
+	}
+	if afterCloseErr == nil {
+		fmt.Println("FAIL: Appending on a closed client should fail")
+		passed = false
+	}
+	if stillOpenErr != nil || len(written) == 0 {
+		fmt.Printf("FAIL: Closing one client should not affect another, got %d events (%v)\n", len(written), stillOpenErr)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll shared client tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
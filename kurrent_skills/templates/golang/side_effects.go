@@ -0,0 +1,321 @@
+// KurrentDB Go Client Example - Reactive projections with side-effect descriptors
+// Demonstrates: Projections that describe derived events and external calls, executed by a runner or inspected by tests
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === SIDE EFFECT DESCRIPTORS ===
+
+// SideEffectKind distinguishes derived events from external calls
+type SideEffectKind string
+
+const (
+	EmitEvent    SideEffectKind = "emit"
+	ExternalCall SideEffectKind = "call"
+)
+
+// SideEffect describes something a projection wants done, without doing it
+type SideEffect struct {
+	Kind SideEffectKind
+	// Stream and EventType are set for EmitEvent
+	Stream    string
+	EventType string
+	// Target names the call handler registered on the runner, for ExternalCall
+	Target string
+	Data   map[string]interface{}
+	// CausationID is the ID of the event that produced the effect, set by ApplyDry
+	CausationID uuid.UUID
+	// EventID is derived from CausationID and the effect's position, so the same source event
+	// always emits the same event IDs; set by ApplyDry for EmitEvent
+	EventID uuid.UUID
+}
+
+// SideEffects is the ordered list of effects one event produced
+type SideEffects []SideEffect
+
+// Emit describes appending a derived event
+func Emit(stream, eventType string, data map[string]interface{}) SideEffect {
+	return SideEffect{Kind: EmitEvent, Stream: stream, EventType: eventType, Data: data}
+}
+
+// Call describes invoking an external system through a named handler
+func Call(target string, data map[string]interface{}) SideEffect {
+	return SideEffect{Kind: ExternalCall, Target: target, Data: data}
+}
+
+// OfType returns the emitted events with the given type, for assertions
+func (effects SideEffects) OfType(eventType string) SideEffects {
+	var matched SideEffects
+	for _, effect := range effects {
+		if effect.Kind == EmitEvent && effect.EventType == eventType {
+			matched = append(matched, effect)
+		}
+	}
+	return matched
+}
+
+// === REACTIONS ===
+
+// Reaction decides side effects from the state before and after the handler ran. It must be pure:
+// the same inputs always produce the same effects, so replaying a projection is safe.
+type Reaction func(before, after, data map[string]interface{}) SideEffects
+
+// React registers a reaction for an event type. A type may have a reaction without a handler.
+func (p *Projection) React(eventType string, reaction Reaction) *Projection {
+	p.reactions[eventType] = reaction
+	return p
+}
+
+// ApplyDry applies the event like Apply and returns the side effects its reaction describes,
+// without executing any of them. State and checkpoint advance exactly as with Apply.
+func (p *Projection) ApplyDry(event *kurrentdb.RecordedEvent, position kurrentdb.Position) (SideEffects, error) {
+	_, effects, err := p.apply(event, position)
+	return effects, err
+}
+
+// react calls reaction, stamping each effect with the causing event and converting a panic into
+// a *HandlerPanicError
+func (p *Projection) react(reaction Reaction, event *kurrentdb.RecordedEvent, before, after, data map[string]interface{}) (effects SideEffects, err error) {
+	defer p.recoverHandler(event, &err)
+
+	effects = reaction(before, after, data)
+	for i := range effects {
+		effects[i].CausationID = event.EventID
+		if effects[i].Kind == EmitEvent {
+			effects[i].EventID = uuid.NewSHA1(event.EventID, []byte(fmt.Sprintf("side-effect-%d", i)))
+		}
+	}
+	return effects, nil
+}
+
+// === RUNNER ===
+
+// SideEffectRunner executes side effects in production
+type SideEffectRunner struct {
+	client *kurrentdb.Client
+	calls  map[string]func(ctx context.Context, data map[string]interface{}) error
+}
+
+func NewSideEffectRunner(client *kurrentdb.Client) *SideEffectRunner {
+	return &SideEffectRunner{
+		client: client,
+		calls:  make(map[string]func(ctx context.Context, data map[string]interface{}) error),
+	}
+}
+
+// Handle registers the function that performs ExternalCall effects for target
+func (r *SideEffectRunner) Handle(target string, call func(ctx context.Context, data map[string]interface{}) error) *SideEffectRunner {
+	r.calls[target] = call
+	return r
+}
+
+// Execute performs effects in order, stopping at the first failure. Emitted events keep their
+// derived EventID, so re-executing after a crash is deduplicated by the server's idempotent-append
+// check instead of writing the event twice. External calls must be idempotent on their own.
+func (r *SideEffectRunner) Execute(ctx context.Context, effects SideEffects) error {
+	for _, effect := range effects {
+		switch effect.Kind {
+		case EmitEvent:
+			data, err := json.Marshal(effect.Data)
+			if err != nil {
+				return fmt.Errorf("encode %s: %w", effect.EventType, err)
+			}
+			metadata, err := NewMeta().SetCausation(effect.CausationID.String()).Marshal()
+			if err != nil {
+				return fmt.Errorf("encode %s metadata: %w", effect.EventType, err)
+			}
+
+			_, err = r.client.AppendToStream(ctx, effect.Stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+				EventID:     effect.EventID,
+				EventType:   effect.EventType,
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        data,
+				Metadata:    metadata,
+			})
+			if err != nil {
+				return fmt.Errorf("emit %s to %s: %w", effect.EventType, effect.Stream, err)
+			}
+
+		case ExternalCall:
+			call, ok := r.calls[effect.Target]
+			if !ok {
+				return fmt.Errorf("no handler for external call %q", effect.Target)
+			}
+			if err := call(ctx, effect.Data); err != nil {
+				return fmt.Errorf("call %s: %w", effect.Target, err)
+			}
+
+		default:
+			return fmt.Errorf("unknown side effect kind %q", effect.Kind)
+		}
+	}
+	return nil
+}
+
+// === INVENTORY PROJECTION ===
+
+// NewInventoryProjection tracks stock on hand per inventory stream and, when a reservation takes
+// stock below threshold, describes a LowStockDetected event and a reorder call
+func NewInventoryProjection(threshold float64) *Projection {
+	return NewProjection("Inventory").
+		On("StockReceived", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			onHand, _ := state["onHand"].(float64)
+			state["sku"] = data["sku"]
+			state["onHand"] = onHand + data["quantity"].(float64)
+			return state
+		}).
+		On("StockReserved", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			state["onHand"] = state["onHand"].(float64) - data["quantity"].(float64)
+			return state
+		}).
+		React("StockReserved", func(before, after, data map[string]interface{}) SideEffects {
+			previous, _ := before["onHand"].(float64)
+			onHand := after["onHand"].(float64)
+			if previous < threshold || onHand >= threshold {
+				// Only the reservation that crosses the threshold alerts
+				return nil
+			}
+
+			sku := after["sku"].(string)
+			return SideEffects{
+				Emit(Streams.Name("stockalert", sku), "LowStockDetected", map[string]interface{}{
+					"sku":       sku,
+					"onHand":    onHand,
+					"threshold": threshold,
+				}),
+				Call("reorder", map[string]interface{}{"sku": sku, "quantity": threshold * 2}),
+			}
+		})
+}
+
+// RunSideEffects drives the inventory projection from a live subscription and executes its effects
+func RunSideEffects() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	sku := "sku" + uuid.New().String()[:8]
+	streamName := Streams.Name("inventory", sku)
+
+	// === APPEND EVENTS ===
+	for _, event := range []struct {
+		eventType string
+		quantity  float64
+	}{
+		{"StockReceived", 20},
+		{"StockReserved", 8},
+		{"StockReserved", 6}, // 6 left: crosses the threshold of 10
+		{"StockReserved", 2}, // already low: no second alert
+	} {
+		data, _ := json.Marshal(map[string]interface{}{"sku": sku, "quantity": event.quantity})
+		_, err := client.AppendToStream(ctx, streamName, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   event.eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        data,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	fmt.Printf("Appended 4 events to %s\n", streamName)
+
+	// === RUN ===
+	projection := NewInventoryProjection(10)
+
+	var reorders []map[string]interface{}
+	runner := NewSideEffectRunner(client).Handle("reorder", func(ctx context.Context, data map[string]interface{}) error {
+		fmt.Printf("  reorder requested: %v\n", data)
+		reorders = append(reorders, data)
+		return nil
+	})
+
+	subscription, err := client.SubscribeToStream(ctx, streamName, kurrentdb.SubscribeToStreamOptions{From: kurrentdb.Start{}})
+	if err != nil {
+		panic(err)
+	}
+
+	processed := 0
+	for processed < 4 {
+		message := subscription.Recv()
+		if message.SubscriptionDropped != nil {
+			panic(message.SubscriptionDropped.Error)
+		}
+		if message.EventAppeared == nil {
+			continue
+		}
+
+		event := Resolve(message.EventAppeared, false)
+		processed++
+
+		effects, err := projection.ApplyDry(event, Resolve(message.EventAppeared, true).Position)
+		if err != nil {
+			panic(err)
+		}
+		if err := runner.Execute(ctx, effects); err != nil {
+			panic(err)
+		}
+
+		// Executing the same effects again (e.g. after a crash before the checkpoint) is a no-op append
+		if err := runner.Execute(ctx, effects.OfType("LowStockDetected")); err != nil {
+			panic(err)
+		}
+	}
+	subscription.Close()
+
+	// === VERIFY ===
+	var alerts []*kurrentdb.RecordedEvent
+	events, err := client.ReadStream(ctx, Streams.Name("stockalert", sku), kurrentdb.ReadStreamOptions{From: kurrentdb.Start{}}, 100)
+	if err != nil {
+		panic(err)
+	}
+	for {
+		event, err := events.Recv()
+		if err != nil {
+			break
+		}
+		alert := Resolve(event, false)
+		alerts = append(alerts, alert)
+		fmt.Printf("  %s: %s\n", alert.EventType, alert.Data)
+	}
+	events.Close()
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if len(alerts) != 1 {
+		fmt.Printf("FAIL: Expected exactly 1 LowStockDetected event despite re-execution, got %d\n", len(alerts))
+		passed = false
+	}
+	if len(reorders) != 1 {
+		fmt.Printf("FAIL: Expected 1 reorder call, got %d\n", len(reorders))
+		passed = false
+	}
+	if onHand := projection.Get(streamName)["onHand"]; onHand != float64(4) {
+		fmt.Printf("FAIL: Expected 4 on hand, got %v\n", onHand)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll side effect tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,199 @@
+// KurrentDB Go Client Example - Versioned projection snapshots
+// Demonstrates: Saving projection state with a format version and migrating old snapshots on load
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === SNAPSHOT FORMAT ===
+
+// CurrentSnapshotVersion is the state shape written by this code. Bump it together with a
+// migration from the previous version whenever the shape changes.
+const CurrentSnapshotVersion = 2
+
+// ProjectionSnapshot is the on-disk format. Only State changes shape between versions; the
+// envelope fields stay stable so any version can be read far enough to be migrated.
+type ProjectionSnapshot struct {
+	Version    int                 `json:"version"`
+	Name       string              `json:"name"`
+	Checkpoint *kurrentdb.Position `json:"checkpoint"`
+	State      json.RawMessage     `json:"state"`
+}
+
+// Errors returned when a snapshot can't be brought to the current version
+var (
+	ErrNoMigrationPath    = errors.New("no snapshot migration path")
+	ErrSnapshotTooNew     = errors.New("snapshot is newer than this code supports")
+	ErrSnapshotNameChange = errors.New("snapshot belongs to a different projection")
+)
+
+// === MIGRATOR ===
+
+// SnapshotMigration rewrites state data from one version to the next
+type SnapshotMigration func(data []byte) ([]byte, error)
+
+// SnapshotMigrator holds one migration step per source version. Safe for concurrent use.
+type SnapshotMigrator struct {
+	mu    sync.RWMutex
+	steps map[int]SnapshotMigration
+}
+
+func NewSnapshotMigrator() *SnapshotMigrator {
+	return &SnapshotMigrator{steps: make(map[int]SnapshotMigration)}
+}
+
+// Register adds the step that migrates fromVersion state to fromVersion+1
+func (m *SnapshotMigrator) Register(fromVersion int, migrate SnapshotMigration) *SnapshotMigrator {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.steps[fromVersion] = migrate
+	return m
+}
+
+// Migrate runs one step, returning the migrated data and its new version
+func (m *SnapshotMigrator) Migrate(fromVersion int, data []byte) ([]byte, int, error) {
+	m.mu.RLock()
+	migrate, ok := m.steps[fromVersion]
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, fromVersion, fmt.Errorf("%w from version %d", ErrNoMigrationPath, fromVersion)
+	}
+	migrated, err := migrate(data)
+	if err != nil {
+		return nil, fromVersion, fmt.Errorf("migrate snapshot v%d -> v%d: %w", fromVersion, fromVersion+1, err)
+	}
+	return migrated, fromVersion + 1, nil
+}
+
+// MigrateToCurrent runs steps until data is at CurrentSnapshotVersion
+func (m *SnapshotMigrator) MigrateToCurrent(version int, data []byte) ([]byte, error) {
+	if version > CurrentSnapshotVersion {
+		return nil, fmt.Errorf("%w: v%d, current is v%d", ErrSnapshotTooNew, version, CurrentSnapshotVersion)
+	}
+	for version < CurrentSnapshotVersion {
+		var err error
+		if data, version, err = m.Migrate(version, data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// OrderSummaryMigrations knows every historical OrderSummary state shape.
+//
+//	v1: {"<stream>": {"total": 125, "state": "shipped", "itemCount": 1}}
+//	v2: {"<stream>": {"amount": 125, "status": "shipped", "items": [...]}}
+//
+// v1 didn't keep item names, so migrated orders get an empty items list.
+func OrderSummaryMigrations() *SnapshotMigrator {
+	return NewSnapshotMigrator().
+		Register(1, func(data []byte) ([]byte, error) {
+			var v1 map[string]map[string]interface{}
+			if err := json.Unmarshal(data, &v1); err != nil {
+				return nil, err
+			}
+			v2 := make(map[string]map[string]interface{}, len(v1))
+			for stream, order := range v1 {
+				migrated := make(map[string]interface{}, len(order))
+				for key, value := range order {
+					switch key {
+					case "total":
+						migrated["amount"] = value
+					case "state":
+						migrated["status"] = value
+					case "itemCount":
+						// dropped: derived from items in v2
+					default:
+						migrated[key] = value
+					}
+				}
+				migrated["items"] = []string{}
+				v2[stream] = migrated
+			}
+			return json.Marshal(v2)
+		})
+}
+
+// === SAVE / LOAD ===
+
+// SaveProjectionSnapshot writes the projection's state and checkpoint at CurrentSnapshotVersion
+func SaveProjectionSnapshot(path string, p *Projection) error {
+	data, err := encodeSnapshot(p)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadProjectionSnapshot restores p from path, migrating older versions first. It returns
+// false if there is no snapshot, in which case p is left untouched and should replay from the start.
+func LoadProjectionSnapshot(path string, p *Projection, migrator *SnapshotMigrator) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := restoreSnapshot(path, data, p, migrator); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// encodeSnapshot serializes p at CurrentSnapshotVersion
+func encodeSnapshot(p *Projection) ([]byte, error) {
+	state, err := json.Marshal(p.State)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(ProjectionSnapshot{
+		Version:    CurrentSnapshotVersion,
+		Name:       p.Name,
+		Checkpoint: p.Checkpoint,
+		State:      state,
+	}, "", "  ")
+}
+
+// restoreSnapshot decodes and migrates a snapshot read from source (a path or stream name, for
+// errors) into p. p is only modified once the snapshot has been fully decoded.
+func restoreSnapshot(source string, data []byte, p *Projection, migrator *SnapshotMigrator) error {
+	var snapshot ProjectionSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("corrupt snapshot %s: %w", source, err)
+	}
+	if snapshot.Version == 0 {
+		// Snapshots written before the version field existed are v1
+		snapshot.Version = 1
+	}
+	if snapshot.Name != p.Name {
+		return fmt.Errorf("%w: %s is for %q, not %q", ErrSnapshotNameChange, source, snapshot.Name, p.Name)
+	}
+
+	stateData, err := migrator.MigrateToCurrent(snapshot.Version, snapshot.State)
+	if err != nil {
+		return fmt.Errorf("load snapshot %s: %w", source, err)
+	}
+
+	state := make(map[string]map[string]interface{})
+	if err := json.Unmarshal(stateData, &state); err != nil {
+		return fmt.Errorf("decode migrated snapshot %s: %w", source, err)
+	}
+
+	p.State = state
+	p.Checkpoint = snapshot.Checkpoint
+	return nil
+}
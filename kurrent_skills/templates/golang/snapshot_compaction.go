@@ -0,0 +1,390 @@
+// KurrentDB Go Client Example - Compacting snapshot streams
+// Demonstrates: Keeping the last K snapshots with $maxCount or $tb, loading safely during compaction, and scavenging
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === SNAPSHOT COMPACTOR ===
+
+// SnapshotCompaction chooses how a SnapshotCompactor hides superseded snapshots. Either way they
+// stop being readable at once, but stay on disk until a scavenge removes them.
+type SnapshotCompaction int
+
+const (
+	// CompactByMaxCount sets $maxCount to the retention once per stream; the server then hides
+	// older snapshots on its own as new ones arrive
+	CompactByMaxCount SnapshotCompaction = iota
+	// CompactByTruncate sets $tb after every save to the first retained revision: a metadata write
+	// per save, but the stream's metadata records exactly which snapshots were dropped
+	CompactByTruncate
+)
+
+// SnapshotCompactor keeps the last K snapshots of each stream it compacts readable. It is safe to
+// share between the snapshot stores of several projections.
+type SnapshotCompactor struct {
+	client *kurrentdb.Client
+	keep   uint64
+	mode   SnapshotCompaction
+
+	scavengeEvery int
+	scavenge      func(ctx context.Context) error
+
+	mu          sync.Mutex
+	maxCountSet map[string]bool
+	compactions int
+}
+
+// NewSnapshotCompactor keeps only the latest snapshot, via $maxCount
+func NewSnapshotCompactor(client *kurrentdb.Client) *SnapshotCompactor {
+	return &SnapshotCompactor{
+		client:      client,
+		keep:        1,
+		mode:        CompactByMaxCount,
+		maxCountSet: make(map[string]bool),
+	}
+}
+
+// KeepLast sets how many snapshots stay readable. Keeping more than one lets Load fall back to an
+// older snapshot when the latest fails to restore.
+func (c *SnapshotCompactor) KeepLast(k int) *SnapshotCompactor {
+	if k < 1 {
+		panic(fmt.Sprintf("snapshot compactor: must keep at least 1 snapshot, got %d", k))
+	}
+	c.keep = uint64(k)
+	return c
+}
+
+// Truncating switches to CompactByTruncate
+func (c *SnapshotCompactor) Truncating() *SnapshotCompactor {
+	c.mode = CompactByTruncate
+	return c
+}
+
+// ScavengeEvery runs scavenge after every nth compaction. A scavenge rewrites chunks for the whole
+// node, not just the snapshot streams, so on a busy node prefer a scheduled scavenge and leave this unset.
+func (c *SnapshotCompactor) ScavengeEvery(n int, scavenge func(ctx context.Context) error) *SnapshotCompactor {
+	c.scavengeEvery = n
+	c.scavenge = scavenge
+	return c
+}
+
+// Keep returns how many snapshots stay readable
+func (c *SnapshotCompactor) Keep() uint64 {
+	return c.keep
+}
+
+// Compact hides the snapshots of stream older than the last K, given the revision of the snapshot
+// just appended. Call it only after that append succeeded, so the latest snapshot stays readable.
+func (c *SnapshotCompactor) Compact(ctx context.Context, stream string, latest uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var err error
+	switch c.mode {
+	case CompactByMaxCount:
+		err = c.setMaxCount(ctx, stream)
+	case CompactByTruncate:
+		err = c.truncate(ctx, stream, latest)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.compactions++
+	if c.scavenge != nil && c.scavengeEvery > 0 && c.compactions%c.scavengeEvery == 0 {
+		if err := c.scavenge(ctx); err != nil {
+			return fmt.Errorf("scavenge: %w", err)
+		}
+	}
+	return nil
+}
+
+// setMaxCount sets $maxCount to the retention unless the stream already has it, keeping any other metadata
+func (c *SnapshotCompactor) setMaxCount(ctx context.Context, stream string) error {
+	if c.maxCountSet[stream] {
+		return nil
+	}
+
+	metadata, err := c.metadata(ctx, stream)
+	if err != nil {
+		return err
+	}
+	if maxCount := metadata.MaxCount(); maxCount == nil || *maxCount != c.keep {
+		metadata.SetMaxCount(c.keep)
+		if _, err := c.client.SetStreamMetadata(ctx, stream, kurrentdb.AppendToStreamOptions{}, *metadata); err != nil {
+			return err
+		}
+	}
+	c.maxCountSet[stream] = true
+	return nil
+}
+
+// truncate sets $tb to the first retained revision, keeping any other metadata. It never lowers
+// $tb: a slower writer compacting after a faster one would otherwise bring snapshots back.
+func (c *SnapshotCompactor) truncate(ctx context.Context, stream string, latest uint64) error {
+	if latest+1 <= c.keep {
+		return nil
+	}
+	truncateBefore := latest + 1 - c.keep
+
+	metadata, err := c.metadata(ctx, stream)
+	if err != nil {
+		return err
+	}
+	if current := metadata.TruncateBefore(); current != nil && *current >= truncateBefore {
+		return nil
+	}
+	metadata.SetTruncateBefore(truncateBefore)
+	_, err = c.client.SetStreamMetadata(ctx, stream, kurrentdb.AppendToStreamOptions{}, *metadata)
+	return err
+}
+
+func (c *SnapshotCompactor) metadata(ctx context.Context, stream string) (*kurrentdb.StreamMetadata, error) {
+	metadata, err := c.client.GetStreamMetadata(ctx, stream, kurrentdb.ReadStreamOptions{})
+	if err != nil && !isStreamNotFound(err) {
+		return nil, err
+	}
+	if metadata == nil {
+		metadata = &kurrentdb.StreamMetadata{}
+	}
+	return metadata, nil
+}
+
+// === SCAVENGE ===
+
+// StartScavenge asks the node to start a scavenge through its HTTP API and returns the scavenge
+// ID. The client has no scavenge call, and the scavenge runs in the background after this returns.
+func StartScavenge(ctx context.Context, baseURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/admin/scavenge", nil)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth("admin", "changeit")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("start scavenge: %s: %s", resp.Status, body)
+	}
+	var started struct {
+		ScavengeID string `json:"scavengeId"`
+	}
+	if err := json.Unmarshal(body, &started); err != nil {
+		return "", fmt.Errorf("decode scavenge response: %w", err)
+	}
+	return started.ScavengeID, nil
+}
+
+// RunSnapshotCompaction saves many snapshots under each compaction mode, loads continuously while
+// compacting, falls back past a corrupt snapshot, and starts a scavenge
+func RunSnapshotCompaction() {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	// A unique projection name gives each run its own snapshot stream
+	newProjection := func() *Projection {
+		p := NewOrderSummaryProjection()
+		p.Name = "OrderSummary" + uuid.New().String()[:8]
+		return p
+	}
+	// saveAt snapshots p as if it had projected up to position n
+	saveAt := func(store *StreamSnapshotStore, p *Projection, n int) error {
+		p.State["order-1"] = map[string]interface{}{"amount": float64(n)}
+		p.Checkpoint = &kurrentdb.Position{Commit: uint64(n), Prepare: uint64(n)}
+		return store.Save(ctx, p)
+	}
+	// readable lists the revisions still readable in a stream
+	readable := func(stream string) []uint64 {
+		events, _, err := readStreamFrom(ctx, NewClientStore(client), stream, NoVersion)
+		if err != nil {
+			panic(err)
+		}
+		revisions := make([]uint64, len(events))
+		for i, event := range events {
+			revisions[i] = event.EventNumber
+		}
+		return revisions
+	}
+
+	// === KEEP THE LATEST ===
+	fmt.Println("\n=== $maxCount, keep 1 ===")
+	latestOnly := newProjection()
+	latestStore := NewStreamSnapshotStore(client, latestOnly.Name, OrderSummaryMigrations())
+	for n := 1; n <= 10; n++ {
+		if err := saveAt(latestStore, latestOnly, n); err != nil {
+			panic(err)
+		}
+	}
+	latestRevisions := readable(latestStore.Stream())
+	latestLoaded := newProjection()
+	latestLoaded.Name = latestOnly.Name
+	if _, err := latestStore.Load(ctx, latestLoaded); err != nil {
+		panic(err)
+	}
+	fmt.Printf("  10 snapshots saved, readable revisions %v, loaded checkpoint %d\n", latestRevisions, latestLoaded.Checkpoint.Commit)
+
+	// === KEEP THE LAST K ===
+	fmt.Println("\n=== $tb, keep 3 ===")
+	lastK := newProjection()
+	lastKCompactor := NewSnapshotCompactor(client).KeepLast(3).Truncating()
+	lastKStore := NewStreamSnapshotStore(client, lastK.Name, OrderSummaryMigrations()).WithCompactor(lastKCompactor)
+	for n := 1; n <= 10; n++ {
+		if err := saveAt(lastKStore, lastK, n); err != nil {
+			panic(err)
+		}
+	}
+	lastKRevisions := readable(lastKStore.Stream())
+	metadata, err := client.GetStreamMetadata(ctx, lastKStore.Stream(), kurrentdb.ReadStreamOptions{})
+	if err != nil {
+		panic(err)
+	}
+	var truncateBefore uint64
+	if tb := metadata.TruncateBefore(); tb != nil {
+		truncateBefore = *tb
+	}
+	fmt.Printf("  10 snapshots saved, $tb=%d, readable revisions %v\n", truncateBefore, lastKRevisions)
+
+	// A corrupt latest snapshot falls back to the one before it
+	_, err = client.AppendToStream(ctx, lastKStore.Stream(), kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+		EventID:     uuid.New(),
+		EventType:   ProjectionSnapshotEventType,
+		ContentType: kurrentdb.ContentTypeJson,
+		Data:        []byte(`{"version":`),
+	})
+	if err != nil {
+		panic(err)
+	}
+	fallback := newProjection()
+	fallback.Name = lastK.Name
+	fallbackLoaded, fallbackErr := lastKStore.Load(ctx, fallback)
+	var fallbackCheckpoint uint64
+	if fallback.Checkpoint != nil {
+		fallbackCheckpoint = fallback.Checkpoint.Commit
+	}
+	fmt.Printf("  After a corrupt snapshot: loaded=%v checkpoint=%d err=%v\n", fallbackLoaded, fallbackCheckpoint, fallbackErr)
+
+	// === LOADING DURING COMPACTION ===
+	fmt.Println("\n=== Loading while compacting ===")
+	racing := newProjection()
+	racingStore := NewStreamSnapshotStore(client, racing.Name, OrderSummaryMigrations()).
+		WithCompactor(NewSnapshotCompactor(client).Truncating())
+	if err := saveAt(racingStore, racing, 1); err != nil {
+		panic(err)
+	}
+
+	const saves = 30
+	var loads, misses, regressions atomic.Int64
+	var loadErr atomic.Value
+	stop := make(chan struct{})
+	var loader sync.WaitGroup
+	loader.Add(1)
+	go func() {
+		defer loader.Done()
+		var last uint64
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			p := NewOrderSummaryProjection()
+			p.Name = racing.Name
+			loaded, err := racingStore.Load(ctx, p)
+			if err != nil {
+				loadErr.Store(err)
+				return
+			}
+			loads.Add(1)
+			if !loaded {
+				misses.Add(1)
+				continue
+			}
+			if p.Checkpoint.Commit < last {
+				regressions.Add(1)
+			}
+			last = p.Checkpoint.Commit
+		}
+	}()
+	for n := 2; n <= saves; n++ {
+		if err := saveAt(racingStore, racing, n); err != nil {
+			panic(err)
+		}
+	}
+	close(stop)
+	loader.Wait()
+	racingRevisions := readable(racingStore.Stream())
+	fmt.Printf("  %d saves, %d concurrent loads, %d found nothing, %d went backwards; readable revisions %v\n",
+		saves, loads.Load(), misses.Load(), regressions.Load(), racingRevisions)
+
+	// === SCAVENGE ===
+	// Compaction only hides old snapshots; a scavenge reclaims their space
+	scavengeID, scavengeErr := StartScavenge(ctx, httpBaseURL(connectionString))
+	if scavengeErr != nil {
+		fmt.Printf("\nScavenge not started: %v\n", scavengeErr)
+	} else {
+		fmt.Printf("\nScavenge %s started\n", scavengeID)
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if fmt.Sprint(latestRevisions) != "[9]" || latestLoaded.Checkpoint == nil || latestLoaded.Checkpoint.Commit != 10 {
+		fmt.Printf("FAIL: $maxCount=1 should leave only the latest snapshot readable, got %v\n", latestRevisions)
+		passed = false
+	}
+	if truncateBefore != 7 || fmt.Sprint(lastKRevisions) != "[7 8 9]" {
+		fmt.Printf("FAIL: Keeping 3 should set $tb=7 and leave revisions 7-9, got $tb=%d and %v\n", truncateBefore, lastKRevisions)
+		passed = false
+	}
+	if !fallbackLoaded || fallbackErr != nil || fallbackCheckpoint != 10 {
+		fmt.Printf("FAIL: A corrupt latest snapshot should fall back to the previous one, got loaded=%v checkpoint=%d err=%v\n",
+			fallbackLoaded, fallbackCheckpoint, fallbackErr)
+		passed = false
+	}
+	if err, _ := loadErr.Load().(error); err != nil {
+		fmt.Printf("FAIL: Loading during compaction should not fail, got %v\n", err)
+		passed = false
+	}
+	if loads.Load() == 0 || misses.Load() != 0 || regressions.Load() != 0 {
+		fmt.Printf("FAIL: Every concurrent load should find a snapshot no older than the last, got %d misses and %d regressions in %d loads\n",
+			misses.Load(), regressions.Load(), loads.Load())
+		passed = false
+	}
+	if fmt.Sprint(racingRevisions) != fmt.Sprintf("[%d]", saves-1) {
+		fmt.Printf("FAIL: Only the latest of %d snapshots should remain readable, got %v\n", saves, racingRevisions)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll snapshot compaction tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,678 @@
+// KurrentDB Go Client Example - Bounded memory projection with spill-to-disk
+// Demonstrates: Keeping hot entities in an LRU cache and cold ones in SQLite, flushing dirty state with the checkpoint
+package main
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+	_ "modernc.org/sqlite"
+)
+
+// === SPILL STORE ===
+
+// SpillStore is where a SpillingProjection keeps the state that doesn't fit in memory
+type SpillStore interface {
+	// Load returns the encoded state of key, and false if it has none
+	Load(ctx context.Context, key string) ([]byte, bool, error)
+	// Save writes states and moves the checkpoint to position, all or nothing
+	Save(ctx context.Context, states map[string][]byte, position kurrentdb.Position) error
+	// Checkpoint returns the position of the last Save, or nil before the first
+	Checkpoint(ctx context.Context) (*kurrentdb.Position, error)
+	Close() error
+}
+
+const spillSchema = `
+CREATE TABLE IF NOT EXISTS spill_state (
+	projection TEXT NOT NULL,
+	key        TEXT NOT NULL,
+	state      BLOB NOT NULL,
+	PRIMARY KEY (projection, key)
+) WITHOUT ROWID;
+CREATE TABLE IF NOT EXISTS spill_checkpoints (
+	projection       TEXT PRIMARY KEY,
+	commit_position  INTEGER NOT NULL,
+	prepare_position INTEGER NOT NULL
+);`
+
+// SQLiteSpillStore is a SpillStore in a SQLite file: a key-value table per projection name,
+// and the checkpoint saved in the same transaction as the states
+type SQLiteSpillStore struct {
+	db   *sql.DB
+	name string
+}
+
+// OpenSQLiteSpillStore opens (or creates) the database at path for the projection name
+func OpenSQLiteSpillStore(path, name string) (*SQLiteSpillStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(spillSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create schema: %w", err)
+	}
+	return &SQLiteSpillStore{db: db, name: name}, nil
+}
+
+func (s *SQLiteSpillStore) Load(ctx context.Context, key string) ([]byte, bool, error) {
+	var state []byte
+	err := s.db.QueryRowContext(ctx, `SELECT state FROM spill_state WHERE projection = ? AND key = ?`, s.name, key).Scan(&state)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return state, true, nil
+}
+
+func (s *SQLiteSpillStore) Save(ctx context.Context, states map[string][]byte, position kurrentdb.Position) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	upsert, err := tx.PrepareContext(ctx, `
+		INSERT INTO spill_state (projection, key, state) VALUES (?, ?, ?)
+		ON CONFLICT(projection, key) DO UPDATE SET state = excluded.state`)
+	if err != nil {
+		return err
+	}
+	defer upsert.Close()
+	for key, state := range states {
+		if _, err := upsert.ExecContext(ctx, s.name, key, state); err != nil {
+			return fmt.Errorf("save %s: %w", key, err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO spill_checkpoints (projection, commit_position, prepare_position) VALUES (?, ?, ?)
+		ON CONFLICT(projection) DO UPDATE SET commit_position = excluded.commit_position, prepare_position = excluded.prepare_position`,
+		s.name, position.Commit, position.Prepare)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteSpillStore) Checkpoint(ctx context.Context) (*kurrentdb.Position, error) {
+	var position kurrentdb.Position
+	err := s.db.QueryRowContext(ctx,
+		`SELECT commit_position, prepare_position FROM spill_checkpoints WHERE projection = ?`, s.name).
+		Scan(&position.Commit, &position.Prepare)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &position, nil
+}
+
+func (s *SQLiteSpillStore) Close() error {
+	return s.db.Close()
+}
+
+// === SPILLING PROJECTION ===
+
+// SpillStats counts how the cache served Apply and Get
+type SpillStats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Flushes   int64
+	// Resident is how many entities are in memory now
+	Resident int
+}
+
+// HitRate is the fraction of lookups served from memory
+func (s SpillStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+type spillEntry struct {
+	key   string
+	state map[string]interface{}
+	// dirty is set when state changed since it was last saved
+	dirty bool
+}
+
+// SpillingProjection is a per-stream projection like Projection whose memory is bounded by
+// Capacity entities rather than the size of the key space. The most recently used entities stay
+// in memory; the rest live in a SpillStore and are loaded back on Get or Apply.
+//
+// The store only ever holds state as of its checkpoint: Flush saves every dirty entity together
+// with the position of the last applied event, and evicting a dirty entity flushes first. After a
+// crash, reopening resumes from that checkpoint and Apply skips what it already covers, so no event
+// is lost or applied twice. Safe for concurrent use.
+type SpillingProjection struct {
+	Name     string
+	Capacity int
+
+	store    SpillStore
+	handlers map[string]EventHandler
+
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	recent     *list.List // front is most recently used
+	checkpoint *kurrentdb.Position
+	flushed    *kurrentdb.Position
+	stats      SpillStats
+}
+
+// NewSpillingProjection keeps up to capacity entities in memory and resumes from store's checkpoint
+func NewSpillingProjection(ctx context.Context, name string, store SpillStore, capacity int) (*SpillingProjection, error) {
+	checkpoint, err := store.Checkpoint(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load checkpoint of %s: %w", name, err)
+	}
+	return &SpillingProjection{
+		Name:       name,
+		Capacity:   max(capacity, 1),
+		store:      store,
+		handlers:   make(map[string]EventHandler),
+		entries:    make(map[string]*list.Element),
+		recent:     list.New(),
+		checkpoint: checkpoint,
+		flushed:    checkpoint,
+	}, nil
+}
+
+func (p *SpillingProjection) On(eventType string, handler EventHandler) *SpillingProjection {
+	p.handlers[eventType] = handler
+	return p
+}
+
+// Checkpoint returns the position of the last applied event; subscribe from Flushed instead,
+// which is what the store holds
+func (p *SpillingProjection) Checkpoint() *kurrentdb.Position {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.checkpoint
+}
+
+// Flushed returns the checkpoint saved in the store, the position to resume from after a restart
+func (p *SpillingProjection) Flushed() *kurrentdb.Position {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.flushed
+}
+
+// Stats returns the cache counters
+func (p *SpillingProjection) Stats() SpillStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats
+	stats.Resident = p.recent.Len()
+	return stats
+}
+
+// Get returns the state of streamID, loading it from the store if it isn't in memory. The map is
+// a copy of the state at the time of the call, or nil if the stream has none.
+func (p *SpillingProjection) Get(ctx context.Context, streamID string) (map[string]interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, err := p.lookup(ctx, streamID)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	return cloneState(entry.state), nil
+}
+
+// Apply runs the handler for the event on its stream's state. Events at or before the checkpoint
+// are ignored, so redelivery after a restart is harmless. Unhandled types advance the checkpoint and
+// return false; on a decode error nothing changes.
+func (p *SpillingProjection) Apply(ctx context.Context, event *kurrentdb.RecordedEvent, position kurrentdb.Position) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.checkpoint != nil && !positionAfter(position, *p.checkpoint) {
+		return false, nil
+	}
+	handler := p.handlers[event.EventType]
+	if handler == nil {
+		p.checkpoint = &position
+		return false, nil
+	}
+
+	data := make(map[string]interface{})
+	if len(event.Data) > 0 {
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return false, fmt.Errorf("decode %s on %s: %w", event.EventType, event.StreamID, err)
+		}
+	}
+
+	entry, err := p.lookup(ctx, event.StreamID)
+	if err != nil {
+		return false, err
+	}
+	if entry == nil {
+		entry = &spillEntry{key: event.StreamID, state: make(map[string]interface{})}
+		if err := p.insert(ctx, entry); err != nil {
+			return false, err
+		}
+	}
+	entry.state = handler(entry.state, data)
+	entry.dirty = true
+	p.checkpoint = &position
+	return true, nil
+}
+
+// Flush saves every dirty entity and the checkpoint in one store transaction. Call it before
+// recording progress anywhere else, and before stopping.
+func (p *SpillingProjection) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.flush(ctx)
+}
+
+// flush is Flush with p.mu held
+func (p *SpillingProjection) flush(ctx context.Context) error {
+	if p.checkpoint == nil || p.flushed != nil && *p.flushed == *p.checkpoint {
+		return nil
+	}
+
+	dirty := make(map[string][]byte)
+	for element := p.recent.Front(); element != nil; element = element.Next() {
+		entry := element.Value.(*spillEntry)
+		if !entry.dirty {
+			continue
+		}
+		encoded, err := json.Marshal(entry.state)
+		if err != nil {
+			return fmt.Errorf("encode state of %s: %w", entry.key, err)
+		}
+		dirty[entry.key] = encoded
+	}
+	if err := p.store.Save(ctx, dirty, *p.checkpoint); err != nil {
+		return fmt.Errorf("flush %s: %w", p.Name, err)
+	}
+
+	for element := p.recent.Front(); element != nil; element = element.Next() {
+		element.Value.(*spillEntry).dirty = false
+	}
+	checkpoint := *p.checkpoint
+	p.flushed = &checkpoint
+	p.stats.Flushes++
+	return nil
+}
+
+// lookup returns the entry for key from memory or the store, marking it most recently used, or
+// nil if the key has no state; p.mu must be held
+func (p *SpillingProjection) lookup(ctx context.Context, key string) (*spillEntry, error) {
+	if element, ok := p.entries[key]; ok {
+		p.stats.Hits++
+		p.recent.MoveToFront(element)
+		return element.Value.(*spillEntry), nil
+	}
+	p.stats.Misses++
+
+	encoded, found, err := p.store.Load(ctx, key)
+	if err != nil || !found {
+		return nil, err
+	}
+	entry := &spillEntry{key: key}
+	if err := json.Unmarshal(encoded, &entry.state); err != nil {
+		return nil, fmt.Errorf("decode state of %s: %w", key, err)
+	}
+	if err := p.insert(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// insert adds entry as most recently used, evicting the least recently used entries over
+// Capacity; p.mu must be held
+func (p *SpillingProjection) insert(ctx context.Context, entry *spillEntry) error {
+	for p.recent.Len() >= p.Capacity {
+		oldest := p.recent.Back()
+		evicted := oldest.Value.(*spillEntry)
+		// Saving just this entry would put state past the stored checkpoint on disk
+		if evicted.dirty {
+			if err := p.flush(ctx); err != nil {
+				return err
+			}
+		}
+		p.recent.Remove(oldest)
+		delete(p.entries, evicted.key)
+		p.stats.Evictions++
+	}
+	p.entries[entry.key] = p.recent.PushFront(entry)
+	return nil
+}
+
+// cloneState copies a state map deeply enough that the caller can't change the projection's copy
+func cloneState(state map[string]interface{}) map[string]interface{} {
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		return nil
+	}
+	var cloned map[string]interface{}
+	json.Unmarshal(encoded, &cloned)
+	return cloned
+}
+
+// NewCustomerSpendProjection totals orders per customer stream
+func NewCustomerSpendProjection(ctx context.Context, store SpillStore, capacity int) (*SpillingProjection, error) {
+	projection, err := NewSpillingProjection(ctx, "CustomerSpend", store, capacity)
+	if err != nil {
+		return nil, err
+	}
+	return projection.On("OrderPlaced", func(state, data map[string]interface{}) map[string]interface{} {
+		orders, _ := state["orders"].(float64)
+		total, _ := state["total"].(float64)
+		amount, _ := data["amount"].(float64)
+		state["orders"] = orders + 1
+		state["total"] = total + amount
+		return state
+	}), nil
+}
+
+// === CHECKS ===
+
+// RunSpillProjectionChecks projects a key space many times the cache capacity, checks every total
+// against a plain map, and crashes and resumes mid-way; no server required
+func RunSpillProjectionChecks() {
+	checks := newChecker("spill projection")
+
+	ctx := context.Background()
+	dir, err := os.MkdirTemp("", "spill-projection")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	dbPath := filepath.Join(dir, "spill.db")
+
+	const (
+		customers = 20000
+		events    = 100000
+		capacity  = 500
+		// hot customers take 80% of the orders, the way a few accounts dominate real traffic
+		hotCustomers = 200
+	)
+	customerOf := func(i int) string {
+		if i%5 != 0 {
+			return fmt.Sprintf("customer-%d", (i*7919)%hotCustomers)
+		}
+		return fmt.Sprintf("customer-%d", (i/5*104729)%customers)
+	}
+	eventAt := func(i int) *kurrentdb.RecordedEvent {
+		return syntheticEvent(customerOf(i), "OrderPlaced", 0, uint64(i+1)*10, fmt.Sprintf(`{"amount":%d}`, i%100))
+	}
+	expected := make(map[string]float64)
+	for i := 0; i < events; i++ {
+		expected[customerOf(i)] += float64(i % 100)
+	}
+
+	open := func() (*SQLiteSpillStore, *SpillingProjection) {
+		store, err := OpenSQLiteSpillStore(dbPath, "CustomerSpend")
+		if err != nil {
+			panic(err)
+		}
+		projection, err := NewCustomerSpendProjection(ctx, store, capacity)
+		if err != nil {
+			panic(err)
+		}
+		return store, projection
+	}
+
+	// --- Crash mid-way ---
+	fmt.Println("\n--- Crash before the final flush ---")
+	store, projection := open()
+	const crashAt = 65432
+	for i := 0; i < crashAt; i++ {
+		event := eventAt(i)
+		if _, err := projection.Apply(ctx, event, event.Position); err != nil {
+			panic(err)
+		}
+		if i%10000 == 9999 {
+			if err := projection.Flush(ctx); err != nil {
+				panic(err)
+			}
+		}
+	}
+	// Dirty entries since the last flush are lost with the process
+	lost := projection.Checkpoint().Commit - projection.Flushed().Commit
+	flushedAt := *projection.Flushed()
+	store.Close()
+	fmt.Printf("  crashed at %d with the store at %d (%d unflushed positions)\n", projection.Checkpoint().Commit, flushedAt.Commit, lost)
+	checks.Check(lost > 0, "the crash should lose unflushed state for the resume to replay")
+
+	// --- Resume ---
+	fmt.Println("\n--- Resume from the stored checkpoint ---")
+	store, projection = open()
+	defer store.Close()
+	resumedFrom := projection.Checkpoint()
+	checks.Check(resumedFrom != nil && *resumedFrom == flushedAt, "should resume from the flushed checkpoint %v, got %v", flushedAt, resumedFrom)
+
+	var before runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	started := time.Now()
+	// Redeliver from the start: Apply skips everything up to the checkpoint
+	for i := 0; i < events; i++ {
+		event := eventAt(i)
+		if _, err := projection.Apply(ctx, event, event.Position); err != nil {
+			panic(err)
+		}
+	}
+	if err := projection.Flush(ctx); err != nil {
+		panic(err)
+	}
+	var after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	stats := projection.Stats()
+	fmt.Printf("  %d events over %d customers in %s, %d in memory\n", events, len(expected), time.Since(started).Round(time.Millisecond), stats.Resident)
+	fmt.Printf("  hit rate %.1f%% (%d hits, %d misses), %d evictions, %d flushes, heap %d KiB -> %d KiB\n",
+		stats.HitRate()*100, stats.Hits, stats.Misses, stats.Evictions, stats.Flushes, before.HeapAlloc/1024, after.HeapAlloc/1024)
+
+	checks.Check(stats.Resident <= capacity, "memory should hold at most %d entities, got %d", capacity, stats.Resident)
+	checks.Check(stats.Evictions > 0 && stats.Misses > 0, "a key space of %d should spill, got %+v", len(expected), stats)
+	checks.Check(stats.HitRate() > 0.5, "hot customers should be served from memory, hit rate %.2f", stats.HitRate())
+	checks.Check(projection.Flushed() != nil && projection.Flushed().Commit == events*10, "the final flush should store the last position, got %v", projection.Flushed())
+
+	// --- Every total, from memory or disk ---
+	fmt.Println("\n--- Totals ---")
+	wrong := 0
+	for customer, total := range expected {
+		state, err := projection.Get(ctx, customer)
+		if err != nil || state == nil || state["total"] != total {
+			if wrong < 3 {
+				fmt.Printf("  %s: expected %v, got %v (%v)\n", customer, total, state, err)
+			}
+			wrong++
+		}
+	}
+	fmt.Printf("  %d customers checked, %d wrong\n", len(expected), wrong)
+	checks.Check(wrong == 0, "every customer total should survive the crash exactly once, %d did not", wrong)
+
+	missing, err := projection.Get(ctx, "customer-none")
+	checks.Check(missing == nil && err == nil, "a customer without orders should have no state, got %v (%v)", missing, err)
+
+	checks.Done()
+}
+
+// === DEMO ===
+
+// RunSpillProjection projects orders from many customer streams with room for a tenth of them in
+// memory, then restarts from the stored checkpoint
+func RunSpillProjection() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	dir, err := os.MkdirTemp("", "spill-projection")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	dbPath := filepath.Join(dir, "spill.db")
+
+	const (
+		customers = 1000
+		capacity  = 100
+	)
+	run := uuid.New().String()[:8]
+	category := "spendcustomer" + run
+	expected := make(map[string]float64)
+	for c := 0; c < customers; c++ {
+		stream := Streams.Name(category, fmt.Sprint(c))
+		var events []kurrentdb.EventData
+		for n := 0; n <= c%3; n++ {
+			amount := float64(10 * (n + 1))
+			expected[stream] += amount
+			data, _ := json.Marshal(map[string]interface{}{"amount": amount})
+			events = append(events, kurrentdb.EventData{
+				EventID:     uuid.New(),
+				EventType:   "OrderPlaced",
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        data,
+			})
+		}
+		if _, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, events...); err != nil {
+			panic(err)
+		}
+	}
+	fmt.Printf("Wrote orders for %d customers in category %s\n", customers, category)
+
+	project := func(stopAfter int) (*SpillingProjection, SpillStats) {
+		spill, err := OpenSQLiteSpillStore(dbPath, "CustomerSpend")
+		if err != nil {
+			panic(err)
+		}
+		defer spill.Close()
+		projection, err := NewCustomerSpendProjection(ctx, spill, capacity)
+		if err != nil {
+			panic(err)
+		}
+
+		options := kurrentdb.SubscribeToAllOptions{
+			From:   kurrentdb.Start{},
+			Filter: &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{category + "-"}},
+		}
+		if flushed := projection.Flushed(); flushed != nil {
+			options.From = *flushed
+		}
+		subscription, err := client.SubscribeToAll(ctx, options)
+		if err != nil {
+			panic(err)
+		}
+		defer subscription.Close()
+
+		applied := 0
+		for applied < stopAfter {
+			message := subscription.Recv()
+			if message.SubscriptionDropped != nil {
+				panic(message.SubscriptionDropped.Error)
+			}
+			if message.EventAppeared == nil {
+				continue
+			}
+			event := Resolve(message.EventAppeared, false)
+			handled, err := projection.Apply(ctx, event, event.Position)
+			if err != nil {
+				panic(err)
+			}
+			if !handled {
+				continue
+			}
+			// A crash replays at most the orders since the last flush
+			if applied++; applied%250 == 0 {
+				if err := projection.Flush(ctx); err != nil {
+					panic(err)
+				}
+			}
+		}
+		if err := projection.Flush(ctx); err != nil {
+			panic(err)
+		}
+		return projection, projection.Stats()
+	}
+
+	total := 0
+	for c := 0; c < customers; c++ {
+		total += c%3 + 1
+	}
+
+	// === FIRST RUN ===
+	fmt.Println("\n=== First run: stop after 1000 orders ===")
+	_, first := project(1000)
+	fmt.Printf("  hit rate %.1f%%, %d evictions, %d flushes, %d in memory\n", first.HitRate()*100, first.Evictions, first.Flushes, first.Resident)
+
+	// === RESTART ===
+	fmt.Printf("\n=== Restart: the remaining %d orders ===\n", total-1000)
+	projection, second := project(total - 1000)
+	fmt.Printf("  hit rate %.1f%%, %d evictions, %d flushes, %d in memory\n", second.HitRate()*100, second.Evictions, second.Flushes, second.Resident)
+
+	spill, err := OpenSQLiteSpillStore(dbPath, "CustomerSpend")
+	if err != nil {
+		panic(err)
+	}
+	defer spill.Close()
+	reopened, err := NewCustomerSpendProjection(ctx, spill, capacity)
+	if err != nil {
+		panic(err)
+	}
+	wrong := 0
+	for stream, amount := range expected {
+		state, err := reopened.Get(ctx, stream)
+		if err != nil || state == nil || state["total"] != amount {
+			wrong++
+		}
+	}
+	fmt.Printf("\nChecked %d customer totals from disk, %d wrong\n", len(expected), wrong)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if first.Resident > capacity || second.Resident > capacity {
+		fmt.Printf("FAIL: At most %d customers should be in memory, got %d and %d\n", capacity, first.Resident, second.Resident)
+		passed = false
+	}
+	if first.Evictions == 0 {
+		fmt.Println("FAIL: A key space ten times the capacity should spill to disk")
+		passed = false
+	}
+	if wrong != 0 {
+		fmt.Printf("FAIL: Every customer total should be applied exactly once across the restart, %d were not\n", wrong)
+		passed = false
+	}
+	if flushed := reopened.Flushed(); flushed == nil || *flushed != *projection.Checkpoint() {
+		fmt.Printf("FAIL: The stored checkpoint should be the last applied position, got %v\n", flushed)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll spill projection tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
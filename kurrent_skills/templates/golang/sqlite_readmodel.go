@@ -0,0 +1,328 @@
+// KurrentDB Go Client Example - Projection into a SQLite read model
+// Demonstrates: Upserting order rows with the checkpoint in the same transaction for exactly-once updates
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+	_ "modernc.org/sqlite"
+)
+
+// === SQLITE READ MODEL ===
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS orders (
+	stream_id   TEXT PRIMARY KEY,
+	order_id    TEXT NOT NULL,
+	customer_id TEXT NOT NULL,
+	amount      REAL NOT NULL,
+	status      TEXT NOT NULL,
+	items       TEXT NOT NULL DEFAULT '[]',
+	shipped_at  TEXT
+);
+CREATE TABLE IF NOT EXISTS checkpoints (
+	projection       TEXT PRIMARY KEY,
+	commit_position  INTEGER NOT NULL,
+	prepare_position INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS fencing_tokens (
+	projection TEXT PRIMARY KEY,
+	token      INTEGER NOT NULL,
+	owner      TEXT NOT NULL
+);`
+
+// SQLiteOrderReadModel keeps one row per order and the projection checkpoint in one database,
+// updating both in a single transaction per event
+type SQLiteOrderReadModel struct {
+	db   *sql.DB
+	name string
+	// token is what Apply writes with, once WithToken sets it; see Acquire
+	token FencingToken
+
+	// beforeCommit lets checks simulate a crash after the row update but before commit
+	beforeCommit func(Envelope) error
+}
+
+// OpenSQLiteOrderReadModel opens (or creates) the database and its schema
+func OpenSQLiteOrderReadModel(path, name string) (*SQLiteOrderReadModel, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows one writer; a single connection avoids "database is locked" between our own transactions
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create schema: %w", err)
+	}
+	return &SQLiteOrderReadModel{db: db, name: name}, nil
+}
+
+func (r *SQLiteOrderReadModel) Close() error {
+	return r.db.Close()
+}
+
+// Checkpoint returns the last applied position, or nil on first run
+func (r *SQLiteOrderReadModel) Checkpoint(ctx context.Context) (*kurrentdb.Position, error) {
+	var position kurrentdb.Position
+	err := r.db.QueryRowContext(ctx,
+		`SELECT commit_position, prepare_position FROM checkpoints WHERE projection = ?`, r.name).
+		Scan(&position.Commit, &position.Prepare)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &position, nil
+}
+
+// Apply updates the order row and advances the checkpoint in one transaction (see TransactionalSink).
+// Events at or before the stored checkpoint are ignored, so redelivery never double-applies. Once
+// fencing tokens have been issued, it fails with ErrFenced unless it writes with the latest.
+func (r *SQLiteOrderReadModel) Apply(ctx context.Context, envelope Envelope, position kurrentdb.Position) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.checkFence(ctx, tx); err != nil {
+		return err
+	}
+
+	// Events of one append share a commit position, so the prepare position decides between them
+	var stored kurrentdb.Position
+	err = tx.QueryRowContext(ctx, `SELECT commit_position, prepare_position FROM checkpoints WHERE projection = ?`, r.name).
+		Scan(&stored.Commit, &stored.Prepare)
+	if err == nil && !positionAfter(position, stored) {
+		return nil
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	handled, err := r.applyEvent(ctx, tx, envelope)
+	if err != nil || !handled {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO checkpoints (projection, commit_position, prepare_position) VALUES (?, ?, ?)
+		ON CONFLICT(projection) DO UPDATE SET commit_position = excluded.commit_position, prepare_position = excluded.prepare_position`,
+		r.name, position.Commit, position.Prepare)
+	if err != nil {
+		return err
+	}
+
+	if r.beforeCommit != nil {
+		if err := r.beforeCommit(envelope); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLiteOrderReadModel) applyEvent(ctx context.Context, tx *sql.Tx, event Envelope) (bool, error) {
+	switch event.EventType {
+	case "OrderCreated":
+		var data ProjectionOrderCreated
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return false, err
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO orders (stream_id, order_id, customer_id, amount, status) VALUES (?, ?, ?, ?, 'created')
+			ON CONFLICT(stream_id) DO UPDATE SET order_id = excluded.order_id, customer_id = excluded.customer_id,
+				amount = excluded.amount, status = excluded.status`,
+			event.StreamID, data.OrderID, data.CustomerID, data.Amount)
+		return err == nil, err
+
+	case "ItemAdded":
+		var data ProjectionItemAdded
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return false, err
+		}
+		var rawItems string
+		err := tx.QueryRowContext(ctx, `SELECT items FROM orders WHERE stream_id = ?`, event.StreamID).Scan(&rawItems)
+		if err != nil {
+			return false, fmt.Errorf("ItemAdded for unknown order %s: %w", event.StreamID, err)
+		}
+		var items []string
+		json.Unmarshal([]byte(rawItems), &items)
+		updated, _ := json.Marshal(append(items, data.Item))
+
+		_, err = tx.ExecContext(ctx, `UPDATE orders SET items = ?, amount = amount + ? WHERE stream_id = ?`,
+			string(updated), data.Price, event.StreamID)
+		return err == nil, err
+
+	case "OrderShipped":
+		var data ProjectionOrderShipped
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return false, err
+		}
+		_, err := tx.ExecContext(ctx, `UPDATE orders SET status = 'shipped', shipped_at = ? WHERE stream_id = ?`,
+			data.ShippedAt, event.StreamID)
+		return err == nil, err
+
+	case "OrderCompleted":
+		_, err := tx.ExecContext(ctx, `UPDATE orders SET status = 'completed' WHERE stream_id = ?`, event.StreamID)
+		return err == nil, err
+	}
+
+	return false, nil
+}
+
+// OrderRow is one row of the orders table
+type OrderRow struct {
+	StreamID   string
+	CustomerID string
+	Amount     float64
+	Status     string
+	Items      []string
+}
+
+func (r *SQLiteOrderReadModel) Get(ctx context.Context, streamID string) (*OrderRow, error) {
+	row := OrderRow{StreamID: streamID}
+	var rawItems string
+	err := r.db.QueryRowContext(ctx, `SELECT customer_id, amount, status, items FROM orders WHERE stream_id = ?`, streamID).
+		Scan(&row.CustomerID, &row.Amount, &row.Status, &rawItems)
+	if err != nil {
+		return nil, err
+	}
+	json.Unmarshal([]byte(rawItems), &row.Items)
+	return &row, nil
+}
+
+// RunSQLiteReadModel demonstrates a restart resuming from the persisted checkpoint with no double-apply
+func RunSQLiteReadModel() {
+	ctx := context.Background()
+
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	dir, err := os.MkdirTemp("", "sqlite-readmodel")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	dbPath := filepath.Join(dir, "readmodel.db")
+
+	orderID := uuid.New().String()
+	streamName := Streams.Name("order", orderID)
+
+	makeEvent := func(eventType string, data interface{}) kurrentdb.EventData {
+		jsonData, _ := json.Marshal(data)
+		return kurrentdb.EventData{
+			EventID:     uuid.New(),
+			ContentType: kurrentdb.ContentTypeJson,
+			EventType:   eventType,
+			Data:        jsonData,
+		}
+	}
+
+	// === FIRST RUN ===
+	fmt.Println("\n=== First run ===")
+
+	_, err = client.AppendToStream(ctx, streamName, kurrentdb.AppendToStreamOptions{},
+		makeEvent("OrderCreated", ProjectionOrderCreated{OrderID: orderID, CustomerID: "cust-1", Amount: 100}),
+		makeEvent("ItemAdded", ProjectionItemAdded{Item: "Widget", Price: 25}))
+	if err != nil {
+		panic(err)
+	}
+
+	readModel, err := OpenSQLiteOrderReadModel(dbPath, "OrderSummary")
+	if err != nil {
+		panic(err)
+	}
+
+	orderStreams := kurrentdb.SubscribeToAllOptions{
+		Filter: &kurrentdb.SubscriptionFilter{
+			Type:     kurrentdb.StreamFilterType,
+			Prefixes: []string{"order-"},
+		},
+	}
+
+	seen := 0
+	err = NewTransactionalRunner(client, readModel, orderStreams).Run(ctx, func(evt Envelope) bool {
+		if evt.StreamID == streamName {
+			seen++
+		}
+		return seen >= 2
+	})
+	if err != nil {
+		panic(err)
+	}
+	readModel.Close()
+	fmt.Println("  Stopped after 2 events (simulated restart)")
+
+	// === EVENTS WRITTEN WHILE DOWN ===
+	_, err = client.AppendToStream(ctx, streamName, kurrentdb.AppendToStreamOptions{},
+		makeEvent("ItemAdded", ProjectionItemAdded{Item: "Gadget", Price: 30}),
+		makeEvent("OrderShipped", ProjectionOrderShipped{ShippedAt: "2024-01-15T10:00:00Z"}))
+	if err != nil {
+		panic(err)
+	}
+
+	// === SECOND RUN ===
+	fmt.Println("\n=== Second run ===")
+
+	readModel, err = OpenSQLiteOrderReadModel(dbPath, "OrderSummary")
+	if err != nil {
+		panic(err)
+	}
+	defer readModel.Close()
+
+	seenAfterRestart := 0
+	err = NewTransactionalRunner(client, readModel, orderStreams).Run(ctx, func(evt Envelope) bool {
+		if evt.StreamID == streamName {
+			seenAfterRestart++
+		}
+		return evt.StreamID == streamName && evt.EventType == "OrderShipped"
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	row, err := readModel.Get(ctx, streamName)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("\nRow: status=%s amount=%.2f items=%v\n", row.Status, row.Amount, row.Items)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if seenAfterRestart != 2 {
+		fmt.Printf("FAIL: Second run should only see the 2 new events, saw %d\n", seenAfterRestart)
+		passed = false
+	}
+	if row.Amount != 155 || len(row.Items) != 2 {
+		fmt.Printf("FAIL: Expected amount 155 with 2 items, got %.2f with %v\n", row.Amount, row.Items)
+		passed = false
+	}
+	if row.Status != "shipped" {
+		fmt.Printf("FAIL: Expected status shipped, got %s\n", row.Status)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll SQLite read model tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
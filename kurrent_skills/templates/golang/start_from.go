@@ -0,0 +1,170 @@
+// KurrentDB Go Client Example - Subscription starting point from configuration
+// Demonstrates: Parsing "start", "end", "position:commit/prepare" and "revision:n" into subscribe options
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === PARSER ===
+
+// ErrInvalidStartFrom wraps every ParseStartFrom validation error
+var ErrInvalidStartFrom = errors.New("invalid start position")
+
+// ParseStartFrom parses a configured starting point:
+//
+//	start                        from the beginning
+//	end                          live events only
+//	position:<commit>/<prepare>  a $all position, e.g. from a saved checkpoint
+//	revision:<n>                 a stream revision
+//
+// It returns kurrentdb.Start{}, kurrentdb.End{}, kurrentdb.Position or kurrentdb.StreamRevision.
+// Use AllFrom or StreamFrom to check the value fits the subscription type.
+func ParseStartFrom(s string) (interface{}, error) {
+	value := strings.TrimSpace(s)
+
+	switch strings.ToLower(value) {
+	case "start":
+		return kurrentdb.Start{}, nil
+	case "end":
+		return kurrentdb.End{}, nil
+	case "":
+		return nil, fmt.Errorf("%w: empty value, expected start, end, position:<commit>/<prepare> or revision:<n>", ErrInvalidStartFrom)
+	}
+
+	kind, arg, found := strings.Cut(value, ":")
+	if !found {
+		return nil, fmt.Errorf("%w: %q, expected start, end, position:<commit>/<prepare> or revision:<n>", ErrInvalidStartFrom, s)
+	}
+
+	switch strings.ToLower(kind) {
+	case "position":
+		commitText, prepareText, found := strings.Cut(arg, "/")
+		if !found {
+			return nil, fmt.Errorf("%w: %q, position needs both parts as <commit>/<prepare>", ErrInvalidStartFrom, s)
+		}
+		commit, err := strconv.ParseUint(strings.TrimSpace(commitText), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q, commit position %q is not an unsigned integer", ErrInvalidStartFrom, s, commitText)
+		}
+		prepare, err := strconv.ParseUint(strings.TrimSpace(prepareText), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q, prepare position %q is not an unsigned integer", ErrInvalidStartFrom, s, prepareText)
+		}
+		if prepare > commit {
+			return nil, fmt.Errorf("%w: %q, prepare position can't be after the commit position", ErrInvalidStartFrom, s)
+		}
+		return kurrentdb.Position{Commit: commit, Prepare: prepare}, nil
+
+	case "revision":
+		revision, err := strconv.ParseUint(strings.TrimSpace(arg), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q, revision %q is not an unsigned integer", ErrInvalidStartFrom, s, arg)
+		}
+		return kurrentdb.Revision(revision), nil
+	}
+
+	return nil, fmt.Errorf("%w: %q, unknown kind %q", ErrInvalidStartFrom, s, kind)
+}
+
+// AllFrom narrows a parsed value to a $all starting point; revisions are rejected
+func AllFrom(from interface{}) (kurrentdb.AllPosition, error) {
+	if _, isRevision := from.(kurrentdb.StreamRevision); isRevision {
+		return nil, fmt.Errorf("%w: a revision can't be used for a $all subscription", ErrInvalidStartFrom)
+	}
+	position, ok := from.(kurrentdb.AllPosition)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T is not a $all position", ErrInvalidStartFrom, from)
+	}
+	return position, nil
+}
+
+// StreamFrom narrows a parsed value to a stream starting point; $all positions are rejected
+func StreamFrom(from interface{}) (kurrentdb.StreamPosition, error) {
+	if _, isPosition := from.(kurrentdb.Position); isPosition {
+		return nil, fmt.Errorf("%w: a $all position can't be used for a stream subscription", ErrInvalidStartFrom)
+	}
+	position, ok := from.(kurrentdb.StreamPosition)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T is not a stream position", ErrInvalidStartFrom, from)
+	}
+	return position, nil
+}
+
+// AllFromEnv reads a $all starting point from the named environment variable, or returns
+// fallback when it is unset
+func AllFromEnv(name string, fallback kurrentdb.AllPosition) (kurrentdb.AllPosition, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return fallback, nil
+	}
+	from, err := ParseStartFrom(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	position, err := AllFrom(from)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return position, nil
+}
+
+// RunStartFromChecks verifies parsing and validation without a server
+func RunStartFromChecks() {
+	checks := newChecker("start position")
+
+	valid := map[string]interface{}{
+		"start":                kurrentdb.Start{},
+		" END ":                kurrentdb.End{},
+		"position:1024/1000":   kurrentdb.Position{Commit: 1024, Prepare: 1000},
+		"Position: 42 / 42":    kurrentdb.Position{Commit: 42, Prepare: 42},
+		"revision:0":           kurrentdb.Revision(0),
+		"revision:18446744073": kurrentdb.Revision(18446744073),
+	}
+	for input, expected := range valid {
+		from, err := ParseStartFrom(input)
+		checks.Check(err == nil && from == expected, "ParseStartFrom(%q) = %v, %v; want %v", input, from, err, expected)
+	}
+
+	invalid := []string{
+		"",
+		"beginning",
+		"position:",
+		"position:1024",
+		"position:abc/1",
+		"position:1/-1",
+		"position:1000/1024",
+		"revision:",
+		"revision:-3",
+		"revision:1.5",
+		"offset:5",
+	}
+	for _, input := range invalid {
+		_, err := ParseStartFrom(input)
+		checks.Check(errors.Is(err, ErrInvalidStartFrom), "ParseStartFrom(%q) should fail with ErrInvalidStartFrom, got %v", input, err)
+		if err != nil {
+			fmt.Printf("  %-22q -> %v\n", input, err)
+		}
+	}
+
+	revision, _ := ParseStartFrom("revision:5")
+	_, err := AllFrom(revision)
+	checks.Check(errors.Is(err, ErrInvalidStartFrom), "AllFrom should reject a revision, got %v", err)
+
+	position, _ := ParseStartFrom("position:10/10")
+	_, err = StreamFrom(position)
+	checks.Check(errors.Is(err, ErrInvalidStartFrom), "StreamFrom should reject a $all position, got %v", err)
+
+	start, _ := ParseStartFrom("start")
+	_, allErr := AllFrom(start)
+	_, streamErr := StreamFrom(start)
+	checks.Check(allErr == nil && streamErr == nil, "start should fit both subscription types, got %v / %v", allErr, streamErr)
+
+	checks.Done()
+}
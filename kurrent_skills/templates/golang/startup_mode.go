@@ -0,0 +1,173 @@
+// KurrentDB Go Client Example - Choosing where a subscription starts: replay, checkpoint or live
+// Demonstrates: A notification projection that only reacts to events after startup, next to replaying and resuming
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// RunStartupMode runs the same handler over an order stream with each StartupMode and compares
+// which events it sees
+func RunStartupMode() {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	orderStream := Streams.Name("order", uuid.New().String())
+	sequence := 0
+	ship := func(count int) {
+		for i := 0; i < count; i++ {
+			sequence++
+			_, err := client.AppendToStream(ctx, orderStream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+				EventID:     uuid.New(),
+				EventType:   "OrderShipped",
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        []byte(fmt.Sprintf(`{"parcel":%d}`, sequence)),
+			})
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "startup-mode")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// === HISTORY ===
+	ship(3)
+	fmt.Printf("Shipped parcels 1-3 to %s before any subscriber started\n", orderStream)
+
+	// subscribe builds a subscription to the order stream in mode, checkpointing to checkpoint
+	subscribe := func(mode StartupMode, checkpoint string) *MeteredSubscription {
+		subscription := NewMeteredSubscription(client, kurrentdb.SubscribeToAllOptions{
+			Filter: &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{orderStream}},
+		})
+		subscription.Startup = mode
+		subscription.Checkpoints = FileCheckpoint{Path: filepath.Join(dir, checkpoint+".json")}
+		return subscription
+	}
+
+	// run runs subscription, calls during once it has caught up, then stops once want parcels
+	// have arrived (or after a timeout). It returns the parcels notified.
+	run := func(name string, subscription *MeteredSubscription, during func(), want int) []int {
+		store := subscription.Checkpoints.(FileCheckpoint)
+
+		var mu sync.Mutex
+		var notified []int
+		runCtx, stop := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() {
+			done <- subscription.Run(runCtx, func(event *kurrentdb.ResolvedEvent) error {
+				var parcel int
+				fmt.Sscanf(string(Resolve(event, false).Data), `{"parcel":%d}`, &parcel)
+				mu.Lock()
+				defer mu.Unlock()
+				notified = append(notified, parcel)
+				return nil
+			})
+		}()
+
+		wait := func(ready func() bool) {
+			for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline) && !ready(); time.Sleep(50 * time.Millisecond) {
+			}
+		}
+		// Caught-up saves a checkpoint, so once the file exists a LiveOnly run has pinned its start
+		// and new events reach it
+		wait(func() bool {
+			_, err := os.Stat(store.Path)
+			return err == nil
+		})
+		if during != nil {
+			during()
+		}
+		wait(func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(notified) >= want
+		})
+		// Give anything unexpected a moment to arrive
+		time.Sleep(200 * time.Millisecond)
+		stop()
+		if err := <-done; err != nil {
+			panic(err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Printf("  %-26s notified parcels %v\n", name, notified)
+		return notified
+	}
+
+	// === LIVE ONLY ===
+	fmt.Println("\n=== Notifications: LiveOnly ===")
+	live := run("LiveOnly", subscribe(LiveOnly, "notifications"), func() { ship(2) }, 2)
+
+	// === REPLAY / CHECKPOINT ===
+	fmt.Println("\n=== Read model: FromCheckpoint and ReplayAll ===")
+	firstRun := run("FromCheckpoint, no checkpoint", subscribe(FromCheckpoint, "read-model"), nil, 5)
+	resumed := run("FromCheckpoint, restarted", subscribe(FromCheckpoint, "read-model"), func() { ship(1) }, 1)
+	replayed := run("ReplayAll, with checkpoint", subscribe(ReplayAll, "read-model"), nil, 6)
+
+	// === REUSED SUBSCRIPTION ===
+	// A second Run on the same MeteredSubscription starts from its own startup mode, not from where
+	// the first Run's Metrics left off
+	fmt.Println("\n=== Reused subscription: LiveOnly, then ReplayAll ===")
+	reused := subscribe(LiveOnly, "reused")
+	reusedLive := run("LiveOnly, first Run", reused, func() { ship(1) }, 1)
+	reused.Startup = ReplayAll
+	reusedReplay := run("ReplayAll, second Run", reused, nil, 7)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if !slices.Equal(live, []int{4, 5}) {
+		fmt.Printf("FAIL: LiveOnly should notify only parcels shipped after startup [4 5], got %v\n", live)
+		passed = false
+	}
+	if !slices.Equal(firstRun, []int{1, 2, 3, 4, 5}) {
+		fmt.Printf("FAIL: FromCheckpoint without a checkpoint should replay everything, got %v\n", firstRun)
+		passed = false
+	}
+	if !slices.Equal(resumed, []int{6}) {
+		fmt.Printf("FAIL: FromCheckpoint should resume after the saved checkpoint [6], got %v\n", resumed)
+		passed = false
+	}
+	if !slices.Equal(replayed, []int{1, 2, 3, 4, 5, 6}) {
+		fmt.Printf("FAIL: ReplayAll should ignore the checkpoint and replay everything, got %v\n", replayed)
+		passed = false
+	}
+	if !slices.Equal(reusedLive, []int{7}) {
+		fmt.Printf("FAIL: LiveOnly on a fresh subscription should notify only parcel 7, got %v\n", reusedLive)
+		passed = false
+	}
+	if !slices.Equal(reusedReplay, []int{1, 2, 3, 4, 5, 6, 7}) {
+		fmt.Printf("FAIL: ReplayAll on a reused subscription should replay everything, not resume, got %v\n", reusedReplay)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll startup mode tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,544 @@
+// KurrentDB Go Client Example - Exporting projected state as rows
+// Demonstrates: Streaming a projection's state to CSV or JSON Lines through a column schema that copes with differently shaped states
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === SCHEMA ===
+
+// ColumnType is how an exported column's values are checked and formatted
+type ColumnType int
+
+const (
+	// ColumnString takes strings, and formats other scalars as text
+	ColumnString ColumnType = iota
+	// ColumnNumber takes numbers, Money and json.Number, and strings that parse as numbers, as older
+	// events sometimes wrote amounts
+	ColumnNumber
+	ColumnBool
+	// ColumnTime takes RFC 3339 strings and time.Time, and writes them in UTC
+	ColumnTime
+	// ColumnJSON writes any value, e.g. a list of items, as JSON text
+	ColumnJSON
+)
+
+// ExportColumn maps one value of a state to one column
+type ExportColumn struct {
+	Name string
+	// Field is the path of the value in the state, dot-separated for nested maps, e.g.
+	// "shipping.carrier"; ExportKeyField is the stream ID, or the key with KeyBy
+	Field string
+	// Value derives the value instead, e.g. the number of items; found false means it is missing
+	Value func(key string, state map[string]interface{}) (value interface{}, found bool)
+	Type  ColumnType
+	// Required rejects states without the value; otherwise the column is left empty, or set to
+	// Default when there is one
+	Required bool
+	Default  interface{}
+}
+
+// ExportKeyField is the Field of a column holding each state's key
+const ExportKeyField = "$key"
+
+// ExportSchema is the columns an export writes, in order. States don't all have the same shape:
+// older events may lack fields or write them with other types, so each column says how to read its
+// value, and SkipInvalid decides what happens to a state that doesn't fit.
+type ExportSchema struct {
+	Columns []ExportColumn
+	// SkipInvalid leaves out states missing a required value or holding one of the wrong type, and
+	// reports them in ExportResult.Skipped; otherwise the first one stops the export with
+	// ErrInvalidRow
+	SkipInvalid bool
+}
+
+// ErrInvalidRow is returned, wrapped, for a state that doesn't fit the schema
+var ErrInvalidRow = errors.New("state does not fit the export schema")
+
+// row converts one state to a row of values, formatted for the column types
+func (s ExportSchema) row(key string, state map[string]interface{}) ([]interface{}, error) {
+	values := make([]interface{}, len(s.Columns))
+	for i, column := range s.Columns {
+		raw, found := column.lookup(key, state)
+		if !found || raw == nil {
+			if column.Required {
+				return nil, fmt.Errorf("%w: %s has no %s", ErrInvalidRow, key, column.Name)
+			}
+			raw = column.Default
+		}
+		if raw == nil {
+			continue
+		}
+		value, err := column.convert(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s column %s: %w", ErrInvalidRow, key, column.Name, err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func (c ExportColumn) lookup(key string, state map[string]interface{}) (interface{}, bool) {
+	if c.Value != nil {
+		return c.Value(key, state)
+	}
+	if c.Field == ExportKeyField {
+		return key, true
+	}
+	var value interface{} = state
+	for _, part := range strings.Split(c.Field, ".") {
+		nested, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = nested[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// convert checks raw against the column type, returning a string, float64, json.Number, bool,
+// time.Time or, for ColumnJSON, json.RawMessage
+func (c ExportColumn) convert(raw interface{}) (interface{}, error) {
+	switch c.Type {
+	case ColumnNumber:
+		switch v := raw.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case Money:
+			// Keep the exact decimal rather than round-tripping it through float64
+			return json.Number(v.String()), nil
+		case json.Number:
+			return v, nil
+		case string:
+			if _, err := strconv.ParseFloat(v, 64); err == nil {
+				return json.Number(v), nil
+			}
+		}
+	case ColumnBool:
+		if v, ok := raw.(bool); ok {
+			return v, nil
+		}
+	case ColumnTime:
+		switch v := raw.(type) {
+		case time.Time:
+			return v.UTC(), nil
+		case string:
+			if at, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return at.UTC(), nil
+			}
+		}
+	case ColumnJSON:
+		encoded, err := json.Marshal(raw)
+		return json.RawMessage(encoded), err
+	default:
+		switch v := raw.(type) {
+		case string:
+			return v, nil
+		case float64, bool, json.Number, Money:
+			return fmt.Sprint(v), nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %T %v", raw, raw)
+}
+
+// === FORMATS ===
+
+// RowWriter writes exported rows in one format. WriteHeader is called once, before the rows; each
+// value in a row is nil for an empty cell, or a string, float64, json.Number, bool, time.Time or
+// json.RawMessage. Close flushes what is buffered but doesn't close the underlying writer.
+//
+// CSV and JSON Lines are built in. For Parquet, implement RowWriter over a Parquet library, mapping
+// the column types to its schema; the template leaves it out rather than add the dependency.
+type RowWriter interface {
+	WriteHeader(columns []ExportColumn) error
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// csvRowWriter writes RFC 4180 CSV with a header line
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func NewCSVRowWriter(w io.Writer) RowWriter {
+	return &csvRowWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvRowWriter) WriteHeader(columns []ExportColumn) error {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	return c.w.Write(names)
+}
+
+func (c *csvRowWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case time.Time:
+			record[i] = v.Format(time.RFC3339Nano)
+		case json.RawMessage:
+			record[i] = string(v)
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvRowWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonLinesRowWriter writes one JSON object per row, keyed by column name, with typed values
+type jsonLinesRowWriter struct {
+	w       *bufio.Writer
+	columns []string
+}
+
+func NewJSONLinesRowWriter(w io.Writer) RowWriter {
+	return &jsonLinesRowWriter{w: bufio.NewWriter(w)}
+}
+
+func (j *jsonLinesRowWriter) WriteHeader(columns []ExportColumn) error {
+	for _, column := range columns {
+		j.columns = append(j.columns, column.Name)
+	}
+	return nil
+}
+
+func (j *jsonLinesRowWriter) WriteRow(values []interface{}) error {
+	row := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		row[j.columns[i]] = value
+	}
+	encoded, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	_, err = j.w.Write(append(encoded, '\n'))
+	return err
+}
+
+func (j *jsonLinesRowWriter) Close() error {
+	return j.w.Flush()
+}
+
+// === EXPORT ===
+
+// ExportSkip is a state left out of an export, and why
+type ExportSkip struct {
+	Key string
+	Err error
+}
+
+// ExportResult counts what an export wrote
+type ExportResult struct {
+	Rows    int
+	Skipped []ExportSkip
+}
+
+// States yields each stream's state in key order, each a shallow copy taken under the lock. The
+// keys are read up front, but each state only when it is reached, so an export holds one row at a
+// time and Apply carries on between rows: a long export sees states from different moments, and
+// skips streams removed or added meanwhile.
+func (p *Projection) States() iter.Seq2[string, map[string]interface{}] {
+	return func(yield func(string, map[string]interface{}) bool) {
+		p.mu.Lock()
+		keys := slices.Sorted(maps.Keys(p.State))
+		p.mu.Unlock()
+
+		for _, key := range keys {
+			p.mu.Lock()
+			state, ok := p.State[key]
+			state = maps.Clone(state)
+			p.mu.Unlock()
+			if ok && !yield(key, state) {
+				return
+			}
+		}
+	}
+}
+
+// ExportStates writes a row through w for every state from states that fits schema, one at a time,
+// then closes w. It stops with ctx's error if ctx ends, and with w's error if a write fails.
+func ExportStates(ctx context.Context, states iter.Seq2[string, map[string]interface{}], schema ExportSchema, w RowWriter) (ExportResult, error) {
+	var result ExportResult
+	if err := w.WriteHeader(schema.Columns); err != nil {
+		return result, fmt.Errorf("write header: %w", err)
+	}
+	for key, state := range states {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		values, err := schema.row(key, state)
+		if err != nil && schema.SkipInvalid {
+			result.Skipped = append(result.Skipped, ExportSkip{Key: key, Err: err})
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		if err := w.WriteRow(values); err != nil {
+			return result, fmt.Errorf("write %s: %w", key, err)
+		}
+		result.Rows++
+	}
+	if err := w.Close(); err != nil {
+		return result, fmt.Errorf("flush: %w", err)
+	}
+	return result, nil
+}
+
+// OrderSummaryExportSchema is the columns of an order summary export. Orders projected before
+// customers were recorded have an empty customerId; summaries without an amount are skipped.
+var OrderSummaryExportSchema = ExportSchema{
+	Columns: []ExportColumn{
+		{Name: "stream", Field: ExportKeyField, Type: ColumnString, Required: true},
+		{Name: "orderId", Field: "orderId", Type: ColumnString},
+		{Name: "customerId", Field: "customerId", Type: ColumnString},
+		{Name: "status", Field: "status", Type: ColumnString, Default: "created"},
+		{Name: "amount", Field: "amount", Type: ColumnNumber, Required: true},
+		{Name: "itemCount", Type: ColumnNumber, Value: func(key string, state map[string]interface{}) (interface{}, bool) {
+			items, ok := state["items"]
+			if !ok {
+				return 0, true
+			}
+			return len(stringSlice(items)), true
+		}},
+		{Name: "items", Field: "items", Type: ColumnJSON},
+		{Name: "shippedAt", Field: "shippedAt", Type: ColumnTime},
+	},
+	SkipInvalid: true,
+}
+
+// === CHECKS ===
+
+// RunStateExportChecks exports hand-built order summaries of several shapes. No server required.
+func RunStateExportChecks() {
+	checks := newChecker("state export")
+
+	ctx := context.Background()
+	orders := NewOrderSummaryProjection()
+	orders.State = map[string]map[string]interface{}{
+		"order-1": {"orderId": "1", "customerId": "cust-1", "amount": 125.5, "status": "shipped", "items": []string{"Widget", `Gadget, "large"`}, "shippedAt": "2024-01-15T11:00:00+01:00"},
+		// Projected by an older version: no customer, no status, the amount as a string
+		"order-2": {"orderId": "2", "amount": "80", "items": []interface{}{"Gizmo"}},
+		// No amount: skipped
+		"order-3": {"orderId": "3", "status": "created"},
+		// An amount of the wrong type: skipped
+		"order-4": {"orderId": "4", "amount": true},
+		"order-5": {"orderId": "5", "amount": Money(1999), "status": "created", "items": []string{}},
+	}
+
+	fmt.Println("\n--- CSV ---")
+	var out strings.Builder
+	result, err := ExportStates(ctx, orders.States(), OrderSummaryExportSchema, NewCSVRowWriter(&out))
+	fmt.Print(out.String())
+	records, parseErr := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	checks.Check(err == nil && parseErr == nil && result.Rows == 3 && len(records) == 4, "3 rows should be written after the header, got %d (%v, %v)", result.Rows, err, parseErr)
+	checks.Check(len(records) == 4 && strings.Join(records[0], ",") == "stream,orderId,customerId,status,amount,itemCount,items,shippedAt", "unexpected header %v", records)
+	if len(records) == 4 {
+		checks.Check(slices.Equal(records[1], []string{"order-1", "1", "cust-1", "shipped", "125.5", "2", `["Widget","Gadget, \"large\""]`, "2024-01-15T10:00:00Z"}),
+			"order-1 should be quoted and in UTC, got %q", records[1])
+		checks.Check(slices.Equal(records[2], []string{"order-2", "2", "", "created", "80", "1", `["Gizmo"]`, ""}),
+			"missing values should be empty or default, got %q", records[2])
+		checks.Check(records[3][4] == "19.99" && records[3][5] == "0", "Money should export exactly, got %q", records[3])
+	}
+	var skipped []string
+	for _, skip := range result.Skipped {
+		skipped = append(skipped, skip.Key)
+		checks.Check(errors.Is(skip.Err, ErrInvalidRow), "skips should wrap ErrInvalidRow, got %v", skip.Err)
+	}
+	fmt.Printf("  skipped: %v\n", result.Skipped)
+	checks.Check(slices.Equal(skipped, []string{"order-3", "order-4"}), "states that don't fit should be skipped, got %v", skipped)
+
+	fmt.Println("\n--- Strict ---")
+	strict := OrderSummaryExportSchema
+	strict.SkipInvalid = false
+	out.Reset()
+	result, err = ExportStates(ctx, orders.States(), strict, NewCSVRowWriter(&out))
+	fmt.Printf("  %v\n", err)
+	checks.Check(errors.Is(err, ErrInvalidRow) && result.Rows == 2, "a strict export should stop at order-3, got %d rows (%v)", result.Rows, err)
+
+	fmt.Println("\n--- JSON Lines ---")
+	out.Reset()
+	schema := ExportSchema{Columns: []ExportColumn{
+		{Name: "order", Field: ExportKeyField},
+		{Name: "amount", Field: "amount", Type: ColumnNumber},
+		{Name: "shipped", Type: ColumnBool, Value: func(key string, state map[string]interface{}) (interface{}, bool) {
+			return state["status"] == "shipped", true
+		}},
+		{Name: "shippedAt", Field: "shippedAt", Type: ColumnTime},
+	}, SkipInvalid: true}
+	result, err = ExportStates(ctx, orders.States(), schema, NewJSONLinesRowWriter(&out))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	fmt.Printf("  %s\n", lines[0])
+	checks.Check(err == nil && len(lines) == 4 && len(result.Skipped) == 1, "every state but order-4 should be a line, got %d (%v)", len(lines), err)
+	checks.Check(lines[0] == `{"amount":125.5,"order":"order-1","shipped":true,"shippedAt":"2024-01-15T10:00:00Z"}`, "values should keep their types, got %s", lines[0])
+	checks.Check(len(lines) == 4 && lines[2] == `{"amount":null,"order":"order-3","shipped":false,"shippedAt":null}`, "missing values should be null, got %v", lines)
+
+	fmt.Println("\n--- Streaming ---")
+	// A writer failing on the second row stops the export there: states are read one at a time
+	read := 0
+	counting := func(yield func(string, map[string]interface{}) bool) {
+		for key, state := range orders.States() {
+			read++
+			if !yield(key, state) {
+				return
+			}
+		}
+	}
+	failing := &failingRowWriter{RowWriter: NewCSVRowWriter(io.Discard), failAt: 2}
+	result, err = ExportStates(ctx, counting, OrderSummaryExportSchema, failing)
+	checks.Check(err != nil && result.Rows == 1 && read == 2, "a failed write should stop reading states, got %d rows after reading %d (%v)", result.Rows, read, err)
+
+	// The state exported is a copy taken when the row is reached
+	for key, state := range orders.States() {
+		state["status"] = "tampered"
+		checks.Check(orders.State[key]["status"] != "tampered", "exporting should not change the projection")
+		break
+	}
+
+	checks.Done()
+}
+
+// failingRowWriter fails the failAt-th row, for checking an export stops
+type failingRowWriter struct {
+	RowWriter
+	failAt, rows int
+}
+
+func (f *failingRowWriter) WriteRow(values []interface{}) error {
+	f.rows++
+	if f.rows == f.failAt {
+		return errors.New("disk full")
+	}
+	return f.RowWriter.WriteRow(values)
+}
+
+// === DEMO ===
+
+// RunStateExport projects two orders from the server and exports the order summary as CSV
+func RunStateExport() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	makeEvent := func(eventType string, data interface{}) kurrentdb.EventData {
+		payload, _ := json.Marshal(data)
+		return kurrentdb.EventData{EventID: uuid.New(), EventType: eventType, ContentType: kurrentdb.ContentTypeJson, Data: payload}
+	}
+
+	// === WRITE ===
+	fmt.Println("\n=== Writing orders ===")
+	shippedID, openID := uuid.New().String(), uuid.New().String()
+	shipped, open := Streams.Name("order", shippedID), Streams.Name("order", openID)
+	if _, err := client.AppendToStream(ctx, shipped, kurrentdb.AppendToStreamOptions{},
+		makeEvent("OrderCreated", ProjectionOrderCreated{OrderID: shippedID, CustomerID: "cust-1", Amount: 100}),
+		makeEvent("ItemAdded", ProjectionItemAdded{Item: "Widget", Price: 25}),
+		makeEvent("OrderShipped", ProjectionOrderShipped{ShippedAt: "2024-01-15T10:00:00Z"})); err != nil {
+		panic(err)
+	}
+	last, err := client.AppendToStream(ctx, open, kurrentdb.AppendToStreamOptions{},
+		makeEvent("OrderCreated", ProjectionOrderCreated{OrderID: openID, CustomerID: "cust-2", Amount: 50}))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("  %s, %s\n", shipped, open)
+
+	// === PROJECT ===
+	projection := NewOrderSummaryProjection()
+	followCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	go projection.Follow(followCtx, NewClientStore(client), kurrentdb.SubscribeToAllOptions{
+		From:   kurrentdb.Start{},
+		Filter: projection.SubscriptionFilter(),
+	}, func(*kurrentdb.RecordedEvent) bool { return false })
+	if err := projection.WaitFor(ctx, kurrentdb.Position{Commit: last.CommitPosition, Prepare: last.PreparePosition}); err != nil {
+		panic(err)
+	}
+
+	// === EXPORT ===
+	// Straight to a file, a row at a time, while the subscription keeps applying events
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("order-summary-%s.csv", time.Now().UTC().Format("20060102T150405Z")))
+	fmt.Printf("\n=== Exporting to %s ===\n", path)
+	file, err := os.Create(path)
+	if err != nil {
+		panic(err)
+	}
+	result, err := ExportStates(ctx, projection.States(), OrderSummaryExportSchema, NewCSVRowWriter(file))
+	err = errors.Join(err, file.Close())
+	fmt.Printf("  %d rows, %d skipped (%v)\n", result.Rows, len(result.Skipped), err)
+
+	raw, readErr := os.ReadFile(path)
+	records, parseErr := csv.NewReader(strings.NewReader(string(raw))).ReadAll()
+	rows := make(map[string][]string)
+	for _, record := range records {
+		rows[record[0]] = record
+	}
+	fmt.Printf("  %s\n  %s\n", strings.Join(rows[shipped], ","), strings.Join(rows[open], ","))
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if err != nil || readErr != nil || parseErr != nil {
+		fmt.Printf("FAIL: Expected the export to write a readable CSV, got %v / %v / %v\n", err, readErr, parseErr)
+		passed = false
+	}
+	if len(records) == 0 || records[0][0] != "stream" || len(records)-1 != result.Rows {
+		fmt.Printf("FAIL: Expected a header and one line per row, got %d lines for %d rows\n", len(records), result.Rows)
+		passed = false
+	}
+	if !slices.Equal(rows[shipped], []string{shipped, shippedID, "cust-1", "shipped", "125", "1", `["Widget"]`, "2024-01-15T10:00:00Z"}) {
+		fmt.Printf("FAIL: Unexpected row for the shipped order: %q\n", rows[shipped])
+		passed = false
+	}
+	if !slices.Equal(rows[open], []string{open, openID, "cust-2", "created", "50", "0", "[]", ""}) {
+		fmt.Printf("FAIL: Unexpected row for the open order: %q\n", rows[open])
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll state export tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,666 @@
+// KurrentDB Go Client Example - Stream access control lists
+// Demonstrates: Restricting a stream to a group with $acl metadata, resolving the effective ACL, and classifying denials
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === ACL RESOLUTION ===
+
+// Built-in roles an ACL can name besides users and groups
+const (
+	// RoleAll is every user, including unauthenticated requests
+	RoleAll = "$all"
+	// RoleAdmins is the admins group, which the server lets do anything whatever the ACL says
+	RoleAdmins = "$admins"
+	// RoleOps is the operators group
+	RoleOps = "$ops"
+)
+
+// settingsStream holds the default ACLs as its last event, written by an admin
+const settingsStream = "$settings"
+
+// AclOperation is one of the permissions an ACL grants
+type AclOperation int
+
+const (
+	AclRead AclOperation = iota
+	AclWrite
+	AclDelete
+	AclMetaRead
+	AclMetaWrite
+)
+
+func (o AclOperation) String() string {
+	switch o {
+	case AclRead:
+		return "$r"
+	case AclWrite:
+		return "$w"
+	case AclDelete:
+		return "$d"
+	case AclMetaRead:
+		return "$mr"
+	case AclMetaWrite:
+		return "$mw"
+	}
+	return fmt.Sprintf("AclOperation(%d)", int(o))
+}
+
+var aclOperations = []AclOperation{AclRead, AclWrite, AclDelete, AclMetaRead, AclMetaWrite}
+
+// aclRoles returns the roles acl grants operation, or nil when it doesn't mention the operation
+func aclRoles(acl *kurrentdb.Acl, operation AclOperation) []string {
+	switch operation {
+	case AclRead:
+		return acl.ReadRoles()
+	case AclWrite:
+		return acl.WriteRoles()
+	case AclDelete:
+		return acl.DeleteRoles()
+	case AclMetaRead:
+		return acl.MetaReadRoles()
+	case AclMetaWrite:
+		return acl.MetaWriteRoles()
+	}
+	return nil
+}
+
+// uniformAcl grants every operation to roles
+func uniformAcl(roles ...string) *kurrentdb.Acl {
+	acl := &kurrentdb.Acl{}
+	acl.AddReadRoles(roles...)
+	acl.AddWriteRoles(roles...)
+	acl.AddDeleteRoles(roles...)
+	acl.AddMetaReadRoles(roles...)
+	acl.AddMetaWriteRoles(roles...)
+	return acl
+}
+
+// DefaultAcls are the ACLs streams without one of their own get: User for ordinary streams and
+// System for those starting with $, $all among them. An admin sets them by appending
+// {"$userStreamAcl": {...}, "$systemStreamAcl": {...}} to $settings.
+type DefaultAcls struct {
+	User   *kurrentdb.Acl
+	System *kurrentdb.Acl
+	// Source is where they came from: $settings, or the server's built-in defaults
+	Source string
+}
+
+// ServerDefaultAcls are the defaults before anything is written to $settings: anyone may do
+// anything to user streams, only admins to system streams
+func ServerDefaultAcls() DefaultAcls {
+	return DefaultAcls{User: uniformAcl(RoleAll), System: uniformAcl(RoleAdmins), Source: "server defaults"}
+}
+
+// ParseDefaultAcls decodes a $settings event. A default it leaves out keeps the server's.
+func ParseDefaultAcls(data []byte) (DefaultAcls, error) {
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return DefaultAcls{}, fmt.Errorf("decode %s: %w", settingsStream, err)
+	}
+
+	defaults := ServerDefaultAcls()
+	defaults.Source = settingsStream
+	for key, target := range map[string]**kurrentdb.Acl{kurrentdb.UserStreamAcl: &defaults.User, kurrentdb.SystemStreamAcl: &defaults.System} {
+		raw, ok := settings[key]
+		if !ok {
+			continue
+		}
+		acl, err := parseAcl(raw)
+		if err != nil {
+			return DefaultAcls{}, fmt.Errorf("decode %s in %s: %w", key, settingsStream, err)
+		}
+		*target = acl
+	}
+	return defaults, nil
+}
+
+// parseAcl decodes an ACL object, whose roles are each a string or a list of strings. The client's
+// own parsing, in StreamMetadataFromJson and GetStreamMetadata, rejects lists.
+func parseAcl(raw json.RawMessage) (*kurrentdb.Acl, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	acl := &kurrentdb.Acl{}
+	for key, value := range fields {
+		var roles []string
+		var role string
+		if err := json.Unmarshal(value, &role); err == nil {
+			roles = []string{role}
+		} else if err := json.Unmarshal(value, &roles); err != nil {
+			return nil, fmt.Errorf("%s should be a role or a list of roles, got %s", key, value)
+		}
+		switch key {
+		case "$r":
+			acl.AddReadRoles(roles...)
+		case "$w":
+			acl.AddWriteRoles(roles...)
+		case "$d":
+			acl.AddDeleteRoles(roles...)
+		case "$mr":
+			acl.AddMetaReadRoles(roles...)
+		case "$mw":
+			acl.AddMetaWriteRoles(roles...)
+		default:
+			return nil, fmt.Errorf("unknown ACL key %q", key)
+		}
+	}
+	return acl, nil
+}
+
+// ParseStreamAcl decodes the $acl of a stream metadata event into metadata holding only the ACL:
+// either the name of a default or an ACL object. It returns nil metadata if there is no $acl.
+func ParseStreamAcl(data []byte) (*kurrentdb.StreamMetadata, error) {
+	var fields struct {
+		Acl json.RawMessage `json:"$acl"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	if fields.Acl == nil {
+		return nil, nil
+	}
+
+	metadata := &kurrentdb.StreamMetadata{}
+	var name string
+	if json.Unmarshal(fields.Acl, &name) == nil {
+		if name != kurrentdb.UserStreamAcl && name != kurrentdb.SystemStreamAcl {
+			return nil, fmt.Errorf("unknown default ACL %q", name)
+		}
+		metadata.SetAcl(name)
+		return metadata, nil
+	}
+	acl, err := parseAcl(fields.Acl)
+	if err != nil {
+		return nil, err
+	}
+	metadata.SetAcl(*acl)
+	return metadata, nil
+}
+
+// EffectiveAcl is who may do what to a stream, with where each operation's roles came from
+type EffectiveAcl struct {
+	Stream string
+	Roles  map[AclOperation][]string
+	Source map[AclOperation]string
+}
+
+// ResolveAcl works out the ACL the server applies to stream: each operation the stream's own $acl
+// names uses its roles, and the rest fall back to the default for the kind of stream. An $acl of
+// "$userStreamAcl" or "$systemStreamAcl" picks that default whatever the stream's name. metadata
+// may be nil for a stream without any.
+func ResolveAcl(stream string, metadata *kurrentdb.StreamMetadata, defaults DefaultAcls) EffectiveAcl {
+	fallback, fallbackName := defaults.User, kurrentdb.UserStreamAcl
+	if len(stream) > 0 && stream[0] == '$' {
+		fallback, fallbackName = defaults.System, kurrentdb.SystemStreamAcl
+	}
+
+	var own *kurrentdb.Acl
+	if metadata != nil {
+		switch {
+		case metadata.IsUserStreamAcl():
+			fallback, fallbackName = defaults.User, kurrentdb.UserStreamAcl
+		case metadata.IsSystemStreamAcl():
+			fallback, fallbackName = defaults.System, kurrentdb.SystemStreamAcl
+		default:
+			own = metadata.StreamAcl()
+		}
+	}
+
+	effective := EffectiveAcl{Stream: stream, Roles: map[AclOperation][]string{}, Source: map[AclOperation]string{}}
+	for _, operation := range aclOperations {
+		if own != nil && aclRoles(own, operation) != nil {
+			effective.Roles[operation] = aclRoles(own, operation)
+			effective.Source[operation] = "stream $acl"
+			continue
+		}
+		effective.Roles[operation] = aclRoles(fallback, operation)
+		effective.Source[operation] = fmt.Sprintf("%s (%s)", fallbackName, defaults.Source)
+	}
+	return effective
+}
+
+// AclUser is who a request runs as: a login and the groups it belongs to
+type AclUser struct {
+	Login  string
+	Groups []string
+}
+
+// Allows reports whether user may perform operation, as the server decides it: admins always may,
+// and otherwise one of the operation's roles has to be $all, the user's login or one of its groups
+func (e EffectiveAcl) Allows(user AclUser, operation AclOperation) bool {
+	if slices.Contains(user.Groups, RoleAdmins) {
+		return true
+	}
+	for _, role := range e.Roles[operation] {
+		if role == RoleAll || role == user.Login || slices.Contains(user.Groups, role) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e EffectiveAcl) String() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s:", e.Stream)
+	for _, operation := range aclOperations {
+		fmt.Fprintf(&b, "\n  %-3s %v from %s", operation, e.Roles[operation], e.Source[operation])
+	}
+	return b.String()
+}
+
+// === READING ACLs ===
+
+// ReadDefaultAcls returns the defaults in $settings, or the server's when nothing has been written
+// there. Reading $settings takes admin credentials.
+func ReadDefaultAcls(ctx context.Context, client *kurrentdb.Client, admin *kurrentdb.Credentials) (DefaultAcls, error) {
+	events, err := client.ReadStream(ctx, settingsStream, kurrentdb.ReadStreamOptions{
+		Direction:     kurrentdb.Backwards,
+		From:          kurrentdb.End{},
+		Authenticated: admin,
+	}, 1)
+	if err != nil {
+		return DefaultAcls{}, fmt.Errorf("read %s: %w", settingsStream, err)
+	}
+	defer events.Close()
+
+	event, err := events.Recv()
+	if errors.Is(err, io.EOF) || isStreamNotFound(err) {
+		return ServerDefaultAcls(), nil
+	}
+	if err != nil {
+		return DefaultAcls{}, fmt.Errorf("read %s: %w", settingsStream, err)
+	}
+	return ParseDefaultAcls(event.OriginalEvent().Data)
+}
+
+// GetEffectiveAcl reads stream's metadata and the defaults and resolves the ACL the server applies.
+// For $all, pass "$all": its metadata lives in $$$all like any other stream's. The metadata is read
+// as an event rather than with GetStreamMetadata, which fails on an $acl listing several roles for
+// one operation.
+func GetEffectiveAcl(ctx context.Context, client *kurrentdb.Client, stream string, admin *kurrentdb.Credentials) (EffectiveAcl, error) {
+	metadataStream := "$$" + stream
+	var metadata *kurrentdb.StreamMetadata
+	events, err := client.ReadStream(ctx, metadataStream, kurrentdb.ReadStreamOptions{
+		Direction:     kurrentdb.Backwards,
+		From:          kurrentdb.End{},
+		Authenticated: admin,
+	}, 1)
+	if err == nil {
+		var event *kurrentdb.ResolvedEvent
+		event, err = events.Recv()
+		events.Close()
+		if err == nil {
+			metadata, err = ParseStreamAcl(event.OriginalEvent().Data)
+		}
+	}
+	if err != nil && !errors.Is(err, io.EOF) && !isStreamNotFound(err) {
+		return EffectiveAcl{}, fmt.Errorf("read %s: %w", metadataStream, err)
+	}
+	defaults, err := ReadDefaultAcls(ctx, client, admin)
+	if err != nil {
+		return EffectiveAcl{}, err
+	}
+	return ResolveAcl(stream, metadata, defaults), nil
+}
+
+// isAccessDenied reports whether the server refused a request because the ACL doesn't allow it.
+// A wrong password or unknown user is ErrorCodeUnauthenticated instead: the request was never
+// checked against the ACL.
+func isAccessDenied(err error) bool {
+	return errorCode(err) == kurrentdb.ErrorCodeAccessDenied
+}
+
+// isUnauthenticated reports whether the server didn't accept the request's credentials
+func isUnauthenticated(err error) bool {
+	return errorCode(err) == kurrentdb.ErrorCodeUnauthenticated
+}
+
+// === USERS ===
+
+// createUser adds a user through the HTTP API, as the client has no user management calls. A user
+// that already exists is left as it is.
+func createUser(ctx context.Context, baseURL string, admin *kurrentdb.Credentials, login, password string, groups ...string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"loginName": login,
+		"fullName":  login,
+		"password":  password,
+		"groups":    groups,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/users/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(admin.Login, admin.Password)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusConflict {
+		message, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("create user %s: %s: %s", login, resp.Status, message)
+	}
+	return nil
+}
+
+// RunStreamAclChecks resolves ACLs from stream metadata and defaults and evaluates them, without a
+// server
+func RunStreamAclChecks() {
+	checks := newChecker("stream ACL")
+
+	alice := AclUser{Login: "alice", Groups: []string{"finance"}}
+	bob := AclUser{Login: "bob", Groups: []string{"sales"}}
+	admin := AclUser{Login: "admin", Groups: []string{RoleAdmins}}
+
+	fmt.Println("\n--- Stream ACL ---")
+	ledgerAcl := kurrentdb.Acl{}
+	ledgerAcl.AddReadRoles("finance")
+	ledgerAcl.AddWriteRoles("finance")
+	ledgerAcl.AddMetaWriteRoles(RoleAdmins)
+	metadata := &kurrentdb.StreamMetadata{}
+	metadata.SetAcl(ledgerAcl)
+	ledger := ResolveAcl("ledger-1", metadata, ServerDefaultAcls())
+	fmt.Println(ledger)
+	checks.Check(ledger.Allows(alice, AclRead) && ledger.Allows(alice, AclWrite), "finance should read and write the ledger")
+	checks.Check(!ledger.Allows(bob, AclRead) && !ledger.Allows(bob, AclWrite), "sales should neither read nor write the ledger")
+	checks.Check(!ledger.Allows(alice, AclMetaWrite) && ledger.Allows(admin, AclMetaWrite), "only admins should change the ledger's metadata")
+	checks.Check(ledger.Allows(bob, AclDelete) && ledger.Source[AclDelete] == "$userStreamAcl (server defaults)",
+		"operations the $acl leaves out should fall back to the default, got %v from %s", ledger.Roles[AclDelete], ledger.Source[AclDelete])
+	named := kurrentdb.Acl{}
+	named.AddReadRoles("bob")
+	byName := ResolveAcl("ledger-2", func() *kurrentdb.StreamMetadata { m := &kurrentdb.StreamMetadata{}; m.SetAcl(named); return m }(), ServerDefaultAcls())
+	checks.Check(byName.Allows(bob, AclRead) && !byName.Allows(alice, AclRead), "a role naming a login should admit that user only")
+
+	fmt.Println("\n--- Defaults ---")
+	open := ResolveAcl("notes-1", nil, ServerDefaultAcls())
+	checks.Check(open.Allows(bob, AclWrite) && open.Allows(AclUser{}, AclRead), "user streams should be open to everyone by default, got %v", open.Roles)
+	all := ResolveAcl("$all", nil, ServerDefaultAcls())
+	fmt.Println(all)
+	checks.Check(!all.Allows(alice, AclRead) && all.Allows(admin, AclRead), "$all should be readable by admins only by default")
+	checks.Check(!ResolveAcl("$ce-ledger", nil, ServerDefaultAcls()).Allows(alice, AclRead), "system streams should be admin-only by default")
+	checks.Check(ResolveAcl("$ce-ledger", &kurrentdb.StreamMetadata{}, ServerDefaultAcls()).Source[AclRead] == "$systemStreamAcl (server defaults)",
+		"metadata without an $acl should use the default")
+
+	settings, err := ParseDefaultAcls([]byte(`{
+		"$userStreamAcl": {"$r": "$all", "$w": ["finance", "ops-bot"], "$d": "$admins", "$mr": "$all", "$mw": "$admins"},
+		"$systemStreamAcl": {"$r": ["$admins", "auditors"], "$w": "$admins", "$d": "$admins", "$mr": "$admins", "$mw": "$admins"}
+	}`))
+	checks.Check(err == nil && settings.Source == settingsStream, "$settings should parse, got %v", err)
+	fromSettings := ResolveAcl("notes-1", nil, settings)
+	fmt.Println(fromSettings)
+	checks.Check(fromSettings.Allows(alice, AclWrite) && !fromSettings.Allows(bob, AclWrite) && fromSettings.Allows(bob, AclRead),
+		"$settings should restrict writes to finance, got %v", fromSettings.Roles[AclWrite])
+	checks.Check(fromSettings.Source[AclWrite] == "$userStreamAcl ($settings)", "the source should name $settings, got %s", fromSettings.Source[AclWrite])
+	auditor := AclUser{Login: "carol", Groups: []string{"auditors"}}
+	checks.Check(ResolveAcl("$all", nil, settings).Allows(auditor, AclRead), "$systemStreamAcl in $settings should open $all to auditors")
+	// A stream's own $acl still beats the defaults
+	checks.Check(!ResolveAcl("ledger-1", metadata, settings).Allows(bob, AclRead) && ResolveAcl("ledger-1", metadata, settings).Source[AclDelete] == "$userStreamAcl ($settings)",
+		"the stream $acl should win, falling back to $settings")
+
+	systemNamed := &kurrentdb.StreamMetadata{}
+	systemNamed.SetAcl(kurrentdb.SystemStreamAcl)
+	locked := ResolveAcl("notes-2", systemNamed, settings)
+	checks.Check(!locked.Allows(alice, AclWrite) && locked.Allows(auditor, AclRead), "an $acl of $systemStreamAcl should apply the system default to a user stream, got %v", locked.Roles)
+
+	partial, err := ParseDefaultAcls([]byte(`{"$userStreamAcl": {"$r": "$all", "$w": "$admins", "$d": "$admins", "$mr": "$all", "$mw": "$admins"}}`))
+	checks.Check(err == nil && !ResolveAcl("$all", nil, partial).Allows(auditor, AclRead), "a default $settings leaves out should stay the server's, got %v", err)
+	_, err = ParseDefaultAcls([]byte(`{"$userStreamAcl": {"$read": "$all"}}`))
+	fmt.Printf("  bad key: %v\n", err)
+	checks.Check(err != nil, "an unknown ACL key should fail to parse")
+
+	fmt.Println("\n--- Metadata events ---")
+	parsed, err := ParseStreamAcl([]byte(`{"$maxCount": 10, "$acl": {"$r": ["finance", "auditors"], "$w": "finance"}}`))
+	checks.Check(err == nil && ResolveAcl("ledger-3", parsed, ServerDefaultAcls()).Allows(auditor, AclRead) &&
+		!ResolveAcl("ledger-3", parsed, ServerDefaultAcls()).Allows(auditor, AclWrite), "an $acl listing several roles should parse, got %v", err)
+	parsed, err = ParseStreamAcl([]byte(`{"$acl": "$systemStreamAcl"}`))
+	checks.Check(err == nil && parsed.IsSystemStreamAcl(), "a default's name should parse, got %v", err)
+	parsed, err = ParseStreamAcl([]byte(`{"$maxCount": 10}`))
+	checks.Check(err == nil && parsed == nil, "metadata without an $acl should have none, got %v", err)
+	_, err = ParseStreamAcl([]byte(`{"$acl": "$everyone"}`))
+	checks.Check(err != nil, "an unknown default name should fail to parse")
+
+	checks.Done()
+}
+
+// RunStreamAcl restricts a stream to a group and shows who gets in, how $all and the $settings
+// defaults interact with it, and how denials are reported
+func RunStreamAcl() {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	admin := &kurrentdb.Credentials{Login: "admin", Password: "changeit"}
+	alice := &kurrentdb.Credentials{Login: "acl-alice", Password: "alice-password"}
+	bob := &kurrentdb.Credentials{Login: "acl-bob", Password: "bob-password"}
+	event := func(eventType string) kurrentdb.EventData {
+		return kurrentdb.EventData{EventID: uuid.New(), EventType: eventType, ContentType: kurrentdb.ContentTypeJson, Data: []byte(`{}`)}
+	}
+	appendAs := func(user *kurrentdb.Credentials, stream string) error {
+		_, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{Authenticated: user}, event("LedgerEntryPosted"))
+		return err
+	}
+	readAs := func(user *kurrentdb.Credentials, stream string) error {
+		events, err := client.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{From: kurrentdb.Start{}, Authenticated: user}, 10)
+		if err != nil {
+			return err
+		}
+		defer events.Close()
+		for {
+			if _, err := events.Recv(); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+	readAllAs := func(user *kurrentdb.Credentials) error {
+		events, err := client.ReadAll(ctx, kurrentdb.ReadAllOptions{From: kurrentdb.End{}, Direction: kurrentdb.Backwards, Authenticated: user}, 1)
+		if err != nil {
+			return err
+		}
+		defer events.Close()
+		if _, err := events.Recv(); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		return nil
+	}
+
+	// === USERS ===
+	fmt.Println("\n=== Users ===")
+	baseURL := httpBaseURL(connectionString)
+	usersErr := errors.Join(
+		createUser(ctx, baseURL, admin, alice.Login, alice.Password, "finance"),
+		createUser(ctx, baseURL, admin, bob.Login, bob.Password, "sales"))
+	if usersErr != nil {
+		fmt.Printf("Couldn't create users: %v\n", usersErr)
+	} else {
+		fmt.Printf("%s in finance, %s in sales\n", alice.Login, bob.Login)
+	}
+
+	// === STREAM ACL ===
+	fmt.Println("\n=== Restricting a ledger to finance ===")
+	ledger := Streams.Name("ledger", uuid.New().String())
+	if err := appendAs(admin, ledger); err != nil {
+		panic(err)
+	}
+	acl := kurrentdb.Acl{}
+	acl.AddReadRoles("finance")
+	acl.AddWriteRoles("finance")
+	acl.AddMetaReadRoles("finance")
+	acl.AddMetaWriteRoles(RoleAdmins)
+	metadata := kurrentdb.StreamMetadata{}
+	metadata.SetAcl(acl)
+	if _, err := client.SetStreamMetadata(ctx, ledger, kurrentdb.AppendToStreamOptions{Authenticated: admin}, metadata); err != nil {
+		panic(err)
+	}
+
+	effective, aclErr := GetEffectiveAcl(ctx, client, ledger, admin)
+	fmt.Println(effective)
+
+	// Without authentication and authorization (an --insecure server) every request runs as an
+	// admin: the ACL is stored and resolved but nothing is denied
+	bobReadErr := readAs(bob, ledger)
+	enforced := isAccessDenied(bobReadErr)
+	if !enforced {
+		fmt.Println("\nAccess control is off on this server (it runs with --insecure), so ACLs are stored but not")
+		fmt.Println("enforced. Run against a secure server to see the denials below.")
+	}
+
+	bobWriteErr := appendAs(bob, ledger)
+	aliceWriteErr := appendAs(alice, ledger)
+	aliceReadErr := readAs(alice, ledger)
+	wrongPasswordErr := appendAs(&kurrentdb.Credentials{Login: alice.Login, Password: "wrong"}, ledger)
+	fmt.Printf("\n%s reads:  %v\n%s writes: %v\n", bob.Login, bobReadErr, bob.Login, bobWriteErr)
+	fmt.Printf("%s reads:  %v\n%s writes: %v\n", alice.Login, aliceReadErr, alice.Login, aliceWriteErr)
+	fmt.Printf("wrong password: %v\n", wrongPasswordErr)
+
+	// === $all ===
+	// $all has an ACL of its own, the system default unless $$$all says otherwise. A stream's ACL
+	// doesn't carry over: alice may read the ledger but not $all, and whoever may read $all sees the
+	// ledger's events in it whatever the ledger's ACL says.
+	fmt.Println("\n=== $all ===")
+	allAcl, allErr := GetEffectiveAcl(ctx, client, "$all", admin)
+	fmt.Println(allAcl)
+	aliceAllErr := readAllAs(alice)
+	adminAllErr := readAllAs(admin)
+	fmt.Printf("%s reads $all: %v\nadmin reads $all: %v\n", alice.Login, aliceAllErr, adminAllErr)
+
+	// === $settings ===
+	// Streams without an $acl get the $userStreamAcl default. Here it's narrowed to let only finance
+	// write, then put back: $settings applies to the whole server.
+	fmt.Println("\n=== Default ACL via $settings ===")
+	notes := Streams.Name("notes", uuid.New().String())
+	before, settingsErr := GetEffectiveAcl(ctx, client, notes, admin)
+	fmt.Println(before)
+
+	var after EffectiveAcl
+	var bobNotesErr, aliceNotesErr error
+	if enforced && settingsErr == nil {
+		previous, err := client.ReadStream(ctx, settingsStream, kurrentdb.ReadStreamOptions{Direction: kurrentdb.Backwards, From: kurrentdb.End{}, Authenticated: admin}, 1)
+		restore := []byte(`{"$userStreamAcl": {"$r": "$all", "$w": "$all", "$d": "$all", "$mr": "$all", "$mw": "$all"}, "$systemStreamAcl": {"$r": "$admins", "$w": "$admins", "$d": "$admins", "$mr": "$admins", "$mw": "$admins"}}`)
+		if err == nil {
+			if last, err := previous.Recv(); err == nil {
+				restore = last.OriginalEvent().Data
+			}
+			previous.Close()
+		}
+		settings := kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   "update-default-acl",
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(`{"$userStreamAcl": {"$r": "$all", "$w": ["finance", "$admins"], "$d": "$admins", "$mr": "$all", "$mw": "$admins"}, "$systemStreamAcl": {"$r": "$admins", "$w": "$admins", "$d": "$admins", "$mr": "$admins", "$mw": "$admins"}}`),
+		}
+		if _, err := client.AppendToStream(ctx, settingsStream, kurrentdb.AppendToStreamOptions{Authenticated: admin}, settings); err != nil {
+			panic(err)
+		}
+		defer func() {
+			restored := kurrentdb.EventData{EventID: uuid.New(), EventType: "update-default-acl", ContentType: kurrentdb.ContentTypeJson, Data: restore}
+			if _, err := client.AppendToStream(context.Background(), settingsStream, kurrentdb.AppendToStreamOptions{Authenticated: admin}, restored); err != nil {
+				fmt.Printf("Couldn't restore %s: %v\n", settingsStream, err)
+			}
+		}()
+
+		after, settingsErr = GetEffectiveAcl(ctx, client, notes, admin)
+		fmt.Println(after)
+		// The server applies new defaults shortly after they are written
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(200 * time.Millisecond) {
+			if bobNotesErr = appendAs(bob, notes); isAccessDenied(bobNotesErr) {
+				break
+			}
+		}
+		aliceNotesErr = appendAs(alice, notes)
+		fmt.Printf("%s writes: %v\n%s writes: %v\n", bob.Login, bobNotesErr, alice.Login, aliceNotesErr)
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if aclErr != nil || !slices.Equal(effective.Roles[AclRead], []string{"finance"}) || effective.Source[AclRead] != "stream $acl" {
+		fmt.Printf("FAIL: The ledger's read roles should come from its $acl, got %v from %s (%v)\n", effective.Roles[AclRead], effective.Source[AclRead], aclErr)
+		passed = false
+	}
+	if effective.Source[AclDelete] == "stream $acl" {
+		fmt.Println("FAIL: Delete isn't in the ledger's $acl and should fall back to the default")
+		passed = false
+	}
+	if allErr != nil || len(allAcl.Roles[AclRead]) == 0 {
+		fmt.Printf("FAIL: $all's ACL should resolve, got %v (%v)\n", allAcl.Roles, allErr)
+		passed = false
+	}
+	if settingsErr != nil || before.Source[AclWrite] == "stream $acl" {
+		fmt.Printf("FAIL: A stream without an $acl should use the default, got %s (%v)\n", before.Source[AclWrite], settingsErr)
+		passed = false
+	}
+	if !enforced {
+		if bobWriteErr != nil || aliceWriteErr != nil || adminAllErr != nil {
+			fmt.Printf("FAIL: Without access control every request should succeed, got %v / %v / %v\n", bobWriteErr, aliceWriteErr, adminAllErr)
+			passed = false
+		}
+	} else {
+		if usersErr != nil {
+			fmt.Printf("FAIL: Users should be created, got %v\n", usersErr)
+			passed = false
+		}
+		if !isAccessDenied(bobWriteErr) {
+			fmt.Printf("FAIL: %s's write should be classified as access denied, got %v\n", bob.Login, bobWriteErr)
+			passed = false
+		}
+		if isUnauthenticated(bobWriteErr) || !isUnauthenticated(wrongPasswordErr) || isAccessDenied(wrongPasswordErr) {
+			fmt.Printf("FAIL: A wrong password should be unauthenticated and a denial not, got %v / %v\n", wrongPasswordErr, bobWriteErr)
+			passed = false
+		}
+		if aliceReadErr != nil || aliceWriteErr != nil {
+			fmt.Printf("FAIL: %s is in finance and should read and write, got %v / %v\n", alice.Login, aliceReadErr, aliceWriteErr)
+			passed = false
+		}
+		if !isAccessDenied(aliceAllErr) || adminAllErr != nil {
+			fmt.Printf("FAIL: Only admins should read $all, got %v for %s and %v for admin\n", aliceAllErr, alice.Login, adminAllErr)
+			passed = false
+		}
+		if after.Source[AclWrite] != "$userStreamAcl ($settings)" || !slices.Contains(after.Roles[AclWrite], "finance") {
+			fmt.Printf("FAIL: The default from $settings should apply, got %v from %s\n", after.Roles[AclWrite], after.Source[AclWrite])
+			passed = false
+		}
+		if !isAccessDenied(bobNotesErr) || aliceNotesErr != nil {
+			fmt.Printf("FAIL: The $settings default should let only finance write, got %v / %v\n", bobNotesErr, aliceNotesErr)
+			passed = false
+		}
+	}
+
+	if passed {
+		fmt.Println("\nAll stream ACL tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,370 @@
+// KurrentDB Go Client Example - Stream diff
+// Demonstrates: Comparing one stream across two environments event by event, ignoring volatile fields
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === NORMALIZERS ===
+
+// EventNormalizer rewrites event data before comparison, e.g. to drop timestamps or generated ids
+type EventNormalizer func(eventType string, data []byte) []byte
+
+// IgnoreJSONFields removes the named fields (dotted paths for nested objects, e.g. "audit.at")
+// and re-encodes the rest with sorted keys, so key order and whitespace don't count as differences.
+// Data that isn't a JSON object is compared as-is.
+func IgnoreJSONFields(fields ...string) EventNormalizer {
+	return func(eventType string, data []byte) []byte {
+		var object map[string]interface{}
+		if err := json.Unmarshal(data, &object); err != nil {
+			return data
+		}
+		for _, field := range fields {
+			deleteJSONPath(object, strings.Split(field, "."))
+		}
+		normalized, err := json.Marshal(object)
+		if err != nil {
+			return data
+		}
+		return normalized
+	}
+}
+
+func deleteJSONPath(object map[string]interface{}, path []string) {
+	if len(path) == 1 {
+		delete(object, path[0])
+		return
+	}
+	if child, ok := object[path[0]].(map[string]interface{}); ok {
+		deleteJSONPath(child, path[1:])
+	}
+}
+
+// === STREAM DIFF ===
+
+// DiffSource is one side of a comparison
+type DiffSource struct {
+	Name   string
+	Client *kurrentdb.Client
+	Stream string
+}
+
+// EventDifference is the first index at which the two streams disagree
+type EventDifference struct {
+	Index       int
+	LeftNumber  uint64
+	RightNumber uint64
+	Field       string // "type" or "data"
+	Left        string
+	Right       string
+}
+
+// StreamDiff is the outcome of DiffStreams. Events are compared by index, so a stream truncated
+// in one environment shows up as a difference at index 0 rather than being realigned.
+type StreamDiff struct {
+	Left, Right      DiffSource
+	LeftCount        int
+	RightCount       int
+	Differing        int
+	FirstDifference  *EventDifference
+	FirstExtraNumber uint64 // event number of the first event only the longer stream has
+}
+
+func (d *StreamDiff) Equal() bool {
+	return d.FirstDifference == nil && d.LeftCount == d.RightCount
+}
+
+func (d *StreamDiff) String() string {
+	var b strings.Builder
+
+	if d.Equal() {
+		fmt.Fprintf(&b, "%s: identical (%d events)", d.Left.Stream, d.LeftCount)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "%s (%s) vs %s (%s): DIFFERENT", d.Left.Stream, d.Left.Name, d.Right.Stream, d.Right.Name)
+	if diff := d.FirstDifference; diff != nil {
+		fmt.Fprintf(&b, "\n  first difference at index %d (%s #%d, %s #%d): %s differs",
+			diff.Index, d.Left.Name, diff.LeftNumber, d.Right.Name, diff.RightNumber, diff.Field)
+		fmt.Fprintf(&b, "\n    %-6s %s", d.Left.Name+":", truncateForDiff(diff.Left, 120))
+		fmt.Fprintf(&b, "\n    %-6s %s", d.Right.Name+":", truncateForDiff(diff.Right, 120))
+		fmt.Fprintf(&b, "\n  %d of %d compared events differ", d.Differing, min(d.LeftCount, d.RightCount))
+	}
+	if d.LeftCount != d.RightCount {
+		longer, extra := d.Left.Name, d.LeftCount-d.RightCount
+		if d.RightCount > d.LeftCount {
+			longer, extra = d.Right.Name, d.RightCount-d.LeftCount
+		}
+		fmt.Fprintf(&b, "\n  length: %s %d, %s %d (%s has %d extra from #%d)",
+			d.Left.Name, d.LeftCount, d.Right.Name, d.RightCount, longer, extra, d.FirstExtraNumber)
+	}
+	return b.String()
+}
+
+func truncateForDiff(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
+
+// DiffStreams reads both streams forwards in lockstep and compares EventType and normalized Data.
+// A missing stream is treated as empty. normalize may be nil.
+func DiffStreams(ctx context.Context, left, right DiffSource, normalize EventNormalizer) (*StreamDiff, error) {
+	if normalize == nil {
+		normalize = func(_ string, data []byte) []byte { return data }
+	}
+
+	leftEvents, err := openDiffStream(ctx, left)
+	if err != nil {
+		return nil, err
+	}
+	defer leftEvents.close()
+
+	rightEvents, err := openDiffStream(ctx, right)
+	if err != nil {
+		return nil, err
+	}
+	defer rightEvents.close()
+
+	diff := &StreamDiff{Left: left, Right: right}
+
+	for index := 0; ; index++ {
+		l, err := leftEvents.next()
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", left.Name, err)
+		}
+		r, err := rightEvents.next()
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", right.Name, err)
+		}
+
+		if l != nil {
+			diff.LeftCount++
+		}
+		if r != nil {
+			diff.RightCount++
+		}
+
+		switch {
+		case l == nil && r == nil:
+			return diff, nil
+		case l == nil || r == nil:
+			if min(diff.LeftCount, diff.RightCount) == index {
+				// First event only one side has
+				if l != nil {
+					diff.FirstExtraNumber = l.EventNumber
+				} else {
+					diff.FirstExtraNumber = r.EventNumber
+				}
+			}
+			continue
+		}
+
+		var difference *EventDifference
+		if l.EventType != r.EventType {
+			difference = &EventDifference{Field: "type", Left: l.EventType, Right: r.EventType}
+		} else if ld, rd := normalize(l.EventType, l.Data), normalize(r.EventType, r.Data); string(ld) != string(rd) {
+			difference = &EventDifference{Field: "data", Left: string(ld), Right: string(rd)}
+		}
+
+		if difference != nil {
+			diff.Differing++
+			if diff.FirstDifference == nil {
+				difference.Index = index
+				difference.LeftNumber = l.EventNumber
+				difference.RightNumber = r.EventNumber
+				diff.FirstDifference = difference
+			}
+		}
+	}
+}
+
+// diffStream yields events one at a time, or nil once exhausted
+type diffStream struct {
+	stream *kurrentdb.ReadStream
+}
+
+func openDiffStream(ctx context.Context, source DiffSource) (*diffStream, error) {
+	stream, err := source.Client.ReadStream(ctx, source.Stream, kurrentdb.ReadStreamOptions{
+		Direction: kurrentdb.Forwards,
+		From:      kurrentdb.Start{},
+	}, ^uint64(0))
+	if err != nil {
+		if isStreamNotFound(err) {
+			return &diffStream{}, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", source.Name, err)
+	}
+	return &diffStream{stream: stream}, nil
+}
+
+func (s *diffStream) next() (*kurrentdb.RecordedEvent, error) {
+	if s.stream == nil {
+		return nil, nil
+	}
+	event, err := s.stream.Recv()
+	if errors.Is(err, io.EOF) {
+		s.close()
+		return nil, nil
+	}
+	if err != nil {
+		if isStreamNotFound(err) {
+			s.close()
+			return nil, nil
+		}
+		return nil, err
+	}
+	return Resolve(event, false), nil
+}
+
+func (s *diffStream) close() {
+	if s.stream != nil {
+		s.stream.Close()
+		s.stream = nil
+	}
+}
+
+// RunStreamDiff compares a stream across two connections:
+//
+//	stream-diff <stream> <right-connection-string>
+//
+// Without arguments it writes two diverging copies of a stream to one server and diffs those.
+func RunStreamDiff() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	normalize := IgnoreJSONFields("timestamp", "requestId", "audit.at")
+
+	if len(os.Args) > 3 {
+		settings, err := kurrentdb.ParseConnectionString(os.Args[3])
+		if err != nil {
+			panic(err)
+		}
+		right, err := kurrentdb.NewClient(settings)
+		if err != nil {
+			panic(err)
+		}
+		defer right.Close()
+
+		diff, err := DiffStreams(ctx,
+			DiffSource{Name: "left", Client: client, Stream: os.Args[2]},
+			DiffSource{Name: "right", Client: right, Stream: os.Args[2]},
+			normalize)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(diff)
+		if !diff.Equal() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// === SIMULATE TWO ENVIRONMENTS ===
+	// A second client stands in for the other environment
+	settings, err := kurrentdb.ParseConnectionString(connectionString)
+	if err != nil {
+		panic(err)
+	}
+	other, err := kurrentdb.NewClient(settings)
+	if err != nil {
+		panic(err)
+	}
+	defer other.Close()
+
+	orderID := uuid.New().String()
+	staging := Streams.Name("stagingorder", orderID)
+	production := Streams.Name("prodorder", orderID)
+
+	appendAll := func(c *kurrentdb.Client, stream string, events [][2]string) {
+		for _, e := range events {
+			_, err := c.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+				EventID:     uuid.New(),
+				EventType:   e[0],
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        []byte(e[1]),
+			})
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	appendAll(client, staging, [][2]string{
+		{"OrderCreated", `{"orderId":"1","amount":100,"timestamp":"2024-01-15T10:00:00Z","requestId":"a1"}`},
+		{"ItemAdded", `{"item":"Widget","price":25,"audit":{"at":"2024-01-15T10:01:00Z","by":"alice"}}`},
+		{"ItemAdded", `{"item":"Gadget","price":30}`},
+		{"OrderShipped", `{"carrier":"ups"}`},
+	})
+	appendAll(other, production, [][2]string{
+		// Same events with different volatile fields and key order
+		{"OrderCreated", `{"requestId":"b7","amount":100,"orderId":"1","timestamp":"2024-01-16T08:30:00Z"}`},
+		{"ItemAdded", `{"item":"Widget","price":25,"audit":{"by":"alice","at":"2024-01-16T08:31:00Z"}}`},
+		{"ItemAdded", `{"item":"Gadget","price":35}`},
+	})
+
+	diff, err := DiffStreams(ctx,
+		DiffSource{Name: "staging", Client: client, Stream: staging},
+		DiffSource{Name: "prod", Client: other, Stream: production},
+		normalize)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println()
+	fmt.Println(diff)
+
+	missing, err := DiffStreams(ctx,
+		DiffSource{Name: "staging", Client: client, Stream: staging},
+		DiffSource{Name: "prod", Client: other, Stream: Streams.Name("prodorder", uuid.New().String())},
+		normalize)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(missing)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	first := diff.FirstDifference
+	if first == nil || first.Index != 2 || first.Field != "data" {
+		fmt.Printf("FAIL: First difference should be the Gadget price at index 2, got %+v\n", first)
+		passed = false
+	}
+	if diff.Differing != 1 {
+		fmt.Printf("FAIL: Volatile fields should be ignored, got %d differing events\n", diff.Differing)
+		passed = false
+	}
+	if diff.LeftCount != 4 || diff.RightCount != 3 || diff.FirstExtraNumber != 3 {
+		fmt.Printf("FAIL: Expected staging 4, prod 3 with extra from #3, got %d, %d from #%d\n", diff.LeftCount, diff.RightCount, diff.FirstExtraNumber)
+		passed = false
+	}
+	if missing.RightCount != 0 || missing.LeftCount != 4 || missing.FirstDifference != nil {
+		fmt.Printf("FAIL: A missing stream should compare as empty, got %d vs %d\n", missing.LeftCount, missing.RightCount)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll stream diff tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
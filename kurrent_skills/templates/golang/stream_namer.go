@@ -0,0 +1,110 @@
+// KurrentDB Go Client Example - Stream-per-aggregate naming convention
+// Demonstrates: Building and parsing {category}-{id} stream names and their $ce- category streams
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// === STREAM NAMER ===
+
+// StreamNamer enforces the {category}-{id} convention used by the $by_category system projection,
+// which splits a stream name at the first separator. Categories therefore must not contain the
+// separator, while ids may (e.g. UUIDs).
+type StreamNamer struct {
+	// Separator defaults to "-", matching the server's default $by_category configuration
+	Separator string
+}
+
+// Streams is the shared namer used by the templates
+var Streams = StreamNamer{}
+
+func (n StreamNamer) separator() string {
+	if n.Separator == "" {
+		return "-"
+	}
+	return n.Separator
+}
+
+// Name returns the stream for one aggregate instance. It panics if category is empty or contains
+// the separator, since such a name would not parse back to the same category.
+func (n StreamNamer) Name(category, id string) string {
+	if category == "" || strings.Contains(category, n.separator()) {
+		panic(fmt.Sprintf("invalid stream category %q", category))
+	}
+	return category + n.separator() + id
+}
+
+// Parse splits a stream name into category and id. ok is false for system streams ($-prefixed)
+// and names without a non-empty category and id.
+func (n StreamNamer) Parse(stream string) (category, id string, ok bool) {
+	if strings.HasPrefix(stream, "$") {
+		return "", "", false
+	}
+	category, id, found := strings.Cut(stream, n.separator())
+	if !found || category == "" || id == "" {
+		return "", "", false
+	}
+	return category, id, true
+}
+
+// Category returns the $ce- stream holding links to every event in the category
+func (n StreamNamer) Category(category string) string {
+	return "$ce-" + category
+}
+
+// CategoryOf returns the $ce- stream a given aggregate stream is linked into
+func (n StreamNamer) CategoryOf(stream string) (string, bool) {
+	category, _, ok := n.Parse(stream)
+	if !ok {
+		return "", false
+	}
+	return n.Category(category), true
+}
+
+// EventType returns the $et- stream holding links to every event of a type, in any stream
+func (n StreamNamer) EventType(eventType string) string {
+	return "$et-" + eventType
+}
+
+// RunStreamNamerChecks verifies round-tripping and hyphenated ids without a server
+func RunStreamNamerChecks() {
+	checks := newChecker("stream namer")
+
+	// Round-trip
+	for _, id := range []string{"123", "5f0c2c6e-8a1b-4b8e-9b1e-0c6f2d8a9e11", "a-b-c", "-leading"} {
+		stream := Streams.Name("order", id)
+		category, parsedID, ok := Streams.Parse(stream)
+		checks.Check(ok && category == "order" && parsedID == id,
+			"Parse(%q) = (%q, %q, %v), want (order, %q, true)", stream, category, parsedID, ok, id)
+		fmt.Printf("  %s -> category=%s id=%s\n", stream, category, parsedID)
+	}
+
+	// Category streams
+	checks.Check(Streams.Category("order") == "$ce-order", "Category(order) = %q", Streams.Category("order"))
+	ce, ok := Streams.CategoryOf("order-1-2")
+	checks.Check(ok && ce == "$ce-order", "CategoryOf(order-1-2) = (%q, %v)", ce, ok)
+	checks.Check(Streams.EventType("OrderShipped") == "$et-OrderShipped", "EventType(OrderShipped) = %q", Streams.EventType("OrderShipped"))
+
+	// Invalid names
+	for _, stream := range []string{"order", "order-", "-123", "$ce-order", ""} {
+		_, _, ok := Streams.Parse(stream)
+		checks.Check(!ok, "Parse(%q) should fail", stream)
+	}
+
+	// Categories containing the separator are rejected
+	func() {
+		defer func() {
+			checks.Check(recover() != nil, "Name with hyphenated category should panic")
+		}()
+		Streams.Name("sales-order", "1")
+	}()
+
+	// Custom separator
+	custom := StreamNamer{Separator: "_"}
+	category, id, ok := custom.Parse(custom.Name("order", "a-b"))
+	checks.Check(ok && category == "order" && id == "a-b", "custom separator round-trip = (%q, %q, %v)", category, id, ok)
+
+	checks.Done()
+}
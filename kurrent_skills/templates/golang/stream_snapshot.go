@@ -0,0 +1,291 @@
+// KurrentDB Go Client Example - Projection snapshots stored in KurrentDB
+// Demonstrates: Saving projection state as an event in a $maxCount=1 stream and resuming from it after a restart
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === STREAM SNAPSHOT STORE ===
+
+// ProjectionSnapshotEventType is the event type of snapshots in the snapshot stream
+const ProjectionSnapshotEventType = "ProjectionSnapshot"
+
+// StreamSnapshotStore keeps the latest snapshot of one projection as the only event of a
+// dedicated stream, so a restarted process needs nothing but KurrentDB to resume. The event data
+// is the same versioned ProjectionSnapshot format as the file store, checkpoint included.
+type StreamSnapshotStore struct {
+	client    *kurrentdb.Client
+	stream    string
+	migrator  *SnapshotMigrator
+	compactor *SnapshotCompactor
+}
+
+// NewStreamSnapshotStore stores snapshots of the named projection in snapshot-{name}
+func NewStreamSnapshotStore(client *kurrentdb.Client, projectionName string, migrator *SnapshotMigrator) *StreamSnapshotStore {
+	return &StreamSnapshotStore{
+		client:    client,
+		stream:    Streams.Name("snapshot", projectionName),
+		migrator:  migrator,
+		compactor: NewSnapshotCompactor(client),
+	}
+}
+
+// WithCompactor replaces the default compactor, which keeps only the latest snapshot via $maxCount
+func (s *StreamSnapshotStore) WithCompactor(compactor *SnapshotCompactor) *StreamSnapshotStore {
+	s.compactor = compactor
+	return s
+}
+
+// Stream returns the snapshot stream name
+func (s *StreamSnapshotStore) Stream() string {
+	return s.stream
+}
+
+// Save appends the projection's state and checkpoint as a new snapshot event, then has the
+// compactor drop the snapshots it supersedes. With the default compactor the first save sets
+// $maxCount=1 on the stream, so older snapshots stop being readable at once and are removed by
+// the next scavenge.
+func (s *StreamSnapshotStore) Save(ctx context.Context, p *Projection) error {
+	data, err := encodeSnapshot(p)
+	if err != nil {
+		return err
+	}
+	result, err := s.client.AppendToStream(ctx, s.stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+		EventID:     uuid.New(),
+		EventType:   ProjectionSnapshotEventType,
+		ContentType: kurrentdb.ContentTypeJson,
+		Data:        data,
+	})
+	if err != nil {
+		return fmt.Errorf("append snapshot to %s: %w", s.stream, err)
+	}
+	// Compacting only after the append keeps a snapshot readable at every moment
+	if err := s.compactor.Compact(ctx, s.stream, result.NextExpectedVersion); err != nil {
+		return fmt.Errorf("compact snapshot stream %s: %w", s.stream, err)
+	}
+	return nil
+}
+
+// Load restores p from the latest snapshot, migrating older versions first. If the compactor
+// retains more than one snapshot, a latest one that fails to restore falls back to the one before
+// it. It returns false if no snapshot has been saved yet, in which case p is left untouched and
+// should replay from the start.
+//
+// Load always reads backwards from the end, never from a revision looked up beforehand, so a
+// compaction landing mid-load can only hide snapshots older than the ones it reads.
+func (s *StreamSnapshotStore) Load(ctx context.Context, p *Projection) (bool, error) {
+	events, err := s.client.ReadStream(ctx, s.stream, kurrentdb.ReadStreamOptions{
+		Direction: kurrentdb.Backwards,
+		From:      kurrentdb.End{},
+	}, s.compactor.Keep())
+	if err != nil {
+		if isStreamNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer events.Close()
+
+	var restoreErr error
+	for {
+		event, err := events.Recv()
+		if errors.Is(err, io.EOF) || isStreamNotFound(err) {
+			return false, restoreErr
+		}
+		if err != nil {
+			return false, err
+		}
+
+		recorded := Resolve(event, false)
+		if recorded.EventType != ProjectionSnapshotEventType {
+			return false, fmt.Errorf("unexpected %s event in snapshot stream %s", recorded.EventType, s.stream)
+		}
+		err = restoreSnapshot(fmt.Sprintf("%s@%d", s.stream, recorded.EventNumber), recorded.Data, p, s.migrator)
+		if err == nil {
+			return true, nil
+		}
+		// Report the latest snapshot's error if none of the retained ones restores
+		if restoreErr == nil {
+			restoreErr = err
+		}
+	}
+}
+
+// projectFromAll applies events from $all after from until count events were applied
+func projectFromAll(ctx context.Context, client *kurrentdb.Client, p *Projection, from kurrentdb.AllPosition, filter *kurrentdb.SubscriptionFilter, count int) (int, error) {
+	subscription, err := client.SubscribeToAll(ctx, kurrentdb.SubscribeToAllOptions{From: from, Filter: filter})
+	if err != nil {
+		return 0, err
+	}
+	defer subscription.Close()
+
+	applied := 0
+	for applied < count {
+		message := subscription.Recv()
+		if message.SubscriptionDropped != nil {
+			return applied, message.SubscriptionDropped.Error
+		}
+		if message.EventAppeared == nil {
+			continue
+		}
+		event := Resolve(message.EventAppeared, false)
+		ok, err := p.Apply(event, Resolve(message.EventAppeared, true).Position)
+		if err != nil {
+			return applied, err
+		}
+		if ok {
+			applied++
+		}
+	}
+	return applied, nil
+}
+
+// RunStreamSnapshot projects, snapshots to KurrentDB, "restarts", and resumes from the snapshot
+func RunStreamSnapshot() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	orderStream := Streams.Name("order", uuid.New().String())
+	filter := &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{orderStream}}
+
+	appendOrderEvent := func(eventType, data string) {
+		_, err := client.AppendToStream(ctx, orderStream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(data),
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	head, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+
+	// A unique projection name gives this run its own snapshot stream
+	projectionName := "OrderSummary" + uuid.New().String()[:8]
+	newProjection := func() *Projection {
+		p := NewOrderSummaryProjection()
+		p.Name = projectionName
+		return p
+	}
+
+	// === FIRST PROCESS ===
+	fmt.Println("\n=== First process ===")
+	first := newProjection()
+	store := NewStreamSnapshotStore(client, projectionName, OrderSummaryMigrations())
+
+	loaded, err := store.Load(ctx, first)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Snapshot on startup: %v (replaying from the start)\n", loaded)
+	noSnapshotYet := !loaded
+
+	appendOrderEvent("OrderCreated", `{"orderId":"1","customerId":"cust-1","amount":100}`)
+	appendOrderEvent("ItemAdded", `{"item":"Widget","price":25}`)
+	appendOrderEvent("ItemAdded", `{"item":"Gadget","price":10}`)
+
+	var from kurrentdb.AllPosition = head
+	for i := 0; i < 3; i++ {
+		if _, err := projectFromAll(ctx, client, first, from, filter, 1); err != nil {
+			panic(err)
+		}
+		from = *first.Checkpoint
+		// Snapshot after every event; $maxCount keeps only the latest
+		if err := store.Save(ctx, first); err != nil {
+			panic(err)
+		}
+	}
+	fmt.Printf("Projected 3 events, snapshot saved to %s at %d/%d\n", store.Stream(), first.Checkpoint.Commit, first.Checkpoint.Prepare)
+
+	// === WHILE DOWN ===
+	appendOrderEvent("ItemAdded", `{"item":"Gizmo","price":15}`)
+	appendOrderEvent("OrderShipped", `{"shippedAt":"2024-01-15T10:00:00Z"}`)
+	fmt.Println("\nProcess stopped; 2 more events appended")
+
+	// === RESTARTED PROCESS ===
+	fmt.Println("\n=== Restarted process ===")
+	restarted := newProjection()
+	restartedStore := NewStreamSnapshotStore(client, projectionName, OrderSummaryMigrations())
+
+	loaded, err = restartedStore.Load(ctx, restarted)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Snapshot on startup: %v, amount=%v, resuming after %d/%d\n",
+		loaded, restarted.Get(orderStream)["amount"], restarted.Checkpoint.Commit, restarted.Checkpoint.Prepare)
+
+	resumed, err := projectFromAll(ctx, client, restarted, *restarted.Checkpoint, filter, 2)
+	if err != nil {
+		panic(err)
+	}
+	state := restarted.Get(orderStream)
+	fmt.Printf("Applied %d events after the snapshot: status=%v amount=%v items=%v\n", resumed, state["status"], state["amount"], state["items"])
+
+	// === RETAINED SNAPSHOTS ===
+	retained := 0
+	events, err := client.ReadStream(ctx, store.Stream(), kurrentdb.ReadStreamOptions{From: kurrentdb.Start{}}, 100)
+	if err != nil {
+		panic(err)
+	}
+	for {
+		if _, err := events.Recv(); err != nil {
+			break
+		}
+		retained++
+	}
+	events.Close()
+	fmt.Printf("Readable snapshots in %s: %d\n", store.Stream(), retained)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if !noSnapshotYet {
+		fmt.Println("FAIL: A new projection should start without a snapshot")
+		passed = false
+	}
+	if !loaded {
+		fmt.Println("FAIL: The restarted process should load the snapshot")
+		passed = false
+	}
+	if resumed != 2 {
+		fmt.Printf("FAIL: Only the 2 events after the snapshot should be applied, got %d\n", resumed)
+		passed = false
+	}
+	if state["amount"] != float64(150) || state["status"] != "shipped" || len(stringSlice(state["items"])) != 3 {
+		fmt.Printf("FAIL: Expected shipped order worth 150 with 3 items, got %v\n", state)
+		passed = false
+	}
+	if retained != 1 {
+		fmt.Printf("FAIL: $maxCount=1 should leave 1 readable snapshot, got %d\n", retained)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll stream snapshot tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,362 @@
+// KurrentDB Go Client Example - Error policies for subscription handlers
+// Demonstrates: Failing fast, skipping or dead-lettering events a catch-up subscription handler fails on
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === ERROR POLICY ===
+
+// ErrorPolicy chooses what MeteredSubscription.Run does when the handler returns an error
+type ErrorPolicy int
+
+const (
+	// FailFast ends Run with the handler's error. The checkpoint stays at the last event handled,
+	// so after a fix the failed event is the first one delivered again.
+	FailFast ErrorPolicy = iota
+	// SkipAndLog logs the error and moves past the event. The event is lost to this read model,
+	// so keep it for handlers where a missing event is tolerable, like metrics.
+	SkipAndLog
+	// DeadLetter parks the event and its error in the DeadLetters sink and moves past it. If the
+	// sink fails, Run ends as under FailFast, since the event would otherwise be lost.
+	DeadLetter
+)
+
+func (p ErrorPolicy) String() string {
+	switch p {
+	case FailFast:
+		return "FailFast"
+	case SkipAndLog:
+		return "SkipAndLog"
+	case DeadLetter:
+		return "DeadLetter"
+	}
+	return fmt.Sprintf("ErrorPolicy(%d)", int(p))
+}
+
+// DeadLetterSink stores events a handler failed on, for inspection and replay
+type DeadLetterSink interface {
+	DeadLetter(ctx context.Context, event *kurrentdb.ResolvedEvent, cause error) error
+}
+
+// DeadLetterFunc adapts a function to a DeadLetterSink
+type DeadLetterFunc func(ctx context.Context, event *kurrentdb.ResolvedEvent, cause error) error
+
+func (f DeadLetterFunc) DeadLetter(ctx context.Context, event *kurrentdb.ResolvedEvent, cause error) error {
+	return f(ctx, event, cause)
+}
+
+// handleFailure applies the error policy to a handler error; nil means Run moves past the event
+func (s *MeteredSubscription) handleFailure(ctx context.Context, event *kurrentdb.ResolvedEvent, err error) error {
+	recorded := Resolve(event, false)
+	switch s.OnError {
+	case FailFast:
+		return err
+	case SkipAndLog:
+		fmt.Printf("  [subscription] skipped %s@%d (%s): %v\n", recorded.StreamID, recorded.EventNumber, recorded.EventType, err)
+		s.Metrics.recordSkipped()
+		return nil
+	case DeadLetter:
+		if s.DeadLetters == nil {
+			return fmt.Errorf("no dead-letter sink: %w", err)
+		}
+		if sinkErr := s.DeadLetters.DeadLetter(ctx, event, err); sinkErr != nil {
+			return errors.Join(err, fmt.Errorf("dead-letter %s@%d: %w", recorded.StreamID, recorded.EventNumber, sinkErr))
+		}
+		s.Metrics.recordDeadLetter()
+		return nil
+	}
+	return fmt.Errorf("unknown error policy %s: %w", s.OnError, err)
+}
+
+// === DEAD-LETTER STREAM ===
+
+// Metadata keys StreamDeadLetters adds to a parked event
+const (
+	MetaDeadLetterStream   = "deadLetterStream"
+	MetaDeadLetterRevision = "deadLetterRevision"
+	MetaDeadLetterPosition = "deadLetterPosition"
+	MetaDeadLetterError    = "deadLetterError"
+)
+
+// StreamDeadLetters parks failed events in a stream: a copy of each, with its origin and the error
+// in metadata, caused by the original. Exclude that stream from the subscription's filter, or a
+// parked event is delivered again and, failing again, parked again.
+type StreamDeadLetters struct {
+	store  EventStore
+	stream string
+}
+
+func NewStreamDeadLetters(store EventStore, stream string) *StreamDeadLetters {
+	return &StreamDeadLetters{store: store, stream: stream}
+}
+
+// Stream returns the dead-letter stream name
+func (d *StreamDeadLetters) Stream() string {
+	return d.stream
+}
+
+// DeadLetter appends the parked copy. Its ID derives from the original's, so parking an event again
+// after a crash, before the checkpoint moved past it, is deduplicated by the server.
+func (d *StreamDeadLetters) DeadLetter(ctx context.Context, event *kurrentdb.ResolvedEvent, cause error) error {
+	recorded := Resolve(event, false)
+	position := Resolve(event, true).Position
+
+	meta := CausedBy(recorded)
+	meta[MetaDeadLetterStream] = recorded.StreamID
+	meta[MetaDeadLetterRevision] = strconv.FormatUint(recorded.EventNumber, 10)
+	meta[MetaDeadLetterPosition] = fmt.Sprintf("%d/%d", position.Commit, position.Prepare)
+	meta[MetaDeadLetterError] = cause.Error()
+	metadata, err := meta.Marshal()
+	if err != nil {
+		return err
+	}
+
+	_, err = d.store.AppendToStream(ctx, d.stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+		EventID:     uuid.NewSHA1(recorded.EventID, []byte("dead-letter")),
+		EventType:   recorded.EventType,
+		ContentType: recordedContentType(recorded),
+		Data:        recorded.Data,
+		Metadata:    metadata,
+	})
+	return err
+}
+
+// errPoison is what the demo handlers return for a Poison event
+var errPoison = errors.New("cannot handle poison event")
+
+// poisonHandler records the types it handles and fails on Poison events
+func poisonHandler(handled *[]string) func(*kurrentdb.ResolvedEvent) error {
+	return func(event *kurrentdb.ResolvedEvent) error {
+		recorded := Resolve(event, false)
+		if recorded.EventType == "Poison" {
+			return errPoison
+		}
+		*handled = append(*handled, recorded.EventType)
+		return nil
+	}
+}
+
+// RunErrorPolicyChecks runs each policy over an in-memory log with a poison event, no server required
+func RunErrorPolicyChecks() {
+	checks := newChecker("error policy")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	dir, err := os.MkdirTemp("", "error-policy")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := NewMemoryEventStore()
+	var last kurrentdb.Position
+	for _, eventType := range []string{"OrderCreated", "Poison", "OrderShipped"} {
+		written, err := store.AppendToStream(ctx, "order-1", kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventType:   eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(`{"orderId":"1"}`),
+		})
+		if err != nil {
+			panic(err)
+		}
+		last = kurrentdb.Position{Commit: written.CommitPosition, Prepare: written.PreparePosition}
+	}
+	const created = 100
+
+	// run catches up on the order events under policy; it returns the types handled, the saved
+	// checkpoint's commit position, the metrics and Run's error
+	run := func(name string, policy ErrorPolicy, sink DeadLetterSink) ([]string, uint64, MetricsSnapshot, error) {
+		metered := NewMeteredSubscription(nil, kurrentdb.SubscribeToAllOptions{
+			From:   kurrentdb.Start{},
+			Filter: &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{"order-"}},
+		})
+		metered.subscribe = store.SubscribeToAll
+		metered.readHead = func(context.Context) (kurrentdb.Position, error) { return last, nil }
+		metered.Checkpoints = FileCheckpoint{Path: filepath.Join(dir, name+".json")}
+		metered.StopAt = &last
+		metered.OnError = policy
+		metered.DeadLetters = sink
+
+		var handled []string
+		runErr := metered.Run(ctx, poisonHandler(&handled))
+		var checkpoint uint64
+		if saved, _ := metered.Checkpoints.Load(); saved != nil {
+			checkpoint = saved.Commit
+		}
+		return handled, checkpoint, metered.Stats(), runErr
+	}
+
+	// --- FailFast ---
+	fmt.Println("\n--- FailFast ---")
+	handled, checkpoint, _, err := run("fail-fast", FailFast, nil)
+	fmt.Printf("  handled %v, checkpoint %d, err %v\n", handled, checkpoint, err)
+	checks.Check(errors.Is(err, errPoison), "FailFast should return the handler's error, got %v", err)
+	checks.Check(fmt.Sprint(handled) == "[OrderCreated]", "FailFast should stop at the poison event, got %v", handled)
+	checks.Check(checkpoint == created, "FailFast should leave the checkpoint before the poison event, got %d", checkpoint)
+
+	// --- SkipAndLog ---
+	fmt.Println("\n--- SkipAndLog ---")
+	handled, checkpoint, stats, err := run("skip", SkipAndLog, nil)
+	checks.Check(err == nil, "SkipAndLog should not fail, got %v", err)
+	checks.Check(fmt.Sprint(handled) == "[OrderCreated OrderShipped]", "SkipAndLog should handle the events after the poison one, got %v", handled)
+	checks.Check(checkpoint == last.Commit, "SkipAndLog should advance the checkpoint past the poison event, got %d", checkpoint)
+	checks.Check(stats.SkippedTotal == 1 && stats.DeadLettersTotal == 0, "SkipAndLog should count 1 skip, got %+v", stats)
+
+	// --- DeadLetter ---
+	fmt.Println("\n--- DeadLetter ---")
+	deadLetters := NewStreamDeadLetters(store, "deadletter-orders")
+	handled, checkpoint, stats, err = run("dead-letter", DeadLetter, deadLetters)
+	parked := store.streams[deadLetters.Stream()]
+	checks.Check(err == nil, "DeadLetter should not fail, got %v", err)
+	checks.Check(fmt.Sprint(handled) == "[OrderCreated OrderShipped]", "DeadLetter should handle the events after the poison one, got %v", handled)
+	checks.Check(checkpoint == last.Commit, "DeadLetter should advance the checkpoint past the poison event, got %d", checkpoint)
+	checks.Check(stats.DeadLettersTotal == 1, "DeadLetter should count 1 dead letter, got %+v", stats)
+	checks.Check(len(parked) == 1, "the poison event should be parked once, got %d", len(parked))
+	if len(parked) == 1 {
+		var meta Meta
+		checks.Check(meta.UnmarshalFrom(parked[0]) == nil, "the parked event's metadata should decode")
+		fmt.Printf("  parked %s with %v\n", parked[0].EventType, meta)
+		checks.Check(parked[0].EventType == "Poison" && string(parked[0].Data) == `{"orderId":"1"}`, "the parked copy should keep type and data, got %s %s", parked[0].EventType, parked[0].Data)
+		checks.Check(meta[MetaDeadLetterStream] == "order-1" && meta[MetaDeadLetterRevision] == "1" && meta[MetaDeadLetterError] == errPoison.Error(),
+			"the parked copy should record its origin and error, got %v", meta)
+		checks.Check(meta.Causation() != "", "the parked copy should be caused by the original")
+	}
+
+	// --- DeadLetter without a working sink ---
+	fmt.Println("\n--- DeadLetter, sink failing ---")
+	errSinkDown := errors.New("sink down")
+	_, checkpoint, _, err = run("sink-failing", DeadLetter, DeadLetterFunc(func(context.Context, *kurrentdb.ResolvedEvent, error) error {
+		return errSinkDown
+	}))
+	fmt.Printf("  err %v\n", err)
+	checks.Check(errors.Is(err, errPoison) && errors.Is(err, errSinkDown), "a failing sink should end Run with both errors, got %v", err)
+	checks.Check(checkpoint == created, "a failing sink should leave the checkpoint before the poison event, got %d", checkpoint)
+	_, _, _, err = run("no-sink", DeadLetter, nil)
+	checks.Check(errors.Is(err, errPoison), "DeadLetter without a sink should fail fast, got %v", err)
+
+	checks.Done()
+}
+
+// RunErrorPolicy catches up on an order stream holding a poison event under each policy
+func RunErrorPolicy() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	dir, err := os.MkdirTemp("", "error-policy")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// === SETUP ===
+	orderStream := Streams.Name("order", uuid.New().String())
+	head, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+	var positions []kurrentdb.Position
+	for _, eventType := range []string{"OrderCreated", "Poison", "OrderShipped"} {
+		written, err := AppendAndPosition(ctx, client, orderStream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(`{"orderId":"1"}`),
+		})
+		if err != nil {
+			panic(err)
+		}
+		positions = append(positions, written.Position)
+	}
+	fmt.Printf("Wrote OrderCreated, Poison, OrderShipped to %s\n", orderStream)
+
+	// run catches up on the order stream under policy; it returns the types handled, the saved
+	// checkpoint and Run's error
+	run := func(policy ErrorPolicy, sink DeadLetterSink) ([]string, kurrentdb.Position, error) {
+		metered := NewMeteredSubscription(client, kurrentdb.SubscribeToAllOptions{
+			From:   head,
+			Filter: &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{orderStream}},
+		})
+		metered.Checkpoints = FileCheckpoint{Path: filepath.Join(dir, policy.String()+".json")}
+		metered.StopAt = &positions[2]
+		metered.OnError = policy
+		metered.DeadLetters = sink
+
+		var handled []string
+		runErr := metered.Run(ctx, poisonHandler(&handled))
+		var checkpoint kurrentdb.Position
+		if saved, _ := metered.Checkpoints.Load(); saved != nil {
+			checkpoint = *saved
+		}
+		fmt.Printf("  handled %v, checkpoint %d/%d, err %v\n", handled, checkpoint.Commit, checkpoint.Prepare, runErr)
+		return handled, checkpoint, runErr
+	}
+
+	// === FAIL FAST ===
+	fmt.Println("\n=== FailFast ===")
+	failHandled, failCheckpoint, failErr := run(FailFast, nil)
+
+	// === SKIP AND LOG ===
+	fmt.Println("\n=== SkipAndLog ===")
+	skipHandled, skipCheckpoint, skipErr := run(SkipAndLog, nil)
+
+	// === DEAD LETTER ===
+	fmt.Println("\n=== DeadLetter ===")
+	deadLetters := NewStreamDeadLetters(NewClientStore(client), Streams.Name("deadletter", uuid.New().String()))
+	deadHandled, deadCheckpoint, deadErr := run(DeadLetter, deadLetters)
+	parked, _, err := readStreamFrom(ctx, NewClientStore(client), deadLetters.Stream(), NoVersion)
+	if err != nil {
+		panic(err)
+	}
+	var parkedMeta Meta
+	if len(parked) > 0 {
+		parkedMeta.UnmarshalFrom(parked[0])
+		fmt.Printf("  %s holds %d event(s): %s from %s@%s: %s\n", deadLetters.Stream(), len(parked), parked[0].EventType,
+			parkedMeta[MetaDeadLetterStream], parkedMeta[MetaDeadLetterRevision], parkedMeta[MetaDeadLetterError])
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if !errors.Is(failErr, errPoison) || fmt.Sprint(failHandled) != "[OrderCreated]" || failCheckpoint != positions[0] {
+		fmt.Printf("FAIL: FailFast should stop at the poison event with the checkpoint before it, got %v, %v, %v\n", failHandled, failErr, failCheckpoint)
+		passed = false
+	}
+	if skipErr != nil || fmt.Sprint(skipHandled) != "[OrderCreated OrderShipped]" || skipCheckpoint != positions[2] {
+		fmt.Printf("FAIL: SkipAndLog should move past the poison event to the end, got %v, %v, %v\n", skipHandled, skipErr, skipCheckpoint)
+		passed = false
+	}
+	if deadErr != nil || fmt.Sprint(deadHandled) != "[OrderCreated OrderShipped]" || deadCheckpoint != positions[2] {
+		fmt.Printf("FAIL: DeadLetter should move past the poison event to the end, got %v, %v, %v\n", deadHandled, deadErr, deadCheckpoint)
+		passed = false
+	}
+	if len(parked) != 1 || parked[0].EventType != "Poison" || parkedMeta[MetaDeadLetterStream] != orderStream || parkedMeta[MetaDeadLetterRevision] != "1" {
+		fmt.Printf("FAIL: The poison event should be parked once with its origin, got %d events, %v\n", len(parked), parkedMeta)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll error policy tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
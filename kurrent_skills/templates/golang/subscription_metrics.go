@@ -0,0 +1,951 @@
+// KurrentDB Go Client Example - Subscription metrics sidecar
+// Demonstrates: Wrapping a catch-up subscription with throughput, position, lag and health metrics
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === METRICS ===
+
+// SubscriptionMetrics collects per-subscription counters. Safe for concurrent use.
+type SubscriptionMetrics struct {
+	mu sync.Mutex
+
+	events   uint64
+	bytes    uint64
+	position kurrentdb.Position
+	head     kurrentdb.Position
+	// positioned is whether position was set by an event or checkpoint since the last resetPosition
+	positioned  bool
+	reconnects  int
+	stalls      int
+	skipped     uint64
+	deadLetters uint64
+	lastEventAt time.Time
+
+	// Time lag: handlingCreated is the Created time of the event in the handler (zero between
+	// events), eventLag how old the last handled event was when it finished, and caughtUp whether
+	// nothing was pending at the last caught-up signal or head sample
+	handlingCreated time.Time
+	eventLag        time.Duration
+	caughtUp        bool
+
+	// rates are computed by Sample from the counters above
+	sampledAt    time.Time
+	sampleEvents uint64
+	sampleBytes  uint64
+	eventsPerSec float64
+	bytesPerSec  float64
+}
+
+// MetricsSnapshot is the JSON shape served by the metrics endpoint
+type MetricsSnapshot struct {
+	EventsTotal         uint64  `json:"eventsTotal"`
+	BytesTotal          uint64  `json:"bytesTotal"`
+	EventsPerSec        float64 `json:"eventsPerSec"`
+	BytesPerSec         float64 `json:"bytesPerSec"`
+	CommitPosition      uint64  `json:"commitPosition"`
+	PreparePosition     uint64  `json:"preparePosition"`
+	HeadCommitPosition  uint64  `json:"headCommitPosition"`
+	Lag                 uint64  `json:"lag"`
+	Reconnects          int     `json:"reconnects"`
+	Stalls              int     `json:"stalls"`
+	SkippedTotal        uint64  `json:"skippedTotal"`
+	DeadLettersTotal    uint64  `json:"deadLettersTotal"`
+	LastEventAgeSeconds float64 `json:"lastEventAgeSeconds"`
+	TimeLagSeconds      float64 `json:"timeLagSeconds"`
+}
+
+func NewSubscriptionMetrics() *SubscriptionMetrics {
+	return &SubscriptionMetrics{sampledAt: time.Now()}
+}
+
+// startEvent marks event as being handled, so time lag keeps growing while a handler is slow
+func (m *SubscriptionMetrics) startEvent(event *kurrentdb.RecordedEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.handlingCreated = event.CreatedDate
+	m.caughtUp = false
+}
+
+func (m *SubscriptionMetrics) recordEvent(event *kurrentdb.RecordedEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.events++
+	m.bytes += uint64(len(event.Data) + len(event.UserMetadata))
+	m.position = event.Position
+	m.positioned = true
+	m.lastEventAt = time.Now()
+	m.eventLag = max(0, m.lastEventAt.Sub(event.CreatedDate))
+	m.handlingCreated = time.Time{}
+}
+
+func (m *SubscriptionMetrics) recordCaughtUp() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.caughtUp = m.handlingCreated.IsZero()
+}
+
+func (m *SubscriptionMetrics) recordCheckpoint(position kurrentdb.Position) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.position = position
+	m.positioned = true
+}
+
+// resetPosition forgets the last position, so a new Run starts where its startup mode says rather
+// than where a previous Run left off. The counters keep accumulating.
+func (m *SubscriptionMetrics) resetPosition() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.position = kurrentdb.Position{}
+	m.positioned = false
+}
+
+func (m *SubscriptionMetrics) recordReconnect() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reconnects++
+}
+
+func (m *SubscriptionMetrics) recordStall() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stalls++
+}
+
+func (m *SubscriptionMetrics) recordSkipped() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.skipped++
+}
+
+func (m *SubscriptionMetrics) recordDeadLetter() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.deadLetters++
+}
+
+// TimeLag is how far behind in wall-clock time the subscription is: the age of the event being
+// handled, or else of the last one handled when it finished. It doesn't grow while idle: with no
+// new events it stays at the last value, and drops to zero on a caught-up signal or a head sample
+// at our position. Created comes from the server's clock, so skew against the local clock shows
+// up here; negative values are reported as zero.
+func (m *SubscriptionMetrics) TimeLag() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.timeLag()
+}
+
+func (m *SubscriptionMetrics) timeLag() time.Duration {
+	switch {
+	case !m.handlingCreated.IsZero():
+		return max(0, time.Since(m.handlingCreated))
+	case m.caughtUp:
+		return 0
+	}
+	return m.eventLag
+}
+
+func (m *SubscriptionMetrics) lastPosition() (kurrentdb.Position, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.position, m.positioned
+}
+
+// Snapshot returns a consistent copy of the current metrics
+func (m *SubscriptionMetrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := MetricsSnapshot{
+		EventsTotal:        m.events,
+		BytesTotal:         m.bytes,
+		EventsPerSec:       m.eventsPerSec,
+		BytesPerSec:        m.bytesPerSec,
+		CommitPosition:     m.position.Commit,
+		PreparePosition:    m.position.Prepare,
+		HeadCommitPosition: m.head.Commit,
+		Reconnects:         m.reconnects,
+		Stalls:             m.stalls,
+		SkippedTotal:       m.skipped,
+		DeadLettersTotal:   m.deadLetters,
+		TimeLagSeconds:     m.timeLag().Seconds(),
+	}
+	if m.head.Commit > m.position.Commit {
+		snapshot.Lag = m.head.Commit - m.position.Commit
+	}
+	if !m.lastEventAt.IsZero() {
+		snapshot.LastEventAgeSeconds = time.Since(m.lastEventAt).Seconds()
+	}
+	return snapshot
+}
+
+// Sample refreshes the $all head position and recomputes the per-second rates
+func (m *SubscriptionMetrics) Sample(ctx context.Context, client *kurrentdb.Client) error {
+	head, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(m.sampledAt).Seconds()
+	if elapsed > 0 {
+		m.eventsPerSec = float64(m.events-m.sampleEvents) / elapsed
+		m.bytesPerSec = float64(m.bytes-m.sampleBytes) / elapsed
+	}
+	m.sampledAt = now
+	m.sampleEvents = m.events
+	m.sampleBytes = m.bytes
+	m.head = head
+	if m.handlingCreated.IsZero() && !positionAfter(head, m.position) {
+		m.caughtUp = true
+	}
+	return nil
+}
+
+// ServeHTTP exposes the snapshot as JSON, e.g. on /metrics
+func (m *SubscriptionMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m.Snapshot())
+}
+
+// Report samples every interval and logs a one-line summary until ctx is cancelled
+func (m *SubscriptionMetrics) Report(ctx context.Context, client *kurrentdb.Client, interval time.Duration, logf func(MetricsSnapshot)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Sample(ctx, client); err != nil {
+				if ctx.Err() == nil {
+					fmt.Printf("  [metrics] sample failed: %v\n", err)
+				}
+				continue
+			}
+			logf(m.Snapshot())
+		}
+	}
+}
+
+// readAllHead returns the position of the last event in $all, and true if there is none
+func readAllHead(ctx context.Context, store EventStore) (kurrentdb.Position, bool, error) {
+	reader, err := store.ReadAll(ctx, kurrentdb.ReadAllOptions{
+		Direction: kurrentdb.Backwards,
+		From:      kurrentdb.End{},
+	}, 1)
+	if err != nil {
+		return kurrentdb.Position{}, false, err
+	}
+	defer reader.Close()
+
+	event, err := reader.Recv()
+	if errors.Is(err, io.EOF) {
+		return kurrentdb.Position{}, true, nil
+	}
+	if err != nil {
+		return kurrentdb.Position{}, false, err
+	}
+	return Resolve(event, true).Position, false, nil
+}
+
+// === METERED SUBSCRIPTION ===
+
+// StartupMode chooses where MeteredSubscription.Run starts
+type StartupMode int
+
+const (
+	// FromCheckpoint resumes from the saved checkpoint, or behaves like ReplayAll when there is
+	// none (or no Checkpoints store). The usual choice for read models: nothing is missed or
+	// processed twice across restarts.
+	FromCheckpoint StartupMode = iota
+	// ReplayAll starts from the options' From, the beginning of $all unless set, ignoring any saved
+	// checkpoint. Use it to rebuild a read model; handlers see every historical event again, so
+	// side effects like emails must be idempotent or switched off.
+	ReplayAll
+	// LiveOnly starts at the head of $all when Run is called, ignoring history and any checkpoint.
+	// Right for notifications that only matter now, but events appended while the process was down
+	// are never processed: pair it with FromCheckpoint if gaps are not acceptable.
+	LiveOnly
+)
+
+func (m StartupMode) String() string {
+	switch m {
+	case FromCheckpoint:
+		return "FromCheckpoint"
+	case ReplayAll:
+		return "ReplayAll"
+	case LiveOnly:
+		return "LiveOnly"
+	}
+	return fmt.Sprintf("StartupMode(%d)", int(m))
+}
+
+// MeteredSubscription runs a $all catch-up subscription, recording metrics and
+// resubscribing from the last seen position when the subscription drops
+type MeteredSubscription struct {
+	client    *kurrentdb.Client
+	options   kurrentdb.SubscribeToAllOptions
+	reconnect Backoff
+	Metrics   *SubscriptionMetrics
+
+	// IdleTimeout enables the stall watchdog: if nothing (event, checkpoint or caught-up) arrives
+	// for this long while $all has events past our position, the subscription is restarted.
+	// Zero disables the watchdog.
+	IdleTimeout time.Duration
+
+	// Checkpoints, if set, restores the position on Run and saves it when the subscription catches
+	// up, when the server sends a filter checkpoint, and when Run returns. Saving on caught-up means a
+	// heavily filtered subscription that delivers nothing still restarts near the head.
+	Checkpoints CheckpointStore
+
+	// Startup chooses the starting point on Run; checkpoints are still saved in every mode.
+	// Reconnects within a Run always resume from the last position, but a later Run on the same
+	// MeteredSubscription starts afresh from its startup mode.
+	Startup StartupMode
+
+	// StopAt, if set, bounds the catch-up: events up to and including this $all position are
+	// handled, then Run returns nil. Reaching it is also detected from a filter checkpoint or a
+	// caught-up at or past it, so a filtered subscription stops even if no later event matches.
+	// If ctx ends first, Run returns ErrStopPositionNotReached.
+	StopAt *kurrentdb.Position
+
+	// OnError chooses what happens when the handler returns an error: FailFast (the default) ends
+	// Run with it, SkipAndLog and DeadLetter move past the event. DeadLetters is where DeadLetter
+	// parks failed events.
+	OnError     ErrorPolicy
+	DeadLetters DeadLetterSink
+
+	subscribe func(ctx context.Context, options kurrentdb.SubscribeToAllOptions) (EventSubscription, error)
+	readHead  func(ctx context.Context) (kurrentdb.Position, error)
+	// subscribeHead is the $all head read just before the current subscription started; nil if it
+	// couldn't be read
+	subscribeHead *kurrentdb.Position
+	// lastStallHead is the head seen at the last forced restart. A filtered subscription can sit
+	// below a head made of filtered-out events, so the watchdog restarts at most once per head.
+	lastStallHead kurrentdb.Position
+	// startedFrom is where the current Run started, after the startup mode and End were resolved
+	startedFrom kurrentdb.AllPosition
+	// stopped is set once the current Run has reached StopAt
+	stopped bool
+
+	// onInit and onClose are the lifecycle hooks set by Init and Close
+	onInit  func() error
+	onClose func() error
+
+	// pauseMu guards the pause state and the cancel func of the live subscription
+	pauseMu           sync.Mutex
+	paused            bool
+	resumed           chan struct{}
+	closeSubscription context.CancelFunc
+}
+
+func NewMeteredSubscription(client *kurrentdb.Client, options kurrentdb.SubscribeToAllOptions) *MeteredSubscription {
+	return &MeteredSubscription{
+		client:    client,
+		options:   options,
+		reconnect: NewBackoff(time.Second, 30*time.Second),
+		Metrics:   NewSubscriptionMetrics(),
+		subscribe: func(ctx context.Context, options kurrentdb.SubscribeToAllOptions) (EventSubscription, error) {
+			return client.SubscribeToAll(ctx, options)
+		},
+		readHead: func(ctx context.Context) (kurrentdb.Position, error) {
+			head, _, err := readAllHead(ctx, NewClientStore(client))
+			return head, err
+		},
+	}
+}
+
+// Init registers fn to run at the start of every Run, before the checkpoint is loaded and anything
+// is replayed, e.g. to open the read model's database or seed defaults. An error from fn stops Run
+// before it subscribes.
+func (s *MeteredSubscription) Init(fn func() error) *MeteredSubscription {
+	s.onInit = fn
+	return s
+}
+
+// Close registers fn to run whenever Run returns: after a cancel, a handler error, a failed Init or a
+// handler panic, and after the final checkpoint save. Since it also follows a failed Init, fn must
+// cope with setup that only partly happened.
+func (s *MeteredSubscription) Close(fn func() error) *MeteredSubscription {
+	s.onClose = fn
+	return s
+}
+
+// Run delivers events to handler until ctx is cancelled or, under FailFast, the handler fails
+func (s *MeteredSubscription) Run(ctx context.Context, handler func(*kurrentdb.ResolvedEvent) error) (err error) {
+	if s.onClose != nil {
+		defer func() {
+			if closeErr := s.onClose(); closeErr != nil {
+				err = errors.Join(err, fmt.Errorf("close: %w", closeErr))
+			}
+		}()
+	}
+	if s.onInit != nil {
+		if err := s.onInit(); err != nil {
+			return fmt.Errorf("init: %w", err)
+		}
+	}
+
+	s.stopped = false
+	if err := s.run(ctx, handler); err != nil {
+		return err
+	}
+	if s.StopAt != nil && !s.stopped {
+		return fmt.Errorf("%w: %d/%d: %w", ErrStopPositionNotReached, s.StopAt.Commit, s.StopAt.Prepare, context.Cause(ctx))
+	}
+	return nil
+}
+
+func (s *MeteredSubscription) run(ctx context.Context, handler func(*kurrentdb.ResolvedEvent) error) error {
+	options := s.options
+	if options.From == nil {
+		options.From = kurrentdb.Start{}
+	}
+
+	// The resume position belongs to one Run: a reused Metrics must not carry it into this one
+	s.Metrics.resetPosition()
+
+	switch s.Startup {
+	case FromCheckpoint:
+		if s.Checkpoints == nil {
+			break
+		}
+		saved, err := s.Checkpoints.Load()
+		if err != nil {
+			return fmt.Errorf("load checkpoint: %w", err)
+		}
+		if saved != nil {
+			options.From = *saved
+			s.Metrics.recordCheckpoint(*saved)
+		}
+	case ReplayAll:
+	case LiveOnly:
+		options.From = kurrentdb.End{}
+	default:
+		return fmt.Errorf("unknown startup mode %s", s.Startup)
+	}
+	if s.Checkpoints != nil {
+		defer s.saveCheckpoint()
+	}
+
+	// Pin End to the current head, so resubscribing before the first event (after a drop or a
+	// pause) doesn't skip what was appended in between
+	if _, fromEnd := options.From.(kurrentdb.End); fromEnd {
+		head, _, err := readAllHead(ctx, NewClientStore(s.client))
+		if err != nil {
+			return err
+		}
+		options.From = head
+	}
+	s.startedFrom = options.From
+
+	reconnect := s.reconnect
+	for {
+		if !s.waitWhilePaused(ctx) {
+			return nil
+		}
+		if position, ok := s.Metrics.lastPosition(); ok {
+			options.From = position
+		}
+
+		s.readSubscribeHead(ctx)
+
+		subscriptionCtx, cancel := context.WithCancel(ctx)
+		s.setCloseSubscription(cancel)
+
+		subscribed := time.Now()
+		subscription, err := s.subscribe(subscriptionCtx, options)
+		if err == nil {
+			err = s.consume(subscriptionCtx, subscription, handler)
+			subscription.Close()
+		}
+		cancel()
+
+		if errors.Is(err, errStopAtReached) {
+			s.stopped = true
+			return nil
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if _, isHandlerErr := err.(handlerError); isHandlerErr {
+			return err
+		}
+		if s.Paused() {
+			position, _ := s.Metrics.lastPosition()
+			fmt.Printf("  [supervisor] paused at %d/%d\n", position.Commit, position.Prepare)
+			continue
+		}
+
+		if errors.Is(err, errSubscriptionStalled) {
+			fmt.Printf("  [watchdog] WARNING: %v, forcing reconnect\n", err)
+			s.Metrics.recordStall()
+		} else {
+			fmt.Printf("  [metrics] subscription dropped, reconnecting: %v\n", err)
+		}
+		s.Metrics.recordReconnect()
+
+		// A subscription that stayed up for a while was healthy: start the delays over
+		if time.Since(subscribed) > reconnect.Max {
+			reconnect.Reset()
+		}
+		if reconnect.Wait(ctx) != nil {
+			return nil
+		}
+	}
+}
+
+// Stats returns the subscription's current metrics
+func (s *MeteredSubscription) Stats() MetricsSnapshot {
+	return s.Metrics.Snapshot()
+}
+
+// TimeLag returns how far behind in wall-clock time the handler is; see SubscriptionMetrics.TimeLag
+func (s *MeteredSubscription) TimeLag() time.Duration {
+	return s.Metrics.TimeLag()
+}
+
+// === PAUSE / RESUME ===
+
+// Pause stops delivery and closes the subscription without losing its place. A handler call already
+// in progress finishes; no new one starts. Nothing is buffered: Resume resubscribes from the last
+// handled position, so events appended meanwhile are delivered then.
+func (s *MeteredSubscription) Pause() {
+	s.pauseMu.Lock()
+	defer s.pauseMu.Unlock()
+
+	if s.paused {
+		return
+	}
+	s.paused = true
+	s.resumed = make(chan struct{})
+	if s.closeSubscription != nil {
+		s.closeSubscription()
+	}
+}
+
+// Resume reconnects from the last handled position
+func (s *MeteredSubscription) Resume() {
+	s.pauseMu.Lock()
+	defer s.pauseMu.Unlock()
+
+	if !s.paused {
+		return
+	}
+	s.paused = false
+	close(s.resumed)
+}
+
+// Paused reports whether delivery is paused
+func (s *MeteredSubscription) Paused() bool {
+	s.pauseMu.Lock()
+	defer s.pauseMu.Unlock()
+
+	return s.paused
+}
+
+// waitWhilePaused blocks until resumed; false means ctx ended first
+func (s *MeteredSubscription) waitWhilePaused(ctx context.Context) bool {
+	for {
+		s.pauseMu.Lock()
+		paused, resumed := s.paused, s.resumed
+		s.pauseMu.Unlock()
+
+		if !paused {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-resumed:
+		}
+	}
+}
+
+// setCloseSubscription registers the live subscription's cancel func, closing it at once if a
+// Pause raced with the resubscribe
+func (s *MeteredSubscription) setCloseSubscription(cancel context.CancelFunc) {
+	s.pauseMu.Lock()
+	defer s.pauseMu.Unlock()
+
+	s.closeSubscription = cancel
+	if s.paused {
+		cancel()
+	}
+}
+
+// handlerError marks an error returned by the handler, which ends Run instead of reconnecting
+type handlerError struct{ error }
+
+func (e handlerError) Unwrap() error { return e.error }
+
+// ErrStopPositionNotReached is returned by Run when ctx ends before StopAt is reached
+var ErrStopPositionNotReached = errors.New("stop position not reached")
+
+var (
+	errSubscriptionStalled = errors.New("subscription stalled")
+	errSubscriptionPaused  = errors.New("subscription paused")
+	errStopAtReached       = errors.New("stop position reached")
+)
+
+func (s *MeteredSubscription) consume(ctx context.Context, subscription EventSubscription, handler func(*kurrentdb.ResolvedEvent) error) error {
+	if s.IdleTimeout <= 0 {
+		for {
+			if err := s.dispatch(ctx, subscription.Recv(), handler); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Recv blocks, so it runs on its own goroutine and the watchdog selects on its output.
+	// Closing the subscription (done by Run) unblocks Recv and ends the pump.
+	events := make(chan *kurrentdb.SubscriptionEvent)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			event := subscription.Recv()
+			select {
+			case events <- event:
+			case <-stop:
+				return
+			}
+			if event.SubscriptionDropped != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(s.IdleTimeout / 4)
+	defer ticker.Stop()
+	lastActivity := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event := <-events:
+			if err := s.dispatch(ctx, event, handler); err != nil {
+				return err
+			}
+			// Measured from when the handler returns, so a slow handler isn't mistaken for a stall
+			lastActivity = time.Now()
+		case <-ticker.C:
+			idle := time.Since(lastActivity)
+			if idle < s.IdleTimeout {
+				continue
+			}
+			stalled, err := s.behindHead(ctx)
+			if err != nil {
+				fmt.Printf("  [watchdog] head check failed: %v\n", err)
+			}
+			if stalled {
+				return fmt.Errorf("%w: idle for %s while behind the head", errSubscriptionStalled, idle.Round(time.Millisecond))
+			}
+			// Genuinely idle and caught up: wait another full timeout before checking again
+			lastActivity = time.Now()
+		}
+	}
+}
+
+// behindHead reports whether $all has moved past our position since the last forced restart
+func (s *MeteredSubscription) behindHead(ctx context.Context) (bool, error) {
+	head, _, err := readAllHead(ctx, NewClientStore(s.client))
+	if err != nil {
+		return false, err
+	}
+	position, ok := s.Metrics.lastPosition()
+	if !ok {
+		// Nothing received yet: compare against where the subscription started
+		switch from := s.startedFrom.(type) {
+		case kurrentdb.Position:
+			position = from
+		case kurrentdb.End:
+			return false, nil
+		}
+	}
+	if !positionAfter(head, position) || head == s.lastStallHead {
+		return false, nil
+	}
+	s.lastStallHead = head
+	return true, nil
+}
+
+// dispatch handles one subscription message; a non-nil error ends the current subscription
+func (s *MeteredSubscription) dispatch(ctx context.Context, event *kurrentdb.SubscriptionEvent, handler func(*kurrentdb.ResolvedEvent) error) error {
+	if event.SubscriptionDropped != nil {
+		if event.SubscriptionDropped.Error != nil {
+			return event.SubscriptionDropped.Error
+		}
+		return errors.New("subscription dropped")
+	}
+
+	if event.CheckPointReached != nil {
+		s.Metrics.recordCheckpoint(*event.CheckPointReached)
+		s.saveCheckpoint()
+		if s.reachedStopAt(*event.CheckPointReached) {
+			return errStopAtReached
+		}
+	}
+
+	if event.CaughtUp != nil {
+		s.Metrics.recordCaughtUp()
+		s.caughtUp()
+		// Everything up to the head read before subscribing has been delivered
+		if head := s.subscribeHead; head != nil && s.reachedStopAt(*head) {
+			return errStopAtReached
+		}
+	}
+
+	if event.EventAppeared != nil {
+		if s.Paused() {
+			// Not handled and not recorded, so it is redelivered after Resume
+			return errSubscriptionPaused
+		}
+		recorded := Resolve(event.EventAppeared, true)
+		if s.StopAt != nil && positionAfter(recorded.Position, *s.StopAt) {
+			// Not handled and not recorded: it belongs after the bound
+			return errStopAtReached
+		}
+		s.Metrics.startEvent(recorded)
+		if err := handler(event.EventAppeared); err != nil {
+			// Past the policy, the event counts as handled and the checkpoint moves beyond it
+			if err := s.handleFailure(ctx, event.EventAppeared, err); err != nil {
+				return handlerError{err}
+			}
+		}
+		s.Metrics.recordEvent(recorded)
+		if s.reachedStopAt(recorded.Position) {
+			return errStopAtReached
+		}
+	}
+	return nil
+}
+
+// reachedStopAt reports whether position is at or past StopAt
+func (s *MeteredSubscription) reachedStopAt(position kurrentdb.Position) bool {
+	return s.StopAt != nil && !positionAfter(*s.StopAt, position)
+}
+
+// === CAUGHT-UP CHECKPOINTS ===
+
+// readSubscribeHead records the $all head before subscribing, if checkpoints are kept or a
+// StopAt needs detecting on caught-up
+func (s *MeteredSubscription) readSubscribeHead(ctx context.Context) {
+	s.subscribeHead = nil
+	if s.Checkpoints == nil && s.StopAt == nil {
+		return
+	}
+	head, err := s.readHead(ctx)
+	if err != nil {
+		fmt.Printf("  [checkpoint] head unavailable, caught-up will save the last position: %v\n", err)
+		return
+	}
+	s.subscribeHead = &head
+}
+
+// caughtUp advances the position to the head read before subscribing and saves it. Caught-up means
+// the server has sent everything up to at least that head, so events filtered out before it never
+// need scanning again. The caught-up message carries no position in this client, and a head read
+// now could pass an event that is committed but not yet delivered; the earlier head can't.
+// Servers before 23.10 don't send caught-up at all, which leaves the filter checkpoints.
+func (s *MeteredSubscription) caughtUp() {
+	if s.Checkpoints == nil {
+		return
+	}
+	position, ok := s.Metrics.lastPosition()
+	if head := s.subscribeHead; head != nil && (!ok || positionAfter(*head, position)) {
+		s.Metrics.recordCheckpoint(*head)
+	}
+	s.saveCheckpoint()
+}
+
+// saveCheckpoint persists the last position; failures are logged, the next save retries
+func (s *MeteredSubscription) saveCheckpoint() {
+	if s.Checkpoints == nil {
+		return
+	}
+	position, ok := s.Metrics.lastPosition()
+	if !ok {
+		return
+	}
+	if err := s.Checkpoints.Save(position); err != nil {
+		fmt.Printf("  [checkpoint] save failed: %v\n", err)
+	}
+}
+
+// lagBar renders lag as a fixed-width bar relative to max
+func lagBar(lag, max uint64, width int) string {
+	filled := 0
+	if max > 0 {
+		filled = int(lag * uint64(width) / max)
+	}
+	if filled > width {
+		filled = width
+	}
+	return strings.Repeat("#", filled) + strings.Repeat(".", width-filled)
+}
+
+// RunSubscriptionMetrics demonstrates lag spiking under a slow handler and then recovering
+func RunSubscriptionMetrics() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	// === START SUBSCRIPTION AT THE HEAD ===
+	head, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+
+	// SUBSCRIPTION_START_FROM overrides the start, e.g. "start" or "position:1024/1000"
+	from, err := AllFromEnv("SUBSCRIPTION_START_FROM", head)
+	if err != nil {
+		panic(err)
+	}
+
+	metered := NewMeteredSubscription(client, kurrentdb.SubscribeToAllOptions{
+		From:   from,
+		Filter: kurrentdb.ExcludeSystemEventsFilter(),
+	})
+
+	// === HTTP ENDPOINT ===
+	metricsAddr := os.Getenv("METRICS_ADDR")
+	if metricsAddr == "" {
+		metricsAddr = "localhost:9102"
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metered.Metrics)
+	server := &http.Server{Addr: metricsAddr, Handler: mux}
+	go server.ListenAndServe()
+	defer server.Close()
+
+	fmt.Printf("Serving metrics on http://%s/metrics\n", metricsAddr)
+
+	// === PERIODIC SUMMARY ===
+	var samplesMu sync.Mutex
+	var samples []MetricsSnapshot
+
+	go metered.Metrics.Report(ctx, client, 250*time.Millisecond, func(s MetricsSnapshot) {
+		samplesMu.Lock()
+		samples = append(samples, s)
+		samplesMu.Unlock()
+
+		fmt.Printf("  [metrics] events=%d rate=%.0f/s bytes=%.0f/s lag=%d timeLag=%.1fs reconnects=%d lastEventAge=%.1fs\n",
+			s.EventsTotal, s.EventsPerSec, s.BytesPerSec, s.Lag, s.TimeLagSeconds, s.Reconnects, s.LastEventAgeSeconds)
+	})
+
+	// === SLOW HANDLER ===
+	// The first 150 events take 20ms each, simulating a slow downstream, then the handler recovers
+	streamName := fmt.Sprintf("metrics-%s", uuid.New().String())
+	handled := 0
+
+	runDone := make(chan error, 1)
+	go func() {
+		runDone <- metered.Run(ctx, func(event *kurrentdb.ResolvedEvent) error {
+			if Resolve(event, false).StreamID != streamName {
+				return nil
+			}
+			handled++
+			if handled <= 150 {
+				time.Sleep(20 * time.Millisecond)
+			}
+			return nil
+		})
+	}()
+
+	// === BURST OF WRITES ===
+	fmt.Println("\n=== Appending a burst of 300 events ===")
+
+	for i := 0; i < 300; i++ {
+		_, err := client.AppendToStream(ctx, streamName, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			ContentType: kurrentdb.ContentTypeJson,
+			EventType:   "MetricsSample",
+			Data:        []byte(fmt.Sprintf(`{"sequence":%d}`, i)),
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	time.Sleep(6 * time.Second)
+	cancel()
+	<-runDone
+
+	// === PLOT LAG ===
+	fmt.Println("\n=== Lag over time ===")
+
+	samplesMu.Lock()
+	defer samplesMu.Unlock()
+
+	var peak uint64
+	for _, s := range samples {
+		if s.Lag > peak {
+			peak = s.Lag
+		}
+	}
+	for i, s := range samples {
+		fmt.Printf("  t=%5.2fs |%s| %d\n", float64(i+1)*0.25, lagBar(s.Lag, peak, 40), s.Lag)
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if handled != 300 {
+		fmt.Printf("FAIL: Handler should see 300 events, got %d\n", handled)
+		passed = false
+	}
+	if len(samples) == 0 || peak == 0 {
+		fmt.Println("FAIL: Lag should spike while the handler is slow")
+		passed = false
+	} else if final := samples[len(samples)-1].Lag; final >= peak {
+		fmt.Printf("FAIL: Lag should recover below the peak %d, got %d\n", peak, final)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll subscription metrics tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
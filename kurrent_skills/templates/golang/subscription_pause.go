@@ -0,0 +1,165 @@
+// KurrentDB Go Client Example - Pausing a subscription during a downstream outage
+// Demonstrates: Pause() closing the subscription in place and Resume() continuing from the last checkpoint
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// RunSubscriptionPause pauses a running subscription while its sink is down, keeps appending,
+// then resumes and checks every event arrives exactly once and in order
+func RunSubscriptionPause() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	streamName := Streams.Name("order", uuid.New().String())
+
+	head, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+
+	metered := NewMeteredSubscription(client, kurrentdb.SubscribeToAllOptions{
+		From: head,
+		Filter: &kurrentdb.SubscriptionFilter{
+			Type:     kurrentdb.StreamFilterType,
+			Prefixes: []string{streamName},
+		},
+	})
+
+	// === DOWNSTREAM SINK ===
+	var mu sync.Mutex
+	var delivered []int
+	sinkDown := false
+	deliveredWhileDown := 0
+
+	handler := func(event *kurrentdb.ResolvedEvent) error {
+		var sequence int
+		fmt.Sscanf(string(Resolve(event, false).Data), `{"sequence":%d}`, &sequence)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if sinkDown {
+			deliveredWhileDown++
+		}
+		delivered = append(delivered, sequence)
+		return nil
+	}
+
+	runDone := make(chan error, 1)
+	go func() { runDone <- metered.Run(ctx, handler) }()
+
+	appendBatch := func(from, count int) {
+		for i := from; i < from+count; i++ {
+			_, err := client.AppendToStream(ctx, streamName, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+				EventID:     uuid.New(),
+				EventType:   "OrderUpdated",
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        []byte(fmt.Sprintf(`{"sequence":%d}`, i)),
+			})
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+	waitFor := func(count int) bool {
+		for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+			mu.Lock()
+			n := len(delivered)
+			mu.Unlock()
+			if n >= count {
+				return true
+			}
+		}
+		return false
+	}
+
+	// === RUNNING ===
+	appendBatch(0, 10)
+	waitFor(10)
+	fmt.Println("Delivered 10 events before the outage")
+
+	// === OUTAGE: PAUSE ===
+	metered.Pause()
+	mu.Lock()
+	sinkDown = true
+	mu.Unlock()
+	fmt.Println("\nSink down: subscription paused")
+
+	appendBatch(10, 15)
+	time.Sleep(time.Second)
+
+	mu.Lock()
+	duringPause := len(delivered)
+	mu.Unlock()
+	fmt.Printf("Appended 15 events while paused, delivered so far: %d\n", duringPause)
+
+	// === RECOVERY: RESUME ===
+	mu.Lock()
+	sinkDown = false
+	mu.Unlock()
+	metered.Resume()
+	fmt.Println("\nSink back: subscription resumed")
+
+	appendBatch(25, 5)
+	waitFor(30)
+
+	// Let any duplicate show up before checking
+	time.Sleep(500 * time.Millisecond)
+	cancel()
+	if err := <-runDone; err != nil {
+		panic(err)
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if deliveredWhileDown != 0 {
+		fmt.Printf("FAIL: Handler should not run while paused, ran %d times\n", deliveredWhileDown)
+		passed = false
+	}
+	if duringPause != 10 {
+		fmt.Printf("FAIL: Nothing should be delivered while paused, got %d events\n", duringPause-10)
+		passed = false
+	}
+	if len(delivered) != 30 {
+		fmt.Printf("FAIL: Expected 30 events delivered exactly once, got %d\n", len(delivered))
+		passed = false
+	}
+	for i, sequence := range delivered {
+		if sequence != i {
+			fmt.Printf("FAIL: Expected events 0..29 in order without gaps, got %v\n", delivered)
+			passed = false
+			break
+		}
+	}
+	if snapshot := metered.Metrics.Snapshot(); snapshot.Reconnects != 0 {
+		fmt.Printf("FAIL: Pause/resume should not count as reconnects, got %d\n", snapshot.Reconnects)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll subscription pause tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
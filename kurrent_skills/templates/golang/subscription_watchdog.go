@@ -0,0 +1,219 @@
+// KurrentDB Go Client Example - Stalled subscription watchdog
+// Demonstrates: Detecting a subscription that silently stops delivering and recovering by reconnecting
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// stallingSubscription forwards the first `after` events, then goes silent without dropping,
+// the way a half-open connection behaves
+type stallingSubscription struct {
+	inner  EventSubscription
+	after  int
+	seen   int
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (s *stallingSubscription) Recv() *kurrentdb.SubscriptionEvent {
+	event := s.inner.Recv()
+	if event.EventAppeared != nil {
+		s.seen++
+		if s.seen > s.after {
+			<-s.closed
+			return &kurrentdb.SubscriptionEvent{SubscriptionDropped: &kurrentdb.SubscriptionDropped{Error: context.Canceled}}
+		}
+	}
+	return event
+}
+
+func (s *stallingSubscription) Close() error {
+	s.once.Do(func() { close(s.closed) })
+	return s.inner.Close()
+}
+
+// RunSubscriptionWatchdog stalls the first subscription mid-stream and checks the watchdog recovers it,
+// then leaves the log idle and runs a slow handler to check the watchdog fires for neither
+func RunSubscriptionWatchdog() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	head, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+
+	const idleTimeout = time.Second
+
+	metered := NewMeteredSubscription(client, kurrentdb.SubscribeToAllOptions{From: head})
+	metered.IdleTimeout = idleTimeout
+	metered.reconnect = NewBackoff(100*time.Millisecond, time.Second)
+
+	// The first subscription stalls after 3 events; later ones are real
+	subscriptions := 0
+	metered.subscribe = func(ctx context.Context, options kurrentdb.SubscribeToAllOptions) (EventSubscription, error) {
+		subscription, err := client.SubscribeToAll(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions++
+		if subscriptions == 1 {
+			return &stallingSubscription{inner: subscription, after: 3, closed: make(chan struct{})}, nil
+		}
+		return subscription, nil
+	}
+
+	streamName := Streams.Name("watchdog", uuid.New().String())
+	slowStream := Streams.Name("watchdog", uuid.New().String())
+	var mu sync.Mutex
+	handled := map[uint64]int{}
+	slowHandled := 0
+
+	runDone := make(chan error, 1)
+	go func() {
+		runDone <- metered.Run(ctx, func(event *kurrentdb.ResolvedEvent) error {
+			if Resolve(event, false).StreamID == slowStream {
+				// Busy for longer than IdleTimeout while the next event waits behind it
+				time.Sleep(2 * idleTimeout)
+				mu.Lock()
+				slowHandled++
+				mu.Unlock()
+				return nil
+			}
+			if Resolve(event, false).StreamID != streamName {
+				return nil
+			}
+			mu.Lock()
+			handled[Resolve(event, false).EventNumber]++
+			mu.Unlock()
+			return nil
+		})
+	}()
+
+	// === APPEND EVENTS ===
+	const total = 10
+	for i := 0; i < total; i++ {
+		_, err := client.AppendToStream(ctx, streamName, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			ContentType: kurrentdb.ContentTypeJson,
+			EventType:   "Heartbeat",
+			Data:        []byte(fmt.Sprintf(`{"sequence":%d}`, i)),
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	fmt.Printf("Appended %d events; the first subscription goes silent after 3\n", total)
+
+	// === WAIT FOR RECOVERY ===
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(handled)
+		mu.Unlock()
+		if n == total {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	recovered := metered.Metrics.Snapshot()
+	fmt.Printf("  Recovered: handled=%d stalls=%d reconnects=%d\n", len(handled), recovered.Stalls, recovered.Reconnects)
+
+	// === IDLE AND CAUGHT UP ===
+	fmt.Printf("\nLeaving the log idle for %s...\n", 3*idleTimeout)
+	time.Sleep(3 * idleTimeout)
+	idle := metered.Metrics.Snapshot()
+	fmt.Printf("  After idle: stalls=%d reconnects=%d\n", idle.Stalls, idle.Reconnects)
+
+	// === SLOW HANDLER ===
+	const slowEvents = 2
+	for i := 0; i < slowEvents; i++ {
+		_, err := client.AppendToStream(ctx, slowStream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			ContentType: kurrentdb.ContentTypeJson,
+			EventType:   "Heartbeat",
+			Data:        []byte(fmt.Sprintf(`{"sequence":%d}`, i)),
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	fmt.Printf("\nAppended %d events the handler takes %s each to process...\n", slowEvents, 2*idleTimeout)
+	deadline = time.Now().Add(slowEvents*2*idleTimeout + 5*time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := slowHandled
+		mu.Unlock()
+		if n == slowEvents {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	// Give the watchdog a tick after the last handler returns
+	time.Sleep(idleTimeout / 2)
+	slow := metered.Metrics.Snapshot()
+	mu.Lock()
+	fmt.Printf("  After slow handler: handled=%d stalls=%d reconnects=%d\n", slowHandled, slow.Stalls, slow.Reconnects)
+	mu.Unlock()
+
+	cancel()
+	if err := <-runDone; err != nil {
+		panic(err)
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(handled) != total {
+		fmt.Printf("FAIL: All %d events should be handled after recovery, got %d\n", total, len(handled))
+		passed = false
+	}
+	for number, count := range handled {
+		if count != 1 {
+			fmt.Printf("FAIL: Event #%d handled %d times\n", number, count)
+			passed = false
+		}
+	}
+	if recovered.Stalls != 1 {
+		fmt.Printf("FAIL: Watchdog should detect exactly one stall, got %d\n", recovered.Stalls)
+		passed = false
+	}
+	if idle.Stalls != recovered.Stalls {
+		fmt.Printf("FAIL: Watchdog should not fire while idle and caught up, stalls went %d -> %d\n", recovered.Stalls, idle.Stalls)
+		passed = false
+	}
+	if slowHandled != slowEvents {
+		fmt.Printf("FAIL: All %d slow events should be handled, got %d\n", slowEvents, slowHandled)
+		passed = false
+	}
+	if slow.Stalls != idle.Stalls {
+		fmt.Printf("FAIL: Watchdog should not fire while a slow handler runs, stalls went %d -> %d\n", idle.Stalls, slow.Stalls)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll watchdog tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
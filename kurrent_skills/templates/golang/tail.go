@@ -0,0 +1,314 @@
+// KurrentDB Go Client Example - Tailing a stream
+// Demonstrates: Printing the last N events of a stream, then following it live like tail -f
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === TAIL ===
+
+// TailOptions configures Tail
+type TailOptions struct {
+	// Lines is how many of the stream's last events to print first
+	Lines uint64
+	// Follow keeps printing new events until ctx ends
+	Follow bool
+	// JSON prints one JSON object per event instead of a line of text
+	JSON bool
+	// RawLinks prints the links in streams like $ce-order as they are, instead of the events they
+	// point to
+	RawLinks bool
+	// Out receives the output, os.Stdout if nil
+	Out io.Writer
+}
+
+// TailedEvent is the shape --json prints
+type TailedEvent struct {
+	Stream  string    `json:"stream"`
+	Number  uint64    `json:"number"`
+	Type    string    `json:"type"`
+	Created time.Time `json:"created"`
+	Origin  string    `json:"origin,omitempty"`
+	// Dangling marks a link whose event was deleted
+	Dangling bool            `json:"dangling,omitempty"`
+	Data     json.RawMessage `json:"data"`
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+}
+
+// Tail prints the last Lines events of stream and, with Follow, every event appended after them
+// until ctx ends, which is a clean stop and returns nil. Unless RawLinks is set, links are resolved,
+// so category and event type streams like $ce-order show the events they point to. Without Follow a
+// missing stream is an error; with it, Tail waits for the stream to appear. Following starts where
+// TailWithHistory's does, just after the newest event, so nothing is printed twice or missed.
+func Tail(ctx context.Context, client *kurrentdb.Client, stream string, options TailOptions) error {
+	out := options.Out
+	if out == nil {
+		out = os.Stdout
+	}
+
+	history, from, err := readTail(ctx, client, stream, options.Lines, !options.RawLinks)
+	if err != nil {
+		return err
+	}
+	if _, empty := from.(kurrentdb.Start); empty && !options.Follow {
+		return fmt.Errorf("tail %s: stream not found or empty", stream)
+	}
+
+	for _, event := range history {
+		if err := printTailed(out, event, options.JSON); err != nil {
+			return err
+		}
+	}
+	if !options.Follow {
+		return nil
+	}
+
+	return followStream(ctx, client, stream, from, !options.RawLinks, func(event *kurrentdb.ResolvedEvent) error {
+		return printTailed(out, event, options.JSON)
+	})
+}
+
+// printTailed writes one event as a line of text or JSON, in a single Write
+func printTailed(out io.Writer, resolved *kurrentdb.ResolvedEvent, asJSON bool) error {
+	event := Resolve(resolved, false)
+	position := Resolve(resolved, true)
+	// For a link, show where the event it points to lives
+	var origin string
+	if position.StreamID != event.StreamID {
+		origin = fmt.Sprintf("%s@%d", event.StreamID, event.EventNumber)
+	}
+	dangling := LinkStatusOf(resolved) == LinkDangling
+
+	var line string
+	if asJSON {
+		encoded, err := json.Marshal(TailedEvent{
+			Stream:   position.StreamID,
+			Number:   position.EventNumber,
+			Type:     event.EventType,
+			Created:  event.CreatedDate,
+			Origin:   origin,
+			Dangling: dangling,
+			Data:     tailJSON(event.Data),
+			Metadata: tailJSON(event.UserMetadata),
+		})
+		if err != nil {
+			return err
+		}
+		line = string(encoded) + "\n"
+	} else {
+		age := time.Since(event.CreatedDate).Round(time.Second)
+		if origin != "" {
+			origin = " (" + origin + ")"
+		}
+		if dangling {
+			origin = " (event deleted)"
+		}
+		line = fmt.Sprintf("#%-6d %-24s %8s ago%s  %s\n", position.EventNumber, event.EventType, age, origin, tailText(event))
+	}
+	_, err := io.WriteString(out, line)
+	return err
+}
+
+// tailJSON returns data as is when it is JSON, and as a JSON string otherwise
+func tailJSON(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	if json.Valid(data) {
+		return data
+	}
+	encoded, _ := json.Marshal(string(data))
+	return encoded
+}
+
+// tailText renders event data on one line: compacted JSON, a link's target, or its size for anything else
+func tailText(event *kurrentdb.RecordedEvent) string {
+	if stream, number, ok := ParseLink(event); ok {
+		return fmt.Sprintf("-> %s@%d", stream, number)
+	}
+	var compact bytes.Buffer
+	if json.Compact(&compact, event.Data) == nil {
+		return compact.String()
+	}
+	return fmt.Sprintf("<%d bytes of %s>", len(event.Data), event.ContentType)
+}
+
+// tailLines collects Tail's output line by line, safe for the following goroutine to write to
+type tailLines struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (t *tailLines) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lines = append(t.lines, strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+func (t *tailLines) Lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]string(nil), t.lines...)
+}
+
+// RunTail tails a stream until Ctrl+C:
+//
+//	tail <stream> [--follow] [--lines N] [--json] [--raw-links]
+//
+// Without a stream it tails a stream it writes to itself and checks the output.
+func RunTail() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	flags := flag.NewFlagSet("tail", flag.ExitOnError)
+	follow := flags.Bool("follow", false, "keep printing new events until Ctrl+C")
+	flags.BoolVar(follow, "f", false, "shorthand for --follow")
+	lines := flags.Uint64("lines", 10, "number of past events to print")
+	flags.Uint64Var(lines, "n", 10, "shorthand for --lines")
+	asJSON := flags.Bool("json", false, "print one JSON object per event")
+	rawLinks := flags.Bool("raw-links", false, "print links as they are instead of the events they point to")
+	// Accept the stream before or after the flags
+	var streams []string
+	for args := os.Args[2:]; len(args) > 0; args = flags.Args()[1:] {
+		flags.Parse(args)
+		if flags.NArg() == 0 {
+			break
+		}
+		streams = append(streams, flags.Arg(0))
+	}
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	if len(streams) > 0 {
+		err := Tail(ctx, client, streams[0], TailOptions{Lines: *lines, Follow: *follow, JSON: *asJSON, RawLinks: *rawLinks})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	stream := Streams.Name("order", uuid.New().String())
+	appendEvent := func(eventType string, seq int) {
+		data, _ := json.Marshal(map[string]interface{}{"seq": seq, "note": "tail demo"})
+		_, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        data,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	// waitFor polls until output has n lines, or gives up after a few seconds
+	waitFor := func(output *tailLines, n int) {
+		for deadline := time.Now().Add(5 * time.Second); len(output.Lines()) < n && time.Now().Before(deadline); {
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	for seq := 0; seq < 5; seq++ {
+		appendEvent("ItemAdded", seq)
+	}
+	fmt.Printf("Wrote 5 events to %s\n", stream)
+
+	// === FOLLOW ===
+	fmt.Println("\n=== tail --lines 3 --follow ===")
+	followed := &tailLines{}
+	followCtx, stopFollowing := context.WithCancel(ctx)
+	followErr := make(chan error, 1)
+	go func() {
+		followErr <- Tail(followCtx, client, stream, TailOptions{Lines: 3, Follow: true, Out: followed})
+	}()
+	waitFor(followed, 3)
+	appendEvent("OrderShipped", 5)
+	appendEvent("OrderCompleted", 6)
+	waitFor(followed, 5)
+	stopFollowing()
+	var stoppedErr error
+	select {
+	case stoppedErr = <-followErr:
+	case <-time.After(5 * time.Second):
+		stoppedErr = errors.New("tail did not stop")
+	}
+	for _, line := range followed.Lines() {
+		fmt.Println("  " + line)
+	}
+
+	// === JSON ===
+	fmt.Println("\n=== tail --lines 2 --json ===")
+	jsonLines := &tailLines{}
+	jsonErr := Tail(ctx, client, stream, TailOptions{Lines: 2, JSON: true, Out: jsonLines})
+	var decoded []TailedEvent
+	for _, line := range jsonLines.Lines() {
+		fmt.Println("  " + line)
+		var event TailedEvent
+		if err := json.Unmarshal([]byte(line), &event); err == nil {
+			decoded = append(decoded, event)
+		}
+	}
+
+	// === MISSING STREAM ===
+	missingErr := Tail(ctx, client, Streams.Name("order", uuid.New().String()), TailOptions{Lines: 10, Out: io.Discard})
+	fmt.Printf("\nTail of a missing stream: %v\n", missingErr)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	var numbers []string
+	for _, line := range followed.Lines() {
+		numbers = append(numbers, strings.Fields(line)[0])
+	}
+	if fmt.Sprint(numbers) != "[#2 #3 #4 #5 #6]" {
+		fmt.Printf("FAIL: Following should print the last 3 events then the 2 new ones, got %v\n", numbers)
+		passed = false
+	}
+	if lines := followed.Lines(); len(lines) == 5 && (!strings.Contains(lines[3], "OrderShipped") || !strings.Contains(lines[3], `{"note":"tail demo","seq":5}`)) {
+		fmt.Printf("FAIL: Each line should show the type and data, got %q\n", lines[3])
+		passed = false
+	}
+	if stoppedErr != nil {
+		fmt.Printf("FAIL: Cancelling should stop following cleanly, got %v\n", stoppedErr)
+		passed = false
+	}
+	if jsonErr != nil || len(decoded) != 2 || decoded[0].Number != 5 || decoded[1].Type != "OrderCompleted" || decoded[1].Stream != stream {
+		fmt.Printf("FAIL: --json should print the last 2 events as JSON, got %+v (%v)\n", decoded, jsonErr)
+		passed = false
+	}
+	if missingErr == nil {
+		fmt.Println("FAIL: Tailing a missing stream without --follow should fail")
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll tail tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
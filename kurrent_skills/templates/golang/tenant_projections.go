@@ -0,0 +1,485 @@
+// KurrentDB Go Client Example - Per-tenant projections fed from one $all subscription
+// Demonstrates: Routing events by a tenant metadata key to isolated projections with their own buffers and checkpoints
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === TENANT PROJECTION RUNNER ===
+
+// CheckpointStore persists a $all position; FileCheckpoint is one implementation
+type CheckpointStore interface {
+	// Load returns the stored position, or nil if nothing has been saved yet
+	Load() (*kurrentdb.Position, error)
+	Save(position kurrentdb.Position) error
+}
+
+// TenantProjectionOptions configures routing and buffering
+type TenantProjectionOptions struct {
+	// TenantKey is the metadata field naming the tenant; defaults to "tenant"
+	TenantKey string
+	// BufferSize is how many events a tenant may lag behind the subscription before it holds up
+	// the others; defaults to 100
+	BufferSize int
+	// CheckpointEvery saves a tenant's checkpoint after this many events, and whenever its buffer
+	// drains; defaults to 100
+	CheckpointEvery int
+	// From is where tenants without a checkpoint start; defaults to the start of $all
+	From kurrentdb.AllPosition
+	// Filter narrows the shared subscription, e.g. to the categories the projections handle
+	Filter *kurrentdb.SubscriptionFilter
+}
+
+// TenantProjection is one tenant's projection, fed by its own worker
+type TenantProjection struct {
+	Tenant string
+
+	store      CheckpointStore
+	projection *Projection
+	queue      chan *kurrentdb.ResolvedEvent
+
+	mu         sync.Mutex
+	checkpoint *kurrentdb.Position
+	processed  int
+}
+
+// Read gives fn exclusive access to the projection and its checkpoint while the runner is live
+func (t *TenantProjection) Read(fn func(projection *Projection, checkpoint *kurrentdb.Position)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fn(t.projection, t.checkpoint)
+}
+
+// Processed counts the events this tenant handled since the runner started
+func (t *TenantProjection) Processed() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.processed
+}
+
+// TenantProjectionRunner subscribes to $all once and routes each event to the projection of the
+// tenant named in its metadata. Every tenant applies events on its own goroutine from a bounded
+// buffer, so a slow tenant holds the others up only once its buffer is full.
+//
+// Each tenant checkpoints independently. The shared subscription restarts from the lowest
+// checkpoint, and tenants that are further ahead skip what they have already handled.
+type TenantProjectionRunner struct {
+	client  *kurrentdb.Client
+	options TenantProjectionOptions
+	tenants map[string]*TenantProjection
+
+	reconnect Backoff
+
+	mu       sync.Mutex
+	unrouted int
+	err      error
+}
+
+func NewTenantProjectionRunner(client *kurrentdb.Client, options TenantProjectionOptions) *TenantProjectionRunner {
+	if options.TenantKey == "" {
+		options.TenantKey = "tenant"
+	}
+	if options.BufferSize <= 0 {
+		options.BufferSize = 100
+	}
+	if options.CheckpointEvery <= 0 {
+		options.CheckpointEvery = 100
+	}
+	if options.From == nil {
+		options.From = kurrentdb.Start{}
+	}
+	return &TenantProjectionRunner{
+		client:    client,
+		options:   options,
+		tenants:   make(map[string]*TenantProjection),
+		reconnect: NewBackoff(time.Second, 30*time.Second),
+	}
+}
+
+// Add registers a tenant's projection and checkpoint store; call before Run
+func (r *TenantProjectionRunner) Add(tenant string, projection *Projection, store CheckpointStore) *TenantProjection {
+	t := &TenantProjection{Tenant: tenant, store: store, projection: projection}
+	r.tenants[tenant] = t
+	return t
+}
+
+// Unrouted counts events without a registered tenant
+func (r *TenantProjectionRunner) Unrouted() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.unrouted
+}
+
+// Run restores every tenant's checkpoint and projects until ctx is cancelled. Each tenant saves
+// its checkpoint before Run returns. The returned error is the first checkpoint failure, if any.
+func (r *TenantProjectionRunner) Run(ctx context.Context) error {
+	for _, t := range r.tenants {
+		checkpoint, err := t.store.Load()
+		if err != nil {
+			return fmt.Errorf("load checkpoint of tenant %s: %w", t.Tenant, err)
+		}
+		t.checkpoint = checkpoint
+		t.processed = 0
+		t.queue = make(chan *kurrentdb.ResolvedEvent, r.options.BufferSize)
+	}
+
+	var wg sync.WaitGroup
+	for _, t := range r.tenants {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work(ctx, t)
+		}()
+	}
+
+	reconnect := r.reconnect
+	for ctx.Err() == nil {
+		subscribed := time.Now()
+		err := r.subscribe(ctx)
+		if ctx.Err() != nil {
+			break
+		}
+		fmt.Printf("  [tenants] subscription dropped, reconnecting: %v\n", err)
+
+		// A long-lived subscription resets the backoff, so a rare drop reconnects quickly
+		if time.Since(subscribed) > reconnect.Max {
+			reconnect.Reset()
+		}
+		reconnect.Wait(ctx)
+	}
+
+	for _, t := range r.tenants {
+		close(t.queue)
+	}
+	wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// startPosition is the lowest tenant checkpoint, or From if any tenant has none
+func (r *TenantProjectionRunner) startPosition() kurrentdb.AllPosition {
+	var lowest *kurrentdb.Position
+	for _, t := range r.tenants {
+		t.mu.Lock()
+		checkpoint := t.checkpoint
+		t.mu.Unlock()
+
+		if checkpoint == nil {
+			return r.options.From
+		}
+		if lowest == nil || positionAfter(*lowest, *checkpoint) {
+			lowest = checkpoint
+		}
+	}
+	if lowest == nil {
+		return r.options.From
+	}
+	return *lowest
+}
+
+func (r *TenantProjectionRunner) subscribe(ctx context.Context) error {
+	subscription, err := r.client.SubscribeToAll(ctx, kurrentdb.SubscribeToAllOptions{
+		From:   r.startPosition(),
+		Filter: r.options.Filter,
+	})
+	if err != nil {
+		return err
+	}
+	defer subscription.Close()
+
+	for {
+		message := subscription.Recv()
+
+		if message.SubscriptionDropped != nil {
+			if message.SubscriptionDropped.Error == nil {
+				return errors.New("subscription dropped")
+			}
+			return message.SubscriptionDropped.Error
+		}
+		if message.EventAppeared == nil {
+			continue
+		}
+
+		t, ok := r.tenants[r.tenantOf(Resolve(message.EventAppeared, false))]
+		if !ok {
+			r.mu.Lock()
+			r.unrouted++
+			r.mu.Unlock()
+			continue
+		}
+
+		select {
+		case t.queue <- message.EventAppeared:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// tenantOf reads the tenant key from the event's metadata; "" if it's missing
+func (r *TenantProjectionRunner) tenantOf(event *kurrentdb.RecordedEvent) string {
+	var metadata map[string]interface{}
+	if json.Unmarshal(event.UserMetadata, &metadata) != nil {
+		return ""
+	}
+	tenant, _ := metadata[r.options.TenantKey].(string)
+	return tenant
+}
+
+func (r *TenantProjectionRunner) work(ctx context.Context, t *TenantProjection) {
+	t.mu.Lock()
+	saved := t.checkpoint
+	t.mu.Unlock()
+
+	save := func() {
+		t.mu.Lock()
+		checkpoint := t.checkpoint
+		t.mu.Unlock()
+		if checkpoint == nil || (saved != nil && *saved == *checkpoint) {
+			return
+		}
+		if err := t.store.Save(*checkpoint); err != nil {
+			r.fail(fmt.Errorf("save checkpoint of tenant %s: %w", t.Tenant, err))
+			return
+		}
+		saved = checkpoint
+	}
+	defer save()
+
+	unsaved := 0
+	for resolved := range t.queue {
+		if ctx.Err() != nil {
+			// Stopping: leave the rest for the next run
+			continue
+		}
+
+		event := Resolve(resolved, false)
+		position := Resolve(resolved, true).Position
+
+		t.mu.Lock()
+		// Replayed after a reconnect from a lower tenant's checkpoint
+		seen := t.checkpoint != nil && !positionAfter(position, *t.checkpoint)
+		if !seen {
+			if _, err := t.projection.Apply(event, position); err != nil {
+				fmt.Printf("  [%s] skipped %s@%d: %v\n", t.Tenant, event.StreamID, event.EventNumber, err)
+			}
+			t.checkpoint = &position
+			t.processed++
+		}
+		t.mu.Unlock()
+		if seen {
+			continue
+		}
+
+		unsaved++
+		if unsaved >= r.options.CheckpointEvery || len(t.queue) == 0 {
+			save()
+			unsaved = 0
+		}
+	}
+}
+
+func (r *TenantProjectionRunner) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// RunTenantProjections projects a busy, slow tenant and a quiet one side by side, stops mid-way and
+// resumes each from its own checkpoint
+func RunTenantProjections() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	category := "tenantorder" + strings.ReplaceAll(uuid.New().String(), "-", "")[:8]
+	options := TenantProjectionOptions{
+		BufferSize: 4,
+		Filter:     &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{category + "-"}},
+	}
+
+	appendOrder := func(tenant string, amount int) {
+		metadata := []byte(`{}`)
+		if tenant != "" {
+			metadata = []byte(fmt.Sprintf(`{"tenant":%q}`, tenant))
+		}
+		_, err := client.AppendToStream(ctx, Streams.Name(category, tenant+uuid.New().String()[:8]), kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   "OrderPlaced",
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(fmt.Sprintf(`{"amount":%d}`, amount)),
+			Metadata:    metadata,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// Each run starts with empty read models; the checkpoints alone say what was already projected
+	newTenantProjection := func(delay time.Duration) *Projection {
+		return NewProjection("TenantOrders").On("OrderPlaced", func(state, data map[string]interface{}) map[string]interface{} {
+			time.Sleep(delay)
+			total, _ := state["total"].(float64)
+			amount, _ := data["amount"].(float64)
+			state["total"] = total + amount
+			return state
+		})
+	}
+
+	dir, err := os.MkdirTemp("", "tenant-checkpoints")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	stores := map[string]CheckpointStore{
+		"acme":   FileCheckpoint{Path: filepath.Join(dir, "acme.json")},
+		"globex": FileCheckpoint{Path: filepath.Join(dir, "globex.json")},
+	}
+
+	// === EVENTS ===
+	appendOrder("", 1)
+	appendOrder("initech", 1)
+	// acme: 60 orders projected slowly; globex: 6 orders, one after every 5th acme order
+	for i := 0; i < 60; i++ {
+		appendOrder("acme", 10)
+		if i%5 == 0 && i < 30 {
+			appendOrder("globex", 100)
+		}
+	}
+	fmt.Println("Appended 1 untagged, 1 unregistered-tenant, 60 acme and 6 globex orders")
+
+	waitUntil := func(done func() bool) bool {
+		for deadline := time.Now().Add(15 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if done() {
+				return true
+			}
+		}
+		return false
+	}
+
+	// === FIRST RUN ===
+	fmt.Println("\n=== First run ===")
+	first := NewTenantProjectionRunner(client, options)
+	acme := first.Add("acme", newTenantProjection(10*time.Millisecond), stores["acme"])
+	globex := first.Add("globex", newTenantProjection(0), stores["globex"])
+
+	runCtx, stop := context.WithCancel(ctx)
+	runDone := make(chan error, 1)
+	go func() { runDone <- first.Run(runCtx) }()
+
+	globexCaughtUp := waitUntil(func() bool { return globex.Processed() == 6 })
+	acmeWhenGlobexDone := acme.Processed()
+	fmt.Printf("globex caught up (6 orders) while acme was at %d/60\n", acmeWhenGlobexDone)
+
+	// Stop mid-way: acme's checkpoint is behind globex's
+	waitUntil(func() bool { return acme.Processed() >= 30 })
+	stop()
+	if err := <-runDone; err != nil {
+		panic(err)
+	}
+	acmeFirst, globexFirst := acme.Processed(), globex.Processed()
+	unrouted := first.Unrouted()
+	fmt.Printf("Stopped: acme processed %d, globex %d, unrouted %d\n", acmeFirst, globexFirst, unrouted)
+
+	acmeSaved, _ := stores["acme"].Load()
+	globexSaved, _ := stores["globex"].Load()
+	if acmeSaved != nil && globexSaved != nil {
+		fmt.Printf("Saved checkpoints: acme %d/%d, globex %d/%d\n", acmeSaved.Commit, acmeSaved.Prepare, globexSaved.Commit, globexSaved.Prepare)
+	}
+
+	// === SECOND RUN ===
+	fmt.Println("\n=== Second run ===")
+	appendOrder("globex", 100)
+	appendOrder("globex", 100)
+
+	second := NewTenantProjectionRunner(client, options)
+	acme2 := second.Add("acme", newTenantProjection(time.Millisecond), stores["acme"])
+	globex2 := second.Add("globex", newTenantProjection(0), stores["globex"])
+
+	runCtx, stop = context.WithCancel(ctx)
+	go func() { runDone <- second.Run(runCtx) }()
+	resumed := waitUntil(func() bool { return acme2.Processed() == 60-acmeFirst && globex2.Processed() == 2 })
+	// Let any duplicate show up before stopping
+	time.Sleep(300 * time.Millisecond)
+	stop()
+	if err := <-runDone; err != nil {
+		panic(err)
+	}
+
+	var acmeTotal, globexTotal float64
+	acme2.Read(func(p *Projection, _ *kurrentdb.Position) {
+		for _, state := range p.State {
+			total, _ := state["total"].(float64)
+			acmeTotal += total
+		}
+	})
+	globex2.Read(func(p *Projection, _ *kurrentdb.Position) {
+		for _, state := range p.State {
+			total, _ := state["total"].(float64)
+			globexTotal += total
+		}
+	})
+	fmt.Printf("Resumed: acme processed %d (total %v), globex %d (total %v)\n", acme2.Processed(), acmeTotal, globex2.Processed(), globexTotal)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if !globexCaughtUp || acmeWhenGlobexDone >= 60 {
+		fmt.Printf("FAIL: globex should catch up before the slow acme projection, acme was at %d\n", acmeWhenGlobexDone)
+		passed = false
+	}
+	// globex's last order follows acme's 26th, so it is routed once acme's buffer takes that one
+	if acmeWhenGlobexDone > 26+options.BufferSize {
+		fmt.Printf("FAIL: acme should hold globex up by at most its buffer, acme had processed %d\n", acmeWhenGlobexDone)
+		passed = false
+	}
+	if globexFirst != 6 || acmeFirst < 30 || acmeFirst >= 60 {
+		fmt.Printf("FAIL: Expected the first run to stop with globex done and acme part-way, got acme=%d globex=%d\n", acmeFirst, globexFirst)
+		passed = false
+	}
+	if unrouted != 2 {
+		fmt.Printf("FAIL: Expected the untagged and unregistered orders unrouted, got %d\n", unrouted)
+		passed = false
+	}
+	if acmeSaved == nil || globexSaved == nil || !positionAfter(*globexSaved, *acmeSaved) {
+		fmt.Println("FAIL: Each tenant should save its own checkpoint, globex's ahead of acme's")
+		passed = false
+	}
+	if !resumed || acme2.Processed() != 60-acmeFirst || acmeTotal != float64(10*(60-acmeFirst)) {
+		fmt.Printf("FAIL: acme should resume after its checkpoint without duplicates, processed %d of %d\n", acme2.Processed(), 60-acmeFirst)
+		passed = false
+	}
+	if globex2.Processed() != 2 || globexTotal != 200 {
+		fmt.Printf("FAIL: globex should only process its 2 new orders, processed %d\n", globex2.Processed())
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll tenant projection tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
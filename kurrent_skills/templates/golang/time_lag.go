@@ -0,0 +1,144 @@
+// KurrentDB Go Client Example - Subscription lag in seconds
+// Demonstrates: Reporting how far behind in wall-clock time a handler is, under a slow handler, on recovery and while idle
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// RunTimeLag slows a handler down until it falls a second behind, lets it recover, then idles
+func RunTimeLag() {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	start, _, err := readAllHead(ctx, NewClientStore(client))
+	if err != nil {
+		panic(err)
+	}
+
+	orderStream := Streams.Name("order", uuid.New().String())
+	appendEvents := func(count int) {
+		for i := 0; i < count; i++ {
+			_, err := client.AppendToStream(ctx, orderStream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+				EventID:     uuid.New(),
+				EventType:   "OrderUpdated",
+				ContentType: kurrentdb.ContentTypeJson,
+				Data:        []byte(`{}`),
+			})
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	subscription := NewMeteredSubscription(client, kurrentdb.SubscribeToAllOptions{
+		From:   start,
+		Filter: &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{orderStream}},
+	})
+
+	var slow atomic.Bool
+	var handled atomic.Int64
+	runCtx, stop := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		done <- subscription.Run(runCtx, func(event *kurrentdb.ResolvedEvent) error {
+			if slow.Load() {
+				time.Sleep(150 * time.Millisecond)
+			}
+			handled.Add(1)
+			return nil
+		})
+	}()
+
+	// Sample the lag every 50ms, as a metrics scraper would
+	var mu sync.Mutex
+	var peak time.Duration
+	sampleCtx, stopSampling := context.WithCancel(ctx)
+	defer stopSampling()
+	go func() {
+		for sampleCtx.Err() == nil {
+			lag := subscription.TimeLag()
+			mu.Lock()
+			peak = max(peak, lag)
+			mu.Unlock()
+			time.Sleep(50 * time.Millisecond)
+		}
+	}()
+
+	waitFor := func(ready func() bool) {
+		for deadline := time.Now().Add(15 * time.Second); time.Now().Before(deadline) && !ready(); time.Sleep(50 * time.Millisecond) {
+		}
+	}
+
+	// === SLOW HANDLER ===
+	fmt.Println("\n=== Slow handler: 150ms per event ===")
+	slow.Store(true)
+	appendEvents(30)
+	waitFor(func() bool { return subscription.TimeLag() >= 2*time.Second })
+	behind := subscription.TimeLag()
+	fmt.Printf("  handled %d/30, time lag %s\n", handled.Load(), behind.Round(time.Millisecond))
+
+	// === RECOVERY ===
+	fmt.Println("\n=== Recovered handler ===")
+	slow.Store(false)
+	waitFor(func() bool { return handled.Load() == 30 })
+	appendEvents(10)
+	waitFor(func() bool { return handled.Load() == 40 })
+	recovered := subscription.TimeLag()
+	fmt.Printf("  handled %d/40, time lag %s\n", handled.Load(), recovered.Round(time.Millisecond))
+
+	// === IDLE ===
+	fmt.Println("\n=== Idle for 2s ===")
+	time.Sleep(2 * time.Second)
+	idle := subscription.Stats()
+	fmt.Printf("  time lag %.3fs, last event %.1fs ago\n", idle.TimeLagSeconds, idle.LastEventAgeSeconds)
+
+	stopSampling()
+	stop()
+	if err := <-done; err != nil {
+		panic(err)
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	mu.Lock()
+	defer mu.Unlock()
+	fmt.Printf("Peak time lag: %s\n", peak.Round(time.Millisecond))
+
+	if behind < 2*time.Second || peak < behind {
+		fmt.Printf("FAIL: The slow handler should fall at least 2s behind, got %s (peak %s)\n", behind, peak)
+		passed = false
+	}
+	if recovered >= 500*time.Millisecond {
+		fmt.Printf("FAIL: After recovering the lag should be near zero, got %s\n", recovered)
+		passed = false
+	}
+	if idleLag := time.Duration(idle.TimeLagSeconds * float64(time.Second)); idleLag > recovered+time.Millisecond || idle.LastEventAgeSeconds < 2 {
+		fmt.Printf("FAIL: The lag should not grow while idle: %s after recovery, %s after 2s idle\n", recovered, idleLag)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll time lag tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,558 @@
+// KurrentDB Go Client Example - Read-decide-append with optimistic concurrency
+// Demonstrates: Re-running a decision against the current stream whenever another writer gets there first
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === TRANSACT ===
+
+// ErrTransactRetriesExhausted is returned when every attempt lost the race to another writer
+var ErrTransactRetriesExhausted = errors.New("transaction retries exhausted")
+
+// ErrTransactConflict is returned, wrapped, when a ConflictResolver finds that events appended by
+// another writer invalidate ours
+var ErrTransactConflict = errors.New("transaction conflicts with concurrent events")
+
+// ErrRedecide is returned by a ConflictResolver that can't tell whether our events still apply, to
+// have Transact read the whole stream and decide again
+var ErrRedecide = errors.New("redecide")
+
+// ConflictResolver is called when an append loses the race to another writer. base is the stream
+// the decision saw, theirs the events appended since and ours the events decided. It returns the
+// events to append after theirs, usually ours unchanged, or nothing when theirs already did the
+// job; an error aborts, and ErrRedecide falls back to deciding again.
+type ConflictResolver func(base, theirs []*kurrentdb.RecordedEvent, ours []kurrentdb.EventData) ([]kurrentdb.EventData, error)
+
+// AbortOnConflict keeps our events unless conflicts reports one of them clashing with one of theirs,
+// then aborts with ErrTransactConflict. Events that don't clash, like another item added to the
+// same cart, are appended without deciding again.
+func AbortOnConflict(conflicts func(ours kurrentdb.EventData, theirs *kurrentdb.RecordedEvent) bool) ConflictResolver {
+	return func(base, theirs []*kurrentdb.RecordedEvent, ours []kurrentdb.EventData) ([]kurrentdb.EventData, error) {
+		for _, event := range ours {
+			for _, concurrent := range theirs {
+				if conflicts(event, concurrent) {
+					return nil, fmt.Errorf("%w: %s conflicts with %s@%d", ErrTransactConflict, event.EventType, concurrent.EventType, concurrent.EventNumber)
+				}
+			}
+		}
+		return ours, nil
+	}
+}
+
+// TransactResult describes a successful transaction
+type TransactResult struct {
+	// Version is the stream's revision afterwards: after the appended events, or as read when
+	// the decision appended nothing. NoVersion if the stream still doesn't exist.
+	Version int64
+	// Attempts counts the decisions and merges made, including those retried after a conflict
+	Attempts int
+	// Merged counts the conflicts the resolver settled without deciding again
+	Merged int
+	// Appended is the number of events appended
+	Appended int
+}
+
+// Transactor runs read-decide-append loops: the ad-hoc counterpart of OrderRepository.Execute,
+// for decisions that don't warrant an aggregate type
+type Transactor struct {
+	store EventStore
+
+	// MaxAttempts bounds the decisions and merges tried before giving up with
+	// ErrTransactRetriesExhausted
+	MaxAttempts int
+	// Backoff spaces out the retries, with jitter so writers that collided don't collide again
+	Backoff Backoff
+	// Resolver, when set, settles conflicts from only the events appended since the decision's
+	// read, instead of reading the whole stream and deciding again
+	Resolver ConflictResolver
+
+	// beforeAppend runs between the decision and the append; the demo uses it to race a writer in
+	beforeAppend func(attempt int)
+}
+
+func NewTransactor(store EventStore) *Transactor {
+	return &Transactor{
+		store:       store,
+		MaxAttempts: 5,
+		Backoff:     NewBackoff(10*time.Millisecond, 200*time.Millisecond),
+	}
+}
+
+// Transact reads stream, asks decide for the events to append given everything in it, and appends
+// them expecting the stream unchanged since the read. On a conflict it reads again and re-runs
+// decide. An error from decide (a broken invariant, say) is returned as is, without retrying.
+// decide may run several times, so it must not have side effects.
+func Transact(ctx context.Context, client *kurrentdb.Client, stream string, decide func(current []*kurrentdb.RecordedEvent) ([]kurrentdb.EventData, error)) (TransactResult, error) {
+	return NewTransactor(NewClientStore(client)).Transact(ctx, stream, decide)
+}
+
+// Transact is the function Transact with the transactor's retry settings. With a Resolver, a
+// conflict reads just the events since the last read and asks the resolver whether ours still
+// apply: no backoff, no full read and no second decision, and a genuine conflict ends the
+// transaction rather than being retried into an error from decide.
+func (t *Transactor) Transact(ctx context.Context, stream string, decide func(current []*kurrentdb.RecordedEvent) ([]kurrentdb.EventData, error)) (TransactResult, error) {
+	var result TransactResult
+	var conflict error
+	var current []*kurrentdb.RecordedEvent
+	var events []kurrentdb.EventData
+	version := NoVersion
+	redecide := true
+	backoff := t.Backoff
+	for result.Attempts < t.MaxAttempts {
+		if redecide && result.Attempts > 0 {
+			if err := backoff.Wait(ctx); err != nil {
+				return result, err
+			}
+		}
+		result.Attempts++
+
+		if redecide {
+			var err error
+			if current, version, err = readStreamFrom(ctx, t.store, stream, NoVersion); err != nil {
+				return result, fmt.Errorf("read %s: %w", stream, err)
+			}
+			if events, err = decide(current); err != nil {
+				return result, err
+			}
+		}
+		if len(events) == 0 {
+			result.Version = version
+			return result, nil
+		}
+
+		if t.beforeAppend != nil {
+			t.beforeAppend(result.Attempts)
+		}
+		written, err := t.store.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{
+			StreamState: expectedState(version),
+		}, events...)
+		if isWrongExpectedVersion(err) {
+			conflict = err
+			if result.Attempts == t.MaxAttempts {
+				break
+			}
+			if redecide, err = t.resolve(ctx, stream, &current, &version, &events); err != nil {
+				return result, err
+			}
+			if !redecide {
+				result.Merged++
+			}
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("append to %s: %w", stream, err)
+		}
+		result.Version = int64(written.NextExpectedVersion)
+		result.Appended = len(events)
+		return result, nil
+	}
+	return result, fmt.Errorf("%w: %s changed during each of %d attempts: %w", ErrTransactRetriesExhausted, stream, result.Attempts, conflict)
+}
+
+// resolve reads the events appended after *version and passes them to the resolver, moving
+// current, version and events on past them. It returns true to decide again instead: without a
+// resolver, or when the resolver returns ErrRedecide.
+func (t *Transactor) resolve(ctx context.Context, stream string, current *[]*kurrentdb.RecordedEvent, version *int64, events *[]kurrentdb.EventData) (bool, error) {
+	if t.Resolver == nil {
+		return true, nil
+	}
+	theirs, latest, err := readStreamFrom(ctx, t.store, stream, *version)
+	if err != nil {
+		return false, fmt.Errorf("read %s after %d: %w", stream, *version, err)
+	}
+	merged, err := t.Resolver(*current, theirs, *events)
+	if errors.Is(err, ErrRedecide) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	*current = append(*current, theirs...)
+	*version = latest
+	*events = merged
+	return false, nil
+}
+
+// readStreamFrom returns the events of stream after revision after (NoVersion for all of them) and
+// the revision of the last event in the stream; a missing stream has no events and NoVersion.
+// Links are not resolved: a stream of links, such as a category stream, comes back as the link
+// records, so the events and the revision are always those of stream itself.
+func readStreamFrom(ctx context.Context, store EventStore, stream string, after int64) ([]*kurrentdb.RecordedEvent, int64, error) {
+	var from kurrentdb.StreamPosition = kurrentdb.Start{}
+	if after != NoVersion {
+		from = kurrentdb.Revision(uint64(after + 1))
+	}
+	reader, err := store.ReadStream(ctx, stream, kurrentdb.ReadStreamOptions{From: from}, ^uint64(0))
+	if isStreamNotFound(err) {
+		return nil, NoVersion, nil
+	}
+	if err != nil {
+		return nil, after, err
+	}
+	defer reader.Close()
+
+	var events []*kurrentdb.RecordedEvent
+	version := after
+	for {
+		event, err := reader.Recv()
+		if errors.Is(err, io.EOF) {
+			return events, version, nil
+		}
+		if isStreamNotFound(err) {
+			return nil, NoVersion, nil
+		}
+		if err != nil {
+			return nil, after, err
+		}
+		recorded := event.OriginalEvent()
+		events = append(events, recorded)
+		version = int64(recorded.EventNumber)
+	}
+}
+
+// RunTransact enforces "no item twice in a cart" with Transact, under a racing writer with and
+// without a conflict resolver, under concurrent writers and until retries run out
+func RunTransact() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	errDuplicateItem := errors.New("item already in cart")
+	itemAdded := func(item string) kurrentdb.EventData {
+		return kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   "ItemAdded",
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(fmt.Sprintf(`{"item":%q}`, item)),
+		}
+	}
+	itemsIn := func(current []*kurrentdb.RecordedEvent) []string {
+		var items []string
+		for _, event := range current {
+			var added struct {
+				Item string `json:"item"`
+			}
+			if event.EventType == "ItemAdded" && json.Unmarshal(event.Data, &added) == nil {
+				items = append(items, added.Item)
+			}
+		}
+		return items
+	}
+	// addItem is the decision: the invariant is checked against the stream as just read
+	addItem := func(item string) func([]*kurrentdb.RecordedEvent) ([]kurrentdb.EventData, error) {
+		return func(current []*kurrentdb.RecordedEvent) ([]kurrentdb.EventData, error) {
+			if slices.Contains(itemsIn(current), item) {
+				return nil, fmt.Errorf("%w: %s", errDuplicateItem, item)
+			}
+			return []kurrentdb.EventData{itemAdded(item)}, nil
+		}
+	}
+	items := func(stream string) []string {
+		current, _, err := readStreamFrom(ctx, NewClientStore(client), stream, NoVersion)
+		if err != nil {
+			panic(err)
+		}
+		return itemsIn(current)
+	}
+
+	// === SIMPLE ===
+	fmt.Println("\n=== Adding items ===")
+	cart := Streams.Name("cart", uuid.New().String())
+	first, err := Transact(ctx, client, cart, addItem("Widget"))
+	fmt.Printf("  Widget: version %d after %d attempt(s), err %v\n", first.Version, first.Attempts, err)
+	_, duplicateErr := Transact(ctx, client, cart, addItem("Widget"))
+	fmt.Printf("  Widget again: %v\n", duplicateErr)
+
+	// === RACING WRITER ===
+	fmt.Println("\n=== Another writer adds the same item between read and append ===")
+	transactor := NewTransactor(NewClientStore(client))
+	transactor.beforeAppend = func(attempt int) {
+		if attempt == 1 {
+			if _, err := client.AppendToStream(ctx, cart, kurrentdb.AppendToStreamOptions{}, itemAdded("Gadget")); err != nil {
+				panic(err)
+			}
+		}
+	}
+	raced, racedErr := transactor.Transact(ctx, cart, addItem("Gadget"))
+	fmt.Printf("  Gadget: %d attempt(s), err %v\n", raced.Attempts, racedErr)
+
+	// === MERGING ===
+	// With a resolver, the Sprocket another writer adds is checked against our Gear alone: no
+	// backoff, no reading the cart again, no second decision
+	fmt.Println("\n=== Another writer adds a different item between read and append ===")
+	merging := NewTransactor(NewClientStore(client))
+	merging.Resolver = AbortOnConflict(func(ours kurrentdb.EventData, theirs *kurrentdb.RecordedEvent) bool {
+		return theirs.EventType == "ItemAdded" && slices.Equal(itemsIn([]*kurrentdb.RecordedEvent{theirs}), []string{"Gear"})
+	})
+	merging.beforeAppend = func(attempt int) {
+		if attempt == 1 {
+			if _, err := client.AppendToStream(ctx, cart, kurrentdb.AppendToStreamOptions{}, itemAdded("Sprocket")); err != nil {
+				panic(err)
+			}
+		}
+	}
+	merged, mergedErr := merging.Transact(ctx, cart, addItem("Gear"))
+	fmt.Printf("  Gear: %d attempt(s), %d merged, err %v\n", merged.Attempts, merged.Merged, mergedErr)
+
+	// === CONCURRENT WRITERS ===
+	fmt.Println("\n=== 10 writers add Gizmo at once ===")
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var succeeded, rejected, otherErrors int
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := Transact(ctx, client, cart, addItem("Gizmo"))
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.Is(err, errDuplicateItem):
+				rejected++
+			default:
+				otherErrors++
+				fmt.Printf("  unexpected: %v\n", err)
+			}
+		}()
+	}
+	wg.Wait()
+	fmt.Printf("  %d added, %d rejected as duplicates\n", succeeded, rejected)
+
+	// === RETRIES EXHAUSTED ===
+	fmt.Println("\n=== A writer that always gets there first ===")
+	busy := NewTransactor(NewClientStore(client))
+	busy.MaxAttempts = 3
+	busy.beforeAppend = func(int) {
+		if _, err := client.AppendToStream(ctx, cart, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   "CartViewed",
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(`{}`),
+		}); err != nil {
+			panic(err)
+		}
+	}
+	exhausted, exhaustedErr := busy.Transact(ctx, cart, addItem("Doohickey"))
+	fmt.Printf("  Doohickey: %v\n", exhaustedErr)
+
+	final := items(cart)
+	fmt.Printf("\nCart: %v\n", final)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if err != nil || first.Version != 0 || first.Attempts != 1 || first.Appended != 1 {
+		fmt.Printf("FAIL: The first item should be appended at version 0 in one attempt, got %+v (%v)\n", first, err)
+		passed = false
+	}
+	if !errors.Is(duplicateErr, errDuplicateItem) {
+		fmt.Printf("FAIL: A duplicate item should be rejected by the decision, got %v\n", duplicateErr)
+		passed = false
+	}
+	if !errors.Is(racedErr, errDuplicateItem) || raced.Attempts != 2 {
+		fmt.Printf("FAIL: After the conflict the retry should see the racer's Gadget and reject it, got %d attempts (%v)\n", raced.Attempts, racedErr)
+		passed = false
+	}
+	if mergedErr != nil || merged.Attempts != 2 || merged.Merged != 1 || merged.Appended != 1 {
+		fmt.Printf("FAIL: Gear should be merged after the racer's Sprocket in 2 attempts, got %+v (%v)\n", merged, mergedErr)
+		passed = false
+	}
+	if succeeded != 1 || rejected != 9 || otherErrors != 0 {
+		fmt.Printf("FAIL: Exactly one concurrent writer should add Gizmo, got %d added, %d rejected, %d errors\n", succeeded, rejected, otherErrors)
+		passed = false
+	}
+	if !errors.Is(exhaustedErr, ErrTransactRetriesExhausted) || exhausted.Attempts != 3 {
+		fmt.Printf("FAIL: Expected ErrTransactRetriesExhausted after 3 attempts, got %d (%v)\n", exhausted.Attempts, exhaustedErr)
+		passed = false
+	}
+	if !slices.Equal(final, []string{"Widget", "Gadget", "Sprocket", "Gear", "Gizmo"}) {
+		fmt.Printf("FAIL: Expected each item once [Widget Gadget Sprocket Gear Gizmo], got %v\n", final)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll transact tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
+
+// === CHECKS ===
+
+// RunTransactChecks races writers into a cart on an in-memory store and checks that conflicts are
+// retried, merged or aborted. No server required.
+func RunTransactChecks() {
+	checks := newChecker("transact")
+
+	ctx := context.Background()
+	store := NewMemoryEventStore()
+	errDuplicateItem := errors.New("item already in cart")
+	errCartFull := errors.New("cart full")
+	const maxItems = 3
+
+	itemAdded := func(item string) kurrentdb.EventData {
+		return kurrentdb.EventData{EventID: uuid.New(), EventType: "ItemAdded", ContentType: kurrentdb.ContentTypeJson, Data: []byte(fmt.Sprintf(`{"item":%q}`, item))}
+	}
+	itemOf := func(data []byte) string {
+		var added struct {
+			Item string `json:"item"`
+		}
+		json.Unmarshal(data, &added)
+		return added.Item
+	}
+	itemsIn := func(events []*kurrentdb.RecordedEvent) []string {
+		var items []string
+		for _, event := range events {
+			if event.EventType == "ItemAdded" {
+				items = append(items, itemOf(event.Data))
+			}
+		}
+		return items
+	}
+	// validate is the add-item rule, run by the decision on the stream as read and by the merge on
+	// the stream with the concurrent events added
+	validate := func(events []*kurrentdb.RecordedEvent, item string) error {
+		items := itemsIn(events)
+		if slices.Contains(items, item) {
+			return fmt.Errorf("%w: %s", errDuplicateItem, item)
+		}
+		if len(items) >= maxItems {
+			return fmt.Errorf("%w: %d items", errCartFull, len(items))
+		}
+		return nil
+	}
+	decisions := 0
+	addItem := func(item string) func([]*kurrentdb.RecordedEvent) ([]kurrentdb.EventData, error) {
+		return func(current []*kurrentdb.RecordedEvent) ([]kurrentdb.EventData, error) {
+			decisions++
+			if err := validate(current, item); err != nil {
+				return nil, err
+			}
+			return []kurrentdb.EventData{itemAdded(item)}, nil
+		}
+	}
+	// revalidate merges an add-item against concurrently added items
+	revalidate := func(base, theirs []*kurrentdb.RecordedEvent, ours []kurrentdb.EventData) ([]kurrentdb.EventData, error) {
+		for _, event := range ours {
+			if err := validate(append(slices.Clone(base), theirs...), itemOf(event.Data)); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrTransactConflict, err)
+			}
+		}
+		return ours, nil
+	}
+	// racing appends events as another writer between our read and our append, on the first attempt
+	racing := func(cart string, resolver ConflictResolver, events ...kurrentdb.EventData) *Transactor {
+		transactor := NewTransactor(store)
+		transactor.Backoff = NewBackoff(time.Millisecond, time.Millisecond)
+		transactor.Resolver = resolver
+		transactor.beforeAppend = func(attempt int) {
+			if attempt == 1 {
+				if _, err := store.AppendToStream(ctx, cart, kurrentdb.AppendToStreamOptions{}, events...); err != nil {
+					panic(err)
+				}
+			}
+		}
+		return transactor
+	}
+	items := func(cart string) []string {
+		events, _, err := readStreamFrom(ctx, store, cart, NoVersion)
+		if err != nil {
+			panic(err)
+		}
+		return itemsIn(events)
+	}
+
+	fmt.Println("\n--- Without a resolver ---")
+	cart := Streams.Name("cart", "redecide")
+	decisions = 0
+	result, err := racing(cart, nil, itemAdded("Gadget")).Transact(ctx, cart, addItem("Widget"))
+	fmt.Printf("  %+v, %d decisions, %v\n", result, decisions, items(cart))
+	checks.Check(err == nil && result.Attempts == 2 && result.Merged == 0 && decisions == 2, "a conflict should be decided again, got %+v after %d decisions (%v)", result, decisions, err)
+	checks.Check(slices.Equal(items(cart), []string{"Gadget", "Widget"}), "both items should be added, got %v", items(cart))
+
+	fmt.Println("\n--- Merging ---")
+	cart = Streams.Name("cart", "merge")
+	store.AppendToStream(ctx, cart, kurrentdb.AppendToStreamOptions{}, itemAdded("Widget"))
+	decisions = 0
+	result, err = racing(cart, revalidate, itemAdded("Gadget")).Transact(ctx, cart, addItem("Gizmo"))
+	fmt.Printf("  %+v, %d decisions, %v\n", result, decisions, items(cart))
+	checks.Check(err == nil && result.Attempts == 2 && result.Merged == 1 && decisions == 1, "an unrelated item should be merged without deciding again, got %+v after %d decisions (%v)", result, decisions, err)
+	checks.Check(result.Version == 2 && slices.Equal(items(cart), []string{"Widget", "Gadget", "Gizmo"}), "ours should follow theirs, got version %d and %v", result.Version, items(cart))
+
+	fmt.Println("\n--- Genuine conflicts ---")
+	cart = Streams.Name("cart", "duplicate")
+	_, err = racing(cart, revalidate, itemAdded("Widget")).Transact(ctx, cart, addItem("Widget"))
+	fmt.Printf("  %v\n", err)
+	checks.Check(errors.Is(err, ErrTransactConflict) && errors.Is(err, errDuplicateItem), "the same item added concurrently should abort, got %v", err)
+	checks.Check(slices.Equal(items(cart), []string{"Widget"}), "an aborted merge should append nothing, got %v", items(cart))
+
+	cart = Streams.Name("cart", "full")
+	store.AppendToStream(ctx, cart, kurrentdb.AppendToStreamOptions{}, itemAdded("Widget"), itemAdded("Gadget"))
+	_, err = racing(cart, revalidate, itemAdded("Gizmo")).Transact(ctx, cart, addItem("Sprocket"))
+	fmt.Printf("  %v\n", err)
+	checks.Check(errors.Is(err, ErrTransactConflict) && errors.Is(err, errCartFull), "an item filling the cart concurrently should abort, got %v", err)
+
+	fmt.Println("\n--- AbortOnConflict ---")
+	sameItem := AbortOnConflict(func(ours kurrentdb.EventData, theirs *kurrentdb.RecordedEvent) bool {
+		return theirs.EventType == "CartCheckedOut" || theirs.EventType == ours.EventType && itemOf(theirs.Data) == itemOf(ours.Data)
+	})
+	cart = Streams.Name("cart", "abort")
+	result, err = racing(cart, sameItem, itemAdded("Gadget")).Transact(ctx, cart, addItem("Widget"))
+	checks.Check(err == nil && result.Merged == 1, "a different item should not conflict, got %+v (%v)", result, err)
+	_, err = racing(cart, sameItem, kurrentdb.EventData{EventType: "CartCheckedOut", ContentType: kurrentdb.ContentTypeJson, Data: []byte(`{}`)}).Transact(ctx, cart, addItem("Gizmo"))
+	fmt.Printf("  %v\n", err)
+	checks.Check(errors.Is(err, ErrTransactConflict), "a checkout should conflict with any item, got %v", err)
+
+	fmt.Println("\n--- Redecide ---")
+	cart = Streams.Name("cart", "unsure")
+	decisions = 0
+	unsure := func(base, theirs []*kurrentdb.RecordedEvent, ours []kurrentdb.EventData) ([]kurrentdb.EventData, error) {
+		return nil, ErrRedecide
+	}
+	_, err = racing(cart, unsure, itemAdded("Widget")).Transact(ctx, cart, addItem("Widget"))
+	checks.Check(errors.Is(err, errDuplicateItem) && !errors.Is(err, ErrTransactConflict) && decisions == 2, "ErrRedecide should decide again, got %v after %d decisions", err, decisions)
+
+	// A resolver whose merge already happened returns nothing to append
+	cart = Streams.Name("cart", "done")
+	settled := func(base, theirs []*kurrentdb.RecordedEvent, ours []kurrentdb.EventData) ([]kurrentdb.EventData, error) {
+		return nil, nil
+	}
+	result, err = racing(cart, settled, itemAdded("Widget")).Transact(ctx, cart, addItem("Widget"))
+	checks.Check(err == nil && result.Appended == 0 && result.Version == 0 && slices.Equal(items(cart), []string{"Widget"}), "an empty merge should append nothing, got %+v (%v)", result, err)
+
+	fmt.Println("\n--- Retries exhausted ---")
+	cart = Streams.Name("cart", "busy")
+	busy := NewTransactor(store)
+	busy.MaxAttempts = 3
+	busy.Resolver = revalidate
+	busy.beforeAppend = func(int) {
+		store.AppendToStream(ctx, cart, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{EventType: "CartViewed", ContentType: kurrentdb.ContentTypeJson, Data: []byte(`{}`)})
+	}
+	result, err = busy.Transact(ctx, cart, addItem("Widget"))
+	checks.Check(errors.Is(err, ErrTransactRetriesExhausted) && result.Attempts == 3 && result.Merged == 2, "merges should count towards MaxAttempts, got %+v (%v)", result, err)
+
+	checks.Done()
+}
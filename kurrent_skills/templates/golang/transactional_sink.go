@@ -0,0 +1,210 @@
+// KurrentDB Go Client Example - Transactional checkpoint + read model updates
+// Demonstrates: A sink contract that commits state and checkpoint together, and the runner driving it
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === TRANSACTIONAL SINK ===
+
+// TransactionalSink is a read model that stores its own checkpoint.
+//
+// Apply must update the read model and advance the checkpoint to position in one atomic unit
+// (e.g. a database transaction), and must ignore events at or before the stored checkpoint.
+// Together this guarantees:
+//   - no gap: the checkpoint never moves past an event whose update was not committed
+//   - no duplicate: after a crash at any point, redelivered events up to the checkpoint are
+//     skipped and the first uncommitted event is applied exactly once
+type TransactionalSink interface {
+	Apply(ctx context.Context, envelope Envelope, position kurrentdb.Position) error
+	// Checkpoint returns the last committed position, or nil if nothing has been applied
+	Checkpoint(ctx context.Context) (*kurrentdb.Position, error)
+}
+
+// TransactionalRunner feeds a $all subscription into a TransactionalSink, resuming from the sink's checkpoint
+type TransactionalRunner struct {
+	client  *kurrentdb.Client
+	sink    TransactionalSink
+	options kurrentdb.SubscribeToAllOptions
+}
+
+func NewTransactionalRunner(client *kurrentdb.Client, sink TransactionalSink, options kurrentdb.SubscribeToAllOptions) *TransactionalRunner {
+	return &TransactionalRunner{client: client, sink: sink, options: options}
+}
+
+// Run applies events until done returns true, ctx is cancelled, or the sink fails.
+// A sink error stops the runner; restarting resumes from the last committed checkpoint.
+func (r *TransactionalRunner) Run(ctx context.Context, done func(Envelope) bool) error {
+	checkpoint, err := r.sink.Checkpoint(ctx)
+	if err != nil {
+		return err
+	}
+
+	options := r.options
+	if checkpoint != nil {
+		options.From = *checkpoint
+		fmt.Printf("  Resuming from checkpoint %d/%d\n", checkpoint.Commit, checkpoint.Prepare)
+	} else if options.From == nil {
+		options.From = kurrentdb.Start{}
+		fmt.Println("  No checkpoint, starting from the beginning")
+	}
+
+	subscription, err := r.client.SubscribeToAll(ctx, options)
+	if err != nil {
+		return err
+	}
+	defer subscription.Close()
+
+	for {
+		event := subscription.Recv()
+
+		if event.SubscriptionDropped != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return event.SubscriptionDropped.Error
+		}
+
+		if event.EventAppeared != nil {
+			envelope := NewEnvelope(event.EventAppeared)
+			if err := r.sink.Apply(ctx, envelope, envelope.Position); err != nil {
+				return fmt.Errorf("apply %s@%d: %w", envelope.StreamID, envelope.EventNumber, err)
+			}
+			if done != nil && done(envelope) {
+				return nil
+			}
+		}
+	}
+}
+
+// errSimulatedCrash stands in for the process dying mid-transaction
+var errSimulatedCrash = errors.New("simulated crash before commit")
+
+// RunTransactionalSinkChecks proves no double-apply after a crash between apply and checkpoint, without a server
+func RunTransactionalSinkChecks() {
+	ctx := context.Background()
+
+	checks := newChecker("transactional sink")
+
+	dir, err := os.MkdirTemp("", "transactional-sink")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	dbPath := filepath.Join(dir, "readmodel.db")
+
+	streamName := Streams.Name("order", uuid.New().String())
+	var commit uint64
+	envelope := func(eventType, data string) Envelope {
+		commit += 100
+		return Envelope{
+			EventID:   uuid.New(),
+			EventType: eventType,
+			StreamID:  streamName,
+			Data:      []byte(data),
+			Position:  kurrentdb.Position{Commit: commit, Prepare: commit},
+		}
+	}
+	history := []Envelope{
+		envelope("OrderCreated", `{"orderId":"1","customerId":"cust-1","amount":100}`),
+		envelope("ItemAdded", `{"item":"Widget","price":25}`),
+		envelope("ItemAdded", `{"item":"Gadget","price":30}`),
+		envelope("OrderShipped", `{"shippedAt":"2024-01-15T10:00:00Z"}`),
+	}
+
+	// === FIRST RUN: CRASH WHILE APPLYING THE THIRD EVENT ===
+	sink, err := OpenSQLiteOrderReadModel(dbPath, "OrderSummary")
+	if err != nil {
+		panic(err)
+	}
+	sink.beforeCommit = func(e Envelope) error {
+		if e.EventID == history[2].EventID {
+			return errSimulatedCrash
+		}
+		return nil
+	}
+
+	var crashErr error
+	for _, e := range history {
+		if crashErr = sink.Apply(ctx, e, e.Position); crashErr != nil {
+			fmt.Printf("  Crashed applying %s: %v\n", e.EventType, crashErr)
+			break
+		}
+	}
+	sink.Close()
+
+	// === RESTART: THE SUBSCRIPTION REDELIVERS EVERYTHING ===
+	sink, err = OpenSQLiteOrderReadModel(dbPath, "OrderSummary")
+	if err != nil {
+		panic(err)
+	}
+	defer sink.Close()
+
+	checkpoint, err := sink.Checkpoint(ctx)
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range history {
+		if err := sink.Apply(ctx, e, e.Position); err != nil {
+			panic(err)
+		}
+	}
+
+	row, err := sink.Get(ctx, streamName)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("  Row after restart: status=%s amount=%.2f items=%v\n", row.Status, row.Amount, row.Items)
+
+	// === ONE APPEND, SEVERAL EVENTS: SAME COMMIT, INCREASING PREPARE ===
+	batchStream := Streams.Name("order", uuid.New().String())
+	commit += 100
+	batch := []Envelope{
+		{EventID: uuid.New(), EventType: "OrderCreated", StreamID: batchStream,
+			Data: []byte(`{"orderId":"2","customerId":"cust-2","amount":10}`), Position: kurrentdb.Position{Commit: commit, Prepare: commit}},
+		{EventID: uuid.New(), EventType: "ItemAdded", StreamID: batchStream,
+			Data: []byte(`{"item":"Widget","price":5}`), Position: kurrentdb.Position{Commit: commit, Prepare: commit + 10}},
+		{EventID: uuid.New(), EventType: "OrderShipped", StreamID: batchStream,
+			Data: []byte(`{"shippedAt":"2024-01-16T10:00:00Z"}`), Position: kurrentdb.Position{Commit: commit, Prepare: commit + 20}},
+	}
+	for range 2 {
+		// The second pass is a redelivery of the whole batch
+		for _, e := range batch {
+			if err := sink.Apply(ctx, e, e.Position); err != nil {
+				panic(err)
+			}
+		}
+	}
+	batchRow, err := sink.Get(ctx, batchStream)
+	if err != nil {
+		panic(err)
+	}
+	batchCheckpoint, err := sink.Checkpoint(ctx)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("  Batch row: status=%s amount=%.2f items=%v\n", batchRow.Status, batchRow.Amount, batchRow.Items)
+
+	// === ASSERTIONS ===
+	checks.Check(errors.Is(crashErr, errSimulatedCrash), "First run should crash, got %v", crashErr)
+	checks.Check(checkpoint != nil && checkpoint.Commit == history[1].Position.Commit,
+		"Checkpoint after crash should be the last committed event, got %v", checkpoint)
+	checks.Check(row.Amount == 155 && len(row.Items) == 2 && row.Status == "shipped",
+		"Expected shipped, amount 155 with 2 items, got %s, %.2f with %v", row.Status, row.Amount, row.Items)
+
+	checks.Check(batchRow.Amount == 15 && len(batchRow.Items) == 1 && batchRow.Status == "shipped",
+		"Every event of one append should apply once, expected shipped, amount 15 with 1 item, got %s, %.2f with %v",
+		batchRow.Status, batchRow.Amount, batchRow.Items)
+	checks.Check(batchCheckpoint != nil && *batchCheckpoint == batch[2].Position,
+		"Checkpoint should be the last event of the append, got %v", batchCheckpoint)
+
+	checks.Done()
+}
@@ -0,0 +1,661 @@
+// KurrentDB Go Client Example - gRPC transport compression
+// Demonstrates: Appending and reading over a gzip-compressed gRPC connection, measuring the bytes on the wire with and without it
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+	"github.com/kurrent-io/KurrentDB-Client-Go/protos/kurrentdb/protocols/v1/shared"
+	"github.com/kurrent-io/KurrentDB-Client-Go/protos/kurrentdb/protocols/v1/streams"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+// === TRANSPORT COMPRESSION ===
+//
+// Compressing payloads before appending shrinks what is stored; transport compression shrinks only
+// what crosses the network, and every message is compressed by the sender and inflated by the
+// receiver on each call. It pays off on slow or metered links with compressible payloads like JSON,
+// and costs CPU on both ends for every message: on a fast local network the time spent in gzip can
+// exceed the time saved sending, and payloads that are already compressed or encrypted don't shrink
+// at all.
+//
+// The client settings have no compression option, and kurrentdb.NewClient dials its own gRPC
+// connections without accepting dial options. TransportStreams therefore dials the node itself and
+// speaks the Streams service through the protocol stubs that ship with the client. It is a narrow
+// path for bulk transfers over a constrained link, not a replacement for the client:
+//   - it connects to the one node in the connection string, with no gossip discovery or leader
+//     reconnection, so it should point at the leader
+//   - only appends with an expected state and forward reads of a stream are implemented
+//   - gzip applies to requests, which is what an append sends; whether reads come back compressed
+//     is the server's choice, though the connection advertises that it accepts gzip
+
+// TransportStreams appends and reads over its own gRPC connection, compressing requests with
+// compressor: gzip.Name, or "" for none. A server that rejects the compressed encoding downgrades
+// the connection to uncompressed for the rest of its life rather than failing the call.
+type TransportStreams struct {
+	conn       *grpc.ClientConn
+	client     streams.StreamsClient
+	compressor atomic.Value
+	// downgraded records the server rejecting compressed requests
+	downgraded atomic.Bool
+}
+
+// DialTransportStreams connects to the node settings name, with its TLS and credentials, compressing
+// requests with compressor. Extra options, such as a ByteCounter's dialer, are added last.
+func DialTransportStreams(settings *kurrentdb.Configuration, compressor string, options ...grpc.DialOption) (*TransportStreams, error) {
+	address := settings.Address
+	if address == "" && len(settings.GossipSeeds) > 0 {
+		address = settings.GossipSeeds[0].String()
+	}
+	if address == "" {
+		return nil, errors.New("transport streams: the connection string names no node")
+	}
+
+	secure := !settings.DisableTLS
+	transport := insecure.NewCredentials()
+	if secure {
+		config := &tls.Config{InsecureSkipVerify: settings.SkipCertificateVerification, RootCAs: settings.RootCAs}
+		if settings.UserCertFile != "" && settings.UserKeyFile != "" {
+			certificate, err := tls.LoadX509KeyPair(settings.UserCertFile, settings.UserKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("transport streams: load user certificate: %w", err)
+			}
+			config.Certificates = []tls.Certificate{certificate}
+		}
+		transport = credentials.NewTLS(config)
+	}
+	dial := []grpc.DialOption{grpc.WithTransportCredentials(transport)}
+	if settings.Username != "" {
+		dial = append(dial, grpc.WithPerRPCCredentials(basicAuth{
+			username: settings.Username, password: settings.Password, secure: secure,
+		}))
+	}
+	conn, err := grpc.NewClient("passthrough:///"+address, append(dial, options...)...)
+	if err != nil {
+		return nil, err
+	}
+	s := &TransportStreams{conn: conn, client: streams.NewStreamsClient(conn)}
+	s.compressor.Store(compressor)
+	return s, nil
+}
+
+// basicAuth sends the connection string's credentials with every call, as the client does
+type basicAuth struct {
+	username, password string
+	secure             bool
+}
+
+func (a basicAuth) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	token := base64.StdEncoding.EncodeToString([]byte(a.username + ":" + a.password))
+	return map[string]string{"authorization": "Basic " + token}, nil
+}
+
+func (a basicAuth) RequireTransportSecurity() bool { return a.secure }
+
+// Compressor is the compressor requests currently use, "" once the server has rejected it
+func (s *TransportStreams) Compressor() string { return s.compressor.Load().(string) }
+
+// Downgraded reports whether the server rejected compressed requests
+func (s *TransportStreams) Downgraded() bool { return s.downgraded.Load() }
+
+func (s *TransportStreams) Close() error { return s.conn.Close() }
+
+// callOptions selects the compressor for one call
+func (s *TransportStreams) callOptions() []grpc.CallOption {
+	if compressor := s.Compressor(); compressor != "" {
+		return []grpc.CallOption{grpc.UseCompressor(compressor)}
+	}
+	return nil
+}
+
+// isCompressionUnsupported reports whether err is a server refusing a request's grpc-encoding:
+// gRPC servers answer Unimplemented for an encoding they have no decompressor for, before the
+// handler runs, so the call is safe to repeat uncompressed
+func isCompressionUnsupported(err error) bool {
+	s, ok := status.FromError(err)
+	return ok && s.Code() == codes.Unimplemented && strings.Contains(s.Message(), "grpc-encoding")
+}
+
+// downgrade switches the connection to uncompressed requests if err says the server can't read them
+func (s *TransportStreams) downgrade(err error) bool {
+	if s.Compressor() == "" || !isCompressionUnsupported(err) {
+		return false
+	}
+	fmt.Printf("  Server rejected %s requests, continuing uncompressed: %v\n", s.Compressor(), status.Convert(err).Message())
+	s.compressor.Store("")
+	s.downgraded.Store(true)
+	return true
+}
+
+// AppendToStream appends events to stream with options' expected state, like the client's
+func (s *TransportStreams) AppendToStream(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events ...kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+	result, err := s.append(ctx, stream, options, events)
+	if err != nil && s.downgrade(err) {
+		result, err = s.append(ctx, stream, options, events)
+	}
+	return result, err
+}
+
+func (s *TransportStreams) append(ctx context.Context, stream string, options kurrentdb.AppendToStreamOptions, events []kurrentdb.EventData) (*kurrentdb.WriteResult, error) {
+	call, err := s.client.Append(ctx, s.callOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	if err := call.Send(appendHeader(stream, options.StreamState)); err != nil {
+		return nil, appendSendError(call, err)
+	}
+	for _, event := range events {
+		if err := call.Send(&streams.AppendReq{Content: &streams.AppendReq_ProposedMessage_{ProposedMessage: proposedMessage(event)}}); err != nil {
+			return nil, appendSendError(call, err)
+		}
+	}
+	response, err := call.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+
+	if wrong := response.GetWrongExpectedVersion(); wrong != nil {
+		return nil, fmt.Errorf("append %s: wrong expected version, stream is at %v", stream, wrong.GetCurrentRevisionOption())
+	}
+	success := response.GetSuccess()
+	result := &kurrentdb.WriteResult{NextExpectedVersion: success.GetCurrentRevision()}
+	if position := success.GetPosition(); position != nil {
+		result.CommitPosition, result.PreparePosition = position.CommitPosition, position.PreparePosition
+	}
+	return result, nil
+}
+
+// appendSendError turns a failed Send into the call's status: Send reports only io.EOF when the
+// server has already ended the call
+func appendSendError(call streams.Streams_AppendClient, err error) error {
+	if errors.Is(err, io.EOF) {
+		_, err = call.CloseAndRecv()
+	}
+	return err
+}
+
+func appendHeader(stream string, state kurrentdb.StreamState) *streams.AppendReq {
+	options := &streams.AppendReq_Options{StreamIdentifier: &shared.StreamIdentifier{StreamName: []byte(stream)}}
+	switch value := state.(type) {
+	case kurrentdb.NoStream:
+		options.ExpectedStreamRevision = &streams.AppendReq_Options_NoStream{NoStream: &shared.Empty{}}
+	case kurrentdb.StreamExists:
+		options.ExpectedStreamRevision = &streams.AppendReq_Options_StreamExists{StreamExists: &shared.Empty{}}
+	case kurrentdb.StreamRevision:
+		options.ExpectedStreamRevision = &streams.AppendReq_Options_Revision{Revision: value.Value}
+	default:
+		options.ExpectedStreamRevision = &streams.AppendReq_Options_Any{Any: &shared.Empty{}}
+	}
+	return &streams.AppendReq{Content: &streams.AppendReq_Options_{Options: options}}
+}
+
+func proposedMessage(event kurrentdb.EventData) *streams.AppendReq_ProposedMessage {
+	contentType := "application/octet-stream"
+	if event.ContentType == kurrentdb.ContentTypeJson {
+		contentType = "application/json"
+	}
+	id := event.EventID
+	if id == uuid.Nil {
+		id = uuid.New()
+	}
+	most, least := kurrentdb.UUIDAsInt64(id)
+	return &streams.AppendReq_ProposedMessage{
+		Id: &shared.UUID{Value: &shared.UUID_Structured_{Structured: &shared.UUID_Structured{
+			MostSignificantBits: most, LeastSignificantBits: least,
+		}}},
+		Metadata:       map[string]string{"type": event.EventType, "content-type": contentType},
+		CustomMetadata: append([]byte{}, event.Metadata...),
+		Data:           append([]byte{}, event.Data...),
+	}
+}
+
+// ReadStream reads up to count events of stream forwards from the start. A missing stream reads
+// as no events.
+func (s *TransportStreams) ReadStream(ctx context.Context, stream string, count uint64) ([]*kurrentdb.RecordedEvent, error) {
+	events, err := s.read(ctx, stream, count)
+	if err != nil && s.downgrade(err) {
+		events, err = s.read(ctx, stream, count)
+	}
+	return events, err
+}
+
+func (s *TransportStreams) read(ctx context.Context, stream string, count uint64) ([]*kurrentdb.RecordedEvent, error) {
+	call, err := s.client.Read(ctx, &streams.ReadReq{Options: &streams.ReadReq_Options{
+		StreamOption: &streams.ReadReq_Options_Stream{Stream: &streams.ReadReq_Options_StreamOptions{
+			StreamIdentifier: &shared.StreamIdentifier{StreamName: []byte(stream)},
+			RevisionOption:   &streams.ReadReq_Options_StreamOptions_Start{Start: &shared.Empty{}},
+		}},
+		ReadDirection: streams.ReadReq_Options_Forwards,
+		CountOption:   &streams.ReadReq_Options_Count{Count: count},
+		FilterOption:  &streams.ReadReq_Options_NoFilter{NoFilter: &shared.Empty{}},
+		UuidOption:    &streams.ReadReq_Options_UUIDOption{Content: &streams.ReadReq_Options_UUIDOption_Structured{Structured: &shared.Empty{}}},
+	}}, s.callOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*kurrentdb.RecordedEvent
+	for {
+		response, err := call.Recv()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if response.GetStreamNotFound() != nil {
+			return nil, nil
+		}
+		if read := response.GetEvent(); read != nil && read.GetEvent() != nil {
+			events = append(events, recordedFromProto(read.GetEvent()))
+		}
+	}
+}
+
+func recordedFromProto(event *streams.ReadResp_ReadEvent_RecordedEvent) *kurrentdb.RecordedEvent {
+	structured := event.GetId().GetStructured()
+	id, _ := kurrentdb.ParseUUIDFromInt64(structured.GetMostSignificantBits(), structured.GetLeastSignificantBits())
+	return &kurrentdb.RecordedEvent{
+		EventID:        id,
+		EventType:      event.Metadata["type"],
+		ContentType:    event.Metadata["content-type"],
+		StreamID:       string(event.GetStreamIdentifier().GetStreamName()),
+		EventNumber:    event.StreamRevision,
+		Position:       kurrentdb.Position{Commit: event.CommitPosition, Prepare: event.PreparePosition},
+		Data:           event.Data,
+		SystemMetadata: event.Metadata,
+		UserMetadata:   event.CustomMetadata,
+	}
+}
+
+// === MEASURING THE WIRE ===
+
+// ByteCounter counts the bytes written to and read from the connections dialled through Dialer,
+// after TLS and HTTP/2 framing, which is what a constrained link carries
+type ByteCounter struct {
+	sent, received atomic.Int64
+}
+
+// Dialer returns a dial option that routes connections through the counter
+func (c *ByteCounter) Dialer() grpc.DialOption {
+	return grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+		if err != nil {
+			return nil, err
+		}
+		return &countingConn{Conn: conn, counter: c}, nil
+	})
+}
+
+// Sent and Received return the bytes counted so far
+func (c *ByteCounter) Sent() int64     { return c.sent.Load() }
+func (c *ByteCounter) Received() int64 { return c.received.Load() }
+
+type countingConn struct {
+	net.Conn
+	counter *ByteCounter
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.counter.received.Add(int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.counter.sent.Add(int64(n))
+	return n, err
+}
+
+// TransportMeasurement is the traffic and time of appending a batch and reading it back
+type TransportMeasurement struct {
+	Compressor string
+	Downgraded bool
+	Sent       int64
+	Received   int64
+	Elapsed    time.Duration
+	Events     []*kurrentdb.RecordedEvent
+}
+
+func (m TransportMeasurement) String() string {
+	name := m.Compressor
+	if name == "" {
+		name = "none"
+	}
+	if m.Downgraded {
+		name += " (downgraded)"
+	}
+	return fmt.Sprintf("%-18s sent %8d bytes, received %8d bytes in %s", name, m.Sent, m.Received, m.Elapsed.Round(time.Millisecond))
+}
+
+// measureTransport appends events to stream over a fresh connection compressing with compressor,
+// reads them back and reports what crossed the wire. Connection setup is part of the count, the
+// same for both modes.
+func measureTransport(ctx context.Context, settings *kurrentdb.Configuration, compressor, stream string, events []kurrentdb.EventData) (TransportMeasurement, error) {
+	counter := &ByteCounter{}
+	conn, err := DialTransportStreams(settings, compressor, counter.Dialer())
+	if err != nil {
+		return TransportMeasurement{}, err
+	}
+	defer conn.Close()
+
+	started := time.Now()
+	measurement := TransportMeasurement{Compressor: compressor}
+	if _, err := conn.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{StreamState: kurrentdb.NoStream{}}, events...); err != nil {
+		return measurement, fmt.Errorf("append %s: %w", stream, err)
+	}
+	if measurement.Events, err = conn.ReadStream(ctx, stream, uint64(len(events))); err != nil {
+		return measurement, fmt.Errorf("read %s: %w", stream, err)
+	}
+	measurement.Elapsed = time.Since(started)
+	measurement.Sent, measurement.Received = counter.Sent(), counter.Received()
+	measurement.Downgraded = conn.Downgraded()
+	return measurement, nil
+}
+
+// transportBatch builds count order events with a couple of kilobytes of repetitive JSON each, the
+// kind of payload gzip shrinks well
+func transportBatch(count int) []kurrentdb.EventData {
+	events := make([]kurrentdb.EventData, count)
+	for i := range events {
+		lines := make([]map[string]interface{}, 20)
+		for j := range lines {
+			lines[j] = map[string]interface{}{"sku": fmt.Sprintf("SKU-%05d", j), "description": "Stainless steel widget", "quantity": j + 1, "unitPrice": 9.99, "currency": "EUR"}
+		}
+		data, _ := json.Marshal(map[string]interface{}{"orderId": fmt.Sprintf("order-%d", i), "customerId": "customer-42", "lines": lines})
+		events[i] = kurrentdb.EventData{EventID: uuid.New(), EventType: "OrderPlaced", ContentType: kurrentdb.ContentTypeJson, Data: data}
+	}
+	return events
+}
+
+// randomBatch builds count events of random bytes, standing in for payloads already compressed or
+// encrypted
+func randomBatch(count, size int) []kurrentdb.EventData {
+	events := make([]kurrentdb.EventData, count)
+	for i := range events {
+		data := make([]byte, size)
+		rand.Read(data)
+		events[i] = kurrentdb.EventData{EventID: uuid.New(), EventType: "BlobStored", ContentType: kurrentdb.ContentTypeBinary, Data: data}
+	}
+	return events
+}
+
+// === CHECKS ===
+
+// memoryStreamsServer serves the Streams append and stream read RPCs from a MemoryEventStore
+type memoryStreamsServer struct {
+	streams.UnimplementedStreamsServer
+	store *MemoryEventStore
+}
+
+func (s *memoryStreamsServer) Append(call grpc.ClientStreamingServer[streams.AppendReq, streams.AppendResp]) error {
+	header, err := call.Recv()
+	if err != nil {
+		return err
+	}
+	options := header.GetOptions()
+	var state kurrentdb.StreamState = kurrentdb.Any{}
+	switch {
+	case options.GetNoStream() != nil:
+		state = kurrentdb.NoStream{}
+	case options.GetStreamExists() != nil:
+		state = kurrentdb.StreamExists{}
+	case options.GetExpectedStreamRevision() != nil && options.GetAny() == nil:
+		state = kurrentdb.StreamRevision{Value: options.GetRevision()}
+	}
+
+	var events []kurrentdb.EventData
+	for {
+		request, err := call.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		message := request.GetProposedMessage()
+		structured := message.GetId().GetStructured()
+		id, _ := kurrentdb.ParseUUIDFromInt64(structured.GetMostSignificantBits(), structured.GetLeastSignificantBits())
+		contentType := kurrentdb.ContentTypeBinary
+		if message.Metadata["content-type"] == "application/json" {
+			contentType = kurrentdb.ContentTypeJson
+		}
+		events = append(events, kurrentdb.EventData{
+			EventID: id, EventType: message.Metadata["type"], ContentType: contentType,
+			Data: message.Data, Metadata: message.CustomMetadata,
+		})
+	}
+
+	result, err := s.store.AppendToStream(call.Context(), string(options.GetStreamIdentifier().GetStreamName()), kurrentdb.AppendToStreamOptions{StreamState: state}, events...)
+	if err != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return call.SendAndClose(&streams.AppendResp{Result: &streams.AppendResp_Success_{Success: &streams.AppendResp_Success{
+		CurrentRevisionOption: &streams.AppendResp_Success_CurrentRevision{CurrentRevision: result.NextExpectedVersion},
+		PositionOption: &streams.AppendResp_Success_Position{Position: &streams.AppendResp_Position{
+			CommitPosition: result.CommitPosition, PreparePosition: result.PreparePosition,
+		}},
+	}}})
+}
+
+func (s *memoryStreamsServer) Read(request *streams.ReadReq, call grpc.ServerStreamingServer[streams.ReadResp]) error {
+	stream := request.GetOptions().GetStream().GetStreamIdentifier()
+	events, version, err := readStreamFrom(call.Context(), s.store, string(stream.GetStreamName()), NoVersion)
+	if err != nil {
+		return err
+	}
+	if version == NoVersion {
+		return call.Send(&streams.ReadResp{Content: &streams.ReadResp_StreamNotFound_{StreamNotFound: &streams.ReadResp_StreamNotFound{StreamIdentifier: stream}}})
+	}
+	for _, event := range events {
+		most, least := kurrentdb.UUIDAsInt64(event.EventID)
+		if err := call.Send(&streams.ReadResp{Content: &streams.ReadResp_Event{Event: &streams.ReadResp_ReadEvent{
+			Event: &streams.ReadResp_ReadEvent_RecordedEvent{
+				Id:               &shared.UUID{Value: &shared.UUID_Structured_{Structured: &shared.UUID_Structured{MostSignificantBits: most, LeastSignificantBits: least}}},
+				StreamIdentifier: stream,
+				StreamRevision:   event.EventNumber,
+				CommitPosition:   event.Position.Commit,
+				PreparePosition:  event.Position.Prepare,
+				Metadata:         map[string]string{"type": event.EventType, "content-type": event.ContentType},
+				CustomMetadata:   event.UserMetadata,
+				Data:             event.Data,
+			},
+		}}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rejectCompression makes a test server behave like one without gzip: the encoding of each
+// request is recorded as the call starts, and compressed calls are refused the way gRPC refuses an
+// encoding it can't decompress
+type rejectCompression struct{}
+
+type requestEncodingKey struct{}
+
+func (rejectCompression) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, requestEncodingKey{}, new(atomic.Value))
+}
+
+func (rejectCompression) HandleRPC(ctx context.Context, rpc stats.RPCStats) {
+	if header, ok := rpc.(*stats.InHeader); ok {
+		if encoding, ok := ctx.Value(requestEncodingKey{}).(*atomic.Value); ok {
+			encoding.Store(header.Compression)
+		}
+	}
+}
+
+func (rejectCompression) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (rejectCompression) HandleConn(context.Context, stats.ConnStats) {}
+
+func (rejectCompression) intercept(srv any, call grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if encoding, ok := call.Context().Value(requestEncodingKey{}).(*atomic.Value); ok {
+		if name, _ := encoding.Load().(string); name != "" && name != "identity" {
+			return status.Errorf(codes.Unimplemented, "grpc: Decompressor is not installed for grpc-encoding %q", name)
+		}
+	}
+	return handler(srv, call)
+}
+
+// serveMemoryStreams starts a Streams server over store on a loopback port, without gzip support
+// when rejectGzip is set
+func serveMemoryStreams(store *MemoryEventStore, rejectGzip bool) (*grpc.Server, *kurrentdb.Configuration, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, nil, err
+	}
+	var options []grpc.ServerOption
+	if rejectGzip {
+		options = append(options, grpc.StatsHandler(rejectCompression{}), grpc.StreamInterceptor(rejectCompression{}.intercept))
+	}
+	server := grpc.NewServer(options...)
+	streams.RegisterStreamsServer(server, &memoryStreamsServer{store: store})
+	go server.Serve(listener)
+	return server, &kurrentdb.Configuration{Address: listener.Addr().String(), DisableTLS: true}, nil
+}
+
+// RunTransportCompressionChecks measures a batch over a loopback Streams server with and without gzip
+func RunTransportCompressionChecks() {
+	checks := newChecker("transport compression")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	store := NewMemoryEventStore()
+	server, settings, err := serveMemoryStreams(store, false)
+	if err != nil {
+		panic(err)
+	}
+	defer server.Stop()
+
+	fmt.Println("\n--- Compressible batch ---")
+	batch := transportBatch(200)
+	plain, plainErr := measureTransport(ctx, settings, "", "order-plain", batch)
+	gzipped, gzipErr := measureTransport(ctx, settings, gzip.Name, "order-gzip", batch)
+	fmt.Printf("  %v\n  %v\n", plain, gzipped)
+	checks.Check(plainErr == nil && gzipErr == nil, "both transfers should succeed: %v / %v", plainErr, gzipErr)
+	checks.Check(gzipped.Sent*4 < plain.Sent, "gzip should send under a quarter of the bytes of a JSON batch, got %d vs %d", gzipped.Sent, plain.Sent)
+	checks.Check(gzipped.Received*2 < plain.Received, "a server with gzip should answer reads compressed too, got %d vs %d", gzipped.Received, plain.Received)
+	checks.Check(len(gzipped.Events) == len(batch), "all %d events should read back, got %d", len(batch), len(gzipped.Events))
+	for i, event := range gzipped.Events {
+		if event.EventID != batch[i].EventID || string(event.Data) != string(batch[i].Data) || event.EventNumber != uint64(i) {
+			checks.Check(false, "event %d should round-trip unchanged, got %s@%d", i, event.EventID, event.EventNumber)
+			break
+		}
+	}
+
+	fmt.Println("\n--- Incompressible batch ---")
+	blobs := randomBatch(50, 4096)
+	plain, _ = measureTransport(ctx, settings, "", "blob-plain", blobs)
+	gzipped, _ = measureTransport(ctx, settings, gzip.Name, "blob-gzip", blobs)
+	fmt.Printf("  %v\n  %v\n", plain, gzipped)
+	checks.Check(gzipped.Sent*100 > plain.Sent*95, "random bytes shouldn't shrink, got %d vs %d", gzipped.Sent, plain.Sent)
+
+	fmt.Println("\n--- Server without gzip ---")
+	legacyStore := NewMemoryEventStore()
+	legacy, legacySettings, err := serveMemoryStreams(legacyStore, true)
+	if err != nil {
+		panic(err)
+	}
+	defer legacy.Stop()
+	downgraded, err := measureTransport(ctx, legacySettings, gzip.Name, "order-legacy", batch[:20])
+	fmt.Printf("  %v\n", downgraded)
+	checks.Check(err == nil && downgraded.Downgraded && len(downgraded.Events) == 20,
+		"a server rejecting gzip should be retried uncompressed, got %v (%v)", downgraded, err)
+	stored, _, _ := readStreamFrom(ctx, legacyStore, "order-legacy", NoVersion)
+	checks.Check(len(stored) == 20, "the rejected attempt shouldn't have appended anything, got %d events", len(stored))
+	checks.Check(!isCompressionUnsupported(status.Error(codes.Unimplemented, "unknown service")) &&
+		!isCompressionUnsupported(errors.New("grpc-encoding")),
+		"only an Unimplemented status about the encoding should downgrade")
+
+	fmt.Println("\n--- Missing stream ---")
+	conn, _ := DialTransportStreams(settings, gzip.Name)
+	missing, err := conn.ReadStream(ctx, "order-missing", 10)
+	conn.Close()
+	checks.Check(missing == nil && err == nil, "a missing stream should read as empty, got %v (%v)", missing, err)
+
+	checks.Done()
+}
+
+// === DEMO ===
+
+// RunTransportCompression appends the same large batch with and without gzip and compares the traffic
+func RunTransportCompression() {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	settings, err := kurrentdb.ParseConnectionString(connectionString)
+	if err != nil {
+		panic(err)
+	}
+	batch := transportBatch(200)
+	plainStream := Streams.Name("order", uuid.New().String())
+	gzipStream := Streams.Name("order", uuid.New().String())
+
+	plain, plainErr := measureTransport(ctx, settings, "", plainStream, batch)
+	gzipped, gzipErr := measureTransport(ctx, settings, gzip.Name, gzipStream, batch)
+	fmt.Printf("%v\n%v\n", plain, gzipped)
+	if plain.Sent > 0 {
+		fmt.Printf("gzip sent %.0f%% of the uncompressed bytes\n", 100*float64(gzipped.Sent)/float64(plain.Sent))
+	}
+	if gzipped.Downgraded {
+		fmt.Println("The server doesn't accept gzip requests; the batch was sent uncompressed")
+	}
+
+	// The regular client sees the same events
+	readBack, _, readErr := readStreamFrom(ctx, NewClientStore(client), gzipStream, NoVersion)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+	if plainErr != nil || gzipErr != nil || readErr != nil {
+		fmt.Printf("FAIL: Appending and reading should succeed, got %v / %v / %v\n", plainErr, gzipErr, readErr)
+		passed = false
+	}
+	if len(plain.Events) != len(batch) || len(gzipped.Events) != len(batch) || len(readBack) != len(batch) {
+		fmt.Printf("FAIL: Both streams should hold all %d events, got %d / %d / %d\n", len(batch), len(plain.Events), len(gzipped.Events), len(readBack))
+		passed = false
+	}
+	if !gzipped.Downgraded && gzipped.Sent >= plain.Sent {
+		fmt.Printf("FAIL: gzip should send fewer bytes, got %d vs %d\n", gzipped.Sent, plain.Sent)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll transport compression tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,277 @@
+// KurrentDB Go Client Example - Event type mapping for streams shared with other languages
+// Demonstrates: Decoding events written with .NET type names into Go structs, and writing back under those names
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === TYPE MAPPER ===
+
+// TypeMapper translates between the event types other producers write, such as .NET type names
+// like "Orders.Contracts.OrderCreated", and the names Go types are registered under. As an
+// EventRegistry it looks up the registry by the mapped name, so a Decoder reads foreign events
+// into Go structs. Types without a mapping pass through unchanged both ways. Safe for concurrent use.
+type TypeMapper struct {
+	registry EventRegistry
+
+	mu         sync.RWMutex
+	toInternal map[string]string
+	toExternal map[string]string
+}
+
+func NewTypeMapper(registry EventRegistry) *TypeMapper {
+	return &TypeMapper{
+		registry:   registry,
+		toInternal: make(map[string]string),
+		toExternal: make(map[string]string),
+	}
+}
+
+// Map reads external as internal. The first external name mapped to an internal name is the one
+// ToExternal writes; later ones are read-only aliases, e.g. for a type the producer has since
+// renamed or moved. Mapping an external name to two internal names panics.
+func (m *TypeMapper) Map(external, internal string) *TypeMapper {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, ok := m.toInternal[external]; ok && existing != internal {
+		panic(fmt.Sprintf("event type %s is already mapped to %s, not %s", external, existing, internal))
+	}
+	m.toInternal[external] = internal
+	if _, ok := m.toExternal[internal]; !ok {
+		m.toExternal[internal] = external
+	}
+	return m
+}
+
+// ToInternal returns the Go-side name for an event type read from the store
+func (m *TypeMapper) ToInternal(external string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if internal, ok := m.toInternal[external]; ok {
+		return internal
+	}
+	return external
+}
+
+// ToExternal returns the event type to write for a Go-side name, so other consumers of the
+// stream see the names they expect
+func (m *TypeMapper) ToExternal(internal string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if external, ok := m.toExternal[internal]; ok {
+		return external
+	}
+	return internal
+}
+
+// Lookup resolves an event type as written in the store
+func (m *TypeMapper) Lookup(eventType string) (DecodeFunc, bool) {
+	return m.registry.Lookup(m.ToInternal(eventType))
+}
+
+// NewDotNetOrderMapper maps the order service's CLR type names onto the Go order events
+func NewDotNetOrderMapper() *TypeMapper {
+	return NewTypeMapper(NewOrderRegistry()).
+		Map("Orders.Contracts.OrderCreated", "OrderCreated").
+		Map("Orders.Contracts.ItemAdded", "ItemAdded").
+		Map("Orders.Contracts.OrderShipped", "OrderShipped").
+		// Written by versions before the contracts moved to their own assembly
+		Map("Orders.Domain.Events.ItemAdded", "ItemAdded")
+}
+
+// RunTypeMapperChecks verifies mapping, reverse mapping and decoding through the mapper, no server required
+func RunTypeMapperChecks() {
+	checks := newChecker("type mapper")
+
+	mapper := NewDotNetOrderMapper()
+
+	// --- Mapping ---
+	fmt.Println("\n--- Mapping ---")
+	for _, c := range []struct{ external, internal string }{
+		{"Orders.Contracts.OrderCreated", "OrderCreated"},
+		{"Orders.Contracts.ItemAdded", "ItemAdded"},
+		{"Orders.Domain.Events.ItemAdded", "ItemAdded"},
+		{"Orders.Contracts.OrderShipped", "OrderShipped"},
+		// Unmapped names pass through, including Go names written by Go producers
+		{"OrderCreated", "OrderCreated"},
+		{"Orders.Contracts.CouponApplied", "Orders.Contracts.CouponApplied"},
+	} {
+		external, internal := c.external, c.internal
+		got := mapper.ToInternal(external)
+		fmt.Printf("  %-31s -> %s\n", external, got)
+		checks.Check(got == internal, "%s should map to %s, got %s", external, internal, got)
+	}
+
+	// --- Reverse mapping ---
+	fmt.Println("\n--- Reverse mapping ---")
+	for _, c := range []struct{ internal, external string }{
+		{"OrderCreated", "Orders.Contracts.OrderCreated"},
+		// The alias is read-only: writes use the first mapping
+		{"ItemAdded", "Orders.Contracts.ItemAdded"},
+		{"OrderShipped", "Orders.Contracts.OrderShipped"},
+		{"CouponApplied", "CouponApplied"},
+	} {
+		internal, external := c.internal, c.external
+		got := mapper.ToExternal(internal)
+		fmt.Printf("  %-13s -> %s\n", internal, got)
+		checks.Check(got == external, "%s should map back to %s, got %s", internal, external, got)
+		checks.Check(mapper.ToInternal(got) == internal, "%s should round-trip, got %s", internal, mapper.ToInternal(got))
+	}
+
+	// --- Decoding ---
+	fmt.Println("\n--- Decoding ---")
+	decoder := NewDecoder(mapper)
+	// System.Text.Json writes PascalCase by default; encoding/json matches field names case-insensitively
+	decoded, err := decoder.Decode(Envelope{EventType: "Orders.Domain.Events.ItemAdded", Data: []byte(`{"Item":"Widget","Price":25}`)})
+	checks.Check(err == nil && decoded == ItemAdded{Item: "Widget", Price: 25}, "an aliased .NET event should decode into ItemAdded, got %#v (%v)", decoded, err)
+	decoded, err = decoder.Decode(Envelope{EventType: "OrderCreated", Data: []byte(`{"orderId":"1","customerId":"cust-1","amount":10}`)})
+	checks.Check(err == nil && decoded == OrderCreated{OrderID: "1", CustomerID: "cust-1", Amount: 10}, "a Go name should still decode, got %#v (%v)", decoded, err)
+	decoded, err = decoder.Decode(Envelope{EventType: "Orders.Contracts.CouponApplied", Data: []byte(`{}`)})
+	unknown, isUnknown := decoded.(UnknownEvent)
+	checks.Check(err == nil && isUnknown && unknown.Type == "Orders.Contracts.CouponApplied",
+		"an unmapped, unregistered type should pass through as UnknownEvent with its stored name, got %#v (%v)", decoded, err)
+
+	// --- Conflicts ---
+	fmt.Println("\n--- Conflicts ---")
+	conflict := func() (value interface{}) {
+		defer func() { value = recover() }()
+		NewDotNetOrderMapper().Map("Orders.Contracts.ItemAdded", "OrderShipped")
+		return nil
+	}()
+	fmt.Printf("  remapping: %v\n", conflict)
+	checks.Check(conflict != nil, "mapping an external name to a second internal name should panic")
+
+	checks.Done()
+}
+
+// RunTypeMapper consumes an order stream written by a .NET service and appends to it under the
+// service's type names
+func RunTypeMapper() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	orderID := uuid.New().String()
+	streamName := Streams.Name("order", orderID)
+	mapper := NewDotNetOrderMapper()
+
+	// === WRITTEN BY THE .NET SERVICE ===
+	history := []struct{ eventType, data string }{
+		{"Orders.Contracts.OrderCreated", fmt.Sprintf(`{"OrderId":"%s","CustomerId":"cust-1","Amount":0}`, orderID)},
+		{"Orders.Domain.Events.ItemAdded", `{"Item":"Widget","Price":25}`},
+		{"Orders.Contracts.ItemAdded", `{"Item":"Gadget","Price":30}`},
+		{"Orders.Contracts.CouponApplied", `{"Code":"SPRING10"}`},
+	}
+	for _, h := range history {
+		_, err := client.AppendToStream(ctx, streamName, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   h.eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(h.data),
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	fmt.Printf("Appended %d .NET events to %s\n", len(history), streamName)
+
+	// === WRITTEN BY GO, FOR .NET READERS ===
+	shippedData, _ := json.Marshal(OrderShipped{ShippedAt: "2024-01-15T10:00:00Z"})
+	shippedType := mapper.ToExternal("OrderShipped")
+	_, err := client.AppendToStream(ctx, streamName, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+		EventID:     uuid.New(),
+		EventType:   shippedType,
+		ContentType: kurrentdb.ContentTypeJson,
+		Data:        shippedData,
+	})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Appended OrderShipped as %s\n", shippedType)
+
+	// === READ AND DECODE ===
+	fmt.Println("\n=== Reading the stream ===")
+	events, err := client.ReadStream(ctx, streamName, kurrentdb.ReadStreamOptions{From: kurrentdb.Start{}}, ^uint64(0))
+	if err != nil {
+		panic(err)
+	}
+	defer events.Close()
+
+	decoder := NewDecoder(mapper)
+	order := NewOrder(orderID)
+	var storedTypes, unknown []string
+	for {
+		event, err := events.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			panic(err)
+		}
+		envelope := NewEnvelope(event)
+		storedTypes = append(storedTypes, envelope.EventType)
+
+		decoded, err := decoder.Decode(envelope)
+		if err != nil {
+			fmt.Printf("  Skipping malformed event: %v\n", err)
+			continue
+		}
+		switch e := decoded.(type) {
+		case OrderCreated:
+			order.Status = "created"
+			order.CustomerID = e.CustomerID
+		case ItemAdded:
+			order.Items = append(order.Items, e.Item)
+			order.Amount += e.Price
+		case OrderShipped:
+			order.Status = "shipped"
+		case UnknownEvent:
+			unknown = append(unknown, e.Type)
+		}
+		fmt.Printf("  %-31s -> %T\n", envelope.EventType, decoded)
+	}
+	fmt.Printf("\nOrder: status=%s customer=%s amount=%.2f items=%v\n", order.Status, order.CustomerID, order.Amount, order.Items)
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	if order.Status != "shipped" || order.CustomerID != "cust-1" || order.Amount != 55 || len(order.Items) != 2 {
+		fmt.Printf("FAIL: The .NET events should fold into a shipped order of 55 with 2 items, got %s %s %.2f %v\n",
+			order.Status, order.CustomerID, order.Amount, order.Items)
+		passed = false
+	}
+	if fmt.Sprint(unknown) != "[Orders.Contracts.CouponApplied]" {
+		fmt.Printf("FAIL: Only CouponApplied should pass through unknown, got %v\n", unknown)
+		passed = false
+	}
+	if len(storedTypes) != 5 || storedTypes[4] != "Orders.Contracts.OrderShipped" {
+		fmt.Printf("FAIL: The Go-written event should be stored under its .NET name, got %v\n", storedTypes)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll type mapper tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,510 @@
+// KurrentDB Go Client Example - Webhook relay
+// Demonstrates: POSTing $all events to an HTTP endpoint with HMAC signatures, backoff retries and a 2xx-gated checkpoint
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === FILE CHECKPOINT ===
+
+// FileCheckpoint stores a $all position as JSON, written atomically via rename
+type FileCheckpoint struct {
+	Path string
+}
+
+// Load returns the stored position, or nil if nothing has been saved yet
+func (c FileCheckpoint) Load() (*kurrentdb.Position, error) {
+	data, err := os.ReadFile(c.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var position kurrentdb.Position
+	if err := json.Unmarshal(data, &position); err != nil {
+		return nil, fmt.Errorf("corrupt checkpoint %s: %w", c.Path, err)
+	}
+	return &position, nil
+}
+
+func (c FileCheckpoint) Save(position kurrentdb.Position) error {
+	data, err := json.Marshal(position)
+	if err != nil {
+		return err
+	}
+	tmp := c.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.Path)
+}
+
+// === WEBHOOK RELAY ===
+
+// Headers sent with every delivery. Receivers verify the signature and dedupe on the event id.
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookEventIDHeader   = "X-Webhook-Event-Id"
+	WebhookAttemptHeader   = "X-Webhook-Attempt"
+)
+
+// RejectAction decides what happens to an event the endpoint refuses with a 4xx
+type RejectAction int
+
+const (
+	// RejectPark appends the event to the park stream for manual inspection, then moves on
+	RejectPark RejectAction = iota
+	// RejectSkip drops the event and moves on
+	RejectSkip
+)
+
+// WebhookOptions configures a relay
+type WebhookOptions struct {
+	URL    string
+	Secret []byte
+
+	// MaxAttempts bounds deliveries of one event on 5xx or transport errors; the relay stops when exhausted
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	OnReject   RejectAction
+	ParkStream string
+
+	Checkpoint FileCheckpoint
+	HTTPClient *http.Client
+}
+
+// WebhookPayload is the JSON body POSTed for each event
+type WebhookPayload struct {
+	EventID     uuid.UUID       `json:"eventId"`
+	EventType   string          `json:"eventType"`
+	StreamID    string          `json:"streamId"`
+	EventNumber uint64          `json:"eventNumber"`
+	Commit      uint64          `json:"commitPosition"`
+	Prepare     uint64          `json:"preparePosition"`
+	Created     time.Time       `json:"created"`
+	Data        json.RawMessage `json:"data,omitempty"`
+	Metadata    json.RawMessage `json:"metadata,omitempty"`
+}
+
+// webhookRejected is a 4xx response: retrying the same request won't change the answer
+type webhookRejected struct {
+	status int
+	body   string
+}
+
+func (e *webhookRejected) Error() string {
+	return fmt.Sprintf("rejected with %d: %s", e.status, e.body)
+}
+
+// WebhookRelay delivers events one at a time, in $all order.
+//
+// Delivery is at-least-once: the checkpoint is saved after the endpoint answers 2xx (or after a
+// 4xx event was parked or skipped), so a crash between the response and the save redelivers that
+// event on restart. Receivers should dedupe on the X-Webhook-Event-Id header. Because the next event
+// isn't sent until the current one is settled, the endpoint never sees events out of order.
+type WebhookRelay struct {
+	client  *kurrentdb.Client
+	options WebhookOptions
+
+	Delivered int
+	Rejected  int
+	Retried   int
+}
+
+func NewWebhookRelay(client *kurrentdb.Client, options WebhookOptions) *WebhookRelay {
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 5
+	}
+	if options.InitialBackoff <= 0 {
+		options.InitialBackoff = 200 * time.Millisecond
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = 30 * time.Second
+	}
+	if options.HTTPClient == nil {
+		options.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookRelay{client: client, options: options}
+}
+
+// Sign returns the signature header value for body: "sha256=" + hex(HMAC-SHA256(secret, body))
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature is what a receiver runs before trusting a delivery
+func VerifySignature(secret, body []byte, header string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(header))
+}
+
+// Run relays events matching filter until done returns true, ctx is cancelled, or a delivery
+// exhausts its retries. Restarting resumes after the last settled event.
+func (r *WebhookRelay) Run(ctx context.Context, filter *kurrentdb.SubscriptionFilter, done func(Envelope) bool) error {
+	checkpoint, err := r.options.Checkpoint.Load()
+	if err != nil {
+		return err
+	}
+
+	options := kurrentdb.SubscribeToAllOptions{Filter: filter, From: kurrentdb.Start{}}
+	if checkpoint != nil {
+		options.From = *checkpoint
+		fmt.Printf("  Resuming from checkpoint %d/%d\n", checkpoint.Commit, checkpoint.Prepare)
+	}
+
+	subscription, err := r.client.SubscribeToAll(ctx, options)
+	if err != nil {
+		return err
+	}
+	defer subscription.Close()
+
+	for {
+		event := subscription.Recv()
+
+		if event.SubscriptionDropped != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return event.SubscriptionDropped.Error
+		}
+
+		if event.EventAppeared != nil {
+			envelope := NewEnvelope(event.EventAppeared)
+			if err := r.relay(ctx, envelope); err != nil {
+				return fmt.Errorf("relay %s@%d: %w", envelope.StreamID, envelope.EventNumber, err)
+			}
+			if err := r.options.Checkpoint.Save(envelope.Position); err != nil {
+				return err
+			}
+			if done != nil && done(envelope) {
+				return nil
+			}
+		}
+	}
+}
+
+// relay settles one event: delivered, parked or skipped. An error means it is still unsettled.
+func (r *WebhookRelay) relay(ctx context.Context, envelope Envelope) error {
+	body, err := json.Marshal(r.payload(envelope))
+	if err != nil {
+		return err
+	}
+
+	err = r.deliver(ctx, envelope, body)
+
+	var rejected *webhookRejected
+	if !errors.As(err, &rejected) {
+		if err == nil {
+			r.Delivered++
+		}
+		return err
+	}
+
+	r.Rejected++
+	if r.options.OnReject == RejectSkip {
+		fmt.Printf("  Skipped %s@%d: %v\n", envelope.StreamID, envelope.EventNumber, rejected)
+		return nil
+	}
+	fmt.Printf("  Parking %s@%d: %v\n", envelope.StreamID, envelope.EventNumber, rejected)
+	return r.park(ctx, envelope, rejected)
+}
+
+func (r *WebhookRelay) payload(envelope Envelope) WebhookPayload {
+	payload := WebhookPayload{
+		EventID:     envelope.EventID,
+		EventType:   envelope.EventType,
+		StreamID:    envelope.StreamID,
+		EventNumber: envelope.EventNumber,
+		Commit:      envelope.Position.Commit,
+		Prepare:     envelope.Position.Prepare,
+		Created:     envelope.Created,
+	}
+	if json.Valid(envelope.Data) {
+		payload.Data = envelope.Data
+	} else if len(envelope.Data) > 0 {
+		// Binary events are sent as a base64 JSON string
+		payload.Data, _ = json.Marshal(envelope.Data)
+	}
+	if json.Valid(envelope.Metadata) {
+		payload.Metadata = envelope.Metadata
+	}
+	return payload
+}
+
+// deliver POSTs body with exponential backoff. 5xx, 408, 429 and transport errors are retried;
+// any other non-2xx is returned as *webhookRejected without retrying.
+func (r *WebhookRelay) deliver(ctx context.Context, envelope Envelope, body []byte) error {
+	backoff := NewBackoff(r.options.InitialBackoff, r.options.MaxBackoff)
+
+	retryable := func(err error) bool {
+		var rejected *webhookRejected
+		return !errors.As(err, &rejected)
+	}
+
+	var lastErr error
+	err := backoff.Retry(ctx, r.options.MaxAttempts, func(attempt int) error {
+		if attempt > 1 {
+			r.Retried++
+			fmt.Printf("  Retrying %s@%d (attempt %d): %v\n", envelope.StreamID, envelope.EventNumber, attempt, lastErr)
+		}
+		lastErr = r.post(ctx, envelope, body, attempt)
+		return lastErr
+	}, retryable)
+	if err != nil && ctx.Err() == nil && retryable(err) {
+		return fmt.Errorf("gave up after %d attempts: %w", r.options.MaxAttempts, err)
+	}
+	return err
+}
+
+// post makes one delivery attempt
+func (r *WebhookRelay) post(ctx context.Context, envelope Envelope, body []byte, attempt int) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, r.options.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookSignatureHeader, Sign(r.options.Secret, body))
+	request.Header.Set(WebhookEventIDHeader, envelope.EventID.String())
+	request.Header.Set(WebhookAttemptHeader, strconv.Itoa(attempt))
+
+	response, err := r.options.HTTPClient.Do(request)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	responseBody, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+	response.Body.Close()
+
+	switch status := response.StatusCode; {
+	case status >= 200 && status < 300:
+		return nil
+	case status >= 500, status == http.StatusRequestTimeout, status == http.StatusTooManyRequests:
+		return fmt.Errorf("endpoint returned %d", status)
+	default:
+		return &webhookRejected{status: status, body: string(bytes.TrimSpace(responseBody))}
+	}
+}
+
+// park copies the rejected event to the park stream with the response in its metadata
+func (r *WebhookRelay) park(ctx context.Context, envelope Envelope, rejected *webhookRejected) error {
+	metadata, err := json.Marshal(map[string]interface{}{
+		"originalStreamId":    envelope.StreamID,
+		"originalEventNumber": envelope.EventNumber,
+		"originalEventId":     envelope.EventID,
+		"status":              rejected.status,
+		"response":            rejected.body,
+	})
+	if err != nil {
+		return err
+	}
+
+	contentType := kurrentdb.ContentTypeJson
+	if !json.Valid(envelope.Data) {
+		contentType = kurrentdb.ContentTypeBinary
+	}
+
+	// The original event id keeps a retried park idempotent
+	_, err = r.client.AppendToStream(ctx, r.options.ParkStream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+		EventID:     envelope.EventID,
+		EventType:   envelope.EventType,
+		ContentType: contentType,
+		Data:        envelope.Data,
+		Metadata:    metadata,
+	})
+	return err
+}
+
+// RunWebhook relays order events to an in-process endpoint that fails transiently and rejects one event
+func RunWebhook() {
+	ctx := context.Background()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	secret := []byte("webhook-demo-secret")
+
+	// === RECEIVER ===
+	var mu sync.Mutex
+	var received []WebhookPayload
+	badSignatures := 0
+	attempts := map[string]int{}
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !VerifySignature(secret, body, r.Header.Get(WebhookSignatureHeader)) {
+			badSignatures++
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+
+		var payload WebhookPayload
+		json.Unmarshal(body, &payload)
+
+		eventID := r.Header.Get(WebhookEventIDHeader)
+		attempts[eventID]++
+
+		switch {
+		case payload.EventType == "ItemAdded" && attempts[eventID] == 1:
+			// Transient outage on the first attempt
+			http.Error(w, "temporarily unavailable", http.StatusServiceUnavailable)
+		case payload.EventType == "OrderNoted":
+			http.Error(w, "unsupported event type", http.StatusUnprocessableEntity)
+		default:
+			received = append(received, payload)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer receiver.Close()
+
+	// === APPEND EVENTS ===
+	orderID := uuid.New().String()
+	streamName := Streams.Name("order", orderID)
+
+	history := []struct{ eventType, data string }{
+		{"OrderCreated", fmt.Sprintf(`{"orderId":"%s","customerId":"cust-1","amount":100}`, orderID)},
+		{"ItemAdded", `{"item":"Widget","price":25}`},
+		{"OrderNoted", `{"note":"leave at the door"}`},
+		{"OrderShipped", `{"shippedAt":"2024-01-15T10:00:00Z"}`},
+	}
+	for _, h := range history {
+		_, err := client.AppendToStream(ctx, streamName, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   h.eventType,
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        []byte(h.data),
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	fmt.Printf("Appended %d events to %s\n", len(history), streamName)
+
+	// === RELAY ===
+	dir, err := os.MkdirTemp("", "webhook")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	parkStream := "webhook-parked-" + orderID
+	checkpoint := FileCheckpoint{Path: filepath.Join(dir, "checkpoint.json")}
+
+	relay := NewWebhookRelay(client, WebhookOptions{
+		URL:            receiver.URL,
+		Secret:         secret,
+		MaxAttempts:    4,
+		InitialBackoff: 50 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		OnReject:       RejectPark,
+		ParkStream:     parkStream,
+		Checkpoint:     checkpoint,
+	})
+
+	fmt.Println("\nRelaying to", receiver.URL)
+	seen := 0
+	filter := &kurrentdb.SubscriptionFilter{Type: kurrentdb.StreamFilterType, Prefixes: []string{streamName}}
+	err = relay.Run(ctx, filter, func(e Envelope) bool {
+		seen++
+		return seen >= len(history)
+	})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("  Delivered=%d Rejected=%d Retries=%d\n", relay.Delivered, relay.Rejected, relay.Retried)
+
+	saved, err := checkpoint.Load()
+	if err != nil {
+		panic(err)
+	}
+
+	parked, err := client.ReadStream(ctx, parkStream, kurrentdb.ReadStreamOptions{From: kurrentdb.Start{}}, 10)
+	if err != nil {
+		panic(err)
+	}
+	defer parked.Close()
+
+	var parkedTypes []string
+	for {
+		event, err := parked.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			panic(err)
+		}
+		parkedTypes = append(parkedTypes, Resolve(event, false).EventType)
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var receivedTypes []string
+	for _, p := range received {
+		receivedTypes = append(receivedTypes, p.EventType)
+	}
+	if fmt.Sprint(receivedTypes) != "[OrderCreated ItemAdded OrderShipped]" {
+		fmt.Printf("FAIL: Endpoint should receive accepted events in order, got %v\n", receivedTypes)
+		passed = false
+	}
+	if badSignatures != 0 {
+		fmt.Printf("FAIL: Every delivery should carry a valid signature, %d did not\n", badSignatures)
+		passed = false
+	}
+	if relay.Retried != 1 {
+		fmt.Printf("FAIL: The 503 should be retried exactly once, got %d retries\n", relay.Retried)
+		passed = false
+	}
+	if fmt.Sprint(parkedTypes) != "[OrderNoted]" {
+		fmt.Printf("FAIL: The 422 event should be parked, park stream has %v\n", parkedTypes)
+		passed = false
+	}
+	if saved == nil || len(received) == 0 || saved.Commit != received[len(received)-1].Commit {
+		fmt.Printf("FAIL: Checkpoint should be the last settled event, got %v\n", saved)
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll webhook tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}
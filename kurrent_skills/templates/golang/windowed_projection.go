@@ -0,0 +1,518 @@
+// KurrentDB Go Client Example - Time-windowed projection
+// Demonstrates: Folding events into tumbling windows, closing windows past a grace period, and handling late events
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kurrent-io/KurrentDB-Client-Go/kurrentdb"
+)
+
+// === WINDOWED PROJECTION ===
+
+// Window is one tumbling window of a WindowedProjection: the state its handlers built from the
+// events timestamped in [Start, End)
+type Window struct {
+	Start  time.Time
+	End    time.Time
+	State  map[string]interface{}
+	Events int
+	// Late counts events applied after the watermark had already passed End, within the grace period
+	Late int
+	// Closed windows are final: events for them are dropped
+	Closed bool
+}
+
+// WindowedProjection folds events into tumbling windows of Size, by event time. The watermark is
+// the latest event time seen; a window closes once the watermark passes its End by Grace, is
+// emitted to OnClose, and drops any event for it that arrives later. Safe for concurrent use.
+//
+// Event time defaults to EventTime: the occurredAt metadata of imported events, and otherwise
+// Created, the server's clock at write time. In one node's $all Created hardly goes backwards, so
+// late events come from producer timestamps, read by Timestamp, and from imports.
+type WindowedProjection struct {
+	Name       string
+	Size       time.Duration
+	Grace      time.Duration
+	Checkpoint *kurrentdb.Position
+
+	timestamp func(event *kurrentdb.RecordedEvent, data map[string]interface{}) (time.Time, error)
+	handlers  map[string]EventHandler
+	onClose   func(Window)
+
+	mu        sync.Mutex
+	windows   map[time.Time]*Window
+	watermark time.Time
+	dropped   int
+}
+
+// NewWindowedProjection builds windows of size, closed grace after they end
+func NewWindowedProjection(name string, size, grace time.Duration) *WindowedProjection {
+	if size <= 0 || grace < 0 {
+		panic(fmt.Sprintf("windowed projection %s: invalid size %s or grace %s", name, size, grace))
+	}
+	return &WindowedProjection{
+		Name:  name,
+		Size:  size,
+		Grace: grace,
+		timestamp: func(event *kurrentdb.RecordedEvent, data map[string]interface{}) (time.Time, error) {
+			return EventTime(event)
+		},
+		handlers: make(map[string]EventHandler),
+		windows:  make(map[time.Time]*Window),
+	}
+}
+
+// On registers the handler folding eventType into a window's state, which starts empty
+func (p *WindowedProjection) On(eventType string, handler EventHandler) *WindowedProjection {
+	p.handlers[eventType] = handler
+	return p
+}
+
+// Timestamp replaces EventTime as the event time, e.g. with a business timestamp from the data
+func (p *WindowedProjection) Timestamp(fn func(event *kurrentdb.RecordedEvent, data map[string]interface{}) (time.Time, error)) *WindowedProjection {
+	p.timestamp = fn
+	return p
+}
+
+// OnClose registers fn to receive each window as it closes, in window order
+func (p *WindowedProjection) OnClose(fn func(Window)) *WindowedProjection {
+	p.onClose = fn
+	return p
+}
+
+// Apply folds the event into its window. It returns false for unhandled types and for late events
+// dropped because their window had closed, and an error if the data isn't JSON, the timestamp
+// can't be read or the handler panics; on error nothing changes. Otherwise the checkpoint moves to
+// position, for handled, unhandled and dropped events alike.
+func (p *WindowedProjection) Apply(event *kurrentdb.RecordedEvent, position kurrentdb.Position) (bool, error) {
+	applied, closed, err := p.apply(event, position)
+	p.emit(closed)
+	return applied, err
+}
+
+func (p *WindowedProjection) apply(event *kurrentdb.RecordedEvent, position kurrentdb.Position) (bool, []Window, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	handler := p.handlers[event.EventType]
+	if handler == nil {
+		p.Checkpoint = &position
+		return false, nil, nil
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return false, nil, fmt.Errorf("decode %s on %s: %w", event.EventType, event.StreamID, err)
+	}
+	at, err := p.timestamp(event, data)
+	if err != nil {
+		return false, nil, fmt.Errorf("timestamp of %s on %s: %w", event.EventType, event.StreamID, err)
+	}
+
+	start := at.UTC().Truncate(p.Size)
+	window := p.windows[start]
+	if (window != nil && window.Closed) || (window == nil && p.expired(start.Add(p.Size))) {
+		p.dropped++
+		p.Checkpoint = &position
+		return false, nil, nil
+	}
+	if window == nil {
+		window = &Window{Start: start, End: start.Add(p.Size)}
+	}
+
+	next, err := p.invoke(handler, event, window.State, data)
+	if err != nil {
+		return false, nil, err
+	}
+	window.State = next
+	window.Events++
+	if !p.watermark.Before(window.End) {
+		window.Late++
+	}
+	p.windows[start] = window
+
+	if at.After(p.watermark) {
+		p.watermark = at
+	}
+	p.Checkpoint = &position
+	return true, p.closeExpired(), nil
+}
+
+// invoke runs the handler on a copy of the window state, so a panic leaves the window unchanged
+func (p *WindowedProjection) invoke(handler EventHandler, event *kurrentdb.RecordedEvent, state, data map[string]interface{}) (next map[string]interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &HandlerPanicError{EventType: event.EventType, StreamID: event.StreamID, Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	state = maps.Clone(state)
+	if state == nil {
+		state = make(map[string]interface{})
+	}
+	return handler(state, data), nil
+}
+
+// expired reports whether a window ending at end is past its grace period; p.mu must be held
+func (p *WindowedProjection) expired(end time.Time) bool {
+	return !p.watermark.IsZero() && !end.Add(p.Grace).After(p.watermark)
+}
+
+// closeExpired closes the windows past their grace period and returns them in window order; p.mu
+// must be held
+func (p *WindowedProjection) closeExpired() []Window {
+	var closed []Window
+	for _, window := range p.windows {
+		if !window.Closed && p.expired(window.End) {
+			window.Closed = true
+			closed = append(closed, window.copy())
+		}
+	}
+	slices.SortFunc(closed, func(a, b Window) int { return a.Start.Compare(b.Start) })
+	return closed
+}
+
+// emit hands closed windows to OnClose, outside the lock so it may query the projection
+func (p *WindowedProjection) emit(closed []Window) {
+	if p.onClose == nil {
+		return
+	}
+	for _, window := range closed {
+		p.onClose(window)
+	}
+}
+
+// CloseUntil moves the watermark to now if it is behind, closing the windows that are past their
+// grace period by then. Call it on a timer, so the last windows close even when no later event
+// arrives to move the watermark.
+func (p *WindowedProjection) CloseUntil(now time.Time) {
+	p.mu.Lock()
+	if now.After(p.watermark) {
+		p.watermark = now
+	}
+	closed := p.closeExpired()
+	p.mu.Unlock()
+
+	p.emit(closed)
+}
+
+// Window returns the window containing t, if it has any events
+func (p *WindowedProjection) Window(t time.Time) (Window, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	window, ok := p.windows[t.UTC().Truncate(p.Size)]
+	if !ok {
+		return Window{}, false
+	}
+	return window.copy(), true
+}
+
+// Windows returns every window with events, in window order
+func (p *WindowedProjection) Windows() []Window {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	windows := make([]Window, 0, len(p.windows))
+	for _, window := range p.windows {
+		windows = append(windows, window.copy())
+	}
+	slices.SortFunc(windows, func(a, b Window) int { return a.Start.Compare(b.Start) })
+	return windows
+}
+
+// Dropped returns how many late events were dropped because their window had closed
+func (p *WindowedProjection) Dropped() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.dropped
+}
+
+// copy returns w with its own state map, safe to hand outside the lock
+func (w *Window) copy() Window {
+	copied := *w
+	copied.State = maps.Clone(w.State)
+	return copied
+}
+
+// NewHourlyOrderTotals counts OrderPlaced events and sums their amounts per hour, by the order's
+// placedAt, accepting orders up to grace late
+func NewHourlyOrderTotals(grace time.Duration) *WindowedProjection {
+	return NewWindowedProjection("HourlyOrderTotals", time.Hour, grace).
+		Timestamp(func(event *kurrentdb.RecordedEvent, data map[string]interface{}) (time.Time, error) {
+			placedAt, _ := data["placedAt"].(string)
+			return time.Parse(time.RFC3339, placedAt)
+		}).
+		On("OrderPlaced", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			orders, _ := state["orders"].(float64)
+			total, _ := state["total"].(float64)
+			state["orders"] = orders + 1
+			state["total"] = total + data["amount"].(float64)
+			return state
+		})
+}
+
+// RunWindowedProjectionChecks checks window assignment, closing, grace and late events with
+// synthetic events, no server required
+func RunWindowedProjectionChecks() {
+	checks := newChecker("windowed projection")
+
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	var closed []string
+	projection := NewWindowedProjection("OrderCounts", time.Hour, 10*time.Minute).
+		On("OrderPlaced", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			orders, _ := state["orders"].(float64)
+			state["orders"] = orders + 1
+			return state
+		}).
+		OnClose(func(w Window) {
+			closed = append(closed, fmt.Sprintf("%s=%v", w.Start.Format("15:04"), w.State["orders"]))
+		})
+
+	commit := uint64(0)
+	apply := func(eventType string, created time.Time) bool {
+		commit += 100
+		event := syntheticEvent("order-1", eventType, commit/100-1, commit, `{}`)
+		event.CreatedDate = created
+		applied, err := projection.Apply(event, event.Position)
+		checks.Check(err == nil, "applying %s at %s: %v", eventType, created.Format("15:04"), err)
+		return applied
+	}
+	count := func(t time.Time) interface{} {
+		window, _ := projection.Window(t)
+		return window.State["orders"]
+	}
+
+	// --- Tumbling windows ---
+	fmt.Println("\n--- Tumbling windows ---")
+	apply("OrderPlaced", at(10, 5))
+	apply("OrderPlaced", at(10, 30))
+	checks.Check(!apply("PageViewed", at(10, 40)), "unhandled types should not be applied")
+	apply("OrderPlaced", at(11, 5))
+	window, ok := projection.Window(at(10, 45))
+	checks.Check(ok && window.Start.Equal(at(10, 0)) && window.End.Equal(at(11, 0)), "10:45 should be in the 10:00-11:00 window, got %v", window)
+	checks.Check(count(at(10, 0)) == float64(2) && count(at(11, 59)) == float64(1), "expected 2 orders at 10:00 and 1 at 11:00, got %v and %v", count(at(10, 0)), count(at(11, 0)))
+	_, ok = projection.Window(at(9, 0))
+	checks.Check(!ok, "a window without events should not exist")
+	checks.Check(len(closed) == 0, "no window should close within the grace period, got %v", closed)
+	checks.Check(projection.Checkpoint != nil && projection.Checkpoint.Commit == commit, "every event should advance the checkpoint, got %v", projection.Checkpoint)
+
+	// --- Late events ---
+	fmt.Println("\n--- Late events ---")
+	checks.Check(apply("OrderPlaced", at(10, 55)), "an event within the grace period should be applied")
+	window, _ = projection.Window(at(10, 0))
+	checks.Check(window.State["orders"] == float64(3) && window.Late == 1 && !window.Closed, "10:00 should take the late event, got %+v", window)
+	apply("OrderPlaced", at(11, 12))
+	checks.Check(fmt.Sprint(closed) == "[10:00=3]", "10:00 should close once the watermark passes 11:10, got %v", closed)
+	checks.Check(!apply("OrderPlaced", at(10, 59)), "an event for a closed window should be dropped")
+	checks.Check(!apply("OrderPlaced", at(8, 0)), "an event for a window past its grace should be dropped even if it never existed")
+	checks.Check(projection.Dropped() == 2 && count(at(10, 0)) == float64(3), "2 events should be dropped without changing 10:00, got %d and %v", projection.Dropped(), count(at(10, 0)))
+	_, ok = projection.Window(at(8, 0))
+	checks.Check(!ok, "a dropped event should not create a window")
+
+	// --- Closing on time ---
+	fmt.Println("\n--- Closing on time ---")
+	projection.CloseUntil(at(12, 5))
+	checks.Check(fmt.Sprint(closed) == "[10:00=3]", "11:00 should stay open within its grace period, got %v", closed)
+	projection.CloseUntil(at(12, 10))
+	checks.Check(fmt.Sprint(closed) == "[10:00=3 11:00=2]", "11:00 should close at 12:10, got %v", closed)
+	windows := projection.Windows()
+	checks.Check(len(windows) == 2 && windows[0].Closed && windows[1].Closed, "both windows should be listed closed, got %+v", windows)
+	fmt.Printf("  closed: %v\n", closed)
+
+	// --- Handler panics ---
+	fmt.Println("\n--- Handler panics ---")
+	panicky := NewWindowedProjection("Panicky", time.Hour, 0).
+		On("OrderPlaced", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			state["orders"] = 1
+			return state
+		}).
+		On("Broken", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			state["orders"] = 99
+			panic("broken handler")
+		})
+	good := syntheticEvent("order-1", "OrderPlaced", 0, 100, `{}`)
+	good.CreatedDate = at(10, 0)
+	broken := syntheticEvent("order-1", "Broken", 1, 200, `{}`)
+	broken.CreatedDate = at(10, 1)
+	panicky.Apply(good, good.Position)
+	_, err := panicky.Apply(broken, broken.Position)
+	window, _ = panicky.Window(at(10, 0))
+	checks.Check(err != nil && window.State["orders"] == 1 && window.Events == 1, "a panicking handler should leave the window unchanged, got %v and %+v", err, window)
+	checks.Check(panicky.Checkpoint.Commit == 100, "a failed event should not advance the checkpoint, got %d", panicky.Checkpoint.Commit)
+
+	// --- Occurred at ---
+	fmt.Println("\n--- Occurred at ---")
+	imported := NewWindowedProjection("Imported", time.Hour, 0).
+		On("OrderPlaced", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			orders, _ := state["orders"].(float64)
+			state["orders"] = orders + 1
+			return state
+		})
+	backfilled, err := WithOccurredAt(kurrentdb.EventData{Metadata: []byte(`{"$correlationId":"import-7"}`)}, at(9, 20))
+	checks.Check(err == nil, "occurredAt should be added to existing metadata, got %v", err)
+	historical := syntheticEvent("order-1", "OrderPlaced", 0, 100, `{}`)
+	historical.CreatedDate, historical.UserMetadata = at(15, 0), backfilled.Metadata
+	current := syntheticEvent("order-2", "OrderPlaced", 0, 200, `{}`)
+	current.CreatedDate = at(15, 5)
+	imported.Apply(historical, historical.Position)
+	imported.Apply(current, current.Position)
+	_, inNine := imported.Window(at(9, 0))
+	_, inFifteen := imported.Window(at(15, 0))
+	var kept Meta
+	kept.UnmarshalJSON(backfilled.Metadata)
+	checks.Check(inNine && inFifteen && len(imported.Windows()) == 2, "occurredAt should win over Created, and Created be the fallback, got %+v", imported.Windows())
+	checks.Check(kept.Correlation() == "import-7", "WithOccurredAt should keep the other metadata, got %v", kept)
+	invalid := syntheticEvent("order-3", "OrderPlaced", 0, 300, `{}`)
+	invalid.UserMetadata = []byte(`{"occurredAt":"yesterday"}`)
+	_, err = imported.Apply(invalid, invalid.Position)
+	fmt.Printf("  malformed occurredAt: %v\n", err)
+	checks.Check(errors.Is(err, ErrInvalidMeta), "a malformed occurredAt should fail the event, got %v", err)
+
+	checks.Done()
+}
+
+// RunWindowedProjection writes orders placed over three hours, some arriving late, and builds
+// hourly order totals from them
+func RunWindowedProjection() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// === CONNECTION ===
+	client, connectionString := connect()
+	defer client.Close()
+
+	fmt.Printf("Connected to KurrentDB at %s\n", connectionString)
+
+	// === ORDERS ===
+	// Orders arrive from tills that forward them in batches, so placedAt can lag the write
+	stream := Streams.Name("orders", uuid.New().String())
+	base := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	orders := []struct {
+		Minute int
+		Amount float64
+	}{
+		{5, 20}, {40, 30}, // 09:00
+		{65, 15},            // 10:00
+		{58, 5},             // 09:00, arriving after 10:05: late, but 09:00 stays open until 10:15
+		{80, 25}, {110, 10}, // 10:00
+		{130, 40},  // 11:00
+		{55, 1000}, // 09:00, arriving after 11:10: dropped, 09:00 has closed
+		{170, 35},  // 11:00
+	}
+	for _, order := range orders {
+		data, _ := json.Marshal(map[string]interface{}{
+			"orderId":  uuid.New().String(),
+			"amount":   order.Amount,
+			"placedAt": base.Add(time.Duration(order.Minute) * time.Minute).Format(time.RFC3339),
+		})
+		_, err := client.AppendToStream(ctx, stream, kurrentdb.AppendToStreamOptions{}, kurrentdb.EventData{
+			EventID:     uuid.New(),
+			EventType:   "OrderPlaced",
+			ContentType: kurrentdb.ContentTypeJson,
+			Data:        data,
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+	fmt.Printf("Wrote %d orders to %s\n", len(orders), stream)
+
+	events, _, err := readStreamFrom(ctx, NewClientStore(client), stream, NoVersion)
+	if err != nil {
+		panic(err)
+	}
+
+	// === HOURLY TOTALS ===
+	fmt.Println("\n=== Hourly totals by placedAt, 15 minute grace ===")
+	hourly := NewHourlyOrderTotals(15 * time.Minute).OnClose(func(w Window) {
+		fmt.Printf("  closed %s: %v orders, total %v (%d late)\n", w.Start.Format("15:04"), w.State["orders"], w.State["total"], w.Late)
+	})
+	for _, event := range events {
+		if _, err := hourly.Apply(event, event.Position); err != nil {
+			panic(err)
+		}
+	}
+	// The last hour has no later order to close it; a timer would, at the end of its grace period
+	hourly.CloseUntil(base.Add(3*time.Hour + 15*time.Minute))
+	for _, window := range hourly.Windows() {
+		fmt.Printf("  %s-%s: %v orders, total %v, closed %v\n",
+			window.Start.Format("15:04"), window.End.Format("15:04"), window.State["orders"], window.State["total"], window.Closed)
+	}
+	fmt.Printf("  dropped late: %d\n", hourly.Dropped())
+	at0930, _ := hourly.Window(base.Add(30 * time.Minute))
+
+	// === BY CREATED ===
+	// Without Timestamp or occurredAt, windows follow the server's write time: everything lands in this hour
+	fmt.Println("\n=== Hourly counts by Created ===")
+	byCreated := NewWindowedProjection("HourlyOrderCounts", time.Hour, 15*time.Minute).
+		On("OrderPlaced", func(state map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+			orders, _ := state["orders"].(float64)
+			state["orders"] = orders + 1
+			return state
+		})
+	for _, event := range events {
+		if _, err := byCreated.Apply(event, event.Position); err != nil {
+			panic(err)
+		}
+	}
+	createdWindows := byCreated.Windows()
+	for _, window := range createdWindows {
+		fmt.Printf("  %s-%s: %v orders\n", window.Start.Format("15:04"), window.End.Format("15:04"), window.State["orders"])
+	}
+
+	// === ASSERTIONS ===
+	fmt.Println("\n=== Running assertions ===")
+
+	passed := true
+
+	windows := hourly.Windows()
+	var summary []string
+	for _, window := range windows {
+		summary = append(summary, fmt.Sprintf("%s:%v/%v/%d", window.Start.Format("15:04"), window.State["orders"], window.State["total"], window.Late))
+	}
+	if fmt.Sprint(summary) != "[09:00:3/55/1 10:00:3/50/0 11:00:2/75/0]" {
+		fmt.Printf("FAIL: Expected hourly orders/total/late of 09:00 3/55/1, 10:00 3/50/0, 11:00 2/75/0, got %v\n", summary)
+		passed = false
+	}
+	if hourly.Dropped() != 1 {
+		fmt.Printf("FAIL: The order arriving after 09:00 closed should be dropped, got %d dropped\n", hourly.Dropped())
+		passed = false
+	}
+	for _, window := range windows {
+		if !window.Closed {
+			fmt.Printf("FAIL: Every window should be closed, %s is open\n", window.Start.Format("15:04"))
+			passed = false
+		}
+	}
+	if !at0930.Start.Equal(base) || at0930.State["orders"] != float64(3) {
+		fmt.Printf("FAIL: Window(09:30) should return the 09:00 window, got %+v\n", at0930)
+		passed = false
+	}
+	if len(createdWindows) == 0 || len(createdWindows) > 2 {
+		fmt.Printf("FAIL: By Created, the orders should fall in the current hour (or two, across an hour boundary), got %d windows\n", len(createdWindows))
+		passed = false
+	}
+
+	if passed {
+		fmt.Println("\nAll windowed projection tests passed!")
+	} else {
+		fmt.Println("\nSome tests failed!")
+		os.Exit(1)
+	}
+}